@@ -21,9 +21,12 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"strings"
 	"time"
+	// embed the time zones of the analysis schedule, the image doesn't ship tzdata
+	_ "time/tzdata"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-logr/zapr"
@@ -47,9 +50,11 @@ import (
 	"github.com/fluxcd/flagger/pkg/logger"
 	"github.com/fluxcd/flagger/pkg/metrics/observers"
 	"github.com/fluxcd/flagger/pkg/notifier"
+	"github.com/fluxcd/flagger/pkg/quota"
 	"github.com/fluxcd/flagger/pkg/router"
 	"github.com/fluxcd/flagger/pkg/server"
 	"github.com/fluxcd/flagger/pkg/signals"
+	"github.com/fluxcd/flagger/pkg/spiffe"
 	"github.com/fluxcd/flagger/pkg/version"
 )
 
@@ -83,6 +88,20 @@ var (
 	enableConfigTracking     bool
 	ver                      bool
 	kubeconfigServiceMesh    string
+	noCrossNamespaceRefs     bool
+	verifyMeshProvider       bool
+	reportSMI                bool
+	decisionsAPIToken        string
+	maxCanaries              int
+	maxAnalyses              int
+	quotaTenantLabel         string
+	admissionPort            string
+	admissionCertFile        string
+	admissionKeyFile         string
+	insightsTeamLabel        string
+	insightsReportInterval   time.Duration
+	spiffeEndpointSocket     string
+	spiffeTrustDomain        string
 )
 
 func init() {
@@ -106,7 +125,7 @@ func init() {
 	flag.BoolVar(&zapReplaceGlobals, "zap-replace-globals", false, "Whether to change the logging level of the global zap logger.")
 	flag.StringVar(&zapEncoding, "zap-encoding", "json", "Zap logger encoding.")
 	flag.StringVar(&namespace, "namespace", "", "Namespace that flagger would watch canary object.")
-	flag.StringVar(&meshProvider, "mesh-provider", "istio", "Service mesh provider, can be istio, linkerd, appmesh, contour, gloo, nginx, skipper, traefik or osm.")
+	flag.StringVar(&meshProvider, "mesh-provider", "istio", "Service mesh provider, can be istio, linkerd, appmesh, contour, gloo, nginx, skipper, traefik, osm, gatewayapi, gatewayapi:nginx, apisix, consul or kuma.")
 	flag.StringVar(&selectorLabels, "selector-labels", "app,name,app.kubernetes.io/name", "List of pod labels that Flagger uses to create pod selectors.")
 	flag.StringVar(&ingressAnnotationsPrefix, "ingress-annotations-prefix", "nginx.ingress.kubernetes.io", "Annotations prefix for NGINX ingresses.")
 	flag.StringVar(&ingressClass, "ingress-class", "", "Ingress class used for annotating HTTPProxy objects.")
@@ -115,6 +134,20 @@ func init() {
 	flag.BoolVar(&enableConfigTracking, "enable-config-tracking", true, "Enable secrets and configmaps tracking.")
 	flag.BoolVar(&ver, "version", false, "Print version")
 	flag.StringVar(&kubeconfigServiceMesh, "kubeconfig-service-mesh", "", "Path to a kubeconfig for the service mesh control plane cluster.")
+	flag.BoolVar(&noCrossNamespaceRefs, "no-cross-namespace-refs", false, "Disable cross-namespace references to metric templates and alert providers, unless granted with allowedNamespaces.")
+	flag.BoolVar(&verifyMeshProvider, "verify-provider", false, "Run the routing conformance checks against the mesh provider and exit.")
+	flag.BoolVar(&reportSMI, "report-smi-canaries", false, "Print the canaries routed with SMI TrafficSplits and their Gateway API migration status and exit.")
+	flag.StringVar(&decisionsAPIToken, "decisions-api-token", "", "Bearer token that enables the promotion decisions API used by admission policies.")
+	flag.IntVar(&maxCanaries, "max-canaries", 0, "Max number of canaries per tenant, enforced by the admission webhook. Zero means unlimited.")
+	flag.IntVar(&maxAnalyses, "max-concurrent-analyses", 0, "Max number of canary analyses running at the same time per tenant. Zero means unlimited.")
+	flag.StringVar(&quotaTenantLabel, "quota-tenant-label", "", "Namespace label that groups namespaces into tenants for the canary quotas, defaults to one tenant per namespace.")
+	flag.StringVar(&admissionPort, "admission-port", "", "Port of the canary validating admission webhook, the webhook is disabled when empty.")
+	flag.StringVar(&admissionCertFile, "admission-tls-cert", "/etc/flagger/tls/tls.crt", "TLS certificate of the admission webhook.")
+	flag.StringVar(&admissionKeyFile, "admission-tls-key", "/etc/flagger/tls/tls.key", "TLS key of the admission webhook.")
+	flag.StringVar(&insightsTeamLabel, "insights-team-label", "", "Canary label that holds the team name of the rollout statistics, defaults to the canary namespace.")
+	flag.DurationVar(&insightsReportInterval, "insights-report-interval", 0, "Interval of the rollouts summary sent with the global notifier, e.g. 168h for a weekly report. Zero disables the report.")
+	flag.StringVar(&spiffeEndpointSocket, "spiffe-endpoint-socket", os.Getenv(spiffe.EndpointSocketEnv), "Address of the SPIFFE Workload API, e.g. unix:///run/spire/sockets/agent.sock, enables mTLS for the webhooks and metric providers that opt in.")
+	flag.StringVar(&spiffeTrustDomain, "spiffe-trust-domain", "", "SPIFFE trust domain of the webhooks and metric providers, defaults to any trust domain of the bundle.")
 }
 
 func main() {
@@ -190,6 +223,19 @@ func main() {
 		logger.Infof("Watching namespace %s", namespace)
 	}
 
+	// present the workload SVID on the outbound calls that opt in for SPIFFE mTLS
+	if spiffeEndpointSocket != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		source, err := spiffe.NewSource(ctx, spiffeEndpointSocket, spiffeTrustDomain, logger)
+		cancel()
+		if err != nil {
+			logger.Fatalf("Error fetching SPIFFE SVID: %v", err)
+		}
+		defer source.Close()
+		spiffe.SetDefault(source)
+		logger.Infof("Using SPIFFE SVID from the Workload API %s", spiffeEndpointSocket)
+	}
+
 	observerFactory, err := observers.NewFactory(metricsServer)
 	if err != nil {
 		logger.Fatalf("Error building prometheus client: %s", err.Error())
@@ -205,11 +251,47 @@ func main() {
 	// setup Slack or MS Teams notifications
 	notifierClient := initNotifier(logger)
 
+	// expose the promotion decisions to admission policies and the traffic weights to external tools
+	if token := fromEnv("DECISIONS_API_TOKEN", decisionsAPIToken); token != "" {
+		http.Handle(server.DecisionsPath, server.DecisionsHandler(flaggerClient, token, logger))
+		http.Handle(server.WeightsPath, server.WeightsHandler(flaggerClient, token, logger))
+	}
+
 	// start HTTP server
 	go server.ListenAndServe(port, 3*time.Second, logger, stopCh)
 
+	quotaChecker := quota.NewChecker(quota.Quota{
+		MaxCanaries: maxCanaries,
+		MaxAnalyses: maxAnalyses,
+		TenantLabel: quotaTenantLabel,
+	}, kubeClient, flaggerClient)
+
+	// start the canary quota admission webhook
+	if admissionPort != "" {
+		if quotaChecker == nil {
+			logger.Fatalf("The admission webhook requires -max-canaries or -max-concurrent-analyses")
+		}
+		mux := http.NewServeMux()
+		mux.Handle(quota.AdmissionPath, quotaChecker.AdmissionHandler(logger))
+		go server.ListenAndServeTLS(admissionPort, admissionCertFile, admissionKeyFile, mux, 3*time.Second, logger, stopCh)
+	}
+
 	routerFactory := router.NewFactory(cfg, kubeClient, flaggerClient, ingressAnnotationsPrefix, ingressClass, logger, meshClient)
 
+	if verifyMeshProvider {
+		if err := verifyProvider(flaggerClient, routerFactory, labels, logger); err != nil {
+			logger.Fatalf("Provider verification failed: %v", err)
+		}
+		os.Exit(0)
+	}
+
+	if reportSMI {
+		if err := reportSMICanaries(flaggerClient); err != nil {
+			logger.Fatalf("SMI canaries report failed: %v", err)
+		}
+		os.Exit(0)
+	}
+
 	var configTracker canary.Tracker
 	if enableConfigTracking {
 		configTracker = &canary.ConfigTracker{
@@ -238,6 +320,9 @@ func main() {
 		meshProvider,
 		version.VERSION,
 		fromEnv("EVENT_WEBHOOK_URL", eventWebhook),
+		noCrossNamespaceRefs,
+		quotaChecker,
+		controller.NewInsights(insightsTeamLabel, insightsReportInterval),
 	)
 
 	// leader election context
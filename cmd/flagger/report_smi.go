@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+	"github.com/fluxcd/flagger/pkg/router"
+)
+
+// reportSMICanaries prints the canaries routed with SMI TrafficSplits and their
+// Gateway API migration status, the canaries of the flipped migrations are included
+// since Flagger still writes their TrafficSplits
+func reportSMICanaries(flaggerClient clientset.Interface) error {
+	canaries, err := flaggerClient.FlaggerV1beta1().Canaries(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("canaries list error: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "NAMESPACE\tNAME\tPROVIDER\tMIGRATION\n")
+	for _, cd := range canaries.Items {
+		provider := meshProvider
+		if cd.Spec.Provider != "" {
+			provider = cd.Spec.Provider
+		}
+		status := router.MigrationStatus(&cd, provider)
+		if status == "" {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", cd.Namespace, cd.Name, provider, status)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+	"github.com/fluxcd/flagger/pkg/router"
+)
+
+// verifyProvider creates a disposable canary, runs the routing conformance checks
+// against the configured mesh provider and prints the supported features.
+// The canary targets a deployment that doesn't exist so the controller never
+// starts an analysis for it, removing it garbage collects the routing objects.
+func verifyProvider(flaggerClient clientset.Interface, routerFactory *router.Factory, labels []string, logger *zap.SugaredLogger) error {
+	ns := namespace
+	if ns == "" {
+		ns = "default"
+	}
+	name := fmt.Sprintf("flagger-verify-%s", rand.String(5))
+
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		Spec: flaggerv1.CanarySpec{
+			Provider: meshProvider,
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:       name,
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+			},
+			Service: flaggerv1.CanaryService{
+				Port: 80,
+			},
+			Analysis: &flaggerv1.CanaryAnalysis{
+				Interval:   "1m",
+				Threshold:  1,
+				MaxWeight:  50,
+				StepWeight: 10,
+			},
+		},
+	}
+
+	cd, err := flaggerClient.FlaggerV1beta1().Canaries(ns).Create(context.TODO(), cd, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("canary %s.%s create error: %w", name, ns, err)
+	}
+	defer func() {
+		err := flaggerClient.FlaggerV1beta1().Canaries(ns).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		if err != nil {
+			logger.Errorf("Canary %s.%s delete error: %v", name, ns, err)
+		}
+	}()
+	logger.Infof("Verifying provider %s with canary %s.%s", meshProvider, name, ns)
+
+	kubeRouter := routerFactory.KubernetesRouter(cd.Spec.TargetRef.Kind, labels[0], name, nil)
+	meshRouter := routerFactory.MeshRouter(meshProvider, labels[0])
+	results, err := router.CheckConformance(kubeRouter, meshRouter, cd)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "FEATURE\tSUPPORTED\tMESSAGE\n")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%t\t%s\n", r.Feature, r.Supported, r.Message)
+	}
+	return w.Flush()
+}
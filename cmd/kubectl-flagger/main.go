@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-flagger is a kubectl plugin for opening and closing the local gates
+// that confirm webhooks can target with gate://<name> URLs.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/fluxcd/flagger/pkg/gate"
+)
+
+const usage = `Usage:
+  kubectl flagger gate open <name> [-n namespace] [--ttl duration]
+  kubectl flagger gate close <name> [-n namespace]
+  kubectl flagger gate status <name> [-n namespace]
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) < 3 || args[0] != "gate" {
+		return fmt.Errorf("invalid arguments\n%s", usage)
+	}
+	action, name := args[1], args[2]
+
+	fs := flag.NewFlagSet("gate", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	var namespace, kubeconfig string
+	var ttl time.Duration
+	fs.StringVar(&namespace, "n", "", "Namespace of the canary, defaults to the current context namespace.")
+	fs.StringVar(&namespace, "namespace", "", "Namespace of the canary, defaults to the current context namespace.")
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig.")
+	fs.DurationVar(&ttl, "ttl", 0, "Close the gate automatically after this duration.")
+	if err := fs.Parse(args[3:]); err != nil {
+		return err
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{})
+	if namespace == "" {
+		ns, _, err := clientConfig.Namespace()
+		if err != nil {
+			return fmt.Errorf("error reading the current namespace: %w", err)
+		}
+		namespace = ns
+	}
+	cfg, err := clientConfig.ClientConfig()
+	if err != nil {
+		return fmt.Errorf("error building kubeconfig: %w", err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("error building kubernetes clientset: %w", err)
+	}
+
+	ctx := context.Background()
+	switch action {
+	case "open":
+		if err := gate.Open(ctx, kubeClient, namespace, name, ttl); err != nil {
+			return err
+		}
+		fmt.Printf("gate %s.%s opened\n", name, namespace)
+	case "close":
+		if err := gate.Close(ctx, kubeClient, namespace, name); err != nil {
+			return err
+		}
+		fmt.Printf("gate %s.%s closed\n", name, namespace)
+	case "status":
+		open, err := gate.IsOpen(ctx, kubeClient, namespace, name)
+		if err != nil {
+			return err
+		}
+		state := "closed"
+		if open {
+			state = "open"
+		}
+		fmt.Printf("gate %s.%s is %s\n", name, namespace, state)
+	default:
+		return fmt.Errorf("unknown gate action %q", action)
+	}
+	return nil
+}
@@ -29,11 +29,13 @@ import (
 
 var VERSION = "0.21.0"
 var (
-	logLevel          string
-	port              string
-	timeout           time.Duration
-	zapReplaceGlobals bool
-	zapEncoding       string
+	logLevel           string
+	port               string
+	timeout            time.Duration
+	zapReplaceGlobals  bool
+	zapEncoding        string
+	gateTokensFile     string
+	gateAdminTokenFile string
 )
 
 func init() {
@@ -42,6 +44,8 @@ func init() {
 	flag.DurationVar(&timeout, "timeout", time.Hour, "Load test exec timeout.")
 	flag.BoolVar(&zapReplaceGlobals, "zap-replace-globals", false, "Whether to change the logging level of the global zap logger.")
 	flag.StringVar(&zapEncoding, "zap-encoding", "json", "Zap logger encoding.")
+	flag.StringVar(&gateTokensFile, "gate-tokens-file", "", "JSON file mapping the canary <name>.<namespace> to the token required to open and close its gates.")
+	flag.StringVar(&gateAdminTokenFile, "gate-admin-token-file", "", "File containing the token required to read the audit log of all the gates.")
 }
 
 func main() {
@@ -66,5 +70,15 @@ func main() {
 	logger.Infof("Starting load tester v%s API on port %s", VERSION, port)
 
 	gateStorage := loadtester.NewGateStorage("in-memory")
+	if gateTokensFile != "" {
+		if err := gateStorage.LoadTokens(gateTokensFile); err != nil {
+			logger.Fatalf("Error loading gate tokens: %v", err)
+		}
+	}
+	if gateAdminTokenFile != "" {
+		if err := gateStorage.LoadAdminToken(gateAdminTokenFile); err != nil {
+			logger.Fatalf("Error loading gate admin token: %v", err)
+		}
+	}
 	loadtester.ListenAndServe(port, time.Minute, logger, taskRunner, gateStorage, stopCh)
 }
@@ -0,0 +1,5 @@
+package apisix
+
+const (
+	GroupName = "apisix.apache.org"
+)
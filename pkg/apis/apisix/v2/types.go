@@ -0,0 +1,183 @@
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApisixRoute is used to define the route rules and upstreams for Apache APISIX
+type ApisixRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ApisixRouteSpec `json:"spec"`
+	Status ApisixStatus    `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApisixRouteList contains a list of ApisixRoute
+type ApisixRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ApisixRoute `json:"items"`
+}
+
+// ApisixRouteSpec is the spec definition for ApisixRouteSpec
+type ApisixRouteSpec struct {
+	// +optional
+	HTTP []ApisixRouteHTTP `json:"http,omitempty"`
+}
+
+// ApisixRouteHTTP represents a single route in for HTTP traffic
+type ApisixRouteHTTP struct {
+	// The rule name, cannot be empty
+	Name string `json:"name"`
+	// Route priority, when multiple routes contains
+	// same URI path (for path matching), route with
+	// higher priority will take effect
+	// +optional
+	Priority int `json:"priority,omitempty"`
+	// +optional
+	Timeout *UpstreamTimeout `json:"timeout,omitempty"`
+	// +optional
+	Match *ApisixRouteHTTPMatch `json:"match,omitempty"`
+	// Backends represents potential backends to proxy after the route
+	// rule matched, the traffic is split between the backends by weight
+	// +optional
+	Backends []ApisixRouteHTTPBackend `json:"backends,omitempty"`
+	// +optional
+	Websocket bool `json:"websocket,omitempty"`
+	// +optional
+	PluginConfigName string `json:"plugin_config_name,omitempty"`
+	// +optional
+	Plugins []ApisixRoutePlugin `json:"plugins,omitempty"`
+	// +optional
+	Authentication *ApisixRouteAuthentication `json:"authentication,omitempty"`
+}
+
+// UpstreamTimeout is settings for the read, send and connect to the upstream
+type UpstreamTimeout struct {
+	// +optional
+	Connect metav1.Duration `json:"connect,omitempty"`
+	// +optional
+	Send metav1.Duration `json:"send,omitempty"`
+	// +optional
+	Read metav1.Duration `json:"read,omitempty"`
+}
+
+// ApisixRouteHTTPMatch represents the match condition for hitting this route
+type ApisixRouteHTTPMatch struct {
+	// URI path predicates, at least one path should be
+	// configured, path could be exact or prefix, for prefix path,
+	// append "*" after it, for instance, "/foo*"
+	// +optional
+	Paths []string `json:"paths,omitempty"`
+	// HTTP request method predicates
+	// +optional
+	Methods []string `json:"methods,omitempty"`
+	// HTTP Host predicates, host can be a wildcard domain or
+	// an exact domain, for wildcard domain, only one generic
+	// level is allowed, for instance, "*.foo.com" is valid but
+	// "*.*.foo.com" is not
+	// +optional
+	Hosts []string `json:"hosts,omitempty"`
+	// Remote address predicates, items can be valid IPv4 address
+	// or IPv6 address or CIDR
+	// +optional
+	RemoteAddrs []string `json:"remoteAddrs,omitempty"`
+	// NginxVars represents generic match predicates,
+	// it uses Nginx variable systems, so any predicate
+	// like headers, querystring and etc can be leveraged
+	// here to match the route
+	// +optional
+	NginxVars []ApisixRouteHTTPMatchExpr `json:"exprs,omitempty"`
+}
+
+// ApisixRouteHTTPMatchExpr represents a binary route match expression
+type ApisixRouteHTTPMatchExpr struct {
+	// Subject is the expression subject, it can
+	// be any string composed by literals and nginx
+	// vars
+	Subject ApisixRouteHTTPMatchExprSubject `json:"subject"`
+	// Op is the operator
+	Op string `json:"op"`
+	// Set is an array type object of the expression
+	// It should be used when the Op is "in" or "not_in"
+	// +optional
+	Set []string `json:"set,omitempty"`
+	// Value is the normal type object for the expression,
+	// it should be used when the Op is not "in" and "not_in"
+	// +optional
+	Value *string `json:"value,omitempty"`
+}
+
+// ApisixRouteHTTPMatchExprSubject describes the route match expression subject
+type ApisixRouteHTTPMatchExprSubject struct {
+	// The subject scope, can be: ScopeQuery, ScopeHeader, ScopePath
+	// when subject is ScopePath, Name field will be ignored
+	Scope string `json:"scope"`
+	// The name of subject
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// ApisixRouteHTTPBackend represents an HTTP backend (a Kubernetes Service)
+type ApisixRouteHTTPBackend struct {
+	// The name (short) of the service, note cross namespace is forbidden,
+	// so be sure the ApisixRoute and Service are in the same namespace
+	ServiceName string `json:"serviceName"`
+	// The service port, could be the name or the port number
+	ServicePort intstr.IntOrString `json:"servicePort"`
+	// The resolve granularity, can be "endpoints" or "service",
+	// when set to "endpoints", the pod ips will be used; other
+	// wise, the service ClusterIP or ExternalIP will be used,
+	// default is endpoints
+	// +optional
+	ResolveGranularity string `json:"resolveGranularity,omitempty"`
+	// Weight of this backend
+	// +optional
+	Weight *int `json:"weight,omitempty"`
+	// Subset specifies a subset for the target Service. The subset should be pre-defined
+	// in ApisixUpstream about this service
+	// +optional
+	Subset string `json:"subset,omitempty"`
+}
+
+// ApisixRoutePlugin represents an APISIX plugin
+type ApisixRoutePlugin struct {
+	// The plugin name
+	Name string `json:"name"`
+	// Whether this plugin is in use, default is true
+	Enable bool `json:"enable"`
+	// Plugin configuration
+	// +optional
+	Config *runtime.RawExtension `json:"config,omitempty"`
+}
+
+// ApisixRouteAuthentication is the authentication-related
+// configuration in ApisixRoute
+type ApisixRouteAuthentication struct {
+	Enable bool   `json:"enable"`
+	Type   string `json:"type"`
+	// +optional
+	KeyAuth ApisixRouteAuthenticationKeyAuth `json:"keyAuth,omitempty"`
+}
+
+// ApisixRouteAuthenticationKeyAuth is the keyAuth-related
+// configuration in ApisixRouteAuthentication
+type ApisixRouteAuthenticationKeyAuth struct {
+	// +optional
+	Header string `json:"header,omitempty"`
+}
+
+// ApisixStatus is the status report for Apisix ingress Resources
+type ApisixStatus struct {
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
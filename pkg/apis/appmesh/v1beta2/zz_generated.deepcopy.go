@@ -234,6 +234,67 @@ func (in *GRPCConnectionPool) DeepCopy() *GRPCConnectionPool {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCGatewayRoute) DeepCopyInto(out *GRPCGatewayRoute) {
+	*out = *in
+	in.Match.DeepCopyInto(&out.Match)
+	in.Action.DeepCopyInto(&out.Action)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCGatewayRoute.
+func (in *GRPCGatewayRoute) DeepCopy() *GRPCGatewayRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(GRPCGatewayRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCGatewayRouteAction) DeepCopyInto(out *GRPCGatewayRouteAction) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCGatewayRouteAction.
+func (in *GRPCGatewayRouteAction) DeepCopy() *GRPCGatewayRouteAction {
+	if in == nil {
+		return nil
+	}
+	out := new(GRPCGatewayRouteAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCGatewayRouteMatch) DeepCopyInto(out *GRPCGatewayRouteMatch) {
+	*out = *in
+	if in.ServiceName != nil {
+		in, out := &in.ServiceName, &out.ServiceName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Hostname != nil {
+		in, out := &in.Hostname, &out.Hostname
+		*out = new(GatewayRouteHostnameMatch)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCGatewayRouteMatch.
+func (in *GRPCGatewayRouteMatch) DeepCopy() *GRPCGatewayRouteMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(GRPCGatewayRouteMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GRPCRetryPolicy) DeepCopyInto(out *GRPCRetryPolicy) {
 	*out = *in
@@ -443,6 +504,255 @@ func (in *GRPCTimeout) DeepCopy() *GRPCTimeout {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayRoute) DeepCopyInto(out *GatewayRoute) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayRoute.
+func (in *GatewayRoute) DeepCopy() *GatewayRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GatewayRoute) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayRouteCondition) DeepCopyInto(out *GatewayRouteCondition) {
+	*out = *in
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Reason != nil {
+		in, out := &in.Reason, &out.Reason
+		*out = new(string)
+		**out = **in
+	}
+	if in.Message != nil {
+		in, out := &in.Message, &out.Message
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayRouteCondition.
+func (in *GatewayRouteCondition) DeepCopy() *GatewayRouteCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayRouteCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayRouteHostnameMatch) DeepCopyInto(out *GatewayRouteHostnameMatch) {
+	*out = *in
+	if in.Exact != nil {
+		in, out := &in.Exact, &out.Exact
+		*out = new(string)
+		**out = **in
+	}
+	if in.Suffix != nil {
+		in, out := &in.Suffix, &out.Suffix
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayRouteHostnameMatch.
+func (in *GatewayRouteHostnameMatch) DeepCopy() *GatewayRouteHostnameMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayRouteHostnameMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayRouteList) DeepCopyInto(out *GatewayRouteList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GatewayRoute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayRouteList.
+func (in *GatewayRouteList) DeepCopy() *GatewayRouteList {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayRouteList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GatewayRouteList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayRouteSpec) DeepCopyInto(out *GatewayRouteSpec) {
+	*out = *in
+	if in.AWSName != nil {
+		in, out := &in.AWSName, &out.AWSName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int64)
+		**out = **in
+	}
+	if in.GRPCRoute != nil {
+		in, out := &in.GRPCRoute, &out.GRPCRoute
+		*out = new(GRPCGatewayRoute)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPRoute != nil {
+		in, out := &in.HTTPRoute, &out.HTTPRoute
+		*out = new(HTTPGatewayRoute)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTP2Route != nil {
+		in, out := &in.HTTP2Route, &out.HTTP2Route
+		*out = new(HTTPGatewayRoute)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VirtualGatewayRef != nil {
+		in, out := &in.VirtualGatewayRef, &out.VirtualGatewayRef
+		*out = new(VirtualGatewayReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MeshRef != nil {
+		in, out := &in.MeshRef, &out.MeshRef
+		*out = new(MeshReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayRouteSpec.
+func (in *GatewayRouteSpec) DeepCopy() *GatewayRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayRouteStatus) DeepCopyInto(out *GatewayRouteStatus) {
+	*out = *in
+	if in.GatewayRouteARN != nil {
+		in, out := &in.GatewayRouteARN, &out.GatewayRouteARN
+		*out = new(string)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]GatewayRouteCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ObservedGeneration != nil {
+		in, out := &in.ObservedGeneration, &out.ObservedGeneration
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayRouteStatus.
+func (in *GatewayRouteStatus) DeepCopy() *GatewayRouteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayRouteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayRouteTarget) DeepCopyInto(out *GatewayRouteTarget) {
+	*out = *in
+	in.VirtualService.DeepCopyInto(&out.VirtualService)
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayRouteTarget.
+func (in *GatewayRouteTarget) DeepCopy() *GatewayRouteTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayRouteTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayRouteVirtualService) DeepCopyInto(out *GatewayRouteVirtualService) {
+	*out = *in
+	if in.VirtualServiceRef != nil {
+		in, out := &in.VirtualServiceRef, &out.VirtualServiceRef
+		*out = new(VirtualServiceReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VirtualServiceARN != nil {
+		in, out := &in.VirtualServiceARN, &out.VirtualServiceARN
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayRouteVirtualService.
+func (in *GatewayRouteVirtualService) DeepCopy() *GatewayRouteVirtualService {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayRouteVirtualService)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTP2ConnectionPool) DeepCopyInto(out *HTTP2ConnectionPool) {
 	*out = *in
@@ -480,6 +790,67 @@ func (in *HTTPConnectionPool) DeepCopy() *HTTPConnectionPool {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPGatewayRoute) DeepCopyInto(out *HTTPGatewayRoute) {
+	*out = *in
+	in.Match.DeepCopyInto(&out.Match)
+	in.Action.DeepCopyInto(&out.Action)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPGatewayRoute.
+func (in *HTTPGatewayRoute) DeepCopy() *HTTPGatewayRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPGatewayRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPGatewayRouteAction) DeepCopyInto(out *HTTPGatewayRouteAction) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPGatewayRouteAction.
+func (in *HTTPGatewayRouteAction) DeepCopy() *HTTPGatewayRouteAction {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPGatewayRouteAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPGatewayRouteMatch) DeepCopyInto(out *HTTPGatewayRouteMatch) {
+	*out = *in
+	if in.Prefix != nil {
+		in, out := &in.Prefix, &out.Prefix
+		*out = new(string)
+		**out = **in
+	}
+	if in.Hostname != nil {
+		in, out := &in.Hostname, &out.Hostname
+		*out = new(GatewayRouteHostnameMatch)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPGatewayRouteMatch.
+func (in *HTTPGatewayRouteMatch) DeepCopy() *HTTPGatewayRouteMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPGatewayRouteMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPRetryPolicy) DeepCopyInto(out *HTTPRetryPolicy) {
 	*out = *in
@@ -0,0 +1,5 @@
+package consul
+
+const (
+	GroupName = "consul.hashicorp.com"
+)
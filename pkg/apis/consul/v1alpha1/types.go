@@ -0,0 +1,174 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceResolver is the Schema for the serviceresolvers API
+type ServiceResolver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ServiceResolverSpec `json:"spec,omitempty"`
+	Status Status              `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceResolverList contains a list of ServiceResolver
+type ServiceResolverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ServiceResolver `json:"items"`
+}
+
+// ServiceResolverSpec defines the desired state of ServiceResolver
+type ServiceResolverSpec struct {
+	// DefaultSubset is the subset to use when no explicit subset is requested.
+	// If empty the unnamed subset is used.
+	// +optional
+	DefaultSubset string `json:"defaultSubset,omitempty"`
+
+	// Subsets is map of subset name to subset definition for all usable named
+	// subsets of this service.
+	// +optional
+	Subsets map[string]ServiceResolverSubset `json:"subsets,omitempty"`
+
+	// Redirect when configured, all attempts to resolve the service this
+	// resolver defines will be substituted for the supplied redirect.
+	// +optional
+	Redirect *ServiceResolverRedirect `json:"redirect,omitempty"`
+
+	// Failover controls when and how to reroute traffic to an alternate pool of
+	// service instances, the map is keyed by the service subset it applies to
+	// and the special string "*" is a wildcard that applies to any subset.
+	// +optional
+	Failover map[string]ServiceResolverFailover `json:"failover,omitempty"`
+
+	// ConnectTimeout is the timeout for establishing new network connections
+	// to this service.
+	// +optional
+	ConnectTimeout metav1.Duration `json:"connectTimeout,omitempty"`
+}
+
+// ServiceResolverSubset defines a named subset of the service instances
+type ServiceResolverSubset struct {
+	// Filter is the filter expression to be used for selecting instances of the
+	// requested service.
+	// +optional
+	Filter string `json:"filter,omitempty"`
+
+	// OnlyPassing specifies the behavior of the resolver's health check
+	// interpretation, if true instances with warning health checks are excluded.
+	// +optional
+	OnlyPassing bool `json:"onlyPassing,omitempty"`
+}
+
+// ServiceResolverRedirect defines the service the resolution is redirected to
+type ServiceResolverRedirect struct {
+	// +optional
+	Service string `json:"service,omitempty"`
+	// +optional
+	ServiceSubset string `json:"serviceSubset,omitempty"`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// +optional
+	Datacenter string `json:"datacenter,omitempty"`
+}
+
+// ServiceResolverFailover defines the alternate pool of service instances
+type ServiceResolverFailover struct {
+	// Service is the service to resolve instead of the default as the failover group of instances.
+	// +optional
+	Service string `json:"service,omitempty"`
+	// +optional
+	ServiceSubset string `json:"serviceSubset,omitempty"`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// +optional
+	Datacenters []string `json:"datacenters,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceSplitter is the Schema for the servicesplitters API
+type ServiceSplitter struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ServiceSplitterSpec `json:"spec,omitempty"`
+	Status Status              `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceSplitterList contains a list of ServiceSplitter
+type ServiceSplitterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ServiceSplitter `json:"items"`
+}
+
+// ServiceSplitterSpec defines the desired state of ServiceSplitter
+type ServiceSplitterSpec struct {
+	// Splits is a list of traffic splits, the weights must sum to 100
+	Splits []ServiceSplit `json:"splits,omitempty"`
+}
+
+// ServiceSplit defines how much traffic to send to which set of service instances
+type ServiceSplit struct {
+	// Weight is a value between 0 and 100 reflecting what portion of traffic should be directed to this split
+	Weight float32 `json:"weight"`
+
+	// Service is the service to resolve instead of the default
+	// +optional
+	Service string `json:"service,omitempty"`
+
+	// ServiceSubset is a named subset of the given service to resolve instead of the one defined
+	// as that service's DefaultSubset
+	// +optional
+	ServiceSubset string `json:"serviceSubset,omitempty"`
+
+	// Namespace is the Consul namespace to resolve the service from instead of the current namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Status defines the sync state of a config entry with Consul
+type Status struct {
+	// Conditions indicate the latest available observations of a resource's current state.
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// LastSyncedTime is the last time the resource successfully synced with Consul.
+	// +optional
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+}
+
+// Condition represents an observation of an object's state
+type Condition struct {
+	// Type of condition.
+	Type string `json:"type"`
+
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+
+	// LastTransitionTime is the last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// The reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
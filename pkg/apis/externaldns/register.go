@@ -0,0 +1,5 @@
+package externaldns
+
+const (
+	GroupName = "externaldns.k8s.io"
+)
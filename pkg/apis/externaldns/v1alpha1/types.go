@@ -0,0 +1,69 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DNSEndpoint is a contract between external-dns and the controllers
+// that want to publish DNS records.
+type DNSEndpoint struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DNSEndpointSpec   `json:"spec,omitempty"`
+	Status DNSEndpointStatus `json:"status,omitempty"`
+}
+
+// DNSEndpointSpec defines the desired state of DNSEndpoint.
+type DNSEndpointSpec struct {
+	Endpoints []*Endpoint `json:"endpoints,omitempty"`
+}
+
+// DNSEndpointStatus defines the observed state of DNSEndpoint.
+type DNSEndpointStatus struct {
+	// The generation observed by the external-dns controller.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// Endpoint is a high-level way of a connection between a service and an IP.
+type Endpoint struct {
+	// The hostname of the DNS record.
+	DNSName string `json:"dnsName,omitempty"`
+
+	// The targets the DNS record points to.
+	Targets []string `json:"targets,omitempty"`
+
+	// RecordType type of record, e.g. CNAME, A, SRV, TXT etc.
+	RecordType string `json:"recordType,omitempty"`
+
+	// Identifier to distinguish multiple records with the same name and type.
+	SetIdentifier string `json:"setIdentifier,omitempty"`
+
+	// TTL for the record in seconds.
+	RecordTTL int64 `json:"recordTTL,omitempty"`
+
+	// Labels stores labels defined for the Endpoint.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// ProviderSpecific stores provider specific config.
+	ProviderSpecific []ProviderSpecificProperty `json:"providerSpecific,omitempty"`
+}
+
+// ProviderSpecificProperty holds the name and value of a configuration which is specific to individual DNS providers.
+type ProviderSpecificProperty struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DNSEndpointList is a list of DNSEndpoint objects.
+type DNSEndpointList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []DNSEndpoint `json:"items"`
+}
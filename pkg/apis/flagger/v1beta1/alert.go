@@ -71,6 +71,11 @@ type AlertProviderSpec struct {
 	// Secret reference containing the provider webhook URL
 	// +optional
 	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// AllowedNamespaces is the list of namespaces of the canaries
+	// that can reference this provider, the wildcard * allows all namespaces
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
 }
 
 type AlertProviderStatus struct {
@@ -18,9 +18,15 @@ package v1beta1
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	gatewayapiv1alpha2 "github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1alpha2"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
 	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+	nginxgatewayv1alpha1 "github.com/fluxcd/flagger/pkg/apis/nginxgateway/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -31,6 +37,10 @@ const (
 	AnalysisInterval        = 60 * time.Second
 	PrimaryReadyThreshold   = 100
 	MetricInterval          = "1m"
+	MigrationTimeout        = 10 * time.Minute
+	JWTClaimHeaderPrefix    = "x-jwt-claim-"
+	JWTClaimMatchPrefix     = "@request.auth.claims."
+	GeoHeader               = "cloudfront-viewer-country"
 )
 
 // +genclient
@@ -78,6 +88,11 @@ type CanarySpec struct {
 	// +optional
 	IngressRef *CrossNamespaceObjectReference `json:"ingressRef,omitempty"`
 
+	// Reference to APISIX route resource, the HTTP rules routing to the apex service
+	// are copied to the route generated by flagger
+	// +optional
+	RouteRef *CrossNamespaceObjectReference `json:"routeRef,omitempty"`
+
 	// Reference to Gloo Upstream resource. Upstream config is copied from
 	// the referenced upstream to the upstreams generated by flagger.
 	// +optional
@@ -101,9 +116,245 @@ type CanarySpec struct {
 	// +optional
 	SkipAnalysis bool `json:"skipAnalysis,omitempty"`
 
+	// Suspend pauses the canary runs, an analysis in progress is held
+	// at its current traffic weight until the canary is resumed
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
 	// revert canary mutation on deletion of canary resource
 	// +optional
 	RevertOnDeletion bool `json:"revertOnDeletion,omitempty"`
+
+	// Migration defines the Kubernetes jobs that run before routing traffic
+	// to the canary and after a rollback
+	// +optional
+	Migration *CanaryMigration `json:"migration,omitempty"`
+
+	// ScaleDown defines the canary scale down behaviour after promotion
+	// +optional
+	ScaleDown *CanaryScaleDown `json:"scaleDown,omitempty"`
+
+	// CapacityCheck verifies the cluster capacity before scaling up the canary
+	// +optional
+	CapacityCheck *CanaryCapacityCheck `json:"capacityCheck,omitempty"`
+
+	// Primary defines the primary workload overrides applied on promotion
+	// +optional
+	Primary *CanaryPrimary `json:"primary,omitempty"`
+
+	// Monitor generates Prometheus Operator monitors for the primary and canary pods
+	// +optional
+	Monitor *CanaryMonitor `json:"monitor,omitempty"`
+
+	// PodMetadata injects the role and analysis metadata into the primary and canary pods
+	// +optional
+	PodMetadata *CanaryPodMetadata `json:"podMetadata,omitempty"`
+
+	// DNS publishes a dedicated canary hostname through external-dns while the analysis is running
+	// +optional
+	DNS *CanaryDNS `json:"dns,omitempty"`
+
+	// Preview creates an isolated stack that exposes the canary on a dedicated host while the analysis is running
+	// +optional
+	Preview *CanaryPreview `json:"preview,omitempty"`
+
+	// Bootstrap defines how the traffic of an existing service is moved to the primary on initialization
+	// +optional
+	Bootstrap *CanaryBootstrap `json:"bootstrap,omitempty"`
+
+	// Worker defines how the consumers of a queue worker are split between primary and canary
+	// when using the keda provider
+	// +optional
+	Worker *CanaryWorker `json:"worker,omitempty"`
+}
+
+// BootstrapMode defines how the canary is initialized
+type BootstrapMode string
+
+const (
+	// DefaultBootstrap scales down the workload as soon as the primary is ready
+	DefaultBootstrap BootstrapMode = "default"
+	// TakeoverBootstrap switches the live traffic to the primary
+	// and verifies it before scaling down the workload
+	TakeoverBootstrap BootstrapMode = "takeover"
+)
+
+// CanaryBootstrap defines the initialization of the canary
+type CanaryBootstrap struct {
+	// Mode can be default or takeover
+	// Defaults to default
+	// +optional
+	Mode BootstrapMode `json:"mode,omitempty"`
+}
+
+// CanaryWorker defines the traffic-less canary of a queue consumer,
+// the canary weight is expressed as the ratio of canary consumers
+type CanaryWorker struct {
+	// Partitions is the number of partitions of the consumed topic,
+	// when set the consumers are capped to one per partition and the
+	// canary weight is rounded to whole partitions
+	// +optional
+	Partitions int32 `json:"partitions,omitempty"`
+}
+
+// CanaryPodMetadata defines how the role and analysis metadata are injected into the pods
+type CanaryPodMetadata struct {
+	// Env injects the FLAGGER_ROLE, FLAGGER_RUN_ID and FLAGGER_INITIAL_WEIGHT environment variables
+	// +optional
+	Env bool `json:"env,omitempty"`
+
+	// Labels injects the flagger.app/role and flagger.app/run-id pod labels
+	// +optional
+	Labels bool `json:"labels,omitempty"`
+}
+
+// CanaryDNS defines the external-dns DNSEndpoint of the canary preview host
+type CanaryDNS struct {
+	// Hostname of the canary preview host
+	Hostname string `json:"hostname"`
+
+	// Targets of the DNS record, e.g. the ingress load balancer address
+	Targets []string `json:"targets"`
+
+	// RecordType of the DNS record
+	// Defaults to A for IP targets and to CNAME otherwise
+	// +optional
+	RecordType string `json:"recordType,omitempty"`
+
+	// TTL of the DNS record in seconds
+	// +optional
+	TTL int64 `json:"ttl,omitempty"`
+
+	// Labels added to the DNSEndpoint, used by external-dns to select it
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// CanaryPreview defines the ingress and the dependency stubs of the canary preview stack
+type CanaryPreview struct {
+	// Host routed to the canary service by the preview ingress
+	Host string `json:"host"`
+
+	// IngressClassName of the preview ingress
+	// +optional
+	IngressClassName string `json:"ingressClassName,omitempty"`
+
+	// StubsRef references a config map holding the manifests of the dependency stubs,
+	// the supported kinds are Deployment, Service and ConfigMap
+	// +optional
+	StubsRef *CrossNamespaceObjectReference `json:"stubsRef,omitempty"`
+}
+
+// CanaryMonitor defines the Prometheus Operator ServiceMonitor or PodMonitor
+// generated for the primary and canary workloads
+type CanaryMonitor struct {
+	// Kind of the monitor, can be ServiceMonitor or PodMonitor
+	// Defaults to ServiceMonitor
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Port name of the metrics endpoint
+	// Defaults to the canary service port name
+	// +optional
+	Port string `json:"port,omitempty"`
+
+	// Path of the metrics endpoint
+	// Defaults to /metrics
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Interval at which metrics are scraped
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// Labels added to the monitors, used by Prometheus to select them
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// CanaryPrimary defines the overrides applied to the primary workload
+// when the canary spec is copied over
+type CanaryPrimary struct {
+	// ResourcesMultiplier scales the canary containers requests and limits, e.g. "4" or "1.5"
+	// +optional
+	ResourcesMultiplier string `json:"resourcesMultiplier,omitempty"`
+
+	// Containers replaces the resources of the named primary containers,
+	// takes precedence over the multiplier
+	// +optional
+	Containers []CanaryPrimaryContainer `json:"containers,omitempty"`
+
+	// Scheduling defines how the pod affinity and topology spread selectors
+	// targeting the canary pods are rewritten for the primary
+	// +optional
+	Scheduling *CanaryPrimaryScheduling `json:"scheduling,omitempty"`
+}
+
+// CanaryPrimaryScheduling defines the rewrite of the label selectors of the
+// pod affinity, anti-affinity and topology spread constraints, the values
+// matching the canary are suffixed with -primary and the values matching
+// the primary lose their suffix
+type CanaryPrimaryScheduling struct {
+	// LabelKeys of the rewritten selectors
+	// Defaults to the selector labels of the Flagger controller
+	// +optional
+	LabelKeys []string `json:"labelKeys,omitempty"`
+
+	// Disabled copies the selectors to the primary unchanged
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// CanaryPrimaryContainer defines the resources of a primary container
+type CanaryPrimaryContainer struct {
+	// Name of the container
+	Name string `json:"name"`
+
+	// Resources of the primary container
+	Resources corev1.ResourceRequirements `json:"resources"`
+}
+
+// CanaryCapacityCheck defines the cluster capacity pre-flight check
+// run before the canary is scaled up
+type CanaryCapacityCheck struct {
+	// Max number of unschedulable pods in the cluster, defaults to zero
+	// +optional
+	MaxPendingPods int `json:"maxPendingPods,omitempty"`
+
+	// Promotion includes the primary replicas rolled out during promotion
+	// in the required capacity
+	// +optional
+	Promotion bool `json:"promotion,omitempty"`
+}
+
+// CanaryScaleDown defines the canary scale down behaviour after promotion
+type CanaryScaleDown struct {
+	// Delay of the scale down after the promotion has finished
+	// +optional
+	Delay string `json:"delay,omitempty"`
+
+	// Replicas to keep running after promotion, defaults to zero
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Disabled leaves the canary workload untouched after promotion
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// CanaryMigration defines the database migration jobs of a canary release
+type CanaryMigration struct {
+	// Timeout for the up migration job to complete
+	// Defaults to 10m
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+
+	// Up is the job template executed before routing traffic to the canary
+	Up batchv1.JobTemplateSpec `json:"up"`
+
+	// Down is the job template executed after the canary has been rolled back
+	// +optional
+	Down *batchv1.JobTemplateSpec `json:"down,omitempty"`
 }
 
 // CanaryService defines how ClusterIP services, service mesh or ingress routing objects are generated
@@ -126,6 +377,16 @@ type CanaryService struct {
 	// +optional
 	TargetPort intstr.IntOrString `json:"targetPort,omitempty"`
 
+	// Ports are the additional named ports of the generated Kubernetes services,
+	// the traffic of all ports is shifted with the same weights
+	// +optional
+	Ports []CanaryServicePort `json:"ports,omitempty"`
+
+	// Type of the generated apex Kubernetes service
+	// Defaults to the type of the existing service or to ClusterIP
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
 	// PortDiscovery adds all container ports to the generated Kubernetes service
 	PortDiscovery bool `json:"portDiscovery"`
 
@@ -138,21 +399,50 @@ type CanaryService struct {
 	// +optional
 	Gateways []string `json:"gateways,omitempty"`
 
-	// Hosts attached to the generated Istio virtual service
-	// Defaults to the service name
+	// Hosts attached to the generated Istio virtual service or Gateway API HTTPRoute
+	// Defaults to the service name for Istio
 	// +optional
 	Hosts []string `json:"hosts,omitempty"`
 
+	// GatewayRefs attached to the generated Gateway API HTTPRoute
+	// +optional
+	GatewayRefs []gatewayapiv1alpha2.ParentReference `json:"gatewayRefs,omitempty"`
+
+	// GatewayAPIMigration writes the traffic weights to both the SMI TrafficSplit and the
+	// Gateway API HTTPRoute, so that the provider can be switched without restarting the analysis
+	// +optional
+	GatewayAPIMigration *CanaryGatewayAPIMigration `json:"gatewayAPIMigration,omitempty"`
+
+	// NGINXGateway policies attached to the generated Gateway API HTTPRoute
+	// when the provider is gatewayapi:nginx
+	// +optional
+	NGINXGateway *NGINXGatewayPolicies `json:"nginxGateway,omitempty"`
+
 	// If enabled, Flagger would generate Istio VirtualServices without hosts and gateway,
 	// making the service compatible with Istio delegation. Note that pilot env
 	// `PILOT_ENABLE_VIRTUAL_SERVICE_DELEGATE` must also be set.
 	// +optional
 	Delegation bool `json:"delegation,omitempty"`
 
+	// RoutingSnapshot enables saving the routing objects in a config map before each
+	// traffic change, the snapshot is used to restore them when the change fails
+	// +optional
+	RoutingSnapshot bool `json:"routingSnapshot,omitempty"`
+
 	// TrafficPolicy attached to the generated Istio destination rules
 	// +optional
 	TrafficPolicy *istiov1alpha3.TrafficPolicy `json:"trafficPolicy,omitempty"`
 
+	// CanaryTrafficPolicy overrides the TrafficPolicy fields of the generated Istio canary destination rule
+	// +optional
+	CanaryTrafficPolicy *istiov1alpha3.TrafficPolicy `json:"canaryTrafficPolicy,omitempty"`
+
+	// AnalysisTrafficPolicy overrides the TrafficPolicy fields of the generated Istio canary destination rule
+	// while the analysis is running, it can be used to apply a tighter outlier detection and circuit breaking
+	// until the canary is promoted
+	// +optional
+	AnalysisTrafficPolicy *istiov1alpha3.TrafficPolicy `json:"analysisTrafficPolicy,omitempty"`
+
 	// URI match conditions for the generated service
 	// +optional
 	Match []istiov1alpha3.HTTPMatchRequest `json:"match,omitempty"`
@@ -169,6 +459,11 @@ type CanaryService struct {
 	// +optional
 	Headers *istiov1alpha3.Headers `json:"headers,omitempty"`
 
+	// CanaryHeaders marks the requests routed to the canary,
+	// so that the downstream logs and traces can be filtered by canary membership
+	// +optional
+	CanaryHeaders *CanaryHeaders `json:"canaryHeaders,omitempty"`
+
 	// Cross-Origin Resource Sharing policy for the generated Istio virtual service
 	// +optional
 	CorsPolicy *istiov1alpha3.CorsPolicy `json:"corsPolicy,omitempty"`
@@ -181,6 +476,15 @@ type CanaryService struct {
 	// +optional
 	Backends []string `json:"backends,omitempty"`
 
+	// GatewayRoute generates an App Mesh gateway route that sends the
+	// virtual gateway traffic to the generated virtual service
+	// +optional
+	GatewayRoute *CanaryGatewayRoute `json:"gatewayRoute,omitempty"`
+
+	// Protocol settings of the generated Istio, Contour and Traefik routes
+	// +optional
+	Protocol *CanaryProtocol `json:"protocol,omitempty"`
+
 	// Apex is metadata to add to the apex service
 	// +optional
 	Apex *CustomMetadata `json:"apex,omitempty"`
@@ -192,8 +496,45 @@ type CanaryService struct {
 	// Canary is the metadata to add to the canary service
 	// +optional
 	Canary *CustomMetadata `json:"canary,omitempty"`
+
+	// DriftPolicy defines how the changes made outside of Flagger
+	// to the generated routing objects are handled, can be repair or alert
+	// Defaults to repair
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+}
+
+// CanaryGatewayAPIMigration defines the SMI provider migrated to the Gateway API
+type CanaryGatewayAPIMigration struct {
+	// SMIProvider that generates the TrafficSplit, e.g. linkerd or smi:v1alpha2:linkerd
+	SMIProvider string `json:"smiProvider"`
 }
 
+// NGINXGatewayPolicies defines the NGINX Gateway Fabric policies generated for the HTTPRoute
+type NGINXGatewayPolicies struct {
+	// Tracing of the requests, generates an ObservabilityPolicy
+	// +optional
+	Tracing *nginxgatewayv1alpha1.Tracing `json:"tracing,omitempty"`
+
+	// ClientBody settings of the requests, generates a ClientSettingsPolicy
+	// +optional
+	ClientBody *nginxgatewayv1alpha1.ClientBody `json:"clientBody,omitempty"`
+
+	// ClientKeepAlive settings of the connections, generates a ClientSettingsPolicy
+	// +optional
+	ClientKeepAlive *nginxgatewayv1alpha1.ClientKeepAlive `json:"clientKeepAlive,omitempty"`
+}
+
+// DriftPolicy defines how Flagger handles the external changes to the generated routing objects
+type DriftPolicy string
+
+const (
+	// DriftPolicyRepair reverts the external changes
+	DriftPolicyRepair DriftPolicy = "repair"
+	// DriftPolicyAlert keeps the external changes and alerts
+	DriftPolicyAlert DriftPolicy = "alert"
+)
+
 // CanaryAnalysis is used to describe how the analysis should be done
 type CanaryAnalysis struct {
 	// Schedule interval for this canary analysis
@@ -227,6 +568,57 @@ type CanaryAnalysis struct {
 	// +optional
 	StepWeightPromotion int `json:"stepWeightPromotion,omitempty"`
 
+	// ProportionalReplicas scales the canary replicas, or the min and max replicas
+	// of its HPA, to the canary weight share of the primary replicas
+	// +optional
+	ProportionalReplicas bool `json:"proportionalReplicas,omitempty"`
+
+	// Baseline runs a copy of the primary deployment with fresh pods during the analysis,
+	// the comparison decider compares the canary metrics to the baseline instead of the primary
+	// +optional
+	Baseline bool `json:"baseline,omitempty"`
+
+	// ProfileRef references a ConfigMap holding the rollout pacing shared by multiple canaries,
+	// its stepWeights, interval and threshold override the values of this analysis
+	// +optional
+	ProfileRef *CanaryProfileReference `json:"profileRef,omitempty"`
+
+	// AdaptiveSteps sizes the traffic weight steps based on how close
+	// the metrics are to their thresholds, it overrides StepWeight and StepWeights
+	// +optional
+	AdaptiveSteps *CanaryAdaptiveSteps `json:"adaptiveSteps,omitempty"`
+
+	// Scoring passes the metric checks when the weighted share of the metrics
+	// within their thresholds reaches the score threshold, instead of halting
+	// the advancement on the first metric out of its threshold
+	// +optional
+	Scoring *CanaryScoring `json:"scoring,omitempty"`
+
+	// Decider selects the engine that decides if the canary weight
+	// is increased, held or rolled back after each analysis interval
+	// +optional
+	Decider *CanaryDecider `json:"decider,omitempty"`
+
+	// TrafficVerification checks that the data plane converged to the
+	// last applied traffic weights before each analysis interval
+	// +optional
+	TrafficVerification *CanaryTrafficVerification `json:"trafficVerification,omitempty"`
+
+	// Schedule restricts the canary progression and promotion to the time windows,
+	// outside of them the analysis is held at its current state
+	// +optional
+	Schedule []CanaryAnalysisWindow `json:"schedule,omitempty"`
+
+	// SessionAffinity keeps the clients routed to the canary on the canary
+	// for the cookie max age during the weighted traffic shifting
+	// +optional
+	SessionAffinity *CanarySessionAffinity `json:"sessionAffinity,omitempty"`
+
+	// Time to wait for the connections to drain after a traffic weight change,
+	// before running the next analysis step or scaling down the canary
+	// +optional
+	DrainDuration string `json:"drainDuration,omitempty"`
+
 	// Max number of failed checks before the canary is terminated
 	Threshold int `json:"threshold"`
 
@@ -244,9 +636,359 @@ type CanaryAnalysis struct {
 	// +optional
 	Webhooks []CanaryWebhook `json:"webhooks,omitempty"`
 
+	// Max duration of a webhook group, measured from the start of its parallel calls
+	// +optional
+	WebhookGroupTimeout string `json:"webhookGroupTimeout,omitempty"`
+
 	// A/B testing HTTP header match conditions
 	// +optional
 	Match []istiov1alpha3.HTTPMatchRequest `json:"match,omitempty"`
+
+	// A/B testing gRPC match conditions, translated to HTTP/2 header and path matches
+	// +optional
+	GRPCMatch []CanaryGRPCMatch `json:"grpcMatch,omitempty"`
+
+	// Prefix of the headers populated by the gateway with the JWT claims,
+	// used to translate @request.auth.claims matches for providers other than Istio
+	// Defaults to x-jwt-claim-
+	// +optional
+	JWTClaimHeaderPrefix string `json:"jwtClaimHeaderPrefix,omitempty"`
+
+	// Geo restricts the A/B testing match conditions to a list of countries
+	// +optional
+	Geo *CanaryGeoMatch `json:"geo,omitempty"`
+
+	// SourceWeights caps the canary weight per traffic source
+	// +optional
+	SourceWeights []CanarySourceWeight `json:"sourceWeights,omitempty"`
+
+	// PodHealth fails the canary on pod level signals
+	// +optional
+	PodHealth *CanaryPodHealth `json:"podHealth,omitempty"`
+
+	// FailureReport saves the canary pods logs and events on rollback
+	// +optional
+	FailureReport *CanaryFailureReport `json:"failureReport,omitempty"`
+
+	// Dependencies are the upstream services checked before each analysis step,
+	// the canary is held at its current weight while a dependency is unhealthy
+	// +optional
+	Dependencies []CanaryDependency `json:"dependencies,omitempty"`
+
+	// ErrorBudget holds off new analyses while the error budget of the target service is exhausted
+	// +optional
+	ErrorBudget *CanaryErrorBudget `json:"errorBudget,omitempty"`
+
+	// Verification runs the analysis metrics and the verification webhooks
+	// against the promoted primary on a schedule, without shifting traffic
+	// +optional
+	Verification *CanaryVerification `json:"verification,omitempty"`
+
+	// ProviderOutage holds the canary weight while all the metric providers
+	// or all the alert providers are unreachable
+	// +optional
+	ProviderOutage *CanaryProviderOutage `json:"providerOutage,omitempty"`
+
+	// TimeSlices alternates all the traffic between the canary and the primary in time windows
+	// and compares the metrics of the two cohorts, for services with too little traffic to split
+	// +optional
+	TimeSlices *CanaryTimeSlices `json:"timeSlices,omitempty"`
+}
+
+// CanaryProviderOutage defines when the canary weight is held during an observability outage
+type CanaryProviderOutage struct {
+	// Intervals is the number of consecutive analysis intervals the providers
+	// must be unreachable before the canary weight is held, defaults to one
+	// +optional
+	Intervals int `json:"intervals,omitempty"`
+}
+
+// CanaryTimeSlices defines a time-sliced experiment
+type CanaryTimeSlices struct {
+	// Window is the duration of a time slice, e.g. 10m
+	Window string `json:"window"`
+
+	// Slices is the number of canary windows, each canary window is followed by a primary window
+	Slices int `json:"slices"`
+
+	// MaxRegression is the percentage by which the canary mean of a metric
+	// can be worse than the primary mean, defaults to 10
+	// +optional
+	MaxRegression *float64 `json:"maxRegression,omitempty"`
+}
+
+// CanaryAnalysisWindow defines a time window during which the canary analysis can advance
+type CanaryAnalysisWindow struct {
+	// Cron expression matching the minutes of the window, e.g. "* 9-16 * * 1-5"
+	Cron string `json:"cron"`
+
+	// TimeZone of the cron expression, e.g. Europe/London
+	// Defaults to UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// CanaryVerification defines the schedule of the primary verification runs
+type CanaryVerification struct {
+	// Schedule in cron format evaluated in UTC, e.g. "0 */6 * * *" or "@daily"
+	Schedule string `json:"schedule"`
+
+	// Alert on the successful verification runs, the failed runs are always alerted
+	// +optional
+	AlertOnSuccess bool `json:"alertOnSuccess,omitempty"`
+}
+
+// CanaryErrorBudget defines the error budget query evaluated before starting an analysis
+type CanaryErrorBudget struct {
+	// TemplateRef references a metric template returning the remaining error budget ratio,
+	// e.g. a Sloth or Pyrra recording rule
+	TemplateRef CrossNamespaceObjectReference `json:"templateRef"`
+
+	// MinRemaining is the remaining error budget ratio required to start an analysis
+	// Defaults to 0
+	// +optional
+	MinRemaining *float64 `json:"minRemaining,omitempty"`
+
+	// Interval used to render the metric template query
+	// Defaults to the analysis interval
+	// +optional
+	Interval string `json:"interval,omitempty"`
+}
+
+// CanaryTrafficVerification defines how the applied traffic weights are verified
+type CanaryTrafficVerification struct {
+	// ProbeURL receives test requests marked with the X-Flagger-Probe header
+	// after the routes are read back
+	// +optional
+	ProbeURL string `json:"probeURL,omitempty"`
+
+	// ProbeRequests is the number of test requests sent to the probe URL
+	// Defaults to 10
+	// +optional
+	ProbeRequests int `json:"probeRequests,omitempty"`
+}
+
+// CanaryProfileReference references the ConfigMap of a rollout profile
+type CanaryProfileReference struct {
+	// Name of the ConfigMap
+	Name string `json:"name"`
+
+	// Namespace of the ConfigMap, defaults to the canary namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// CanaryScoring defines the weighted score required to pass the metric checks
+type CanaryScoring struct {
+	// Threshold is the minimum share of the metric weights, in percent,
+	// that must pass their checks for the analysis to advance
+	Threshold float64 `json:"threshold"`
+}
+
+// CanaryAdaptiveSteps defines the bounds of the adaptive traffic weight steps
+type CanaryAdaptiveSteps struct {
+	// MinStepWeight is the step used for the first traffic increase
+	// and when the metrics are close to their thresholds
+	// Defaults to 1
+	// +optional
+	MinStepWeight int `json:"minStepWeight,omitempty"`
+
+	// MaxStepWeight is the step used when the metrics pass with a wide margin
+	MaxStepWeight int `json:"maxStepWeight"`
+
+	// WideMargin is the relative distance of the metrics to their thresholds
+	// from which the max step is used
+	// Defaults to 0.5
+	// +optional
+	WideMargin *float64 `json:"wideMargin,omitempty"`
+
+	// NarrowMargin is the relative distance of the metrics to their thresholds
+	// under which the canary weight is held
+	// Defaults to 0.1
+	// +optional
+	NarrowMargin *float64 `json:"narrowMargin,omitempty"`
+
+	// MaxDuration of the traffic increase, the steps are raised up to the max step
+	// to reach the max weight before the duration elapses
+	// +optional
+	MaxDuration string `json:"maxDuration,omitempty"`
+}
+
+// CanaryDecider defines the engine that drives the progressive traffic increase
+type CanaryDecider struct {
+	// Type of the decider, can be threshold, statistical, comparison, adaptive, grpc or judge
+	// Defaults to adaptive when adaptive steps are set, to threshold otherwise
+	Type DeciderType `json:"type"`
+
+	// Address of the gRPC decider in the host:port format
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// URL of the judge decider
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// Timeout of the gRPC and judge decider calls
+	// Defaults to 10s for gRPC and to the analysis interval for the judge,
+	// the judge timeout can't exceed the analysis interval
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+
+	// TLS of the gRPC decider connection
+	// Defaults to plaintext
+	// +optional
+	TLS *CanaryDeciderTLS `json:"tls,omitempty"`
+
+	// Fallback action applied when the judge can't be reached or returns
+	// an invalid verdict, can be advance, hold or fail
+	// Defaults to hold
+	// +optional
+	Fallback DecisionAction `json:"fallback,omitempty"`
+
+	// Confidence level in percent of the statistical and comparison deciders, can be 90, 95 or 99
+	// Defaults to 95
+	// +optional
+	Confidence int `json:"confidence,omitempty"`
+
+	// MinSamples is the number of analysis runs the statistical decider
+	// waits for before increasing the canary weight, for the comparison decider
+	// it is the number of datapoints required in both the canary and primary series
+	// Defaults to 3 for the statistical decider and to 5 for the comparison decider
+	// +optional
+	MinSamples int `json:"minSamples,omitempty"`
+}
+
+// CanaryDeciderTLS defines the transport security of the gRPC decider connection
+type CanaryDeciderTLS struct {
+	// SecretRef to a secret in the canary namespace with the CA certificate (ca.crt)
+	// that verifies the decider and the client certificate (tls.crt and tls.key) for mutual TLS
+	// Defaults to the system CA certificates
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// ServerName verified in the decider certificate
+	// Defaults to the host of the decider address
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// InsecureSkipVerify disables the decider certificate verification
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// CanaryDependency defines an upstream Service, Canary or URL the canary depends on
+type CanaryDependency struct {
+	// Kind of the dependency, can be Service, Canary or URL
+	Kind string `json:"kind"`
+
+	// Name of the Service or Canary
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the Service or Canary
+	// Defaults to the canary namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// URL of the dependency health endpoint, a 2xx or 3xx response means healthy
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// Timeout of the URL health check
+	// Defaults to 5s
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// CanaryFailureReport defines what is captured from the canary pods on rollback
+type CanaryFailureReport struct {
+	// TailLines is the number of log lines captured for each container
+	// Defaults to 100
+	// +optional
+	TailLines int64 `json:"tailLines,omitempty"`
+
+	// MaxPods is the number of pods the logs are captured from,
+	// the failing pods are captured first
+	// Defaults to 3
+	// +optional
+	MaxPods int `json:"maxPods,omitempty"`
+}
+
+// CanaryPodHealth holds the pod level checks that fail the canary
+// while the analysis is running, regardless of the replicas availability
+type CanaryPodHealth struct {
+	// Max number of container restarts per canary pod
+	// +optional
+	MaxRestarts *int32 `json:"maxRestarts,omitempty"`
+
+	// Fail the canary if a container is in CrashLoopBackOff
+	// +optional
+	CrashLoopBackOff bool `json:"crashLoopBackOff,omitempty"`
+
+	// Fail the canary if a container was OOMKilled
+	// +optional
+	OOMKilled bool `json:"oomKilled,omitempty"`
+
+	// Fail the canary if a container was restarted before its startup probe succeeded
+	// +optional
+	StartupProbe bool `json:"startupProbe,omitempty"`
+
+	// Fail the canary if the canary pods report the given events more times than allowed
+	// +optional
+	Events []CanaryPodEvent `json:"events,omitempty"`
+}
+
+// CanaryPodEvent holds the max number of occurrences of a pod event reason
+type CanaryPodEvent struct {
+	// Reason of the event, e.g. FailedScheduling, ImagePullBackOff or Evicted,
+	// matched against the pod events, the container waiting reasons and the pod status reason
+	Reason string `json:"reason"`
+
+	// Max number of occurrences across the canary pods
+	// Defaults to 0
+	// +optional
+	MaxCount int32 `json:"maxCount,omitempty"`
+}
+
+// CanarySourceWeight holds the max canary weight for a traffic source
+type CanarySourceWeight struct {
+	// Gateway is the name of the gateway as specified in spec.service.gateways,
+	// the reserved name mesh refers to the in-cluster traffic
+	Gateway string `json:"gateway"`
+
+	// MaxWeight is the max traffic percentage routed to canary for this source
+	MaxWeight int `json:"maxWeight"`
+
+	// SourceWorkload is the Istio source workload of the gateway pods,
+	// if set the builtin metrics are also checked for the requests of this source
+	// +optional
+	SourceWorkload string `json:"sourceWorkload,omitempty"`
+}
+
+// CanaryGeoMatch holds the country allowlist of an A/B testing analysis
+type CanaryGeoMatch struct {
+	// Header populated by the CDN or load balancer with the viewer country code
+	// Defaults to cloudfront-viewer-country
+	// +optional
+	Header string `json:"header,omitempty"`
+
+	// Countries is the list of ISO 3166-1 alpha-2 country codes
+	Countries []string `json:"countries"`
+}
+
+// CanaryGRPCMatch holds the gRPC conditions of an A/B testing analysis
+type CanaryGRPCMatch struct {
+	// Service is the fully qualified gRPC service name, e.g. grpc.health.v1.Health
+	// +optional
+	Service string `json:"service,omitempty"`
+
+	// Method of the gRPC service, requires the service to be set
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Metadata keys and values sent by the gRPC client
+	// +optional
+	Metadata map[string]istiov1alpha1.StringMatch `json:"metadata,omitempty"`
 }
 
 // CanaryMetric holds the reference to metrics used for canary analysis
@@ -271,6 +1013,31 @@ type CanaryMetric struct {
 	// TemplateRef references a metric template object
 	// +optional
 	TemplateRef *CrossNamespaceObjectReference `json:"templateRef,omitempty"`
+
+	// Sampling fetches the datapoints of the interval and aggregates them
+	// instead of running the query for a single value
+	// +optional
+	Sampling *CanaryMetricSampling `json:"sampling,omitempty"`
+
+	// Weight of the metric in the analysis score, used only when scoring is enabled
+	// Defaults to 1
+	// +optional
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// CanaryMetricSampling defines how the datapoints of a metric interval are aggregated
+type CanaryMetricSampling struct {
+	// Step between datapoints (defaults to a tenth of the interval)
+	// +optional
+	Step string `json:"step,omitempty"`
+
+	// Aggregator applied to the datapoints: avg, min, max, p95 or count-above-threshold
+	Aggregator string `json:"aggregator"`
+
+	// MaxBreaches is the number of datapoints allowed outside the threshold
+	// when using the count-above-threshold aggregator
+	// +optional
+	MaxBreaches int `json:"maxBreaches,omitempty"`
 }
 
 // CanaryThresholdRange defines the range used for metrics validation
@@ -325,6 +1092,53 @@ const (
 	RollbackHook HookType = "rollback"
 	// ConfirmTrafficIncreaseHook increases traffic weight if webhook returns HTTP 200
 	ConfirmTrafficIncreaseHook = "confirm-traffic-increase"
+	// ConfirmErrorBudgetHook starts the analysis with an exhausted error budget if webhook returns HTTP 200
+	ConfirmErrorBudgetHook HookType = "confirm-error-budget"
+	// VerificationHook execute webhook during the scheduled verification of the primary
+	VerificationHook HookType = "verification"
+)
+
+// GateExpiryAction is the action taken when a gate webhook expires
+type GateExpiryAction string
+
+const (
+	// GateExpiryWait keeps waiting for approval and escalates the alerts
+	GateExpiryWait GateExpiryAction = "wait"
+	// GateExpiryApprove approves the gate
+	GateExpiryApprove GateExpiryAction = "approve"
+	// GateExpiryRollback rolls back the canary
+	GateExpiryRollback GateExpiryAction = "rollback"
+)
+
+// DeciderType is the engine that decides the canary traffic increase
+type DeciderType string
+
+const (
+	// ThresholdDecider increases the canary weight by the configured step when the checks pass
+	ThresholdDecider DeciderType = "threshold"
+	// StatisticalDecider holds the canary weight until the metric samples
+	// pass their thresholds at the configured confidence level
+	StatisticalDecider DeciderType = "statistical"
+	// ComparisonDecider fails the interval when the canary metric datapoints
+	// are significantly worse than the primary ones
+	ComparisonDecider DeciderType = "comparison"
+	// AdaptiveDecider sizes the steps based on the metrics distance to their thresholds
+	AdaptiveDecider DeciderType = "adaptive"
+	// GRPCDecider delegates the decision to an external gRPC service
+	GRPCDecider DeciderType = "grpc"
+	// JudgeDecider delegates the decision to an external HTTP service that
+	// explains its verdict, the verdict is recorded in the canary status
+	JudgeDecider DeciderType = "judge"
+)
+
+// WebhookPayloadVersion is the schema version of the payload sent to webhooks
+type WebhookPayloadVersion string
+
+const (
+	// WebhookPayloadV1 contains the canary name, namespace, phase and the webhook metadata
+	WebhookPayloadV1 WebhookPayloadVersion = "v1"
+	// WebhookPayloadV2 adds the canary status, spec revisions and metric results to v1
+	WebhookPayloadV2 WebhookPayloadVersion = "v2"
 )
 
 // CanaryWebhook holds the reference to external checks used for canary analysis
@@ -347,6 +1161,71 @@ type CanaryWebhook struct {
 	// Metadata (key-value pairs) for this webhook
 	// +optional
 	Metadata *map[string]string `json:"metadata,omitempty"`
+
+	// Weights restricts a confirm-traffic-increase webhook to the steps
+	// that reach or cross one of the listed canary weights
+	// +optional
+	Weights []int `json:"weights,omitempty"`
+
+	// Expiry of a gate webhook, measured from the time the canary started waiting for approval
+	// +optional
+	Expiry string `json:"expiry,omitempty"`
+
+	// ExpiryAction taken when the gate expires, can be wait, approve or rollback
+	// Defaults to wait
+	// +optional
+	ExpiryAction GateExpiryAction `json:"expiryAction,omitempty"`
+
+	// Group of a pre-rollout or rollout webhook, the webhooks of a group are called in parallel
+	// and the groups are called sequentially in the order they appear in
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Version of the payload sent to this webhook, can be v1 or v2
+	// Defaults to v1
+	// +optional
+	Version WebhookPayloadVersion `json:"version,omitempty"`
+
+	// SPIFFE presents the SVID of Flagger as client certificate and
+	// verifies the SVID of the webhook server
+	// +optional
+	SPIFFE bool `json:"spiffe,omitempty"`
+}
+
+// GetExpiry returns the gate expiry, zero means the gate never expires
+func (w CanaryWebhook) GetExpiry() time.Duration {
+	if w.Expiry == "" {
+		return 0
+	}
+
+	expiry, err := time.ParseDuration(w.Expiry)
+	if err != nil {
+		return 0
+	}
+
+	return expiry
+}
+
+// GetExpiryAction returns the action taken when the gate expires (default wait)
+func (w CanaryWebhook) GetExpiryAction() GateExpiryAction {
+	if w.ExpiryAction == "" {
+		return GateExpiryWait
+	}
+	return w.ExpiryAction
+}
+
+// AppliesToStep returns true if the webhook should run for the traffic
+// increase from the current canary weight to the next one
+func (w CanaryWebhook) AppliesToStep(canaryWeight int, nextWeight int) bool {
+	if len(w.Weights) == 0 {
+		return true
+	}
+	for _, weight := range w.Weights {
+		if canaryWeight < weight && weight <= nextWeight {
+			return true
+		}
+	}
+	return false
 }
 
 // CanaryWebhookPayload holds the deployment info and metadata sent to webhooks
@@ -364,6 +1243,125 @@ type CanaryWebhookPayload struct {
 	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
+// CanaryWebhookPayloadV2 extends the v1 payload with the analysis context,
+// the v1 fields are kept so that v1 consumers can decode it
+type CanaryWebhookPayloadV2 struct {
+	CanaryWebhookPayload `json:",inline"`
+
+	// Version of the payload schema
+	Version WebhookPayloadVersion `json:"version"`
+
+	// TargetRef of the canary
+	TargetRef CrossNamespaceObjectReference `json:"targetRef"`
+
+	// CanaryWeight is the current traffic weight routed to the canary
+	CanaryWeight int `json:"canaryWeight"`
+
+	// NextWeight is the canary weight awaiting approval,
+	// it is set only for confirm-traffic-increase hooks
+	NextWeight int `json:"nextWeight,omitempty"`
+
+	// Iterations is the number of analysis iterations run so far
+	Iterations int `json:"iterations"`
+
+	// FailedChecks is the number of failed checks of the current analysis
+	FailedChecks int `json:"failedChecks"`
+
+	// LastAppliedSpec is the hash of the canary revision under analysis
+	LastAppliedSpec string `json:"lastAppliedSpec,omitempty"`
+
+	// LastPromotedSpec is the hash of the last promoted revision,
+	// it differs from LastAppliedSpec while a new revision is analysed
+	LastPromotedSpec string `json:"lastPromotedSpec,omitempty"`
+
+	// Metrics holds the values returned by the last metric checks
+	Metrics []CanaryMetricResult `json:"metrics,omitempty"`
+}
+
+// CanaryMetricResult holds the value returned by a metric check
+type CanaryMetricResult struct {
+	// Name of the metric
+	Name string `json:"name"`
+
+	// Value returned by the metric query
+	Value float64 `json:"value"`
+}
+
+// DecisionAction is the outcome of a step decision
+type DecisionAction string
+
+const (
+	// DecisionAdvance increases the canary weight
+	DecisionAdvance DecisionAction = "advance"
+	// DecisionHold keeps the canary weight until the next analysis interval
+	DecisionHold DecisionAction = "hold"
+	// DecisionFail counts the analysis interval as a failed check
+	DecisionFail DecisionAction = "fail"
+)
+
+// CanaryDecisionRequest is sent to the gRPC decider after each successful analysis interval
+type CanaryDecisionRequest struct {
+	// Name of the canary
+	Name string `json:"name"`
+
+	// Namespace of the canary
+	Namespace string `json:"namespace"`
+
+	// AnalysisRunID of the current analysis
+	AnalysisRunID string `json:"analysisRunID,omitempty"`
+
+	// CanaryWeight is the current traffic weight routed to the canary
+	CanaryWeight int `json:"canaryWeight"`
+
+	// MaxWeight of the canary analysis
+	MaxWeight int `json:"maxWeight"`
+
+	// StepWeight configured in the canary analysis
+	StepWeight int `json:"stepWeight"`
+
+	// FailedChecks of the current analysis
+	FailedChecks int `json:"failedChecks"`
+
+	// Metrics contains the values returned by the metric checks of the last interval
+	Metrics []CanaryMetricResult `json:"metrics,omitempty"`
+}
+
+// CanaryJudgementRequest is sent to the judge decider after each successful analysis interval
+type CanaryJudgementRequest struct {
+	CanaryDecisionRequest `json:",inline"`
+
+	// Samples contains the metric values collected during the current analysis run
+	Samples map[string][]float64 `json:"samples,omitempty"`
+}
+
+// CanaryJudgement is returned by the judge decider
+type CanaryJudgement struct {
+	// Verdict can be advance, hold or fail
+	Verdict DecisionAction `json:"verdict"`
+
+	// StepWeight overrides the configured step weight when advancing
+	// +optional
+	StepWeight int `json:"stepWeight,omitempty"`
+
+	// Explanation of the verdict in a human-readable form
+	// +optional
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// CanaryDecision is returned by the step deciders
+type CanaryDecision struct {
+	// Action can be advance, hold or fail
+	Action DecisionAction `json:"action"`
+
+	// StepWeight overrides the configured step weight when advancing
+	// +optional
+	StepWeight int `json:"stepWeight,omitempty"`
+
+	// Reason of the decision, used in the canary events
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
 // CrossNamespaceObjectReference contains enough information to let you locate the
 // typed referenced object at cluster level
 type CrossNamespaceObjectReference struct {
@@ -383,6 +1381,87 @@ type CrossNamespaceObjectReference struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// CanaryServicePort is an additional port of the generated Kubernetes services
+type CanaryServicePort struct {
+	// Name of the port, Istio selects the protocol based on the name prefix
+	Name string `json:"name"`
+
+	// Port number of the generated Kubernetes services
+	Port int32 `json:"port"`
+
+	// Target port number or name, defaults to Port
+	// +optional
+	TargetPort intstr.IntOrString `json:"targetPort,omitempty"`
+}
+
+// CanaryHeaders defines the headers set on the traffic routed to the canary
+type CanaryHeaders struct {
+	// Name of the header set to true, defaults to x-canary,
+	// the analysis run ID is set on the <name>-run request header
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Request sets the headers on the requests forwarded to the canary
+	// +optional
+	Request bool `json:"request,omitempty"`
+
+	// Response sets the header on the responses returned by the canary
+	// +optional
+	Response bool `json:"response,omitempty"`
+
+	// Baggage appends the canary membership and the run ID to the W3C baggage request header
+	// +optional
+	Baggage bool `json:"baggage,omitempty"`
+}
+
+// CanarySessionAffinity defines the cookie that pins the clients to the canary
+type CanarySessionAffinity struct {
+	// CookieName is the name of the session affinity cookie
+	// Defaults to flagger-cookie
+	// +optional
+	CookieName string `json:"cookieName,omitempty"`
+
+	// MaxAge is the number of seconds until the cookie expires
+	// Defaults to 86400 (one day)
+	// +optional
+	MaxAge int `json:"maxAge,omitempty"`
+}
+
+// CanaryGatewayRoute defines the App Mesh gateway route of the canary service
+type CanaryGatewayRoute struct {
+	// Prefix of the matched HTTP requests
+	// Defaults to /
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Hostname of the matched requests, required for gRPC services
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+
+	// Priority of the gateway route, from 0 (highest) to 1000 (lowest)
+	// +optional
+	Priority *int64 `json:"priority,omitempty"`
+}
+
+// CanaryProtocol holds the protocol settings of the generated routes
+type CanaryProtocol struct {
+	// Type of the routed traffic, can be http, grpc or tcp,
+	// TCP routes are generated for Istio only
+	// Defaults to http
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Upstream protocol used by the proxy to reach the primary and canary services,
+	// can be h2, h2c or tls
+	// +optional
+	Upstream string `json:"upstream,omitempty"`
+
+	// PermitInsecure allows the generated route to serve plain HTTP requests
+	// when TLS is enabled on the root proxy, instead of redirecting them to HTTPS
+	// +optional
+	PermitInsecure bool `json:"permitInsecure,omitempty"`
+}
+
 // CustomMetadata holds labels and annotations to set on generated objects.
 type CustomMetadata struct {
 	Labels      map[string]string `json:"labels,omitempty"`
@@ -409,6 +1488,59 @@ func (c *Canary) GetProgressDeadlineSeconds() int {
 	return ProgressDeadlineSeconds
 }
 
+// GetCanaryHeaders returns the request, response and baggage header values
+// set on the traffic routed to the canary
+func (c *Canary) GetCanaryHeaders() (request map[string]string, response map[string]string, baggage string) {
+	h := c.Spec.Service.CanaryHeaders
+	if h == nil {
+		return
+	}
+
+	name := "x-canary"
+	if h.Name != "" {
+		name = strings.ToLower(h.Name)
+	}
+	if h.Request {
+		request = map[string]string{name: "true"}
+		if c.Status.AnalysisRunID != "" {
+			request[name+"-run"] = c.Status.AnalysisRunID
+		}
+	}
+	if h.Response {
+		response = map[string]string{name: "true"}
+	}
+	if h.Baggage {
+		baggage = "canary=true"
+		if c.Status.AnalysisRunID != "" {
+			baggage += ",canary.run=" + c.Status.AnalysisRunID
+		}
+	}
+	return
+}
+
+// GetSessionAffinityCookie returns the cookie set on the responses of the canary
+// and its max age, the cookie value changes with each analysis run
+func (c *Canary) GetSessionAffinityCookie() (cookie string, maxAge int) {
+	if c.GetAnalysis() == nil || c.GetAnalysis().SessionAffinity == nil {
+		return "", 0
+	}
+	sa := c.GetAnalysis().SessionAffinity
+
+	name := "flagger-cookie"
+	if sa.CookieName != "" {
+		name = sa.CookieName
+	}
+	value := c.Status.AnalysisRunID
+	if value == "" {
+		value = c.Status.LastAppliedSpec
+	}
+	maxAge = 86400
+	if sa.MaxAge > 0 {
+		maxAge = sa.MaxAge
+	}
+	return fmt.Sprintf("%s=%s", name, value), maxAge
+}
+
 // GetAnalysis returns the analysis v1beta1 or v1alpha3
 // to be removed along with spec.canaryAnalysis in v1
 func (c *Canary) GetAnalysis() *CanaryAnalysis {
@@ -436,6 +1568,20 @@ func (c *Canary) GetAnalysisInterval() time.Duration {
 	return interval
 }
 
+// GetAnalysisDrainDuration returns the connection drain duration (default 0)
+func (c *Canary) GetAnalysisDrainDuration() time.Duration {
+	if c.GetAnalysis().DrainDuration == "" {
+		return 0
+	}
+
+	drain, err := time.ParseDuration(c.GetAnalysis().DrainDuration)
+	if err != nil {
+		return 0
+	}
+
+	return drain
+}
+
 // GetAnalysisThreshold returns the canary threshold (default 1)
 func (c *Canary) GetAnalysisThreshold() int {
 	if c.GetAnalysis().Threshold > 0 {
@@ -452,11 +1598,159 @@ func (c *Canary) GetAnalysisPrimaryReadyThreshold() int {
 	return PrimaryReadyThreshold
 }
 
+// GetAnalysisMatch returns the A/B testing match conditions, including the
+// gRPC conditions, merged with the geo country allowlist
+func (c *Canary) GetAnalysisMatch() []istiov1alpha3.HTTPMatchRequest {
+	conditions := c.GetAnalysis().Match
+	if len(c.GetAnalysis().GRPCMatch) > 0 {
+		conditions = append(append([]istiov1alpha3.HTTPMatchRequest(nil), conditions...), c.getGRPCMatch()...)
+	}
+
+	geo := c.GetAnalysis().Geo
+	if geo == nil || len(geo.Countries) == 0 {
+		return conditions
+	}
+
+	header := c.GetGeoHeader()
+	condition := istiov1alpha1.StringMatch{Exact: geo.Countries[0]}
+	if len(geo.Countries) > 1 {
+		condition = istiov1alpha1.StringMatch{Regex: fmt.Sprintf("^(%s)$", strings.Join(geo.Countries, "|"))}
+	}
+
+	if len(conditions) == 0 {
+		return []istiov1alpha3.HTTPMatchRequest{{
+			Headers: map[string]istiov1alpha1.StringMatch{header: condition},
+		}}
+	}
+
+	match := make([]istiov1alpha3.HTTPMatchRequest, 0, len(conditions))
+	for _, m := range conditions {
+		m = *m.DeepCopy()
+		if m.Headers == nil {
+			m.Headers = make(map[string]istiov1alpha1.StringMatch)
+		}
+		m.Headers[header] = condition
+		match = append(match, m)
+	}
+	return match
+}
+
+// GetGeoHeader returns the lowercase name of the header holding the viewer country
+func (c *Canary) GetGeoHeader() string {
+	if geo := c.GetAnalysis().Geo; geo != nil && geo.Header != "" {
+		return strings.ToLower(geo.Header)
+	}
+	return GeoHeader
+}
+
+// getGRPCMatch translates the gRPC match conditions to HTTP/2 matches,
+// the metadata keys are sent as lowercase headers and the method as the request path
+func (c *Canary) getGRPCMatch() []istiov1alpha3.HTTPMatchRequest {
+	match := make([]istiov1alpha3.HTTPMatchRequest, 0, len(c.GetAnalysis().GRPCMatch))
+	for _, g := range c.GetAnalysis().GRPCMatch {
+		m := istiov1alpha3.HTTPMatchRequest{}
+		if len(g.Metadata) > 0 {
+			m.Headers = make(map[string]istiov1alpha1.StringMatch, len(g.Metadata))
+			for k, v := range g.Metadata {
+				m.Headers[strings.ToLower(k)] = v
+			}
+		}
+		switch {
+		case g.Service != "" && g.Method != "":
+			m.Uri = &istiov1alpha1.StringMatch{Exact: fmt.Sprintf("/%s/%s", g.Service, g.Method)}
+		case g.Service != "":
+			m.Uri = &istiov1alpha1.StringMatch{Prefix: fmt.Sprintf("/%s/", g.Service)}
+		}
+		match = append(match, m)
+	}
+	return match
+}
+
+// GetJWTClaimHeaderPrefix returns the prefix of the JWT claim headers (default x-jwt-claim-)
+func (c *Canary) GetJWTClaimHeaderPrefix() string {
+	if c.GetAnalysis().JWTClaimHeaderPrefix != "" {
+		return c.GetAnalysis().JWTClaimHeaderPrefix
+	}
+	return JWTClaimHeaderPrefix
+}
+
+// GetScaleDownDelay returns the delay of the canary scale down after promotion (default 0)
+func (c *Canary) GetScaleDownDelay() time.Duration {
+	if c.Spec.ScaleDown == nil || c.Spec.ScaleDown.Delay == "" {
+		return 0
+	}
+
+	delay, err := time.ParseDuration(c.Spec.ScaleDown.Delay)
+	if err != nil {
+		return 0
+	}
+
+	return delay
+}
+
+// GetMigrationTimeout returns the migration job timeout (default 10m)
+func (c *Canary) GetMigrationTimeout() time.Duration {
+	if c.Spec.Migration == nil || c.Spec.Migration.Timeout == "" {
+		return MigrationTimeout
+	}
+
+	timeout, err := time.ParseDuration(c.Spec.Migration.Timeout)
+	if err != nil {
+		return MigrationTimeout
+	}
+
+	return timeout
+}
+
 // GetMetricInterval returns the metric interval default value (1m)
 func (c *Canary) GetMetricInterval() string {
 	return MetricInterval
 }
 
+// IsTakeoverBootstrap returns true if the workload is scaled down
+// only after the live traffic has been switched to the primary
+func (c *Canary) IsTakeoverBootstrap() bool {
+	return c.Spec.Bootstrap != nil && c.Spec.Bootstrap.Mode == TakeoverBootstrap
+}
+
+// IsTCP returns true if the canary routes raw TCP traffic
+func (c *Canary) IsTCP() bool {
+	return c.Spec.Service.Protocol != nil && strings.EqualFold(c.Spec.Service.Protocol.Type, "tcp")
+}
+
+// IsGRPC returns true if the protocol type is grpc
+// or if the service port is named grpc or http2
+func (c *Canary) IsGRPC() bool {
+	if c.Spec.Service.Protocol != nil && c.Spec.Service.Protocol.Type != "" {
+		return strings.EqualFold(c.Spec.Service.Protocol.Type, "grpc")
+	}
+	name := strings.ToLower(c.Spec.Service.PortName)
+	for _, prefix := range []string{"grpc", "http2"} {
+		if name == prefix || strings.HasPrefix(name, prefix+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUpstreamProtocol returns the protocol used by the proxy to reach the
+// primary and canary services, defaults to h2c for gRPC services
+func (c *Canary) GetUpstreamProtocol() string {
+	if c.Spec.Service.Protocol != nil && c.Spec.Service.Protocol.Upstream != "" {
+		return c.Spec.Service.Protocol.Upstream
+	}
+	if c.IsGRPC() {
+		return "h2c"
+	}
+	return ""
+}
+
+// IsWorker returns true if the canary is a queue consumer
+// that is split from the primary by KEDA scaling instead of traffic routing
+func (c *Canary) IsWorker() bool {
+	return c.Spec.Provider == KedaProvider
+}
+
 // SkipAnalysis returns true if the analysis is nil
 // or if spec.SkipAnalysis is true
 func (c *Canary) SkipAnalysis() bool {
@@ -56,6 +56,11 @@ type MetricTemplateSpec struct {
 
 	// Query template for this metric
 	Query string `json:"query,omitempty"`
+
+	// AllowedNamespaces is the list of namespaces of the canaries
+	// that can reference this template, the wildcard * allows all namespaces
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
 }
 
 // MetricProvider is the spec for a MetricProvider resource
@@ -78,6 +83,11 @@ type MetricTemplateProvider struct {
 	// InsecureSkipVerify disables certificate verification for the provider
 	// +optional
 	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// SPIFFE presents the SVID of Flagger as client certificate and
+	// verifies the SVID of the provider
+	// +optional
+	SPIFFE bool `json:"spiffe,omitempty"`
 }
 
 // MetricTemplateModel is the query template model
@@ -87,7 +97,9 @@ type MetricTemplateModel struct {
 	Target    string `json:"target"`
 	Service   string `json:"service"`
 	Ingress   string `json:"ingress"`
+	Route     string `json:"route"`
 	Interval  string `json:"interval"`
+	Source    string `json:"source,omitempty"`
 }
 
 // TemplateFunctions returns a map of functions, one for each model field
@@ -98,7 +110,9 @@ func (mtm *MetricTemplateModel) TemplateFunctions() template.FuncMap {
 		"target":    func() string { return mtm.Target },
 		"service":   func() string { return mtm.Service },
 		"ingress":   func() string { return mtm.Ingress },
+		"route":     func() string { return mtm.Route },
 		"interval":  func() string { return mtm.Interval },
+		"source":    func() string { return mtm.Source },
 	}
 }
 
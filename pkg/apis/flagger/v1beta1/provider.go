@@ -1,15 +1,21 @@
 package v1beta1
 
 const (
-	AppMeshProvider    string = "appmesh"
-	LinkerdProvider    string = "linkerd"
-	IstioProvider      string = "istio"
-	SMIProvider        string = "smi"
-	ContourProvider    string = "contour"
-	GlooProvider       string = "gloo"
-	NGINXProvider      string = "nginx"
-	KubernetesProvider string = "kubernetes"
-	SkipperProvider    string = "skipper"
-	TraefikProvider    string = "traefik"
-	OsmProvider        string = "osm"
+	AppMeshProvider      string = "appmesh"
+	LinkerdProvider      string = "linkerd"
+	IstioProvider        string = "istio"
+	SMIProvider          string = "smi"
+	ContourProvider      string = "contour"
+	GlooProvider         string = "gloo"
+	NGINXProvider        string = "nginx"
+	KubernetesProvider   string = "kubernetes"
+	SkipperProvider      string = "skipper"
+	TraefikProvider      string = "traefik"
+	OsmProvider          string = "osm"
+	KedaProvider         string = "keda"
+	GatewayProvider      string = "gatewayapi"
+	NGINXGatewayProvider string = "gatewayapi:nginx"
+	ApisixProvider       string = "apisix"
+	ConsulProvider       string = "consul"
+	KumaProvider         string = "kuma"
 )
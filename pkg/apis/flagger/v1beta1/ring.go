@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	RingRolloutKind = "RingRollout"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RingRollout sequences the analysis of multiple canaries into rings
+type RingRollout struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RingRolloutSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RingRolloutList is a list of ring rollout resources
+type RingRolloutList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []RingRollout `json:"items"`
+}
+
+// RingRolloutSpec is the specification of the desired behavior of the RingRollout
+type RingRolloutSpec struct {
+	// Rings in the order they are rolled out, the canaries of a ring
+	// are held until all the canaries of the previous rings have succeeded
+	Rings []Ring `json:"rings"`
+}
+
+// Ring is a group of canaries analysed in parallel
+type Ring struct {
+	// Name of this ring
+	Name string `json:"name"`
+
+	// Canaries of this ring
+	Canaries []RingCanaryReference `json:"canaries"`
+
+	// BakeTime is the time to wait after all the canaries of this ring
+	// have succeeded before starting the next ring
+	// +optional
+	BakeTime string `json:"bakeTime,omitempty"`
+}
+
+// RingCanaryReference holds the reference to a canary
+type RingCanaryReference struct {
+	// Name of the canary
+	Name string `json:"name"`
+
+	// Namespace of the canary
+	// Defaults to the ring rollout namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// GetBakeTime returns the ring bake time, zero means the next ring starts right away
+func (r Ring) GetBakeTime() time.Duration {
+	if r.BakeTime == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(r.BakeTime)
+	if err != nil {
+		return 0
+	}
+	return d
+}
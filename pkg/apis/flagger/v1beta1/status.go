@@ -56,6 +56,9 @@ const (
 	// CanaryPhaseSucceeded means the canary analysis has been successful
 	// and the canary deployment has been promoted
 	CanaryPhaseSucceeded CanaryPhase = "Succeeded"
+	// CanaryPhasePromotedWithoutAnalysis means the canary has been promoted
+	// with the analysis skipped
+	CanaryPhasePromotedWithoutAnalysis CanaryPhase = "PromotedWithoutAnalysis"
 	// CanaryPhaseFailed means the canary analysis failed
 	// and the canary deployment has been scaled to zero
 	CanaryPhaseFailed CanaryPhase = "Failed"
@@ -67,6 +70,12 @@ const (
 	CanaryPhaseTerminated CanaryPhase = "Terminated"
 )
 
+// IsPromoted returns true if the phase ends a canary run with the canary promoted,
+// whether the analysis succeeded or was skipped
+func (p CanaryPhase) IsPromoted() bool {
+	return p == CanaryPhaseSucceeded || p == CanaryPhasePromotedWithoutAnalysis
+}
+
 // CanaryStatus is used for state persistence (read-only)
 type CanaryStatus struct {
 	Phase        CanaryPhase `json:"phase"`
@@ -80,7 +89,107 @@ type CanaryStatus struct {
 	// +optional
 	LastPromotedSpec string `json:"lastPromotedSpec,omitempty"`
 	// +optional
+	AnalysisRunID string `json:"analysisRunID,omitempty"`
+	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// LastWeightChangeTime is the time the canary traffic weight was last changed,
+	// the drain duration is measured from it
+	// +optional
+	LastWeightChangeTime metav1.Time `json:"lastWeightChangeTime,omitempty"`
 	// +optional
 	Conditions []CanaryCondition `json:"conditions,omitempty"`
+	// +optional
+	LastVerification *CanaryVerificationStatus `json:"lastVerification,omitempty"`
+	// +optional
+	ProviderOutages int `json:"providerOutages,omitempty"`
+	// +optional
+	TimeSlice *CanaryTimeSliceStatus `json:"timeSlice,omitempty"`
+	// +optional
+	LastJudgement *CanaryJudgementStatus `json:"lastJudgement,omitempty"`
+	// +optional
+	Routes *CanaryRoutesStatus `json:"routes,omitempty"`
+	// +optional
+	Gates []CanaryGateStatus `json:"gates,omitempty"`
+}
+
+// CanaryGateStatus tracks a gate webhook with an expiry that holds the canary
+type CanaryGateStatus struct {
+	// Name of the gate webhook
+	Name string `json:"name"`
+	// WaitingSince is the time the gate started holding the canary
+	WaitingSince metav1.Time `json:"waitingSince"`
+	// Expiries is the number of expiry periods elapsed while waiting
+	// +optional
+	Expiries int `json:"expiries,omitempty"`
+}
+
+// CanaryRoutesStatus is the traffic split read back from the routing objects of the provider
+type CanaryRoutesStatus struct {
+	// Provider that applies the traffic split
+	Provider string `json:"provider"`
+	// PrimaryWeight is the percentage of the traffic routed to the primary
+	PrimaryWeight int `json:"primaryWeight"`
+	// CanaryWeight is the percentage of the traffic routed to the canary
+	CanaryWeight int `json:"canaryWeight"`
+	// Mirrored is true when the primary traffic is mirrored to the canary
+	// +optional
+	Mirrored bool `json:"mirrored,omitempty"`
+	// LastTransitionTime is the time the traffic split changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// CanaryJudgementStatus is the last verdict of the judge decider
+type CanaryJudgementStatus struct {
+	// Time of the verdict
+	Time metav1.Time `json:"time"`
+	// Verdict can be advance, hold or fail
+	Verdict DecisionAction `json:"verdict"`
+	// Explanation of the verdict returned by the judge
+	// +optional
+	Explanation string `json:"explanation,omitempty"`
+	// Fallback is true when the judge failed and the fallback action was applied
+	// +optional
+	Fallback bool `json:"fallback,omitempty"`
+}
+
+// TimeSliceCohort is the workload receiving all the traffic during a time slice
+type TimeSliceCohort string
+
+const (
+	TimeSliceCohortPrimary TimeSliceCohort = "primary"
+	TimeSliceCohortCanary  TimeSliceCohort = "canary"
+)
+
+// CanaryTimeSliceStatus is the state of a time-sliced experiment
+type CanaryTimeSliceStatus struct {
+	// Window is the number of the current time slice starting from one
+	Window int `json:"window"`
+	// Cohort receiving all the traffic during the current time slice
+	Cohort TimeSliceCohort `json:"cohort"`
+	// StartedAt is the start time of the current time slice
+	StartedAt metav1.Time `json:"startedAt"`
+	// Results holds the metric values measured at the end of each time slice
+	// +optional
+	Results []CanaryTimeSliceResult `json:"results,omitempty"`
+}
+
+// CanaryTimeSliceResult holds the metric values of a completed time slice
+type CanaryTimeSliceResult struct {
+	Window  int                  `json:"window"`
+	Cohort  TimeSliceCohort      `json:"cohort"`
+	Metrics []CanaryMetricResult `json:"metrics,omitempty"`
+}
+
+// CanaryVerificationStatus is the report of the last scheduled verification of the primary
+type CanaryVerificationStatus struct {
+	// Time of the verification run
+	Time metav1.Time `json:"time"`
+	// Succeeded is true if all the metric checks and verification webhooks passed
+	Succeeded bool `json:"succeeded"`
+	// Message describes the first failed check
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Metrics holds the values returned by the metric queries
+	// +optional
+	Metrics []CanaryMetricResult `json:"metrics,omitempty"`
 }
@@ -22,7 +22,11 @@ limitations under the License.
 package v1beta1
 
 import (
+	v1alpha2 "github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1alpha2"
+	v1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
 	v1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+	nginxgatewayv1alpha1 "github.com/fluxcd/flagger/pkg/apis/nginxgateway/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -114,6 +118,11 @@ func (in *AlertProviderSpec) DeepCopyInto(out *AlertProviderSpec) {
 		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -178,6 +187,32 @@ func (in *Canary) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryAdaptiveSteps) DeepCopyInto(out *CanaryAdaptiveSteps) {
+	*out = *in
+	if in.WideMargin != nil {
+		in, out := &in.WideMargin, &out.WideMargin
+		*out = new(float64)
+		**out = **in
+	}
+	if in.NarrowMargin != nil {
+		in, out := &in.NarrowMargin, &out.NarrowMargin
+		*out = new(float64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAdaptiveSteps.
+func (in *CanaryAdaptiveSteps) DeepCopy() *CanaryAdaptiveSteps {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAdaptiveSteps)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryAlert) DeepCopyInto(out *CanaryAlert) {
 	*out = *in
@@ -203,6 +238,41 @@ func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
 		*out = make([]int, len(*in))
 		copy(*out, *in)
 	}
+	if in.ProfileRef != nil {
+		in, out := &in.ProfileRef, &out.ProfileRef
+		*out = new(CanaryProfileReference)
+		**out = **in
+	}
+	if in.AdaptiveSteps != nil {
+		in, out := &in.AdaptiveSteps, &out.AdaptiveSteps
+		*out = new(CanaryAdaptiveSteps)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Scoring != nil {
+		in, out := &in.Scoring, &out.Scoring
+		*out = new(CanaryScoring)
+		**out = **in
+	}
+	if in.Decider != nil {
+		in, out := &in.Decider, &out.Decider
+		*out = new(CanaryDecider)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TrafficVerification != nil {
+		in, out := &in.TrafficVerification, &out.TrafficVerification
+		*out = new(CanaryTrafficVerification)
+		**out = **in
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = make([]CanaryAnalysisWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.SessionAffinity != nil {
+		in, out := &in.SessionAffinity, &out.SessionAffinity
+		*out = new(CanarySessionAffinity)
+		**out = **in
+	}
 	if in.PrimaryReadyThreshold != nil {
 		in, out := &in.PrimaryReadyThreshold, &out.PrimaryReadyThreshold
 		*out = new(int)
@@ -234,6 +304,58 @@ func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.GRPCMatch != nil {
+		in, out := &in.GRPCMatch, &out.GRPCMatch
+		*out = make([]CanaryGRPCMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Geo != nil {
+		in, out := &in.Geo, &out.Geo
+		*out = new(CanaryGeoMatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SourceWeights != nil {
+		in, out := &in.SourceWeights, &out.SourceWeights
+		*out = make([]CanarySourceWeight, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodHealth != nil {
+		in, out := &in.PodHealth, &out.PodHealth
+		*out = new(CanaryPodHealth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailureReport != nil {
+		in, out := &in.FailureReport, &out.FailureReport
+		*out = new(CanaryFailureReport)
+		**out = **in
+	}
+	if in.Dependencies != nil {
+		in, out := &in.Dependencies, &out.Dependencies
+		*out = make([]CanaryDependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.ErrorBudget != nil {
+		in, out := &in.ErrorBudget, &out.ErrorBudget
+		*out = new(CanaryErrorBudget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(CanaryVerification)
+		**out = **in
+	}
+	if in.ProviderOutage != nil {
+		in, out := &in.ProviderOutage, &out.ProviderOutage
+		*out = new(CanaryProviderOutage)
+		**out = **in
+	}
+	if in.TimeSlices != nil {
+		in, out := &in.TimeSlices, &out.TimeSlices
+		*out = new(CanaryTimeSlices)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -247,6 +369,54 @@ func (in *CanaryAnalysis) DeepCopy() *CanaryAnalysis {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryAnalysisWindow) DeepCopyInto(out *CanaryAnalysisWindow) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysisWindow.
+func (in *CanaryAnalysisWindow) DeepCopy() *CanaryAnalysisWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAnalysisWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryBootstrap) DeepCopyInto(out *CanaryBootstrap) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryBootstrap.
+func (in *CanaryBootstrap) DeepCopy() *CanaryBootstrap {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryBootstrap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryCapacityCheck) DeepCopyInto(out *CanaryCapacityCheck) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryCapacityCheck.
+func (in *CanaryCapacityCheck) DeepCopy() *CanaryCapacityCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryCapacityCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryCondition) DeepCopyInto(out *CanaryCondition) {
 	*out = *in
@@ -266,176 +436,996 @@ func (in *CanaryCondition) DeepCopy() *CanaryCondition {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanaryList) DeepCopyInto(out *CanaryList) {
+func (in *CanaryDNS) DeepCopyInto(out *CanaryDNS) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Canary, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryList.
-func (in *CanaryList) DeepCopy() *CanaryList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryDNS.
+func (in *CanaryDNS) DeepCopy() *CanaryDNS {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryList)
+	out := new(CanaryDNS)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CanaryList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryDecider) DeepCopyInto(out *CanaryDecider) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(CanaryDeciderTLS)
+		(*in).DeepCopyInto(*out)
 	}
-	return nil
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryDecider.
+func (in *CanaryDecider) DeepCopy() *CanaryDecider {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryDecider)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanaryMetric) DeepCopyInto(out *CanaryMetric) {
+func (in *CanaryDeciderTLS) DeepCopyInto(out *CanaryDeciderTLS) {
 	*out = *in
-	if in.ThresholdRange != nil {
-		in, out := &in.ThresholdRange, &out.ThresholdRange
-		*out = new(CanaryThresholdRange)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.TemplateRef != nil {
-		in, out := &in.TemplateRef, &out.TemplateRef
-		*out = new(CrossNamespaceObjectReference)
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetric.
-func (in *CanaryMetric) DeepCopy() *CanaryMetric {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryDeciderTLS.
+func (in *CanaryDeciderTLS) DeepCopy() *CanaryDeciderTLS {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryMetric)
+	out := new(CanaryDeciderTLS)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanaryService) DeepCopyInto(out *CanaryService) {
+func (in *CanaryDecision) DeepCopyInto(out *CanaryDecision) {
 	*out = *in
-	out.TargetPort = in.TargetPort
-	if in.Gateways != nil {
-		in, out := &in.Gateways, &out.Gateways
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryDecision.
+func (in *CanaryDecision) DeepCopy() *CanaryDecision {
+	if in == nil {
+		return nil
 	}
-	if in.Hosts != nil {
-		in, out := &in.Hosts, &out.Hosts
-		*out = make([]string, len(*in))
+	out := new(CanaryDecision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryDecisionRequest) DeepCopyInto(out *CanaryDecisionRequest) {
+	*out = *in
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetricResult, len(*in))
 		copy(*out, *in)
 	}
-	if in.TrafficPolicy != nil {
-		in, out := &in.TrafficPolicy, &out.TrafficPolicy
-		*out = new(v1alpha3.TrafficPolicy)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Match != nil {
-		in, out := &in.Match, &out.Match
-		*out = make([]v1alpha3.HTTPMatchRequest, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryDecisionRequest.
+func (in *CanaryDecisionRequest) DeepCopy() *CanaryDecisionRequest {
+	if in == nil {
+		return nil
 	}
-	if in.Rewrite != nil {
-		in, out := &in.Rewrite, &out.Rewrite
-		*out = new(v1alpha3.HTTPRewrite)
-		**out = **in
+	out := new(CanaryDecisionRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryDependency) DeepCopyInto(out *CanaryDependency) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryDependency.
+func (in *CanaryDependency) DeepCopy() *CanaryDependency {
+	if in == nil {
+		return nil
 	}
-	if in.Retries != nil {
-		in, out := &in.Retries, &out.Retries
-		*out = new(v1alpha3.HTTPRetry)
+	out := new(CanaryDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryErrorBudget) DeepCopyInto(out *CanaryErrorBudget) {
+	*out = *in
+	out.TemplateRef = in.TemplateRef
+	if in.MinRemaining != nil {
+		in, out := &in.MinRemaining, &out.MinRemaining
+		*out = new(float64)
 		**out = **in
 	}
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
-		*out = new(v1alpha3.Headers)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.CorsPolicy != nil {
-		in, out := &in.CorsPolicy, &out.CorsPolicy
-		*out = new(v1alpha3.CorsPolicy)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Backends != nil {
-		in, out := &in.Backends, &out.Backends
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Apex != nil {
-		in, out := &in.Apex, &out.Apex
-		*out = new(CustomMetadata)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Primary != nil {
-		in, out := &in.Primary, &out.Primary
-		*out = new(CustomMetadata)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Canary != nil {
-		in, out := &in.Canary, &out.Canary
-		*out = new(CustomMetadata)
-		(*in).DeepCopyInto(*out)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryService.
-func (in *CanaryService) DeepCopy() *CanaryService {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryErrorBudget.
+func (in *CanaryErrorBudget) DeepCopy() *CanaryErrorBudget {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryService)
+	out := new(CanaryErrorBudget)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
+func (in *CanaryFailureReport) DeepCopyInto(out *CanaryFailureReport) {
 	*out = *in
-	out.TargetRef = in.TargetRef
-	if in.AutoscalerRef != nil {
-		in, out := &in.AutoscalerRef, &out.AutoscalerRef
-		*out = new(CrossNamespaceObjectReference)
-		**out = **in
-	}
-	if in.IngressRef != nil {
-		in, out := &in.IngressRef, &out.IngressRef
-		*out = new(CrossNamespaceObjectReference)
-		**out = **in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryFailureReport.
+func (in *CanaryFailureReport) DeepCopy() *CanaryFailureReport {
+	if in == nil {
+		return nil
 	}
-	if in.UpstreamRef != nil {
+	out := new(CanaryFailureReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryGRPCMatch) DeepCopyInto(out *CanaryGRPCMatch) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]v1alpha1.StringMatch, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryGRPCMatch.
+func (in *CanaryGRPCMatch) DeepCopy() *CanaryGRPCMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryGRPCMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryGateStatus) DeepCopyInto(out *CanaryGateStatus) {
+	*out = *in
+	in.WaitingSince.DeepCopyInto(&out.WaitingSince)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryGateStatus.
+func (in *CanaryGateStatus) DeepCopy() *CanaryGateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryGateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryGatewayAPIMigration) DeepCopyInto(out *CanaryGatewayAPIMigration) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryGatewayAPIMigration.
+func (in *CanaryGatewayAPIMigration) DeepCopy() *CanaryGatewayAPIMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryGatewayAPIMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryGatewayRoute) DeepCopyInto(out *CanaryGatewayRoute) {
+	*out = *in
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryGatewayRoute.
+func (in *CanaryGatewayRoute) DeepCopy() *CanaryGatewayRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryGatewayRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryGeoMatch) DeepCopyInto(out *CanaryGeoMatch) {
+	*out = *in
+	if in.Countries != nil {
+		in, out := &in.Countries, &out.Countries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryGeoMatch.
+func (in *CanaryGeoMatch) DeepCopy() *CanaryGeoMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryGeoMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryHeaders) DeepCopyInto(out *CanaryHeaders) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryHeaders.
+func (in *CanaryHeaders) DeepCopy() *CanaryHeaders {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryHeaders)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryJudgement) DeepCopyInto(out *CanaryJudgement) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryJudgement.
+func (in *CanaryJudgement) DeepCopy() *CanaryJudgement {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryJudgement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryJudgementRequest) DeepCopyInto(out *CanaryJudgementRequest) {
+	*out = *in
+	in.CanaryDecisionRequest.DeepCopyInto(&out.CanaryDecisionRequest)
+	if in.Samples != nil {
+		in, out := &in.Samples, &out.Samples
+		*out = make(map[string][]float64, len(*in))
+		for key, val := range *in {
+			var outVal []float64
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]float64, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryJudgementRequest.
+func (in *CanaryJudgementRequest) DeepCopy() *CanaryJudgementRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryJudgementRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryJudgementStatus) DeepCopyInto(out *CanaryJudgementStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryJudgementStatus.
+func (in *CanaryJudgementStatus) DeepCopy() *CanaryJudgementStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryJudgementStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryList) DeepCopyInto(out *CanaryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Canary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryList.
+func (in *CanaryList) DeepCopy() *CanaryList {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetric) DeepCopyInto(out *CanaryMetric) {
+	*out = *in
+	if in.ThresholdRange != nil {
+		in, out := &in.ThresholdRange, &out.ThresholdRange
+		*out = new(CanaryThresholdRange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(CrossNamespaceObjectReference)
+		**out = **in
+	}
+	if in.Sampling != nil {
+		in, out := &in.Sampling, &out.Sampling
+		*out = new(CanaryMetricSampling)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetric.
+func (in *CanaryMetric) DeepCopy() *CanaryMetric {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetric)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricResult) DeepCopyInto(out *CanaryMetricResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricResult.
+func (in *CanaryMetricResult) DeepCopy() *CanaryMetricResult {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricSampling) DeepCopyInto(out *CanaryMetricSampling) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricSampling.
+func (in *CanaryMetricSampling) DeepCopy() *CanaryMetricSampling {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricSampling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMigration) DeepCopyInto(out *CanaryMigration) {
+	*out = *in
+	in.Up.DeepCopyInto(&out.Up)
+	if in.Down != nil {
+		in, out := &in.Down, &out.Down
+		*out = new(batchv1.JobTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMigration.
+func (in *CanaryMigration) DeepCopy() *CanaryMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMonitor) DeepCopyInto(out *CanaryMonitor) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMonitor.
+func (in *CanaryMonitor) DeepCopy() *CanaryMonitor {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMonitor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryPodEvent) DeepCopyInto(out *CanaryPodEvent) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryPodEvent.
+func (in *CanaryPodEvent) DeepCopy() *CanaryPodEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryPodEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryPodHealth) DeepCopyInto(out *CanaryPodHealth) {
+	*out = *in
+	if in.MaxRestarts != nil {
+		in, out := &in.MaxRestarts, &out.MaxRestarts
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]CanaryPodEvent, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryPodHealth.
+func (in *CanaryPodHealth) DeepCopy() *CanaryPodHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryPodHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryPodMetadata) DeepCopyInto(out *CanaryPodMetadata) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryPodMetadata.
+func (in *CanaryPodMetadata) DeepCopy() *CanaryPodMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryPodMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryPreview) DeepCopyInto(out *CanaryPreview) {
+	*out = *in
+	if in.StubsRef != nil {
+		in, out := &in.StubsRef, &out.StubsRef
+		*out = new(CrossNamespaceObjectReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryPreview.
+func (in *CanaryPreview) DeepCopy() *CanaryPreview {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryPreview)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryPrimary) DeepCopyInto(out *CanaryPrimary) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]CanaryPrimaryContainer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Scheduling != nil {
+		in, out := &in.Scheduling, &out.Scheduling
+		*out = new(CanaryPrimaryScheduling)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryPrimary.
+func (in *CanaryPrimary) DeepCopy() *CanaryPrimary {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryPrimary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryPrimaryContainer) DeepCopyInto(out *CanaryPrimaryContainer) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryPrimaryContainer.
+func (in *CanaryPrimaryContainer) DeepCopy() *CanaryPrimaryContainer {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryPrimaryContainer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryPrimaryScheduling) DeepCopyInto(out *CanaryPrimaryScheduling) {
+	*out = *in
+	if in.LabelKeys != nil {
+		in, out := &in.LabelKeys, &out.LabelKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryPrimaryScheduling.
+func (in *CanaryPrimaryScheduling) DeepCopy() *CanaryPrimaryScheduling {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryPrimaryScheduling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryProfileReference) DeepCopyInto(out *CanaryProfileReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryProfileReference.
+func (in *CanaryProfileReference) DeepCopy() *CanaryProfileReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryProfileReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryProtocol) DeepCopyInto(out *CanaryProtocol) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryProtocol.
+func (in *CanaryProtocol) DeepCopy() *CanaryProtocol {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryProtocol)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryProviderOutage) DeepCopyInto(out *CanaryProviderOutage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryProviderOutage.
+func (in *CanaryProviderOutage) DeepCopy() *CanaryProviderOutage {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryProviderOutage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryRoutesStatus) DeepCopyInto(out *CanaryRoutesStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryRoutesStatus.
+func (in *CanaryRoutesStatus) DeepCopy() *CanaryRoutesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryRoutesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryScaleDown) DeepCopyInto(out *CanaryScaleDown) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryScaleDown.
+func (in *CanaryScaleDown) DeepCopy() *CanaryScaleDown {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryScaleDown)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryScoring) DeepCopyInto(out *CanaryScoring) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryScoring.
+func (in *CanaryScoring) DeepCopy() *CanaryScoring {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryScoring)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryService) DeepCopyInto(out *CanaryService) {
+	*out = *in
+	out.TargetPort = in.TargetPort
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]CanaryServicePort, len(*in))
+		copy(*out, *in)
+	}
+	if in.Gateways != nil {
+		in, out := &in.Gateways, &out.Gateways
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GatewayRefs != nil {
+		in, out := &in.GatewayRefs, &out.GatewayRefs
+		*out = make([]v1alpha2.ParentReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GatewayAPIMigration != nil {
+		in, out := &in.GatewayAPIMigration, &out.GatewayAPIMigration
+		*out = new(CanaryGatewayAPIMigration)
+		**out = **in
+	}
+	if in.NGINXGateway != nil {
+		in, out := &in.NGINXGateway, &out.NGINXGateway
+		*out = new(NGINXGatewayPolicies)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TrafficPolicy != nil {
+		in, out := &in.TrafficPolicy, &out.TrafficPolicy
+		*out = new(v1alpha3.TrafficPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CanaryTrafficPolicy != nil {
+		in, out := &in.CanaryTrafficPolicy, &out.CanaryTrafficPolicy
+		*out = new(v1alpha3.TrafficPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AnalysisTrafficPolicy != nil {
+		in, out := &in.AnalysisTrafficPolicy, &out.AnalysisTrafficPolicy
+		*out = new(v1alpha3.TrafficPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = make([]v1alpha3.HTTPMatchRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rewrite != nil {
+		in, out := &in.Rewrite, &out.Rewrite
+		*out = new(v1alpha3.HTTPRewrite)
+		**out = **in
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(v1alpha3.HTTPRetry)
+		**out = **in
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = new(v1alpha3.Headers)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CanaryHeaders != nil {
+		in, out := &in.CanaryHeaders, &out.CanaryHeaders
+		*out = new(CanaryHeaders)
+		**out = **in
+	}
+	if in.CorsPolicy != nil {
+		in, out := &in.CorsPolicy, &out.CorsPolicy
+		*out = new(v1alpha3.CorsPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backends != nil {
+		in, out := &in.Backends, &out.Backends
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GatewayRoute != nil {
+		in, out := &in.GatewayRoute, &out.GatewayRoute
+		*out = new(CanaryGatewayRoute)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Protocol != nil {
+		in, out := &in.Protocol, &out.Protocol
+		*out = new(CanaryProtocol)
+		**out = **in
+	}
+	if in.Apex != nil {
+		in, out := &in.Apex, &out.Apex
+		*out = new(CustomMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Primary != nil {
+		in, out := &in.Primary, &out.Primary
+		*out = new(CustomMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CustomMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryService.
+func (in *CanaryService) DeepCopy() *CanaryService {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryServicePort) DeepCopyInto(out *CanaryServicePort) {
+	*out = *in
+	out.TargetPort = in.TargetPort
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryServicePort.
+func (in *CanaryServicePort) DeepCopy() *CanaryServicePort {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryServicePort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanarySessionAffinity) DeepCopyInto(out *CanarySessionAffinity) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanarySessionAffinity.
+func (in *CanarySessionAffinity) DeepCopy() *CanarySessionAffinity {
+	if in == nil {
+		return nil
+	}
+	out := new(CanarySessionAffinity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanarySourceWeight) DeepCopyInto(out *CanarySourceWeight) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanarySourceWeight.
+func (in *CanarySourceWeight) DeepCopy() *CanarySourceWeight {
+	if in == nil {
+		return nil
+	}
+	out := new(CanarySourceWeight)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
+	*out = *in
+	out.TargetRef = in.TargetRef
+	if in.AutoscalerRef != nil {
+		in, out := &in.AutoscalerRef, &out.AutoscalerRef
+		*out = new(CrossNamespaceObjectReference)
+		**out = **in
+	}
+	if in.IngressRef != nil {
+		in, out := &in.IngressRef, &out.IngressRef
+		*out = new(CrossNamespaceObjectReference)
+		**out = **in
+	}
+	if in.RouteRef != nil {
+		in, out := &in.RouteRef, &out.RouteRef
+		*out = new(CrossNamespaceObjectReference)
+		**out = **in
+	}
+	if in.UpstreamRef != nil {
 		in, out := &in.UpstreamRef, &out.UpstreamRef
 		*out = new(CrossNamespaceObjectReference)
 		**out = **in
 	}
-	in.Service.DeepCopyInto(&out.Service)
-	if in.Analysis != nil {
-		in, out := &in.Analysis, &out.Analysis
-		*out = new(CanaryAnalysis)
+	in.Service.DeepCopyInto(&out.Service)
+	if in.Analysis != nil {
+		in, out := &in.Analysis, &out.Analysis
+		*out = new(CanaryAnalysis)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CanaryAnalysis != nil {
+		in, out := &in.CanaryAnalysis, &out.CanaryAnalysis
+		*out = new(CanaryAnalysis)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Migration != nil {
+		in, out := &in.Migration, &out.Migration
+		*out = new(CanaryMigration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScaleDown != nil {
+		in, out := &in.ScaleDown, &out.ScaleDown
+		*out = new(CanaryScaleDown)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CapacityCheck != nil {
+		in, out := &in.CapacityCheck, &out.CapacityCheck
+		*out = new(CanaryCapacityCheck)
+		**out = **in
+	}
+	if in.Primary != nil {
+		in, out := &in.Primary, &out.Primary
+		*out = new(CanaryPrimary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitor != nil {
+		in, out := &in.Monitor, &out.Monitor
+		*out = new(CanaryMonitor)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodMetadata != nil {
+		in, out := &in.PodMetadata, &out.PodMetadata
+		*out = new(CanaryPodMetadata)
+		**out = **in
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(CanaryDNS)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.CanaryAnalysis != nil {
-		in, out := &in.CanaryAnalysis, &out.CanaryAnalysis
-		*out = new(CanaryAnalysis)
+	if in.Preview != nil {
+		in, out := &in.Preview, &out.Preview
+		*out = new(CanaryPreview)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.ProgressDeadlineSeconds != nil {
-		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
-		*out = new(int32)
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(CanaryBootstrap)
+		**out = **in
+	}
+	if in.Worker != nil {
+		in, out := &in.Worker, &out.Worker
+		*out = new(CanaryWorker)
 		**out = **in
 	}
 	return
@@ -466,6 +1456,7 @@ func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
 		}
 	}
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	in.LastWeightChangeTime.DeepCopyInto(&out.LastWeightChangeTime)
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]CanaryCondition, len(*in))
@@ -473,6 +1464,33 @@ func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastVerification != nil {
+		in, out := &in.LastVerification, &out.LastVerification
+		*out = new(CanaryVerificationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TimeSlice != nil {
+		in, out := &in.TimeSlice, &out.TimeSlice
+		*out = new(CanaryTimeSliceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastJudgement != nil {
+		in, out := &in.LastJudgement, &out.LastJudgement
+		*out = new(CanaryJudgementStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = new(CanaryRoutesStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Gates != nil {
+		in, out := &in.Gates, &out.Gates
+		*out = make([]CanaryGateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -512,6 +1530,126 @@ func (in *CanaryThresholdRange) DeepCopy() *CanaryThresholdRange {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryTimeSliceResult) DeepCopyInto(out *CanaryTimeSliceResult) {
+	*out = *in
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetricResult, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryTimeSliceResult.
+func (in *CanaryTimeSliceResult) DeepCopy() *CanaryTimeSliceResult {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryTimeSliceResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryTimeSliceStatus) DeepCopyInto(out *CanaryTimeSliceStatus) {
+	*out = *in
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]CanaryTimeSliceResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryTimeSliceStatus.
+func (in *CanaryTimeSliceStatus) DeepCopy() *CanaryTimeSliceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryTimeSliceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryTimeSlices) DeepCopyInto(out *CanaryTimeSlices) {
+	*out = *in
+	if in.MaxRegression != nil {
+		in, out := &in.MaxRegression, &out.MaxRegression
+		*out = new(float64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryTimeSlices.
+func (in *CanaryTimeSlices) DeepCopy() *CanaryTimeSlices {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryTimeSlices)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryTrafficVerification) DeepCopyInto(out *CanaryTrafficVerification) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryTrafficVerification.
+func (in *CanaryTrafficVerification) DeepCopy() *CanaryTrafficVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryTrafficVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryVerification) DeepCopyInto(out *CanaryVerification) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryVerification.
+func (in *CanaryVerification) DeepCopy() *CanaryVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryVerificationStatus) DeepCopyInto(out *CanaryVerificationStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetricResult, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryVerificationStatus.
+func (in *CanaryVerificationStatus) DeepCopy() *CanaryVerificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryVerificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryWebhook) DeepCopyInto(out *CanaryWebhook) {
 	*out = *in
@@ -526,6 +1664,11 @@ func (in *CanaryWebhook) DeepCopyInto(out *CanaryWebhook) {
 			}
 		}
 	}
+	if in.Weights != nil {
+		in, out := &in.Weights, &out.Weights
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -562,6 +1705,45 @@ func (in *CanaryWebhookPayload) DeepCopy() *CanaryWebhookPayload {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryWebhookPayloadV2) DeepCopyInto(out *CanaryWebhookPayloadV2) {
+	*out = *in
+	in.CanaryWebhookPayload.DeepCopyInto(&out.CanaryWebhookPayload)
+	out.TargetRef = in.TargetRef
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetricResult, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryWebhookPayloadV2.
+func (in *CanaryWebhookPayloadV2) DeepCopy() *CanaryWebhookPayloadV2 {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryWebhookPayloadV2)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryWorker) DeepCopyInto(out *CanaryWorker) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryWorker.
+func (in *CanaryWorker) DeepCopy() *CanaryWorker {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryWorker)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CrossNamespaceObjectReference) DeepCopyInto(out *CrossNamespaceObjectReference) {
 	*out = *in
@@ -728,6 +1910,11 @@ func (in *MetricTemplateProvider) DeepCopy() *MetricTemplateProvider {
 func (in *MetricTemplateSpec) DeepCopyInto(out *MetricTemplateSpec) {
 	*out = *in
 	in.Provider.DeepCopyInto(&out.Provider)
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -763,3 +1950,154 @@ func (in *MetricTemplateStatus) DeepCopy() *MetricTemplateStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NGINXGatewayPolicies) DeepCopyInto(out *NGINXGatewayPolicies) {
+	*out = *in
+	if in.Tracing != nil {
+		in, out := &in.Tracing, &out.Tracing
+		*out = new(nginxgatewayv1alpha1.Tracing)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientBody != nil {
+		in, out := &in.ClientBody, &out.ClientBody
+		*out = new(nginxgatewayv1alpha1.ClientBody)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientKeepAlive != nil {
+		in, out := &in.ClientKeepAlive, &out.ClientKeepAlive
+		*out = new(nginxgatewayv1alpha1.ClientKeepAlive)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NGINXGatewayPolicies.
+func (in *NGINXGatewayPolicies) DeepCopy() *NGINXGatewayPolicies {
+	if in == nil {
+		return nil
+	}
+	out := new(NGINXGatewayPolicies)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Ring) DeepCopyInto(out *Ring) {
+	*out = *in
+	if in.Canaries != nil {
+		in, out := &in.Canaries, &out.Canaries
+		*out = make([]RingCanaryReference, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Ring.
+func (in *Ring) DeepCopy() *Ring {
+	if in == nil {
+		return nil
+	}
+	out := new(Ring)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RingCanaryReference) DeepCopyInto(out *RingCanaryReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RingCanaryReference.
+func (in *RingCanaryReference) DeepCopy() *RingCanaryReference {
+	if in == nil {
+		return nil
+	}
+	out := new(RingCanaryReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RingRollout) DeepCopyInto(out *RingRollout) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RingRollout.
+func (in *RingRollout) DeepCopy() *RingRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(RingRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RingRollout) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RingRolloutList) DeepCopyInto(out *RingRolloutList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RingRollout, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RingRolloutList.
+func (in *RingRolloutList) DeepCopy() *RingRolloutList {
+	if in == nil {
+		return nil
+	}
+	out := new(RingRolloutList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RingRolloutList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RingRolloutSpec) DeepCopyInto(out *RingRolloutSpec) {
+	*out = *in
+	if in.Rings != nil {
+		in, out := &in.Rings, &out.Rings
+		*out = make([]Ring, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RingRolloutSpec.
+func (in *RingRolloutSpec) DeepCopy() *RingRolloutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RingRolloutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,6 @@
+// +k8s:deepcopy-gen=package
+
+// Package v1alpha2 is the v1alpha2 version of the API.
+// +groupName=gateway.networking.k8s.io
+// +groupGoName=gatewayapi
+package v1alpha2
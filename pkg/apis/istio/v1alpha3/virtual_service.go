@@ -513,6 +513,9 @@ type DestinationWeight struct {
 	// If there is only destination in a rule, the weight value is assumed to
 	// be 100.
 	Weight int `json:"weight"`
+
+	// Header manipulation rules
+	Headers *Headers `json:"headers,omitempty"`
 }
 
 // PortSelector specifies the number of a port to be used for
@@ -557,11 +560,9 @@ type TCPRoute struct {
 	// is matched if any one of the match blocks succeed.
 	Match []L4MatchAttributes `json:"match,omitempty"`
 
-	// The destination to which the connection should be forwarded to.
-	// Currently, only one destination is allowed for TCP services. When TCP
-	// weighted routing support is introduced in Envoy, multiple destinations
-	// with weights can be specified.
-	Route DestinationWeight `json:"route"`
+	// The destinations to which the connection should be forwarded to,
+	// the weights of the destinations must add up to 100.
+	Route []DestinationWeight `json:"route"`
 }
 
 // L4 connection match attributes. Note that L4 connection matching support
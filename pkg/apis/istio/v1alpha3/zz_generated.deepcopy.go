@@ -233,6 +233,11 @@ func (in *DestinationRuleSpec) DeepCopy() *DestinationRuleSpec {
 func (in *DestinationWeight) DeepCopyInto(out *DestinationWeight) {
 	*out = *in
 	in.Destination.DeepCopyInto(&out.Destination)
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = new(Headers)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -843,7 +848,13 @@ func (in *TCPRoute) DeepCopyInto(out *TCPRoute) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	in.Route.DeepCopyInto(&out.Route)
+	if in.Route != nil {
+		in, out := &in.Route, &out.Route
+		*out = make([]DestinationWeight, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
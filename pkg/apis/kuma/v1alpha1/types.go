@@ -0,0 +1,64 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TrafficRoute is the Schema for the Kuma traffic routes API
+type TrafficRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Mesh is the name of the Kuma mesh this resource belongs to
+	Mesh string `json:"mesh,omitempty"`
+
+	Spec TrafficRouteSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TrafficRouteList contains a list of TrafficRoute
+type TrafficRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []TrafficRoute `json:"items"`
+}
+
+// TrafficRouteSpec defines how the traffic is routed from the sources to the destinations
+type TrafficRouteSpec struct {
+	// Sources is the list of selectors matching the data plane proxies that originate the traffic
+	Sources []Selector `json:"sources,omitempty"`
+
+	// Destinations is the list of selectors matching the services that receive the traffic
+	Destinations []Selector `json:"destinations,omitempty"`
+
+	// Conf is the routing configuration
+	Conf TrafficRouteConf `json:"conf,omitempty"`
+}
+
+// Selector matches the Kuma tags of a data plane proxy or service
+type Selector struct {
+	Match map[string]string `json:"match,omitempty"`
+}
+
+// TrafficRouteConf defines the destinations of the matched traffic
+type TrafficRouteConf struct {
+	// Split divides the traffic between multiple destinations by weight
+	// +optional
+	Split []TrafficRouteSplit `json:"split,omitempty"`
+
+	// Destination routes the traffic to a single destination
+	// +optional
+	Destination map[string]string `json:"destination,omitempty"`
+}
+
+// TrafficRouteSplit is a weighted destination
+type TrafficRouteSplit struct {
+	Weight      uint32            `json:"weight"`
+	Destination map[string]string `json:"destination"`
+}
@@ -0,0 +1,168 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceMonitor defines monitoring for a set of services.
+type ServiceMonitor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ServiceMonitorSpec `json:"spec"`
+}
+
+// ServiceMonitorSpec contains specification parameters for a ServiceMonitor.
+type ServiceMonitorSpec struct {
+	// JobLabel selects the label from the associated Kubernetes service
+	// which will be used as the job label for all metrics.
+	JobLabel string `json:"jobLabel,omitempty"`
+
+	// PodTargetLabels transfers labels on the Kubernetes pod onto the target.
+	PodTargetLabels []string `json:"podTargetLabels,omitempty"`
+
+	// A list of endpoints allowed as part of this ServiceMonitor.
+	Endpoints []Endpoint `json:"endpoints"`
+
+	// Selector to select Endpoints objects.
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Selector to select which namespaces the Kubernetes Endpoints objects are discovered from.
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector,omitempty"`
+}
+
+// Endpoint defines a scrapeable endpoint serving Prometheus metrics.
+type Endpoint struct {
+	// Name of the service port this endpoint refers to.
+	Port string `json:"port,omitempty"`
+
+	// Name or number of the target port of the Pod behind the Service.
+	TargetPort *intstr.IntOrString `json:"targetPort,omitempty"`
+
+	// HTTP path to scrape for metrics.
+	Path string `json:"path,omitempty"`
+
+	// HTTP scheme to use for scraping.
+	Scheme string `json:"scheme,omitempty"`
+
+	// Interval at which metrics should be scraped.
+	Interval string `json:"interval,omitempty"`
+
+	// Timeout after which the scrape is ended.
+	ScrapeTimeout string `json:"scrapeTimeout,omitempty"`
+
+	// HonorLabels chooses the metric's labels on collisions with target labels.
+	HonorLabels bool `json:"honorLabels,omitempty"`
+
+	// RelabelConfigs to apply to samples before scraping.
+	RelabelConfigs []*RelabelConfig `json:"relabelings,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceMonitorList is a list of ServiceMonitors.
+type ServiceMonitorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ServiceMonitor `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodMonitor defines monitoring for a set of pods.
+type PodMonitor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PodMonitorSpec `json:"spec"`
+}
+
+// PodMonitorSpec contains specification parameters for a PodMonitor.
+type PodMonitorSpec struct {
+	// The label to use to retrieve the job name from.
+	JobLabel string `json:"jobLabel,omitempty"`
+
+	// PodTargetLabels transfers labels on the Kubernetes Pod onto the target.
+	PodTargetLabels []string `json:"podTargetLabels,omitempty"`
+
+	// A list of endpoints allowed as part of this PodMonitor.
+	PodMetricsEndpoints []PodMetricsEndpoint `json:"podMetricsEndpoints"`
+
+	// Selector to select Pod objects.
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Selector to select which namespaces the Endpoints objects are discovered from.
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector,omitempty"`
+}
+
+// PodMetricsEndpoint defines a scrapeable endpoint of a Kubernetes Pod serving Prometheus metrics.
+type PodMetricsEndpoint struct {
+	// Name of the pod port this endpoint refers to.
+	Port string `json:"port,omitempty"`
+
+	// HTTP path to scrape for metrics.
+	Path string `json:"path,omitempty"`
+
+	// HTTP scheme to use for scraping.
+	Scheme string `json:"scheme,omitempty"`
+
+	// Interval at which metrics should be scraped.
+	Interval string `json:"interval,omitempty"`
+
+	// Timeout after which the scrape is ended.
+	ScrapeTimeout string `json:"scrapeTimeout,omitempty"`
+
+	// HonorLabels chooses the metric's labels on collisions with target labels.
+	HonorLabels bool `json:"honorLabels,omitempty"`
+
+	// RelabelConfigs to apply to samples before scraping.
+	RelabelConfigs []*RelabelConfig `json:"relabelings,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodMonitorList is a list of PodMonitors.
+type PodMonitorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PodMonitor `json:"items"`
+}
+
+// NamespaceSelector is a selector for selecting either all namespaces or a
+// list of namespaces.
+type NamespaceSelector struct {
+	// Boolean describing whether all namespaces are selected in contrast to a
+	// list restricting them.
+	Any bool `json:"any,omitempty"`
+
+	// List of namespace names.
+	MatchNames []string `json:"matchNames,omitempty"`
+}
+
+// RelabelConfig allows dynamic rewriting of the label set.
+type RelabelConfig struct {
+	// The source labels select values from existing labels.
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+
+	// Separator placed between concatenated source label values.
+	Separator string `json:"separator,omitempty"`
+
+	// Label to which the resulting value is written in a replace action.
+	TargetLabel string `json:"targetLabel,omitempty"`
+
+	// Regular expression against which the extracted value is matched.
+	Regex string `json:"regex,omitempty"`
+
+	// Replacement value against which a regex replace is performed.
+	Replacement string `json:"replacement,omitempty"`
+
+	// Action to perform based on regex matching.
+	Action string `json:"action,omitempty"`
+}
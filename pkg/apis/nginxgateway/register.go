@@ -0,0 +1,5 @@
+package nginxgateway
+
+const (
+	GroupName = "gateway.nginx.org"
+)
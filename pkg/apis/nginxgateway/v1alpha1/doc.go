@@ -0,0 +1,6 @@
+// +k8s:deepcopy-gen=package
+
+// Package v1alpha1 is the v1alpha1 version of the API.
+// +groupName=gateway.nginx.org
+// +groupGoName=nginxgateway
+package v1alpha1
@@ -0,0 +1,152 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ObservabilityPolicy configures the NGINX tracing of the requests matched by the target routes
+type ObservabilityPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ObservabilityPolicySpec `json:"spec"`
+}
+
+// ObservabilityPolicySpec defines the tracing configuration of the target routes
+type ObservabilityPolicySpec struct {
+	// Tracing configures the tracing of the requests
+	// +optional
+	Tracing *Tracing `json:"tracing,omitempty"`
+
+	// TargetRefs identifies the routes the policy is attached to
+	TargetRefs []LocalPolicyTargetReference `json:"targetRefs"`
+}
+
+// TraceStrategy defines how the requests are sampled
+type TraceStrategy string
+
+const (
+	// TraceStrategyRatio samples a percentage of the requests
+	TraceStrategyRatio TraceStrategy = "ratio"
+	// TraceStrategyParent samples the requests with a sampled parent span
+	TraceStrategyParent TraceStrategy = "parent"
+)
+
+// Tracing defines the sampling strategy and the attributes of the spans
+type Tracing struct {
+	// Strategy defines if the requests are sampled by ratio or by parent span
+	Strategy TraceStrategy `json:"strategy"`
+
+	// Ratio is the percentage of the requests sampled with the ratio strategy
+	// +optional
+	Ratio *int32 `json:"ratio,omitempty"`
+
+	// SpanName is the name of the spans
+	// +optional
+	SpanName *string `json:"spanName,omitempty"`
+
+	// SpanAttributes are the custom key/value attributes added to the spans
+	// +optional
+	SpanAttributes []SpanAttribute `json:"spanAttributes,omitempty"`
+}
+
+// SpanAttribute is a key/value attribute of a span
+type SpanAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ObservabilityPolicyList contains a list of ObservabilityPolicy
+type ObservabilityPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ObservabilityPolicy `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClientSettingsPolicy configures the NGINX client connections of the requests matched by the target route
+type ClientSettingsPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClientSettingsPolicySpec `json:"spec"`
+}
+
+// ClientSettingsPolicySpec defines the client request body and keep-alive settings of the target route
+type ClientSettingsPolicySpec struct {
+	// Body configures the client request body
+	// +optional
+	Body *ClientBody `json:"body,omitempty"`
+
+	// KeepAlive configures the client keep-alive connections
+	// +optional
+	KeepAlive *ClientKeepAlive `json:"keepAlive,omitempty"`
+
+	// TargetRef identifies the route the policy is attached to
+	TargetRef LocalPolicyTargetReference `json:"targetRef"`
+}
+
+// Duration is an NGINX duration, e.g. 30s or 1m
+type Duration string
+
+// ClientBody defines the client request body settings
+type ClientBody struct {
+	// MaxSize is the maximum size of the client request body, e.g. 1m
+	// +optional
+	MaxSize *string `json:"maxSize,omitempty"`
+
+	// Timeout for reading the client request body
+	// +optional
+	Timeout *Duration `json:"timeout,omitempty"`
+}
+
+// ClientKeepAlive defines the client keep-alive settings
+type ClientKeepAlive struct {
+	// Requests is the maximum number of requests served through one keep-alive connection
+	// +optional
+	Requests *int32 `json:"requests,omitempty"`
+
+	// Time is the maximum time during which the requests can be served through one keep-alive connection
+	// +optional
+	Time *Duration `json:"time,omitempty"`
+
+	// Timeout defines the keep-alive timeouts
+	// +optional
+	Timeout *ClientKeepAliveTimeout `json:"timeout,omitempty"`
+}
+
+// ClientKeepAliveTimeout defines the timeouts of the client keep-alive connections
+type ClientKeepAliveTimeout struct {
+	// Server is the time a keep-alive client connection stays open on the server side
+	// +optional
+	Server *Duration `json:"server,omitempty"`
+
+	// Header is the value of the Keep-Alive response header
+	// +optional
+	Header *Duration `json:"header,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClientSettingsPolicyList contains a list of ClientSettingsPolicy
+type ClientSettingsPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ClientSettingsPolicy `json:"items"`
+}
+
+// LocalPolicyTargetReference identifies an object in the namespace of the policy
+type LocalPolicyTargetReference struct {
+	Group string `json:"group"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+}
@@ -44,4 +44,5 @@ type Service struct {
 	Namespace string `json:"namespace"`
 	Port      int32  `json:"port"`
 	Weight    uint   `json:"weight,omitempty"`
+	Scheme    string `json:"scheme,omitempty"`
 }
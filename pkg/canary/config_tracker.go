@@ -144,6 +144,14 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 		vs = targetDae.Spec.Template.Spec.Volumes
 		cs = targetDae.Spec.Template.Spec.Containers
 		cs = append(cs, targetDae.Spec.Template.Spec.InitContainers...)
+	case "StatefulSet":
+		targetSts, err := ct.KubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+		}
+		vs = targetSts.Spec.Template.Spec.Volumes
+		cs = targetSts.Spec.Template.Spec.Containers
+		cs = append(cs, targetSts.Spec.Template.Spec.InitContainers...)
 	default:
 		return nil, fmt.Errorf("TargetRef.Kind invalid: %s", cd.Spec.TargetRef.Kind)
 	}
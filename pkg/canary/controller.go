@@ -35,5 +35,20 @@ type Controller interface {
 	HaveDependenciesChanged(canary *flaggerv1.Canary) (bool, error)
 	ScaleToZero(canary *flaggerv1.Canary) error
 	ScaleFromZero(canary *flaggerv1.Canary) error
+	ScaleTo(canary *flaggerv1.Canary, replicas int32) error
 	Finalize(canary *flaggerv1.Canary) error
+	SaveFailureReport(canary *flaggerv1.Canary) (string, error)
+}
+
+// WeightScaler is implemented by the controllers that can size
+// the canary workload proportionally to its traffic weight
+type WeightScaler interface {
+	ScaleToWeight(canary *flaggerv1.Canary, canaryWeight int) error
+}
+
+// BaselineController is implemented by the controllers that can run a copy of the
+// primary workload with fresh pods for the duration of the analysis
+type BaselineController interface {
+	CreateBaseline(canary *flaggerv1.Canary) error
+	DeleteBaseline(canary *flaggerv1.Canary) error
 }
@@ -71,6 +71,16 @@ func (c *DaemonSetController) ScaleToZero(cd *flaggerv1.Canary) error {
 	return nil
 }
 
+// SaveFailureReport captures the canary pods logs and events in a config map
+func (c *DaemonSetController) SaveFailureReport(cd *flaggerv1.Canary) (string, error) {
+	targetName := cd.Spec.TargetRef.Name
+	dae, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+	return saveFailureReport(c.kubeClient, cd, dae.Spec.Selector)
+}
+
 func (c *DaemonSetController) ScaleFromZero(cd *flaggerv1.Canary) error {
 	targetName := cd.Spec.TargetRef.Name
 	dep, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
@@ -82,6 +92,7 @@ func (c *DaemonSetController) ScaleFromZero(cd *flaggerv1.Canary) error {
 	for k := range daemonSetScaleDownNodeSelector {
 		delete(depCopy.Spec.Template.Spec.NodeSelector, k)
 	}
+	injectCanaryPodMetadata(cd, &depCopy.Spec.Template)
 
 	_, err = c.kubeClient.AppsV1().DaemonSets(dep.Namespace).Update(context.TODO(), depCopy, metav1.UpdateOptions{})
 	if err != nil {
@@ -90,6 +101,15 @@ func (c *DaemonSetController) ScaleFromZero(cd *flaggerv1.Canary) error {
 	return nil
 }
 
+// ScaleTo scales the canary DaemonSet to zero when replicas is zero,
+// any other value restores the DaemonSet scheduling on all nodes
+func (c *DaemonSetController) ScaleTo(cd *flaggerv1.Canary, replicas int32) error {
+	if replicas == 0 {
+		return c.ScaleToZero(cd)
+	}
+	return c.ScaleFromZero(cd)
+}
+
 // Initialize creates the primary DaemonSet, scales down the canary DaemonSet,
 // and returns the pod selector label and container ports
 func (c *DaemonSetController) Initialize(cd *flaggerv1.Canary) (err error) {
@@ -99,16 +119,19 @@ func (c *DaemonSetController) Initialize(cd *flaggerv1.Canary) (err error) {
 	}
 
 	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
-		if !cd.SkipAnalysis() {
+		if !cd.SkipAnalysis() || cd.IsTakeoverBootstrap() {
 			if err := c.IsPrimaryReady(cd); err != nil {
 				return fmt.Errorf("%w", err)
 			}
 		}
 
-		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
-			Infof("Scaling down DaemonSet %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
-		if err := c.ScaleToZero(cd); err != nil {
-			return fmt.Errorf("ScaleToZero failed: %w", err)
+		// on takeover the scheduler scales down the workload after the traffic has been switched to primary
+		if !cd.IsTakeoverBootstrap() {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+				Infof("Scaling down DaemonSet %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+			if err := c.ScaleToZero(cd); err != nil {
+				return fmt.Errorf("ScaleToZero failed: %w", err)
+			}
 		}
 	}
 	return nil
@@ -149,8 +172,13 @@ func (c *DaemonSetController) Promote(cd *flaggerv1.Canary) error {
 	primaryCopy.Spec.RevisionHistoryLimit = canary.Spec.RevisionHistoryLimit
 	primaryCopy.Spec.UpdateStrategy = canary.Spec.UpdateStrategy
 
-	// update spec with primary secrets and config maps
-	primaryCopy.Spec.Template.Spec = c.configTracker.ApplyPrimaryConfigs(canary.Spec.Template.Spec, configRefs)
+	// update spec with primary secrets, config maps and resources
+	primarySpec, err := makePrimaryResources(cd, c.configTracker.ApplyPrimaryConfigs(canary.Spec.Template.Spec, configRefs))
+	if err != nil {
+		return err
+	}
+	primarySpec = makePrimaryScheduling(cd, primarySpec, c.labels, canary.Name, labelValue)
+	primaryCopy.Spec.Template.Spec = primarySpec
 
 	// ignore `daemonSetScaleDownNodeSelector` node selector
 	for key := range daemonSetScaleDownNodeSelector {
@@ -165,6 +193,7 @@ func (c *DaemonSetController) Promote(cd *flaggerv1.Canary) error {
 
 	primaryCopy.Spec.Template.Annotations = annotations
 	primaryCopy.Spec.Template.Labels = makePrimaryLabels(canary.Spec.Template.Labels, primaryLabelValue, label)
+	makePrimaryPodMetadata(cd, &primaryCopy.Spec.Template)
 
 	// apply update
 	_, err = c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
@@ -193,6 +222,9 @@ func (c *DaemonSetController) HasTargetChanged(cd *flaggerv1.Canary) (bool, erro
 		canary.Spec.Template.Spec.NodeSelector = map[string]string{}
 	}
 
+	// ignore the injected pod metadata
+	removePodMetadata(&canary.Spec.Template)
+
 	return hasSpecChanged(cd, canary.Spec.Template)
 }
 
@@ -255,6 +287,11 @@ func (c *DaemonSetController) createPrimaryDaemonSet(cd *flaggerv1.Canary, inclu
 		if err != nil {
 			return fmt.Errorf("makeAnnotations failed: %w", err)
 		}
+		primarySpec, err := makePrimaryResources(cd, c.configTracker.ApplyPrimaryConfigs(canaryDae.Spec.Template.Spec, configRefs))
+		if err != nil {
+			return err
+		}
+		primarySpec = makePrimaryScheduling(cd, primarySpec, c.labels, canaryDae.Name, labelValue)
 
 		// create primary daemonset
 		primaryDae = &appsv1.DaemonSet{
@@ -285,12 +322,14 @@ func (c *DaemonSetController) createPrimaryDaemonSet(cd *flaggerv1.Canary, inclu
 						Labels:      makePrimaryLabels(canaryDae.Spec.Template.Labels, primaryLabelValue, label),
 						Annotations: annotations,
 					},
-					// update spec with the primary secrets and config maps
-					Spec: c.configTracker.ApplyPrimaryConfigs(canaryDae.Spec.Template.Spec, configRefs),
+					// update spec with the primary secrets, config maps and resources
+					Spec: primarySpec,
 				},
 			},
 		}
 
+		makePrimaryPodMetadata(cd, &primaryDae.Spec.Template)
+
 		_, err = c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Create(context.TODO(), primaryDae, metav1.CreateOptions{})
 		if err != nil {
 			return fmt.Errorf("creating daemonset %s.%s failed: %w", primaryDae.Name, cd.Namespace, err)
@@ -18,6 +18,7 @@ package canary
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -52,6 +53,11 @@ func (c *DaemonSetController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error)
 		return true, fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.Namespace, err)
 	}
 
+	if err := checkPodHealth(c.kubeClient, cd, canary.Spec.Selector); err != nil {
+		return !errors.Is(err, ErrPodUnhealthy), fmt.Errorf("canary daemonset %s.%s not healthy: %w",
+			targetName, cd.Namespace, err)
+	}
+
 	retryable, err := c.isDaemonSetReady(cd, canary, 100)
 	if err != nil {
 		return retryable, fmt.Errorf("canary damonset %s.%s not ready with retryable %v: %w",
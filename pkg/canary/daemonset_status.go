@@ -42,6 +42,9 @@ func (c *DaemonSetController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.
 		dae.Spec.Template.Spec.NodeSelector = map[string]string{}
 	}
 
+	// ignore the injected pod metadata
+	removePodMetadata(&dae.Spec.Template)
+
 	configs, err := c.configTracker.GetConfigRefs(cd)
 	if err != nil {
 		return fmt.Errorf("GetConfigRefs failed: %w", err)
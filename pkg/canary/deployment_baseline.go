@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// baselineLabel distinguishes the baseline pods from the primary ones,
+// the baseline pods keep the primary selector label to receive a share of the primary traffic
+const baselineLabel = "flagger.app/baseline"
+
+// CreateBaseline deploys a copy of the primary deployment with fresh pods,
+// sized as the canary deployment, for the duration of the analysis
+func (c *DeploymentController) CreateBaseline(cd *flaggerv1.Canary) error {
+	baselineName := fmt.Sprintf("%s-baseline", cd.Spec.TargetRef.Name)
+	_, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), baselineName, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return fmt.Errorf("deployment %s.%s get query error: %w", baselineName, cd.Namespace, err)
+	}
+
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", primaryName, cd.Namespace, err)
+	}
+	canary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+	}
+
+	replicas := int32Default(canary.Spec.Replicas)
+	if replicas < 1 {
+		replicas = 1
+	}
+
+	template := *primary.Spec.Template.DeepCopy()
+	template.Labels = make(map[string]string)
+	for k, v := range primary.Spec.Template.Labels {
+		template.Labels[k] = v
+	}
+	template.Labels[baselineLabel] = "true"
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{baselineLabel: "true"}}
+	if primary.Spec.Selector != nil {
+		for k, v := range primary.Spec.Selector.MatchLabels {
+			selector.MatchLabels[k] = v
+		}
+	}
+
+	baseline := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        baselineName,
+			Namespace:   cd.Namespace,
+			Labels:      primary.Labels,
+			Annotations: primary.Annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+					Group:   flaggerv1.SchemeGroupVersion.Group,
+					Version: flaggerv1.SchemeGroupVersion.Version,
+					Kind:    flaggerv1.CanaryKind,
+				}),
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			ProgressDeadlineSeconds: primary.Spec.ProgressDeadlineSeconds,
+			MinReadySeconds:         primary.Spec.MinReadySeconds,
+			RevisionHistoryLimit:    primary.Spec.RevisionHistoryLimit,
+			Replicas:                int32p(replicas),
+			Strategy:                primary.Spec.Strategy,
+			Selector:                selector,
+			Template:                template,
+		},
+	}
+
+	_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Create(context.TODO(), baseline, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("creating deployment %s.%s failed: %w", baselineName, cd.Namespace, err)
+	}
+	c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("Deployment %s.%s created", baselineName, cd.Namespace)
+	return nil
+}
+
+// DeleteBaseline removes the baseline deployment once the analysis ended
+func (c *DeploymentController) DeleteBaseline(cd *flaggerv1.Canary) error {
+	baselineName := fmt.Sprintf("%s-baseline", cd.Spec.TargetRef.Name)
+	err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Delete(context.TODO(), baselineName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("deleting deployment %s.%s failed: %w", baselineName, cd.Namespace, err)
+	}
+	return nil
+}
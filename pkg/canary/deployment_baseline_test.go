@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeploymentController_Baseline(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.initializeCanary(t)
+
+	canary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	canary.Spec.Replicas = int32p(2)
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), canary, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, mocks.controller.CreateBaseline(mocks.canary))
+	// idempotent
+	require.NoError(t, mocks.controller.CreateBaseline(mocks.canary))
+
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	baseline, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-baseline", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(2), *baseline.Spec.Replicas)
+	assert.Equal(t, primary.Spec.Template.Spec, baseline.Spec.Template.Spec)
+	// the baseline pods are selected by the primary service
+	assert.Equal(t, "podinfo-primary", baseline.Spec.Template.Labels["name"])
+	assert.Equal(t, "true", baseline.Spec.Template.Labels[baselineLabel])
+	assert.Equal(t, "true", baseline.Spec.Selector.MatchLabels[baselineLabel])
+	assert.NotContains(t, primary.Spec.Template.Labels, baselineLabel)
+
+	require.NoError(t, mocks.controller.DeleteBaseline(mocks.canary))
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-baseline", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	require.NoError(t, mocks.controller.DeleteBaseline(mocks.canary))
+}
@@ -53,16 +53,19 @@ func (c *DeploymentController) Initialize(cd *flaggerv1.Canary) (err error) {
 	}
 
 	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
-		if !cd.SkipAnalysis() {
+		if !cd.SkipAnalysis() || cd.IsTakeoverBootstrap() {
 			if err := c.IsPrimaryReady(cd); err != nil {
 				return fmt.Errorf("%w", err)
 			}
 		}
 
-		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
-			Infof("Scaling down Deployment %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
-		if err := c.ScaleToZero(cd); err != nil {
-			return fmt.Errorf("scaling down canary deployment %s.%s failed: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+		// on takeover the scheduler scales down the workload after the traffic has been switched to primary
+		if !cd.IsTakeoverBootstrap() {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+				Infof("Scaling down Deployment %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+			if err := c.ScaleToZero(cd); err != nil {
+				return fmt.Errorf("scaling down canary deployment %s.%s failed: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+			}
 		}
 	}
 
@@ -72,6 +75,11 @@ func (c *DeploymentController) Initialize(cd *flaggerv1.Canary) (err error) {
 				return fmt.Errorf(
 					"initial reconcilePrimaryHpa for %s.%s failed: %w", primaryName, cd.Namespace, err)
 			}
+		} else if isScaledObject(cd) {
+			if err := c.reconcilePrimaryScaledObject(cd, true); err != nil {
+				return fmt.Errorf(
+					"initial reconcilePrimaryScaledObject for %s.%s failed: %w", primaryName, cd.Namespace, err)
+			}
 		} else {
 			return fmt.Errorf("cd.Spec.AutoscalerRef.Kind is invalid: %s", cd.Spec.AutoscalerRef.Kind)
 		}
@@ -115,8 +123,12 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 	primaryCopy.Spec.RevisionHistoryLimit = canary.Spec.RevisionHistoryLimit
 	primaryCopy.Spec.Strategy = canary.Spec.Strategy
 
-	// update spec with primary secrets and config maps
-	primaryCopy.Spec.Template.Spec = c.getPrimaryDeploymentTemplateSpec(canary, configRefs)
+	// update spec with primary secrets, config maps and resources
+	primarySpec, err := c.getPrimaryDeploymentTemplateSpec(cd, canary, configRefs)
+	if err != nil {
+		return err
+	}
+	primaryCopy.Spec.Template.Spec = primarySpec
 
 	// update pod annotations to ensure a rolling update
 	annotations, err := makeAnnotations(canary.Spec.Template.Annotations)
@@ -126,6 +138,7 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 
 	primaryCopy.Spec.Template.Annotations = annotations
 	primaryCopy.Spec.Template.Labels = makePrimaryLabels(canary.Spec.Template.Labels, primaryLabelValue, label)
+	makePrimaryPodMetadata(cd, &primaryCopy.Spec.Template)
 
 	// apply update
 	_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
@@ -141,6 +154,11 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 				return fmt.Errorf(
 					"reconcilePrimaryHpa for %s.%s failed: %w", primaryName, cd.Namespace, err)
 			}
+		} else if isScaledObject(cd) {
+			if err := c.reconcilePrimaryScaledObject(cd, false); err != nil {
+				return fmt.Errorf(
+					"reconcilePrimaryScaledObject for %s.%s failed: %w", primaryName, cd.Namespace, err)
+			}
 		} else {
 			return fmt.Errorf("cd.Spec.AutoscalerRef.Kind is invalid: %s", cd.Spec.AutoscalerRef.Kind)
 		}
@@ -156,11 +174,19 @@ func (c *DeploymentController) HasTargetChanged(cd *flaggerv1.Canary) (bool, err
 		return false, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
 	}
 
+	// ignore the injected pod metadata
+	removePodMetadata(&canary.Spec.Template)
+
 	return hasSpecChanged(cd, canary.Spec.Template)
 }
 
 // ScaleToZero Scale sets the canary deployment replicas
 func (c *DeploymentController) ScaleToZero(cd *flaggerv1.Canary) error {
+	return c.ScaleTo(cd, 0)
+}
+
+// ScaleTo sets the canary deployment replicas
+func (c *DeploymentController) ScaleTo(cd *flaggerv1.Canary, replicas int32) error {
 	targetName := cd.Spec.TargetRef.Name
 	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
@@ -168,13 +194,30 @@ func (c *DeploymentController) ScaleToZero(cd *flaggerv1.Canary) error {
 	}
 
 	depCopy := dep.DeepCopy()
-	depCopy.Spec.Replicas = int32p(0)
+	depCopy.Spec.Replicas = int32p(replicas)
 
 	_, err = c.kubeClient.AppsV1().Deployments(dep.Namespace).Update(context.TODO(), depCopy, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("deployment %s.%s update query error: %w", targetName, cd.Namespace, err)
 	}
-	return nil
+
+	// KEDA would otherwise scale the deployment back up
+	if isScaledObject(cd) {
+		if err := c.pauseScaledObject(cd, int32p(replicas)); err != nil {
+			return err
+		}
+	}
+	return c.restoreHpaReplicas(cd)
+}
+
+// SaveFailureReport captures the canary pods logs and events in a config map
+func (c *DeploymentController) SaveFailureReport(cd *flaggerv1.Canary) (string, error) {
+	targetName := cd.Spec.TargetRef.Name
+	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+	return saveFailureReport(c.kubeClient, cd, dep.Spec.Selector)
 }
 
 func (c *DeploymentController) ScaleFromZero(cd *flaggerv1.Canary) error {
@@ -190,11 +233,21 @@ func (c *DeploymentController) ScaleFromZero(cd *flaggerv1.Canary) error {
 	}
 	depCopy := dep.DeepCopy()
 	depCopy.Spec.Replicas = replicas
+	injectCanaryPodMetadata(cd, &depCopy.Spec.Template)
 
 	_, err = c.kubeClient.AppsV1().Deployments(dep.Namespace).Update(context.TODO(), depCopy, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("scaling up %s.%s to %v failed: %v", depCopy.GetName(), depCopy.Namespace, replicas, err)
 	}
+
+	// a queue worker canary is pinned to its initial replicas until
+	// the keda router assigns it a share of the consumers
+	if isScaledObject(cd) {
+		if cd.IsWorker() {
+			return c.pauseScaledObject(cd, replicas)
+		}
+		return c.pauseScaledObject(cd, nil)
+	}
 	return nil
 }
 
@@ -252,6 +305,11 @@ func (c *DeploymentController) createPrimaryDeployment(cd *flaggerv1.Canary, inc
 			return fmt.Errorf("makeAnnotations failed: %w", err)
 		}
 
+		primarySpec, err := c.getPrimaryDeploymentTemplateSpec(cd, canaryDep, configRefs)
+		if err != nil {
+			return err
+		}
+
 		replicas := int32(1)
 		if canaryDep.Spec.Replicas != nil && *canaryDep.Spec.Replicas > 0 {
 			replicas = *canaryDep.Spec.Replicas
@@ -288,12 +346,14 @@ func (c *DeploymentController) createPrimaryDeployment(cd *flaggerv1.Canary, inc
 						Labels:      makePrimaryLabels(canaryDep.Spec.Template.Labels, primaryLabelValue, label),
 						Annotations: annotations,
 					},
-					// update spec with the primary secrets and config maps
-					Spec: c.getPrimaryDeploymentTemplateSpec(canaryDep, configRefs),
+					// update spec with the primary secrets, config maps and resources
+					Spec: primarySpec,
 				},
 			},
 		}
 
+		makePrimaryPodMetadata(cd, &primaryDep.Spec.Template)
+
 		_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Create(context.TODO(), primaryDep, metav1.CreateOptions{})
 		if err != nil {
 			return fmt.Errorf("creating deployment %s.%s failed: %w", primaryDep.Name, cd.Namespace, err)
@@ -314,14 +374,16 @@ func (c *DeploymentController) reconcilePrimaryHpa(cd *flaggerv1.Canary, init bo
 			cd.Spec.AutoscalerRef.Name, cd.Namespace, err)
 	}
 
+	// ignore the replicas scaled down to the canary weight
+	minReplicas, maxReplicas := hpaReplicas(hpa)
 	hpaSpec := hpav2.HorizontalPodAutoscalerSpec{
 		ScaleTargetRef: hpav2.CrossVersionObjectReference{
 			Name:       primaryName,
 			Kind:       hpa.Spec.ScaleTargetRef.Kind,
 			APIVersion: hpa.Spec.ScaleTargetRef.APIVersion,
 		},
-		MinReplicas: hpa.Spec.MinReplicas,
-		MaxReplicas: hpa.Spec.MaxReplicas,
+		MinReplicas: int32p(minReplicas),
+		MaxReplicas: maxReplicas,
 		Metrics:     hpa.Spec.Metrics,
 		Behavior:    hpa.Spec.Behavior,
 	}
@@ -406,6 +468,15 @@ func (c *DeploymentController) HaveDependenciesChanged(cd *flaggerv1.Canary) (bo
 // during a delete to attempt to revert the deployment back to the original state.  Error is returned if unable
 // update the reference deployment replicas to the primary replicas
 func (c *DeploymentController) Finalize(cd *flaggerv1.Canary) error {
+	// resume the autoscaling of the reference deployment
+	if isScaledObject(cd) {
+		if err := c.pauseScaledObject(cd, nil); err != nil {
+			return err
+		}
+	}
+	if err := c.restoreHpaReplicas(cd); err != nil {
+		return err
+	}
 
 	// get ref deployment
 	refDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
@@ -453,43 +524,15 @@ func (c *DeploymentController) scale(cd *flaggerv1.Canary, replicas int32) error
 	return nil
 }
 
-func (c *DeploymentController) getPrimaryDeploymentTemplateSpec(canaryDep *appsv1.Deployment, refs map[string]ConfigRef) corev1.PodSpec {
-	spec := c.configTracker.ApplyPrimaryConfigs(canaryDep.Spec.Template.Spec, refs)
-
-	// update TopologySpreadConstraints
-	for _, topologySpreadConstraint := range spec.TopologySpreadConstraints {
-		c.appendPrimarySuffixToValuesIfNeeded(topologySpreadConstraint.LabelSelector, canaryDep)
-	}
-
-	// update affinity
-	if affinity := spec.Affinity; affinity != nil {
-		if podAntiAffinity := affinity.PodAntiAffinity; podAntiAffinity != nil {
-			for _, preferredAntiAffinity := range podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
-				c.appendPrimarySuffixToValuesIfNeeded(preferredAntiAffinity.PodAffinityTerm.LabelSelector, canaryDep)
-			}
-
-			for _, requiredAntiAffinity := range podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
-				c.appendPrimarySuffixToValuesIfNeeded(requiredAntiAffinity.LabelSelector, canaryDep)
-			}
-		}
+func (c *DeploymentController) getPrimaryDeploymentTemplateSpec(cd *flaggerv1.Canary, canaryDep *appsv1.Deployment, refs map[string]ConfigRef) (corev1.PodSpec, error) {
+	spec, err := makePrimaryResources(cd, c.configTracker.ApplyPrimaryConfigs(canaryDep.Spec.Template.Spec, refs))
+	if err != nil {
+		return spec, err
 	}
 
-	return spec
-}
-
-func (c *DeploymentController) appendPrimarySuffixToValuesIfNeeded(labelSelector *metav1.LabelSelector, canaryDep *appsv1.Deployment) {
-	if labelSelector != nil {
-		for _, matchExpression := range labelSelector.MatchExpressions {
-			if contains(c.labels, matchExpression.Key) {
-				for i := range matchExpression.Values {
-					if matchExpression.Values[i] == canaryDep.Name {
-						matchExpression.Values[i] += "-primary"
-						break
-					}
-				}
-			}
-		}
-	}
+	// point the affinity and topology spread selectors to the primary pods
+	_, labelValue, _ := c.getSelectorLabel(canaryDep)
+	return makePrimaryScheduling(cd, spec, c.labels, canaryDep.Name, labelValue), nil
 }
 
 func contains(slice []string, val string) bool {
@@ -30,6 +30,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	kedav1alpha1 "github.com/fluxcd/flagger/pkg/apis/keda/v1alpha1"
 )
 
 func TestDeploymentController_Sync_ConsistentNaming(t *testing.T) {
@@ -124,6 +125,82 @@ func TestDeploymentController_Promote(t *testing.T) {
 	assert.Equal(t, "podinfo-primary", value)
 }
 
+func TestDeploymentController_PrimaryResources(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.canary.Spec.Primary = &flaggerv1.CanaryPrimary{ResourcesMultiplier: "2.5"}
+	mocks.initializeCanary(t)
+
+	depPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	cpu := depPrimary.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+	assert.Equal(t, int64(2500), cpu.Value())
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cpu = dep.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+	assert.Equal(t, int64(1000), cpu.Value())
+
+	mocks.canary.Spec.Primary.Containers = []flaggerv1.CanaryPrimaryContainer{{
+		Name: "podinfo",
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		},
+	}}
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+
+	depPrimary, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	resources := depPrimary.Spec.Template.Spec.Containers[0].Resources
+	assert.Empty(t, resources.Requests)
+	assert.Equal(t, resource.MustParse("1Gi"), resources.Limits[corev1.ResourceMemory])
+
+	mocks.canary.Spec.Primary.ResourcesMultiplier = "-1"
+	err = mocks.controller.Promote(mocks.canary)
+	require.Error(t, err)
+}
+
+func TestDeploymentController_PodMetadata(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.canary.Spec.PodMetadata = &flaggerv1.CanaryPodMetadata{Env: true, Labels: true}
+	mocks.initializeCanary(t)
+
+	err := mocks.controller.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing})
+	require.NoError(t, err)
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	err = mocks.controller.ScaleFromZero(cd)
+	require.NoError(t, err)
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "canary", dep.Spec.Template.Labels[podRoleLabel])
+	assert.Equal(t, cd.Status.LastAppliedSpec, dep.Spec.Template.Labels[podRunIDLabel])
+	assert.Contains(t, dep.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: podRoleEnv, Value: "canary"})
+	assert.Contains(t, dep.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: podRunIDEnv, Value: cd.Status.LastAppliedSpec})
+	assert.Contains(t, dep.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: podInitialWeightEnv, Value: "10"})
+
+	// the injected metadata is not a new revision
+	changed, err := mocks.controller.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	err = mocks.controller.Promote(cd)
+	require.NoError(t, err)
+
+	depPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "primary", depPrimary.Spec.Template.Labels[podRoleLabel])
+	assert.Empty(t, depPrimary.Spec.Template.Labels[podRunIDLabel])
+	assert.Contains(t, depPrimary.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: podRoleEnv, Value: "primary"})
+	for _, env := range depPrimary.Spec.Template.Spec.Containers[0].Env {
+		assert.NotEqual(t, podRunIDEnv, env.Name)
+	}
+}
+
 func TestDeploymentController_ScaleToZero(t *testing.T) {
 	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
 	mocks := newDeploymentFixture(dc)
@@ -137,6 +214,50 @@ func TestDeploymentController_ScaleToZero(t *testing.T) {
 	assert.Equal(t, int32(0), *c.Spec.Replicas)
 }
 
+func TestDeploymentController_ScaledObject(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.canary.Spec.Provider = flaggerv1.KedaProvider
+	mocks.canary.Spec.AutoscalerRef = &flaggerv1.CrossNamespaceObjectReference{
+		Name:       "podinfo",
+		APIVersion: "keda.sh/v1alpha1",
+		Kind:       "ScaledObject",
+	}
+	maxReplicas := int32(10)
+	_, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Create(context.TODO(), &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef:  &kedav1alpha1.ScaleTarget{Name: "podinfo"},
+			MaxReplicaCount: &maxReplicas,
+			Triggers: []kedav1alpha1.ScaleTriggers{
+				{Type: "kafka", Metadata: map[string]string{"topic": "orders"}},
+			},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	mocks.initializeCanary(t)
+
+	primary, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", primary.Spec.ScaleTargetRef.Name)
+	assert.Equal(t, maxReplicas, *primary.Spec.MaxReplicaCount)
+	assert.Equal(t, "kafka", primary.Spec.Triggers[0].Type)
+
+	pausedReplicas := func() string {
+		so, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		return so.Annotations[kedav1alpha1.PausedReplicasAnnotation]
+	}
+	assert.Equal(t, "0", pausedReplicas())
+
+	require.NoError(t, mocks.controller.ScaleFromZero(mocks.canary))
+	assert.Equal(t, "1", pausedReplicas())
+
+	require.NoError(t, mocks.controller.Finalize(mocks.canary))
+	assert.Equal(t, "", pausedReplicas())
+}
+
 func TestDeploymentController_NoConfigTracking(t *testing.T) {
 	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
 	mocks := newDeploymentFixture(dc)
@@ -297,3 +418,53 @@ func TestDeploymentController_AntiAffinityAndTopologySpreadConstraints(t *testin
 		assert.False(t, strings.HasSuffix(value, "-primary"))
 	})
 }
+
+func TestDeploymentController_SaveFailureReport(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.controller.Initialize(mocks.canary)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "podinfo-1",
+			Namespace: "default",
+			UID:       "podinfo-1-uid",
+			Labels:    map[string]string{"name": "podinfo"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "podinfo"}},
+		},
+	}
+	_, err := mocks.kubeClient.CoreV1().Pods("default").Create(context.TODO(), pod, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	event := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "podinfo-1.backoff", Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "podinfo-1", UID: "podinfo-1-uid"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+	}
+	_, err = mocks.kubeClient.CoreV1().Events("default").Create(context.TODO(), event, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	// the report is disabled by default
+	name, err := mocks.controller.SaveFailureReport(mocks.canary)
+	require.NoError(t, err)
+	assert.Empty(t, name)
+
+	cd := mocks.canary.DeepCopy()
+	cd.Spec.Analysis.FailureReport = &flaggerv1.CanaryFailureReport{TailLines: 10}
+	name, err = mocks.controller.SaveFailureReport(cd)
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-failure-report", name)
+
+	cm, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, cm.Data, "podinfo-1.podinfo.log")
+	assert.Contains(t, cm.Data["events"], "BackOff podinfo-1: Back-off restarting failed container")
+
+	// the report is overwritten by the next rollback
+	_, err = mocks.controller.SaveFailureReport(cd)
+	require.NoError(t, err)
+}
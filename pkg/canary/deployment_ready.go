@@ -18,6 +18,7 @@ package canary
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -59,6 +60,13 @@ func (c *DeploymentController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error)
 		return true, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
 	}
 
+	if err := checkPodHealth(c.kubeClient, cd, canary.Spec.Selector); err != nil {
+		return !errors.Is(err, ErrPodUnhealthy), fmt.Errorf(
+			"canary deployment %s.%s not healthy: %w",
+			targetName, cd.Namespace, err,
+		)
+	}
+
 	retryable, err := c.isDeploymentReady(canary, cd.GetProgressDeadlineSeconds(), 100)
 	if err != nil {
 		return retryable, fmt.Errorf(
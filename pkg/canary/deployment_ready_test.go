@@ -17,6 +17,8 @@ limitations under the License.
 package canary
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -24,7 +26,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 )
 
 func TestDeploymentController_IsReady(t *testing.T) {
@@ -39,6 +44,111 @@ func TestDeploymentController_IsReady(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestDeploymentController_IsCanaryReady_PodHealth(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.controller.Initialize(mocks.canary)
+
+	pod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "podinfo-1",
+			Namespace: "default",
+			Labels:    map[string]string{"name": "podinfo"},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "podinfo",
+				RestartCount: 3,
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+				},
+			}},
+		},
+	}
+	_, err := mocks.kubeClient.CoreV1().Pods("default").Create(context.TODO(), pod, v1.CreateOptions{})
+	require.NoError(t, err)
+
+	// policy is not evaluated outside of the analysis
+	cd := mocks.canary.DeepCopy()
+	cd.Spec.Analysis.PodHealth = &flaggerv1.CanaryPodHealth{CrashLoopBackOff: true}
+	_, err = mocks.controller.IsCanaryReady(cd)
+	require.NoError(t, err)
+
+	cd.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	retriable, err := mocks.controller.IsCanaryReady(cd)
+	require.Error(t, err)
+	assert.False(t, retriable)
+	assert.True(t, errors.Is(err, ErrPodUnhealthy))
+	assert.True(t, strings.Contains(err.Error(), "CrashLoopBackOff"))
+
+	cd.Spec.Analysis.PodHealth = &flaggerv1.CanaryPodHealth{MaxRestarts: int32p(5)}
+	_, err = mocks.controller.IsCanaryReady(cd)
+	require.NoError(t, err)
+
+	cd.Spec.Analysis.PodHealth = &flaggerv1.CanaryPodHealth{MaxRestarts: int32p(2)}
+	retriable, err = mocks.controller.IsCanaryReady(cd)
+	require.Error(t, err)
+	assert.False(t, retriable)
+	assert.True(t, strings.Contains(err.Error(), "maxRestarts 2"))
+}
+
+func TestDeploymentController_IsCanaryReady_PodEvents(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.controller.Initialize(mocks.canary)
+
+	pod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "podinfo-1",
+			Namespace: "default",
+			UID:       "podinfo-1-uid",
+			Labels:    map[string]string{"name": "podinfo"},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "podinfo",
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"},
+				},
+			}},
+		},
+	}
+	_, err := mocks.kubeClient.CoreV1().Pods("default").Create(context.TODO(), pod, v1.CreateOptions{})
+	require.NoError(t, err)
+
+	event := &corev1.Event{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "podinfo-1.scheduling",
+			Namespace: "default",
+		},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "podinfo-1", UID: "podinfo-1-uid"},
+		Reason:         "FailedScheduling",
+		Count:          3,
+	}
+	_, err = mocks.kubeClient.CoreV1().Events("default").Create(context.TODO(), event, v1.CreateOptions{})
+	require.NoError(t, err)
+
+	cd := mocks.canary.DeepCopy()
+	cd.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	cd.Spec.Analysis.PodHealth = &flaggerv1.CanaryPodHealth{
+		Events: []flaggerv1.CanaryPodEvent{{Reason: "FailedScheduling", MaxCount: 5}},
+	}
+	_, err = mocks.controller.IsCanaryReady(cd)
+	require.NoError(t, err)
+
+	cd.Spec.Analysis.PodHealth.Events = []flaggerv1.CanaryPodEvent{{Reason: "FailedScheduling", MaxCount: 2}}
+	retriable, err := mocks.controller.IsCanaryReady(cd)
+	require.Error(t, err)
+	assert.False(t, retriable)
+	assert.True(t, errors.Is(err, ErrPodUnhealthy))
+	assert.True(t, strings.Contains(err.Error(), "FailedScheduling occurred 3 times"))
+
+	cd.Spec.Analysis.PodHealth.Events = []flaggerv1.CanaryPodEvent{{Reason: "ImagePullBackOff"}}
+	_, err = mocks.controller.IsCanaryReady(cd)
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "ImagePullBackOff"))
+}
+
 func TestDeploymentController_isDeploymentReady(t *testing.T) {
 	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
 	mocks := newDeploymentFixture(dc)
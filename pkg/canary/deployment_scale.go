@@ -0,0 +1,157 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	hpav2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// hpaReplicasAnnotation holds the min and max replicas of the canary HPA
+// before they were scaled down to the canary traffic weight
+const hpaReplicasAnnotation = "flagger.app/hpa-replicas"
+
+// ScaleToWeight sizes the canary deployment proportionally to its traffic weight,
+// when an HPA targets the canary its min and max replicas are scaled instead
+func (c *DeploymentController) ScaleToWeight(cd *flaggerv1.Canary, canaryWeight int) error {
+	if isScaledObject(cd) {
+		return nil
+	}
+	if cd.Spec.AutoscalerRef != nil && cd.Spec.AutoscalerRef.Kind == "HorizontalPodAutoscaler" {
+		return c.scaleHpaToWeight(cd, canaryWeight)
+	}
+
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", primaryName, cd.Namespace, err)
+	}
+	canary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+	}
+
+	replicas := weightReplicas(int32Default(primary.Spec.Replicas), canaryWeight)
+	if int32Default(canary.Spec.Replicas) == replicas {
+		return nil
+	}
+	if err := c.scale(cd, replicas); err != nil {
+		return err
+	}
+	c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("Scaled deployment %s.%s to %v replicas for weight %v", canary.Name, cd.Namespace, replicas, canaryWeight)
+	return nil
+}
+
+// scaleHpaToWeight sets the canary HPA min and max replicas to the canary weight
+// share of the replicas declared in the HPA, the declared values are kept in an annotation
+func (c *DeploymentController) scaleHpaToWeight(cd *flaggerv1.Canary, canaryWeight int) error {
+	name := cd.Spec.AutoscalerRef.Name
+	hpa, err := c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("HorizontalPodAutoscaler %s.%s get query error: %w", name, cd.Namespace, err)
+	}
+
+	minReplicas, maxReplicas := hpaReplicas(hpa)
+	scaledMin := weightReplicas(minReplicas, canaryWeight)
+	scaledMax := weightReplicas(maxReplicas, canaryWeight)
+	if int32Default(hpa.Spec.MinReplicas) == scaledMin && hpa.Spec.MaxReplicas == scaledMax {
+		return nil
+	}
+
+	hpaCopy := hpa.DeepCopy()
+	if hpaCopy.Annotations == nil {
+		hpaCopy.Annotations = make(map[string]string)
+	}
+	hpaCopy.Annotations[hpaReplicasAnnotation] = fmt.Sprintf("%d,%d", minReplicas, maxReplicas)
+	hpaCopy.Spec.MinReplicas = int32p(scaledMin)
+	hpaCopy.Spec.MaxReplicas = scaledMax
+
+	_, err = c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(cd.Namespace).Update(context.TODO(), hpaCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("updating HorizontalPodAutoscaler %s.%s failed: %w", name, cd.Namespace, err)
+	}
+	c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("Scaled HorizontalPodAutoscaler %s.%s to %v-%v replicas for weight %v", name, cd.Namespace, scaledMin, scaledMax, canaryWeight)
+	return nil
+}
+
+// restoreHpaReplicas sets back the min and max replicas declared in the canary HPA
+func (c *DeploymentController) restoreHpaReplicas(cd *flaggerv1.Canary) error {
+	if cd.Spec.AutoscalerRef == nil || cd.Spec.AutoscalerRef.Kind != "HorizontalPodAutoscaler" {
+		return nil
+	}
+
+	name := cd.Spec.AutoscalerRef.Name
+	hpa, err := c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("HorizontalPodAutoscaler %s.%s get query error: %w", name, cd.Namespace, err)
+	}
+	if _, ok := hpa.Annotations[hpaReplicasAnnotation]; !ok {
+		return nil
+	}
+
+	hpaCopy := hpa.DeepCopy()
+	minReplicas, maxReplicas := hpaReplicas(hpa)
+	hpaCopy.Spec.MinReplicas = int32p(minReplicas)
+	hpaCopy.Spec.MaxReplicas = maxReplicas
+	delete(hpaCopy.Annotations, hpaReplicasAnnotation)
+
+	_, err = c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(cd.Namespace).Update(context.TODO(), hpaCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("updating HorizontalPodAutoscaler %s.%s failed: %w", name, cd.Namespace, err)
+	}
+	return nil
+}
+
+// hpaReplicas returns the min and max replicas declared in the HPA,
+// ignoring the values set by the proportional scaling
+func hpaReplicas(hpa *hpav2.HorizontalPodAutoscaler) (int32, int32) {
+	if v, ok := hpa.Annotations[hpaReplicasAnnotation]; ok {
+		parts := strings.Split(v, ",")
+		if len(parts) == 2 {
+			minReplicas, errMin := strconv.ParseInt(parts[0], 10, 32)
+			maxReplicas, errMax := strconv.ParseInt(parts[1], 10, 32)
+			if errMin == nil && errMax == nil {
+				return int32(minReplicas), int32(maxReplicas)
+			}
+		}
+	}
+	return int32Default(hpa.Spec.MinReplicas), hpa.Spec.MaxReplicas
+}
+
+// weightReplicas returns the share of the replicas matching the traffic weight, rounded up
+func weightReplicas(replicas int32, weight int) int32 {
+	scaled := (int64(replicas)*int64(weight) + 99) / 100
+	if scaled < 1 {
+		return 1
+	}
+	if scaled > int64(replicas) {
+		return replicas
+	}
+	return int32(scaled)
+}
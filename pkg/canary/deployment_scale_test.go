@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeploymentController_ScaleToWeight(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.canary.Spec.AutoscalerRef = nil
+	mocks.initializeCanary(t)
+
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	primary.Spec.Replicas = int32p(10)
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), primary, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	for weight, replicas := range map[int]int32{5: 1, 25: 3, 50: 5, 100: 10} {
+		require.NoError(t, mocks.controller.ScaleToWeight(mocks.canary, weight))
+		dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, replicas, *dep.Spec.Replicas, "weight %v", weight)
+	}
+}
+
+func TestDeploymentController_ScaleToWeightHPA(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.initializeCanary(t)
+
+	hpa, err := mocks.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	hpa.Spec.MinReplicas = int32p(4)
+	hpa.Spec.MaxReplicas = 20
+	_, err = mocks.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Update(context.TODO(), hpa, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, mocks.controller.ScaleToWeight(mocks.canary, 10))
+	require.NoError(t, mocks.controller.ScaleToWeight(mocks.canary, 30))
+
+	hpa, err = mocks.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *hpa.Spec.MinReplicas)
+	assert.Equal(t, int32(6), hpa.Spec.MaxReplicas)
+
+	// the primary HPA gets the declared replicas
+	require.NoError(t, mocks.controller.Promote(mocks.canary))
+	primaryHpa, err := mocks.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), *primaryHpa.Spec.MinReplicas)
+	assert.Equal(t, int32(20), primaryHpa.Spec.MaxReplicas)
+
+	// the declared replicas are restored when the canary is scaled down
+	require.NoError(t, mocks.controller.ScaleToZero(mocks.canary))
+	hpa, err = mocks.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), *hpa.Spec.MinReplicas)
+	assert.Equal(t, int32(20), hpa.Spec.MaxReplicas)
+	assert.NotContains(t, hpa.Annotations, hpaReplicasAnnotation)
+}
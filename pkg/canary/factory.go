@@ -64,6 +64,14 @@ func (factory *Factory) Controller(kind string) Controller {
 		labels:        factory.labels,
 		configTracker: factory.configTracker,
 	}
+	statefulSetCtrl := &StatefulSetController{
+		logger:             factory.logger,
+		kubeClient:         factory.kubeClient,
+		flaggerClient:      factory.flaggerClient,
+		labels:             factory.labels,
+		configTracker:      factory.configTracker,
+		includeLabelPrefix: factory.includeLabelPrefix,
+	}
 	serviceCtrl := &ServiceController{
 		logger:        factory.logger,
 		kubeClient:    factory.kubeClient,
@@ -75,6 +83,8 @@ func (factory *Factory) Controller(kind string) Controller {
 		return daemonSetCtrl
 	case "Deployment":
 		return deploymentCtrl
+	case "StatefulSet":
+		return statefulSetCtrl
 	case "Service":
 		return serviceCtrl
 	default:
@@ -0,0 +1,183 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	failureReportTailLines = 100
+	failureReportMaxPods   = 3
+	failureReportMaxEvents = 50
+	failureReportLogBytes  = 64 * 1024
+	failureReportEventsKey = "events"
+)
+
+// saveFailureReport stores the last log lines and the recent events of the canary pods
+// in a config map owned by the canary, so that the failure can be inspected after
+// the canary is scaled to zero, returns the config map name
+func saveFailureReport(kubeClient kubernetes.Interface, cd *flaggerv1.Canary, selector *metav1.LabelSelector) (string, error) {
+	report := cd.GetAnalysis().FailureReport
+	if report == nil || selector == nil {
+		return "", nil
+	}
+
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return "", fmt.Errorf("invalid selector for %s.%s: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+	}
+
+	pods, err := kubeClient.CoreV1().Pods(cd.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labelSelector.String(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("pods %s.%s list query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+	}
+
+	tailLines := report.TailLines
+	if tailLines < 1 {
+		tailLines = failureReportTailLines
+	}
+	maxPods := report.MaxPods
+	if maxPods < 1 {
+		maxPods = failureReportMaxPods
+	}
+
+	// capture the failing pods first
+	items := pods.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		return podFailureScore(items[i]) > podFailureScore(items[j])
+	})
+	if len(items) > maxPods {
+		items = items[:maxPods]
+	}
+
+	data := make(map[string]string)
+	uids := make(map[types.UID]bool)
+	limitBytes := int64(failureReportLogBytes)
+	for _, pod := range items {
+		uids[pod.UID] = true
+		for _, container := range pod.Spec.Containers {
+			logs, err := kubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+				Container:  container.Name,
+				TailLines:  &tailLines,
+				LimitBytes: &limitBytes,
+			}).Do(context.TODO()).Raw()
+			if err != nil {
+				logs = []byte(fmt.Sprintf("logs query error: %v", err))
+			}
+			data[fmt.Sprintf("%s.%s.log", pod.Name, container.Name)] = string(logs)
+		}
+	}
+
+	events, err := kubeClient.CoreV1().Events(cd.Namespace).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.kind", "Pod").String(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("events list query error: %w", err)
+	}
+	data[failureReportEventsKey] = formatPodEvents(events.Items, uids)
+
+	name := fmt.Sprintf("%s-failure-report", cd.Name)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cd.Namespace,
+			Annotations: map[string]string{
+				"flagger.app/revision": cd.Status.LastAppliedSpec,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+					Group:   flaggerv1.SchemeGroupVersion.Group,
+					Version: flaggerv1.SchemeGroupVersion.Version,
+					Kind:    flaggerv1.CanaryKind,
+				}),
+			},
+		},
+		Data: data,
+	}
+
+	existing, err := kubeClient.CoreV1().ConfigMaps(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		if _, err := kubeClient.CoreV1().ConfigMaps(cd.Namespace).Create(context.TODO(), cm, metav1.CreateOptions{}); err != nil {
+			return "", fmt.Errorf("configmap %s.%s create error: %w", name, cd.Namespace, err)
+		}
+		return name, nil
+	} else if err != nil {
+		return "", fmt.Errorf("configmap %s.%s get query error: %w", name, cd.Namespace, err)
+	}
+
+	clone := existing.DeepCopy()
+	clone.Annotations = cm.Annotations
+	clone.Data = cm.Data
+	if _, err := kubeClient.CoreV1().ConfigMaps(cd.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("configmap %s.%s update error: %w", name, cd.Namespace, err)
+	}
+	return name, nil
+}
+
+// podFailureScore ranks the pods by how unhealthy they look
+func podFailureScore(pod corev1.Pod) int {
+	score := 0
+	if pod.Status.Phase == corev1.PodFailed {
+		score += 100
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if !cs.Ready {
+			score += 10
+		}
+		score += int(cs.RestartCount)
+	}
+	return score
+}
+
+// formatPodEvents returns the most recent events of the pods, one per line
+func formatPodEvents(events []corev1.Event, uids map[types.UID]bool) string {
+	var matched []corev1.Event
+	for _, event := range events {
+		if uids[event.InvolvedObject.UID] {
+			matched = append(matched, event)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].LastTimestamp.Before(&matched[j].LastTimestamp)
+	})
+	if len(matched) > failureReportMaxEvents {
+		matched = matched[len(matched)-failureReportMaxEvents:]
+	}
+
+	var b strings.Builder
+	for _, event := range matched {
+		fmt.Fprintf(&b, "%s %s %s %s: %s\n", event.LastTimestamp.UTC().Format("2006-01-02T15:04:05Z"),
+			event.Type, event.Reason, event.InvolvedObject.Name, strings.TrimSpace(event.Message))
+	}
+	return b.String()
+}
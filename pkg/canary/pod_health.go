@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// ErrPodUnhealthy is returned when the canary pods violate the analysis pod health policy
+var ErrPodUnhealthy = errors.New("canary pods unhealthy")
+
+// checkPodHealth lists the canary pods matching the selector and returns an ErrPodUnhealthy error
+// if any container violates the analysis pod health policy,
+// the check runs only while the canary analysis is in progress
+func checkPodHealth(kubeClient kubernetes.Interface, cd *flaggerv1.Canary, selector *metav1.LabelSelector) error {
+	policy := cd.GetAnalysis().PodHealth
+	if policy == nil || cd.Status.Phase != flaggerv1.CanaryPhaseProgressing || selector == nil {
+		return nil
+	}
+
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return fmt.Errorf("invalid selector for %s.%s: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+	}
+
+	pods, err := kubeClient.CoreV1().Pods(cd.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labelSelector.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("pods %s.%s list query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+	}
+
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if err := checkContainersHealth(policy, pod); err != nil {
+			return fmt.Errorf("%w: pod %s.%s %v", ErrPodUnhealthy, pod.Name, pod.Namespace, err)
+		}
+	}
+
+	if len(policy.Events) > 0 {
+		if err := checkPodEvents(kubeClient, policy, cd.Namespace, pods.Items); err != nil {
+			return fmt.Errorf("%w: %s.%s %v", ErrPodUnhealthy, cd.Spec.TargetRef.Name, cd.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// checkPodEvents counts the occurrences of the policy event reasons across the canary pods
+func checkPodEvents(kubeClient kubernetes.Interface, policy *flaggerv1.CanaryPodHealth, namespace string, pods []corev1.Pod) error {
+	uids := make(map[types.UID]bool)
+	counts := make(map[string]int32)
+	for _, pod := range pods {
+		uids[pod.UID] = true
+		if pod.Status.Reason != "" {
+			counts[pod.Status.Reason]++
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+				counts[cs.State.Waiting.Reason]++
+			}
+		}
+	}
+
+	events, err := kubeClient.CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.kind", "Pod").String(),
+	})
+	if err != nil {
+		return fmt.Errorf("events list query error: %w", err)
+	}
+	for _, event := range events.Items {
+		if !uids[event.InvolvedObject.UID] {
+			continue
+		}
+		count := event.Count
+		if event.Series != nil && event.Series.Count > count {
+			count = event.Series.Count
+		}
+		if count < 1 {
+			count = 1
+		}
+		counts[event.Reason] += count
+	}
+
+	for _, e := range policy.Events {
+		if counts[e.Reason] > e.MaxCount {
+			return fmt.Errorf("%s occurred %d times (maxCount %d)", e.Reason, counts[e.Reason], e.MaxCount)
+		}
+	}
+	return nil
+}
+
+func checkContainersHealth(policy *flaggerv1.CanaryPodHealth, pod corev1.Pod) error {
+	startupProbes := make(map[string]bool)
+	for _, c := range pod.Spec.Containers {
+		startupProbes[c.Name] = c.StartupProbe != nil
+	}
+
+	var restarts int32
+	for _, cs := range pod.Status.ContainerStatuses {
+		restarts += cs.RestartCount
+
+		if policy.CrashLoopBackOff && cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+			return fmt.Errorf("container %s is in CrashLoopBackOff", cs.Name)
+		}
+		if policy.OOMKilled && isOOMKilled(cs) {
+			return fmt.Errorf("container %s was OOMKilled", cs.Name)
+		}
+		if policy.StartupProbe && startupProbes[cs.Name] && cs.RestartCount > 0 &&
+			(cs.Started == nil || !*cs.Started) {
+			return fmt.Errorf("container %s startup probe is failing", cs.Name)
+		}
+	}
+
+	if policy.MaxRestarts != nil && restarts > *policy.MaxRestarts {
+		return fmt.Errorf("containers restarted %d times (maxRestarts %d)", restarts, *policy.MaxRestarts)
+	}
+	return nil
+}
+
+func isOOMKilled(cs corev1.ContainerStatus) bool {
+	if cs.State.Terminated != nil && cs.State.Terminated.Reason == "OOMKilled" {
+		return true
+	}
+	return cs.LastTerminationState.Terminated != nil && cs.LastTerminationState.Terminated.Reason == "OOMKilled"
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	podRoleEnv          = "FLAGGER_ROLE"
+	podRunIDEnv         = "FLAGGER_RUN_ID"
+	podInitialWeightEnv = "FLAGGER_INITIAL_WEIGHT"
+
+	podRoleLabel  = "flagger.app/role"
+	podRunIDLabel = "flagger.app/run-id"
+)
+
+// injectCanaryPodMetadata sets the canary role, the analysis run ID and the initial weight
+// on the canary pod template, the run ID is the hash of the template without the injected metadata
+func injectCanaryPodMetadata(cd *flaggerv1.Canary, template *corev1.PodTemplateSpec) {
+	removePodMetadata(template)
+	if cd.Spec.PodMetadata == nil {
+		return
+	}
+
+	runID := computeHash(*template)
+	if cd.Spec.PodMetadata.Env {
+		setPodEnv(template, map[string]string{
+			podRoleEnv:          "canary",
+			podRunIDEnv:         runID,
+			podInitialWeightEnv: strconv.Itoa(initialCanaryWeight(cd)),
+		})
+	}
+	if cd.Spec.PodMetadata.Labels {
+		if template.Labels == nil {
+			template.Labels = make(map[string]string)
+		}
+		template.Labels[podRoleLabel] = "canary"
+		template.Labels[podRunIDLabel] = runID
+	}
+}
+
+// makePrimaryPodMetadata replaces the metadata copied over from the canary with the primary role
+func makePrimaryPodMetadata(cd *flaggerv1.Canary, template *corev1.PodTemplateSpec) {
+	removePodMetadata(template)
+	if cd.Spec.PodMetadata == nil {
+		return
+	}
+
+	if cd.Spec.PodMetadata.Env {
+		setPodEnv(template, map[string]string{podRoleEnv: "primary"})
+	}
+	if cd.Spec.PodMetadata.Labels {
+		if template.Labels == nil {
+			template.Labels = make(map[string]string)
+		}
+		template.Labels[podRoleLabel] = "primary"
+	}
+}
+
+// removePodMetadata deletes the injected env vars and labels,
+// it is called before hashing the canary template so that the injection doesn't count as a new revision
+func removePodMetadata(template *corev1.PodTemplateSpec) {
+	delete(template.Labels, podRoleLabel)
+	delete(template.Labels, podRunIDLabel)
+
+	for i := range template.Spec.Containers {
+		container := &template.Spec.Containers[i]
+		if container.Env == nil {
+			continue
+		}
+		env := container.Env[:0]
+		for _, e := range container.Env {
+			if e.Name != podRoleEnv && e.Name != podRunIDEnv && e.Name != podInitialWeightEnv {
+				env = append(env, e)
+			}
+		}
+		if len(env) == 0 {
+			env = nil
+		}
+		container.Env = env
+	}
+}
+
+func setPodEnv(template *corev1.PodTemplateSpec, vars map[string]string) {
+	for i := range template.Spec.Containers {
+		container := &template.Spec.Containers[i]
+		for _, name := range []string{podRoleEnv, podRunIDEnv, podInitialWeightEnv} {
+			if value, ok := vars[name]; ok {
+				container.Env = append(container.Env, corev1.EnvVar{Name: name, Value: value})
+			}
+		}
+	}
+}
+
+// initialCanaryWeight returns the traffic weight routed to the canary in the first analysis step
+func initialCanaryWeight(cd *flaggerv1.Canary) int {
+	analysis := cd.GetAnalysis()
+	if analysis == nil || analysis.Iterations > 0 {
+		return 0
+	}
+	if len(analysis.StepWeights) > 0 {
+		return analysis.StepWeights[0]
+	}
+	return analysis.StepWeight
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	kedav1alpha1 "github.com/fluxcd/flagger/pkg/apis/keda/v1alpha1"
+)
+
+func isScaledObject(cd *flaggerv1.Canary) bool {
+	return cd.Spec.AutoscalerRef != nil && cd.Spec.AutoscalerRef.Kind == "ScaledObject"
+}
+
+// reconcilePrimaryScaledObject clones the KEDA ScaledObject of the canary for the primary deployment
+func (c *DeploymentController) reconcilePrimaryScaledObject(cd *flaggerv1.Canary, init bool) error {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	so, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Get(context.TODO(), cd.Spec.AutoscalerRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s get query error: %w", cd.Spec.AutoscalerRef.Name, cd.Namespace, err)
+	}
+
+	soSpec := so.Spec.DeepCopy()
+	soSpec.ScaleTargetRef = &kedav1alpha1.ScaleTarget{Name: primaryName}
+	if so.Spec.ScaleTargetRef != nil {
+		soSpec.ScaleTargetRef.APIVersion = so.Spec.ScaleTargetRef.APIVersion
+		soSpec.ScaleTargetRef.Kind = so.Spec.ScaleTargetRef.Kind
+		soSpec.ScaleTargetRef.EnvSourceContainerName = so.Spec.ScaleTargetRef.EnvSourceContainerName
+	}
+	// KEDA names the generated HPA after the ScaledObject unless a name is set
+	if soSpec.Advanced != nil && soSpec.Advanced.HorizontalPodAutoscalerConfig != nil &&
+		soSpec.Advanced.HorizontalPodAutoscalerConfig.Name != "" {
+		soSpec.Advanced.HorizontalPodAutoscalerConfig.Name = fmt.Sprintf("%s-primary", soSpec.Advanced.HorizontalPodAutoscalerConfig.Name)
+	}
+
+	primarySoName := fmt.Sprintf("%s-primary", cd.Spec.AutoscalerRef.Name)
+	primarySo, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Get(context.TODO(), primarySoName, metav1.GetOptions{})
+
+	// create ScaledObject
+	if errors.IsNotFound(err) {
+		primarySo = &kedav1alpha1.ScaledObject{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      primarySoName,
+				Namespace: cd.Namespace,
+				Labels:    filterMetadata(so.Labels),
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: *soSpec,
+		}
+
+		_, err = c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Create(context.TODO(), primarySo, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("creating ScaledObject %s.%s failed: %w", primarySo.Name, primarySo.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("ScaledObject %s.%s created", primarySo.GetName(), cd.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s get query failed: %w", primarySoName, cd.Namespace, err)
+	}
+
+	// update ScaledObject
+	if !init {
+		// the keda router splits the replicas between primary and canary
+		if cd.IsWorker() {
+			soSpec.MinReplicaCount = primarySo.Spec.MinReplicaCount
+			soSpec.MaxReplicaCount = primarySo.Spec.MaxReplicaCount
+		}
+		if diff := cmp.Diff(*soSpec, primarySo.Spec); diff != "" {
+			soClone := primarySo.DeepCopy()
+			soClone.Spec = *soSpec
+
+			_, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Update(context.TODO(), soClone, metav1.UpdateOptions{})
+			if err != nil {
+				return fmt.Errorf("updating ScaledObject %s.%s failed: %w", soClone.Name, soClone.Namespace, err)
+			}
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+				Infof("ScaledObject %s.%s updated", primarySo.GetName(), cd.Namespace)
+		}
+	}
+	return nil
+}
+
+// pauseScaledObject stops KEDA from scaling the canary deployment away from the given replicas,
+// a nil value resumes the autoscaling
+func (c *DeploymentController) pauseScaledObject(cd *flaggerv1.Canary, replicas *int32) error {
+	name := cd.Spec.AutoscalerRef.Name
+	so, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s get query error: %w", name, cd.Namespace, err)
+	}
+
+	current, paused := so.Annotations[kedav1alpha1.PausedReplicasAnnotation]
+	var value interface{}
+	if replicas != nil {
+		if paused && current == strconv.Itoa(int(*replicas)) {
+			return nil
+		}
+		value = strconv.Itoa(int(*replicas))
+	} else if !paused {
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				kedav1alpha1.PausedReplicasAnnotation: value,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s patch marshal error: %w", name, cd.Namespace, err)
+	}
+	_, err = c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Patch(context.TODO(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s patch error: %w", name, cd.Namespace, err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// makePrimaryScheduling rewrites the label selectors of the pod affinity, anti-affinity and
+// topology spread constraints copied from the canary, the values matching the canary name or
+// selector label value are suffixed with -primary and the values matching the primary lose their suffix
+func makePrimaryScheduling(cd *flaggerv1.Canary, spec corev1.PodSpec, labels []string, values ...string) corev1.PodSpec {
+	if spec.Affinity == nil && len(spec.TopologySpreadConstraints) == 0 {
+		return spec
+	}
+	if s := cd.Spec.Primary; s != nil && s.Scheduling != nil {
+		if s.Scheduling.Disabled {
+			return spec
+		}
+		if len(s.Scheduling.LabelKeys) > 0 {
+			labels = s.Scheduling.LabelKeys
+		}
+	}
+
+	spec = *spec.DeepCopy()
+	rewrite := func(selector *metav1.LabelSelector) {
+		rewritePrimarySelector(selector, labels, values)
+	}
+
+	for i := range spec.TopologySpreadConstraints {
+		rewrite(spec.TopologySpreadConstraints[i].LabelSelector)
+	}
+	if affinity := spec.Affinity; affinity != nil {
+		if a := affinity.PodAffinity; a != nil {
+			for i := range a.PreferredDuringSchedulingIgnoredDuringExecution {
+				rewrite(a.PreferredDuringSchedulingIgnoredDuringExecution[i].PodAffinityTerm.LabelSelector)
+			}
+			for i := range a.RequiredDuringSchedulingIgnoredDuringExecution {
+				rewrite(a.RequiredDuringSchedulingIgnoredDuringExecution[i].LabelSelector)
+			}
+		}
+		if a := affinity.PodAntiAffinity; a != nil {
+			for i := range a.PreferredDuringSchedulingIgnoredDuringExecution {
+				rewrite(a.PreferredDuringSchedulingIgnoredDuringExecution[i].PodAffinityTerm.LabelSelector)
+			}
+			for i := range a.RequiredDuringSchedulingIgnoredDuringExecution {
+				rewrite(a.RequiredDuringSchedulingIgnoredDuringExecution[i].LabelSelector)
+			}
+		}
+	}
+	return spec
+}
+
+// rewritePrimarySelector swaps the canary and primary values of the selector labels
+func rewritePrimarySelector(selector *metav1.LabelSelector, labels []string, values []string) {
+	if selector == nil {
+		return
+	}
+	for key, value := range selector.MatchLabels {
+		if contains(labels, key) {
+			selector.MatchLabels[key] = swapPrimaryValue(value, values)
+		}
+	}
+	for _, expr := range selector.MatchExpressions {
+		if !contains(labels, expr.Key) {
+			continue
+		}
+		for i := range expr.Values {
+			expr.Values[i] = swapPrimaryValue(expr.Values[i], values)
+		}
+	}
+}
+
+func swapPrimaryValue(value string, values []string) string {
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		if value == v {
+			return v + "-primary"
+		}
+		if value == v+"-primary" {
+			return strings.TrimSuffix(value, "-primary")
+		}
+	}
+	return value
+}
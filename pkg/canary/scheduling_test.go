@@ -0,0 +1,69 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestMakePrimaryScheduling(t *testing.T) {
+	spec := corev1.PodSpec{
+		Affinity: &corev1.Affinity{
+			PodAffinity: &corev1.PodAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "podinfo"}},
+				}},
+			},
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"podinfo-primary", "redis"}},
+						},
+					},
+				}},
+			},
+		},
+		TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "podinfo-app"}},
+		}},
+	}
+	cd := &flaggerv1.Canary{}
+
+	primary := makePrimaryScheduling(cd, spec, []string{"app", "app.kubernetes.io/name"}, "podinfo", "podinfo-app")
+	assert.Equal(t, "podinfo-primary", primary.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchLabels["app"])
+	assert.Equal(t, []string{"podinfo", "redis"}, primary.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchExpressions[0].Values)
+	assert.Equal(t, "podinfo-app-primary", primary.TopologySpreadConstraints[0].LabelSelector.MatchLabels["app.kubernetes.io/name"])
+
+	// the canary spec is left untouched
+	assert.Equal(t, "podinfo", spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchLabels["app"])
+
+	cd.Spec.Primary = &flaggerv1.CanaryPrimary{Scheduling: &flaggerv1.CanaryPrimaryScheduling{LabelKeys: []string{"app"}}}
+	primary = makePrimaryScheduling(cd, spec, []string{"app", "app.kubernetes.io/name"}, "podinfo", "podinfo-app")
+	assert.Equal(t, "podinfo-primary", primary.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchLabels["app"])
+	assert.Equal(t, "podinfo-app", primary.TopologySpreadConstraints[0].LabelSelector.MatchLabels["app.kubernetes.io/name"])
+
+	cd.Spec.Primary.Scheduling.Disabled = true
+	primary = makePrimaryScheduling(cd, spec, []string{"app"}, "podinfo", "podinfo-app")
+	assert.Equal(t, "podinfo", primary.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchLabels["app"])
+}
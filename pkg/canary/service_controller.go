@@ -214,10 +214,18 @@ func (c *ServiceController) ScaleToZero(_ *flaggerv1.Canary) error {
 	return nil
 }
 
+func (c *ServiceController) SaveFailureReport(_ *flaggerv1.Canary) (string, error) {
+	return "", nil
+}
+
 func (c *ServiceController) ScaleFromZero(_ *flaggerv1.Canary) error {
 	return nil
 }
 
+func (c *ServiceController) ScaleTo(_ *flaggerv1.Canary, _ int32) error {
+	return nil
+}
+
 func (c *ServiceController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
 	dep, err := c.kubeClient.CoreV1().Services(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
 	if err != nil {
@@ -0,0 +1,369 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// StatefulSetController is managing the operations for Kubernetes StatefulSet kind
+type StatefulSetController struct {
+	kubeClient         kubernetes.Interface
+	flaggerClient      clientset.Interface
+	logger             *zap.SugaredLogger
+	configTracker      Tracker
+	labels             []string
+	includeLabelPrefix []string
+}
+
+// Initialize creates the primary StatefulSet and scales to zero the canary StatefulSet
+func (c *StatefulSetController) Initialize(cd *flaggerv1.Canary) (err error) {
+	if err := c.createPrimaryStatefulSet(cd, c.includeLabelPrefix); err != nil {
+		return fmt.Errorf("createPrimaryStatefulSet failed: %w", err)
+	}
+
+	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
+		if !cd.SkipAnalysis() || cd.IsTakeoverBootstrap() {
+			if err := c.IsPrimaryReady(cd); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+		}
+
+		// on takeover the scheduler scales down the workload after the traffic has been switched to primary
+		if !cd.IsTakeoverBootstrap() {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+				Infof("Scaling down StatefulSet %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+			if err := c.ScaleToZero(cd); err != nil {
+				return fmt.Errorf("scaling down canary statefulset %s.%s failed: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Promote copies the pod spec, secrets and config maps from canary to primary
+func (c *StatefulSetController) Promote(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := fmt.Sprintf("%s-primary", targetName)
+
+	canary, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	label, labelValue, err := c.getSelectorLabel(canary)
+	primaryLabelValue := fmt.Sprintf("%s-primary", labelValue)
+	if err != nil {
+		return fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	primary, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", primaryName, cd.Namespace, err)
+	}
+
+	// promote secrets and config maps
+	configRefs, err := c.configTracker.GetTargetConfigs(cd)
+	if err != nil {
+		return fmt.Errorf("GetTargetConfigs failed: %w", err)
+	}
+	if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix); err != nil {
+		return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
+	}
+
+	primaryCopy := primary.DeepCopy()
+	primaryCopy.Spec.MinReadySeconds = canary.Spec.MinReadySeconds
+	primaryCopy.Spec.RevisionHistoryLimit = canary.Spec.RevisionHistoryLimit
+	primaryCopy.Spec.UpdateStrategy = primaryUpdateStrategy(canary.Spec.UpdateStrategy)
+
+	// update spec with primary secrets, config maps and resources
+	primarySpec, err := makePrimaryResources(cd, c.configTracker.ApplyPrimaryConfigs(canary.Spec.Template.Spec, configRefs))
+	if err != nil {
+		return err
+	}
+	primarySpec = makePrimaryScheduling(cd, primarySpec, c.labels, canary.Name, labelValue)
+	primaryCopy.Spec.Template.Spec = primarySpec
+
+	// update pod annotations to ensure a rolling update
+	annotations, err := makeAnnotations(canary.Spec.Template.Annotations)
+	if err != nil {
+		return fmt.Errorf("makeAnnotations failed: %w", err)
+	}
+
+	primaryCopy.Spec.Template.Annotations = annotations
+	primaryCopy.Spec.Template.Labels = makePrimaryLabels(canary.Spec.Template.Labels, primaryLabelValue, label)
+	makePrimaryPodMetadata(cd, &primaryCopy.Spec.Template)
+
+	// apply update
+	_, err = c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("updating statefulset %s.%s template spec failed: %w",
+			primaryCopy.GetName(), primaryCopy.Namespace, err)
+	}
+	return nil
+}
+
+// HasTargetChanged returns true if the canary StatefulSet pod spec has changed
+func (c *StatefulSetController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
+	targetName := cd.Spec.TargetRef.Name
+	canary, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	// ignore the injected pod metadata
+	removePodMetadata(&canary.Spec.Template)
+
+	return hasSpecChanged(cd, canary.Spec.Template)
+}
+
+// ScaleToZero sets the canary StatefulSet replicas to zero
+func (c *StatefulSetController) ScaleToZero(cd *flaggerv1.Canary) error {
+	return c.ScaleTo(cd, 0)
+}
+
+// ScaleTo sets the canary StatefulSet replicas
+func (c *StatefulSetController) ScaleTo(cd *flaggerv1.Canary, replicas int32) error {
+	targetName := cd.Spec.TargetRef.Name
+	sts, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	stsCopy := sts.DeepCopy()
+	stsCopy.Spec.Replicas = int32p(replicas)
+
+	_, err = c.kubeClient.AppsV1().StatefulSets(sts.Namespace).Update(context.TODO(), stsCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s update query error: %w", targetName, cd.Namespace, err)
+	}
+	return nil
+}
+
+func (c *StatefulSetController) ScaleFromZero(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	sts, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	replicas := int32p(1)
+	if sts.Spec.Replicas != nil && *sts.Spec.Replicas > 0 {
+		replicas = sts.Spec.Replicas
+	}
+	stsCopy := sts.DeepCopy()
+	stsCopy.Spec.Replicas = replicas
+	injectCanaryPodMetadata(cd, &stsCopy.Spec.Template)
+
+	_, err = c.kubeClient.AppsV1().StatefulSets(sts.Namespace).Update(context.TODO(), stsCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("scaling up %s.%s to %v failed: %w", stsCopy.GetName(), stsCopy.Namespace, *replicas, err)
+	}
+	return nil
+}
+
+// SaveFailureReport captures the canary pods logs and events in a config map
+func (c *StatefulSetController) SaveFailureReport(cd *flaggerv1.Canary) (string, error) {
+	targetName := cd.Spec.TargetRef.Name
+	sts, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+	return saveFailureReport(c.kubeClient, cd, sts.Spec.Selector)
+}
+
+// GetMetadata returns the pod label selector and svc ports
+func (c *StatefulSetController) GetMetadata(cd *flaggerv1.Canary) (string, string, map[string]int32, error) {
+	targetName := cd.Spec.TargetRef.Name
+
+	canarySts, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", nil, fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	label, labelValue, err := c.getSelectorLabel(canarySts)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	var ports map[string]int32
+	if cd.Spec.Service.PortDiscovery {
+		ports = getPorts(cd, canarySts.Spec.Template.Spec.Containers)
+	}
+	return label, labelValue, ports, nil
+}
+
+func (c *StatefulSetController) createPrimaryStatefulSet(cd *flaggerv1.Canary, includeLabelPrefix []string) error {
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+
+	canarySts, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	if canarySts.Spec.UpdateStrategy.Type != "" &&
+		canarySts.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return fmt.Errorf("statefulset %s.%s must have RollingUpdate strategy but have %s",
+			targetName, cd.Namespace, canarySts.Spec.UpdateStrategy.Type)
+	}
+
+	// Create the labels map but filter unwanted labels
+	labels := includeLabelsByPrefix(canarySts.Labels, includeLabelPrefix)
+
+	label, labelValue, err := c.getSelectorLabel(canarySts)
+	primaryLabelValue := fmt.Sprintf("%s-primary", labelValue)
+	if err != nil {
+		return fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	primarySts, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		// create primary secrets and config maps
+		configRefs, err := c.configTracker.GetTargetConfigs(cd)
+		if err != nil {
+			return fmt.Errorf("GetTargetConfigs failed: %w", err)
+		}
+		if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix); err != nil {
+			return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
+		}
+		annotations, err := makeAnnotations(canarySts.Spec.Template.Annotations)
+		if err != nil {
+			return fmt.Errorf("makeAnnotations failed: %w", err)
+		}
+		primarySpec, err := makePrimaryResources(cd, c.configTracker.ApplyPrimaryConfigs(canarySts.Spec.Template.Spec, configRefs))
+		if err != nil {
+			return err
+		}
+		primarySpec = makePrimaryScheduling(cd, primarySpec, c.labels, canarySts.Name, labelValue)
+
+		replicas := int32(1)
+		if canarySts.Spec.Replicas != nil && *canarySts.Spec.Replicas > 0 {
+			replicas = *canarySts.Spec.Replicas
+		}
+
+		// create primary statefulset, the primary pods get their own volumes from the claim templates
+		primarySts = &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        primaryName,
+				Namespace:   cd.Namespace,
+				Labels:      makePrimaryLabels(labels, primaryLabelValue, label),
+				Annotations: filterMetadata(canarySts.Annotations),
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas:                             int32p(replicas),
+				ServiceName:                          canarySts.Spec.ServiceName,
+				PodManagementPolicy:                  canarySts.Spec.PodManagementPolicy,
+				UpdateStrategy:                       primaryUpdateStrategy(canarySts.Spec.UpdateStrategy),
+				MinReadySeconds:                      canarySts.Spec.MinReadySeconds,
+				RevisionHistoryLimit:                 canarySts.Spec.RevisionHistoryLimit,
+				VolumeClaimTemplates:                 canarySts.Spec.VolumeClaimTemplates,
+				PersistentVolumeClaimRetentionPolicy: canarySts.Spec.PersistentVolumeClaimRetentionPolicy,
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						label: primaryLabelValue,
+					},
+				},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels:      makePrimaryLabels(canarySts.Spec.Template.Labels, primaryLabelValue, label),
+						Annotations: annotations,
+					},
+					// update spec with the primary secrets, config maps and resources
+					Spec: primarySpec,
+				},
+			},
+		}
+
+		makePrimaryPodMetadata(cd, &primarySts.Spec.Template)
+
+		_, err = c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Create(context.TODO(), primarySts, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("creating statefulset %s.%s failed: %w", primarySts.Name, cd.Namespace, err)
+		}
+
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof("StatefulSet %s.%s created", primarySts.GetName(), cd.Namespace)
+	}
+	return nil
+}
+
+// primaryUpdateStrategy removes the rolling update partition,
+// the primary pods are updated all at once on promotion
+func primaryUpdateStrategy(strategy appsv1.StatefulSetUpdateStrategy) appsv1.StatefulSetUpdateStrategy {
+	result := *strategy.DeepCopy()
+	if result.RollingUpdate != nil {
+		result.RollingUpdate.Partition = nil
+	}
+	return result
+}
+
+// getSelectorLabel returns the selector match label
+func (c *StatefulSetController) getSelectorLabel(statefulSet *appsv1.StatefulSet) (string, string, error) {
+	for _, l := range c.labels {
+		if _, ok := statefulSet.Spec.Selector.MatchLabels[l]; ok {
+			return l, statefulSet.Spec.Selector.MatchLabels[l], nil
+		}
+	}
+
+	return "", "", fmt.Errorf(
+		"statefulset %s.%s spec.selector.matchLabels must contain one of %v",
+		statefulSet.Name, statefulSet.Namespace, c.labels,
+	)
+}
+
+func (c *StatefulSetController) HaveDependenciesChanged(cd *flaggerv1.Canary) (bool, error) {
+	return c.configTracker.HasConfigChanged(cd)
+}
+
+// Finalize sets the replica count from the primary to the reference StatefulSet
+func (c *StatefulSetController) Finalize(cd *flaggerv1.Canary) error {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	primary, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if err := c.ScaleFromZero(cd); err != nil {
+				return fmt.Errorf("ScaleFromZero failed: %w", err)
+			}
+			return nil
+		}
+		return fmt.Errorf("statefulset %s.%s get query error: %w", primaryName, cd.Namespace, err)
+	}
+
+	if err := c.ScaleTo(cd, int32Default(primary.Spec.Replicas)); err != nil {
+		return fmt.Errorf("ScaleTo failed: %w", err)
+	}
+	return nil
+}
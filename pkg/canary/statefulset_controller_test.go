@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStatefulSetController_Sync(t *testing.T) {
+	mocks := newStatefulSetFixture(int32p(2))
+	err := mocks.controller.Initialize(mocks.canary)
+	require.Error(t, err) // not ready yet
+
+	primary, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", primary.Spec.Selector.MatchLabels["app"])
+	assert.Equal(t, "podinfo-headless", primary.Spec.ServiceName)
+	assert.Equal(t, int32(3), *primary.Spec.Replicas)
+	assert.Equal(t, "data", primary.Spec.VolumeClaimTemplates[0].Name)
+	assert.Nil(t, primary.Spec.UpdateStrategy.RollingUpdate.Partition)
+	assert.Equal(t, "podinfo-config-all-env-primary",
+		primary.Spec.Template.Spec.Containers[0].EnvFrom[0].ConfigMapRef.Name)
+
+	// the canary keeps its own partition
+	canary, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *canary.Spec.UpdateStrategy.RollingUpdate.Partition)
+}
+
+func TestStatefulSetController_Promote(t *testing.T) {
+	mocks := newStatefulSetFixture(nil)
+	mocks.initializeCanary(t)
+
+	canary, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *canary.Spec.Replicas)
+
+	canary.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:1.2.1"
+	_, err = mocks.kubeClient.AppsV1().StatefulSets("default").Update(context.TODO(), canary, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	isNew, err := mocks.controller.HasTargetChanged(mocks.canary)
+	require.NoError(t, err)
+	assert.True(t, isNew)
+
+	require.NoError(t, mocks.controller.Promote(mocks.canary))
+
+	primary, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io/stefanprodan/podinfo:1.2.1", primary.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, "podinfo-primary", primary.Spec.Template.Labels["app"])
+}
+
+func TestStatefulSetController_Finalize(t *testing.T) {
+	mocks := newStatefulSetFixture(nil)
+	mocks.initializeCanary(t)
+
+	require.NoError(t, mocks.controller.Finalize(mocks.canary))
+
+	canary, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), *canary.Spec.Replicas)
+}
+
+func (s statefulSetControllerFixture) initializeCanary(t *testing.T) {
+	err := s.controller.Initialize(s.canary)
+	require.Error(t, err) // not ready yet
+
+	primary, err := s.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	primary.Status = appsv1.StatefulSetStatus{
+		Replicas:        3,
+		UpdatedReplicas: 3,
+		ReadyReplicas:   3,
+	}
+	_, err = s.kubeClient.AppsV1().StatefulSets("default").Update(context.TODO(), primary, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, s.controller.Initialize(s.canary))
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestStatefulSetController_isStatefulSetReady(t *testing.T) {
+	mocks := newStatefulSetFixture(nil)
+	cd := &flaggerv1.Canary{}
+	cd.Status.LastTransitionTime = metav1.Now()
+
+	// observed generation is less than desired generation
+	sts := &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Replicas: int32p(3)}}
+	sts.Generation = 1
+	retryable, err := mocks.controller.isStatefulSetReady(cd, sts, 100)
+	require.Error(t, err)
+	require.True(t, retryable)
+
+	// rollout in progress
+	sts.Status.ObservedGeneration = 1
+	sts.Status.UpdatedReplicas = 1
+	sts.Status.ReadyReplicas = 1
+	retryable, err = mocks.controller.isStatefulSetReady(cd, sts, 100)
+	require.Error(t, err)
+	require.True(t, retryable)
+
+	// ready
+	sts.Status.UpdatedReplicas = 3
+	sts.Status.ReadyReplicas = 3
+	_, err = mocks.controller.isStatefulSetReady(cd, sts, 100)
+	require.NoError(t, err)
+
+	// with a partition only the pods from the partition ordinal are updated
+	sts.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: int32p(2)}
+	sts.Status.UpdatedReplicas = 0
+	_, err = mocks.controller.isStatefulSetReady(cd, sts, 100)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "partitioned rollout")
+
+	sts.Status.UpdatedReplicas = 1
+	_, err = mocks.controller.isStatefulSetReady(cd, sts, 100)
+	require.NoError(t, err)
+
+	// ready threshold
+	sts.Status.ReadyReplicas = 2
+	_, err = mocks.controller.isStatefulSetReady(cd, sts, 100)
+	require.Error(t, err)
+	_, err = mocks.controller.isStatefulSetReady(cd, sts, 50)
+	require.NoError(t, err)
+
+	// progress deadline exceeded
+	cd.Status.LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	retryable, err = mocks.controller.isStatefulSetReady(cd, sts, 100)
+	require.Error(t, err)
+	require.False(t, retryable)
+}
@@ -48,6 +48,9 @@ func syncCanaryStatus(flaggerClient clientset.Interface, cd *flaggerv1.Canary, s
 		cdCopy.Status.FailedChecks = status.FailedChecks
 		cdCopy.Status.Iterations = status.Iterations
 		cdCopy.Status.LastAppliedSpec = hash
+		if status.AnalysisRunID != "" {
+			cdCopy.Status.AnalysisRunID = status.AnalysisRunID
+		}
 		cdCopy.Status.LastTransitionTime = metav1.Now()
 		setAll(cdCopy)
 
@@ -103,6 +106,7 @@ func setStatusWeight(flaggerClient clientset.Interface, cd *flaggerv1.Canary, va
 		cdCopy := cd.DeepCopy()
 		cdCopy.Status.CanaryWeight = val
 		cdCopy.Status.LastTransitionTime = metav1.Now()
+		cdCopy.Status.LastWeightChangeTime = cdCopy.Status.LastTransitionTime
 
 		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
 		firstTry = false
@@ -158,10 +162,12 @@ func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, pha
 		if phase != flaggerv1.CanaryPhaseProgressing && phase != flaggerv1.CanaryPhaseWaiting {
 			cdCopy.Status.CanaryWeight = 0
 			cdCopy.Status.Iterations = 0
+			cdCopy.Status.Gates = nil
 		}
 
 		// on promotion set primary spec hash
-		if phase == flaggerv1.CanaryPhaseInitialized || phase == flaggerv1.CanaryPhaseSucceeded {
+		if phase == flaggerv1.CanaryPhaseInitialized || phase == flaggerv1.CanaryPhaseSucceeded ||
+			phase == flaggerv1.CanaryPhasePromotedWithoutAnalysis {
 			cdCopy.Status.LastPromotedSpec = cd.Status.LastAppliedSpec
 		}
 
@@ -222,6 +228,9 @@ func MakeStatusConditions(cd *flaggerv1.Canary,
 	case flaggerv1.CanaryPhaseSucceeded:
 		status = corev1.ConditionTrue
 		message = "Canary analysis completed successfully, promotion finished."
+	case flaggerv1.CanaryPhasePromotedWithoutAnalysis:
+		status = corev1.ConditionTrue
+		message = "Canary analysis was skipped, promotion finished."
 	case flaggerv1.CanaryPhaseFailed:
 		status = corev1.ConditionFalse
 		message = fmt.Sprintf("Canary analysis failed, %s scaled to zero.", cd.Spec.TargetRef.Kind)
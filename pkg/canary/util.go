@@ -20,9 +20,11 @@ import (
 	"crypto/rand"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -140,6 +142,49 @@ func makePrimaryLabels(labels map[string]string, labelValue string, label string
 	return res
 }
 
+// makePrimaryResources applies the spec.primary resources multiplier
+// and container overrides to the primary pod spec
+func makePrimaryResources(cd *flaggerv1.Canary, spec corev1.PodSpec) (corev1.PodSpec, error) {
+	if cd.Spec.Primary == nil {
+		return spec, nil
+	}
+
+	multiplier := 1.0
+	if m := cd.Spec.Primary.ResourcesMultiplier; m != "" {
+		v, err := strconv.ParseFloat(m, 64)
+		if err != nil || v <= 0 {
+			return spec, fmt.Errorf("invalid primary resources multiplier %s", m)
+		}
+		multiplier = v
+	}
+
+	spec = *spec.DeepCopy()
+	for i, container := range spec.Containers {
+		if multiplier != 1 {
+			spec.Containers[i].Resources.Requests = multiplyResources(container.Resources.Requests, multiplier)
+			spec.Containers[i].Resources.Limits = multiplyResources(container.Resources.Limits, multiplier)
+		}
+		for _, override := range cd.Spec.Primary.Containers {
+			if override.Name == container.Name {
+				spec.Containers[i].Resources = *override.Resources.DeepCopy()
+			}
+		}
+	}
+
+	return spec, nil
+}
+
+func multiplyResources(list corev1.ResourceList, multiplier float64) corev1.ResourceList {
+	if list == nil {
+		return nil
+	}
+	res := make(corev1.ResourceList, len(list))
+	for name, q := range list {
+		res[name] = *resource.NewMilliQuantity(int64(float64(q.MilliValue())*multiplier), q.Format)
+	}
+	return res
+}
+
 func int32p(i int32) *int32 {
 	return &i
 }
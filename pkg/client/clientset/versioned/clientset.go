@@ -22,12 +22,20 @@ import (
 	"fmt"
 	"net/http"
 
+	apisixv2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/apisix/v2"
 	appmeshv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta1"
 	appmeshv1beta2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta2"
+	consulv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/consul/v1alpha1"
+	externaldnsv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/externaldns/v1alpha1"
 	flaggerv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1"
 	gatewayv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gateway/v1"
+	gatewayapiv1alpha2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gatewayapi/v1alpha2"
 	gloov1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gloo/v1"
 	networkingv1alpha3 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3"
+	kedav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1"
+	kumav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/kuma/v1alpha1"
+	monitoringv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/monitoring/v1"
+	nginxgatewayv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/nginxgateway/v1alpha1"
 	projectcontourv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1"
 	splitv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha1"
 	splitv1alpha2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha2"
@@ -40,12 +48,20 @@ import (
 
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
+	ApisixV2() apisixv2.ApisixV2Interface
 	AppmeshV1beta2() appmeshv1beta2.AppmeshV1beta2Interface
 	AppmeshV1beta1() appmeshv1beta1.AppmeshV1beta1Interface
+	ConsulV1alpha1() consulv1alpha1.ConsulV1alpha1Interface
+	ExternaldnsV1alpha1() externaldnsv1alpha1.ExternaldnsV1alpha1Interface
 	FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface
 	GatewayV1() gatewayv1.GatewayV1Interface
+	GatewayapiV1alpha2() gatewayapiv1alpha2.GatewayapiV1alpha2Interface
 	GlooV1() gloov1.GlooV1Interface
 	NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3Interface
+	KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface
+	KumaV1alpha1() kumav1alpha1.KumaV1alpha1Interface
+	MonitoringV1() monitoringv1.MonitoringV1Interface
+	NginxgatewayV1alpha1() nginxgatewayv1alpha1.NginxgatewayV1alpha1Interface
 	ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface
 	SplitV1alpha1() splitv1alpha1.SplitV1alpha1Interface
 	SplitV1alpha2() splitv1alpha2.SplitV1alpha2Interface
@@ -57,17 +73,30 @@ type Interface interface {
 // version included in a Clientset.
 type Clientset struct {
 	*discovery.DiscoveryClient
-	appmeshV1beta2     *appmeshv1beta2.AppmeshV1beta2Client
-	appmeshV1beta1     *appmeshv1beta1.AppmeshV1beta1Client
-	flaggerV1beta1     *flaggerv1beta1.FlaggerV1beta1Client
-	gatewayV1          *gatewayv1.GatewayV1Client
-	glooV1             *gloov1.GlooV1Client
-	networkingV1alpha3 *networkingv1alpha3.NetworkingV1alpha3Client
-	projectcontourV1   *projectcontourv1.ProjectcontourV1Client
-	splitV1alpha1      *splitv1alpha1.SplitV1alpha1Client
-	splitV1alpha2      *splitv1alpha2.SplitV1alpha2Client
-	splitV1alpha3      *splitv1alpha3.SplitV1alpha3Client
-	traefikV1alpha1    *traefikv1alpha1.TraefikV1alpha1Client
+	apisixV2             *apisixv2.ApisixV2Client
+	appmeshV1beta2       *appmeshv1beta2.AppmeshV1beta2Client
+	appmeshV1beta1       *appmeshv1beta1.AppmeshV1beta1Client
+	consulV1alpha1       *consulv1alpha1.ConsulV1alpha1Client
+	externaldnsV1alpha1  *externaldnsv1alpha1.ExternaldnsV1alpha1Client
+	flaggerV1beta1       *flaggerv1beta1.FlaggerV1beta1Client
+	gatewayV1            *gatewayv1.GatewayV1Client
+	gatewayapiV1alpha2   *gatewayapiv1alpha2.GatewayapiV1alpha2Client
+	glooV1               *gloov1.GlooV1Client
+	networkingV1alpha3   *networkingv1alpha3.NetworkingV1alpha3Client
+	kedaV1alpha1         *kedav1alpha1.KedaV1alpha1Client
+	kumaV1alpha1         *kumav1alpha1.KumaV1alpha1Client
+	monitoringV1         *monitoringv1.MonitoringV1Client
+	nginxgatewayV1alpha1 *nginxgatewayv1alpha1.NginxgatewayV1alpha1Client
+	projectcontourV1     *projectcontourv1.ProjectcontourV1Client
+	splitV1alpha1        *splitv1alpha1.SplitV1alpha1Client
+	splitV1alpha2        *splitv1alpha2.SplitV1alpha2Client
+	splitV1alpha3        *splitv1alpha3.SplitV1alpha3Client
+	traefikV1alpha1      *traefikv1alpha1.TraefikV1alpha1Client
+}
+
+// ApisixV2 retrieves the ApisixV2Client
+func (c *Clientset) ApisixV2() apisixv2.ApisixV2Interface {
+	return c.apisixV2
 }
 
 // AppmeshV1beta2 retrieves the AppmeshV1beta2Client
@@ -80,6 +109,16 @@ func (c *Clientset) AppmeshV1beta1() appmeshv1beta1.AppmeshV1beta1Interface {
 	return c.appmeshV1beta1
 }
 
+// ConsulV1alpha1 retrieves the ConsulV1alpha1Client
+func (c *Clientset) ConsulV1alpha1() consulv1alpha1.ConsulV1alpha1Interface {
+	return c.consulV1alpha1
+}
+
+// ExternaldnsV1alpha1 retrieves the ExternaldnsV1alpha1Client
+func (c *Clientset) ExternaldnsV1alpha1() externaldnsv1alpha1.ExternaldnsV1alpha1Interface {
+	return c.externaldnsV1alpha1
+}
+
 // FlaggerV1beta1 retrieves the FlaggerV1beta1Client
 func (c *Clientset) FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface {
 	return c.flaggerV1beta1
@@ -90,6 +129,11 @@ func (c *Clientset) GatewayV1() gatewayv1.GatewayV1Interface {
 	return c.gatewayV1
 }
 
+// GatewayapiV1alpha2 retrieves the GatewayapiV1alpha2Client
+func (c *Clientset) GatewayapiV1alpha2() gatewayapiv1alpha2.GatewayapiV1alpha2Interface {
+	return c.gatewayapiV1alpha2
+}
+
 // GlooV1 retrieves the GlooV1Client
 func (c *Clientset) GlooV1() gloov1.GlooV1Interface {
 	return c.glooV1
@@ -100,6 +144,26 @@ func (c *Clientset) NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3In
 	return c.networkingV1alpha3
 }
 
+// KedaV1alpha1 retrieves the KedaV1alpha1Client
+func (c *Clientset) KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface {
+	return c.kedaV1alpha1
+}
+
+// KumaV1alpha1 retrieves the KumaV1alpha1Client
+func (c *Clientset) KumaV1alpha1() kumav1alpha1.KumaV1alpha1Interface {
+	return c.kumaV1alpha1
+}
+
+// MonitoringV1 retrieves the MonitoringV1Client
+func (c *Clientset) MonitoringV1() monitoringv1.MonitoringV1Interface {
+	return c.monitoringV1
+}
+
+// NginxgatewayV1alpha1 retrieves the NginxgatewayV1alpha1Client
+func (c *Clientset) NginxgatewayV1alpha1() nginxgatewayv1alpha1.NginxgatewayV1alpha1Interface {
+	return c.nginxgatewayV1alpha1
+}
+
 // ProjectcontourV1 retrieves the ProjectcontourV1Client
 func (c *Clientset) ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface {
 	return c.projectcontourV1
@@ -165,6 +229,10 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 
 	var cs Clientset
 	var err error
+	cs.apisixV2, err = apisixv2.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 	cs.appmeshV1beta2, err = appmeshv1beta2.NewForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
 		return nil, err
@@ -173,6 +241,14 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
+	cs.consulV1alpha1, err = consulv1alpha1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	cs.externaldnsV1alpha1, err = externaldnsv1alpha1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 	cs.flaggerV1beta1, err = flaggerv1beta1.NewForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
 		return nil, err
@@ -181,6 +257,10 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
+	cs.gatewayapiV1alpha2, err = gatewayapiv1alpha2.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 	cs.glooV1, err = gloov1.NewForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
 		return nil, err
@@ -189,6 +269,22 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
+	cs.kedaV1alpha1, err = kedav1alpha1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	cs.kumaV1alpha1, err = kumav1alpha1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	cs.monitoringV1, err = monitoringv1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	cs.nginxgatewayV1alpha1, err = nginxgatewayv1alpha1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 	cs.projectcontourV1, err = projectcontourv1.NewForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
 		return nil, err
@@ -230,12 +326,20 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 // New creates a new Clientset for the given RESTClient.
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
+	cs.apisixV2 = apisixv2.New(c)
 	cs.appmeshV1beta2 = appmeshv1beta2.New(c)
 	cs.appmeshV1beta1 = appmeshv1beta1.New(c)
+	cs.consulV1alpha1 = consulv1alpha1.New(c)
+	cs.externaldnsV1alpha1 = externaldnsv1alpha1.New(c)
 	cs.flaggerV1beta1 = flaggerv1beta1.New(c)
 	cs.gatewayV1 = gatewayv1.New(c)
+	cs.gatewayapiV1alpha2 = gatewayapiv1alpha2.New(c)
 	cs.glooV1 = gloov1.New(c)
 	cs.networkingV1alpha3 = networkingv1alpha3.New(c)
+	cs.kedaV1alpha1 = kedav1alpha1.New(c)
+	cs.kumaV1alpha1 = kumav1alpha1.New(c)
+	cs.monitoringV1 = monitoringv1.New(c)
+	cs.nginxgatewayV1alpha1 = nginxgatewayv1alpha1.New(c)
 	cs.projectcontourV1 = projectcontourv1.New(c)
 	cs.splitV1alpha1 = splitv1alpha1.New(c)
 	cs.splitV1alpha2 = splitv1alpha2.New(c)
@@ -20,18 +20,34 @@ package fake
 
 import (
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+	apisixv2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/apisix/v2"
+	fakeapisixv2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/apisix/v2/fake"
 	appmeshv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta1"
 	fakeappmeshv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta1/fake"
 	appmeshv1beta2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta2"
 	fakeappmeshv1beta2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta2/fake"
+	consulv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/consul/v1alpha1"
+	fakeconsulv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/consul/v1alpha1/fake"
+	externaldnsv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/externaldns/v1alpha1"
+	fakeexternaldnsv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/externaldns/v1alpha1/fake"
 	flaggerv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1"
 	fakeflaggerv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1/fake"
 	gatewayv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gateway/v1"
 	fakegatewayv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gateway/v1/fake"
+	gatewayapiv1alpha2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gatewayapi/v1alpha2"
+	fakegatewayapiv1alpha2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gatewayapi/v1alpha2/fake"
 	gloov1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gloo/v1"
 	fakegloov1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gloo/v1/fake"
 	networkingv1alpha3 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3"
 	fakenetworkingv1alpha3 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3/fake"
+	kedav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1"
+	fakekedav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1/fake"
+	kumav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/kuma/v1alpha1"
+	fakekumav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/kuma/v1alpha1/fake"
+	monitoringv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/monitoring/v1"
+	fakemonitoringv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/monitoring/v1/fake"
+	nginxgatewayv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/nginxgateway/v1alpha1"
+	fakenginxgatewayv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/nginxgateway/v1alpha1/fake"
 	projectcontourv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1"
 	fakeprojectcontourv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1/fake"
 	splitv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha1"
@@ -99,6 +115,11 @@ var (
 	_ testing.FakeClient  = &Clientset{}
 )
 
+// ApisixV2 retrieves the ApisixV2Client
+func (c *Clientset) ApisixV2() apisixv2.ApisixV2Interface {
+	return &fakeapisixv2.FakeApisixV2{Fake: &c.Fake}
+}
+
 // AppmeshV1beta2 retrieves the AppmeshV1beta2Client
 func (c *Clientset) AppmeshV1beta2() appmeshv1beta2.AppmeshV1beta2Interface {
 	return &fakeappmeshv1beta2.FakeAppmeshV1beta2{Fake: &c.Fake}
@@ -109,6 +130,16 @@ func (c *Clientset) AppmeshV1beta1() appmeshv1beta1.AppmeshV1beta1Interface {
 	return &fakeappmeshv1beta1.FakeAppmeshV1beta1{Fake: &c.Fake}
 }
 
+// ConsulV1alpha1 retrieves the ConsulV1alpha1Client
+func (c *Clientset) ConsulV1alpha1() consulv1alpha1.ConsulV1alpha1Interface {
+	return &fakeconsulv1alpha1.FakeConsulV1alpha1{Fake: &c.Fake}
+}
+
+// ExternaldnsV1alpha1 retrieves the ExternaldnsV1alpha1Client
+func (c *Clientset) ExternaldnsV1alpha1() externaldnsv1alpha1.ExternaldnsV1alpha1Interface {
+	return &fakeexternaldnsv1alpha1.FakeExternaldnsV1alpha1{Fake: &c.Fake}
+}
+
 // FlaggerV1beta1 retrieves the FlaggerV1beta1Client
 func (c *Clientset) FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface {
 	return &fakeflaggerv1beta1.FakeFlaggerV1beta1{Fake: &c.Fake}
@@ -119,6 +150,11 @@ func (c *Clientset) GatewayV1() gatewayv1.GatewayV1Interface {
 	return &fakegatewayv1.FakeGatewayV1{Fake: &c.Fake}
 }
 
+// GatewayapiV1alpha2 retrieves the GatewayapiV1alpha2Client
+func (c *Clientset) GatewayapiV1alpha2() gatewayapiv1alpha2.GatewayapiV1alpha2Interface {
+	return &fakegatewayapiv1alpha2.FakeGatewayapiV1alpha2{Fake: &c.Fake}
+}
+
 // GlooV1 retrieves the GlooV1Client
 func (c *Clientset) GlooV1() gloov1.GlooV1Interface {
 	return &fakegloov1.FakeGlooV1{Fake: &c.Fake}
@@ -129,6 +165,26 @@ func (c *Clientset) NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3In
 	return &fakenetworkingv1alpha3.FakeNetworkingV1alpha3{Fake: &c.Fake}
 }
 
+// KedaV1alpha1 retrieves the KedaV1alpha1Client
+func (c *Clientset) KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface {
+	return &fakekedav1alpha1.FakeKedaV1alpha1{Fake: &c.Fake}
+}
+
+// KumaV1alpha1 retrieves the KumaV1alpha1Client
+func (c *Clientset) KumaV1alpha1() kumav1alpha1.KumaV1alpha1Interface {
+	return &fakekumav1alpha1.FakeKumaV1alpha1{Fake: &c.Fake}
+}
+
+// MonitoringV1 retrieves the MonitoringV1Client
+func (c *Clientset) MonitoringV1() monitoringv1.MonitoringV1Interface {
+	return &fakemonitoringv1.FakeMonitoringV1{Fake: &c.Fake}
+}
+
+// NginxgatewayV1alpha1 retrieves the NginxgatewayV1alpha1Client
+func (c *Clientset) NginxgatewayV1alpha1() nginxgatewayv1alpha1.NginxgatewayV1alpha1Interface {
+	return &fakenginxgatewayv1alpha1.FakeNginxgatewayV1alpha1{Fake: &c.Fake}
+}
+
 // ProjectcontourV1 retrieves the ProjectcontourV1Client
 func (c *Clientset) ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface {
 	return &fakeprojectcontourv1.FakeProjectcontourV1{Fake: &c.Fake}
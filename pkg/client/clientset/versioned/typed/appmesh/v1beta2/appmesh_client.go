@@ -28,6 +28,7 @@ import (
 
 type AppmeshV1beta2Interface interface {
 	RESTClient() rest.Interface
+	GatewayRoutesGetter
 	VirtualNodesGetter
 	VirtualRoutersGetter
 	VirtualServicesGetter
@@ -38,6 +39,10 @@ type AppmeshV1beta2Client struct {
 	restClient rest.Interface
 }
 
+func (c *AppmeshV1beta2Client) GatewayRoutes(namespace string) GatewayRouteInterface {
+	return newGatewayRoutes(c, namespace)
+}
+
 func (c *AppmeshV1beta2Client) VirtualNodes(namespace string) VirtualNodeInterface {
 	return newVirtualNodes(c, namespace)
 }
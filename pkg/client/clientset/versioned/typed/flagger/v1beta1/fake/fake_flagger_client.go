@@ -40,6 +40,10 @@ func (c *FakeFlaggerV1beta1) MetricTemplates(namespace string) v1beta1.MetricTem
 	return &FakeMetricTemplates{c, namespace}
 }
 
+func (c *FakeFlaggerV1beta1) RingRollouts(namespace string) v1beta1.RingRolloutInterface {
+	return &FakeRingRollouts{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeFlaggerV1beta1) RESTClient() rest.Interface {
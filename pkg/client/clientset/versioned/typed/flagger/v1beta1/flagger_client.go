@@ -31,6 +31,7 @@ type FlaggerV1beta1Interface interface {
 	AlertProvidersGetter
 	CanariesGetter
 	MetricTemplatesGetter
+	RingRolloutsGetter
 }
 
 // FlaggerV1beta1Client is used to interact with features provided by the flagger.app group.
@@ -50,6 +51,10 @@ func (c *FlaggerV1beta1Client) MetricTemplates(namespace string) MetricTemplateI
 	return newMetricTemplates(c, namespace)
 }
 
+func (c *FlaggerV1beta1Client) RingRollouts(namespace string) RingRolloutInterface {
+	return newRingRollouts(c, namespace)
+}
+
 // NewForConfig creates a new FlaggerV1beta1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
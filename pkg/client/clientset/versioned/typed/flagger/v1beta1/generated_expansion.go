@@ -23,3 +23,5 @@ type AlertProviderExpansion interface{}
 type CanaryExpansion interface{}
 
 type MetricTemplateExpansion interface{}
+
+type RingRolloutExpansion interface{}
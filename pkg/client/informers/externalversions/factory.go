@@ -24,12 +24,20 @@ import (
 	time "time"
 
 	versioned "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+	apisix "github.com/fluxcd/flagger/pkg/client/informers/externalversions/apisix"
 	appmesh "github.com/fluxcd/flagger/pkg/client/informers/externalversions/appmesh"
+	consul "github.com/fluxcd/flagger/pkg/client/informers/externalversions/consul"
+	externaldns "github.com/fluxcd/flagger/pkg/client/informers/externalversions/externaldns"
 	flagger "github.com/fluxcd/flagger/pkg/client/informers/externalversions/flagger"
 	gateway "github.com/fluxcd/flagger/pkg/client/informers/externalversions/gateway"
+	gatewayapi "github.com/fluxcd/flagger/pkg/client/informers/externalversions/gatewayapi"
 	gloo "github.com/fluxcd/flagger/pkg/client/informers/externalversions/gloo"
 	internalinterfaces "github.com/fluxcd/flagger/pkg/client/informers/externalversions/internalinterfaces"
 	istio "github.com/fluxcd/flagger/pkg/client/informers/externalversions/istio"
+	keda "github.com/fluxcd/flagger/pkg/client/informers/externalversions/keda"
+	kuma "github.com/fluxcd/flagger/pkg/client/informers/externalversions/kuma"
+	monitoring "github.com/fluxcd/flagger/pkg/client/informers/externalversions/monitoring"
+	nginxgateway "github.com/fluxcd/flagger/pkg/client/informers/externalversions/nginxgateway"
 	projectcontour "github.com/fluxcd/flagger/pkg/client/informers/externalversions/projectcontour"
 	smi "github.com/fluxcd/flagger/pkg/client/informers/externalversions/smi"
 	traefik "github.com/fluxcd/flagger/pkg/client/informers/externalversions/traefik"
@@ -179,20 +187,40 @@ type SharedInformerFactory interface {
 	ForResource(resource schema.GroupVersionResource) (GenericInformer, error)
 	WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
 
+	Apisix() apisix.Interface
 	Appmesh() appmesh.Interface
+	Consul() consul.Interface
+	Externaldns() externaldns.Interface
 	Flagger() flagger.Interface
 	Gateway() gateway.Interface
+	Gatewayapi() gatewayapi.Interface
 	Gloo() gloo.Interface
 	Networking() istio.Interface
+	Keda() keda.Interface
+	Kuma() kuma.Interface
+	Monitoring() monitoring.Interface
+	Nginxgateway() nginxgateway.Interface
 	Projectcontour() projectcontour.Interface
 	Split() smi.Interface
 	Traefik() traefik.Interface
 }
 
+func (f *sharedInformerFactory) Apisix() apisix.Interface {
+	return apisix.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Appmesh() appmesh.Interface {
 	return appmesh.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Consul() consul.Interface {
+	return consul.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Externaldns() externaldns.Interface {
+	return externaldns.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Flagger() flagger.Interface {
 	return flagger.New(f, f.namespace, f.tweakListOptions)
 }
@@ -201,6 +229,10 @@ func (f *sharedInformerFactory) Gateway() gateway.Interface {
 	return gateway.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Gatewayapi() gatewayapi.Interface {
+	return gatewayapi.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Gloo() gloo.Interface {
 	return gloo.New(f, f.namespace, f.tweakListOptions)
 }
@@ -209,6 +241,22 @@ func (f *sharedInformerFactory) Networking() istio.Interface {
 	return istio.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Keda() keda.Interface {
+	return keda.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Kuma() kuma.Interface {
+	return kuma.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Monitoring() monitoring.Interface {
+	return monitoring.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Nginxgateway() nginxgateway.Interface {
+	return nginxgateway.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Projectcontour() projectcontour.Interface {
 	return projectcontour.New(f, f.namespace, f.tweakListOptions)
 }
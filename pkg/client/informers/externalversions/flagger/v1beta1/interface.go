@@ -30,6 +30,8 @@ type Interface interface {
 	Canaries() CanaryInformer
 	// MetricTemplates returns a MetricTemplateInformer.
 	MetricTemplates() MetricTemplateInformer
+	// RingRollouts returns a RingRolloutInformer.
+	RingRollouts() RingRolloutInformer
 }
 
 type version struct {
@@ -57,3 +59,8 @@ func (v *version) Canaries() CanaryInformer {
 func (v *version) MetricTemplates() MetricTemplateInformer {
 	return &metricTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// RingRollouts returns a RingRolloutInformer.
+func (v *version) RingRollouts() RingRolloutInformer {
+	return &ringRolloutInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
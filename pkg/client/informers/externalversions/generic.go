@@ -21,15 +21,23 @@ package externalversions
 import (
 	"fmt"
 
+	v2 "github.com/fluxcd/flagger/pkg/apis/apisix/v2"
 	v1beta1 "github.com/fluxcd/flagger/pkg/apis/appmesh/v1beta1"
 	v1beta2 "github.com/fluxcd/flagger/pkg/apis/appmesh/v1beta2"
+	v1alpha1 "github.com/fluxcd/flagger/pkg/apis/consul/v1alpha1"
+	externaldnsv1alpha1 "github.com/fluxcd/flagger/pkg/apis/externaldns/v1alpha1"
 	flaggerv1beta1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	v1alpha2 "github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1alpha2"
 	v1 "github.com/fluxcd/flagger/pkg/apis/gloo/gateway/v1"
 	gloov1 "github.com/fluxcd/flagger/pkg/apis/gloo/gloo/v1"
 	v1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+	kedav1alpha1 "github.com/fluxcd/flagger/pkg/apis/keda/v1alpha1"
+	kumav1alpha1 "github.com/fluxcd/flagger/pkg/apis/kuma/v1alpha1"
+	monitoringv1 "github.com/fluxcd/flagger/pkg/apis/monitoring/v1"
+	nginxgatewayv1alpha1 "github.com/fluxcd/flagger/pkg/apis/nginxgateway/v1alpha1"
 	projectcontourv1 "github.com/fluxcd/flagger/pkg/apis/projectcontour/v1"
-	v1alpha1 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha1"
-	v1alpha2 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha2"
+	smiv1alpha1 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha1"
+	smiv1alpha2 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha2"
 	smiv1alpha3 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha3"
 	traefikv1alpha1 "github.com/fluxcd/flagger/pkg/apis/traefik/v1alpha1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -62,7 +70,11 @@ func (f *genericInformer) Lister() cache.GenericLister {
 // TODO extend this to unknown resources with a client pool
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
-	// Group=appmesh.k8s.aws, Version=v1beta1
+	// Group=apisix.apache.org, Version=v2
+	case v2.SchemeGroupVersion.WithResource("apisixroutes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Apisix().V2().ApisixRoutes().Informer()}, nil
+
+		// Group=appmesh.k8s.aws, Version=v1beta1
 	case v1beta1.SchemeGroupVersion.WithResource("meshes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Appmesh().V1beta1().Meshes().Informer()}, nil
 	case v1beta1.SchemeGroupVersion.WithResource("virtualnodes"):
@@ -71,6 +83,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Appmesh().V1beta1().VirtualServices().Informer()}, nil
 
 		// Group=appmesh.k8s.aws, Version=v1beta2
+	case v1beta2.SchemeGroupVersion.WithResource("gatewayroutes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Appmesh().V1beta2().GatewayRoutes().Informer()}, nil
 	case v1beta2.SchemeGroupVersion.WithResource("virtualnodes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Appmesh().V1beta2().VirtualNodes().Informer()}, nil
 	case v1beta2.SchemeGroupVersion.WithResource("virtualrouters"):
@@ -78,6 +92,16 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1beta2.SchemeGroupVersion.WithResource("virtualservices"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Appmesh().V1beta2().VirtualServices().Informer()}, nil
 
+		// Group=consul.hashicorp.com, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("serviceresolvers"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Consul().V1alpha1().ServiceResolvers().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("servicesplitters"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Consul().V1alpha1().ServiceSplitters().Informer()}, nil
+
+		// Group=externaldns.k8s.io, Version=v1alpha1
+	case externaldnsv1alpha1.SchemeGroupVersion.WithResource("dnsendpoints"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Externaldns().V1alpha1().DNSEndpoints().Informer()}, nil
+
 		// Group=flagger.app, Version=v1beta1
 	case flaggerv1beta1.SchemeGroupVersion.WithResource("alertproviders"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().AlertProviders().Informer()}, nil
@@ -85,6 +109,18 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().Canaries().Informer()}, nil
 	case flaggerv1beta1.SchemeGroupVersion.WithResource("metrictemplates"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().MetricTemplates().Informer()}, nil
+	case flaggerv1beta1.SchemeGroupVersion.WithResource("ringrollouts"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().RingRollouts().Informer()}, nil
+
+		// Group=gateway.networking.k8s.io, Version=v1alpha2
+	case v1alpha2.SchemeGroupVersion.WithResource("httproutes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Gatewayapi().V1alpha2().HTTPRoutes().Informer()}, nil
+
+		// Group=gateway.nginx.org, Version=v1alpha1
+	case nginxgatewayv1alpha1.SchemeGroupVersion.WithResource("clientsettingspolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Nginxgateway().V1alpha1().ClientSettingsPolicies().Informer()}, nil
+	case nginxgatewayv1alpha1.SchemeGroupVersion.WithResource("observabilitypolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Nginxgateway().V1alpha1().ObservabilityPolicies().Informer()}, nil
 
 		// Group=gateway.solo.io, Version=v1
 	case v1.SchemeGroupVersion.WithResource("routetables"):
@@ -94,6 +130,20 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case gloov1.SchemeGroupVersion.WithResource("upstreams"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Gloo().V1().Upstreams().Informer()}, nil
 
+		// Group=keda.sh, Version=v1alpha1
+	case kedav1alpha1.SchemeGroupVersion.WithResource("scaledobjects"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Keda().V1alpha1().ScaledObjects().Informer()}, nil
+
+		// Group=kuma.io, Version=v1alpha1
+	case kumav1alpha1.SchemeGroupVersion.WithResource("trafficroutes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kuma().V1alpha1().TrafficRoutes().Informer()}, nil
+
+		// Group=monitoring.coreos.com, Version=v1
+	case monitoringv1.SchemeGroupVersion.WithResource("podmonitors"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Monitoring().V1().PodMonitors().Informer()}, nil
+	case monitoringv1.SchemeGroupVersion.WithResource("servicemonitors"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Monitoring().V1().ServiceMonitors().Informer()}, nil
+
 		// Group=networking.istio.io, Version=v1alpha3
 	case v1alpha3.SchemeGroupVersion.WithResource("destinationrules"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha3().DestinationRules().Informer()}, nil
@@ -105,11 +155,11 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Projectcontour().V1().HTTPProxies().Informer()}, nil
 
 		// Group=split.smi-spec.io, Version=v1alpha1
-	case v1alpha1.SchemeGroupVersion.WithResource("trafficsplits"):
+	case smiv1alpha1.SchemeGroupVersion.WithResource("trafficsplits"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Split().V1alpha1().TrafficSplits().Informer()}, nil
 
 		// Group=split.smi-spec.io, Version=v1alpha2
-	case v1alpha2.SchemeGroupVersion.WithResource("trafficsplits"):
+	case smiv1alpha2.SchemeGroupVersion.WithResource("trafficsplits"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Split().V1alpha2().TrafficSplits().Informer()}, nil
 
 		// Group=split.smi-spec.io, Version=v1alpha3
@@ -41,3 +41,11 @@ type MetricTemplateListerExpansion interface{}
 // MetricTemplateNamespaceListerExpansion allows custom methods to be added to
 // MetricTemplateNamespaceLister.
 type MetricTemplateNamespaceListerExpansion interface{}
+
+// RingRolloutListerExpansion allows custom methods to be added to
+// RingRolloutLister.
+type RingRolloutListerExpansion interface{}
+
+// RingRolloutNamespaceListerExpansion allows custom methods to be added to
+// RingRolloutNamespaceLister.
+type RingRolloutNamespaceListerExpansion interface{}
@@ -42,6 +42,7 @@ import (
 	"github.com/fluxcd/flagger/pkg/metrics"
 	"github.com/fluxcd/flagger/pkg/metrics/observers"
 	"github.com/fluxcd/flagger/pkg/notifier"
+	"github.com/fluxcd/flagger/pkg/quota"
 	"github.com/fluxcd/flagger/pkg/router"
 )
 
@@ -58,6 +59,7 @@ type Controller struct {
 	eventRecorder    record.EventRecorder
 	logger           *zap.SugaredLogger
 	canaries         *sync.Map
+	metricResults    *sync.Map
 	jobs             map[string]CanaryJob
 	recorder         metrics.Recorder
 	notifier         notifier.Interface
@@ -66,6 +68,10 @@ type Controller struct {
 	observerFactory  *observers.Factory
 	meshProvider     string
 	eventWebhook     string
+	quota            *quota.Checker
+	insights         *Insights
+
+	noCrossNamespaceRefs bool
 }
 
 type Informers struct {
@@ -87,6 +93,9 @@ func NewController(
 	meshProvider string,
 	version string,
 	eventWebhook string,
+	noCrossNamespaceRefs bool,
+	quotaChecker *quota.Checker,
+	insights *Insights,
 ) *Controller {
 	logger.Debug("Creating event broadcaster")
 	flaggerscheme.AddToScheme(scheme.Scheme)
@@ -109,6 +118,7 @@ func NewController(
 		eventRecorder:    eventRecorder,
 		logger:           logger,
 		canaries:         new(sync.Map),
+		metricResults:    new(sync.Map),
 		jobs:             map[string]CanaryJob{},
 		flaggerWindow:    flaggerWindow,
 		observerFactory:  observerFactory,
@@ -118,6 +128,10 @@ func NewController(
 		routerFactory:    routerFactory,
 		meshProvider:     meshProvider,
 		eventWebhook:     eventWebhook,
+		quota:            quotaChecker,
+		insights:         insights,
+
+		noCrossNamespaceRefs: noCrossNamespaceRefs,
 	}
 
 	flaggerInformers.CanaryInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -165,6 +179,7 @@ func NewController(
 			if ok {
 				ctrl.logger.Infof("Deleting %s.%s from cache", r.Name, r.Namespace)
 				ctrl.canaries.Delete(fmt.Sprintf("%s.%s", r.Name, r.Namespace))
+				ctrl.metricResults.Delete(fmt.Sprintf("%s.%s", r.Name, r.Namespace))
 			}
 		},
 	})
@@ -189,10 +204,19 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	c.logger.Info("Started operator workers")
 
 	tickChan := time.NewTicker(c.flaggerWindow).C
+
+	// post the rollouts summary if enabled
+	var reportChan <-chan time.Time
+	if c.insights != nil && c.insights.reportInterval > 0 {
+		reportChan = time.NewTicker(c.insights.reportInterval).C
+	}
+
 	for {
 		select {
 		case <-tickChan:
 			c.scheduleCanaries()
+		case <-reportChan:
+			c.postInsightsReport()
 		case <-stopCh:
 			c.logger.Info("Shutting down operator workers")
 			return nil
@@ -319,6 +343,24 @@ func checkCustomResourceType(obj interface{}, logger *zap.SugaredLogger) (flagge
 	return *roll, true
 }
 
+// isReferenceAllowed returns true if a canary can reference an object from the given namespace,
+// a cross-namespace reference is allowed if the canary namespace is listed in the object allowedNamespaces,
+// or if the list is empty and cross-namespace references are not disabled
+func (c *Controller) isReferenceAllowed(canary *flaggerv1.Canary, namespace string, allowedNamespaces []string) bool {
+	if namespace == canary.Namespace {
+		return true
+	}
+	if len(allowedNamespaces) == 0 {
+		return !c.noCrossNamespaceRefs
+	}
+	for _, ns := range allowedNamespaces {
+		if ns == "*" || ns == canary.Namespace {
+			return true
+		}
+	}
+	return false
+}
+
 func int32p(i int32) *int32 {
 	return &i
 }
@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	// Friday
+	from := time.Date(2021, 10, 15, 10, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		schedule string
+		next     time.Time
+	}{
+		{schedule: "* * * * *", next: time.Date(2021, 10, 15, 10, 31, 0, 0, time.UTC)},
+		{schedule: "@hourly", next: time.Date(2021, 10, 15, 11, 0, 0, 0, time.UTC)},
+		{schedule: "*/20 * * * *", next: time.Date(2021, 10, 15, 10, 40, 0, 0, time.UTC)},
+		{schedule: "15 9-17/4 * * *", next: time.Date(2021, 10, 15, 13, 15, 0, 0, time.UTC)},
+		{schedule: "@daily", next: time.Date(2021, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{schedule: "0 8 * * 1-5", next: time.Date(2021, 10, 18, 8, 0, 0, 0, time.UTC)},
+		{schedule: "0 0 * * 7", next: time.Date(2021, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{schedule: "0 6 1 * *", next: time.Date(2021, 11, 1, 6, 0, 0, 0, time.UTC)},
+		// day of month or day of week
+		{schedule: "0 0 20 * 6", next: time.Date(2021, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{schedule: "0 0 29 2 *", next: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{schedule: "0 0 30 2 *", next: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schedule, func(t *testing.T) {
+			s, err := parseCronSchedule(tt.schedule)
+			require.NoError(t, err)
+			assert.Equal(t, tt.next, s.next(from))
+		})
+	}
+}
+
+func TestCronSchedule_Invalid(t *testing.T) {
+	for _, schedule := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := parseCronSchedule(schedule)
+		assert.Error(t, err, schedule)
+	}
+}
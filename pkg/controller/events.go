@@ -47,6 +47,14 @@ func (c *Controller) recordEventWarningf(r *flaggerv1.Canary, template string, a
 	c.sendEventToWebhook(r, corev1.EventTypeWarning, template, args)
 }
 
+// recordEventDriftf records the external changes to the routing objects
+// with a reason distinct from the regular reconciliation events
+func (c *Controller) recordEventDriftf(r *flaggerv1.Canary, template string, args ...interface{}) {
+	c.logger.With("canary", fmt.Sprintf("%s.%s", r.Name, r.Namespace)).Infof(template, args...)
+	c.eventRecorder.Event(r, corev1.EventTypeWarning, "RoutingDrift", fmt.Sprintf(template, args...))
+	c.sendEventToWebhook(r, corev1.EventTypeWarning, template, args)
+}
+
 func (c *Controller) sendEventToWebhook(r *flaggerv1.Canary, eventType, template string, args []interface{}) {
 	webhookOverride := false
 	for _, canaryWebhook := range r.GetAnalysis().Webhooks {
@@ -106,40 +114,13 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 			continue
 		}
 
-		// determine alert provider namespace
-		providerNamespace := canary.GetNamespace()
-		if alert.ProviderRef.Namespace != "" {
-			providerNamespace = alert.ProviderRef.Namespace
-		}
-
-		// find alert provider
-		provider, err := c.flaggerInformers.AlertInformer.Lister().AlertProviders(providerNamespace).Get(alert.ProviderRef.Name)
+		provider, url, err := c.getAlertProvider(canary, alert)
 		if err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-				Errorf("alert provider %s.%s error: %v", alert.ProviderRef.Name, providerNamespace, err)
+				Errorf("%v", err)
 			continue
 		}
 
-		// set hook URL address
-		url := provider.Spec.Address
-
-		// extract address from secret
-		if provider.Spec.SecretRef != nil {
-			secret, err := c.kubeClient.CoreV1().Secrets(providerNamespace).Get(context.TODO(), provider.Spec.SecretRef.Name, metav1.GetOptions{})
-			if err != nil {
-				c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-					Errorf("alert provider %s.%s secretRef error: %v", alert.ProviderRef.Name, providerNamespace, err)
-				continue
-			}
-			if address, ok := secret.Data["address"]; ok {
-				url = string(address)
-			} else {
-				c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-					Errorf("alert provider %s.%s secret does not contain an address", alert.ProviderRef.Name, providerNamespace)
-				continue
-			}
-		}
-
 		// set defaults
 		username := "flagger"
 		if provider.Spec.Username != "" {
@@ -159,7 +140,7 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 		n, err := f.Notifier(provider.Spec.Type)
 		if err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-				Errorf("alert provider %s.%s error: %v", alert.ProviderRef.Name, providerNamespace, err)
+				Errorf("alert provider %s.%s error: %v", alert.ProviderRef.Name, provider.Namespace, err)
 			continue
 		}
 
@@ -167,12 +148,49 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 		err = n.Post(canary.Name, canary.Namespace, message, fields, string(severity))
 		if err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-				Errorf("alert provider $s.%s send error: %v", alert.ProviderRef.Name, providerNamespace, err)
+				Errorf("alert provider %s.%s send error: %v", alert.ProviderRef.Name, provider.Namespace, err)
 		}
 
 	}
 }
 
+// getAlertProvider returns the alert provider referenced by the canary alert and its address
+func (c *Controller) getAlertProvider(canary *flaggerv1.Canary, alert flaggerv1.CanaryAlert) (*flaggerv1.AlertProvider, string, error) {
+	// determine alert provider namespace
+	providerNamespace := canary.GetNamespace()
+	if alert.ProviderRef.Namespace != "" {
+		providerNamespace = alert.ProviderRef.Namespace
+	}
+
+	// find alert provider
+	provider, err := c.flaggerInformers.AlertInformer.Lister().AlertProviders(providerNamespace).Get(alert.ProviderRef.Name)
+	if err != nil {
+		return nil, "", fmt.Errorf("alert provider %s.%s error: %v", alert.ProviderRef.Name, providerNamespace, err)
+	}
+	if !c.isReferenceAllowed(canary, providerNamespace, provider.Spec.AllowedNamespaces) {
+		return nil, "", fmt.Errorf("alert provider %s.%s can't be referenced from namespace %s",
+			alert.ProviderRef.Name, providerNamespace, canary.Namespace)
+	}
+
+	// set hook URL address
+	url := provider.Spec.Address
+
+	// extract address from secret
+	if provider.Spec.SecretRef != nil {
+		secret, err := c.kubeClient.CoreV1().Secrets(providerNamespace).Get(context.TODO(), provider.Spec.SecretRef.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, "", fmt.Errorf("alert provider %s.%s secretRef error: %v", alert.ProviderRef.Name, providerNamespace, err)
+		}
+		address, ok := secret.Data["address"]
+		if !ok {
+			return nil, "", fmt.Errorf("alert provider %s.%s secret does not contain an address", alert.ProviderRef.Name, providerNamespace)
+		}
+		url = string(address)
+	}
+
+	return provider, url, nil
+}
+
 func alertMetadata(canary *flaggerv1.Canary) []notifier.Field {
 	var fields []notifier.Field
 	fields = append(fields,
@@ -204,7 +222,7 @@ func alertMetadata(canary *flaggerv1.Canary) []notifier.Field {
 				strings.Trim(strings.Join(strings.Fields(fmt.Sprint(canary.GetAnalysis().StepWeights)), ","), "[]"),
 				canary.GetAnalysis().MaxWeight),
 		})
-	} else if len(canary.GetAnalysis().Match) > 0 {
+	} else if len(canary.GetAnalysisMatch()) > 0 {
 		fields = append(fields, notifier.Field{
 			Name:  "Traffic routing",
 			Value: "A/B Testing",
@@ -72,7 +72,7 @@ func (c *Controller) finalize(old interface{}) error {
 	}
 
 	// Revert the Kubernetes service
-	router := c.routerFactory.KubernetesRouter(canary.Spec.TargetRef.Kind, labelSelector, labelValue, ports)
+	router := c.kubernetesRouter(canary, labelSelector, labelValue, ports)
 	if err := router.Finalize(canary); err != nil {
 		return fmt.Errorf("failed revert router: %w", err)
 	}
@@ -95,7 +95,7 @@ func (c *Controller) revertMesh(r *flaggerv1.Canary) error {
 		provider = r.Spec.Provider
 	}
 
-	meshRouter := c.routerFactory.MeshRouter(provider, "")
+	meshRouter := c.routerFactory.WithGatewayAPIMigration(r, provider, "", c.routerFactory.MeshRouter(provider, ""))
 	if err := meshRouter.Finalize(r); err != nil {
 		return fmt.Errorf("meshRouter.Finlize failed: %w", err)
 	}
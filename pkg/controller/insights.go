@@ -0,0 +1,208 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/notifier"
+)
+
+// rollback causes reported by the canary insights
+const (
+	rollbackFailedChecks     = "failed-checks"
+	rollbackProgressDeadline = "progress-deadline"
+	rollbackPodUnhealthy     = "pod-unhealthy"
+	rollbackManual           = "manual"
+	rollbackMigration        = "migration"
+	rollbackGateExpired      = "gate-expired"
+	rollbackTimeSlices       = "time-slices"
+)
+
+// Insights aggregates the rollout statistics of each team between two summary reports
+type Insights struct {
+	teamLabel      string
+	reportInterval time.Duration
+
+	mu     sync.Mutex
+	since  time.Time
+	starts map[string]time.Time
+	teams  map[string]*teamInsights
+}
+
+// teamInsights holds the rollout statistics of a team
+type teamInsights struct {
+	succeeded  int
+	failed     int
+	promotions int
+	promotion  time.Duration
+	causes     map[string]int
+}
+
+// NewInsights creates the rollout statistics aggregator, the team is read from the
+// canary label, defaults to the namespace, and a summary is posted every report interval
+func NewInsights(teamLabel string, reportInterval time.Duration) *Insights {
+	return &Insights{
+		teamLabel:      teamLabel,
+		reportInterval: reportInterval,
+		since:          time.Now(),
+		starts:         make(map[string]time.Time),
+		teams:          make(map[string]*teamInsights),
+	}
+}
+
+func (in *Insights) team(cd *flaggerv1.Canary) string {
+	if in != nil && in.teamLabel != "" {
+		if team := cd.GetLabels()[in.teamLabel]; team != "" {
+			return team
+		}
+	}
+	return cd.Namespace
+}
+
+func (in *Insights) get(team string) *teamInsights {
+	t, ok := in.teams[team]
+	if !ok {
+		t = &teamInsights{causes: make(map[string]int)}
+		in.teams[team] = t
+	}
+	return t
+}
+
+// start records the start of the canary analysis
+func (in *Insights) start(cd *flaggerv1.Canary, now time.Time) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.starts[fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)] = now
+}
+
+// promoted counts a successful rollout and returns the time spent since the start
+// of the analysis, zero if the analysis started before Flagger was restarted
+func (in *Insights) promoted(cd *flaggerv1.Canary, now time.Time) time.Duration {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	t := in.get(in.team(cd))
+	t.succeeded++
+
+	key := fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)
+	start, ok := in.starts[key]
+	if !ok {
+		return 0
+	}
+	delete(in.starts, key)
+	duration := now.Sub(start)
+	t.promotions++
+	t.promotion += duration
+	return duration
+}
+
+// rolledBack counts a failed rollout and its cause
+func (in *Insights) rolledBack(cd *flaggerv1.Canary, cause string) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	t := in.get(in.team(cd))
+	t.failed++
+	t.causes[cause]++
+	delete(in.starts, fmt.Sprintf("%s.%s", cd.Name, cd.Namespace))
+}
+
+// report returns the summary of each team since the last report and resets the statistics
+func (in *Insights) report(now time.Time) (string, []notifier.Field) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	teams := make([]string, 0, len(in.teams))
+	total, succeeded := 0, 0
+	for team, t := range in.teams {
+		teams = append(teams, team)
+		total += t.succeeded + t.failed
+		succeeded += t.succeeded
+	}
+	sort.Strings(teams)
+
+	message := fmt.Sprintf("No canary rollouts since %s", in.since.Format(time.RFC3339))
+	if total > 0 {
+		message = fmt.Sprintf("%v canary rollouts since %s, %.0f%% succeeded",
+			total, in.since.Format(time.RFC3339), float64(succeeded)*100/float64(total))
+	}
+
+	fields := make([]notifier.Field, 0, len(teams))
+	for _, team := range teams {
+		fields = append(fields, notifier.Field{Name: team, Value: in.teams[team].summary()})
+	}
+
+	in.since = now
+	in.teams = make(map[string]*teamInsights)
+	return message, fields
+}
+
+func (t *teamInsights) summary() string {
+	total := t.succeeded + t.failed
+	parts := []string{fmt.Sprintf("%v rollouts, %.0f%% succeeded", total, float64(t.succeeded)*100/float64(total))}
+	if t.promotions > 0 {
+		mean := t.promotion / time.Duration(t.promotions)
+		parts = append(parts, fmt.Sprintf("mean time to promote %s", mean.Round(time.Second)))
+	}
+	if len(t.causes) > 0 {
+		causes := make([]string, 0, len(t.causes))
+		for cause, n := range t.causes {
+			causes = append(causes, fmt.Sprintf("%s %v", cause, n))
+		}
+		sort.Strings(causes)
+		parts = append(parts, fmt.Sprintf("rollbacks %s", strings.Join(causes, ", ")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// recordAnalysisStart marks the start of a rollout for the time to promote
+func (c *Controller) recordAnalysisStart(cd *flaggerv1.Canary) {
+	if c.insights != nil {
+		c.insights.start(cd, time.Now())
+	}
+}
+
+// recordPromotion exports a successful rollout of the canary team
+func (c *Controller) recordPromotion(cd *flaggerv1.Canary) {
+	var duration time.Duration
+	if c.insights != nil {
+		duration = c.insights.promoted(cd, time.Now())
+	}
+	c.recorder.SetPromotion(c.insights.team(cd), duration)
+}
+
+// recordRollback exports a failed rollout of the canary team and its cause
+func (c *Controller) recordRollback(cd *flaggerv1.Canary, cause string) {
+	if c.insights != nil {
+		c.insights.rolledBack(cd, cause)
+	}
+	c.recorder.SetRollback(c.insights.team(cd), cause)
+}
+
+// postInsightsReport sends the rollouts summary with the global notifier
+func (c *Controller) postInsightsReport() {
+	message, fields := c.insights.report(time.Now())
+	if err := c.notifier.Post("flagger", "insights", message, fields, string(flaggerv1.SeverityInfo)); err != nil {
+		c.logger.Errorf("insights report can't be sent: %v", err)
+	}
+}
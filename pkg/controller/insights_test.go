@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestInsights_Report(t *testing.T) {
+	in := NewInsights("team", 0)
+	now := time.Now()
+
+	payments := &flaggerv1.Canary{ObjectMeta: metav1.ObjectMeta{
+		Name: "api", Namespace: "prod", Labels: map[string]string{"team": "payments"},
+	}}
+	search := &flaggerv1.Canary{ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: "prod"}}
+
+	in.start(payments, now.Add(-30*time.Minute))
+	assert.Equal(t, 30*time.Minute, in.promoted(payments, now))
+	in.start(payments, now.Add(-10*time.Minute))
+	assert.Equal(t, 10*time.Minute, in.promoted(payments, now))
+	in.start(payments, now)
+	in.rolledBack(payments, rollbackFailedChecks)
+	in.rolledBack(search, rollbackManual)
+
+	// the promotion time is unknown after a restart
+	assert.Equal(t, time.Duration(0), in.promoted(search, now))
+
+	message, fields := in.report(now)
+	assert.Contains(t, message, "5 canary rollouts since")
+	assert.Contains(t, message, "60% succeeded")
+	require.Len(t, fields, 2)
+	assert.Equal(t, "payments", fields[0].Name)
+	assert.Equal(t, "3 rollouts, 67% succeeded, mean time to promote 20m0s, rollbacks failed-checks 1", fields[0].Value)
+	assert.Equal(t, "prod", fields[1].Name)
+	assert.Equal(t, "2 rollouts, 50% succeeded, rollbacks manual 1", fields[1].Value)
+
+	// the statistics are reset after each report
+	message, fields = in.report(now)
+	assert.Contains(t, message, "No canary rollouts since")
+	assert.Empty(t, fields)
+}
@@ -18,11 +18,13 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/util/retry"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -71,15 +73,11 @@ func (c *Controller) nextStepWeight(canary *flaggerv1.Canary, canaryWeight int)
 
 	// return min of maxStep and the calculated step to avoid going above totalWeight
 
-	// initial step
-	if canaryWeight == 0 {
-		return c.min(maxStep, canary.GetAnalysis().StepWeights[0])
-	}
-
-	// find the current step and return the difference in weight
-	for i := 0; i < stepWeightsLen-1; i++ {
-		if canary.GetAnalysis().StepWeights[i] == canaryWeight {
-			return c.min(maxStep, canary.GetAnalysis().StepWeights[i+1]-canaryWeight)
+	// find the first step above the current weight and return the difference in weight,
+	// the current weight may not be in the array when the steps are changed during the analysis
+	for _, w := range canary.GetAnalysis().StepWeights {
+		if w > canaryWeight {
+			return c.min(maxStep, w-canaryWeight)
 		}
 	}
 
@@ -101,8 +99,9 @@ func (c *Controller) scheduleCanaries() {
 		current[name] = fmt.Sprintf("%s.%s", cn.Spec.TargetRef.Name, cn.Namespace)
 
 		job, exists := c.jobs[name]
+		interval := c.analysisInterval(cn)
 		// schedule new job for existing job with different analysis interval or non-existing job
-		if (exists && job.GetCanaryAnalysisInterval() != cn.GetAnalysisInterval()) || !exists {
+		if (exists && job.GetCanaryAnalysisInterval() != interval) || !exists {
 			if exists {
 				job.Stop()
 			}
@@ -112,8 +111,8 @@ func (c *Controller) scheduleCanaries() {
 				Namespace:        cn.Namespace,
 				function:         c.advanceCanary,
 				done:             make(chan bool),
-				ticker:           time.NewTicker(cn.GetAnalysisInterval()),
-				analysisInterval: cn.GetAnalysisInterval(),
+				ticker:           time.NewTicker(interval),
+				analysisInterval: interval,
 			}
 
 			c.jobs[name] = newJob
@@ -164,12 +163,40 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		return
 	}
 
+	// a rollback can only abort an analysis, discard the requests made in the other phases
+	if isRollbackRequested(cd) && !isRollbackPhase(cd) {
+		c.recordEventWarningf(cd, "Ignoring the %s annotation of %s.%s, there is no analysis to roll back in the %s phase",
+			rollbackAnnotation, cd.Name, cd.Namespace, cd.Status.Phase)
+		c.clearRollbackRequest(cd)
+		delete(cd.Annotations, rollbackAnnotation)
+	}
+
+	// hold the canary in its current state, without failing or promoting it,
+	// a rollback requested with the annotation is carried out while suspended
+	if cd.Spec.Suspend && !isRollbackRequested(cd) {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Debugf("Skipping canary run, %s.%s is suspended", cd.Name, cd.Namespace)
+		return
+	}
+
+	// load the pacing of the rollout profile, the profile changes apply on the next run
+	if err := c.applyAnalysisProfile(cd); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+
 	// override the global provider if one is specified in the canary spec
 	provider := c.meshProvider
 	if cd.Spec.Provider != "" {
 		provider = cd.Spec.Provider
 	}
 
+	// reject the routing features the provider doesn't implement instead of ignoring them
+	if err := router.ValidateCapabilities(provider, cd); err != nil {
+		c.recordEventErrorf(cd, "Canary %s.%s is not valid: %v", cd.Name, cd.Namespace, err)
+		return
+	}
+
 	// init controller based on target kind
 	canaryController := c.canaryFactory.Controller(cd.Spec.TargetRef.Kind)
 	labelSelector, labelValue, ports, err := canaryController.GetMetadata(cd)
@@ -179,11 +206,10 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	}
 
 	// init Kubernetes router
-	kubeRouter := c.routerFactory.KubernetesRouter(cd.Spec.TargetRef.Kind, labelSelector, labelValue, ports)
+	kubeRouter := c.kubernetesRouter(cd, labelSelector, labelValue, ports)
 
 	// reconcile the canary/primary services
-	if err := kubeRouter.Initialize(cd); err != nil {
-		c.recordEventWarningf(cd, "%v", err)
+	if err := kubeRouter.Initialize(cd); c.routingFailed(cd, err) {
 		return
 	}
 
@@ -194,14 +220,15 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		}
 	}
 
-	// init mesh router
-	meshRouter := c.routerFactory.MeshRouter(provider, labelSelector)
+	// init mesh router, the routing objects are snapshotted before each traffic change if enabled
+	// and the weights are written to both SMI and Gateway API objects during a migration
+	meshRouter := c.routerFactory.WithSnapshots(cd,
+		c.routerFactory.WithGatewayAPIMigration(cd, provider, labelSelector, c.routerFactory.MeshRouter(provider, labelSelector)))
 
 	// register the AppMesh VirtualNodes before creating the primary deployment
 	// otherwise the pods will not be injected with the Envoy proxy
 	if strings.HasPrefix(provider, flaggerv1.AppMeshProvider) {
-		if err := meshRouter.Reconcile(cd); err != nil {
-			c.recordEventWarningf(cd, "%v", err)
+		if err := meshRouter.Reconcile(cd); c.routingFailed(cd, err) {
 			return
 		}
 	}
@@ -214,15 +241,31 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	}
 
 	// change the apex service pod selector to primary
-	if err := kubeRouter.Reconcile(cd); err != nil {
-		c.recordEventWarningf(cd, "%v", err)
+	if err := kubeRouter.Reconcile(cd); c.routingFailed(cd, err) {
 		return
 	}
 
 	// take over an existing virtual service or ingress
 	// runs after the primary is ready to ensure zero downtime
 	if !strings.HasPrefix(provider, flaggerv1.AppMeshProvider) {
-		if err := meshRouter.Reconcile(cd); err != nil {
+		if err := meshRouter.Reconcile(cd); c.routingFailed(cd, err) {
+			return
+		}
+	}
+
+	// publish or remove the canary preview host
+	if err := c.reconcileCanaryDNS(cd); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+	}
+
+	// create or tear down the canary preview stack
+	if err := c.reconcileCanaryPreview(cd); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+	}
+
+	// scale down the workload only after the live traffic has been switched to primary
+	if cd.IsTakeoverBootstrap() && (cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing) {
+		if err := c.completeTakeover(cd, canaryController); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
 			return
 		}
@@ -237,11 +280,37 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 
 	if !shouldAdvance {
 		c.recorder.SetStatus(cd, cd.Status.Phase)
+		c.runScheduledVerification(cd)
+		// report the live traffic split of idle canaries
+		if primaryWeight, canaryWeight, mirrored, err := meshRouter.GetRoutes(cd); err == nil {
+			c.recordStatusRoutes(cd, provider, primaryWeight, canaryWeight, mirrored)
+		}
 		return
 	}
 
+	// roll back on request before the gates and readiness checks,
+	// so that a canary stuck on a failing revision can be aborted
+	if isRollbackRequested(cd) {
+		c.recordEventWarningf(cd, "Rolling back %s.%s %s annotation set", cd.Name, cd.Namespace, rollbackAnnotation)
+		c.alert(cd, fmt.Sprintf("Rolling back %s annotation set", rollbackAnnotation), false, flaggerv1.SeverityWarn)
+		c.rollback(cd, canaryController, meshRouter, rollbackManual)
+		c.clearRollbackRequest(cd)
+		return
+	}
+
+	// hold off new analyses while the error budget is exhausted
+	// or the previous rings of a ring rollout haven't completed
+	if isStartingAnalysis(cd) {
+		if ok := c.runErrorBudgetCheck(cd); !ok {
+			return
+		}
+		if ok := c.runRingsCheck(cd, canaryController); !ok {
+			return
+		}
+	}
+
 	// check gates
-	if isApproved := c.runConfirmRolloutHooks(cd, canaryController); !isApproved {
+	if isApproved := c.runConfirmRolloutHooks(cd, canaryController, meshRouter); !isApproved {
 		return
 	}
 
@@ -263,6 +332,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	}
 
 	c.recorder.SetWeight(cd, primaryWeight, canaryWeight)
+	c.recordStatusRoutes(cd, provider, primaryWeight, canaryWeight, mirrored)
 
 	// check if canary analysis should start (canary revision has changes) or continue
 	if ok := c.checkCanaryStatus(cd, canaryController, shouldAdvance); !ok {
@@ -315,23 +385,38 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		if ok := c.runRollbackHooks(cd, cd.Status.Phase); ok {
 			c.recordEventWarningf(cd, "Rolling back %s.%s manual webhook invoked", cd.Name, cd.Namespace)
 			c.alert(cd, "Rolling back manual webhook invoked", false, flaggerv1.SeverityWarn)
-			c.rollback(cd, canaryController, meshRouter)
+			c.rollback(cd, canaryController, meshRouter, rollbackManual)
 			return
 		}
 	}
 
 	// route traffic back to primary if analysis has succeeded
 	if cd.Status.Phase == flaggerv1.CanaryPhasePromoting {
+		if c.isDraining(cd, canaryWeight) {
+			return
+		}
 		c.runPromotionTrafficShift(cd, canaryController, meshRouter, provider, canaryWeight, primaryWeight)
 		return
 	}
 
-	// scale canary to zero if promotion has finished
+	// scale canary down if promotion has finished
 	if cd.Status.Phase == flaggerv1.CanaryPhaseFinalising {
-		if err := canaryController.ScaleToZero(cd); err != nil {
+		if c.isDraining(cd, canaryWeight) {
+			return
+		}
+		if delay := cd.GetScaleDownDelay(); time.Since(cd.Status.LastTransitionTime.Time) < delay {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+				Debugf("Scale down of %s.%s delayed for %v", cd.Spec.TargetRef.Name, cd.Namespace, delay)
+			return
+		}
+
+		if err := c.scaleDownCanary(cd, canaryController); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
 			return
 		}
+		if err := c.deleteBaseline(cd, canaryController); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+		}
 
 		// set status to succeeded
 		if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseSucceeded); err != nil {
@@ -339,6 +424,8 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			return
 		}
 		c.recorder.SetStatus(cd, flaggerv1.CanaryPhaseSucceeded)
+		c.recordPromotion(cd)
+		c.annotateTargetResult(cd, flaggerv1.CanaryPhaseSucceeded)
 		c.runPostRolloutHooks(cd, flaggerv1.CanaryPhaseSucceeded)
 		c.recordEventInfof(cd, "Promotion completed! Scaling down %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
 		c.alert(cd, "Canary analysis completed successfully, promotion finished.",
@@ -349,25 +436,79 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	// check if the number of failed checks reached the threshold
 	if cd.Status.Phase == flaggerv1.CanaryPhaseProgressing &&
 		(!retriable || cd.Status.FailedChecks >= cd.GetAnalysisThreshold()) {
-		if !retriable {
+		cause := rollbackFailedChecks
+		if !retriable && errors.Is(err, canary.ErrPodUnhealthy) {
+			c.recordEventWarningf(cd, "Rolling back %s.%s %v", cd.Name, cd.Namespace, err)
+			c.alert(cd, fmt.Sprintf("Canary pods unhealthy %v", err),
+				false, flaggerv1.SeverityError)
+			cause = rollbackPodUnhealthy
+		} else if !retriable {
 			c.recordEventWarningf(cd, "Rolling back %s.%s progress deadline exceeded %v",
 				cd.Name, cd.Namespace, err)
 			c.alert(cd, fmt.Sprintf("Progress deadline exceeded %v", err),
 				false, flaggerv1.SeverityError)
+			cause = rollbackProgressDeadline
 		}
-		c.rollback(cd, canaryController, meshRouter)
+		c.rollback(cd, canaryController, meshRouter, cause)
+		return
+	}
+
+	// wait for the connections to drain after a traffic weight change
+	if c.isDraining(cd, canaryWeight) {
 		return
 	}
 
+	// hold the canary at its current weight while an upstream dependency is unhealthy
+	if cd.Status.Phase == flaggerv1.CanaryPhaseProgressing {
+		if err := c.checkDependencies(cd); err != nil {
+			c.recordEventWarningf(cd, "Halt advancement %s.%s %v", cd.Name, cd.Namespace, err)
+			return
+		}
+
+		// hold the canary at its current weight while the observability providers are unreachable
+		if c.holdOnProviderOutage(cd) {
+			return
+		}
+	}
+
 	// record analysis duration
 	defer func() {
 		c.recorder.SetDuration(cd, time.Since(begin))
 	}()
 
+	// the metrics margin is used to size the adaptive steps
+	margin := &analysisMargin{}
+
+	// verify the data plane converged to the last step before analysing its metrics
+	if cd.GetAnalysis().Iterations == 0 && cd.Status.Phase == flaggerv1.CanaryPhaseProgressing {
+		if err := c.verifyTraffic(cd, canaryWeight); err != nil {
+			c.recordEventWarningf(cd, "Halt %s.%s advancement traffic verification failed %v", cd.Name, cd.Namespace, err)
+			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+			}
+			return
+		}
+	}
+
 	// check if the canary success rate is above the threshold
 	// skip check if no traffic is routed or mirrored to canary
 	if canaryWeight == 0 && cd.Status.Iterations == 0 &&
 		!(cd.GetAnalysis().Mirror && mirrored) {
+		// start the analysis only during the schedule windows
+		if c.waitForAnalysisWindow(cd) {
+			return
+		}
+
+		// run the migration job and wait for it to complete
+		if ok, err := c.runUpMigration(cd); err != nil {
+			c.recordEventWarningf(cd, "Rolling back %s.%s migration failed %v", cd.Name, cd.Namespace, err)
+			c.alert(cd, fmt.Sprintf("Migration failed %v", err), false, flaggerv1.SeverityError)
+			c.rollback(cd, canaryController, meshRouter, rollbackMigration)
+			return
+		} else if !ok {
+			return
+		}
+
 		c.recordEventInfof(cd, "Starting canary analysis for %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
 
 		// run pre-rollout web hooks
@@ -378,20 +519,23 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			return
 		}
 	} else {
-		if ok := c.runAnalysis(cd); !ok {
-			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
-				c.recordEventWarningf(cd, "%v", err)
+		if !isPrimaryTimeSlice(cd) {
+			if ok := c.runAnalysis(cd, margin); !ok {
+				if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
+					c.recordEventWarningf(cd, "%v", err)
+				}
+				return
 			}
+		}
+
+		// outside of the schedule windows the metric checks run but the canary doesn't advance
+		if c.waitForAnalysisWindow(cd) {
 			return
 		}
 	}
 
 	// use blue/green strategy for kubernetes provider
 	if provider == flaggerv1.KubernetesProvider {
-		if len(cd.GetAnalysis().Match) > 0 {
-			c.recordEventWarningf(cd, "A/B testing is not supported when using the kubernetes provider")
-			cd.GetAnalysis().Match = nil
-		}
 		if cd.GetAnalysis().Iterations < 1 {
 			c.recordEventWarningf(cd, "Progressive traffic is not supported when using the kubernetes provider")
 			c.recordEventWarningf(cd, "Setting canaryAnalysis.iterations: 10")
@@ -399,8 +543,14 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		}
 	}
 
+	// strategy: time-sliced experiment
+	if cd.GetAnalysis().TimeSlices != nil {
+		c.runTimeSlices(cd, canaryController, meshRouter)
+		return
+	}
+
 	// strategy: A/B testing
-	if len(cd.GetAnalysis().Match) > 0 && cd.GetAnalysis().Iterations > 0 {
+	if len(cd.GetAnalysisMatch()) > 0 && cd.GetAnalysis().Iterations > 0 {
 		c.runAB(cd, canaryController, meshRouter)
 		return
 	}
@@ -411,11 +561,36 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		return
 	}
 
+	// strategy: Canary traffic increase decided by the step decider
+	decision, err := c.decideStep(cd, canaryWeight, maxWeight, margin)
+	if err != nil {
+		c.recordEventWarningf(cd, "Halt %s.%s advancement %v", cd.Name, cd.Namespace, err)
+		if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+		}
+		return
+	}
+	switch decision.Action {
+	case flaggerv1.DecisionHold:
+		c.recordEventInfof(cd, "Hold %s.%s canary weight %v %s", cd.Name, cd.Namespace, canaryWeight, decision.Reason)
+		return
+	case flaggerv1.DecisionFail:
+		c.recordEventWarningf(cd, "Halt %s.%s advancement decider failed the check %s", cd.Name, cd.Namespace, decision.Reason)
+		if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+		}
+		return
+	}
+	if decision.StepWeight > 0 {
+		cd.GetAnalysis().StepWeight = decision.StepWeight
+		cd.GetAnalysis().StepWeights = nil
+	}
+
 	// strategy: Canary progressive traffic increase
-	if c.nextStepWeight(cd, canaryWeight) > 0 {
+	if step := c.nextStepWeight(cd, canaryWeight); step > 0 {
 		// run hook only if traffic is not mirrored
 		if !mirrored {
-			if promote := c.runConfirmTrafficIncreaseHooks(cd); !promote {
+			if promote := c.runConfirmTrafficIncreaseHooks(cd, canaryController, meshRouter, canaryWeight, canaryWeight+step); !promote {
 				return
 			}
 		}
@@ -435,13 +610,17 @@ func (c *Controller) runPromotionTrafficShift(canary *flaggerv1.Canary, canaryCo
 	}
 
 	// route all traffic to primary in one go when promotion step wight is not set
-	if canary.Spec.Analysis.StepWeightPromotion == 0 {
+	if canary.GetAnalysis().StepWeightPromotion == 0 {
 		c.recordEventInfof(canary, "Routing all traffic to primary")
 		if err := meshRouter.SetRoutes(canary, c.totalWeight(canary), 0, false); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
 			return
 		}
 		c.recorder.SetWeight(canary, c.totalWeight(canary), 0)
+		if err := canaryController.SetStatusWeight(canary, 0); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+			return
+		}
 		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseFinalising); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
 		}
@@ -464,16 +643,20 @@ func (c *Controller) runPromotionTrafficShift(canary *flaggerv1.Canary, canaryCo
 		}
 		c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
 		c.recordEventInfof(canary, "Advance %s.%s primary weight %v", canary.Name, canary.Namespace, primaryWeight)
+		if canaryWeight > 0 {
+			c.scaleCanaryToWeight(canary, canaryController, canaryWeight)
+		}
+
+		if err := canaryController.SetStatusWeight(canary, canaryWeight); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+			return
+		}
 
 		// finalize promotion
 		if primaryWeight == c.totalWeight(canary) {
 			if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseFinalising); err != nil {
 				c.recordEventWarningf(canary, "%v", err)
 			}
-		} else {
-			if err := canaryController.SetStatusWeight(canary, canaryWeight); err != nil {
-				c.recordEventWarningf(canary, "%v", err)
-			}
 		}
 	}
 
@@ -517,6 +700,11 @@ func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary
 			}
 		}
 
+		// scale up the canary before routing more traffic to it
+		if !mirrored {
+			c.scaleCanaryToWeight(canary, canaryController, canaryWeight)
+		}
+
 		if err := meshRouter.SetRoutes(canary, primaryWeight, canaryWeight, mirrored); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
 			return
@@ -535,7 +723,7 @@ func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary
 	// promote canary - max weight reached
 	if canaryWeight >= maxWeight {
 		// check promotion gate
-		if promote := c.runConfirmPromotionHooks(canary, canaryController); !promote {
+		if promote := c.runConfirmPromotionHooks(canary, canaryController, meshRouter); !promote {
 			return
 		}
 
@@ -577,7 +765,7 @@ func (c *Controller) runAB(canary *flaggerv1.Canary, canaryController canary.Con
 	}
 
 	// check promotion gate
-	if promote := c.runConfirmPromotionHooks(canary, canaryController); !promote {
+	if promote := c.runConfirmPromotionHooks(canary, canaryController, meshRouter); !promote {
 		return
 	}
 
@@ -607,11 +795,12 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 		// If in "mirror" mode, mirror requests during the entire B/G canary test
 		if provider != "kubernetes" &&
 			canary.GetAnalysis().Mirror && !mirrored {
+			// the iterations only count when the traffic is mirrored to the canary
 			if err := meshRouter.SetRoutes(canary, c.totalWeight(canary), 0, true); err != nil {
 				c.recordEventWarningf(canary, "%v", err)
+				return
 			}
-			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-				Infof("Start traffic mirroring")
+			c.recordEventInfof(canary, "Start traffic mirroring")
 		}
 		if err := canaryController.SetStatusIterations(canary, canary.Status.Iterations+1); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
@@ -623,7 +812,7 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 	}
 
 	// check promotion gate
-	if promote := c.runConfirmPromotionHooks(canary, canaryController); !promote {
+	if promote := c.runConfirmPromotionHooks(canary, canaryController, meshRouter); !promote {
 		return
 	}
 
@@ -668,30 +857,34 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 
 }
 
-func (c *Controller) runAnalysis(canary *flaggerv1.Canary) bool {
+func (c *Controller) runAnalysis(canary *flaggerv1.Canary, margin *analysisMargin) bool {
 	// run external checks
+	var webhooks []flaggerv1.CanaryWebhook
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == "" || webhook.Type == flaggerv1.RolloutHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
-			if err != nil {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement external check %s failed %v",
-					canary.Name, canary.Namespace, webhook.Name, err)
-				return false
-			}
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	for _, r := range c.callWebhookGroups(canary, flaggerv1.CanaryPhaseProgressing, webhooks) {
+		if r.err != nil {
+			c.recordEventWarningf(canary, "Halt %s.%s advancement external check %s failed %v",
+				canary.Name, canary.Namespace, r.webhook.Name, r.err)
+			return false
 		}
 	}
 
-	ok := c.runBuiltinMetricChecks(canary)
+	score := newAnalysisScore(canary)
+	ok := c.runBuiltinMetricChecks(canary, margin, score)
 	if !ok {
 		return ok
 	}
 
-	ok = c.runMetricChecks(canary)
+	ok = c.runMetricChecks(canary, margin, score)
 	if !ok {
 		return ok
 	}
 
-	return true
+	return c.checkScore(canary, score)
 }
 
 func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface, err error, retriable bool) bool {
@@ -703,8 +896,16 @@ func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryControll
 	if !retriable || canary.Status.FailedChecks >= canary.GetAnalysisThreshold() {
 		c.recordEventWarningf(canary, "Rolling back %s.%s progress deadline exceeded %v", canary.Name, canary.Namespace, err)
 		c.alert(canary, fmt.Sprintf("Progress deadline exceeded %v", err), false, flaggerv1.SeverityError)
-		c.rollback(canary, canaryController, meshRouter)
+		c.rollback(canary, canaryController, meshRouter, rollbackProgressDeadline)
+
+		return true
+	}
 
+	// run pre-rollout web hooks, metric checks are skipped
+	if ok := c.runPreRolloutHooks(canary); !ok {
+		if err := canaryController.SetStatusFailedChecks(canary, canary.Status.FailedChecks+1); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+		}
 		return true
 	}
 
@@ -717,6 +918,19 @@ func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryControll
 	}
 	c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
 
+	// verify that the routing conforms to the desired state before promoting
+	if p, cw, mirrored, err := meshRouter.GetRoutes(canary); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return false
+	} else if p != primaryWeight || cw != canaryWeight || mirrored {
+		c.recordEventWarningf(canary, "Halt %s.%s promotion routing verification failed, primary weight %v canary weight %v mirrored %v",
+			canary.Name, canary.Namespace, p, cw, mirrored)
+		if err := canaryController.SetStatusFailedChecks(canary, canary.Status.FailedChecks+1); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+		}
+		return true
+	}
+
 	// copy spec and configs from canary to primary
 	c.recordEventInfof(canary, "Copying %s.%s template spec to %s-primary.%s",
 		canary.Spec.TargetRef.Name, canary.Namespace, canary.Spec.TargetRef.Name, canary.Namespace)
@@ -726,19 +940,24 @@ func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryControll
 	}
 
 	// shutdown canary
-	if err := canaryController.ScaleToZero(canary); err != nil {
+	if err := c.scaleDownCanary(canary, canaryController); err != nil {
 		c.recordEventWarningf(canary, "%v", err)
 		return false
 	}
+	if err := c.deleteBaseline(canary, canaryController); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+	}
 
 	// update status phase
-	if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseSucceeded); err != nil {
+	if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhasePromotedWithoutAnalysis); err != nil {
 		c.recordEventWarningf(canary, "%v", err)
 		return false
 	}
 
 	// notify
-	c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseSucceeded)
+	c.recorder.SetStatus(canary, flaggerv1.CanaryPhasePromotedWithoutAnalysis)
+	c.annotateTargetResult(canary, flaggerv1.CanaryPhasePromotedWithoutAnalysis)
+	c.runPostRolloutHooks(canary, flaggerv1.CanaryPhasePromotedWithoutAnalysis)
 	c.recordEventInfof(canary, "Promotion completed! Canary analysis was skipped for %s.%s",
 		canary.Spec.TargetRef.Name, canary.Namespace)
 	c.alert(canary, "Canary analysis was skipped, promotion finished.",
@@ -804,6 +1023,28 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 	}
 
 	if shouldAdvance {
+		if reason, err := c.checkCapacity(canary); err != nil {
+			c.recordEventErrorf(canary, "%v", err)
+			return false
+		} else if reason != "" {
+			c.recordEventWarningf(canary, "Canary failed! Capacity check for %s.%s failed: %s",
+				canary.Spec.TargetRef.Name, canary.Namespace, reason)
+			c.alert(canary, fmt.Sprintf("Capacity check failed %s", reason), false, flaggerv1.SeverityError)
+			if err := canaryController.SyncStatus(canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseFailed}); err != nil {
+				c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
+				return false
+			}
+			c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseFailed)
+			return false
+		}
+
+		if c.quota != nil {
+			if err := c.quota.CheckAnalyses(context.TODO(), canary); err != nil {
+				c.recordEventWarningf(canary, "Halt %s.%s advancement %v", canary.Name, canary.Namespace, err)
+				return false
+			}
+		}
+
 		canaryPhaseProgressing := canary.DeepCopy()
 		canaryPhaseProgressing.Status.Phase = flaggerv1.CanaryPhaseProgressing
 		c.recordEventInfof(canaryPhaseProgressing, "New revision detected! Scaling up %s.%s", canaryPhaseProgressing.Spec.TargetRef.Name, canaryPhaseProgressing.Namespace)
@@ -814,11 +1055,21 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 			c.recordEventErrorf(canary, "%v", err)
 			return false
 		}
-		if err := canaryController.SyncStatus(canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing}); err != nil {
+		if err := c.createBaseline(canary, canaryController); err != nil {
+			c.recordEventErrorf(canary, "%v", err)
+			return false
+		}
+		// tag the analysis run so that it can be joined with the service metrics
+		status := flaggerv1.CanaryStatus{
+			Phase:         flaggerv1.CanaryPhaseProgressing,
+			AnalysisRunID: string(uuid.NewUUID()),
+		}
+		if err := canaryController.SyncStatus(canary, status); err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 			return false
 		}
 		c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseProgressing)
+		c.recordAnalysisStart(canary)
 		return false
 	}
 	return false
@@ -837,7 +1088,7 @@ func (c *Controller) hasCanaryRevisionChanged(canary *flaggerv1.Canary, canaryCo
 	return false
 }
 
-func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) {
+func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface, cause string) {
 	if canary.Status.FailedChecks >= canary.GetAnalysisThreshold() {
 		c.recordEventWarningf(canary, "Rolling back %s.%s failed checks threshold reached %v",
 			canary.Name, canary.Namespace, canary.Status.FailedChecks)
@@ -860,11 +1111,40 @@ func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.
 
 	c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
 
+	// capture the canary pods logs before they are removed
+	if name, err := canaryController.SaveFailureReport(canary); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+	} else if name != "" {
+		c.recordEventInfof(canary, "Failure report saved to config map %s.%s", name, canary.Namespace)
+	}
+
 	// shutdown canary
 	if err := canaryController.ScaleToZero(canary); err != nil {
 		c.recordEventWarningf(canary, "%v", err)
 		return
 	}
+	if err := c.deleteBaseline(canary, canaryController); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+	}
+
+	// revert the migration applied for this revision
+	if err := c.runDownMigration(canary); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+	}
+
+	// remove the canary preview host
+	if canary.Spec.DNS != nil {
+		if err := c.removeCanaryDNS(canary); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+		}
+	}
+
+	// tear down the canary preview stack
+	if canary.Spec.Preview != nil {
+		if err := c.removeCanaryPreview(canary); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+		}
+	}
 
 	// mark canary as failed
 	if err := canaryController.SyncStatus(canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseFailed, CanaryWeight: 0}); err != nil {
@@ -873,9 +1153,91 @@ func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.
 	}
 
 	c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseFailed)
+	c.recordRollback(canary, cause)
+	c.annotateTargetResult(canary, flaggerv1.CanaryPhaseFailed)
 	c.runPostRolloutHooks(canary, flaggerv1.CanaryPhaseFailed)
 }
 
+// isDraining returns true if the last traffic weight change happened
+// less than the drain duration ago
+func (c *Controller) isDraining(canary *flaggerv1.Canary, canaryWeight int) bool {
+	drain := canary.GetAnalysisDrainDuration()
+	if drain == 0 {
+		return false
+	}
+
+	switch canary.Status.Phase {
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhasePromoting:
+		if canaryWeight == 0 {
+			return false
+		}
+	case flaggerv1.CanaryPhaseFinalising:
+	default:
+		return false
+	}
+
+	// the status transition time is also updated by the checks and the iterations,
+	// only the weight changes recorded in the status restart the drain
+	if time.Since(canary.Status.LastWeightChangeTime.Time) < drain {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Debugf("Waiting %v for connections to drain", drain)
+		return true
+	}
+	return false
+}
+
+// scaleDownCanary scales the canary workload after promotion
+// according to the canary scale down policy
+func (c *Controller) scaleDownCanary(canary *flaggerv1.Canary, canaryController canary.Controller) error {
+	scaleDown := canary.Spec.ScaleDown
+	switch {
+	case scaleDown == nil:
+		return canaryController.ScaleToZero(canary)
+	case scaleDown.Disabled:
+		return nil
+	case scaleDown.Replicas != nil:
+		return canaryController.ScaleTo(canary, *scaleDown.Replicas)
+	default:
+		return canaryController.ScaleToZero(canary)
+	}
+}
+
+// createBaseline deploys a copy of the primary workload when a baseline is enabled for the analysis
+func (c *Controller) createBaseline(cd *flaggerv1.Canary, canaryController canary.Controller) error {
+	if !cd.GetAnalysis().Baseline {
+		return nil
+	}
+	baseline, ok := canaryController.(canary.BaselineController)
+	if !ok {
+		return fmt.Errorf("baseline is not supported for %s targets", cd.Spec.TargetRef.Kind)
+	}
+	return baseline.CreateBaseline(cd)
+}
+
+// deleteBaseline removes the baseline workload at the end of the analysis,
+// it runs regardless of the analysis spec as the baseline may have been disabled meanwhile
+func (c *Controller) deleteBaseline(cd *flaggerv1.Canary, canaryController canary.Controller) error {
+	baseline, ok := canaryController.(canary.BaselineController)
+	if !ok {
+		return nil
+	}
+	return baseline.DeleteBaseline(cd)
+}
+
+// scaleCanaryToWeight sizes the canary workload proportionally to its traffic weight
+func (c *Controller) scaleCanaryToWeight(cd *flaggerv1.Canary, canaryController canary.Controller, canaryWeight int) {
+	if !cd.GetAnalysis().ProportionalReplicas {
+		return
+	}
+	scaler, ok := canaryController.(canary.WeightScaler)
+	if !ok {
+		return
+	}
+	if err := scaler.ScaleToWeight(cd, canaryWeight); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+	}
+}
+
 func (c *Controller) setPhaseInitializing(cd *flaggerv1.Canary) error {
 	phase := flaggerv1.CanaryPhaseInitializing
 	firstTry := true
@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"math"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// analysisMargin tracks the smallest relative distance of the metric values to their thresholds
+type analysisMargin struct {
+	value    float64
+	observed bool
+}
+
+// observe records the margin of a metric value that passed its threshold check
+func (m *analysisMargin) observe(metric flaggerv1.CanaryMetric, val float64) {
+	if m == nil {
+		return
+	}
+	margin := metricMargin(metric, val)
+	if !m.observed || margin < m.value {
+		m.value = margin
+		m.observed = true
+	}
+}
+
+// metricMargin returns the relative distance of the value to the closest threshold,
+// the success rate distance is relative to the error rate allowed by the threshold
+func metricMargin(metric flaggerv1.CanaryMetric, val float64) float64 {
+	margin := math.Inf(1)
+	minMargin := func(min float64) {
+		if metric.Name == "request-success-rate" {
+			if min >= 100 {
+				margin = 0
+				return
+			}
+			margin = math.Min(margin, (val-min)/(100-min))
+		} else if min != 0 {
+			margin = math.Min(margin, (val-min)/math.Abs(min))
+		}
+	}
+	maxMargin := func(max float64) {
+		if max != 0 {
+			margin = math.Min(margin, (max-val)/math.Abs(max))
+		}
+	}
+
+	if tr := metric.ThresholdRange; tr != nil {
+		if tr.Min != nil {
+			minMargin(*tr.Min)
+		}
+		if tr.Max != nil {
+			maxMargin(*tr.Max)
+		}
+	} else if metric.Name == "request-success-rate" {
+		minMargin(metric.Threshold)
+	} else {
+		maxMargin(metric.Threshold)
+	}
+	return margin
+}
+
+// adaptiveStepWeight returns the next traffic weight step based on the metrics margin,
+// zero means the canary weight is held until the metrics move away from their thresholds
+func (c *Controller) adaptiveStepWeight(canary *flaggerv1.Canary, canaryWeight int, maxWeight int, margin *analysisMargin) int {
+	steps := canary.GetAnalysis().AdaptiveSteps
+	remaining := maxWeight - canaryWeight
+	if remaining <= 0 {
+		// any step promotes the canary
+		return 1
+	}
+
+	minStep := steps.MinStepWeight
+	if minStep < 1 {
+		minStep = 1
+	}
+	maxStep := steps.MaxStepWeight
+	if maxStep < minStep {
+		maxStep = minStep
+	}
+	wide := 0.5
+	if steps.WideMargin != nil {
+		wide = *steps.WideMargin
+	}
+	narrow := 0.1
+	if steps.NarrowMargin != nil {
+		narrow = *steps.NarrowMargin
+	}
+
+	step := minStep
+	if margin.observed {
+		switch {
+		case margin.value < narrow:
+			step = 0
+		case margin.value >= wide:
+			step = maxStep
+		default:
+			ratio := (margin.value - narrow) / (wide - narrow)
+			step = minStep + int(math.Round(ratio*float64(maxStep-minStep)))
+		}
+	}
+
+	// raise the step to reach the max weight before the max duration elapses
+	if d, err := time.ParseDuration(steps.MaxDuration); err == nil && d > 0 {
+		left := d - time.Since(analysisStartTime(canary))
+		iterations := int(left / canary.GetAnalysisInterval())
+		if iterations < 1 {
+			iterations = 1
+		}
+		needed := int(math.Ceil(float64(remaining) / float64(iterations)))
+		if needed > step {
+			step = c.min(needed, maxStep)
+		}
+	}
+
+	return c.min(step, remaining)
+}
+
+// analysisStartTime returns the time the canary entered the progressing phase
+func analysisStartTime(canary *flaggerv1.Canary) time.Time {
+	for _, condition := range canary.Status.Conditions {
+		if condition.Type == flaggerv1.PromotedType && condition.Reason == string(flaggerv1.CanaryPhaseProgressing) {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	return canary.Status.LastTransitionTime.Time
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	// lastAnalysisResultAnnotation holds the phase the last canary analysis ended with
+	lastAnalysisResultAnnotation = "flagger.app/last-analysis-result"
+	// lastAnalysisTimeAnnotation holds the time the last canary analysis ended
+	lastAnalysisTimeAnnotation = "flagger.app/last-analysis-time"
+	// lastPromotionTimeAnnotation holds the time of the last successful promotion
+	lastPromotionTimeAnnotation = "flagger.app/last-promotion-time"
+	// failedMetricAnnotation holds the name of the last metric check that halted the analysis
+	failedMetricAnnotation = "flagger.app/failed-metric"
+	// rollbackAnnotation set to true on a canary aborts the analysis in progress
+	rollbackAnnotation = "flagger.app/rollback"
+)
+
+// annotateTargetResult records the analysis result on the target workload
+// so that tools inspecting only the workload can see the rollout state
+func (c *Controller) annotateTargetResult(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	annotations := map[string]string{
+		lastAnalysisResultAnnotation: string(phase),
+		lastAnalysisTimeAnnotation:   now,
+	}
+	var remove []string
+	if phase != flaggerv1.CanaryPhaseFailed {
+		annotations[lastPromotionTimeAnnotation] = now
+		remove = append(remove, failedMetricAnnotation)
+	}
+	c.annotateTarget(canary, annotations, remove)
+}
+
+// annotateTargetFailedMetric records the metric that halted the analysis on the target workload
+func (c *Controller) annotateTargetFailedMetric(canary *flaggerv1.Canary, metric string) {
+	c.annotateTarget(canary, map[string]string{failedMetricAnnotation: metric}, nil)
+}
+
+func (c *Controller) annotateTarget(canary *flaggerv1.Canary, annotations map[string]string, remove []string) {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		switch canary.Spec.TargetRef.Kind {
+		case "Deployment":
+			dep, err := c.kubeClient.AppsV1().Deployments(canary.Namespace).Get(context.TODO(), canary.Spec.TargetRef.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			depCopy := dep.DeepCopy()
+			if !mergeAnnotations(&depCopy.ObjectMeta, annotations, remove) {
+				return nil
+			}
+			_, err = c.kubeClient.AppsV1().Deployments(canary.Namespace).Update(context.TODO(), depCopy, metav1.UpdateOptions{})
+			return err
+		case "DaemonSet":
+			ds, err := c.kubeClient.AppsV1().DaemonSets(canary.Namespace).Get(context.TODO(), canary.Spec.TargetRef.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			dsCopy := ds.DeepCopy()
+			if !mergeAnnotations(&dsCopy.ObjectMeta, annotations, remove) {
+				return nil
+			}
+			_, err = c.kubeClient.AppsV1().DaemonSets(canary.Namespace).Update(context.TODO(), dsCopy, metav1.UpdateOptions{})
+			return err
+		case "StatefulSet":
+			sts, err := c.kubeClient.AppsV1().StatefulSets(canary.Namespace).Get(context.TODO(), canary.Spec.TargetRef.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			stsCopy := sts.DeepCopy()
+			if !mergeAnnotations(&stsCopy.ObjectMeta, annotations, remove) {
+				return nil
+			}
+			_, err = c.kubeClient.AppsV1().StatefulSets(canary.Namespace).Update(context.TODO(), stsCopy, metav1.UpdateOptions{})
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Errorf("Annotating %s %s.%s failed: %v", canary.Spec.TargetRef.Kind, canary.Spec.TargetRef.Name, canary.Namespace, err)
+	}
+}
+
+// mergeAnnotations sets and removes the object annotations,
+// returns false if the annotations were already up to date
+func mergeAnnotations(meta *metav1.ObjectMeta, annotations map[string]string, remove []string) bool {
+	changed := false
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	for k, v := range annotations {
+		if meta.Annotations[k] != v {
+			meta.Annotations[k] = v
+			changed = true
+		}
+	}
+	for _, k := range remove {
+		if _, ok := meta.Annotations[k]; ok {
+			delete(meta.Annotations, k)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// isRollbackRequested returns true if the canary is annotated for rollback
+func isRollbackRequested(canary *flaggerv1.Canary) bool {
+	return canary.Annotations[rollbackAnnotation] == "true"
+}
+
+// isRollbackPhase returns true if the canary is in a phase where the analysis can be rolled back
+func isRollbackPhase(canary *flaggerv1.Canary) bool {
+	switch canary.Status.Phase {
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaiting, flaggerv1.CanaryPhaseWaitingPromotion:
+		return true
+	default:
+		return false
+	}
+}
+
+// clearRollbackRequest removes the rollback annotation from the canary,
+// so that the next revision is analysed
+func (c *Controller) clearRollbackRequest(canary *flaggerv1.Canary) {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(canary.Namespace).Get(context.TODO(), canary.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		cdCopy := cd.DeepCopy()
+		if !mergeAnnotations(&cdCopy.ObjectMeta, nil, []string{rollbackAnnotation}) {
+			return nil
+		}
+		_, err = c.flaggerClient.FlaggerV1beta1().Canaries(canary.Namespace).Update(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Errorf("Removing the %s annotation failed: %v", rollbackAnnotation, err)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+)
+
+// completeTakeover scales down the workload once the apex service routes
+// the live traffic only to primary pods, the scale down is retried on
+// the next interval until the endpoints have converged
+func (c *Controller) completeTakeover(cd *flaggerv1.Canary, canaryController canary.Controller) error {
+	if cd.Spec.TargetRef.Kind == "Service" {
+		return nil
+	}
+
+	apexName, primaryName, _ := cd.GetServiceNames()
+
+	// an ExternalName apex service has no endpoints, it resolves to the primary service
+	if svc, err := c.kubeClient.CoreV1().Services(cd.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{}); err == nil &&
+		svc.Spec.Type == corev1.ServiceTypeExternalName {
+		apexName = primaryName
+	}
+
+	endpoints, err := c.kubeClient.CoreV1().Endpoints(cd.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("takeover of %s.%s waiting for endpoints: %w", apexName, cd.Namespace, err)
+	}
+
+	primaryPrefix := fmt.Sprintf("%s-primary-", cd.Spec.TargetRef.Name)
+	ready := 0
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			if address.TargetRef == nil || !strings.HasPrefix(address.TargetRef.Name, primaryPrefix) {
+				name := address.IP
+				if address.TargetRef != nil {
+					name = address.TargetRef.Name
+				}
+				return fmt.Errorf("takeover of %s.%s waiting for endpoints to switch to primary, %s is still selected",
+					apexName, cd.Namespace, name)
+			}
+			ready++
+		}
+	}
+	if ready == 0 {
+		return fmt.Errorf("takeover of %s.%s waiting for ready primary endpoints", apexName, cd.Namespace)
+	}
+
+	c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("Traffic of %s.%s switched to %v primary endpoints, scaling down %s %s.%s",
+			apexName, cd.Namespace, ready, cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace)
+	return canaryController.ScaleToZero(cd)
+}
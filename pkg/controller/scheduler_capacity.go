@@ -0,0 +1,205 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// checkCapacity verifies that the cluster can schedule the canary pods
+// and returns the reason if the capacity is insufficient,
+// the check applies to Deployment targets only
+func (c *Controller) checkCapacity(canary *flaggerv1.Canary) (string, error) {
+	if canary.Spec.CapacityCheck == nil || canary.Spec.TargetRef.Kind != "Deployment" {
+		return "", nil
+	}
+
+	targetName := canary.Spec.TargetRef.Name
+	dep, err := c.kubeClient.AppsV1().Deployments(canary.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("deployment %s.%s get query error: %w", targetName, canary.Namespace, err)
+	}
+
+	// the canary is scaled up to its desired replicas or to one replica
+	replicas := int64(1)
+	if dep.Spec.Replicas != nil && *dep.Spec.Replicas > 0 {
+		replicas = int64(*dep.Spec.Replicas)
+	}
+
+	// during promotion the new primary pods are rolled out while the canary pods are still running
+	if canary.Spec.CapacityCheck.Promotion {
+		primaryName := fmt.Sprintf("%s-primary", targetName)
+		primary, err := c.kubeClient.AppsV1().Deployments(canary.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("deployment %s.%s get query error: %w", primaryName, canary.Namespace, err)
+		}
+		if primary.Spec.Replicas != nil {
+			replicas += int64(*primary.Spec.Replicas)
+		}
+	}
+
+	requests := podRequests(dep.Spec.Template.Spec)
+	required := corev1.ResourceList{
+		corev1.ResourceCPU:    *resource.NewMilliQuantity(requests.Cpu().MilliValue()*replicas, resource.DecimalSI),
+		corev1.ResourceMemory: *resource.NewQuantity(requests.Memory().Value()*replicas, resource.BinarySI),
+	}
+
+	pods, err := c.kubeClient.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("pods list query error: %w", err)
+	}
+
+	pending := 0
+	for _, pod := range pods.Items {
+		if isUnschedulable(pod) {
+			pending++
+		}
+	}
+	if pending > canary.Spec.CapacityCheck.MaxPendingPods {
+		return fmt.Sprintf("%d pods are unschedulable in the cluster (maxPendingPods %d)",
+			pending, canary.Spec.CapacityCheck.MaxPendingPods), nil
+	}
+
+	nodes, err := c.kubeClient.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("nodes list query error: %w", err)
+	}
+
+	free := corev1.ResourceList{}
+	healthy := make(map[string]bool)
+	for _, node := range nodes.Items {
+		if !isNodeSchedulable(node) {
+			continue
+		}
+		healthy[node.Name] = true
+		addResources(free, node.Status.Allocatable, 1)
+	}
+	if len(healthy) == 0 {
+		return "no schedulable nodes without memory, disk or PID pressure", nil
+	}
+
+	for _, pod := range pods.Items {
+		if !healthy[pod.Spec.NodeName] || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		addResources(free, podRequests(pod.Spec), -1)
+	}
+
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		need, ok := required[name]
+		if !ok || need.IsZero() {
+			continue
+		}
+		if available := free[name]; available.Cmp(need) < 0 {
+			return fmt.Sprintf("insufficient %s on schedulable nodes: %s requested by %d replicas, %s available",
+				name, need.String(), replicas, available.String()), nil
+		}
+	}
+
+	quotas, err := c.kubeClient.CoreV1().ResourceQuotas(canary.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("resource quotas %s list query error: %w", canary.Namespace, err)
+	}
+
+	quotaRequired := corev1.ResourceList{
+		corev1.ResourcePods:           *resource.NewQuantity(replicas, resource.DecimalSI),
+		corev1.ResourceRequestsCPU:    required[corev1.ResourceCPU],
+		corev1.ResourceRequestsMemory: required[corev1.ResourceMemory],
+		corev1.ResourceCPU:            required[corev1.ResourceCPU],
+		corev1.ResourceMemory:         required[corev1.ResourceMemory],
+	}
+	for _, quota := range quotas.Items {
+		for name, hard := range quota.Status.Hard {
+			need, ok := quotaRequired[name]
+			if !ok || need.IsZero() {
+				continue
+			}
+			headroom := hard.DeepCopy()
+			headroom.Sub(quota.Status.Used[name])
+			if headroom.Cmp(need) < 0 {
+				return fmt.Sprintf("resource quota %s.%s exceeded for %s: %s requested by %d replicas, %s available",
+					quota.Name, quota.Namespace, name, need.String(), replicas, headroom.String()), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// podRequests returns the sum of the cpu and memory requests of the pod containers
+func podRequests(spec corev1.PodSpec) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range spec.Containers {
+		addResources(requests, container.Resources.Requests, 1)
+	}
+	return requests
+}
+
+func addResources(list corev1.ResourceList, add corev1.ResourceList, sign int) {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		value, ok := add[name]
+		if !ok {
+			continue
+		}
+		sum := list[name].DeepCopy()
+		if sign < 0 {
+			sum.Sub(value)
+		} else {
+			sum.Add(value)
+		}
+		list[name] = sum
+	}
+}
+
+func isUnschedulable(pod corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodPending {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable {
+			return true
+		}
+	}
+	return false
+}
+
+func isNodeSchedulable(node corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, condition := range node.Status.Conditions {
+		switch condition.Type {
+		case corev1.NodeReady:
+			if condition.Status != corev1.ConditionTrue {
+				return false
+			}
+		case corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure:
+			if condition.Status == corev1.ConditionTrue {
+				return false
+			}
+		}
+	}
+	return true
+}
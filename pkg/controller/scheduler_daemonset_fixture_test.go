@@ -114,6 +114,7 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 		eventRecorder:    &record.FakeRecorder{},
 		logger:           logger,
 		canaries:         new(sync.Map),
+		metricResults:    new(sync.Map),
 		flaggerWindow:    time.Second,
 		canaryFactory:    canaryFactory,
 		observerFactory:  observerFactory,
@@ -122,7 +122,7 @@ func TestScheduler_DaemonSetSkipAnalysis(t *testing.T) {
 	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
 	assert.True(t, c.Spec.SkipAnalysis)
-	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, c.Status.Phase)
+	assert.Equal(t, flaggerv1.CanaryPhasePromotedWithoutAnalysis, c.Status.Phase)
 }
 
 func TestScheduler_DaemonSetNewRevisionReset(t *testing.T) {
@@ -0,0 +1,159 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// stepInput holds the state of the analysis passed to the step deciders
+type stepInput struct {
+	canaryWeight int
+	maxWeight    int
+	margin       *analysisMargin
+}
+
+// stepDecider decides if the canary weight is increased, held or counted as
+// a failed check after an analysis interval passed the metric checks
+type stepDecider interface {
+	Decide(canary *flaggerv1.Canary, input stepInput) (flaggerv1.CanaryDecision, error)
+}
+
+// decideStep runs the step decider of the canary analysis
+func (c *Controller) decideStep(canary *flaggerv1.Canary, canaryWeight int, maxWeight int, margin *analysisMargin) (flaggerv1.CanaryDecision, error) {
+	decider, err := c.getStepDecider(canary)
+	if err != nil {
+		return flaggerv1.CanaryDecision{}, err
+	}
+	return decider.Decide(canary, stepInput{canaryWeight: canaryWeight, maxWeight: maxWeight, margin: margin})
+}
+
+// getStepDecider returns the decider configured in the canary analysis
+func (c *Controller) getStepDecider(canary *flaggerv1.Canary) (stepDecider, error) {
+	analysis := canary.GetAnalysis()
+	deciderType := flaggerv1.ThresholdDecider
+	if analysis.AdaptiveSteps != nil {
+		deciderType = flaggerv1.AdaptiveDecider
+	}
+	decider := analysis.Decider
+	if decider != nil && decider.Type != "" {
+		deciderType = decider.Type
+	}
+
+	switch deciderType {
+	case flaggerv1.ThresholdDecider:
+		return &thresholdDecider{}, nil
+	case flaggerv1.AdaptiveDecider:
+		if analysis.AdaptiveSteps == nil {
+			return nil, fmt.Errorf("adaptive decider requires adaptiveSteps")
+		}
+		return &adaptiveDecider{controller: c}, nil
+	case flaggerv1.StatisticalDecider:
+		d := &statisticalDecider{controller: c, confidence: 95, minSamples: 3}
+		if decider.Confidence > 0 {
+			d.confidence = decider.Confidence
+		}
+		if _, ok := tCriticalValues[d.confidence]; !ok {
+			return nil, fmt.Errorf("statistical decider confidence %v not supported", d.confidence)
+		}
+		if decider.MinSamples > 0 {
+			d.minSamples = decider.MinSamples
+		}
+		return d, nil
+	case flaggerv1.ComparisonDecider:
+		d := &comparisonDecider{controller: c, confidence: 95, minSamples: 5}
+		if decider.Confidence > 0 {
+			d.confidence = decider.Confidence
+		}
+		if _, ok := tCriticalValues[d.confidence]; !ok {
+			return nil, fmt.Errorf("comparison decider confidence %v not supported", d.confidence)
+		}
+		for _, metric := range analysis.Metrics {
+			if metric.TemplateRef == nil {
+				return nil, fmt.Errorf("comparison decider doesn't support the builtin metric %s, use a metric template", metric.Name)
+			}
+		}
+		if decider.MinSamples > 0 {
+			d.minSamples = decider.MinSamples
+		}
+		return d, nil
+	case flaggerv1.GRPCDecider:
+		if decider.Address == "" {
+			return nil, fmt.Errorf("grpc decider requires an address")
+		}
+		d := &grpcDecider{controller: c, address: decider.Address, timeout: 10 * time.Second, tls: decider.TLS}
+		if decider.Timeout != "" {
+			timeout, err := time.ParseDuration(decider.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid grpc decider timeout %s: %w", decider.Timeout, err)
+			}
+			d.timeout = timeout
+		}
+		return d, nil
+	case flaggerv1.JudgeDecider:
+		if decider.URL == "" {
+			return nil, fmt.Errorf("judge decider requires an url")
+		}
+		// the judge must answer before the next analysis interval
+		d := &judgeDecider{controller: c, url: decider.URL, timeout: canary.GetAnalysisInterval(), fallback: flaggerv1.DecisionHold}
+		if decider.Timeout != "" {
+			timeout, err := time.ParseDuration(decider.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid judge decider timeout %s: %w", decider.Timeout, err)
+			}
+			if timeout < d.timeout {
+				d.timeout = timeout
+			}
+		}
+		switch decider.Fallback {
+		case "":
+		case flaggerv1.DecisionAdvance, flaggerv1.DecisionHold, flaggerv1.DecisionFail:
+			d.fallback = decider.Fallback
+		default:
+			return nil, fmt.Errorf("judge decider fallback %s not supported", decider.Fallback)
+		}
+		return d, nil
+	default:
+		return nil, fmt.Errorf("decider type %s not supported", deciderType)
+	}
+}
+
+// thresholdDecider increases the canary weight by the configured step
+type thresholdDecider struct{}
+
+func (d *thresholdDecider) Decide(_ *flaggerv1.Canary, _ stepInput) (flaggerv1.CanaryDecision, error) {
+	return flaggerv1.CanaryDecision{Action: flaggerv1.DecisionAdvance}, nil
+}
+
+// adaptiveDecider sizes the step based on the metrics margin
+type adaptiveDecider struct {
+	controller *Controller
+}
+
+func (d *adaptiveDecider) Decide(canary *flaggerv1.Canary, input stepInput) (flaggerv1.CanaryDecision, error) {
+	step := d.controller.adaptiveStepWeight(canary, input.canaryWeight, input.maxWeight, input.margin)
+	if step == 0 {
+		return flaggerv1.CanaryDecision{
+			Action: flaggerv1.DecisionHold,
+			Reason: fmt.Sprintf("metrics margin %.2f", input.margin.value),
+		}, nil
+	}
+	return flaggerv1.CanaryDecision{Action: flaggerv1.DecisionAdvance, StepWeight: step}, nil
+}
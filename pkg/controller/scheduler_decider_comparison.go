@@ -0,0 +1,215 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/observers"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
+)
+
+// comparisonDecider fails the interval when the datapoints of a canary metric are
+// significantly worse than the primary ones, the canary and primary series are
+// compared with a one-sided Mann-Whitney U test at the confidence level
+type comparisonDecider struct {
+	controller *Controller
+	confidence int
+	minSamples int
+}
+
+func (d *comparisonDecider) Decide(canary *flaggerv1.Canary, input stepInput) (flaggerv1.CanaryDecision, error) {
+	// the canary doesn't receive traffic before the first traffic increase
+	if input.canaryWeight == 0 {
+		return flaggerv1.CanaryDecision{Action: flaggerv1.DecisionAdvance}, nil
+	}
+
+	alpha := 1 - float64(d.confidence)/100
+	for _, metric := range canary.GetAnalysis().Metrics {
+		if !isComparedMetric(canary, metric) {
+			continue
+		}
+		if metric.Interval == "" {
+			metric.Interval = canary.GetMetricInterval()
+		}
+
+		canaryValues, primaryValues, err := d.controller.getComparisonSeries(canary, metric)
+		if err != nil {
+			return flaggerv1.CanaryDecision{}, err
+		}
+		if len(canaryValues) < d.minSamples || len(primaryValues) < d.minSamples {
+			return flaggerv1.CanaryDecision{
+				Action: flaggerv1.DecisionHold,
+				Reason: fmt.Sprintf("collecting %s datapoints canary %v primary %v/%v",
+					metric.Name, len(canaryValues), len(primaryValues), d.minSamples),
+			}, nil
+		}
+
+		// higher values are worse for the metrics with a max threshold
+		_, max := metricBounds(metric)
+		higherIsWorse := max != nil
+		if p := mannWhitneyPValue(canaryValues, primaryValues, higherIsWorse); p < alpha {
+			direction := "lower"
+			if higherIsWorse {
+				direction = "higher"
+			}
+			return flaggerv1.CanaryDecision{
+				Action: flaggerv1.DecisionFail,
+				Reason: fmt.Sprintf("%s canary is %s than primary at %v%% confidence (p-value %.4f)",
+					metric.Name, direction, d.confidence, p),
+			}, nil
+		}
+	}
+	return flaggerv1.CanaryDecision{Action: flaggerv1.DecisionAdvance}, nil
+}
+
+// isComparedMetric returns true if the metric is judged by the comparison decider
+// instead of its thresholds, the thresholds only set the direction of the test
+func isComparedMetric(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric) bool {
+	analysis := canary.GetAnalysis()
+	if analysis.Decider == nil || analysis.Decider.Type != flaggerv1.ComparisonDecider {
+		return false
+	}
+	// the decider runs only for the progressive traffic increase
+	if analysis.Iterations > 0 || analysis.TimeSlices != nil {
+		return false
+	}
+	// only the metric templates support range queries
+	return metric.TemplateRef != nil
+}
+
+// getComparisonSeries runs the metric template range query over the metric interval
+// for the canary and for the primary workload, or the baseline one when enabled
+func (c *Controller) getComparisonSeries(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric) ([]float64, []float64, error) {
+	namespace := canary.Namespace
+	if metric.TemplateRef.Namespace != "" {
+		namespace = metric.TemplateRef.Namespace
+	}
+	template, err := c.flaggerInformers.MetricInformer.Lister().MetricTemplates(namespace).Get(metric.TemplateRef.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("metric template %s.%s error: %v", metric.TemplateRef.Name, namespace, err)
+	}
+	if !c.isReferenceAllowed(canary, namespace, template.Spec.AllowedNamespaces) {
+		return nil, nil, fmt.Errorf("metric template %s.%s can't be referenced from namespace %s",
+			metric.TemplateRef.Name, namespace, canary.Namespace)
+	}
+
+	var credentials map[string][]byte
+	if template.Spec.Provider.SecretRef != nil {
+		secret, err := c.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), template.Spec.Provider.SecretRef.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("metric template %s.%s secret %s error: %v",
+				metric.TemplateRef.Name, namespace, template.Spec.Provider.SecretRef.Name, err)
+		}
+		credentials = secret.Data
+	}
+
+	factory := providers.Factory{}
+	provider, err := factory.Provider(metric.Interval, template.Spec.Provider, credentials)
+	if err != nil {
+		return nil, nil, fmt.Errorf("metric template %s.%s provider %s error: %v",
+			metric.TemplateRef.Name, namespace, template.Spec.Provider.Type, err)
+	}
+	rangeProvider, ok := provider.(providers.RangeInterface)
+	if !ok {
+		return nil, nil, fmt.Errorf("metric template %s.%s provider %s does not support range queries",
+			metric.TemplateRef.Name, namespace, template.Spec.Provider.Type)
+	}
+
+	interval, step, err := samplingRange(metric)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the metric queries and templates target the primary or the baseline workload
+	primary := canary.DeepCopy()
+	primary.Spec.TargetRef.Name = fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
+	if canary.GetAnalysis().Baseline {
+		primary.Spec.TargetRef.Name = fmt.Sprintf("%s-baseline", canary.Spec.TargetRef.Name)
+	}
+
+	var series [2][]float64
+	for i, subject := range []*flaggerv1.Canary{canary, primary} {
+		query, err := observers.RenderQuery(template.Spec.Query, toMetricModel(subject, metric.Interval))
+		if err != nil {
+			return nil, nil, fmt.Errorf("metric template %s.%s query render error: %v",
+				metric.TemplateRef.Name, namespace, err)
+		}
+		series[i], err = rangeProvider.RunRangeQuery(query, interval, step)
+		if err != nil {
+			return nil, nil, fmt.Errorf("metric query failed for %s %s: %v", subject.Spec.TargetRef.Name, metric.Name, err)
+		}
+	}
+	return series[0], series[1], nil
+}
+
+// mannWhitneyPValue returns the one-sided p-value of the Mann-Whitney U test for the x values
+// being greater (or less) than the y values, using the normal approximation with tie correction
+func mannWhitneyPValue(x []float64, y []float64, greater bool) float64 {
+	type datapoint struct {
+		value float64
+		x     bool
+	}
+	all := make([]datapoint, 0, len(x)+len(y))
+	for _, v := range x {
+		all = append(all, datapoint{v, true})
+	}
+	for _, v := range y {
+		all = append(all, datapoint{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+
+	// the tied values get the average of their ranks
+	rankSum, ties := 0.0, 0.0
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].x {
+				rankSum += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	n1, n2, n := float64(len(x)), float64(len(y)), float64(len(all))
+	u := rankSum - n1*(n1+1)/2
+	mean := n1 * n2 / 2
+	sigma := math.Sqrt(n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sigma == 0 {
+		// all the values are equal
+		return 1
+	}
+
+	// continuity correction
+	z := (u - mean - 0.5) / sigma
+	if !greater {
+		z = (mean - u - 0.5) / sigma
+	}
+	return 0.5 * math.Erfc(z/math.Sqrt2)
+}
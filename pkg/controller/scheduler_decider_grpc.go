@@ -0,0 +1,130 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// grpcDecideMethod is the unary method implemented by the external deciders
+const grpcDecideMethod = "/flagger.decider.v1.Decider/Decide"
+
+// grpcDecider delegates the step decision to an external gRPC service,
+// the messages are JSON encoded with the application/grpc+json content type
+type grpcDecider struct {
+	controller *Controller
+	address    string
+	timeout    time.Duration
+	tls        *flaggerv1.CanaryDeciderTLS
+}
+
+func (d *grpcDecider) Decide(canary *flaggerv1.Canary, input stepInput) (flaggerv1.CanaryDecision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	creds, err := d.transportCredentials(ctx, canary)
+	if err != nil {
+		return flaggerv1.CanaryDecision{}, fmt.Errorf("grpc decider %s tls error: %w", d.address, err)
+	}
+	conn, err := grpc.DialContext(ctx, d.address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return flaggerv1.CanaryDecision{}, fmt.Errorf("grpc decider %s dial error: %w", d.address, err)
+	}
+	defer conn.Close()
+
+	req := &flaggerv1.CanaryDecisionRequest{
+		Name:          canary.Name,
+		Namespace:     canary.Namespace,
+		AnalysisRunID: canary.Status.AnalysisRunID,
+		CanaryWeight:  input.canaryWeight,
+		MaxWeight:     input.maxWeight,
+		StepWeight:    canary.GetAnalysis().StepWeight,
+		FailedChecks:  canary.Status.FailedChecks,
+		Metrics:       d.controller.getMetricResults(canary),
+	}
+	var decision flaggerv1.CanaryDecision
+	if err := conn.Invoke(ctx, grpcDecideMethod, req, &decision, grpc.ForceCodec(jsonCodec{})); err != nil {
+		return flaggerv1.CanaryDecision{}, fmt.Errorf("grpc decider %s call error: %w", d.address, err)
+	}
+
+	switch decision.Action {
+	case flaggerv1.DecisionAdvance, flaggerv1.DecisionHold, flaggerv1.DecisionFail:
+		return decision, nil
+	default:
+		return flaggerv1.CanaryDecision{}, fmt.Errorf("grpc decider %s returned unknown action %q", d.address, decision.Action)
+	}
+}
+
+// transportCredentials returns the TLS credentials of the decider connection,
+// the connection is in plaintext when TLS is not configured
+func (d *grpcDecider) transportCredentials(ctx context.Context, canary *flaggerv1.Canary) (credentials.TransportCredentials, error) {
+	if d.tls == nil {
+		return insecure.NewCredentials(), nil
+	}
+
+	config := &tls.Config{
+		ServerName:         d.tls.ServerName,
+		InsecureSkipVerify: d.tls.InsecureSkipVerify,
+	}
+	if d.tls.SecretRef != nil {
+		secret, err := d.controller.kubeClient.CoreV1().Secrets(canary.Namespace).Get(ctx, d.tls.SecretRef.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("secret %s.%s error: %w", d.tls.SecretRef.Name, canary.Namespace, err)
+		}
+		if ca, ok := secret.Data["ca.crt"]; ok {
+			config.RootCAs = x509.NewCertPool()
+			if !config.RootCAs.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("secret %s.%s ca.crt is not a valid PEM certificate", d.tls.SecretRef.Name, canary.Namespace)
+			}
+		}
+		if _, ok := secret.Data["tls.crt"]; ok {
+			cert, err := tls.X509KeyPair(secret.Data["tls.crt"], secret.Data["tls.key"])
+			if err != nil {
+				return nil, fmt.Errorf("secret %s.%s client certificate error: %w", d.tls.SecretRef.Name, canary.Namespace, err)
+			}
+			config.Certificates = []tls.Certificate{cert}
+		}
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// jsonCodec marshals the gRPC messages as JSON
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
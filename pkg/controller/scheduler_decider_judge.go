@@ -0,0 +1,157 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// judgeDecider posts the metric samples of the analysis run to an external
+// judge and records its verdict and explanation in the canary status,
+// the fallback action is applied when the judge fails to answer in time
+type judgeDecider struct {
+	controller *Controller
+	url        string
+	timeout    time.Duration
+	fallback   flaggerv1.DecisionAction
+}
+
+func (d *judgeDecider) Decide(canary *flaggerv1.Canary, input stepInput) (flaggerv1.CanaryDecision, error) {
+	judgement, err := d.judge(canary, input)
+	state := &flaggerv1.CanaryJudgementStatus{
+		Time:        metav1.Now(),
+		Verdict:     judgement.Verdict,
+		Explanation: judgement.Explanation,
+	}
+	if err != nil {
+		state.Verdict = d.fallback
+		state.Explanation = fmt.Sprintf("fallback to %s, %v", d.fallback, err)
+		state.Fallback = true
+		judgement = flaggerv1.CanaryJudgement{Verdict: d.fallback}
+	}
+
+	last := canary.Status.LastJudgement
+	if err := d.controller.setStatusJudgement(canary, state); err != nil {
+		d.controller.recordEventWarningf(canary, "%v", err)
+	}
+	if last == nil || last.Verdict != state.Verdict || state.Fallback {
+		severity := flaggerv1.SeverityInfo
+		if state.Fallback || state.Verdict == flaggerv1.DecisionFail {
+			severity = flaggerv1.SeverityWarn
+		}
+		d.controller.alert(canary, fmt.Sprintf("Judge verdict %s: %s", state.Verdict, state.Explanation), true, severity)
+	}
+
+	return flaggerv1.CanaryDecision{
+		Action:     state.Verdict,
+		StepWeight: judgement.StepWeight,
+		Reason:     state.Explanation,
+	}, nil
+}
+
+// judge calls the external judge with the analysis state and the collected samples
+func (d *judgeDecider) judge(canary *flaggerv1.Canary, input stepInput) (flaggerv1.CanaryJudgement, error) {
+	payload := flaggerv1.CanaryJudgementRequest{
+		CanaryDecisionRequest: flaggerv1.CanaryDecisionRequest{
+			Name:          canary.Name,
+			Namespace:     canary.Namespace,
+			AnalysisRunID: canary.Status.AnalysisRunID,
+			CanaryWeight:  input.canaryWeight,
+			MaxWeight:     input.maxWeight,
+			StepWeight:    canary.GetAnalysis().StepWeight,
+			FailedChecks:  canary.Status.FailedChecks,
+			Metrics:       d.controller.getMetricResults(canary),
+		},
+		Samples: d.controller.getMetricSamples(canary),
+	}
+	payloadBin, err := json.Marshal(payload)
+	if err != nil {
+		return flaggerv1.CanaryJudgement{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", d.url, bytes.NewBuffer(payloadBin))
+	if err != nil {
+		return flaggerv1.CanaryJudgement{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: d.timeout}
+	r, err := client.Do(req)
+	if err != nil {
+		return flaggerv1.CanaryJudgement{}, fmt.Errorf("judge %s call error: %w", d.url, err)
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return flaggerv1.CanaryJudgement{}, fmt.Errorf("judge %s error reading body: %w", d.url, err)
+	}
+	if r.StatusCode > 202 {
+		return flaggerv1.CanaryJudgement{}, fmt.Errorf("judge %s returned status %v", d.url, r.StatusCode)
+	}
+
+	var judgement flaggerv1.CanaryJudgement
+	if err := json.Unmarshal(b, &judgement); err != nil {
+		return flaggerv1.CanaryJudgement{}, fmt.Errorf("judge %s response decoding error: %w", d.url, err)
+	}
+	switch judgement.Verdict {
+	case flaggerv1.DecisionAdvance, flaggerv1.DecisionHold, flaggerv1.DecisionFail:
+		return judgement, nil
+	default:
+		return flaggerv1.CanaryJudgement{}, fmt.Errorf("judge %s returned unknown verdict %q", d.url, judgement.Verdict)
+	}
+}
+
+// setStatusJudgement records the last verdict of the judge decider
+func (c *Controller) setStatusJudgement(cd *flaggerv1.Canary, state *flaggerv1.CanaryJudgementStatus) error {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		selected := cd
+		if !firstTry {
+			selected, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := selected.DeepCopy()
+		cdCopy.Status.LastJudgement = state
+		_, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).UpdateStatus(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		firstTry = false
+		return
+	})
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	cd.Status.LastJudgement = state
+	return nil
+}
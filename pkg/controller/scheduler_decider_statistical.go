@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"math"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// tCriticalValues holds the one-sided Student's t critical values
+// for 1 to 30 degrees of freedom followed by the normal approximation
+var tCriticalValues = map[int][]float64{
+	90: {3.078, 1.886, 1.638, 1.533, 1.476, 1.440, 1.415, 1.397, 1.383, 1.372,
+		1.363, 1.356, 1.350, 1.345, 1.341, 1.337, 1.333, 1.330, 1.328, 1.325,
+		1.323, 1.321, 1.319, 1.318, 1.316, 1.315, 1.314, 1.313, 1.311, 1.310, 1.282},
+	95: {6.314, 2.920, 2.353, 2.132, 2.015, 1.943, 1.895, 1.860, 1.833, 1.812,
+		1.796, 1.782, 1.771, 1.761, 1.753, 1.746, 1.740, 1.734, 1.729, 1.725,
+		1.721, 1.717, 1.714, 1.711, 1.708, 1.706, 1.703, 1.701, 1.699, 1.697, 1.645},
+	99: {31.821, 6.965, 4.541, 3.747, 3.365, 3.143, 2.998, 2.896, 2.821, 2.764,
+		2.718, 2.681, 2.650, 2.624, 2.602, 2.583, 2.567, 2.552, 2.539, 2.528,
+		2.518, 2.508, 2.500, 2.492, 2.485, 2.479, 2.473, 2.467, 2.462, 2.457, 2.326},
+}
+
+// statisticalDecider holds the canary weight until the mean of the metric values
+// recorded during the analysis run is within the thresholds at the confidence level
+type statisticalDecider struct {
+	controller *Controller
+	confidence int
+	minSamples int
+}
+
+func (d *statisticalDecider) Decide(canary *flaggerv1.Canary, input stepInput) (flaggerv1.CanaryDecision, error) {
+	// the metrics are not checked before the first traffic increase
+	if input.canaryWeight == 0 {
+		return flaggerv1.CanaryDecision{Action: flaggerv1.DecisionAdvance}, nil
+	}
+
+	samples := d.controller.getMetricSamples(canary)
+	for _, metric := range canary.GetAnalysis().Metrics {
+		if metric.Sampling != nil && metric.Sampling.Aggregator == samplingCountAboveThreshold {
+			continue
+		}
+		values, ok := samples[metric.Name]
+		if !ok {
+			continue
+		}
+		if len(values) < d.minSamples {
+			return flaggerv1.CanaryDecision{
+				Action: flaggerv1.DecisionHold,
+				Reason: fmt.Sprintf("collecting %s samples %v/%v", metric.Name, len(values), d.minSamples),
+			}, nil
+		}
+
+		mean, bound := confidenceBound(values, d.confidence)
+		min, max := metricBounds(metric)
+		if (min != nil && mean-bound < *min) || (max != nil && mean+bound > *max) {
+			return flaggerv1.CanaryDecision{
+				Action: flaggerv1.DecisionHold,
+				Reason: fmt.Sprintf("%s mean %.2f ± %.2f not within threshold at %v%% confidence",
+					metric.Name, mean, bound, d.confidence),
+			}, nil
+		}
+	}
+	return flaggerv1.CanaryDecision{Action: flaggerv1.DecisionAdvance}, nil
+}
+
+// confidenceBound returns the mean of the values and the
+// one-sided confidence interval width of the mean
+func confidenceBound(values []float64, confidence int) (float64, float64) {
+	n := float64(len(values))
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / n
+	if len(values) < 2 {
+		return mean, math.Inf(1)
+	}
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	stderr := math.Sqrt(variance/(n-1)) / math.Sqrt(n)
+
+	table := tCriticalValues[confidence]
+	df := len(values) - 1
+	if df > len(table)-1 {
+		df = len(table)
+	}
+	return mean, table[df-1] * stderr
+}
+
+// metricBounds returns the min and max thresholds of a metric
+func metricBounds(metric flaggerv1.CanaryMetric) (*float64, *float64) {
+	if tr := metric.ThresholdRange; tr != nil {
+		return tr.Min, tr.Max
+	}
+	threshold := metric.Threshold
+	if metric.Name == "request-success-rate" {
+		return &threshold, nil
+	}
+	return nil, &threshold
+}
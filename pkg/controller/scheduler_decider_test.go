@@ -0,0 +1,391 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newDeciderTestCanary(decider *flaggerv1.CanaryDecider) *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{
+				StepWeight: 10,
+				Decider:    decider,
+				Metrics:    []flaggerv1.CanaryMetric{{Name: "request-success-rate", Threshold: 99}},
+			},
+		},
+		Status: flaggerv1.CanaryStatus{AnalysisRunID: "run-1"},
+	}
+}
+
+func TestController_getStepDecider(t *testing.T) {
+	ctrl := &Controller{metricResults: new(sync.Map)}
+
+	d, err := ctrl.getStepDecider(newDeciderTestCanary(nil))
+	require.NoError(t, err)
+	assert.IsType(t, &thresholdDecider{}, d)
+
+	cd := newDeciderTestCanary(nil)
+	cd.Spec.Analysis.AdaptiveSteps = &flaggerv1.CanaryAdaptiveSteps{MaxStepWeight: 20}
+	d, err = ctrl.getStepDecider(cd)
+	require.NoError(t, err)
+	assert.IsType(t, &adaptiveDecider{}, d)
+
+	_, err = ctrl.getStepDecider(newDeciderTestCanary(&flaggerv1.CanaryDecider{Type: flaggerv1.AdaptiveDecider}))
+	assert.Error(t, err)
+	_, err = ctrl.getStepDecider(newDeciderTestCanary(&flaggerv1.CanaryDecider{Type: flaggerv1.StatisticalDecider, Confidence: 80}))
+	assert.Error(t, err)
+	_, err = ctrl.getStepDecider(newDeciderTestCanary(&flaggerv1.CanaryDecider{Type: flaggerv1.ComparisonDecider, Confidence: 80}))
+	assert.Error(t, err)
+	_, err = ctrl.getStepDecider(newDeciderTestCanary(&flaggerv1.CanaryDecider{Type: flaggerv1.ComparisonDecider}))
+	assert.Error(t, err, "builtin metrics can't be compared")
+	_, err = ctrl.getStepDecider(newDeciderTestCanary(&flaggerv1.CanaryDecider{Type: flaggerv1.GRPCDecider}))
+	assert.Error(t, err)
+	_, err = ctrl.getStepDecider(newDeciderTestCanary(&flaggerv1.CanaryDecider{Type: "bayesian"}))
+	assert.Error(t, err)
+}
+
+func TestStatisticalDecider(t *testing.T) {
+	ctrl := &Controller{metricResults: new(sync.Map)}
+	cd := newDeciderTestCanary(&flaggerv1.CanaryDecider{Type: flaggerv1.StatisticalDecider})
+	d, err := ctrl.getStepDecider(cd)
+	require.NoError(t, err)
+
+	// no traffic routed to the canary yet
+	decision, err := d.Decide(cd, stepInput{canaryWeight: 0, maxWeight: 50})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.DecisionAdvance, decision.Action)
+
+	// not enough samples
+	ctrl.recordMetricResult(cd, "request-success-rate", 99.9)
+	ctrl.recordMetricResult(cd, "request-success-rate", 99.8)
+	decision, err = d.Decide(cd, stepInput{canaryWeight: 10, maxWeight: 50})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.DecisionHold, decision.Action)
+
+	// mean within threshold with a narrow spread
+	ctrl.recordMetricResult(cd, "request-success-rate", 99.9)
+	decision, err = d.Decide(cd, stepInput{canaryWeight: 10, maxWeight: 50})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.DecisionAdvance, decision.Action)
+
+	// mean within threshold with a wide spread
+	ctrl.recordMetricResult(cd, "request-success-rate", 98.5)
+	decision, err = d.Decide(cd, stepInput{canaryWeight: 10, maxWeight: 50})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.DecisionHold, decision.Action)
+
+	// samples are reset for a new analysis run
+	cd.Status.AnalysisRunID = "run-2"
+	ctrl.recordMetricResult(cd, "request-success-rate", 99.9)
+	assert.Len(t, ctrl.getMetricSamples(cd)["request-success-rate"], 1)
+}
+
+func TestComparisonDecider(t *testing.T) {
+	canarySeries := `"100","101","102","103","104","105"`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		series := canarySeries
+		if strings.Contains(r.URL.Query().Get("query"), "podinfo-primary") {
+			series = `"100","101","102","103","104","105"`
+		}
+		var values []string
+		for i, v := range strings.Split(series, ",") {
+			values = append(values, fmt.Sprintf("[%d,%s]", 1545905245+i*10, v))
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[` +
+			strings.Join(values, ",") + `]}]}}`))
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	template := newDeploymentTestMetricTemplate()
+	template.Name = "latency"
+	template.Spec.Provider = flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}
+	template.Spec.Query = `histogram_quantile(0.99, rate(latency_bucket{pod=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)"}[{{ interval }}]))`
+	require.NoError(t, mocks.ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Decider = &flaggerv1.CanaryDecider{Type: flaggerv1.ComparisonDecider}
+	cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+		Name:           "latency",
+		TemplateRef:    &flaggerv1.CrossNamespaceObjectReference{Name: "latency"},
+		ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: toFloatPtr(1000)},
+		Interval:       "1m",
+	}}
+	d, err := mocks.ctrl.getStepDecider(cd)
+	require.NoError(t, err)
+
+	// same distribution
+	decision, err := d.Decide(cd, stepInput{canaryWeight: 10, maxWeight: 50})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.DecisionAdvance, decision.Action)
+
+	// canary latency lower than primary
+	canarySeries = `"90","91","92","93","94","95"`
+	decision, err = d.Decide(cd, stepInput{canaryWeight: 10, maxWeight: 50})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.DecisionAdvance, decision.Action)
+
+	// canary latency higher than primary
+	canarySeries = `"150","151","152","153","154","155"`
+	decision, err = d.Decide(cd, stepInput{canaryWeight: 10, maxWeight: 50})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.DecisionFail, decision.Action)
+	assert.Contains(t, decision.Reason, "latency canary is higher than primary")
+
+	// not enough datapoints
+	canarySeries = `"150","151"`
+	decision, err = d.Decide(cd, stepInput{canaryWeight: 10, maxWeight: 50})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.DecisionHold, decision.Action)
+}
+
+func TestComparisonDecider_Thresholds(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"5000"]}]}}`))
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	template := newDeploymentTestMetricTemplate()
+	template.Name = "latency"
+	template.Spec.Provider = flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}
+	require.NoError(t, mocks.ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Decider = &flaggerv1.CanaryDecider{Type: flaggerv1.ComparisonDecider}
+	cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+		Name:           "latency",
+		TemplateRef:    &flaggerv1.CrossNamespaceObjectReference{Name: "latency"},
+		ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: toFloatPtr(1000)},
+		Interval:       "1m",
+	}}
+
+	// the compared metrics skip their thresholds
+	assert.True(t, mocks.ctrl.runMetricChecks(cd, &analysisMargin{}, newAnalysisScore(cd)))
+
+	// the thresholds apply when the decider doesn't run
+	cd.Spec.Analysis.Iterations = 10
+	assert.False(t, mocks.ctrl.runMetricChecks(cd, &analysisMargin{}, newAnalysisScore(cd)))
+
+	cd.Spec.Analysis.Iterations = 0
+	cd.Spec.Analysis.Decider = nil
+	assert.False(t, mocks.ctrl.runMetricChecks(cd, &analysisMargin{}, newAnalysisScore(cd)))
+}
+
+func TestMannWhitneyPValue(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5, 6, 7, 8}
+	assert.Equal(t, 1.0, mannWhitneyPValue([]float64{1, 1, 1}, []float64{1, 1, 1}, true))
+	assert.Greater(t, mannWhitneyPValue(x, x, true), 0.4)
+
+	shifted := []float64{11, 12, 13, 14, 15, 16, 17, 18}
+	assert.Less(t, mannWhitneyPValue(shifted, x, true), 0.001)
+	assert.Greater(t, mannWhitneyPValue(shifted, x, false), 0.99)
+	assert.Less(t, mannWhitneyPValue(x, shifted, false), 0.001)
+}
+
+func TestGRPCDecider(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var received flaggerv1.CanaryDecisionRequest
+	server := grpc.NewServer(grpc.ForceServerCodec(jsonCodec{}),
+		grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+			method, _ := grpc.MethodFromServerStream(stream)
+			assert.Equal(t, grpcDecideMethod, method)
+			if err := stream.RecvMsg(&received); err != nil {
+				return err
+			}
+			return stream.SendMsg(&flaggerv1.CanaryDecision{
+				Action:     flaggerv1.DecisionAdvance,
+				StepWeight: 25,
+			})
+		}))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	ctrl := &Controller{metricResults: new(sync.Map)}
+	cd := newDeciderTestCanary(&flaggerv1.CanaryDecider{Type: flaggerv1.GRPCDecider, Address: lis.Addr().String()})
+	ctrl.recordMetricResult(cd, "request-success-rate", 99.5)
+
+	decision, err := ctrl.decideStep(cd, 10, 50, &analysisMargin{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.DecisionAdvance, decision.Action)
+	assert.Equal(t, 25, decision.StepWeight)
+	assert.Equal(t, "podinfo", received.Name)
+	assert.Equal(t, 10, received.CanaryWeight)
+	assert.Equal(t, []flaggerv1.CanaryMetricResult{{Name: "request-success-rate", Value: 99.5}}, received.Metrics)
+}
+
+func TestGRPCDecider_TLS(t *testing.T) {
+	// reuse the httptest certificate issued for 127.0.0.1
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	ts.Close()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(grpc.ForceServerCodec(jsonCodec{}),
+		grpc.Creds(credentials.NewServerTLSFromCert(&ts.TLS.Certificates[0])),
+		grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+			var received flaggerv1.CanaryDecisionRequest
+			if err := stream.RecvMsg(&received); err != nil {
+				return err
+			}
+			return stream.SendMsg(&flaggerv1.CanaryDecision{Action: flaggerv1.DecisionHold})
+		}))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	ctrl := &Controller{
+		metricResults: new(sync.Map),
+		kubeClient: fake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "decider-tls", Namespace: "default"},
+			Data:       map[string][]byte{"ca.crt": ca},
+		}),
+	}
+	cd := newDeciderTestCanary(&flaggerv1.CanaryDecider{
+		Type:    flaggerv1.GRPCDecider,
+		Address: lis.Addr().String(),
+		Timeout: "2s",
+		TLS: &flaggerv1.CanaryDeciderTLS{
+			SecretRef: &corev1.LocalObjectReference{Name: "decider-tls"},
+		},
+	})
+	cd.Namespace = "default"
+
+	decision, err := ctrl.decideStep(cd, 10, 50, &analysisMargin{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.DecisionHold, decision.Action)
+
+	// the plaintext connection is refused by the server
+	cd.Spec.Analysis.Decider.TLS = nil
+	_, err = ctrl.decideStep(cd, 10, 50, &analysisMargin{})
+	assert.Error(t, err)
+
+	cd.Spec.Analysis.Decider.TLS = &flaggerv1.CanaryDeciderTLS{SecretRef: &corev1.LocalObjectReference{Name: "missing"}}
+	_, err = ctrl.decideStep(cd, 10, 50, &analysisMargin{})
+	assert.Error(t, err)
+}
+
+func TestJudgeDecider(t *testing.T) {
+	var received flaggerv1.CanaryJudgementRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		json.NewEncoder(w).Encode(flaggerv1.CanaryJudgement{
+			Verdict:     flaggerv1.DecisionHold,
+			Explanation: "p99 latency trending up",
+		})
+	}))
+	defer ts.Close()
+
+	cd := newDeploymentTestCanary()
+	cd.Status.AnalysisRunID = "run-1"
+	cd.Spec.Analysis.Decider = &flaggerv1.CanaryDecider{Type: flaggerv1.JudgeDecider, URL: ts.URL}
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.recordMetricResult(cd, "request-success-rate", 99.5)
+	mocks.ctrl.recordMetricResult(cd, "request-success-rate", 99.7)
+
+	decision, err := mocks.ctrl.decideStep(cd, 10, 50, &analysisMargin{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.DecisionHold, decision.Action)
+	assert.Equal(t, "p99 latency trending up", decision.Reason)
+	assert.Equal(t, 10, received.CanaryWeight)
+	assert.Equal(t, []float64{99.5, 99.7}, received.Samples["request-success-rate"])
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, c.Status.LastJudgement)
+	assert.Equal(t, flaggerv1.DecisionHold, c.Status.LastJudgement.Verdict)
+	assert.Equal(t, "p99 latency trending up", c.Status.LastJudgement.Explanation)
+	assert.False(t, c.Status.LastJudgement.Fallback)
+}
+
+func TestJudgeDecider_Timeout(t *testing.T) {
+	ctrl := &Controller{metricResults: new(sync.Map)}
+	cd := newDeciderTestCanary(&flaggerv1.CanaryDecider{Type: flaggerv1.JudgeDecider, URL: "http://judge.flagger"})
+	cd.Spec.Analysis.Interval = "30s"
+
+	// defaults to the analysis interval
+	d, err := ctrl.getStepDecider(cd)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, d.(*judgeDecider).timeout)
+
+	cd.Spec.Analysis.Decider.Timeout = "10s"
+	d, err = ctrl.getStepDecider(cd)
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, d.(*judgeDecider).timeout)
+
+	// capped to the analysis interval
+	cd.Spec.Analysis.Decider.Timeout = "5m"
+	d, err = ctrl.getStepDecider(cd)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, d.(*judgeDecider).timeout)
+}
+
+func TestJudgeDecider_Fallback(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		json.NewEncoder(w).Encode(flaggerv1.CanaryJudgement{Verdict: flaggerv1.DecisionAdvance})
+	}))
+	defer ts.Close()
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Decider = &flaggerv1.CanaryDecider{
+		Type:     flaggerv1.JudgeDecider,
+		URL:      ts.URL,
+		Timeout:  "50ms",
+		Fallback: flaggerv1.DecisionFail,
+	}
+	mocks := newDeploymentFixture(cd)
+
+	decision, err := mocks.ctrl.decideStep(cd, 10, 50, &analysisMargin{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.DecisionFail, decision.Action)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, c.Status.LastJudgement)
+	assert.True(t, c.Status.LastJudgement.Fallback)
+	assert.Equal(t, flaggerv1.DecisionFail, c.Status.LastJudgement.Verdict)
+
+	cd.Spec.Analysis.Decider.Fallback = "skip"
+	_, err = mocks.ctrl.getStepDecider(cd)
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const defaultDependencyTimeout = 5 * time.Second
+
+// checkDependencies returns an error for the first unhealthy upstream dependency,
+// a failing dependency holds the canary instead of counting as a failed check
+func (c *Controller) checkDependencies(canary *flaggerv1.Canary) error {
+	for _, dep := range canary.GetAnalysis().Dependencies {
+		namespace := dep.Namespace
+		if namespace == "" {
+			namespace = canary.Namespace
+		}
+
+		var err error
+		switch dep.Kind {
+		case "Service":
+			err = c.checkServiceDependency(dep.Name, namespace)
+		case "Canary":
+			err = c.checkCanaryDependency(dep.Name, namespace)
+		case "URL":
+			err = checkURLDependency(dep)
+		default:
+			err = fmt.Errorf("kind %s not supported", dep.Kind)
+		}
+		if err != nil {
+			return fmt.Errorf("dependency %s %s unhealthy: %w", dep.Kind, dependencyName(dep, namespace), err)
+		}
+	}
+	return nil
+}
+
+// checkServiceDependency requires at least one ready endpoint behind the service
+func (c *Controller) checkServiceDependency(name string, namespace string) error {
+	endpoints, err := c.kubeClient.CoreV1().Endpoints(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("no ready endpoints")
+}
+
+// checkCanaryDependency fails if the upstream canary was rolled back
+// or if its analysis is currently failing checks
+func (c *Controller) checkCanaryDependency(name string, namespace string) error {
+	cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	switch {
+	case cd.Status.Phase == flaggerv1.CanaryPhaseFailed:
+		return fmt.Errorf("canary failed")
+	case cd.Status.Phase == flaggerv1.CanaryPhaseProgressing && cd.Status.FailedChecks > 0:
+		return fmt.Errorf("canary analysis has %d failed checks", cd.Status.FailedChecks)
+	}
+	return nil
+}
+
+func checkURLDependency(dep flaggerv1.CanaryDependency) error {
+	timeout := defaultDependencyTimeout
+	if dep.Timeout != "" {
+		if d, err := time.ParseDuration(dep.Timeout); err == nil {
+			timeout = d
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, dep.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func dependencyName(dep flaggerv1.CanaryDependency, namespace string) string {
+	if dep.Kind == "URL" {
+		return dep.URL
+	}
+	return fmt.Sprintf("%s.%s", dep.Name, namespace)
+}
@@ -24,14 +24,20 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/gate"
 	"github.com/fluxcd/flagger/pkg/notifier"
+	"github.com/fluxcd/flagger/pkg/quota"
 )
 
 func TestScheduler_DeploymentInit(t *testing.T) {
@@ -65,6 +71,36 @@ func TestScheduler_DeploymentNewRevision(t *testing.T) {
 	c, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
 	assert.Equal(t, int32(1), *c.Spec.Replicas)
+
+	// the analysis run is tagged with a new ID
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, cd.Status.AnalysisRunID)
+}
+
+func TestScheduler_DeploymentStatusRoutes(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, cd.Status.Routes)
+	assert.Equal(t, 100, cd.Status.Routes.PrimaryWeight)
+	assert.Equal(t, 0, cd.Status.Routes.CanaryWeight)
+
+	// the split read back from the router is recorded when it changes
+	err = mocks.router.SetRoutes(cd, 60, 40, false)
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 60, cd.Status.Routes.PrimaryWeight)
+	assert.Equal(t, 40, cd.Status.Routes.CanaryWeight)
 }
 
 func TestScheduler_DeploymentRollback(t *testing.T) {
@@ -101,18 +137,1037 @@ func TestScheduler_DeploymentRollback(t *testing.T) {
 	// run metric checks
 	mocks.ctrl.advanceCanary("podinfo", "default")
 
-	// finalise analysis
+	// finalise analysis
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// check status
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+}
+
+func TestScheduler_DeploymentTargetAnnotations(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// set a metric check to fail
+	err := mocks.deployer.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing})
+	require.NoError(t, err)
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd := c.DeepCopy()
+	cd.Spec.Analysis.Metrics = append(c.Spec.Analysis.Metrics, flaggerv1.CanaryMetric{
+		Name:     "fail",
+		Interval: "1m",
+		ThresholdRange: &flaggerv1.CanaryThresholdRange{
+			Min: toFloatPtr(0),
+			Max: toFloatPtr(50),
+		},
+		Query: "fail",
+	})
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// run metric checks
+	mocks.makeCanaryReady(t)
+	err = mocks.router.SetRoutes(mocks.canary, 90, 10, false)
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "fail", dep.Annotations[failedMetricAnnotation])
+
+	// rollback
+	err = mocks.deployer.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing, FailedChecks: 10})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFailed))
+
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Failed", dep.Annotations[lastAnalysisResultAnnotation])
+	assert.Equal(t, "fail", dep.Annotations[failedMetricAnnotation])
+	assert.Empty(t, dep.Annotations[lastPromotionTimeAnnotation])
+}
+
+func TestScheduler_DeploymentCanaryDNS(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd := c.DeepCopy()
+	cd.Spec.DNS = &flaggerv1.CanaryDNS{
+		Hostname: "canary.example.com",
+		Targets:  []string{"10.0.0.1"},
+		TTL:      60,
+	}
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// not published before the analysis starts
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	_, err = mocks.flaggerClient.ExternaldnsV1alpha1().DNSEndpoints("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.True(t, errors.IsNotFound(err))
+
+	// published during the analysis
+	err = mocks.deployer.SyncStatus(cd, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	endpoint, err := mocks.flaggerClient.ExternaldnsV1alpha1().DNSEndpoints("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, endpoint.Spec.Endpoints, 1)
+	assert.Equal(t, "canary.example.com", endpoint.Spec.Endpoints[0].DNSName)
+	assert.Equal(t, "A", endpoint.Spec.Endpoints[0].RecordType)
+	assert.Equal(t, int64(60), endpoint.Spec.Endpoints[0].RecordTTL)
+
+	// removed on rollback
+	mocks.makeCanaryReady(t)
+	err = mocks.deployer.SyncStatus(cd, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing, FailedChecks: 10})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFailed))
+
+	_, err = mocks.flaggerClient.ExternaldnsV1alpha1().DNSEndpoints("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.True(t, errors.IsNotFound(err))
+}
+
+func TestScheduler_DeploymentCanaryPreview(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	stubs := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo-stubs", Namespace: "default"},
+		Data: map[string]string{
+			"backend.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: backend-stub
+spec:
+  ports:
+    - port: 9898
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: backend-stub-responses
+data:
+  status: ok
+`,
+		},
+	}
+	_, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Create(context.TODO(), stubs, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd := c.DeepCopy()
+	cd.Spec.Preview = &flaggerv1.CanaryPreview{
+		Host:             "preview.example.com",
+		IngressClassName: "nginx",
+		StubsRef:         &flaggerv1.CrossNamespaceObjectReference{Name: "podinfo-stubs"},
+	}
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// not created before the analysis starts
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	_, err = mocks.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), "podinfo-preview", metav1.GetOptions{})
+	require.True(t, errors.IsNotFound(err))
+
+	// created during the analysis
+	err = mocks.deployer.SyncStatus(cd, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	ingress, err := mocks.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), "podinfo-preview", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "nginx", *ingress.Spec.IngressClassName)
+	assert.Equal(t, "preview.example.com", ingress.Spec.Rules[0].Host)
+	assert.Equal(t, "podinfo-canary", ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name)
+
+	svc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "backend-stub", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", svc.Labels["flagger.app/preview"])
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "backend-stub-responses", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// removed on rollback
+	mocks.makeCanaryReady(t)
+	err = mocks.deployer.SyncStatus(cd, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing, FailedChecks: 10})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFailed))
+
+	_, err = mocks.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), "podinfo-preview", metav1.GetOptions{})
+	require.True(t, errors.IsNotFound(err))
+	_, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "backend-stub", metav1.GetOptions{})
+	require.True(t, errors.IsNotFound(err))
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-stubs", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
+func TestScheduler_DeploymentDependencies(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd := c.DeepCopy()
+	cd.Spec.Analysis.Dependencies = []flaggerv1.CanaryDependency{
+		{Kind: "Service", Name: "backend"},
+	}
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	err = mocks.deployer.SyncStatus(cd, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing})
+	require.NoError(t, err)
+
+	mocks.makeCanaryReady(t)
+	err = mocks.router.SetRoutes(mocks.canary, 90, 10, false)
+	require.NoError(t, err)
+
+	// hold while the dependency has no endpoints
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	_, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 10, canaryWeight)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, c.Status.FailedChecks)
+
+	// advance once the dependency is healthy
+	_, err = mocks.kubeClient.CoreV1().Endpoints("default").Create(context.TODO(), &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "default"},
+		Subsets: []corev1.EndpointSubset{
+			{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	_, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Greater(t, canaryWeight, 10)
+}
+
+func TestScheduler_DeploymentProviderOutage(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd := c.DeepCopy()
+	cd.Spec.MetricsServer = "http://non-exist"
+	cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{Name: "request-success-rate", Threshold: 99, Interval: "1m"}}
+	cd.Spec.Analysis.ProviderOutage = &flaggerv1.CanaryProviderOutage{Intervals: 2}
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	err = mocks.deployer.SyncStatus(cd, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing})
+	require.NoError(t, err)
+
+	mocks.makeCanaryReady(t)
+	err = mocks.router.SetRoutes(mocks.canary, 90, 10, false)
+	require.NoError(t, err)
+
+	// the first outage interval fails the analysis
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.Status.ProviderOutages)
+	assert.Equal(t, 1, c.Status.FailedChecks)
+
+	// hold without failing the analysis once the outage reached the intervals
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.Status.ProviderOutages)
+	assert.Equal(t, 1, c.Status.FailedChecks)
+
+	_, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 10, canaryWeight)
+
+	// advance once the metrics provider is reachable
+	cd = c.DeepCopy()
+	cd.Spec.MetricsServer = testMetricsServerURL
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, c.Status.ProviderOutages)
+
+	_, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Greater(t, canaryWeight, 10)
+}
+
+func TestScheduler_DeploymentErrorBudget(t *testing.T) {
+	approved := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !approved {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer ts.Close()
+
+	cd := newDeploymentTestCanary()
+	// the test metrics server returns 100
+	cd.Spec.Analysis.ErrorBudget = &flaggerv1.CanaryErrorBudget{
+		TemplateRef:  flaggerv1.CrossNamespaceObjectReference{Name: "envoy"},
+		MinRemaining: toFloatPtr(100),
+	}
+	cd.Spec.Analysis.Webhooks = []flaggerv1.CanaryWebhook{
+		{
+			Name: "approve",
+			Type: flaggerv1.ConfirmErrorBudgetHook,
+			URL:  ts.URL,
+		},
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// error budget exhausted
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+
+	// manual approval
+	approved = true
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+}
+
+func TestScheduler_DeploymentRings(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// the first ring canary is running an analysis
+	early := newDeploymentTestCanary()
+	early.Name = "early"
+	early.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	_, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Create(context.TODO(), early, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	rollout := &flaggerv1.RingRollout{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: flaggerv1.RingRolloutSpec{
+			Rings: []flaggerv1.Ring{
+				{Name: "early", Canaries: []flaggerv1.RingCanaryReference{{Name: "early"}}, BakeTime: "1h"},
+				{Name: "broad", Canaries: []flaggerv1.RingCanaryReference{{Name: "podinfo", Namespace: "default"}}},
+			},
+		},
+	}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().RingRollouts("default").Create(context.TODO(), rollout, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// held by the first ring analysis
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseWaiting))
+
+	// held while the first ring is baking
+	early, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "early", metav1.GetOptions{})
+	require.NoError(t, err)
+	early.Status.Phase = flaggerv1.CanaryPhaseSucceeded
+	early.Status.LastTransitionTime = metav1.Now()
+	early, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), early, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseWaiting))
+
+	// bake time passed
+	early.Status.LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), early, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+}
+
+func TestController_isRingComplete(t *testing.T) {
+	for _, tc := range []struct {
+		phase    flaggerv1.CanaryPhase
+		complete bool
+	}{
+		{phase: flaggerv1.CanaryPhaseInitialized, complete: true},
+		{phase: flaggerv1.CanaryPhaseSucceeded, complete: true},
+		{phase: flaggerv1.CanaryPhasePromotedWithoutAnalysis, complete: true},
+		{phase: flaggerv1.CanaryPhaseProgressing, complete: false},
+		{phase: flaggerv1.CanaryPhaseFailed, complete: false},
+	} {
+		t.Run(string(tc.phase), func(t *testing.T) {
+			mocks := newDeploymentFixture(nil)
+
+			early := newDeploymentTestCanary()
+			early.Name = "early"
+			early.Status.Phase = tc.phase
+			_, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Create(context.TODO(), early, metav1.CreateOptions{})
+			require.NoError(t, err)
+
+			rollout := flaggerv1.RingRollout{
+				ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+			}
+			ring := flaggerv1.Ring{Name: "early", Canaries: []flaggerv1.RingCanaryReference{{Name: "early"}}}
+
+			err = mocks.ctrl.isRingComplete(rollout, ring)
+			if tc.complete {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestScheduler_DeploymentAdaptiveSteps(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.AdaptiveSteps = &flaggerv1.CanaryAdaptiveSteps{
+		MinStepWeight: 5,
+		MaxStepWeight: 20,
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	assertCanaryWeight := func(expected int) {
+		_, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+		require.NoError(t, err)
+		assert.Equal(t, expected, canaryWeight)
+	}
+	setCustomMax := func(max int) {
+		c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		c.Spec.Analysis.Metrics[2].ThresholdRange.Max = toFloatPtr(max)
+		_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+
+	// the first step uses the min step
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assertCanaryWeight(5)
+
+	// the custom metric is at its threshold, the weight is held
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assertCanaryWeight(5)
+
+	// wide margin uses the max step
+	setCustomMax(1000)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assertCanaryWeight(25)
+
+	// the step is interpolated between the margins
+	setCustomMax(125)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assertCanaryWeight(34)
+
+	// the step is capped to the max weight
+	setCustomMax(1000)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assertCanaryWeight(50)
+}
+
+func TestScheduler_DeploymentTrafficVerification(t *testing.T) {
+	probes := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "podinfo.default", r.Header.Get(trafficProbeHeader))
+		probes++
+	}))
+	defer ts.Close()
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.TrafficVerification = &flaggerv1.CanaryTrafficVerification{
+		ProbeURL:      ts.URL,
+		ProbeRequests: 2,
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	_, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 20, canaryWeight)
+	assert.Equal(t, 4, probes)
+
+	// the routes are reverted outside of Flagger
+	require.NoError(t, mocks.router.SetRoutes(mocks.canary, 100, 0, false))
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.Status.FailedChecks)
+	assert.Equal(t, 20, c.Status.CanaryWeight)
+	_, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, canaryWeight)
+}
+
+func TestScheduler_DeploymentSkipAnalysis(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// enable skip
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Spec.SkipAnalysis = true
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, c.Spec.SkipAnalysis)
+	assert.Equal(t, flaggerv1.CanaryPhasePromotedWithoutAnalysis, c.Status.Phase)
+}
+
+func TestScheduler_DeploymentSkipAnalysisHooks(t *testing.T) {
+	preRolloutOK := false
+	var postRolloutPhase string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload flaggerv1.CanaryWebhookPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		if payload.Metadata["hook"] == "post" {
+			postRolloutPhase = string(payload.Phase)
+			return
+		}
+		if !preRolloutOK {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.SkipAnalysis = true
+	cd.Spec.Analysis.Webhooks = []flaggerv1.CanaryWebhook{
+		{
+			Name:     "pre",
+			Type:     flaggerv1.PreRolloutHook,
+			URL:      ts.URL,
+			Metadata: &map[string]string{"hook": "pre"},
+		},
+		{
+			Name:     "post",
+			Type:     flaggerv1.PostRolloutHook,
+			URL:      ts.URL,
+			Metadata: &map[string]string{"hook": "post"},
+		},
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// pre-rollout hook failure halts the promotion
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Equal(t, 1, c.Status.FailedChecks)
+
+	// promote without analysis
+	preRolloutOK = true
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhasePromotedWithoutAnalysis))
+	assert.Equal(t, string(flaggerv1.CanaryPhasePromotedWithoutAnalysis), postRolloutPhase)
+
+	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+	assert.False(t, mirrored)
+}
+
+func TestScheduler_DeploymentScaleDown(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.SkipAnalysis = true
+	cd.Spec.ScaleDown = &flaggerv1.CanaryScaleDown{Replicas: int32p(2)}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// promote
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhasePromotedWithoutAnalysis, c.Status.Phase)
+
+	// canary is kept warm
+	d, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *d.Spec.Replicas)
+}
+
+func TestScheduler_DeploymentScaleDownDelay(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.ScaleDown = &flaggerv1.CanaryScaleDown{Delay: "1h"}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// finalising
+	err := mocks.deployer.SetStatusPhase(mocks.canary, flaggerv1.CanaryPhaseFinalising)
+	require.NoError(t, err)
+	mocks.makeCanaryReady(t)
+
+	// wait for the delay
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFinalising, c.Status.Phase)
+}
+
+func TestScheduler_DeploymentDrain(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.DrainDuration = "1h"
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// finalising after all the traffic has been routed to primary
+	err := mocks.deployer.SetStatusWeight(mocks.canary, 0)
+	require.NoError(t, err)
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	err = mocks.deployer.SetStatusPhase(c, flaggerv1.CanaryPhaseFinalising)
+	require.NoError(t, err)
+	mocks.makeCanaryReady(t)
+
+	// wait for connections to drain
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFinalising, c.Status.Phase)
+	assert.False(t, c.Status.LastWeightChangeTime.IsZero())
+}
+
+func TestScheduler_DeploymentDrainFromWeightChange(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.DrainDuration = "1m"
+	mocks := newDeploymentFixture(cd)
+
+	// the status transition time is updated by the checks after the weight change
+	cd.Status.Phase = flaggerv1.CanaryPhaseFinalising
+	cd.Status.LastTransitionTime = metav1.Now()
+	cd.Status.LastWeightChangeTime = metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	assert.False(t, mocks.ctrl.isDraining(cd, 0))
+
+	cd.Status.LastWeightChangeTime = metav1.NewTime(time.Now().Add(-30 * time.Second))
+	assert.True(t, mocks.ctrl.isDraining(cd, 0))
+
+	// the drain applies to the canary weight changes during the analysis
+	cd.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	assert.True(t, mocks.ctrl.isDraining(cd, 10))
+	assert.False(t, mocks.ctrl.isDraining(cd, 0))
+}
+
+func TestScheduler_DeploymentConfirmTrafficIncreaseWeights(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Webhooks = []flaggerv1.CanaryWebhook{{
+		Name:    "approve-half",
+		Type:    flaggerv1.ConfirmTrafficIncreaseHook,
+		URL:     ts.URL,
+		Weights: []int{50},
+	}}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance without confirmation
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	_, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 10, canaryWeight)
+
+	// halt before crossing 50%
+	err = mocks.router.SetRoutes(mocks.canary, 60, 40, false)
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	_, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 40, canaryWeight)
+}
+
+func TestScheduler_DeploymentConfirmPromotionExpiry(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	for action, phase := range map[flaggerv1.GateExpiryAction]flaggerv1.CanaryPhase{
+		flaggerv1.GateExpiryApprove:  flaggerv1.CanaryPhasePromoting,
+		flaggerv1.GateExpiryRollback: flaggerv1.CanaryPhaseFailed,
+		flaggerv1.GateExpiryWait:     flaggerv1.CanaryPhaseWaitingPromotion,
+	} {
+		t.Run(string(action), func(t *testing.T) {
+			cd := newDeploymentTestCanary()
+			cd.Spec.Analysis.Webhooks = []flaggerv1.CanaryWebhook{{
+				Name:         "approve-promotion",
+				Type:         flaggerv1.ConfirmPromotionHook,
+				URL:          ts.URL,
+				Expiry:       "1h",
+				ExpiryAction: action,
+			}}
+			mocks := newDeploymentFixture(cd)
+
+			// initializing
+			mocks.ctrl.advanceCanary("podinfo", "default")
+
+			// make primary ready
+			mocks.makePrimaryReady(t)
+
+			// initialized
+			mocks.ctrl.advanceCanary("podinfo", "default")
+
+			// update
+			dep2 := newDeploymentTestDeploymentV2()
+			_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+			require.NoError(t, err)
+
+			// detect changes
+			mocks.ctrl.advanceCanary("podinfo", "default")
+			mocks.makeCanaryReady(t)
+
+			// wait for promotion approval at max weight
+			err = mocks.router.SetRoutes(mocks.canary, 50, 50, false)
+			require.NoError(t, err)
+			mocks.ctrl.advanceCanary("podinfo", "default")
+			require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseWaitingPromotion))
+
+			// gate not expired
+			mocks.ctrl.advanceCanary("podinfo", "default")
+			require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseWaitingPromotion))
+
+			// expire gate
+			c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Len(t, c.Status.Gates, 1)
+			c.Status.Gates[0].WaitingSince = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+			_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), c, metav1.UpdateOptions{})
+			require.NoError(t, err)
+
+			mocks.ctrl.advanceCanary("podinfo", "default")
+			require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", phase))
+		})
+	}
+}
+
+type severityNotifier struct {
+	severities []string
+}
+
+func (n *severityNotifier) Post(_ string, _ string, _ string, _ []notifier.Field, severity string) error {
+	n.severities = append(n.severities, severity)
+	return nil
+}
+
+func TestScheduler_DeploymentConfirmPromotionExpiryEscalation(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Webhooks = []flaggerv1.CanaryWebhook{{
+		Name:   "approve-promotion",
+		Type:   flaggerv1.ConfirmPromotionHook,
+		URL:    ts.URL,
+		Expiry: "1h",
+	}}
+	mocks := newDeploymentFixture(cd)
+	alerts := &severityNotifier{}
+	mocks.ctrl.notifier = alerts
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// wait for promotion approval at max weight
+	err = mocks.router.SetRoutes(mocks.canary, 50, 50, false)
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseWaitingPromotion))
+
+	expire := func(d time.Duration) {
+		c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Len(t, c.Status.Gates, 1)
+		// the status transition time doesn't restart the expiry
+		c.Status.LastTransitionTime = metav1.Now()
+		c.Status.Gates[0].WaitingSince = metav1.NewTime(time.Now().Add(-d))
+		_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), c, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+
+	// the first expiry is a warning sent once per period
+	alerts.severities = nil
+	expire(90 * time.Minute)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, []string{string(flaggerv1.SeverityWarn)}, alerts.severities)
+
+	// the next expiries are escalated to errors
+	alerts.severities = nil
+	expire(150 * time.Minute)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, []string{string(flaggerv1.SeverityError)}, alerts.severities)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.Status.Gates[0].Expiries)
+	assert.Equal(t, flaggerv1.CanaryPhaseWaitingPromotion, c.Status.Phase)
+}
+
+func TestScheduler_DeploymentLocalGate(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Webhooks = []flaggerv1.CanaryWebhook{{
+		Name: "approve-rollout",
+		Type: flaggerv1.ConfirmRolloutHook,
+		URL:  "gate://",
+	}}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// gate closed
 	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseWaiting))
 
-	// check status
-	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
-	require.NoError(t, err)
+	// open the gate like kubectl flagger gate open podinfo does
+	require.NoError(t, gate.Open(context.TODO(), mocks.kubeClient, "default", "podinfo", time.Hour))
 
-	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
 }
 
-func TestScheduler_DeploymentSkipAnalysis(t *testing.T) {
+func TestScheduler_DeploymentAnalysisQuota(t *testing.T) {
 	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.quota = quota.NewChecker(quota.Quota{MaxAnalyses: 1}, mocks.kubeClient, mocks.flaggerClient)
+
+	other := newDeploymentTestCanary()
+	other.Name = "frontend"
+	other.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	_, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Create(context.TODO(), other, metav1.CreateOptions{})
+	require.NoError(t, err)
+
 	// initializing
 	mocks.ctrl.advanceCanary("podinfo", "default")
 
@@ -122,29 +1177,70 @@ func TestScheduler_DeploymentSkipAnalysis(t *testing.T) {
 	// initialized
 	mocks.ctrl.advanceCanary("podinfo", "default")
 
-	// enable skip
-	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
 	require.NoError(t, err)
-	cd.Spec.SkipAnalysis = true
-	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+
+	// halt while the other analysis is running
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+
+	other, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "frontend", metav1.GetOptions{})
+	require.NoError(t, err)
+	other.Status.Phase = flaggerv1.CanaryPhaseSucceeded
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), other, metav1.UpdateOptions{})
 	require.NoError(t, err)
 
+	// start the analysis
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+}
+
+func TestScheduler_DeploymentPromotionSteps(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	// the deprecated canaryAnalysis field shifts the traffic back to the primary in steps too
+	cd.Spec.Analysis = nil
+	cd.Spec.CanaryAnalysis = &flaggerv1.CanaryAnalysis{
+		Interval:            "1m",
+		StepWeight:          60,
+		MaxWeight:           60,
+		StepWeightPromotion: 30,
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
 	// update
 	dep2 := newDeploymentTestDeploymentV2()
-	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
 	require.NoError(t, err)
 
 	// detect changes
 	mocks.ctrl.advanceCanary("podinfo", "default")
 	mocks.makeCanaryReady(t)
 
-	// advance
+	// advance to max weight
 	mocks.ctrl.advanceCanary("podinfo", "default")
-
-	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	_, c, _, err := mocks.router.GetRoutes(cd)
 	require.NoError(t, err)
-	assert.True(t, c.Spec.SkipAnalysis)
-	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, c.Status.Phase)
+	assert.Equal(t, 60, c)
+
+	// start promotion
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhasePromoting))
+
+	for _, weight := range []int{30, 0} {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+		p, c, _, err := mocks.router.GetRoutes(cd)
+		require.NoError(t, err)
+		assert.Equal(t, weight, c)
+		assert.Equal(t, 100-weight, p)
+	}
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFinalising))
 }
 
 func TestScheduler_DeploymentAnalysisPhases(t *testing.T) {
@@ -328,77 +1424,332 @@ func TestScheduler_DeploymentPromotion(t *testing.T) {
 	// detect configs changes
 	mocks.ctrl.advanceCanary("podinfo", "default")
 
-	_, _, _, err = mocks.router.GetRoutes(mocks.canary)
+	_, _, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+
+	primaryWeight := 60
+	canaryWeight := 40
+	err = mocks.router.SetRoutes(mocks.canary, primaryWeight, canaryWeight, false)
+	require.NoError(t, err)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// check progressing status
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+
+	// promote
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// check promoting status
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhasePromoting, c.Status.Phase)
+
+	// finalise
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+	assert.False(t, mirrored)
+
+	primaryDep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	primaryImage := primaryDep.Spec.Template.Spec.Containers[0].Image
+	canaryImage := dep2.Spec.Template.Spec.Containers[0].Image
+	assert.Equal(t, canaryImage, primaryImage)
+
+	configPrimary, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-config-env-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, config2.Data["color"], configPrimary.Data["color"])
+
+	secretPrimary, err := mocks.kubeClient.CoreV1().Secrets("default").Get(context.TODO(), "podinfo-secret-env-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, string(secret2.Data["apiKey"]), string(secretPrimary.Data["apiKey"]))
+
+	// check finalising status
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFinalising, c.Status.Phase)
+
+	// scale canary to zero
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, c.Status.Phase)
+
+	// check target annotations
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Succeeded", dep.Annotations[lastAnalysisResultAnnotation])
+	assert.NotEmpty(t, dep.Annotations[lastPromotionTimeAnnotation])
+}
+
+func TestScheduler_DeploymentMirroring(t *testing.T) {
+	mocks := newDeploymentFixture(newDeploymentTestCanaryMirror())
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// check if traffic is mirrored to canary
+	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+	assert.True(t, mirrored)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// check if traffic is mirrored to canary
+	primaryWeight, canaryWeight, mirrored, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 90, primaryWeight)
+	assert.Equal(t, 10, canaryWeight)
+	assert.False(t, mirrored)
+}
+
+func TestScheduler_DeploymentBlueGreenMirroring(t *testing.T) {
+	cd := newDeploymentTestCanaryMirror()
+	cd.Spec.Analysis.StepWeight = 0
+	cd.Spec.Analysis.MaxWeight = 0
+	cd.Spec.Analysis.Iterations = 2
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// the traffic is mirrored during all the iterations
+	for i := 1; i <= 2; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+		primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
+		require.NoError(t, err)
+		assert.Equal(t, 100, primaryWeight)
+		assert.Equal(t, 0, canaryWeight)
+		assert.True(t, mirrored)
+
+		c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, i, c.Status.Iterations)
+	}
+
+	// switch all the traffic to the canary
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, primaryWeight)
+	assert.Equal(t, 100, canaryWeight)
+	assert.False(t, mirrored)
+}
+
+func TestScheduler_DeploymentRollbackAnnotation(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+
+	// request the rollback
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Annotations = map[string]string{rollbackAnnotation: "true"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFailed))
+
+	// the annotation is removed so that the next revision is analysed
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, cd.Annotations, rollbackAnnotation)
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(cd)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+}
+
+func TestScheduler_DeploymentRollbackAnnotationSuspendedNotReady(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+
+	// the canary rollout gets stuck
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Status = appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 1}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// suspend the analysis and request the rollback
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Spec.Suspend = true
+	cd.Annotations = map[string]string{rollbackAnnotation: "true"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFailed))
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(cd)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+}
+
+func TestScheduler_DeploymentRollbackAnnotationIgnored(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
 	require.NoError(t, err)
 
-	primaryWeight := 60
-	canaryWeight := 40
-	err = mocks.router.SetRoutes(mocks.canary, primaryWeight, canaryWeight, false)
+	// suspend the canary and request a rollback while there is no analysis in progress
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Spec.Suspend = true
+	cd.Annotations = map[string]string{rollbackAnnotation: "true"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
 	require.NoError(t, err)
 
-	// advance
+	// the annotation is removed and the canary stays suspended
 	mocks.ctrl.advanceCanary("podinfo", "default")
-
-	// check progressing status
-	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
-	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.NotContains(t, cd.Annotations, rollbackAnnotation)
+	assert.Equal(t, flaggerv1.CanaryPhaseInitialized, cd.Status.Phase)
 
-	// promote
+	// the next revision is analysed once resumed
+	cd.Spec.Suspend = false
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
 	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+}
 
-	// check promoting status
-	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
-	require.NoError(t, err)
-	assert.Equal(t, flaggerv1.CanaryPhasePromoting, c.Status.Phase)
+func TestScheduler_DeploymentSuspend(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
 
-	// finalise
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
 	mocks.ctrl.advanceCanary("podinfo", "default")
 
-	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
-	require.NoError(t, err)
-	assert.Equal(t, 100, primaryWeight)
-	assert.Equal(t, 0, canaryWeight)
-	assert.False(t, mirrored)
-
-	primaryDep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
 	require.NoError(t, err)
 
-	primaryImage := primaryDep.Spec.Template.Spec.Containers[0].Image
-	canaryImage := dep2.Spec.Template.Spec.Containers[0].Image
-	assert.Equal(t, canaryImage, primaryImage)
-
-	configPrimary, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-config-env-primary", metav1.GetOptions{})
-	require.NoError(t, err)
-	assert.Equal(t, config2.Data["color"], configPrimary.Data["color"])
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
 
-	secretPrimary, err := mocks.kubeClient.CoreV1().Secrets("default").Get(context.TODO(), "podinfo-secret-env-primary", metav1.GetOptions{})
-	require.NoError(t, err)
-	assert.Equal(t, string(secret2.Data["apiKey"]), string(secretPrimary.Data["apiKey"]))
+	setSuspend := func(suspend bool) {
+		cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		cd.Spec.Suspend = suspend
+		_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
 
-	// check finalising status
-	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	// the analysis is held at the current weight
+	setSuspend(true)
+	for i := 0; i < 3; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+	}
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+	_, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
 	require.NoError(t, err)
-	assert.Equal(t, flaggerv1.CanaryPhaseFinalising, c.Status.Phase)
+	assert.Equal(t, 10, canaryWeight)
 
-	// scale canary to zero
+	// the analysis continues once resumed
+	setSuspend(false)
 	mocks.ctrl.advanceCanary("podinfo", "default")
-
-	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	_, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
 	require.NoError(t, err)
-	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, c.Status.Phase)
+	assert.Equal(t, 20, canaryWeight)
 }
 
-func TestScheduler_DeploymentMirroring(t *testing.T) {
-	mocks := newDeploymentFixture(newDeploymentTestCanaryMirror())
+func TestScheduler_DeploymentAnalysisWindow(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	// a window that never opens
+	cd.Spec.Analysis.Schedule = []flaggerv1.CanaryAnalysisWindow{{Cron: "0 0 30 2 *"}}
+	mocks := newDeploymentFixture(cd)
 
 	// initializing
 	mocks.ctrl.advanceCanary("podinfo", "default")
-
-	// make primary ready
 	mocks.makePrimaryReady(t)
-
-	// initialized
 	mocks.ctrl.advanceCanary("podinfo", "default")
 
 	// update
@@ -410,25 +1761,26 @@ func TestScheduler_DeploymentMirroring(t *testing.T) {
 	mocks.ctrl.advanceCanary("podinfo", "default")
 	mocks.makeCanaryReady(t)
 
-	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
-
-	// check if traffic is mirrored to canary
-	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
+	// the analysis doesn't start outside of the window
+	for i := 0; i < 3; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+	}
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+	_, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
 	require.NoError(t, err)
-	assert.Equal(t, 100, primaryWeight)
 	assert.Equal(t, 0, canaryWeight)
-	assert.True(t, mirrored)
 
-	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	// the analysis starts once the window opens
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	c.Spec.Analysis.Schedule = []flaggerv1.CanaryAnalysisWindow{{Cron: "* * * * *"}}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
 
-	// check if traffic is mirrored to canary
-	primaryWeight, canaryWeight, mirrored, err = mocks.router.GetRoutes(mocks.canary)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	_, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
 	require.NoError(t, err)
-	assert.Equal(t, 90, primaryWeight)
 	assert.Equal(t, 10, canaryWeight)
-	assert.False(t, mirrored)
 }
 
 func TestScheduler_DeploymentABTesting(t *testing.T) {
@@ -496,6 +1848,116 @@ func TestScheduler_DeploymentABTesting(t *testing.T) {
 	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, c.Status.Phase)
 }
 
+func TestScheduler_DeploymentTimeSlices(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.TimeSlices = &flaggerv1.CanaryTimeSlices{
+		Window: "10m",
+		Slices: 1,
+	}
+	mocks := newDeploymentFixture(cd)
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// start the canary time slice
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, primaryWeight)
+	assert.Equal(t, 100, canaryWeight)
+
+	expireTimeSlice := func() {
+		c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.NotNil(t, c.Status.TimeSlice)
+		c.Status.TimeSlice.StartedAt = metav1.NewTime(time.Now().Add(-11 * time.Minute))
+		_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), c, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+
+	// window not elapsed
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.Status.TimeSlice.Window)
+	assert.Equal(t, flaggerv1.TimeSliceCohortCanary, c.Status.TimeSlice.Cohort)
+
+	// switch to the primary time slice
+	expireTimeSlice()
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	primaryWeight, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.Status.TimeSlice.Window)
+	assert.Equal(t, flaggerv1.TimeSliceCohortPrimary, c.Status.TimeSlice.Cohort)
+	require.Len(t, c.Status.TimeSlice.Results, 1)
+	assert.NotEmpty(t, c.Status.TimeSlice.Results[0].Metrics)
+
+	// compare the cohorts and promote
+	expireTimeSlice()
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhasePromoting))
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, c.Status.TimeSlice.Results, 2)
+
+	primaryDep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, dep2.Spec.Template.Spec.Containers[0].Image, primaryDep.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestCompareTimeSlices(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.TimeSlices = &flaggerv1.CanaryTimeSlices{Window: "10m", Slices: 1}
+	result := func(cohort flaggerv1.TimeSliceCohort, success, duration float64) flaggerv1.CanaryTimeSliceResult {
+		return flaggerv1.CanaryTimeSliceResult{
+			Cohort: cohort,
+			Metrics: []flaggerv1.CanaryMetricResult{
+				{Name: "request-success-rate", Value: success},
+				{Name: "request-duration", Value: duration},
+			},
+		}
+	}
+
+	err := compareTimeSlices(cd, []flaggerv1.CanaryTimeSliceResult{
+		result(flaggerv1.TimeSliceCohortCanary, 99, 105),
+		result(flaggerv1.TimeSliceCohortPrimary, 100, 100),
+	})
+	assert.NoError(t, err)
+
+	err = compareTimeSlices(cd, []flaggerv1.CanaryTimeSliceResult{
+		result(flaggerv1.TimeSliceCohortCanary, 99, 150),
+		result(flaggerv1.TimeSliceCohortPrimary, 100, 100),
+	})
+	assert.Error(t, err)
+
+	err = compareTimeSlices(cd, []flaggerv1.CanaryTimeSliceResult{
+		result(flaggerv1.TimeSliceCohortCanary, 99, 100),
+	})
+	assert.Error(t, err)
+}
+
 func TestScheduler_DeploymentPortDiscovery(t *testing.T) {
 	mocks := newDeploymentFixture(nil)
 
@@ -641,3 +2103,165 @@ func TestScheduler_DeploymentAlerts(t *testing.T) {
 	// initialization done - now send alert
 	mocks.ctrl.advanceCanary("podinfo", "default")
 }
+
+func TestScheduler_DeploymentTakeoverBootstrap(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Bootstrap = &flaggerv1.CanaryBootstrap{Mode: flaggerv1.TakeoverBootstrap}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// the apex service still routes to the existing pods
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{
+				{IP: "10.0.0.1", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "podinfo-7c8b9f-abcde"}},
+			},
+		}},
+	}
+	_, err := mocks.kubeClient.CoreV1().Endpoints("default").Create(context.TODO(), endpoints, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, dep.Spec.Replicas == nil || *dep.Spec.Replicas > 0)
+	require.Error(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+
+	// the endpoints switched to primary
+	endpoints.Subsets[0].Addresses[0].TargetRef.Name = "podinfo-primary-5d6f7c-fghij"
+	_, err = mocks.kubeClient.CoreV1().Endpoints("default").Update(context.TODO(), endpoints, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *dep.Spec.Replicas)
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+}
+
+func TestScheduler_DeploymentVerification(t *testing.T) {
+	failed := false
+	var payload flaggerv1.CanaryWebhookPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if failed {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Verification = &flaggerv1.CanaryVerification{Schedule: "@hourly"}
+	cd.Spec.Analysis.Webhooks = []flaggerv1.CanaryWebhook{
+		{
+			Name: "smoke-test",
+			Type: flaggerv1.VerificationHook,
+			URL:  ts.URL,
+		},
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// the schedule hasn't elapsed since the initialization
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, c.Status.LastVerification)
+
+	// verification due
+	c.Status.LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, c.Status.LastVerification)
+	assert.True(t, c.Status.LastVerification.Succeeded)
+	assert.Len(t, c.Status.LastVerification.Metrics, 3)
+	assert.Equal(t, "podinfo", payload.Name)
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+
+	// the failed verification is reported without changing the canary phase
+	failed = true
+	c.Status.LastVerification.Time = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, c.Status.LastVerification)
+	assert.False(t, c.Status.LastVerification.Succeeded)
+	assert.Contains(t, c.Status.LastVerification.Message, "smoke-test")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+}
+
+func TestIsVerifiable(t *testing.T) {
+	for phase, verifiable := range map[flaggerv1.CanaryPhase]bool{
+		flaggerv1.CanaryPhaseInitialized:             true,
+		flaggerv1.CanaryPhaseSucceeded:               true,
+		flaggerv1.CanaryPhasePromotedWithoutAnalysis: true,
+		flaggerv1.CanaryPhaseProgressing:             false,
+		flaggerv1.CanaryPhaseFailed:                  false,
+	} {
+		cd := newDeploymentTestCanary()
+		cd.Status.Phase = phase
+		assert.Equal(t, verifiable, isVerifiable(cd), phase)
+	}
+}
+
+func TestScheduler_DeploymentBaseline(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Baseline = true
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// the baseline runs only during the analysis
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-baseline", metav1.GetOptions{})
+	require.True(t, errors.IsNotFound(err))
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	baseline, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-baseline", metav1.GetOptions{})
+	require.NoError(t, err)
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, primary.Spec.Template.Spec, baseline.Spec.Template.Spec)
+
+	// rollback removes the baseline
+	mocks.makeCanaryReady(t)
+	err = mocks.deployer.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing, FailedChecks: 10})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFailed))
+
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-baseline", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
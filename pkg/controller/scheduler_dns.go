@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	externaldnsv1alpha1 "github.com/fluxcd/flagger/pkg/apis/externaldns/v1alpha1"
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// reconcileCanaryDNS publishes the canary preview host while the analysis is running
+// and removes it once the canary is promoted or rolled back
+func (c *Controller) reconcileCanaryDNS(canary *flaggerv1.Canary) error {
+	if canary.Spec.DNS == nil {
+		return nil
+	}
+
+	switch canary.Status.Phase {
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaiting, flaggerv1.CanaryPhaseWaitingPromotion:
+		return c.publishCanaryDNS(canary)
+	default:
+		return c.removeCanaryDNS(canary)
+	}
+}
+
+func (c *Controller) publishCanaryDNS(canary *flaggerv1.Canary) error {
+	_, _, name := canary.GetServiceNames()
+	spec := externaldnsv1alpha1.DNSEndpointSpec{
+		Endpoints: []*externaldnsv1alpha1.Endpoint{
+			{
+				DNSName:    canary.Spec.DNS.Hostname,
+				Targets:    canary.Spec.DNS.Targets,
+				RecordType: dnsRecordType(canary.Spec.DNS),
+				RecordTTL:  canary.Spec.DNS.TTL,
+			},
+		},
+	}
+
+	endpoint, err := c.flaggerClient.ExternaldnsV1alpha1().DNSEndpoints(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		endpoint = &externaldnsv1alpha1.DNSEndpoint{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: canary.Namespace,
+				Labels:    canary.Spec.DNS.Labels,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: spec,
+		}
+		_, err = c.flaggerClient.ExternaldnsV1alpha1().DNSEndpoints(canary.Namespace).Create(context.TODO(), endpoint, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("DNSEndpoint %s.%s create error: %w", name, canary.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("DNSEndpoint %s.%s created for %s", name, canary.Namespace, canary.Spec.DNS.Hostname)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("DNSEndpoint %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+
+	if cmp.Diff(spec, endpoint.Spec) != "" || cmp.Diff(canary.Spec.DNS.Labels, endpoint.Labels) != "" {
+		clone := endpoint.DeepCopy()
+		clone.Spec = spec
+		clone.Labels = canary.Spec.DNS.Labels
+		_, err = c.flaggerClient.ExternaldnsV1alpha1().DNSEndpoints(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("DNSEndpoint %s.%s update error: %w", name, canary.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("DNSEndpoint %s.%s updated", name, canary.Namespace)
+	}
+	return nil
+}
+
+func (c *Controller) removeCanaryDNS(canary *flaggerv1.Canary) error {
+	_, _, name := canary.GetServiceNames()
+	err := c.flaggerClient.ExternaldnsV1alpha1().DNSEndpoints(canary.Namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("DNSEndpoint %s.%s delete error: %w", name, canary.Namespace, err)
+	}
+	c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("DNSEndpoint %s.%s deleted", name, canary.Namespace)
+	return nil
+}
+
+func dnsRecordType(dns *flaggerv1.CanaryDNS) string {
+	if dns.RecordType != "" {
+		return dns.RecordType
+	}
+	for _, target := range dns.Targets {
+		if net.ParseIP(target) == nil {
+			return "CNAME"
+		}
+	}
+	return "A"
+}
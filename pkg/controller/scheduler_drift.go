@@ -0,0 +1,45 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/router"
+)
+
+// routingFailed records the routing objects modified outside of Flagger with drift events
+// and returns true if the routing reconciliation failed and the canary shouldn't advance
+func (c *Controller) routingFailed(canary *flaggerv1.Canary, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var drift *router.DriftError
+	if !errors.As(err, &drift) {
+		c.recordEventWarningf(canary, "%v", err)
+		return true
+	}
+
+	c.recordEventDriftf(canary, "Routing drift detected for %s.%s: %v", canary.Name, canary.Namespace, drift)
+	if !drift.Repaired {
+		c.alert(canary, fmt.Sprintf("Routing drift detected: %v", drift), false, flaggerv1.SeverityWarn)
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/observers"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
+)
+
+// runErrorBudgetCheck returns false if the error budget of the target service is exhausted,
+// unless the analysis start is approved by all the confirm-error-budget webhooks
+func (c *Controller) runErrorBudgetCheck(canary *flaggerv1.Canary) bool {
+	budget := canary.GetAnalysis().ErrorBudget
+	if budget == nil {
+		return true
+	}
+
+	remaining, err := c.queryErrorBudget(canary, budget)
+	if err != nil {
+		c.recordEventErrorf(canary, "Error budget check for %s.%s failed: %v", canary.Name, canary.Namespace, err)
+		return false
+	}
+
+	minRemaining := 0.0
+	if budget.MinRemaining != nil {
+		minRemaining = *budget.MinRemaining
+	}
+	if remaining > minRemaining {
+		return true
+	}
+
+	approved := false
+	for _, webhook := range canary.GetAnalysis().Webhooks {
+		if webhook.Type != flaggerv1.ConfirmErrorBudgetHook {
+			continue
+		}
+		if err := c.callWebhook(canary, canary.Status.Phase, webhook); err != nil {
+			c.recordEventWarningf(canary, "Halt %s.%s advancement error budget exhausted %.4f <= %v, waiting for approval %s",
+				canary.Name, canary.Namespace, remaining, minRemaining, webhook.Name)
+			return false
+		}
+		c.recordEventInfof(canary, "Confirm-error-budget check %s passed", webhook.Name)
+		approved = true
+	}
+
+	if !approved {
+		c.recordEventWarningf(canary, "Halt %s.%s advancement error budget exhausted %.4f <= %v",
+			canary.Name, canary.Namespace, remaining, minRemaining)
+	}
+	return approved
+}
+
+// queryErrorBudget returns the remaining error budget ratio from the referenced metric template
+func (c *Controller) queryErrorBudget(canary *flaggerv1.Canary, budget *flaggerv1.CanaryErrorBudget) (float64, error) {
+	namespace := canary.Namespace
+	if budget.TemplateRef.Namespace != "" {
+		namespace = budget.TemplateRef.Namespace
+	}
+
+	template, err := c.flaggerInformers.MetricInformer.Lister().MetricTemplates(namespace).Get(budget.TemplateRef.Name)
+	if err != nil {
+		return 0, fmt.Errorf("metric template %s.%s error: %w", budget.TemplateRef.Name, namespace, err)
+	}
+	if !c.isReferenceAllowed(canary, namespace, template.Spec.AllowedNamespaces) {
+		return 0, fmt.Errorf("metric template %s.%s can't be referenced from namespace %s",
+			budget.TemplateRef.Name, namespace, canary.Namespace)
+	}
+
+	var credentials map[string][]byte
+	if template.Spec.Provider.SecretRef != nil {
+		secret, err := c.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), template.Spec.Provider.SecretRef.Name, metav1.GetOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("metric template %s.%s secret %s error: %w",
+				budget.TemplateRef.Name, namespace, template.Spec.Provider.SecretRef.Name, err)
+		}
+		credentials = secret.Data
+	}
+
+	interval := budget.Interval
+	if interval == "" {
+		interval = canary.GetAnalysis().Interval
+	}
+
+	factory := providers.Factory{}
+	provider, err := factory.Provider(interval, template.Spec.Provider, credentials)
+	if err != nil {
+		return 0, fmt.Errorf("metric template %s.%s provider %s error: %w",
+			budget.TemplateRef.Name, namespace, template.Spec.Provider.Type, err)
+	}
+
+	query, err := observers.RenderQuery(template.Spec.Query, toMetricModel(canary, interval))
+	if err != nil {
+		return 0, fmt.Errorf("metric template %s.%s query render error: %w", budget.TemplateRef.Name, namespace, err)
+	}
+
+	return provider.RunQuery(query)
+}
+
+// isStartingAnalysis returns true if the canary is not initializing and no analysis is in progress
+func isStartingAnalysis(canary *flaggerv1.Canary) bool {
+	switch canary.Status.Phase {
+	case "", flaggerv1.CanaryPhaseInitializing, flaggerv1.CanaryPhaseProgressing,
+		flaggerv1.CanaryPhaseWaitingPromotion, flaggerv1.CanaryPhasePromoting, flaggerv1.CanaryPhaseFinalising:
+		return false
+	}
+	return true
+}
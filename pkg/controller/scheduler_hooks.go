@@ -17,17 +17,143 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	"github.com/fluxcd/flagger/pkg/canary"
+	"github.com/fluxcd/flagger/pkg/router"
 )
 
-func (c *Controller) runConfirmTrafficIncreaseHooks(canary *flaggerv1.Canary) bool {
+// gateExpiryAction returns the action to take for a gate that hasn't approved the canary,
+// or an empty action if the canary isn't waiting in the given phase or the gate hasn't expired yet.
+// The expiry is measured from the first check that held the canary in the phase, for the wait action
+// the alert is sent once per expiry period and escalated to the error severity with the canary
+// metadata from the second expiry onwards
+func (c *Controller) gateExpiryAction(canary *flaggerv1.Canary, webhook flaggerv1.CanaryWebhook,
+	phase flaggerv1.CanaryPhase) flaggerv1.GateExpiryAction {
+	expiry := webhook.GetExpiry()
+	if expiry == 0 || canary.Status.Phase != phase {
+		return ""
+	}
+
+	gate := getGateStatus(canary, webhook.Name)
+	if gate == nil {
+		c.setGateStatus(canary, flaggerv1.CanaryGateStatus{Name: webhook.Name, WaitingSince: metav1.Now()})
+		return ""
+	}
+
+	waiting := time.Since(gate.WaitingSince.Time)
+	expiries := int(waiting / expiry)
+	if expiries == 0 {
+		return ""
+	}
+
+	action := webhook.GetExpiryAction()
+	if action == flaggerv1.GateExpiryWait && expiries > gate.Expiries {
+		c.recordEventWarningf(canary, "Gate %s for %s.%s expired %d times, waiting for approval for %v",
+			webhook.Name, canary.Name, canary.Namespace, expiries, waiting.Round(time.Second))
+		if !webhook.MuteAlert {
+			severity := flaggerv1.SeverityWarn
+			if expiries > 1 {
+				severity = flaggerv1.SeverityError
+			}
+			c.alert(canary, fmt.Sprintf("Canary has been waiting for approval of %s for %v.",
+				webhook.Name, waiting.Round(time.Second)), expiries > 1, severity)
+		}
+		c.setGateStatus(canary, flaggerv1.CanaryGateStatus{Name: webhook.Name, WaitingSince: gate.WaitingSince, Expiries: expiries})
+	}
+	return action
+}
+
+// getGateStatus returns the status of the gate webhook or nil if the gate isn't holding the canary
+func getGateStatus(canary *flaggerv1.Canary, name string) *flaggerv1.CanaryGateStatus {
+	for i := range canary.Status.Gates {
+		if canary.Status.Gates[i].Name == name {
+			return &canary.Status.Gates[i]
+		}
+	}
+	return nil
+}
+
+// setGateStatus records the gate webhook state in the canary status
+func (c *Controller) setGateStatus(canary *flaggerv1.Canary, gate flaggerv1.CanaryGateStatus) {
+	c.updateGatesStatus(canary, func(gates []flaggerv1.CanaryGateStatus) []flaggerv1.CanaryGateStatus {
+		for i := range gates {
+			if gates[i].Name == gate.Name {
+				gates[i] = gate
+				return gates
+			}
+		}
+		return append(gates, gate)
+	})
+}
+
+// clearGateStatus removes the gate webhook state from the canary status once the gate approved it
+func (c *Controller) clearGateStatus(canary *flaggerv1.Canary, name string) {
+	if getGateStatus(canary, name) == nil {
+		return
+	}
+	c.updateGatesStatus(canary, func(gates []flaggerv1.CanaryGateStatus) []flaggerv1.CanaryGateStatus {
+		var res []flaggerv1.CanaryGateStatus
+		for _, gate := range gates {
+			if gate.Name != name {
+				res = append(res, gate)
+			}
+		}
+		return res
+	})
+}
+
+func (c *Controller) updateGatesStatus(cd *flaggerv1.Canary, update func([]flaggerv1.CanaryGateStatus) []flaggerv1.CanaryGateStatus) {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	canary := cd
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := cd.DeepCopy()
+		cdCopy.Status.Gates = update(cdCopy.Status.Gates)
+		_, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).UpdateStatus(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		firstTry = false
+		return
+	})
+	if err != nil {
+		c.recordEventWarningf(canary, "gates status update failed after retries: %v", err)
+		return
+	}
+	// keep the in-memory status in sync for the status updates of this interval
+	canary.Status.Gates = update(canary.Status.Gates)
+}
+
+func (c *Controller) runConfirmTrafficIncreaseHooks(canary *flaggerv1.Canary, canaryController canary.Controller,
+	meshRouter router.Interface, canaryWeight int, nextWeight int) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
-		if webhook.Type == flaggerv1.ConfirmTrafficIncreaseHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+		if webhook.Type == flaggerv1.ConfirmTrafficIncreaseHook && webhook.AppliesToStep(canaryWeight, nextWeight) {
+			err := c.callStepWebhook(canary, flaggerv1.CanaryPhaseProgressing, webhook, nextWeight)
 			if err != nil {
+				switch c.gateExpiryAction(canary, webhook, flaggerv1.CanaryPhaseProgressing) {
+				case flaggerv1.GateExpiryApprove:
+					c.recordEventWarningf(canary, "Confirm-traffic-increase check %s expired, approving", webhook.Name)
+					c.clearGateStatus(canary, webhook.Name)
+					continue
+				case flaggerv1.GateExpiryRollback:
+					c.recordEventWarningf(canary, "Rolling back %s.%s confirm-traffic-increase check %s expired",
+						canary.Name, canary.Namespace, webhook.Name)
+					c.alert(canary, fmt.Sprintf("Confirm-traffic-increase check %s expired", webhook.Name),
+						false, flaggerv1.SeverityError)
+					c.rollback(canary, canaryController, meshRouter, rollbackGateExpired)
+					return false
+				}
 				c.recordEventWarningf(canary, "Halt %s.%s advancement waiting for traffic increase approval %s",
 					canary.Name, canary.Namespace, webhook.Name)
 				if !webhook.MuteAlert {
@@ -35,16 +161,32 @@ func (c *Controller) runConfirmTrafficIncreaseHooks(canary *flaggerv1.Canary) bo
 				}
 				return false
 			}
+			c.clearGateStatus(canary, webhook.Name)
 			c.recordEventInfof(canary, "Confirm-traffic-increase check %s passed", webhook.Name)
 		}
 	}
 	return true
 }
 
-func (c *Controller) runConfirmRolloutHooks(canary *flaggerv1.Canary, canaryController canary.Controller) bool {
+func (c *Controller) runConfirmRolloutHooks(canary *flaggerv1.Canary, canaryController canary.Controller,
+	meshRouter router.Interface) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.ConfirmRolloutHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			err := c.callWebhook(canary, flaggerv1.CanaryPhaseProgressing, webhook)
+			if err != nil {
+				switch c.gateExpiryAction(canary, webhook, flaggerv1.CanaryPhaseWaiting) {
+				case flaggerv1.GateExpiryApprove:
+					c.recordEventWarningf(canary, "Confirm-rollout check %s expired, approving", webhook.Name)
+					err = nil
+				case flaggerv1.GateExpiryRollback:
+					c.recordEventWarningf(canary, "Rolling back %s.%s confirm-rollout check %s expired",
+						canary.Name, canary.Namespace, webhook.Name)
+					c.alert(canary, fmt.Sprintf("Confirm-rollout check %s expired", webhook.Name),
+						false, flaggerv1.SeverityError)
+					c.rollback(canary, canaryController, meshRouter, rollbackGateExpired)
+					return false
+				}
+			}
 			if err != nil {
 				if canary.Status.Phase != flaggerv1.CanaryPhaseWaiting {
 					if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseWaiting); err != nil {
@@ -58,6 +200,7 @@ func (c *Controller) runConfirmRolloutHooks(canary *flaggerv1.Canary, canaryCont
 				}
 				return false
 			} else {
+				c.clearGateStatus(canary, webhook.Name)
 				if canary.Status.Phase == flaggerv1.CanaryPhaseWaiting {
 					if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseProgressing); err != nil {
 						c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
@@ -76,11 +219,25 @@ func (c *Controller) runConfirmRolloutHooks(canary *flaggerv1.Canary, canaryCont
 	return true
 }
 
-func (c *Controller) runConfirmPromotionHooks(canary *flaggerv1.Canary, canaryController canary.Controller) bool {
+func (c *Controller) runConfirmPromotionHooks(canary *flaggerv1.Canary, canaryController canary.Controller,
+	meshRouter router.Interface) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.ConfirmPromotionHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			err := c.callWebhook(canary, flaggerv1.CanaryPhaseProgressing, webhook)
 			if err != nil {
+				switch c.gateExpiryAction(canary, webhook, flaggerv1.CanaryPhaseWaitingPromotion) {
+				case flaggerv1.GateExpiryApprove:
+					c.recordEventWarningf(canary, "Confirm-promotion check %s expired, approving", webhook.Name)
+					c.clearGateStatus(canary, webhook.Name)
+					continue
+				case flaggerv1.GateExpiryRollback:
+					c.recordEventWarningf(canary, "Rolling back %s.%s confirm-promotion check %s expired",
+						canary.Name, canary.Namespace, webhook.Name)
+					c.alert(canary, fmt.Sprintf("Confirm-promotion check %s expired", webhook.Name),
+						false, flaggerv1.SeverityError)
+					c.rollback(canary, canaryController, meshRouter, rollbackGateExpired)
+					return false
+				}
 				if canary.Status.Phase != flaggerv1.CanaryPhaseWaitingPromotion {
 					if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseWaitingPromotion); err != nil {
 						c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
@@ -93,6 +250,7 @@ func (c *Controller) runConfirmPromotionHooks(canary *flaggerv1.Canary, canaryCo
 				}
 				return false
 			} else {
+				c.clearGateStatus(canary, webhook.Name)
 				c.recordEventInfof(canary, "Confirm-promotion check %s passed", webhook.Name)
 			}
 		}
@@ -101,17 +259,20 @@ func (c *Controller) runConfirmPromotionHooks(canary *flaggerv1.Canary, canaryCo
 }
 
 func (c *Controller) runPreRolloutHooks(canary *flaggerv1.Canary) bool {
+	var webhooks []flaggerv1.CanaryWebhook
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.PreRolloutHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
-			if err != nil {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement pre-rollout check %s failed %v",
-					canary.Name, canary.Namespace, webhook.Name, err)
-				return false
-			} else {
-				c.recordEventInfof(canary, "Pre-rollout check %s passed", webhook.Name)
-			}
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	for _, r := range c.callWebhookGroups(canary, flaggerv1.CanaryPhaseProgressing, webhooks) {
+		if r.err != nil {
+			c.recordEventWarningf(canary, "Halt %s.%s advancement pre-rollout check %s failed %v",
+				canary.Name, canary.Namespace, r.webhook.Name, r.err)
+			return false
 		}
+		c.recordEventInfof(canary, "Pre-rollout check %s passed", r.webhook.Name)
 	}
 	return true
 }
@@ -119,7 +280,7 @@ func (c *Controller) runPreRolloutHooks(canary *flaggerv1.Canary) bool {
 func (c *Controller) runPostRolloutHooks(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.PostRolloutHook {
-			err := CallWebhook(canary.Name, canary.Namespace, phase, webhook)
+			err := c.callWebhook(canary, phase, webhook)
 			if err != nil {
 				c.recordEventWarningf(canary, "Post-rollout hook %s failed %v", webhook.Name, err)
 				return false
@@ -134,7 +295,7 @@ func (c *Controller) runPostRolloutHooks(canary *flaggerv1.Canary, phase flagger
 func (c *Controller) runRollbackHooks(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.RollbackHook {
-			err := CallWebhook(canary.Name, canary.Namespace, phase, webhook)
+			err := c.callWebhook(canary, phase, webhook)
 			if err != nil {
 				c.recordEventInfof(canary, "Rollback hook %s not signaling a rollback", webhook.Name)
 			} else {
@@ -62,6 +62,10 @@ func (c *Controller) checkMetricProviderAvailability(canary *flaggerv1.Canary) e
 			if err != nil {
 				return fmt.Errorf("metric template %s.%s error: %v", metric.TemplateRef.Name, namespace, err)
 			}
+			if !c.isReferenceAllowed(canary, namespace, template.Spec.AllowedNamespaces) {
+				return fmt.Errorf("metric template %s.%s can't be referenced from namespace %s",
+					metric.TemplateRef.Name, namespace, canary.Namespace)
+			}
 
 			var credentials map[string][]byte
 			if template.Spec.Provider.SecretRef != nil {
@@ -90,7 +94,15 @@ func (c *Controller) checkMetricProviderAvailability(canary *flaggerv1.Canary) e
 	return nil
 }
 
-func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
+func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary, margin *analysisMargin, score *analysisScore) (ok bool) {
+	// record the metric that halted the analysis on the target workload
+	var current string
+	defer func() {
+		if !ok && current != "" {
+			c.annotateTargetFailedMetric(canary, current)
+		}
+	}()
+
 	// override the global provider if one is specified in the canary spec
 	var metricsProvider string
 	// set the metrics provider to Crossover Prometheus when Crossover is the mesh provider
@@ -130,6 +142,7 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 
 	// run metrics checks
 	for _, metric := range canary.GetAnalysis().Metrics {
+		current = metric.Name
 		if metric.Interval == "" {
 			metric.Interval = canary.GetMetricInterval()
 		}
@@ -146,24 +159,30 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 				}
 				return false
 			}
+			c.recordMetricResult(canary, metric.Name, val)
 
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
 				if tr.Min != nil && val < *tr.Min {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% < %v%%",
-						canary.Name, canary.Namespace, val, *tr.Min)
-					return false
+					if c.metricFailed(canary, score, metric, "success rate %.2f%% < %v%%", val, *tr.Min) {
+						return false
+					}
+					continue
 				}
 				if tr.Max != nil && val > *tr.Max {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% > %v%%",
-						canary.Name, canary.Namespace, val, *tr.Max)
-					return false
+					if c.metricFailed(canary, score, metric, "success rate %.2f%% > %v%%", val, *tr.Max) {
+						return false
+					}
+					continue
 				}
 			} else if metric.Threshold > val {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% < %v%%",
-					canary.Name, canary.Namespace, val, metric.Threshold)
-				return false
+				if c.metricFailed(canary, score, metric, "success rate %.2f%% < %v%%", val, metric.Threshold) {
+					return false
+				}
+				continue
 			}
+			margin.observe(metric, val)
+			score.pass(metric)
 		}
 
 		if metric.Name == "request-duration" {
@@ -177,23 +196,29 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 				}
 				return false
 			}
+			c.recordMetricResult(canary, metric.Name, float64(val)/float64(time.Millisecond))
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
 				if tr.Min != nil && val < time.Duration(*tr.Min)*time.Millisecond {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v < %v",
-						canary.Name, canary.Namespace, val, time.Duration(*tr.Min)*time.Millisecond)
-					return false
+					if c.metricFailed(canary, score, metric, "request duration %v < %v", val, time.Duration(*tr.Min)*time.Millisecond) {
+						return false
+					}
+					continue
 				}
 				if tr.Max != nil && val > time.Duration(*tr.Max)*time.Millisecond {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v > %v",
-						canary.Name, canary.Namespace, val, time.Duration(*tr.Max)*time.Millisecond)
-					return false
+					if c.metricFailed(canary, score, metric, "request duration %v > %v", val, time.Duration(*tr.Max)*time.Millisecond) {
+						return false
+					}
+					continue
 				}
 			} else if val > time.Duration(metric.Threshold)*time.Millisecond {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v > %v",
-					canary.Name, canary.Namespace, val, time.Duration(metric.Threshold)*time.Millisecond)
-				return false
+				if c.metricFailed(canary, score, metric, "request duration %v > %v", val, time.Duration(metric.Threshold)*time.Millisecond) {
+					return false
+				}
+				continue
 			}
+			margin.observe(metric, float64(val)/float64(time.Millisecond))
+			score.pass(metric)
 		}
 
 		// in-line PromQL
@@ -209,31 +234,47 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 				}
 				return false
 			}
+			c.recordMetricResult(canary, metric.Name, val)
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
 				if tr.Min != nil && val < *tr.Min {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f < %v",
-						canary.Name, canary.Namespace, metric.Name, val, *tr.Min)
-					return false
+					if c.metricFailed(canary, score, metric, "%s %.2f < %v", metric.Name, val, *tr.Min) {
+						return false
+					}
+					continue
 				}
 				if tr.Max != nil && val > *tr.Max {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
-						canary.Name, canary.Namespace, metric.Name, val, *tr.Max)
-					return false
+					if c.metricFailed(canary, score, metric, "%s %.2f > %v", metric.Name, val, *tr.Max) {
+						return false
+					}
+					continue
 				}
 			} else if val > metric.Threshold {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
-					canary.Name, canary.Namespace, metric.Name, val, metric.Threshold)
-				return false
+				if c.metricFailed(canary, score, metric, "%s %.2f > %v", metric.Name, val, metric.Threshold) {
+					return false
+				}
+				continue
 			}
+			margin.observe(metric, val)
+			score.pass(metric)
 		}
 	}
 
-	return true
+	current = ""
+	return c.runSourceMetricChecks(canary, observer)
 }
 
-func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
+func (c *Controller) runMetricChecks(canary *flaggerv1.Canary, margin *analysisMargin, score *analysisScore) (ok bool) {
+	// record the metric that halted the analysis on the target workload
+	var current string
+	defer func() {
+		if !ok && current != "" {
+			c.annotateTargetFailedMetric(canary, current)
+		}
+	}()
+
 	for _, metric := range canary.GetAnalysis().Metrics {
+		current = metric.Name
 		if metric.TemplateRef != nil {
 			namespace := canary.Namespace
 			if metric.TemplateRef.Namespace != "" {
@@ -245,6 +286,11 @@ func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
 				c.recordEventErrorf(canary, "Metric template %s.%s error: %v", metric.TemplateRef.Name, namespace, err)
 				return false
 			}
+			if !c.isReferenceAllowed(canary, namespace, template.Spec.AllowedNamespaces) {
+				c.recordEventErrorf(canary, "Metric template %s.%s can't be referenced from namespace %s",
+					metric.TemplateRef.Name, namespace, canary.Namespace)
+				return false
+			}
 
 			var credentials map[string][]byte
 			if template.Spec.Provider.SecretRef != nil {
@@ -272,7 +318,12 @@ func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
 				return false
 			}
 
-			val, err := provider.RunQuery(query)
+			var val float64
+			if metric.Sampling != nil {
+				val, err = runSampledQuery(provider, query, metric)
+			} else {
+				val, err = provider.RunQuery(query)
+			}
 			if err != nil {
 				if errors.Is(err, providers.ErrNoValuesFound) {
 					c.recordEventWarningf(canary, "Halt advancement no values found for custom metric: %s: %v",
@@ -282,24 +333,46 @@ func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
 				}
 				return false
 			}
+			c.recordMetricResult(canary, metric.Name, val)
+
+			if isComparedMetric(canary, metric) {
+				score.pass(metric)
+				continue
+			}
+
+			if metric.Sampling != nil && metric.Sampling.Aggregator == samplingCountAboveThreshold {
+				if int(val) > metric.Sampling.MaxBreaches {
+					if c.metricFailed(canary, score, metric, "%s %v datapoints out of threshold > %v", metric.Name, val, metric.Sampling.MaxBreaches) {
+						return false
+					}
+					continue
+				}
+				score.pass(metric)
+				continue
+			}
 
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
 				if tr.Min != nil && val < *tr.Min {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f < %v",
-						canary.Name, canary.Namespace, metric.Name, val, *tr.Min)
-					return false
+					if c.metricFailed(canary, score, metric, "%s %.2f < %v", metric.Name, val, *tr.Min) {
+						return false
+					}
+					continue
 				}
 				if tr.Max != nil && val > *tr.Max {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
-						canary.Name, canary.Namespace, metric.Name, val, *tr.Max)
-					return false
+					if c.metricFailed(canary, score, metric, "%s %.2f > %v", metric.Name, val, *tr.Max) {
+						return false
+					}
+					continue
 				}
 			} else if val > metric.Threshold {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
-					canary.Name, canary.Namespace, metric.Name, val, metric.Threshold)
-				return false
+				if c.metricFailed(canary, score, metric, "%s %.2f > %v", metric.Name, val, metric.Threshold) {
+					return false
+				}
+				continue
 			}
+			margin.observe(metric, val)
+			score.pass(metric)
 		}
 	}
 
@@ -315,12 +388,17 @@ func toMetricModel(r *flaggerv1.Canary, interval string) flaggerv1.MetricTemplat
 	if r.Spec.IngressRef != nil {
 		ingress = r.Spec.IngressRef.Name
 	}
+	route := r.Spec.TargetRef.Name
+	if r.Spec.RouteRef != nil {
+		route = r.Spec.RouteRef.Name
+	}
 	return flaggerv1.MetricTemplateModel{
 		Name:      r.Name,
 		Namespace: r.Namespace,
 		Target:    r.Spec.TargetRef.Name,
 		Service:   service,
 		Ingress:   ingress,
+		Route:     route,
 		Interval:  interval,
 	}
 }
@@ -17,10 +17,14 @@ limitations under the License.
 package controller
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -66,4 +70,134 @@ func TestController_checkMetricProviderAvailability(t *testing.T) {
 		}
 		require.NoError(t, ctrl.checkMetricProviderAvailability(canary))
 	})
+
+	t.Run("cross-namespace templateRef", func(t *testing.T) {
+		ctrl := newDeploymentFixture(nil).ctrl
+
+		analysis := &flaggerv1.CanaryAnalysis{Metrics: []flaggerv1.CanaryMetric{{
+			Name: "", TemplateRef: &flaggerv1.CrossNamespaceObjectReference{
+				Name: "envoy", Namespace: "default",
+			},
+		}}}
+		canary := &flaggerv1.Canary{
+			ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "team-a"},
+			Spec:       flaggerv1.CanarySpec{Analysis: analysis},
+		}
+
+		// ok (cross-namespace references enabled)
+		require.NoError(t, ctrl.checkMetricProviderAvailability(canary))
+
+		// error (cross-namespace references disabled)
+		ctrl.noCrossNamespaceRefs = true
+		require.Error(t, ctrl.checkMetricProviderAvailability(canary))
+
+		// ok (namespace granted)
+		template := newDeploymentTestMetricTemplate()
+		template.Spec.AllowedNamespaces = []string{"team-a"}
+		require.NoError(t, ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Update(template))
+		require.NoError(t, ctrl.checkMetricProviderAvailability(canary))
+
+		// error (namespace not granted)
+		ctrl.noCrossNamespaceRefs = false
+		template.Spec.AllowedNamespaces = []string{"team-b"}
+		require.NoError(t, ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Update(template))
+		require.Error(t, ctrl.checkMetricProviderAvailability(canary))
+	})
+}
+
+func TestMetricMargin(t *testing.T) {
+	tests := []struct {
+		metric   flaggerv1.CanaryMetric
+		val      float64
+		expected float64
+	}{
+		{flaggerv1.CanaryMetric{Name: "request-success-rate", Threshold: 99}, 99.5, 0.5},
+		{flaggerv1.CanaryMetric{Name: "request-duration", Threshold: 500}, 400, 0.2},
+		{flaggerv1.CanaryMetric{Name: "errors", ThresholdRange: &flaggerv1.CanaryThresholdRange{Min: toFloatPtr(10), Max: toFloatPtr(100)}}, 12, 0.2},
+		{flaggerv1.CanaryMetric{Name: "errors", ThresholdRange: &flaggerv1.CanaryThresholdRange{Min: toFloatPtr(0), Max: toFloatPtr(100)}}, 75, 0.25},
+	}
+	for _, tt := range tests {
+		require.InDelta(t, tt.expected, metricMargin(tt.metric, tt.val), 0.0001, tt.metric.Name)
+	}
+}
+
+func TestAggregateSamples(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 100}
+	tests := []struct {
+		aggregator string
+		expected   float64
+	}{
+		{samplingAvg, 14.5},
+		{samplingMin, 1},
+		{samplingMax, 100},
+		{samplingP95, 100},
+		{samplingCountAboveThreshold, 2},
+	}
+	for _, tt := range tests {
+		metric := flaggerv1.CanaryMetric{
+			Name:      "latency",
+			Threshold: 8,
+			Sampling:  &flaggerv1.CanaryMetricSampling{Aggregator: tt.aggregator},
+		}
+		val, err := aggregateSamples(metric, values)
+		require.NoError(t, err)
+		require.InDelta(t, tt.expected, val, 0.0001, tt.aggregator)
+	}
+
+	_, err := aggregateSamples(flaggerv1.CanaryMetric{Sampling: &flaggerv1.CanaryMetricSampling{Aggregator: "p50"}}, values)
+	require.Error(t, err)
+}
+
+func TestController_runAnalysisScoring(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	// the test metrics server returns 100 for every query
+	cd.Spec.Analysis.Metrics[0].Threshold = 101
+	mocks := newDeploymentFixture(cd)
+
+	// strict mode halts on the first metric out of its threshold
+	require.False(t, mocks.ctrl.runAnalysis(cd, &analysisMargin{}))
+
+	// one of three metrics failed
+	cd.Spec.Analysis.Scoring = &flaggerv1.CanaryScoring{Threshold: 60}
+	require.True(t, mocks.ctrl.runAnalysis(cd, &analysisMargin{}))
+	cd.Spec.Analysis.Scoring.Threshold = 75
+	require.False(t, mocks.ctrl.runAnalysis(cd, &analysisMargin{}))
+
+	// the failed metric weighs a fifth of the score
+	cd.Spec.Analysis.Metrics[1].Weight = 2
+	cd.Spec.Analysis.Metrics[2].Weight = 2
+	require.True(t, mocks.ctrl.runAnalysis(cd, &analysisMargin{}))
+}
+
+func TestController_runSourceMetricChecks(t *testing.T) {
+	// the aggregated success rate is 100%, the ingress gateway requests fail half of the time
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		val := "100"
+		if strings.Contains(r.URL.Query().Get("query"), `source_workload="istio-ingressgateway"`) {
+			val = "50"
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1,"` + val + `"]}]}}`))
+	}))
+	defer ts.Close()
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{Name: "request-success-rate", Threshold: 99, Interval: "1m"}}
+	mocks := newDeploymentFixture(cd)
+	obs, err := observers.NewFactory(ts.URL)
+	require.NoError(t, err)
+	observer := obs.Observer("istio")
+
+	// sources without a workload are not checked
+	cd.Spec.Analysis.SourceWeights = []flaggerv1.CanarySourceWeight{
+		{Gateway: "public-gateway.istio-system.svc.cluster.local", MaxWeight: 10},
+		{Gateway: "mesh", MaxWeight: 50, SourceWorkload: "frontend"},
+	}
+	require.True(t, mocks.ctrl.runSourceMetricChecks(cd, observer))
+
+	// the failing source halts the analysis
+	cd.Spec.Analysis.SourceWeights[0].SourceWorkload = "istio-ingressgateway"
+	require.False(t, mocks.ctrl.runSourceMetricChecks(cd, observer))
+
+	// observers without per-source metrics skip the checks
+	require.True(t, mocks.ctrl.runSourceMetricChecks(cd, obs.Observer("linkerd")))
 }
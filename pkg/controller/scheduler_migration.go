@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	migrationUp   = "up"
+	migrationDown = "down"
+
+	// the job name is copied to the job-name label of its pods
+	maxMigrationJobNameLength = 63
+)
+
+// runUpMigration creates the up migration job for the current canary revision,
+// it returns true when the job has completed and an error if the job failed or timed out
+func (c *Controller) runUpMigration(canary *flaggerv1.Canary) (bool, error) {
+	if canary.Spec.Migration == nil {
+		return true, nil
+	}
+
+	name := migrationJobName(canary, migrationUp)
+	job, err := c.kubeClient.BatchV1().Jobs(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		job = newMigrationJob(canary, name, canary.Spec.Migration.Up)
+		if _, err := c.kubeClient.BatchV1().Jobs(canary.Namespace).Create(context.TODO(), job, metav1.CreateOptions{}); err != nil {
+			return false, fmt.Errorf("migration job %s.%s create error: %w", name, canary.Namespace, err)
+		}
+		c.recordEventInfof(canary, "Halt %s.%s advancement waiting for migration job %s to complete",
+			canary.Name, canary.Namespace, name)
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("migration job %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, nil
+		case batchv1.JobFailed:
+			return false, fmt.Errorf("migration job %s.%s failed: %s", name, canary.Namespace, condition.Message)
+		}
+	}
+
+	// the timeout includes the time spent pending, a job whose pods can't be scheduled has no start time
+	if !job.CreationTimestamp.IsZero() && time.Since(job.CreationTimestamp.Time) > canary.GetMigrationTimeout() {
+		return false, fmt.Errorf("migration job %s.%s exceeded its timeout of %v",
+			name, canary.Namespace, canary.GetMigrationTimeout())
+	}
+
+	return false, nil
+}
+
+// runDownMigration creates the down migration job for the current canary revision
+// if an up migration job was started for the same revision
+func (c *Controller) runDownMigration(canary *flaggerv1.Canary) error {
+	if canary.Spec.Migration == nil || canary.Spec.Migration.Down == nil {
+		return nil
+	}
+
+	upName := migrationJobName(canary, migrationUp)
+	_, err := c.kubeClient.BatchV1().Jobs(canary.Namespace).Get(context.TODO(), upName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("migration job %s.%s get query error: %w", upName, canary.Namespace, err)
+	}
+
+	name := migrationJobName(canary, migrationDown)
+	_, err = c.kubeClient.BatchV1().Jobs(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		job := newMigrationJob(canary, name, *canary.Spec.Migration.Down)
+		if _, err := c.kubeClient.BatchV1().Jobs(canary.Namespace).Create(context.TODO(), job, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("migration job %s.%s create error: %w", name, canary.Namespace, err)
+		}
+		c.recordEventInfof(canary, "Down migration job %s.%s created", name, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("migration job %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+
+	return nil
+}
+
+// migrationJobName returns a job name unique to the canary revision,
+// the target name is truncated to keep the revision hash within the length limit
+func migrationJobName(canary *flaggerv1.Canary, direction string) string {
+	suffix := fmt.Sprintf("-migration-%s-%s", direction, canary.Status.LastAppliedSpec)
+	target := canary.Spec.TargetRef.Name
+	if max := maxMigrationJobNameLength - len(suffix); len(target) > max {
+		target = strings.TrimRight(target[:max], "-.")
+	}
+	return target + suffix
+}
+
+func newMigrationJob(canary *flaggerv1.Canary, name string, template batchv1.JobTemplateSpec) *batchv1.Job {
+	spec := *template.Spec.DeepCopy()
+	if spec.Template.Spec.RestartPolicy == "" {
+		spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   canary.Namespace,
+			Labels:      template.Labels,
+			Annotations: template.Annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+					Group:   flaggerv1.SchemeGroupVersion.Group,
+					Version: flaggerv1.SchemeGroupVersion.Version,
+					Kind:    flaggerv1.CanaryKind,
+				}),
+			},
+		},
+		Spec: spec,
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_DeploymentMigration(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Migration = &flaggerv1.CanaryMigration{
+		Up: batchv1.JobTemplateSpec{
+			Spec: batchv1.JobSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "migrate", Image: "migrate/migrate"}},
+					},
+				},
+			},
+		},
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// create migration job
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	jobs, err := mocks.kubeClient.BatchV1().Jobs("default").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, jobs.Items, 1)
+	job := jobs.Items[0]
+	assert.Equal(t, corev1.RestartPolicyNever, job.Spec.Template.Spec.RestartPolicy)
+
+	// halt advancement while the job is running
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Equal(t, 0, c.Status.CanaryWeight)
+
+	// complete migration
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	_, err = mocks.kubeClient.BatchV1().Jobs("default").UpdateStatus(context.TODO(), &job, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, cd.GetAnalysis().StepWeight, c.Status.CanaryWeight)
+}
+
+func TestScheduler_DeploymentMigrationFailed(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Migration = &flaggerv1.CanaryMigration{
+		Up:   batchv1.JobTemplateSpec{},
+		Down: &batchv1.JobTemplateSpec{},
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// create migration job
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	jobs, err := mocks.kubeClient.BatchV1().Jobs("default").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, jobs.Items, 1)
+
+	// fail migration
+	job := jobs.Items[0]
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+	_, err = mocks.kubeClient.BatchV1().Jobs("default").UpdateStatus(context.TODO(), &job, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// rollback
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+
+	// down migration job created
+	jobs, err = mocks.kubeClient.BatchV1().Jobs("default").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, jobs.Items, 2)
+}
+
+func TestScheduler_DeploymentMigrationTimeout(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Migration = &flaggerv1.CanaryMigration{}
+	cd.Status.LastAppliedSpec = "5f9c8b7d6f"
+	mocks := newDeploymentFixture(cd)
+
+	// a pending job has no start time
+	job := newMigrationJob(cd, migrationJobName(cd, migrationUp), cd.Spec.Migration.Up)
+	job.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * cd.GetMigrationTimeout()))
+	_, err := mocks.kubeClient.BatchV1().Jobs("default").Create(context.TODO(), job, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	ok, err := mocks.ctrl.runUpMigration(cd)
+	assert.False(t, ok)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeded its timeout")
+}
+
+func TestMigrationJobName(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Status.LastAppliedSpec = "5f9c8b7d6f"
+	assert.Equal(t, "podinfo-migration-up-5f9c8b7d6f", migrationJobName(cd, migrationUp))
+
+	cd.Spec.TargetRef.Name = strings.Repeat("a", 40) + "-" + strings.Repeat("b", 20)
+	for _, direction := range []string{migrationUp, migrationDown} {
+		name := migrationJobName(cd, direction)
+		assert.LessOrEqual(t, len(name), maxMigrationJobNameLength)
+		assert.True(t, strings.HasSuffix(name, "-migration-"+direction+"-5f9c8b7d6f"), name)
+	}
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/observers"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
+)
+
+const defaultAlertProviderTimeout = 5 * time.Second
+
+// holdOnProviderOutage returns true if the canary weight should be held because
+// all the metric providers or all the alert providers have been unreachable
+// for the configured number of consecutive intervals
+func (c *Controller) holdOnProviderOutage(canary *flaggerv1.Canary) bool {
+	policy := canary.GetAnalysis().ProviderOutage
+	if policy == nil {
+		return false
+	}
+	intervals := policy.Intervals
+	if intervals < 1 {
+		intervals = 1
+	}
+
+	reason := c.checkProviderOutage(canary)
+	previous := canary.Status.ProviderOutages
+	outages := 0
+	if reason != "" {
+		outages = previous + 1
+	}
+	if outages != previous {
+		if err := c.setStatusProviderOutages(canary, outages); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+		}
+		// keep the in-memory status in sync for the status updates of this interval
+		canary.Status.ProviderOutages = outages
+	}
+
+	if reason == "" {
+		if previous >= intervals {
+			c.recordEventInfof(canary, "Providers are reachable, resuming %s.%s analysis", canary.Name, canary.Namespace)
+		}
+		return false
+	}
+	if outages < intervals {
+		return false
+	}
+
+	c.recordEventWarningf(canary, "Hold %s.%s canary weight %s for %d intervals",
+		canary.Name, canary.Namespace, reason, outages)
+	if outages == intervals {
+		c.alert(canary, fmt.Sprintf("Canary weight held, %s", reason), false, flaggerv1.SeverityWarn)
+	}
+	return true
+}
+
+// checkProviderOutage returns the reason of the outage if all the configured metric providers
+// or all the configured alert providers are unreachable
+func (c *Controller) checkProviderOutage(canary *flaggerv1.Canary) string {
+	if total, unreachable := c.probeMetricProviders(canary); total > 0 && total == unreachable {
+		return "all metric providers are unreachable"
+	}
+	if total, unreachable := c.probeAlertProviders(canary); total > 0 && total == unreachable {
+		return "all alert providers are unreachable"
+	}
+	return ""
+}
+
+// probeMetricProviders returns the number of metric providers used by the analysis and how many are offline,
+// the providers that can't be constructed are left to the analysis to report
+func (c *Controller) probeMetricProviders(canary *flaggerv1.Canary) (total int, unreachable int) {
+	builtin := false
+	for _, metric := range canary.GetAnalysis().Metrics {
+		if metric.Name == "request-success-rate" || metric.Name == "request-duration" {
+			if builtin {
+				continue
+			}
+			builtin = true
+
+			observerFactory := c.observerFactory
+			if canary.Spec.MetricsServer != "" {
+				var err error
+				observerFactory, err = observers.NewFactory(canary.Spec.MetricsServer)
+				if err != nil {
+					continue
+				}
+			}
+			total++
+			if ok, err := observerFactory.Client.IsOnline(); !ok || err != nil {
+				unreachable++
+			}
+			continue
+		}
+
+		if metric.TemplateRef != nil {
+			namespace := canary.Namespace
+			if metric.TemplateRef.Namespace != "" {
+				namespace = metric.TemplateRef.Namespace
+			}
+
+			template, err := c.flaggerInformers.MetricInformer.Lister().MetricTemplates(namespace).Get(metric.TemplateRef.Name)
+			if err != nil || !c.isReferenceAllowed(canary, namespace, template.Spec.AllowedNamespaces) {
+				continue
+			}
+
+			var credentials map[string][]byte
+			if template.Spec.Provider.SecretRef != nil {
+				secret, err := c.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), template.Spec.Provider.SecretRef.Name, metav1.GetOptions{})
+				if err != nil {
+					continue
+				}
+				credentials = secret.Data
+			}
+
+			factory := providers.Factory{}
+			provider, err := factory.Provider(metric.Interval, template.Spec.Provider, credentials)
+			if err != nil {
+				continue
+			}
+			total++
+			if ok, err := provider.IsOnline(); !ok || err != nil {
+				unreachable++
+			}
+		}
+	}
+	return
+}
+
+// probeAlertProviders returns the number of alert providers referenced by the analysis
+// and how many of their addresses can't be dialed
+func (c *Controller) probeAlertProviders(canary *flaggerv1.Canary) (total int, unreachable int) {
+	for _, alert := range canary.GetAnalysis().Alerts {
+		_, address, err := c.getAlertProvider(canary, alert)
+		if err != nil {
+			continue
+		}
+		total++
+		if err := dialAddress(address, defaultAlertProviderTimeout); err != nil {
+			unreachable++
+		}
+	}
+	return
+}
+
+// dialAddress opens a TCP connection to the host of the URL
+func dialAddress(address string, timeout time.Duration) error {
+	u, err := url.Parse(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %w", address, err)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	conn, err := net.DialTimeout("tcp", host, timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (c *Controller) setStatusProviderOutages(cd *flaggerv1.Canary, outages int) error {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := cd.DeepCopy()
+		cdCopy.Status.ProviderOutages = outages
+		_, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).UpdateStatus(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		firstTry = false
+		return
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// previewLabel marks the objects of a canary preview stack
+const previewLabel = "flagger.app/preview"
+
+// reconcileCanaryPreview creates the canary preview stack while the analysis is running
+// and tears it down once the canary is promoted or rolled back
+func (c *Controller) reconcileCanaryPreview(canary *flaggerv1.Canary) error {
+	if canary.Spec.Preview == nil {
+		return nil
+	}
+
+	switch canary.Status.Phase {
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaitingPromotion:
+		if err := c.publishPreviewIngress(canary); err != nil {
+			return err
+		}
+		return c.createPreviewStubs(canary)
+	default:
+		return c.removeCanaryPreview(canary)
+	}
+}
+
+func (c *Controller) publishPreviewIngress(canary *flaggerv1.Canary) error {
+	apexName, _, canaryName := canary.GetServiceNames()
+	name := fmt.Sprintf("%s-preview", apexName)
+	pathType := netv1.PathTypePrefix
+	spec := netv1.IngressSpec{
+		Rules: []netv1.IngressRule{
+			{
+				Host: canary.Spec.Preview.Host,
+				IngressRuleValue: netv1.IngressRuleValue{
+					HTTP: &netv1.HTTPIngressRuleValue{
+						Paths: []netv1.HTTPIngressPath{
+							{
+								Path:     "/",
+								PathType: &pathType,
+								Backend: netv1.IngressBackend{
+									Service: &netv1.IngressServiceBackend{
+										Name: canaryName,
+										Port: netv1.ServiceBackendPort{Number: canary.Spec.Service.Port},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if canary.Spec.Preview.IngressClassName != "" {
+		spec.IngressClassName = &canary.Spec.Preview.IngressClassName
+	}
+
+	ingress, err := c.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		ingress = &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       canary.Namespace,
+				Labels:          map[string]string{previewLabel: canary.Name},
+				OwnerReferences: []metav1.OwnerReference{*previewOwnerRef(canary)},
+			},
+			Spec: spec,
+		}
+		_, err = c.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Create(context.TODO(), ingress, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("ingress %s.%s create error: %w", name, canary.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Ingress %s.%s created for %s", name, canary.Namespace, canary.Spec.Preview.Host)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("ingress %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+
+	if cmp.Diff(spec, ingress.Spec) != "" {
+		clone := ingress.DeepCopy()
+		clone.Spec = spec
+		_, err = c.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("ingress %s.%s update error: %w", name, canary.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Ingress %s.%s updated", name, canary.Namespace)
+	}
+	return nil
+}
+
+// createPreviewStubs creates the dependency stubs defined in the stubs config map,
+// the stubs are created once and are not updated for the duration of the analysis
+func (c *Controller) createPreviewStubs(canary *flaggerv1.Canary) error {
+	ref := canary.Spec.Preview.StubsRef
+	if ref == nil {
+		return nil
+	}
+
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = canary.Namespace
+	}
+	if !c.isReferenceAllowed(canary, namespace, nil) {
+		return fmt.Errorf("preview stubs %s.%s cross-namespace reference is not allowed", ref.Name, namespace)
+	}
+
+	cm, err := c.kubeClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("preview stubs %s.%s get query error: %w", ref.Name, namespace, err)
+	}
+
+	objects, err := decodePreviewStubs(cm)
+	if err != nil {
+		return fmt.Errorf("preview stubs %s.%s decode error: %w", ref.Name, namespace, err)
+	}
+
+	for _, obj := range objects {
+		if err := c.createPreviewStub(canary, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Controller) createPreviewStub(canary *flaggerv1.Canary, obj runtime.Object) error {
+	var existing metav1.Object
+	var err error
+	ctx := context.TODO()
+
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		setPreviewMetadata(canary, o)
+		existing, err = c.kubeClient.AppsV1().Deployments(canary.Namespace).Get(ctx, o.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			_, err = c.kubeClient.AppsV1().Deployments(canary.Namespace).Create(ctx, o, metav1.CreateOptions{})
+			return c.previewStubCreated(canary, "Deployment", o.Name, err)
+		}
+	case *corev1.Service:
+		setPreviewMetadata(canary, o)
+		existing, err = c.kubeClient.CoreV1().Services(canary.Namespace).Get(ctx, o.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			_, err = c.kubeClient.CoreV1().Services(canary.Namespace).Create(ctx, o, metav1.CreateOptions{})
+			return c.previewStubCreated(canary, "Service", o.Name, err)
+		}
+	case *corev1.ConfigMap:
+		setPreviewMetadata(canary, o)
+		existing, err = c.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Get(ctx, o.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			_, err = c.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Create(ctx, o, metav1.CreateOptions{})
+			return c.previewStubCreated(canary, "ConfigMap", o.Name, err)
+		}
+	default:
+		return fmt.Errorf("preview stub kind %s is not supported", obj.GetObjectKind().GroupVersionKind().Kind)
+	}
+
+	name := obj.(metav1.Object).GetName()
+	if err != nil {
+		return fmt.Errorf("preview stub %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+	// refuse to take over objects that are not part of the preview stack
+	if existing.GetLabels()[previewLabel] != canary.Name {
+		return fmt.Errorf("preview stub %s.%s conflicts with an existing object", name, canary.Namespace)
+	}
+	return nil
+}
+
+func (c *Controller) previewStubCreated(canary *flaggerv1.Canary, kind, name string, err error) error {
+	if err != nil {
+		return fmt.Errorf("preview stub %s %s.%s create error: %w", kind, name, canary.Namespace, err)
+	}
+	c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("Preview stub %s %s.%s created", kind, name, canary.Namespace)
+	return nil
+}
+
+// removeCanaryPreview deletes the preview ingress and stubs
+func (c *Controller) removeCanaryPreview(canary *flaggerv1.Canary) error {
+	ctx := context.TODO()
+	apexName, _, _ := canary.GetServiceNames()
+	name := fmt.Sprintf("%s-preview", apexName)
+	err := c.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("ingress %s.%s delete error: %w", name, canary.Namespace, err)
+	}
+
+	if canary.Spec.Preview == nil || canary.Spec.Preview.StubsRef == nil {
+		return nil
+	}
+
+	opts := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", previewLabel, canary.Name)}
+	deployments, err := c.kubeClient.AppsV1().Deployments(canary.Namespace).List(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("preview stubs deployments %s list error: %w", canary.Namespace, err)
+	}
+	for _, item := range deployments.Items {
+		err := c.kubeClient.AppsV1().Deployments(canary.Namespace).Delete(ctx, item.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("preview stub deployment %s.%s delete error: %w", item.Name, canary.Namespace, err)
+		}
+	}
+	services, err := c.kubeClient.CoreV1().Services(canary.Namespace).List(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("preview stubs services %s list error: %w", canary.Namespace, err)
+	}
+	for _, item := range services.Items {
+		err := c.kubeClient.CoreV1().Services(canary.Namespace).Delete(ctx, item.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("preview stub service %s.%s delete error: %w", item.Name, canary.Namespace, err)
+		}
+	}
+	configMaps, err := c.kubeClient.CoreV1().ConfigMaps(canary.Namespace).List(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("preview stubs config maps %s list error: %w", canary.Namespace, err)
+	}
+	for _, item := range configMaps.Items {
+		err := c.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Delete(ctx, item.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("preview stub config map %s.%s delete error: %w", item.Name, canary.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// decodePreviewStubs decodes the manifests stored in the config map data, sorted by key
+func decodePreviewStubs(cm *corev1.ConfigMap) ([]runtime.Object, error) {
+	keys := make([]string, 0, len(cm.Data))
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	decoder := scheme.Codecs.UniversalDeserializer()
+	var objects []runtime.Object
+	for _, key := range keys {
+		for _, doc := range strings.Split(cm.Data[key], "\n---") {
+			if strings.TrimSpace(doc) == "" {
+				continue
+			}
+			obj, _, err := decoder.Decode([]byte(doc), nil, nil)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+func setPreviewMetadata(canary *flaggerv1.Canary, obj metav1.Object) {
+	obj.SetNamespace(canary.Namespace)
+	obj.SetResourceVersion("")
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[previewLabel] = canary.Name
+	obj.SetLabels(labels)
+	obj.SetOwnerReferences([]metav1.OwnerReference{*previewOwnerRef(canary)})
+}
+
+func previewOwnerRef(canary *flaggerv1.Canary) *metav1.OwnerReference {
+	return metav1.NewControllerRef(canary, schema.GroupVersionKind{
+		Group:   flaggerv1.SchemeGroupVersion.Group,
+		Version: flaggerv1.SchemeGroupVersion.Version,
+		Kind:    flaggerv1.CanaryKind,
+	})
+}
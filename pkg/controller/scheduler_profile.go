@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// rollout profile ConfigMap keys
+const (
+	profileStepWeightsKey = "stepWeights"
+	profileIntervalKey    = "interval"
+	profileThresholdKey   = "threshold"
+)
+
+// applyAnalysisProfile overrides the canary pacing with the values of the referenced
+// rollout profile, the canary must be a copy that's never written back to the API
+func (c *Controller) applyAnalysisProfile(canary *flaggerv1.Canary) error {
+	analysis := canary.GetAnalysis()
+	if analysis == nil || analysis.ProfileRef == nil {
+		return nil
+	}
+
+	namespace := canary.Namespace
+	if analysis.ProfileRef.Namespace != "" {
+		namespace = analysis.ProfileRef.Namespace
+	}
+
+	cm, err := c.kubeClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), analysis.ProfileRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("rollout profile %s.%s get query error: %w", analysis.ProfileRef.Name, namespace, err)
+	}
+
+	if v, ok := cm.Data[profileStepWeightsKey]; ok {
+		weights, err := parseStepWeights(v)
+		if err != nil {
+			return fmt.Errorf("rollout profile %s.%s %s is invalid: %w", cm.Name, namespace, profileStepWeightsKey, err)
+		}
+		analysis.StepWeights = weights
+		analysis.StepWeight = 0
+	}
+
+	if v, ok := cm.Data[profileIntervalKey]; ok {
+		if _, err := time.ParseDuration(v); err != nil {
+			return fmt.Errorf("rollout profile %s.%s %s is invalid: %w", cm.Name, namespace, profileIntervalKey, err)
+		}
+		analysis.Interval = v
+	}
+
+	if v, ok := cm.Data[profileThresholdKey]; ok {
+		threshold, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || threshold < 1 {
+			return fmt.Errorf("rollout profile %s.%s %s must be a positive integer", cm.Name, namespace, profileThresholdKey)
+		}
+		analysis.Threshold = threshold
+	}
+
+	return nil
+}
+
+// analysisInterval returns the schedule interval of the canary, the interval
+// of the rollout profile is used if the profile can be loaded
+func (c *Controller) analysisInterval(canary *flaggerv1.Canary) time.Duration {
+	if canary.GetAnalysis() == nil || canary.GetAnalysis().ProfileRef == nil {
+		return canary.GetAnalysisInterval()
+	}
+
+	cd := canary.DeepCopy()
+	if err := c.applyAnalysisProfile(cd); err != nil {
+		return canary.GetAnalysisInterval()
+	}
+	return cd.GetAnalysisInterval()
+}
+
+// parseStepWeights parses a comma or space separated list of traffic weights
+func parseStepWeights(value string) ([]int, error) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no weights found")
+	}
+
+	weights := make([]int, 0, len(fields))
+	for _, f := range fields {
+		w, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("weight %q is not an integer", f)
+		}
+		if w < 1 || w > 100 {
+			return nil, fmt.Errorf("weight %v is not in the range [1, 100]", w)
+		}
+		if len(weights) > 0 && w <= weights[len(weights)-1] {
+			return nil, fmt.Errorf("weight %v is not greater than the previous step", w)
+		}
+		weights = append(weights, w)
+	}
+	return weights, nil
+}
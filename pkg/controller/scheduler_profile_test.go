@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_AnalysisProfile(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.ProfileRef = &flaggerv1.CanaryProfileReference{Name: "fleet-pacing", Namespace: "flagger-system"}
+	mocks := newDeploymentFixture(cd)
+
+	profile := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "fleet-pacing", Namespace: "flagger-system"},
+		Data: map[string]string{
+			"stepWeights": "5, 20, 50",
+			"interval":    "30s",
+			"threshold":   "3",
+		},
+	}
+	_, err := mocks.kubeClient.CoreV1().ConfigMaps("flagger-system").Create(context.TODO(), profile, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	c := cd.DeepCopy()
+	require.NoError(t, mocks.ctrl.applyAnalysisProfile(c))
+	assert.Equal(t, []int{5, 20, 50}, c.GetAnalysis().StepWeights)
+	assert.Equal(t, 30*time.Second, c.GetAnalysisInterval())
+	assert.Equal(t, 3, c.GetAnalysisThreshold())
+	assert.Equal(t, 30*time.Second, mocks.ctrl.analysisInterval(cd))
+
+	// the canary spec is left untouched
+	assert.Empty(t, cd.GetAnalysis().StepWeights)
+
+	// hot-swap the profile
+	profile.Data["stepWeights"] = "10,100"
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("flagger-system").Update(context.TODO(), profile, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	c = cd.DeepCopy()
+	require.NoError(t, mocks.ctrl.applyAnalysisProfile(c))
+	assert.Equal(t, []int{10, 100}, c.GetAnalysis().StepWeights)
+
+	// reject invalid weights
+	profile.Data["stepWeights"] = "10,150"
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("flagger-system").Update(context.TODO(), profile, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Error(t, mocks.ctrl.applyAnalysisProfile(cd.DeepCopy()))
+	_, err = parseStepWeights("20,10")
+	require.EqualError(t, err, "weight 10 is not greater than the previous step")
+	assert.Equal(t, cd.GetAnalysisInterval(), mocks.ctrl.analysisInterval(cd))
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+)
+
+// runRingsCheck returns false if the canary belongs to a ring rollout
+// and the canaries of the previous rings haven't succeeded or are still baking,
+// the held canary is set to waiting so that it holds the next rings in turn
+func (c *Controller) runRingsCheck(canary *flaggerv1.Canary, canaryController canary.Controller) bool {
+	rollouts, err := c.flaggerClient.FlaggerV1beta1().RingRollouts(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if errors.IsNotFound(err) {
+		// the RingRollout CRD is not installed
+		return true
+	}
+	if err != nil {
+		c.recordEventErrorf(canary, "Ring rollouts list for %s.%s failed: %v", canary.Name, canary.Namespace, err)
+		return false
+	}
+
+	for _, rollout := range rollouts.Items {
+		index := ringIndex(rollout, canary)
+		for _, ring := range rollout.Spec.Rings[:index] {
+			if err := c.isRingComplete(rollout, ring); err != nil {
+				if canary.Status.Phase != flaggerv1.CanaryPhaseWaiting {
+					if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseWaiting); err != nil {
+						c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
+					}
+				}
+				c.recordEventWarningf(canary, "Halt %s.%s advancement ring rollout %s.%s %v",
+					canary.Name, canary.Namespace, rollout.Name, rollout.Namespace, err)
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isRingComplete returns an error if a canary of the ring hasn't succeeded
+// or if the ring bake time hasn't passed since the last canary succeeded
+func (c *Controller) isRingComplete(rollout flaggerv1.RingRollout, ring flaggerv1.Ring) error {
+	var completed time.Time
+	for _, ref := range ring.Canaries {
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = rollout.Namespace
+		}
+		cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("ring %s canary %s.%s error: %w", ring.Name, ref.Name, namespace, err)
+		}
+		if !cd.Status.Phase.IsPromoted() && cd.Status.Phase != flaggerv1.CanaryPhaseInitialized {
+			return fmt.Errorf("waiting for ring %s canary %s.%s phase %s", ring.Name, ref.Name, namespace, cd.Status.Phase)
+		}
+		if t := cd.Status.LastTransitionTime.Time; t.After(completed) {
+			completed = t
+		}
+	}
+
+	if remaining := time.Until(completed.Add(ring.GetBakeTime())); remaining > 0 {
+		return fmt.Errorf("waiting for ring %s to bake for %v", ring.Name, remaining.Round(time.Second))
+	}
+	return nil
+}
+
+// ringIndex returns the position of the canary ring in the rollout,
+// zero means the canary is in the first ring or it doesn't belong to the rollout
+func ringIndex(rollout flaggerv1.RingRollout, canary *flaggerv1.Canary) int {
+	for i, ring := range rollout.Spec.Rings {
+		for _, ref := range ring.Canaries {
+			namespace := ref.Namespace
+			if namespace == "" {
+				namespace = rollout.Namespace
+			}
+			if ref.Name == canary.Name && namespace == canary.Namespace {
+				return i
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
+)
+
+const (
+	samplingAvg                 = "avg"
+	samplingMin                 = "min"
+	samplingMax                 = "max"
+	samplingP95                 = "p95"
+	samplingCountAboveThreshold = "count-above-threshold"
+)
+
+// runSampledQuery fetches the datapoints of the metric interval
+// and reduces them to a single value with the sampling aggregator
+func runSampledQuery(provider providers.Interface, query string, metric flaggerv1.CanaryMetric) (float64, error) {
+	rangeProvider, ok := provider.(providers.RangeInterface)
+	if !ok {
+		return 0, fmt.Errorf("metric provider does not support sampling")
+	}
+
+	interval, step, err := samplingRange(metric)
+	if err != nil {
+		return 0, err
+	}
+
+	values, err := rangeProvider.RunRangeQuery(query, interval, step)
+	if err != nil {
+		return 0, err
+	}
+	return aggregateSamples(metric, values)
+}
+
+// samplingRange returns the metric interval and the step between its datapoints,
+// the step defaults to a tenth of the interval
+func samplingRange(metric flaggerv1.CanaryMetric) (time.Duration, time.Duration, error) {
+	interval, err := time.ParseDuration(metric.Interval)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid metric interval %s: %w", metric.Interval, err)
+	}
+	step := interval / 10
+	if metric.Sampling != nil && metric.Sampling.Step != "" {
+		step, err = time.ParseDuration(metric.Sampling.Step)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid sampling step %s: %w", metric.Sampling.Step, err)
+		}
+	}
+	if step < time.Second {
+		step = time.Second
+	}
+	return interval, step, nil
+}
+
+// aggregateSamples reduces the datapoints to a single value,
+// count-above-threshold returns the number of datapoints outside the threshold
+func aggregateSamples(metric flaggerv1.CanaryMetric, values []float64) (float64, error) {
+	if len(values) == 0 {
+		return 0, fmt.Errorf("%w", providers.ErrNoValuesFound)
+	}
+
+	switch metric.Sampling.Aggregator {
+	case samplingAvg:
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values)), nil
+	case samplingMin:
+		min := math.Inf(1)
+		for _, v := range values {
+			min = math.Min(min, v)
+		}
+		return min, nil
+	case samplingMax:
+		max := math.Inf(-1)
+		for _, v := range values {
+			max = math.Max(max, v)
+		}
+		return max, nil
+	case samplingP95:
+		sorted := append([]float64(nil), values...)
+		sort.Float64s(sorted)
+		i := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+		return sorted[i], nil
+	case samplingCountAboveThreshold:
+		count := 0
+		for _, v := range values {
+			if !withinThreshold(metric, v) {
+				count++
+			}
+		}
+		return float64(count), nil
+	default:
+		return 0, fmt.Errorf("sampling aggregator %s not supported", metric.Sampling.Aggregator)
+	}
+}
+
+func withinThreshold(metric flaggerv1.CanaryMetric, val float64) bool {
+	if tr := metric.ThresholdRange; tr != nil {
+		return (tr.Min == nil || val >= *tr.Min) && (tr.Max == nil || val <= *tr.Max)
+	}
+	return val <= metric.Threshold
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// analysisScore tracks the weights of the metrics that passed or failed their threshold checks
+type analysisScore struct {
+	scoring *flaggerv1.CanaryScoring
+	passed  float64
+	failed  float64
+}
+
+// newAnalysisScore returns the score of the canary analysis, nil when scoring is disabled
+func newAnalysisScore(canary *flaggerv1.Canary) *analysisScore {
+	if canary.GetAnalysis().Scoring == nil {
+		return nil
+	}
+	return &analysisScore{scoring: canary.GetAnalysis().Scoring}
+}
+
+func metricWeight(metric flaggerv1.CanaryMetric) float64 {
+	if metric.Weight > 0 {
+		return metric.Weight
+	}
+	return 1
+}
+
+// pass records a metric within its thresholds
+func (s *analysisScore) pass(metric flaggerv1.CanaryMetric) {
+	if s == nil {
+		return
+	}
+	s.passed += metricWeight(metric)
+}
+
+// value returns the share of the metric weights that passed, in percent
+func (s *analysisScore) value() float64 {
+	if s.passed+s.failed == 0 {
+		return 100
+	}
+	return s.passed / (s.passed + s.failed) * 100
+}
+
+// metricFailed records a metric out of its thresholds and returns true if the advancement is halted,
+// when scoring is enabled the metric lowers the score and the checks move on to the next metric
+func (c *Controller) metricFailed(canary *flaggerv1.Canary, score *analysisScore, metric flaggerv1.CanaryMetric,
+	format string, a ...interface{}) bool {
+	msg := fmt.Sprintf(format, a...)
+	if score == nil {
+		c.recordEventWarningf(canary, "Halt %s.%s advancement %s", canary.Name, canary.Namespace, msg)
+		return true
+	}
+	score.failed += metricWeight(metric)
+	c.recordEventInfof(canary, "Metric %s of %s.%s out of threshold with weight %v %s",
+		metric.Name, canary.Name, canary.Namespace, metricWeight(metric), msg)
+	return false
+}
+
+// checkScore returns true if the weighted score of the metric checks reached the threshold
+func (c *Controller) checkScore(canary *flaggerv1.Canary, score *analysisScore) bool {
+	if score == nil {
+		return true
+	}
+	if v := score.value(); v < score.scoring.Threshold {
+		c.recordEventWarningf(canary, "Halt %s.%s advancement metrics score %.2f%% < %v%%",
+			canary.Name, canary.Namespace, v, score.scoring.Threshold)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/observers"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
+)
+
+// runSourceMetricChecks evaluates the builtin metrics for each traffic source
+// that has a source workload, a source that fails its thresholds halts the analysis
+// even if the aggregated metrics are within range
+func (c *Controller) runSourceMetricChecks(canary *flaggerv1.Canary, observer observers.Interface) bool {
+	so, ok := observer.(observers.SourceObserver)
+	if !ok {
+		return true
+	}
+
+	for _, source := range canary.GetAnalysis().SourceWeights {
+		if source.SourceWorkload == "" {
+			continue
+		}
+		for _, metric := range canary.GetAnalysis().Metrics {
+			if metric.Name != "request-success-rate" && metric.Name != "request-duration" {
+				continue
+			}
+			if metric.Interval == "" {
+				metric.Interval = canary.GetMetricInterval()
+			}
+			model := toMetricModel(canary, metric.Interval)
+			model.Source = source.SourceWorkload
+			name := fmt.Sprintf("%s/%s", metric.Name, source.Gateway)
+
+			var val float64
+			var err error
+			if metric.Name == "request-success-rate" {
+				val, err = so.GetSourceRequestSuccessRate(model)
+			} else {
+				var d time.Duration
+				d, err = so.GetSourceRequestDuration(model)
+				val = float64(d) / float64(time.Millisecond)
+			}
+			if err != nil {
+				// a source that is not sending traffic to the canary has nothing to check
+				if errors.Is(err, providers.ErrNoValuesFound) {
+					continue
+				}
+				c.recordEventErrorf(canary, "Prometheus query failed for %s: %v", name, err)
+				return false
+			}
+			c.recordMetricResult(canary, name, val)
+
+			if msg := sourceMetricOutOfRange(metric, val); msg != "" {
+				c.metricFailed(canary, nil, metric, "%s %s", name, msg)
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sourceMetricOutOfRange returns a description of the threshold breach or an empty string
+func sourceMetricOutOfRange(metric flaggerv1.CanaryMetric, val float64) string {
+	unit := "%"
+	if metric.Name == "request-duration" {
+		unit = "ms"
+	}
+	if metric.ThresholdRange != nil {
+		tr := *metric.ThresholdRange
+		if tr.Min != nil && val < *tr.Min {
+			return fmt.Sprintf("%.2f%s < %v%s", val, unit, *tr.Min, unit)
+		}
+		if tr.Max != nil && val > *tr.Max {
+			return fmt.Sprintf("%.2f%s > %v%s", val, unit, *tr.Max, unit)
+		}
+		return ""
+	}
+	if metric.Name == "request-success-rate" && val < metric.Threshold {
+		return fmt.Sprintf("%.2f%s < %v%s", val, unit, metric.Threshold, unit)
+	}
+	if metric.Name == "request-duration" && val > metric.Threshold {
+		return fmt.Sprintf("%.2f%s > %v%s", val, unit, metric.Threshold, unit)
+	}
+	return ""
+}
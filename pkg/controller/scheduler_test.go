@@ -24,6 +24,8 @@ import (
 	"os"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 
@@ -60,6 +62,36 @@ func assertPhase(flaggerClient clientset.Interface, canary string, phase flagger
 	return nil
 }
 
+func TestController_NextStepWeight(t *testing.T) {
+	c := &Controller{}
+	cd := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{StepWeights: []int{1, 5, 20, 50, 80}},
+		},
+	}
+
+	tests := []struct {
+		weight int
+		step   int
+	}{
+		{weight: 0, step: 1},
+		{weight: 1, step: 4},
+		{weight: 20, step: 30},
+		// the current weight of a previous array moves to the next step
+		{weight: 30, step: 20},
+		// the last step moves to promotion
+		{weight: 80, step: 20},
+		{weight: 100, step: 1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.step, c.nextStepWeight(cd, tt.weight), "weight %v", tt.weight)
+	}
+
+	// stepWeight takes precedence over the array
+	cd.Spec.Analysis.StepWeight = 10
+	assert.Equal(t, 10, c.nextStepWeight(cd, 20))
+}
+
 func alwaysReady() bool {
 	return true
 }
@@ -0,0 +1,264 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+	"github.com/fluxcd/flagger/pkg/router"
+)
+
+const defaultTimeSliceMaxRegression = 10.0
+
+// runTimeSlices routes all the traffic to the canary and to the primary in alternate time windows,
+// the metrics of each cohort are measured at the end of its windows and compared after the last one
+func (c *Controller) runTimeSlices(cd *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) {
+	slices := cd.GetAnalysis().TimeSlices
+	window, err := time.ParseDuration(slices.Window)
+	if err != nil {
+		c.recordEventErrorf(cd, "Invalid time slice window %s: %v", slices.Window, err)
+		return
+	}
+	windows := 2 * slices.Slices
+
+	// the experiment is completed, wait for the promotion gate
+	if cd.Status.Iterations > windows {
+		c.promoteTimeSlices(cd, canaryController, meshRouter)
+		return
+	}
+
+	// start the experiment with a canary time slice
+	state := cd.Status.TimeSlice
+	if cd.Status.Iterations == 0 || state == nil {
+		c.startTimeSlice(cd, canaryController, meshRouter, &flaggerv1.CanaryTimeSliceStatus{
+			Window: 1,
+			Cohort: flaggerv1.TimeSliceCohortCanary,
+		})
+		return
+	}
+
+	if time.Since(state.StartedAt.Time) < window {
+		return
+	}
+
+	next := state.DeepCopy()
+	next.Results = append(next.Results, flaggerv1.CanaryTimeSliceResult{
+		Window:  state.Window,
+		Cohort:  state.Cohort,
+		Metrics: c.measureTimeSlice(cd, state.Cohort, slices.Window),
+	})
+
+	if state.Window < windows {
+		next.Window++
+		next.Cohort = flaggerv1.TimeSliceCohortCanary
+		if state.Cohort == flaggerv1.TimeSliceCohortCanary {
+			next.Cohort = flaggerv1.TimeSliceCohortPrimary
+		}
+		c.startTimeSlice(cd, canaryController, meshRouter, next)
+		return
+	}
+
+	// all time slices completed, compare the cohorts
+	if err := c.setStatusTimeSlice(cd, next); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	cd.Status.TimeSlice = next
+
+	if err := compareTimeSlices(cd, next.Results); err != nil {
+		c.recordEventWarningf(cd, "Rolling back %s.%s time-sliced experiment failed %v", cd.Name, cd.Namespace, err)
+		c.alert(cd, fmt.Sprintf("Time-sliced experiment failed %v", err), false, flaggerv1.SeverityError)
+		c.rollback(cd, canaryController, meshRouter, rollbackTimeSlices)
+		return
+	}
+	c.recordEventInfof(cd, "Time-sliced experiment of %s.%s passed after %v time slices", cd.Name, cd.Namespace, windows)
+
+	if err := canaryController.SetStatusIterations(cd, windows+1); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	cd.Status.Iterations = windows + 1
+	c.promoteTimeSlices(cd, canaryController, meshRouter)
+}
+
+// startTimeSlice routes all the traffic to the cohort of the time slice
+func (c *Controller) startTimeSlice(cd *flaggerv1.Canary, canaryController canary.Controller,
+	meshRouter router.Interface, state *flaggerv1.CanaryTimeSliceStatus) {
+	primaryWeight, canaryWeight := c.totalWeight(cd), 0
+	if state.Cohort == flaggerv1.TimeSliceCohortCanary {
+		primaryWeight, canaryWeight = 0, c.totalWeight(cd)
+	}
+	if err := meshRouter.SetRoutes(cd, primaryWeight, canaryWeight, false); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	c.recorder.SetWeight(cd, primaryWeight, canaryWeight)
+
+	state.StartedAt = metav1.Now()
+	if err := c.setStatusTimeSlice(cd, state); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	cd.Status.TimeSlice = state
+
+	if err := canaryController.SetStatusIterations(cd, state.Window); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	c.recordEventInfof(cd, "Advance %s.%s time slice %v/%v routing all traffic to %s",
+		cd.Name, cd.Namespace, state.Window, 2*cd.GetAnalysis().TimeSlices.Slices, state.Cohort)
+}
+
+// promoteTimeSlices promotes the canary once the promotion gate is open
+func (c *Controller) promoteTimeSlices(cd *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) {
+	if promote := c.runConfirmPromotionHooks(cd, canaryController, meshRouter); !promote {
+		return
+	}
+
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	c.recordEventInfof(cd, "Copying %s.%s template spec to %s.%s",
+		cd.Spec.TargetRef.Name, cd.Namespace, primaryName, cd.Namespace)
+	if err := canaryController.Promote(cd); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+
+	if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhasePromoting); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+	}
+}
+
+// measureTimeSlice runs the analysis metrics over the time slice window against the cohort workload
+func (c *Controller) measureTimeSlice(cd *flaggerv1.Canary, cohort flaggerv1.TimeSliceCohort, window string) []flaggerv1.CanaryMetricResult {
+	subject := cd.DeepCopy()
+	if cohort == flaggerv1.TimeSliceCohortPrimary {
+		subject.Spec.TargetRef.Name = fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	}
+	subject.Status.AnalysisRunID = string(uuid.NewUUID())
+	for i := range subject.GetAnalysis().Metrics {
+		subject.GetAnalysis().Metrics[i].Interval = window
+	}
+
+	// the threshold checks are ignored, the cohorts are compared after the last time slice
+	margin := &analysisMargin{}
+	c.runBuiltinMetricChecks(subject, margin, nil)
+	c.runMetricChecks(subject, margin, nil)
+
+	v, ok := c.metricResults.Load(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace))
+	if !ok || v.(*analysisMetrics).runID != subject.Status.AnalysisRunID {
+		return nil
+	}
+	return v.(*analysisMetrics).results
+}
+
+// isPrimaryTimeSlice returns true while the primary receives all the traffic during a time-sliced
+// experiment, the analysis checks run only during the canary time slices
+func isPrimaryTimeSlice(cd *flaggerv1.Canary) bool {
+	return cd.GetAnalysis().TimeSlices != nil &&
+		cd.Status.Iterations > 0 &&
+		cd.Status.TimeSlice != nil &&
+		cd.Status.TimeSlice.Cohort == flaggerv1.TimeSliceCohortPrimary
+}
+
+// compareTimeSlices returns an error if the canary mean of a metric is worse than
+// the primary mean by more than the max regression percentage
+func compareTimeSlices(cd *flaggerv1.Canary, results []flaggerv1.CanaryTimeSliceResult) error {
+	maxRegression := defaultTimeSliceMaxRegression
+	if v := cd.GetAnalysis().TimeSlices.MaxRegression; v != nil {
+		maxRegression = *v
+	}
+
+	compared := 0
+	for _, metric := range cd.GetAnalysis().Metrics {
+		if metric.Sampling != nil && metric.Sampling.Aggregator == samplingCountAboveThreshold {
+			continue
+		}
+		canaryMean, canaryOk := timeSliceMean(results, flaggerv1.TimeSliceCohortCanary, metric.Name)
+		primaryMean, primaryOk := timeSliceMean(results, flaggerv1.TimeSliceCohortPrimary, metric.Name)
+		if !canaryOk || !primaryOk {
+			continue
+		}
+		compared++
+
+		tolerance := math.Abs(primaryMean) * maxRegression / 100
+		min, max := metricBounds(metric)
+		if min != nil && canaryMean < primaryMean-tolerance {
+			return fmt.Errorf("%s canary mean %.2f < primary mean %.2f", metric.Name, canaryMean, primaryMean)
+		}
+		if max != nil && canaryMean > primaryMean+tolerance {
+			return fmt.Errorf("%s canary mean %.2f > primary mean %.2f", metric.Name, canaryMean, primaryMean)
+		}
+	}
+
+	if compared == 0 && len(cd.GetAnalysis().Metrics) > 0 {
+		return fmt.Errorf("no metric has values for both the canary and the primary time slices")
+	}
+	return nil
+}
+
+func timeSliceMean(results []flaggerv1.CanaryTimeSliceResult, cohort flaggerv1.TimeSliceCohort, name string) (float64, bool) {
+	var sum float64
+	var count int
+	for _, r := range results {
+		if r.Cohort != cohort {
+			continue
+		}
+		for _, m := range r.Metrics {
+			if m.Name == name {
+				sum += m.Value
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
+
+func (c *Controller) setStatusTimeSlice(cd *flaggerv1.Canary, state *flaggerv1.CanaryTimeSliceStatus) error {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := cd.DeepCopy()
+		cdCopy.Status.TimeSlice = state
+		_, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).UpdateStatus(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		firstTry = false
+		return
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
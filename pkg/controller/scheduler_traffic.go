@@ -0,0 +1,142 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	trafficProbeHeader   = "X-Flagger-Probe"
+	trafficProbeRequests = 10
+	trafficProbeTimeout  = 5 * time.Second
+)
+
+// verifyTraffic checks that the routes read back from the router match the
+// canary weight applied by the last step and that the probe URL serves requests
+func (c *Controller) verifyTraffic(canary *flaggerv1.Canary, canaryWeight int) error {
+	verification := canary.GetAnalysis().TrafficVerification
+	if verification == nil {
+		return nil
+	}
+
+	if canaryWeight != canary.Status.CanaryWeight {
+		return fmt.Errorf("routes read back canary weight %v expected %v", canaryWeight, canary.Status.CanaryWeight)
+	}
+
+	if verification.ProbeURL == "" {
+		return nil
+	}
+
+	requests := verification.ProbeRequests
+	if requests < 1 {
+		requests = trafficProbeRequests
+	}
+	failed := 0
+	var lastErr error
+	for i := 0; i < requests; i++ {
+		if err := probeTraffic(verification.ProbeURL, fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)); err != nil {
+			failed++
+			lastErr = err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%v out of %v probe requests failed: %w", failed, requests, lastErr)
+	}
+	return nil
+}
+
+func probeTraffic(url string, marker string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), trafficProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(trafficProbeHeader, marker)
+
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	io.Copy(io.Discard, r.Body)
+
+	if r.StatusCode >= 500 {
+		return fmt.Errorf("status %d", r.StatusCode)
+	}
+	return nil
+}
+
+// recordStatusRoutes records the traffic split read back from the provider
+func (c *Controller) recordStatusRoutes(cd *flaggerv1.Canary, provider string, primaryWeight int, canaryWeight int, mirrored bool) {
+	if provider == "" {
+		provider = flaggerv1.IstioProvider
+	}
+	if err := c.setStatusRoutes(cd, provider, primaryWeight, canaryWeight, mirrored); err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Errorf("%v", err)
+	}
+}
+
+// setStatusRoutes updates the traffic split status only when the split changes
+func (c *Controller) setStatusRoutes(cd *flaggerv1.Canary, provider string, primaryWeight int, canaryWeight int, mirrored bool) error {
+	if r := cd.Status.Routes; r != nil && r.Provider == provider &&
+		r.PrimaryWeight == primaryWeight && r.CanaryWeight == canaryWeight && r.Mirrored == mirrored {
+		return nil
+	}
+
+	routes := &flaggerv1.CanaryRoutesStatus{
+		Provider:           provider,
+		PrimaryWeight:      primaryWeight,
+		CanaryWeight:       canaryWeight,
+		Mirrored:           mirrored,
+		LastTransitionTime: metav1.Now(),
+	}
+
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		selected := cd
+		if !firstTry {
+			selected, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := selected.DeepCopy()
+		cdCopy.Status.Routes = routes
+		_, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).UpdateStatus(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		firstTry = false
+		return
+	})
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	cd.Status.Routes = routes
+	return nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// runScheduledVerification verifies the promoted primary when the verification schedule is due,
+// the analysis metrics and the verification webhooks are run against the primary without shifting traffic
+func (c *Controller) runScheduledVerification(canary *flaggerv1.Canary) {
+	verification := canary.GetAnalysis().Verification
+	if verification == nil || !isVerifiable(canary) {
+		return
+	}
+
+	schedule, err := parseCronSchedule(verification.Schedule)
+	if err != nil {
+		c.recordEventErrorf(canary, "Verification of %s.%s is not valid: %v", canary.Name, canary.Namespace, err)
+		return
+	}
+	if !isVerificationDue(canary, schedule, time.Now()) {
+		return
+	}
+
+	report := c.runVerification(canary)
+	if err := c.setStatusVerification(canary, report); err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
+	}
+	c.recorder.SetVerification(canary, report.Succeeded)
+
+	if report.Succeeded {
+		c.recordEventInfof(canary, "Verification of %s.%s passed", canary.Name, canary.Namespace)
+		if verification.AlertOnSuccess {
+			c.alert(canary, fmt.Sprintf("Scheduled verification of the primary passed %s", formatMetricResults(report.Metrics)),
+				false, flaggerv1.SeverityInfo)
+		}
+		return
+	}
+	c.recordEventWarningf(canary, "Verification of %s.%s failed %s", canary.Name, canary.Namespace, report.Message)
+	c.alert(canary, fmt.Sprintf("Scheduled verification of the primary failed %s %s", report.Message, formatMetricResults(report.Metrics)),
+		false, flaggerv1.SeverityError)
+}
+
+// runVerification runs the verification webhooks and the metric checks against the primary workload
+func (c *Controller) runVerification(canary *flaggerv1.Canary) flaggerv1.CanaryVerificationStatus {
+	report := flaggerv1.CanaryVerificationStatus{Time: metav1.Now()}
+
+	// the metric queries and templates target the primary workload, the run ID isolates the metric results
+	primary := canary.DeepCopy()
+	primary.Spec.TargetRef.Name = fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
+	primary.Status.AnalysisRunID = string(uuid.NewUUID())
+	// there is no canary to compare with, the primary metrics are checked against their thresholds
+	primary.GetAnalysis().Decider = nil
+
+	var webhooks []flaggerv1.CanaryWebhook
+	for _, webhook := range canary.GetAnalysis().Webhooks {
+		if webhook.Type == flaggerv1.VerificationHook {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	for _, r := range c.callWebhookGroups(primary, canary.Status.Phase, webhooks) {
+		if r.err != nil {
+			report.Message = fmt.Sprintf("webhook %s error: %v", r.webhook.Name, r.err)
+			return report
+		}
+	}
+
+	margin := &analysisMargin{}
+	ok := c.runBuiltinMetricChecks(primary, margin, nil) && c.runMetricChecks(primary, margin, nil)
+	report.Metrics = c.getMetricResults(primary)
+	if !ok {
+		report.Message = fmt.Sprintf("metric checks of %s.%s failed", primary.Spec.TargetRef.Name, canary.Namespace)
+		return report
+	}
+
+	report.Succeeded = true
+	return report
+}
+
+// isVerifiable returns true if the primary runs the promoted revision and no analysis is in progress
+func isVerifiable(canary *flaggerv1.Canary) bool {
+	return canary.Status.Phase.IsPromoted() ||
+		canary.Status.Phase == flaggerv1.CanaryPhaseInitialized
+}
+
+// isVerificationDue returns true if the schedule elapsed since the last verification,
+// or since the last status transition when the primary hasn't been verified yet
+func isVerificationDue(canary *flaggerv1.Canary, schedule *cronSchedule, now time.Time) bool {
+	last := canary.Status.LastTransitionTime.Time
+	if v := canary.Status.LastVerification; v != nil && v.Time.After(last) {
+		last = v.Time.Time
+	}
+	next := schedule.next(last)
+	return !next.IsZero() && !now.Before(next)
+}
+
+func (c *Controller) setStatusVerification(cd *flaggerv1.Canary, report flaggerv1.CanaryVerificationStatus) error {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := cd.DeepCopy()
+		cdCopy.Status.LastVerification = &report
+		_, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).UpdateStatus(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		firstTry = false
+		return
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
+func formatMetricResults(results []flaggerv1.CanaryMetricResult) string {
+	var parts []string
+	for _, r := range results {
+		parts = append(parts, fmt.Sprintf("%s=%.2f", r.Name, r.Value))
+	}
+	return strings.Join(parts, " ")
+}
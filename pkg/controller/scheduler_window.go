@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// isInAnalysisWindow returns true if the canary has no analysis schedule
+// or if one of its windows matches the given time
+func isInAnalysisWindow(canary *flaggerv1.Canary, now time.Time) (bool, error) {
+	windows := canary.GetAnalysis().Schedule
+	if len(windows) == 0 {
+		return true, nil
+	}
+
+	for _, w := range windows {
+		schedule, err := parseCronSchedule(w.Cron)
+		if err != nil {
+			return false, err
+		}
+		location := time.UTC
+		if w.TimeZone != "" {
+			if location, err = time.LoadLocation(w.TimeZone); err != nil {
+				return false, fmt.Errorf("time zone %q: %w", w.TimeZone, err)
+			}
+		}
+		if schedule.matches(now.In(location)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// waitForAnalysisWindow returns true if the canary can't advance at this time,
+// the analysis is held at its current state until a window opens
+func (c *Controller) waitForAnalysisWindow(canary *flaggerv1.Canary) bool {
+	ok, err := isInAnalysisWindow(canary, time.Now())
+	if err != nil {
+		c.recordEventErrorf(canary, "Analysis schedule of %s.%s is not valid: %v", canary.Name, canary.Namespace, err)
+		return true
+	}
+	if !ok {
+		c.recordEventInfof(canary, "Waiting for the analysis window of %s.%s", canary.Name, canary.Namespace)
+		return true
+	}
+	return false
+}
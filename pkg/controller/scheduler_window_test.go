@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestIsInAnalysisWindow(t *testing.T) {
+	cd := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{},
+		},
+	}
+
+	// Friday
+	now := time.Date(2021, 10, 15, 10, 30, 0, 0, time.UTC)
+	ok, err := isInAnalysisWindow(cd, now)
+	require.NoError(t, err)
+	assert.True(t, ok, "no schedule")
+
+	tests := []struct {
+		windows []flaggerv1.CanaryAnalysisWindow
+		ok      bool
+	}{
+		{windows: []flaggerv1.CanaryAnalysisWindow{{Cron: "* 9-16 * * 1-5"}}, ok: true},
+		{windows: []flaggerv1.CanaryAnalysisWindow{{Cron: "* 11-16 * * 1-5"}}, ok: false},
+		{windows: []flaggerv1.CanaryAnalysisWindow{{Cron: "* 9-16 * * 6"}}, ok: false},
+		// any window matches
+		{windows: []flaggerv1.CanaryAnalysisWindow{{Cron: "* 20-23 * * *"}, {Cron: "0-45 10 * * *"}}, ok: true},
+		// 10:30 UTC is 19:30 in Tokyo
+		{windows: []flaggerv1.CanaryAnalysisWindow{{Cron: "* 9-16 * * 1-5", TimeZone: "Asia/Tokyo"}}, ok: false},
+		{windows: []flaggerv1.CanaryAnalysisWindow{{Cron: "* 19 * * 5", TimeZone: "Asia/Tokyo"}}, ok: true},
+	}
+	for _, tt := range tests {
+		cd.Spec.Analysis.Schedule = tt.windows
+		ok, err := isInAnalysisWindow(cd, now)
+		require.NoError(t, err)
+		assert.Equal(t, tt.ok, ok, "%v", tt.windows)
+	}
+
+	cd.Spec.Analysis.Schedule = []flaggerv1.CanaryAnalysisWindow{{Cron: "* 25 * * *"}}
+	_, err = isInAnalysisWindow(cd, now)
+	assert.Error(t, err)
+	cd.Spec.Analysis.Schedule = []flaggerv1.CanaryAnalysisWindow{{Cron: "* * * * *", TimeZone: "Mars/Olympus"}}
+	_, err = isInAnalysisWindow(cd, now)
+	assert.Error(t, err)
+}
@@ -26,12 +26,20 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync"
 	"time"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/gate"
+	"github.com/fluxcd/flagger/pkg/spiffe"
 )
 
-func callWebhook(webhook string, payload interface{}, timeout string) error {
+const webhookVersionHeader = "X-Flagger-Webhook-Version"
+
+// errWebhookVersionNotSupported is returned when the webhook rejects the payload version
+var errWebhookVersionNotSupported = errors.New("webhook payload version not supported")
+
+func callWebhook(webhook string, payload interface{}, timeout string, version flaggerv1.WebhookPayloadVersion, spiffeMTLS bool) error {
 	payloadBin, err := json.Marshal(payload)
 	if err != nil {
 		return err
@@ -48,6 +56,9 @@ func callWebhook(webhook string, payload interface{}, timeout string) error {
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if version != "" {
+		req.Header.Set(webhookVersionHeader, string(version))
+	}
 
 	if timeout == "" {
 		timeout = "10s"
@@ -58,10 +69,17 @@ func callWebhook(webhook string, payload interface{}, timeout string) error {
 		return err
 	}
 
+	client := http.DefaultClient
+	if spiffeMTLS {
+		if client, err = spiffe.DefaultHTTPClient(); err != nil {
+			return err
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(req.Context(), t)
 	defer cancel()
 
-	r, err := http.DefaultClient.Do(req.WithContext(ctx))
+	r, err := client.Do(req.WithContext(ctx))
 	if err != nil {
 		return err
 	}
@@ -72,6 +90,10 @@ func callWebhook(webhook string, payload interface{}, timeout string) error {
 		return fmt.Errorf("error reading body: %s", err.Error())
 	}
 
+	if r.StatusCode == http.StatusUnsupportedMediaType && version != "" && version != flaggerv1.WebhookPayloadV1 {
+		return fmt.Errorf("%w: %s", errWebhookVersionNotSupported, string(b))
+	}
+
 	if r.StatusCode > 202 {
 		return errors.New(string(b))
 	}
@@ -96,7 +118,123 @@ func CallWebhook(name string, namespace string, phase flaggerv1.CanaryPhase, w f
 		w.Timeout = "10s"
 	}
 
-	return callWebhook(w.URL, payload, w.Timeout)
+	return callWebhook(w.URL, payload, w.Timeout, flaggerv1.WebhookPayloadV1, w.SPIFFE)
+}
+
+// callWebhook sends the payload version the webhook asks for,
+// falling back to v1 when the webhook responds with 415 Unsupported Media Type
+func (c *Controller) callWebhook(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook) error {
+	return c.callStepWebhook(canary, phase, w, 0)
+}
+
+// callStepWebhook calls the webhook with the canary weight of the next step in the v2 payload
+func (c *Controller) callStepWebhook(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook, nextWeight int) error {
+	if name, ok := gate.Parse(w.URL, canary.Name); ok {
+		return c.checkLocalGate(canary, name)
+	}
+
+	if w.Version != flaggerv1.WebhookPayloadV2 {
+		return CallWebhook(canary.Name, canary.Namespace, phase, w)
+	}
+
+	payload := flaggerv1.CanaryWebhookPayloadV2{
+		CanaryWebhookPayload: flaggerv1.CanaryWebhookPayload{
+			Name:      canary.Name,
+			Namespace: canary.Namespace,
+			Phase:     phase,
+		},
+		Version:          flaggerv1.WebhookPayloadV2,
+		TargetRef:        canary.Spec.TargetRef,
+		CanaryWeight:     canary.Status.CanaryWeight,
+		NextWeight:       nextWeight,
+		Iterations:       canary.Status.Iterations,
+		FailedChecks:     canary.Status.FailedChecks,
+		LastAppliedSpec:  canary.Status.LastAppliedSpec,
+		LastPromotedSpec: canary.Status.LastPromotedSpec,
+		Metrics:          c.getMetricResults(canary),
+	}
+	if w.Metadata != nil {
+		payload.Metadata = *w.Metadata
+	}
+
+	timeout := w.Timeout
+	if len(timeout) < 2 {
+		timeout = "10s"
+	}
+
+	err := callWebhook(w.URL, payload, timeout, flaggerv1.WebhookPayloadV2, w.SPIFFE)
+	if errors.Is(err, errWebhookVersionNotSupported) {
+		return CallWebhook(canary.Name, canary.Namespace, phase, w)
+	}
+	return err
+}
+
+// checkLocalGate returns an error if the local gate opened with kubectl flagger is closed
+func (c *Controller) checkLocalGate(canary *flaggerv1.Canary, name string) error {
+	open, err := gate.IsOpen(context.TODO(), c.kubeClient, canary.Namespace, name)
+	if err != nil {
+		return err
+	}
+	if !open {
+		return fmt.Errorf("local gate %s.%s is closed", name, canary.Namespace)
+	}
+	return nil
+}
+
+// analysisMetrics holds the metric values recorded during an analysis run
+type analysisMetrics struct {
+	runID   string
+	results []flaggerv1.CanaryMetricResult
+	samples map[string][]float64
+}
+
+// recordMetricResult keeps the last value of a metric check for the v2 webhook payloads
+// and the values of the current analysis run for the statistical decider
+func (c *Controller) recordMetricResult(canary *flaggerv1.Canary, name string, val float64) {
+	key := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+	current := &analysisMetrics{runID: canary.Status.AnalysisRunID}
+	if v, ok := c.metricResults.Load(key); ok && v.(*analysisMetrics).runID == current.runID {
+		current = v.(*analysisMetrics)
+	}
+
+	updated := &analysisMetrics{
+		runID:   current.runID,
+		results: make([]flaggerv1.CanaryMetricResult, 0, len(current.results)+1),
+		samples: make(map[string][]float64, len(current.samples)+1),
+	}
+	found := false
+	for _, r := range current.results {
+		if r.Name == name {
+			r.Value = val
+			found = true
+		}
+		updated.results = append(updated.results, r)
+	}
+	if !found {
+		updated.results = append(updated.results, flaggerv1.CanaryMetricResult{Name: name, Value: val})
+	}
+	for n, values := range current.samples {
+		updated.samples[n] = values
+	}
+	updated.samples[name] = append(append([]float64(nil), current.samples[name]...), val)
+	c.metricResults.Store(key, updated)
+}
+
+func (c *Controller) getMetricResults(canary *flaggerv1.Canary) []flaggerv1.CanaryMetricResult {
+	if v, ok := c.metricResults.Load(fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)); ok {
+		return v.(*analysisMetrics).results
+	}
+	return nil
+}
+
+// getMetricSamples returns the metric values recorded during the current analysis run
+func (c *Controller) getMetricSamples(canary *flaggerv1.Canary) map[string][]float64 {
+	if v, ok := c.metricResults.Load(fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)); ok {
+		if m := v.(*analysisMetrics); m.runID == canary.Status.AnalysisRunID {
+			return m.samples
+		}
+	}
+	return nil
 }
 
 func CallEventWebhook(r *flaggerv1.Canary, w flaggerv1.CanaryWebhook, message, eventtype string) error {
@@ -121,5 +259,99 @@ func CallEventWebhook(r *flaggerv1.Canary, w flaggerv1.CanaryWebhook, message, e
 			payload.Metadata[key] = value
 		}
 	}
-	return callWebhook(w.URL, payload, "5s")
+	return callWebhook(w.URL, payload, "5s", "", w.SPIFFE)
+}
+
+// webhookResult holds the outcome of a webhook call
+type webhookResult struct {
+	webhook flaggerv1.CanaryWebhook
+	err     error
+}
+
+// callWebhookGroups calls the webhooks group by group, the webhooks of a group are called in parallel
+// and the groups are called sequentially in the order of their first webhook, a webhook without a group
+// is a group on its own. It stops after the first group with a failed webhook and returns the results
+// of the called webhooks in the order they are listed.
+func (c *Controller) callWebhookGroups(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase, webhooks []flaggerv1.CanaryWebhook) []webhookResult {
+	var groups [][]flaggerv1.CanaryWebhook
+	index := make(map[string]int)
+	for _, w := range webhooks {
+		if w.Group == "" {
+			groups = append(groups, []flaggerv1.CanaryWebhook{w})
+			continue
+		}
+		if i, ok := index[w.Group]; ok {
+			groups[i] = append(groups[i], w)
+			continue
+		}
+		index[w.Group] = len(groups)
+		groups = append(groups, []flaggerv1.CanaryWebhook{w})
+	}
+
+	var timeout time.Duration
+	if v := canary.GetAnalysis().WebhookGroupTimeout; v != "" {
+		timeout, _ = time.ParseDuration(v)
+	}
+
+	var results []webhookResult
+	for _, group := range groups {
+		groupResults := c.callWebhookGroup(canary, phase, group, timeout)
+		results = append(results, groupResults...)
+		for _, r := range groupResults {
+			if r.err != nil {
+				return results
+			}
+		}
+	}
+	return results
+}
+
+func (c *Controller) callWebhookGroup(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase, group []flaggerv1.CanaryWebhook, timeout time.Duration) []webhookResult {
+	results := make([]webhookResult, len(group))
+	if len(group) == 1 && timeout == 0 {
+		results[0] = webhookResult{group[0], c.callWebhook(canary, phase, group[0])}
+		return results
+	}
+
+	var mu sync.Mutex
+	done := make([]bool, len(group))
+	var wg sync.WaitGroup
+	for i, w := range group {
+		wg.Add(1)
+		go func(i int, w flaggerv1.CanaryWebhook) {
+			defer wg.Done()
+			err := c.callWebhook(canary, phase, w)
+			mu.Lock()
+			defer mu.Unlock()
+			results[i] = webhookResult{w, err}
+			done[i] = true
+		}(i, w)
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		expired = time.After(timeout)
+	}
+	select {
+	case <-finished:
+	case <-expired:
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	res := make([]webhookResult, len(group))
+	for i, w := range group {
+		if !done[i] {
+			res[i] = webhookResult{w, fmt.Errorf("webhook group %s timed out after %v", w.Group, timeout)}
+			continue
+		}
+		res[i] = results[i]
+	}
+	return res
 }
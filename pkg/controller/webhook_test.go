@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -61,6 +63,64 @@ func TestCallWebhook_StatusCode(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestController_callWebhookV2(t *testing.T) {
+	canary := &flaggerv1.Canary{
+		ObjectMeta: v1.ObjectMeta{Name: "podinfo", Namespace: v1.NamespaceDefault},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{Kind: "Deployment", Name: "podinfo"},
+		},
+		Status: flaggerv1.CanaryStatus{CanaryWeight: 20, Iterations: 2},
+	}
+	ctrl := &Controller{metricResults: new(sync.Map)}
+	ctrl.recordMetricResult(canary, "error-rate", 0.5)
+
+	t.Run("v2", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "v2", r.Header.Get(webhookVersionHeader))
+			var payload flaggerv1.CanaryWebhookPayloadV2
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			assert.Equal(t, "podinfo", payload.Name)
+			assert.Equal(t, "podinfo", payload.TargetRef.Name)
+			assert.Equal(t, 20, payload.CanaryWeight)
+			assert.Equal(t, []flaggerv1.CanaryMetricResult{{Name: "error-rate", Value: 0.5}}, payload.Metrics)
+		}))
+		defer ts.Close()
+
+		hook := flaggerv1.CanaryWebhook{Name: "gate", URL: ts.URL, Version: flaggerv1.WebhookPayloadV2}
+		require.NoError(t, ctrl.callWebhook(canary, flaggerv1.CanaryPhaseProgressing, hook))
+	})
+
+	t.Run("next weight", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload flaggerv1.CanaryWebhookPayloadV2
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			assert.Equal(t, 20, payload.CanaryWeight)
+			assert.Equal(t, 30, payload.NextWeight)
+		}))
+		defer ts.Close()
+
+		hook := flaggerv1.CanaryWebhook{Name: "gate", Type: flaggerv1.ConfirmTrafficIncreaseHook,
+			URL: ts.URL, Version: flaggerv1.WebhookPayloadV2}
+		require.NoError(t, ctrl.callStepWebhook(canary, flaggerv1.CanaryPhaseProgressing, hook, 30))
+	})
+
+	t.Run("fallback to v1", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(webhookVersionHeader) != "v1" {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			var payload map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			assert.NotContains(t, payload, "metrics")
+		}))
+		defer ts.Close()
+
+		hook := flaggerv1.CanaryWebhook{Name: "gate", URL: ts.URL, Version: flaggerv1.WebhookPayloadV2}
+		require.NoError(t, ctrl.callWebhook(canary, flaggerv1.CanaryPhaseProgressing, hook))
+	})
+}
+
 func TestCallEventWebhook(t *testing.T) {
 	canaryName := "podinfo"
 	canaryNamespace := v1.NamespaceDefault
@@ -146,3 +206,54 @@ func TestCallEventWebhookStatusCode(t *testing.T) {
 	err := CallEventWebhook(canary, hook, canaryMessage, canaryEventType)
 	assert.Error(t, err)
 }
+
+func TestCallWebhookGroups(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload flaggerv1.CanaryWebhookPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		if d, err := time.ParseDuration(payload.Metadata["sleep"]); err == nil {
+			time.Sleep(d)
+		}
+		if payload.Metadata["fail"] == "true" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	canary := &flaggerv1.Canary{
+		ObjectMeta: v1.ObjectMeta{Name: "podinfo", Namespace: v1.NamespaceDefault},
+		Spec:       flaggerv1.CanarySpec{Analysis: &flaggerv1.CanaryAnalysis{}},
+	}
+	webhooks := []flaggerv1.CanaryWebhook{
+		{Name: "a", URL: ts.URL, Group: "smoke", Metadata: &map[string]string{"sleep": "200ms"}},
+		{Name: "c", URL: ts.URL},
+		{Name: "b", URL: ts.URL, Group: "smoke", Metadata: &map[string]string{"sleep": "200ms"}},
+	}
+
+	ctrl := &Controller{}
+
+	// the smoke group runs in parallel before c
+	start := time.Now()
+	results := ctrl.callWebhookGroups(canary, flaggerv1.CanaryPhaseProgressing, webhooks)
+	assert.Less(t, time.Since(start), 400*time.Millisecond)
+	require.Len(t, results, 3)
+	assert.Equal(t, "a", results[0].webhook.Name)
+	assert.Equal(t, "b", results[1].webhook.Name)
+	assert.Equal(t, "c", results[2].webhook.Name)
+	for _, r := range results {
+		assert.NoError(t, r.err)
+	}
+
+	// a failed group stops the next groups
+	webhooks[2].Metadata = &map[string]string{"fail": "true"}
+	results = ctrl.callWebhookGroups(canary, flaggerv1.CanaryPhaseProgressing, webhooks)
+	require.Len(t, results, 2)
+	assert.Error(t, results[1].err)
+
+	// the group timeout fails the unfinished webhooks
+	webhooks[2].Metadata = &map[string]string{"sleep": "200ms"}
+	canary.Spec.Analysis.WebhookGroupTimeout = "50ms"
+	results = ctrl.callWebhookGroups(canary, flaggerv1.CanaryPhaseProgressing, webhooks)
+	require.Len(t, results, 2)
+	assert.Contains(t, results[0].err.Error(), "timed out")
+}
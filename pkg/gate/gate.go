@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// Scheme is the webhook URL scheme of the local gates, e.g. gate://podinfo
+	Scheme = "gate://"
+
+	gateLabel         = "flagger.app/gate"
+	expiresAnnotation = "flagger.app/gate-expires"
+)
+
+// ConfigMapName returns the name of the config map that holds the gate state
+func ConfigMapName(name string) string {
+	return fmt.Sprintf("flagger-gate-%s", name)
+}
+
+// Parse returns the gate name of a local gate webhook URL,
+// the canary name is used when the URL has no gate name
+func Parse(url string, canaryName string) (string, bool) {
+	if !strings.HasPrefix(url, Scheme) {
+		return "", false
+	}
+	name := strings.Trim(strings.TrimPrefix(url, Scheme), "/")
+	if name == "" {
+		name = canaryName
+	}
+	return name, true
+}
+
+// Open creates or updates the gate config map, the gate closes itself after the TTL if set
+func Open(ctx context.Context, kubeClient kubernetes.Interface, namespace string, name string, ttl time.Duration) error {
+	cmName := ConfigMapName(name)
+	annotations := map[string]string{}
+	if ttl > 0 {
+		annotations[expiresAnnotation] = time.Now().Add(ttl).UTC().Format(time.RFC3339)
+	}
+
+	existing, err := kubeClient.CoreV1().ConfigMaps(namespace).Get(ctx, cmName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        cmName,
+				Namespace:   namespace,
+				Labels:      map[string]string{gateLabel: name},
+				Annotations: annotations,
+			},
+		}
+		if _, err := kubeClient.CoreV1().ConfigMaps(namespace).Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("configmap %s.%s create error: %w", cmName, namespace, err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("configmap %s.%s get query error: %w", cmName, namespace, err)
+	}
+
+	clone := existing.DeepCopy()
+	clone.Annotations = annotations
+	if _, err := kubeClient.CoreV1().ConfigMaps(namespace).Update(ctx, clone, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("configmap %s.%s update error: %w", cmName, namespace, err)
+	}
+	return nil
+}
+
+// Close removes the gate config map
+func Close(ctx context.Context, kubeClient kubernetes.Interface, namespace string, name string) error {
+	cmName := ConfigMapName(name)
+	err := kubeClient.CoreV1().ConfigMaps(namespace).Delete(ctx, cmName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("configmap %s.%s delete error: %w", cmName, namespace, err)
+	}
+	return nil
+}
+
+// IsOpen returns true if the gate config map exists and hasn't expired
+func IsOpen(ctx context.Context, kubeClient kubernetes.Interface, namespace string, name string) (bool, error) {
+	cmName := ConfigMapName(name)
+	cm, err := kubeClient.CoreV1().ConfigMaps(namespace).Get(ctx, cmName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("configmap %s.%s get query error: %w", cmName, namespace, err)
+	}
+
+	if v, ok := cm.Annotations[expiresAnnotation]; ok {
+		expires, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return false, fmt.Errorf("configmap %s.%s invalid %s annotation: %w", cmName, namespace, expiresAnnotation, err)
+		}
+		if time.Now().After(expires) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParse(t *testing.T) {
+	name, ok := Parse("gate://approve-podinfo", "podinfo")
+	assert.True(t, ok)
+	assert.Equal(t, "approve-podinfo", name)
+
+	name, ok = Parse("gate://", "podinfo")
+	assert.True(t, ok)
+	assert.Equal(t, "podinfo", name)
+
+	_, ok = Parse("http://flagger-loadtester.test/gate/check", "podinfo")
+	assert.False(t, ok)
+}
+
+func TestOpenClose(t *testing.T) {
+	ctx := context.TODO()
+	kubeClient := fake.NewSimpleClientset()
+
+	open, err := IsOpen(ctx, kubeClient, "default", "podinfo")
+	require.NoError(t, err)
+	assert.False(t, open)
+
+	require.NoError(t, Open(ctx, kubeClient, "default", "podinfo", 0))
+	open, err = IsOpen(ctx, kubeClient, "default", "podinfo")
+	require.NoError(t, err)
+	assert.True(t, open)
+
+	require.NoError(t, Close(ctx, kubeClient, "default", "podinfo"))
+	open, err = IsOpen(ctx, kubeClient, "default", "podinfo")
+	require.NoError(t, err)
+	assert.False(t, open)
+
+	// closing a closed gate is a no-op
+	require.NoError(t, Close(ctx, kubeClient, "default", "podinfo"))
+}
+
+func TestOpenExpired(t *testing.T) {
+	ctx := context.TODO()
+	kubeClient := fake.NewSimpleClientset()
+
+	require.NoError(t, Open(ctx, kubeClient, "default", "podinfo", time.Hour))
+	open, err := IsOpen(ctx, kubeClient, "default", "podinfo")
+	require.NoError(t, err)
+	assert.True(t, open)
+
+	cm, err := kubeClient.CoreV1().ConfigMaps("default").Get(ctx, ConfigMapName("podinfo"), metav1.GetOptions{})
+	require.NoError(t, err)
+	cm.Annotations[expiresAnnotation] = time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	_, err = kubeClient.CoreV1().ConfigMaps("default").Update(ctx, cm, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	open, err = IsOpen(ctx, kubeClient, "default", "podinfo")
+	require.NoError(t, err)
+	assert.False(t, open)
+}
@@ -16,11 +16,62 @@ limitations under the License.
 
 package loadtester
 
-import "sync"
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxGateAuditEntries is the number of gate changes kept in the audit log
+const maxGateAuditEntries = 1000
 
 type GateStorage struct {
 	backend string
 	data    *sync.Map
+
+	mu         sync.Mutex
+	tokens     map[string]string
+	adminToken string
+	audit      []GateAuditEntry
+}
+
+// gateState holds the state of a gate, an open gate closes itself after the expiry time if set
+type gateState struct {
+	open    bool
+	expires time.Time
+}
+
+// GateRequest is the payload of the gate open and close requests
+type GateRequest struct {
+	// Name of the canary
+	Name string `json:"name"`
+
+	// Namespace of the canary
+	Namespace string `json:"namespace"`
+
+	// TTL closes the opened gate after the duration, e.g. 1h
+	TTL string `json:"ttl,omitempty"`
+
+	// User who changed the gate, recorded in the audit log
+	User string `json:"user,omitempty"`
+}
+
+// GateAuditEntry records a gate change
+type GateAuditEntry struct {
+	Time    time.Time  `json:"time"`
+	Gate    string     `json:"gate"`
+	Action  string     `json:"action"`
+	User    string     `json:"user"`
+	Address string     `json:"address"`
+	Expires *time.Time `json:"expires,omitempty"`
 }
 
 func NewGateStorage(backend string) *GateStorage {
@@ -30,18 +81,232 @@ func NewGateStorage(backend string) *GateStorage {
 	}
 }
 
+// LoadTokens reads the per-canary authorization tokens from a JSON file
+// that maps the canary <name>.<namespace> to its token
+func (gs *GateStorage) LoadTokens(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading gate tokens file %s failed: %w", path, err)
+	}
+	tokens := make(map[string]string)
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return fmt.Errorf("decoding gate tokens file %s failed: %w", path, err)
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.tokens = tokens
+	return nil
+}
+
+// LoadAdminToken reads the token that grants access to the whole audit log from a file
+func (gs *GateStorage) LoadAdminToken(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading gate admin token file %s failed: %w", path, err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return fmt.Errorf("gate admin token file %s is empty", path)
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.adminToken = token
+	return nil
+}
+
+// authorize returns false if the canary has a token and the request doesn't carry it
+func (gs *GateStorage) authorize(canary string, r *http.Request) bool {
+	gs.mu.Lock()
+	token, ok := gs.tokens[canary]
+	gs.mu.Unlock()
+	if !ok {
+		return true
+	}
+	bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1
+}
+
 func (gs *GateStorage) open(key string) {
-	gs.data.Store(key, true)
+	gs.openFor(key, 0)
+}
+
+// openFor opens the gate, a positive ttl closes it after the duration
+func (gs *GateStorage) openFor(key string, ttl time.Duration) time.Time {
+	state := gateState{open: true}
+	if ttl > 0 {
+		state.expires = time.Now().Add(ttl)
+	}
+	gs.data.Store(key, state)
+	return state.expires
 }
 
 func (gs *GateStorage) close(key string) {
-	gs.data.Store(key, false)
+	gs.data.Store(key, gateState{})
 }
 
 func (gs *GateStorage) isOpen(key string) (locked bool) {
-	val, ok := gs.data.LoadOrStore(key, false)
-	if ok {
-		return val.(bool)
+	val, ok := gs.data.LoadOrStore(key, gateState{})
+	if !ok {
+		return
+	}
+	state := val.(gateState)
+	if state.open && !state.expires.IsZero() && time.Now().After(state.expires) {
+		gs.data.Store(key, gateState{})
+		return false
+	}
+	return state.open
+}
+
+// record appends a gate change to the audit log
+func (gs *GateStorage) record(entry GateAuditEntry) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.audit = append(gs.audit, entry)
+	if len(gs.audit) > maxGateAuditEntries {
+		gs.audit = gs.audit[len(gs.audit)-maxGateAuditEntries:]
+	}
+}
+
+// authorizeAudit returns the gate changes the request bearer token can read,
+// the admin token reads the whole audit log and a canary token the changes of its gates
+func (gs *GateStorage) authorizeAudit(r *http.Request) ([]GateAuditEntry, bool) {
+	bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if bearer == "" {
+		return nil, false
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if gs.adminToken != "" && subtle.ConstantTimeCompare([]byte(bearer), []byte(gs.adminToken)) == 1 {
+		return append([]GateAuditEntry{}, gs.audit...), true
+	}
+
+	gates := make(map[string]bool)
+	for canary, token := range gs.tokens {
+		if subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+			gates[canary] = true
+			gates["rollback."+canary] = true
+		}
+	}
+	if len(gates) == 0 {
+		return nil, false
+	}
+	entries := []GateAuditEntry{}
+	for _, entry := range gs.audit {
+		if gates[entry.Gate] {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, true
+}
+
+// HandleGateCheck returns 200 if the gate of the canary is open, the prefix selects the gate kind,
+// the check is called by Flagger and doesn't require a token as it doesn't change the gate
+func HandleGateCheck(logger *zap.SugaredLogger, gate *GateStorage, prefix string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeGateRequest(logger, w, r)
+		if !ok {
+			return
+		}
+
+		canaryName := prefix + fmt.Sprintf("%s.%s", req.Name, req.Namespace)
+		approved := gate.isOpen(canaryName)
+		if approved {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("Approved"))
+		} else {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("Forbidden"))
+		}
+
+		logger.Infof("%s gate check: approved %v", canaryName, approved)
+	}
+}
+
+// HandleGateChange opens or closes the gate of the canary, the prefix selects the gate kind
+func HandleGateChange(logger *zap.SugaredLogger, gate *GateStorage, prefix string, open bool) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeGateRequest(logger, w, r)
+		if !ok {
+			return
+		}
+
+		canary := fmt.Sprintf("%s.%s", req.Name, req.Namespace)
+		if !gate.authorize(canary, r) {
+			logger.Warnf("%s gate change rejected: invalid token", prefix+canary)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		entry := GateAuditEntry{
+			Time:    time.Now().UTC(),
+			Gate:    prefix + canary,
+			Action:  "close",
+			User:    req.User,
+			Address: r.RemoteAddr,
+		}
+		if entry.User == "" {
+			entry.User = "anonymous"
+		}
+
+		if open {
+			var ttl time.Duration
+			if req.TTL != "" {
+				d, err := time.ParseDuration(req.TTL)
+				if err != nil || d <= 0 {
+					logger.Errorf("invalid gate ttl %q", req.TTL)
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				ttl = d
+			}
+			entry.Action = "open"
+			if expires := gate.openFor(entry.Gate, ttl); !expires.IsZero() {
+				entry.Expires = &expires
+			}
+		} else {
+			gate.close(entry.Gate)
+		}
+		gate.record(entry)
+
+		w.WriteHeader(http.StatusAccepted)
+
+		logger.With("user", entry.User, "address", entry.Address).
+			Infof("%s gate %s", entry.Gate, entry.Action)
+	}
+}
+
+// HandleGateAudit returns the gate changes audit log readable with the request token
+func HandleGateAudit(logger *zap.SugaredLogger, gate *GateStorage) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entries, ok := gate.authorizeAudit(r)
+		if !ok {
+			logger.With("address", r.RemoteAddr).Warn("gate audit rejected: invalid token")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(entries)
+	}
+}
+
+func decodeGateRequest(logger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request) (*GateRequest, bool) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.Error("reading the request body failed", zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
+	}
+	defer r.Body.Close()
+
+	req := &GateRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		logger.Error("decoding the request body failed", zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
 	}
-	return
+	return req, true
 }
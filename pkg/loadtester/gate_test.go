@@ -0,0 +1,154 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGate_OpenWithTTL(t *testing.T) {
+	mocks := newServerFixture()
+	gate := NewGateStorage("in-memory")
+
+	resp := httptest.NewRecorder()
+	req := newJsonRequest("POST", "/gate/open", &GateRequest{Name: "podinfo", Namespace: "test", TTL: "1h", User: "on-call"})
+	HandleGateChange(mocks.logger, gate, "", true)(resp, req)
+	require.Equal(t, http.StatusAccepted, resp.Code)
+	assert.True(t, gate.isOpen("podinfo.test"))
+
+	// the gate closes itself after the ttl
+	gate.data.Store("podinfo.test", gateState{open: true, expires: time.Now().Add(-time.Second)})
+	resp = httptest.NewRecorder()
+	req = newJsonRequest("POST", "/gate/check", &GateRequest{Name: "podinfo", Namespace: "test"})
+	HandleGateCheck(mocks.logger, gate, "")(resp, req)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = newJsonRequest("POST", "/gate/open", &GateRequest{Name: "podinfo", Namespace: "test", TTL: "soon"})
+	HandleGateChange(mocks.logger, gate, "", true)(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestGate_Tokens(t *testing.T) {
+	mocks := newServerFixture()
+	gate := NewGateStorage("in-memory")
+
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"podinfo.test": "s3cr3t"}`), 0600))
+	require.NoError(t, gate.LoadTokens(path))
+
+	// the canaries without a token are not protected
+	resp := httptest.NewRecorder()
+	req := newJsonRequest("POST", "/gate/open", &GateRequest{Name: "other", Namespace: "test"})
+	HandleGateChange(mocks.logger, gate, "", true)(resp, req)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = newJsonRequest("POST", "/rollback/open", &GateRequest{Name: "podinfo", Namespace: "test"})
+	HandleGateChange(mocks.logger, gate, "rollback.", true)(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.False(t, gate.isOpen("rollback.podinfo.test"))
+
+	resp = httptest.NewRecorder()
+	req = newJsonRequest("POST", "/rollback/open", &GateRequest{Name: "podinfo", Namespace: "test"})
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	HandleGateChange(mocks.logger, gate, "rollback.", true)(resp, req)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.True(t, gate.isOpen("rollback.podinfo.test"))
+}
+
+func TestGate_Audit(t *testing.T) {
+	mocks := newServerFixture()
+	gate := NewGateStorage("in-memory")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "admin"), []byte("adm1n\n"), 0600))
+	require.NoError(t, gate.LoadAdminToken(filepath.Join(dir, "admin")))
+
+	HandleGateChange(mocks.logger, gate, "", true)(httptest.NewRecorder(),
+		newJsonRequest("POST", "/gate/open", &GateRequest{Name: "podinfo", Namespace: "test", TTL: "30m", User: "alice"}))
+	HandleGateChange(mocks.logger, gate, "", false)(httptest.NewRecorder(),
+		newJsonRequest("POST", "/gate/close", &GateRequest{Name: "podinfo", Namespace: "test"}))
+
+	resp := httptest.NewRecorder()
+	HandleGateAudit(mocks.logger, gate)(resp, httptest.NewRequest("GET", "/gate/audit", nil))
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/gate/audit", nil)
+	req.Header.Set("Authorization", "Bearer adm1n")
+	HandleGateAudit(mocks.logger, gate)(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var entries []GateAuditEntry
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, "podinfo.test", entries[0].Gate)
+	assert.Equal(t, "open", entries[0].Action)
+	assert.Equal(t, "alice", entries[0].User)
+	require.NotNil(t, entries[0].Expires)
+	assert.Equal(t, "close", entries[1].Action)
+	assert.Equal(t, "anonymous", entries[1].User)
+	assert.Nil(t, entries[1].Expires)
+}
+
+func TestGate_AuditCanaryToken(t *testing.T) {
+	mocks := newServerFixture()
+	gate := NewGateStorage("in-memory")
+
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"podinfo.test": "s3cr3t", "other.test": "0th3r"}`), 0600))
+	require.NoError(t, gate.LoadTokens(path))
+
+	for _, r := range []struct {
+		name   string
+		token  string
+		prefix string
+	}{{"podinfo", "s3cr3t", ""}, {"podinfo", "s3cr3t", "rollback."}, {"other", "0th3r", ""}} {
+		req := newJsonRequest("POST", "/gate/open", &GateRequest{Name: r.name, Namespace: "test"})
+		req.Header.Set("Authorization", "Bearer "+r.token)
+		HandleGateChange(mocks.logger, gate, r.prefix, true)(httptest.NewRecorder(), req)
+	}
+
+	// a canary token reads the changes of its gates only
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/gate/audit", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	HandleGateAudit(mocks.logger, gate)(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var entries []GateAuditEntry
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, "podinfo.test", entries[0].Gate)
+	assert.Equal(t, "rollback.podinfo.test", entries[1].Gate)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/gate/audit", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	HandleGateAudit(mocks.logger, gate)(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
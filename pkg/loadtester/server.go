@@ -43,163 +43,13 @@ func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogge
 		w.WriteHeader(http.StatusForbidden)
 		w.Write([]byte("Forbidden"))
 	})
-	mux.HandleFunc("/gate/check", func(w http.ResponseWriter, r *http.Request) {
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			logger.Error("reading the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
-
-		canary := &flaggerv1.CanaryWebhookPayload{}
-		err = json.Unmarshal(body, canary)
-		if err != nil {
-			logger.Error("decoding the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		canaryName := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
-		approved := gate.isOpen(canaryName)
-		if approved {
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("Approved"))
-		} else {
-			w.WriteHeader(http.StatusForbidden)
-			w.Write([]byte("Forbidden"))
-		}
-
-		logger.Infof("%s gate check: approved %v", canaryName, approved)
-	})
-
-	mux.HandleFunc("/gate/open", func(w http.ResponseWriter, r *http.Request) {
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			logger.Error("reading the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
-
-		canary := &flaggerv1.CanaryWebhookPayload{}
-		err = json.Unmarshal(body, canary)
-		if err != nil {
-			logger.Error("decoding the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		canaryName := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
-		gate.open(canaryName)
-
-		w.WriteHeader(http.StatusAccepted)
-
-		logger.Infof("%s gate opened", canaryName)
-	})
-
-	mux.HandleFunc("/gate/close", func(w http.ResponseWriter, r *http.Request) {
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			logger.Error("reading the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
-
-		canary := &flaggerv1.CanaryWebhookPayload{}
-		err = json.Unmarshal(body, canary)
-		if err != nil {
-			logger.Error("decoding the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		canaryName := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
-		gate.close(canaryName)
-
-		w.WriteHeader(http.StatusAccepted)
-
-		logger.Infof("%s gate closed", canaryName)
-	})
-
-	mux.HandleFunc("/rollback/check", func(w http.ResponseWriter, r *http.Request) {
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			logger.Error("reading the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
-
-		canary := &flaggerv1.CanaryWebhookPayload{}
-		err = json.Unmarshal(body, canary)
-		if err != nil {
-			logger.Error("decoding the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		canaryName := fmt.Sprintf("rollback.%s.%s", canary.Name, canary.Namespace)
-		approved := gate.isOpen(canaryName)
-		if approved {
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("Approved"))
-		} else {
-			w.WriteHeader(http.StatusForbidden)
-			w.Write([]byte("Forbidden"))
-		}
-
-		logger.Infof("%s rollback check: approved %v", canaryName, approved)
-	})
-	mux.HandleFunc("/rollback/open", func(w http.ResponseWriter, r *http.Request) {
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			logger.Error("reading the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
-
-		canary := &flaggerv1.CanaryWebhookPayload{}
-		err = json.Unmarshal(body, canary)
-		if err != nil {
-			logger.Error("decoding the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		canaryName := fmt.Sprintf("rollback.%s.%s", canary.Name, canary.Namespace)
-		gate.open(canaryName)
-
-		w.WriteHeader(http.StatusAccepted)
-
-		logger.Infof("%s rollback opened", canaryName)
-	})
-	mux.HandleFunc("/rollback/close", func(w http.ResponseWriter, r *http.Request) {
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			logger.Error("reading the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
-
-		canary := &flaggerv1.CanaryWebhookPayload{}
-		err = json.Unmarshal(body, canary)
-		if err != nil {
-			logger.Error("decoding the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		canaryName := fmt.Sprintf("rollback.%s.%s", canary.Name, canary.Namespace)
-		gate.close(canaryName)
-
-		w.WriteHeader(http.StatusAccepted)
-
-		logger.Infof("%s rollback closed", canaryName)
-	})
+	mux.HandleFunc("/gate/check", HandleGateCheck(logger, gate, ""))
+	mux.HandleFunc("/gate/open", HandleGateChange(logger, gate, "", true))
+	mux.HandleFunc("/gate/close", HandleGateChange(logger, gate, "", false))
+	mux.HandleFunc("/gate/audit", HandleGateAudit(logger, gate))
+	mux.HandleFunc("/rollback/check", HandleGateCheck(logger, gate, "rollback."))
+	mux.HandleFunc("/rollback/open", HandleGateChange(logger, gate, "rollback.", true))
+	mux.HandleFunc("/rollback/close", HandleGateChange(logger, gate, "rollback.", false))
 
 	mux.HandleFunc("/", HandleNewTask(logger, taskRunner))
 	srv := &http.Server{
@@ -380,6 +230,66 @@ func HandleNewTask(logger *zap.SugaredLogger, taskRunner TaskRunnerInterface) fu
 				return
 			}
 
+			// replay recorded requests against the canary (blocking task)
+			if typ == TaskTypeReplay {
+				taskFactory, _ := GetTaskFactory(typ)
+				task, err := taskFactory(metadata, fmt.Sprintf("%s.%s", payload.Name, payload.Namespace), logger)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(err.Error()))
+					return
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), taskRunner.Timeout())
+				defer cancel()
+
+				result := task.Run(ctx)
+				if !result.ok {
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write(result.out)
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+				if rtnCmdOutput {
+					w.Write(result.out)
+				}
+				return
+			}
+
+			// run cdn purge and cache warm tasks after a successful promotion (blocking task)
+			if typ == TaskTypeCDNPurge || typ == TaskTypeCacheWarm {
+				if payload.Phase == flaggerv1.CanaryPhaseFailed {
+					logger.With("canary", payload.Name).Infof("%s skipped for failed canary", typ)
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+
+				taskFactory, _ := GetTaskFactory(typ)
+				task, err := taskFactory(metadata, fmt.Sprintf("%s.%s", payload.Name, payload.Namespace), logger)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(err.Error()))
+					return
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), taskRunner.Timeout())
+				defer cancel()
+
+				result := task.Run(ctx)
+				if !result.ok {
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write(result.out)
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+				if rtnCmdOutput {
+					w.Write(result.out)
+				}
+				return
+			}
+
 			taskFactory, ok := GetTaskFactory(typ)
 			if !ok {
 				w.WriteHeader(http.StatusBadRequest)
@@ -0,0 +1,299 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"go.uber.org/zap"
+)
+
+const (
+	TaskTypeCDNPurge  = "cdn-purge"
+	TaskTypeCacheWarm = "cache-warm"
+
+	cdnProviderCloudFront = "cloudfront"
+	cdnProviderFastly     = "fastly"
+	cdnProviderCloudflare = "cloudflare"
+)
+
+var (
+	fastlyAPIURL     = "https://api.fastly.com"
+	cloudflareAPIURL = "https://api.cloudflare.com/client/v4"
+)
+
+func init() {
+	taskFactories.Store(TaskTypeCDNPurge, func(metadata map[string]string, canary string, logger *zap.SugaredLogger) (Task, error) {
+		provider := metadata["provider"]
+		id := metadata["id"]
+		if provider == "" || id == "" {
+			return nil, errors.New("provider and id are required metadata")
+		}
+
+		task := &CDNPurgeTask{
+			TaskBase: TaskBase{canary, logger},
+			provider: provider,
+			id:       id,
+			client:   http.DefaultClient,
+		}
+		if paths, ok := metadata["paths"]; ok {
+			task.paths = splitList(paths)
+		}
+
+		switch provider {
+		case cdnProviderCloudFront:
+			if len(task.paths) == 0 {
+				task.paths = []string{"/*"}
+			}
+		case cdnProviderFastly:
+			task.token = os.Getenv("FASTLY_API_TOKEN")
+		case cdnProviderCloudflare:
+			task.token = os.Getenv("CLOUDFLARE_API_TOKEN")
+		default:
+			return nil, fmt.Errorf("unknown cdn provider %s, can be cloudfront, fastly or cloudflare", provider)
+		}
+		if provider != cdnProviderCloudFront && task.token == "" {
+			return nil, fmt.Errorf("%s API token not found in the environment", provider)
+		}
+
+		return task, nil
+	})
+
+	taskFactories.Store(TaskTypeCacheWarm, func(metadata map[string]string, canary string, logger *zap.SugaredLogger) (Task, error) {
+		url := metadata["url"]
+		if url == "" {
+			return nil, errors.New("url is a required metadata")
+		}
+
+		task := &CacheWarmTask{
+			TaskBase: TaskBase{canary, logger},
+			url:      strings.TrimSuffix(url, "/"),
+			paths:    []string{"/"},
+			count:    1,
+			headers:  map[string]string{},
+			client:   http.DefaultClient,
+		}
+		if paths, ok := metadata["paths"]; ok {
+			task.paths = splitList(paths)
+		}
+		if count, ok := metadata["count"]; ok {
+			n, err := strconv.Atoi(count)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("metadata count must be a positive integer: %s", count)
+			}
+			task.count = n
+		}
+		if headers, ok := metadata["headers"]; ok {
+			for _, header := range splitList(headers) {
+				kv := strings.SplitN(header, ":", 2)
+				if len(kv) != 2 {
+					return nil, fmt.Errorf("metadata headers must be a list of name:value pairs: %s", header)
+				}
+				task.headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+			}
+		}
+
+		return task, nil
+	})
+}
+
+// CDNPurgeTask invalidates the CDN cache after a promotion
+type CDNPurgeTask struct {
+	TaskBase
+	provider string
+	id       string
+	paths    []string
+	token    string
+	client   *http.Client
+}
+
+func (task *CDNPurgeTask) Hash() string {
+	return hash(task.canary + task.provider + task.id + strings.Join(task.paths, ","))
+}
+
+func (task *CDNPurgeTask) Run(ctx context.Context) *TaskRunResult {
+	var err error
+	switch task.provider {
+	case cdnProviderCloudFront:
+		err = task.purgeCloudFront(ctx)
+	case cdnProviderFastly:
+		err = task.purgeFastly(ctx)
+	case cdnProviderCloudflare:
+		err = task.purgeCloudflare(ctx)
+	}
+	if err != nil {
+		task.logger.With("canary", task.canary).Errorf("%s cache purge failed: %v", task.provider, err)
+		return &TaskRunResult{false, []byte(err.Error())}
+	}
+
+	out := fmt.Sprintf("%s cache purged for %s", task.provider, task.id)
+	task.logger.With("canary", task.canary).Info(out)
+	return &TaskRunResult{true, []byte(out)}
+}
+
+func (task *CDNPurgeTask) String() string {
+	return fmt.Sprintf("%s purge %s %s", task.provider, task.id, strings.Join(task.paths, ","))
+}
+
+// purgeCloudFront creates an invalidation for the distribution paths,
+// the AWS credentials are loaded from the default chain
+func (task *CDNPurgeTask) purgeCloudFront(ctx context.Context) error {
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("error creating aws session: %w", err)
+	}
+
+	paths := make([]*string, 0, len(task.paths))
+	for _, p := range task.paths {
+		paths = append(paths, aws.String(p))
+	}
+
+	_, err = cloudfront.New(sess).CreateInvalidationWithContext(ctx, &cloudfront.CreateInvalidationInput{
+		DistributionId: aws.String(task.id),
+		InvalidationBatch: &cloudfront.InvalidationBatch{
+			CallerReference: aws.String(fmt.Sprintf("flagger-%s-%d", task.canary, time.Now().Unix())),
+			Paths: &cloudfront.Paths{
+				Items:    paths,
+				Quantity: aws.Int64(int64(len(paths))),
+			},
+		},
+	})
+	return err
+}
+
+// purgeFastly purges the service surrogate keys listed in paths,
+// or the whole service cache if no keys are specified
+func (task *CDNPurgeTask) purgeFastly(ctx context.Context) error {
+	if len(task.paths) == 0 {
+		return task.post(ctx, fmt.Sprintf("%s/service/%s/purge_all", fastlyAPIURL, task.id), nil,
+			map[string]string{"Fastly-Key": task.token})
+	}
+
+	body, _ := json.Marshal(map[string][]string{"surrogate_keys": task.paths})
+	return task.post(ctx, fmt.Sprintf("%s/service/%s/purge", fastlyAPIURL, task.id), body,
+		map[string]string{"Fastly-Key": task.token})
+}
+
+// purgeCloudflare purges the zone URLs listed in paths,
+// or the whole zone cache if no URLs are specified
+func (task *CDNPurgeTask) purgeCloudflare(ctx context.Context) error {
+	var payload interface{} = map[string]bool{"purge_everything": true}
+	if len(task.paths) > 0 {
+		payload = map[string][]string{"files": task.paths}
+	}
+
+	body, _ := json.Marshal(payload)
+	return task.post(ctx, fmt.Sprintf("%s/zones/%s/purge_cache", cloudflareAPIURL, task.id), body,
+		map[string]string{"Authorization": "Bearer " + task.token})
+}
+
+func (task *CDNPurgeTask) post(ctx context.Context, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := task.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("purge request failed with status %d: %s", resp.StatusCode, string(b))
+	}
+	return nil
+}
+
+// CacheWarmTask sends requests to the apex host to populate the cache after a promotion
+type CacheWarmTask struct {
+	TaskBase
+	url     string
+	paths   []string
+	count   int
+	headers map[string]string
+	client  *http.Client
+}
+
+func (task *CacheWarmTask) Hash() string {
+	return hash(task.canary + task.url + strings.Join(task.paths, ","))
+}
+
+func (task *CacheWarmTask) Run(ctx context.Context) *TaskRunResult {
+	failed := 0
+	for i := 0; i < task.count; i++ {
+		for _, path := range task.paths {
+			if err := task.request(ctx, task.url+path); err != nil {
+				task.logger.With("canary", task.canary).Infof("cache warm %s failed: %v", path, err)
+				failed++
+			}
+		}
+	}
+
+	total := task.count * len(task.paths)
+	out := fmt.Sprintf("%d out of %d cache warm requests failed", failed, total)
+	task.logger.With("canary", task.canary).Infof("cache warm finished %s", out)
+	return &TaskRunResult{failed == 0, []byte(out)}
+}
+
+func (task *CacheWarmTask) String() string {
+	return fmt.Sprintf("cache warm %s %s", task.url, strings.Join(task.paths, ","))
+}
+
+func (task *CacheWarmTask) request(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range task.headers {
+		if strings.EqualFold(k, "Host") {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+
+	resp, err := task.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
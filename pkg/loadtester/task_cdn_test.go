@@ -0,0 +1,123 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+func TestTaskCDNPurge(t *testing.T) {
+	logger, _ := logger.NewLoggerWithEncoding("debug", "console")
+	taskFactory, ok := GetTaskFactory(TaskTypeCDNPurge)
+	require.True(t, ok, "Failed to get cdn-purge task factory")
+
+	t.Run("fastly", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/service/svc-1/purge_all", r.URL.Path)
+			assert.Equal(t, "fastly-token", r.Header.Get("Fastly-Key"))
+			w.Write([]byte(`{"status":"ok"}`))
+		}))
+		defer ts.Close()
+		fastlyAPIURL = ts.URL
+		t.Setenv("FASTLY_API_TOKEN", "fastly-token")
+
+		task, err := taskFactory(map[string]string{"provider": "fastly", "id": "svc-1"}, "podinfo.default", logger)
+		require.NoError(t, err)
+
+		result := task.Run(context.TODO())
+		assert.True(t, result.ok)
+	})
+
+	t.Run("cloudflare", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/zones/zone-1/purge_cache", r.URL.Path)
+			assert.Equal(t, "Bearer cf-token", r.Header.Get("Authorization"))
+
+			var payload map[string][]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			assert.Equal(t, []string{"https://app.example.com/"}, payload["files"])
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer ts.Close()
+		cloudflareAPIURL = ts.URL
+		t.Setenv("CLOUDFLARE_API_TOKEN", "cf-token")
+
+		task, err := taskFactory(map[string]string{
+			"provider": "cloudflare",
+			"id":       "zone-1",
+			"paths":    "https://app.example.com/",
+		}, "podinfo.default", logger)
+		require.NoError(t, err)
+
+		result := task.Run(context.TODO())
+		assert.False(t, result.ok)
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		t.Setenv("FASTLY_API_TOKEN", "")
+		_, err := taskFactory(map[string]string{"provider": "fastly", "id": "svc-1"}, "podinfo.default", logger)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, err := taskFactory(map[string]string{"provider": "akamai", "id": "1"}, "podinfo.default", logger)
+		require.Error(t, err)
+	})
+}
+
+func TestTaskCacheWarm(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		assert.Equal(t, "app.example.com", r.Host)
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	logger, _ := logger.NewLoggerWithEncoding("debug", "console")
+	taskFactory, ok := GetTaskFactory(TaskTypeCacheWarm)
+	require.True(t, ok, "Failed to get cache-warm task factory")
+
+	task, err := taskFactory(map[string]string{
+		"url":     ts.URL,
+		"paths":   "/, /api/info",
+		"count":   "2",
+		"headers": "Host: app.example.com",
+	}, "podinfo.default", logger)
+	require.NoError(t, err)
+
+	result := task.Run(context.TODO())
+	assert.True(t, result.ok)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&requests))
+
+	task, err = taskFactory(map[string]string{"url": ts.URL, "paths": "/missing", "headers": "Host: app.example.com"},
+		"podinfo.default", logger)
+	require.NoError(t, err)
+	assert.False(t, task.Run(context.TODO()).ok)
+}
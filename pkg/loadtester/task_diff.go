@@ -0,0 +1,246 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const TaskTypeDiff = "diff"
+
+var (
+	diffRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "flagger_loadtester",
+		Name:      "diff_requests_total",
+		Help:      "Total number of requests replayed against primary and canary.",
+	}, []string{"canary"})
+
+	diffMismatches = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "flagger_loadtester",
+		Name:      "diff_mismatches_total",
+		Help:      "Total number of responses that differ between primary and canary.",
+	}, []string{"canary"})
+
+	diffMismatchRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: "flagger_loadtester",
+		Name:      "diff_mismatch_rate",
+		Help:      "Percentage of responses that differ between primary and canary in the last run.",
+	}, []string{"canary"})
+)
+
+func init() {
+	prometheus.MustRegister(diffRequests, diffMismatches, diffMismatchRate)
+
+	taskFactories.Store(TaskTypeDiff, func(metadata map[string]string, canary string, logger *zap.SugaredLogger) (Task, error) {
+		primary := metadata["primary"]
+		canaryURL := metadata["canary"]
+		if primary == "" || canaryURL == "" {
+			return nil, errors.New("primary and canary are required metadata")
+		}
+
+		task := &DiffTask{
+			TaskBase:   TaskBase{canary, logger},
+			primaryURL: strings.TrimSuffix(primary, "/"),
+			canaryURL:  strings.TrimSuffix(canaryURL, "/"),
+			method:     http.MethodGet,
+			paths:      []string{"/"},
+			count:      1,
+			client:     http.DefaultClient,
+		}
+
+		if method, ok := metadata["method"]; ok {
+			task.method = strings.ToUpper(method)
+		}
+		if paths, ok := metadata["paths"]; ok {
+			task.paths = splitList(paths)
+		}
+		if count, ok := metadata["count"]; ok {
+			n, err := strconv.Atoi(count)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("metadata count must be a positive integer: %s", count)
+			}
+			task.count = n
+		}
+		if fields, ok := metadata["ignoreFields"]; ok {
+			task.ignoreFields = splitList(fields)
+		}
+		if expr, ok := metadata["ignoreRegex"]; ok {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("metadata ignoreRegex is invalid: %w", err)
+			}
+			task.ignoreRegex = re
+		}
+		if statusOnly, ok := metadata["statusOnly"]; ok {
+			task.statusOnly, _ = strconv.ParseBool(statusOnly)
+		}
+
+		return task, nil
+	})
+}
+
+// DiffTask replays requests against the primary and canary,
+// and compares the responses status code and body
+type DiffTask struct {
+	TaskBase
+	primaryURL   string
+	canaryURL    string
+	method       string
+	paths        []string
+	count        int
+	ignoreFields []string
+	ignoreRegex  *regexp.Regexp
+	statusOnly   bool
+	client       *http.Client
+}
+
+func (task *DiffTask) Hash() string {
+	return hash(task.canary + task.primaryURL + task.canaryURL + strings.Join(task.paths, ","))
+}
+
+func (task *DiffTask) Run(ctx context.Context) *TaskRunResult {
+	total, mismatches := 0, 0
+	for i := 0; i < task.count; i++ {
+		for _, path := range task.paths {
+			equal, err := task.compare(ctx, path)
+			if err != nil {
+				task.logger.With("canary", task.canary).Errorf("diff request %s failed: %v", path, err)
+				return &TaskRunResult{false, nil}
+			}
+			total++
+			if !equal {
+				mismatches++
+			}
+		}
+	}
+
+	rate := float64(mismatches) / float64(total) * 100
+	diffRequests.WithLabelValues(task.canary).Add(float64(total))
+	diffMismatches.WithLabelValues(task.canary).Add(float64(mismatches))
+	diffMismatchRate.WithLabelValues(task.canary).Set(rate)
+
+	out := fmt.Sprintf("%d out of %d responses differ (%.2f%%)", mismatches, total, rate)
+	task.logger.With("canary", task.canary).Infof("diff finished %s", out)
+	return &TaskRunResult{true, []byte(out)}
+}
+
+func (task *DiffTask) String() string {
+	return fmt.Sprintf("diff %s %s", task.primaryURL, task.canaryURL)
+}
+
+// compare sends the same request to primary and canary
+// and returns true if the normalized responses are equal
+func (task *DiffTask) compare(ctx context.Context, path string) (bool, error) {
+	primaryStatus, primaryBody, err := task.request(ctx, task.primaryURL+path)
+	if err != nil {
+		return false, err
+	}
+	canaryStatus, canaryBody, err := task.request(ctx, task.canaryURL+path)
+	if err != nil {
+		return false, err
+	}
+
+	if primaryStatus != canaryStatus {
+		task.logger.With("canary", task.canary).
+			Infof("diff %s status mismatch primary %d canary %d", path, primaryStatus, canaryStatus)
+		return false, nil
+	}
+	if task.statusOnly {
+		return true, nil
+	}
+
+	if !bytes.Equal(task.normalize(primaryBody), task.normalize(canaryBody)) {
+		task.logger.With("canary", task.canary).Infof("diff %s body mismatch", path)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (task *DiffTask) request(ctx context.Context, url string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, task.method, url, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp, err := task.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("reading response body failed: %w", err)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// normalize removes the ignored JSON fields and regex matches from the response body
+func (task *DiffTask) normalize(body []byte) []byte {
+	if len(task.ignoreFields) > 0 {
+		var doc interface{}
+		if err := json.Unmarshal(body, &doc); err == nil {
+			for _, field := range task.ignoreFields {
+				deleteField(doc, strings.Split(field, "."))
+			}
+			if b, err := json.Marshal(doc); err == nil {
+				body = b
+			}
+		}
+	}
+	if task.ignoreRegex != nil {
+		body = task.ignoreRegex.ReplaceAll(body, nil)
+	}
+	return body
+}
+
+// deleteField removes a dot separated path from a decoded JSON document
+func deleteField(doc interface{}, path []string) {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			delete(v, path[0])
+			return
+		}
+		deleteField(v[path[0]], path[1:])
+	case []interface{}:
+		for _, item := range v {
+			deleteField(item, path)
+		}
+	}
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
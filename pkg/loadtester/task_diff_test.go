@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+func TestTaskDiff(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/version":
+			w.Write([]byte(`{"version":"1.0.0","hostname":"podinfo-primary-1"}`))
+		default:
+			w.Write([]byte(`{"status":"ok","hostname":"podinfo-primary-1"}`))
+		}
+	}))
+	defer primary.Close()
+
+	canary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/version":
+			w.Write([]byte(`{"version":"1.1.0","hostname":"podinfo-1"}`))
+		default:
+			w.Write([]byte(`{"hostname":"podinfo-1","status":"ok"}`))
+		}
+	}))
+	defer canary.Close()
+
+	logger, _ := logger.NewLoggerWithEncoding("debug", "console")
+	taskFactory, ok := GetTaskFactory(TaskTypeDiff)
+	require.True(t, ok, "Failed to get diff task factory")
+
+	t.Run("missing metadata", func(t *testing.T) {
+		_, err := taskFactory(map[string]string{"primary": primary.URL}, "podinfo.default", logger)
+		require.Error(t, err)
+	})
+
+	t.Run("ignore fields", func(t *testing.T) {
+		task, err := taskFactory(map[string]string{
+			"primary":      primary.URL,
+			"canary":       canary.URL,
+			"paths":        "/healthz, /version",
+			"ignoreFields": "hostname",
+		}, "podinfo.default", logger)
+		require.NoError(t, err)
+
+		result := task.Run(context.TODO())
+		assert.True(t, result.ok)
+		assert.Equal(t, float64(50), testutil.ToFloat64(diffMismatchRate.WithLabelValues("podinfo.default")))
+	})
+
+	t.Run("status only", func(t *testing.T) {
+		task, err := taskFactory(map[string]string{
+			"primary":    primary.URL,
+			"canary":     canary.URL,
+			"paths":      "/healthz,/version",
+			"statusOnly": "true",
+		}, "podinfo.test", logger)
+		require.NoError(t, err)
+
+		result := task.Run(context.TODO())
+		assert.True(t, result.ok)
+		assert.Equal(t, float64(0), testutil.ToFloat64(diffMismatchRate.WithLabelValues("podinfo.test")))
+	})
+}
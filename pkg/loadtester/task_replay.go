@@ -0,0 +1,272 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.uber.org/zap"
+	storage "google.golang.org/api/storage/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	TaskTypeReplay = "replay"
+
+	replayFormatVCR   = "vcr"
+	replayFormatJSONL = "jsonl"
+)
+
+func init() {
+	taskFactories.Store(TaskTypeReplay, func(metadata map[string]string, canary string, logger *zap.SugaredLogger) (Task, error) {
+		source := metadata["source"]
+		target := metadata["url"]
+		if source == "" || target == "" {
+			return nil, errors.New("source and url are required metadata")
+		}
+
+		task := &ReplayTask{
+			TaskBase: TaskBase{canary, logger},
+			source:   source,
+			url:      strings.TrimSuffix(target, "/"),
+			format:   replayFormatVCR,
+			count:    1,
+			client:   http.DefaultClient,
+		}
+		if format, ok := metadata["format"]; ok {
+			if format != replayFormatVCR && format != replayFormatJSONL {
+				return nil, fmt.Errorf("unknown replay format %s, can be vcr or jsonl", format)
+			}
+			task.format = format
+		}
+		if count, ok := metadata["count"]; ok {
+			n, err := strconv.Atoi(count)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("metadata count must be a positive integer: %s", count)
+			}
+			task.count = n
+		}
+		if rate, ok := metadata["maxErrorRate"]; ok {
+			v, err := strconv.ParseFloat(rate, 64)
+			if err != nil || v < 0 || v > 100 {
+				return nil, fmt.Errorf("metadata maxErrorRate must be a percentage: %s", rate)
+			}
+			task.maxErrorRate = v
+		}
+
+		return task, nil
+	})
+}
+
+// ReplayTask sends previously recorded production requests to the canary
+type ReplayTask struct {
+	TaskBase
+	source       string
+	url          string
+	format       string
+	count        int
+	maxErrorRate float64
+	client       *http.Client
+}
+
+// recordedRequest is a request captured in a VCR cassette or a JSON lines file
+type recordedRequest struct {
+	Method  string              `json:"method"`
+	URL     string              `json:"url"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    string              `json:"body,omitempty"`
+}
+
+// vcrCassette is the go-vcr cassette format, only the requests are replayed
+type vcrCassette struct {
+	Interactions []struct {
+		Request recordedRequest `json:"request"`
+	} `json:"interactions"`
+}
+
+func (task *ReplayTask) Hash() string {
+	return hash(task.canary + task.source + task.url)
+}
+
+func (task *ReplayTask) Run(ctx context.Context) *TaskRunResult {
+	data, err := task.load(ctx)
+	if err != nil {
+		task.logger.With("canary", task.canary).Errorf("loading recording %s failed: %v", task.source, err)
+		return &TaskRunResult{false, []byte(err.Error())}
+	}
+
+	requests, err := task.parse(data)
+	if err != nil {
+		task.logger.With("canary", task.canary).Errorf("parsing recording %s failed: %v", task.source, err)
+		return &TaskRunResult{false, []byte(err.Error())}
+	}
+	if len(requests) == 0 {
+		return &TaskRunResult{false, []byte(fmt.Sprintf("no requests found in %s", task.source))}
+	}
+
+	failed := 0
+	for i := 0; i < task.count; i++ {
+		for _, r := range requests {
+			if err := task.replay(ctx, r); err != nil {
+				task.logger.With("canary", task.canary).Infof("replay %s %s failed: %v", r.Method, r.URL, err)
+				failed++
+			}
+		}
+	}
+
+	total := task.count * len(requests)
+	errorRate := float64(failed) * 100 / float64(total)
+	out := fmt.Sprintf("%d out of %d replayed requests failed", failed, total)
+	task.logger.With("canary", task.canary).Infof("replay finished %s", out)
+	return &TaskRunResult{errorRate <= task.maxErrorRate, []byte(out)}
+}
+
+func (task *ReplayTask) String() string {
+	return fmt.Sprintf("replay %s to %s", task.source, task.url)
+}
+
+// load reads the recording from S3, GCS, an HTTP server or the local file system
+func (task *ReplayTask) load(ctx context.Context) ([]byte, error) {
+	u, err := url.Parse(task.source)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "s3":
+		sess, err := session.NewSession()
+		if err != nil {
+			return nil, fmt.Errorf("error creating aws session: %w", err)
+		}
+		obj, err := s3.New(sess).GetObjectWithContext(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(u.Host),
+			Key:    aws.String(strings.TrimPrefix(u.Path, "/")),
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer obj.Body.Close()
+		return io.ReadAll(obj.Body)
+	case "gs":
+		svc, err := storage.NewService(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error creating gcs client: %w", err)
+		}
+		resp, err := svc.Objects.Get(u.Host, strings.TrimPrefix(u.Path, "/")).Context(ctx).Download()
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		return io.ReadAll(resp.Body)
+	case "http", "https":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, task.source, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := task.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("download failed with status %d", resp.StatusCode)
+		}
+		return io.ReadAll(resp.Body)
+	default:
+		return os.ReadFile(task.source)
+	}
+}
+
+func (task *ReplayTask) parse(data []byte) ([]recordedRequest, error) {
+	var requests []recordedRequest
+	if task.format == replayFormatJSONL {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var r recordedRequest
+			if err := json.Unmarshal(line, &r); err != nil {
+				return nil, err
+			}
+			requests = append(requests, r)
+		}
+		return requests, scanner.Err()
+	}
+
+	var cassette vcrCassette
+	if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096).Decode(&cassette); err != nil {
+		return nil, err
+	}
+	for _, i := range cassette.Interactions {
+		requests = append(requests, i.Request)
+	}
+	return requests, nil
+}
+
+// replay sends the recorded request path and query to the canary URL
+func (task *ReplayTask) replay(ctx context.Context, r recordedRequest) error {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return err
+	}
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, task.url+u.RequestURI(), strings.NewReader(r.Body))
+	if err != nil {
+		return err
+	}
+	for k, values := range r.Headers {
+		if strings.EqualFold(k, "Host") || strings.EqualFold(k, "Content-Length") {
+			continue
+		}
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+
+	resp, err := task.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
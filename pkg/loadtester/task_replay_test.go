@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+func TestTaskReplay(t *testing.T) {
+	logger, _ := logger.NewLoggerWithEncoding("debug", "console")
+	taskFactory, ok := GetTaskFactory(TaskTypeReplay)
+	require.True(t, ok, "Failed to get replay task factory")
+
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		switch r.URL.RequestURI() {
+		case "/api/info?id=1":
+			assert.Equal(t, http.MethodGet, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		case "/api/echo":
+			b, _ := io.ReadAll(r.Body)
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, `{"msg":"hello"}`, string(b))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	cassette := filepath.Join(dir, "cassette.yaml")
+	require.NoError(t, os.WriteFile(cassette, []byte(`
+version: 1
+interactions:
+- request:
+    method: GET
+    url: https://podinfo.example.com/api/info?id=1
+    headers:
+      Accept:
+      - application/json
+  response:
+    code: 200
+- request:
+    method: POST
+    url: https://podinfo.example.com/api/echo
+    body: '{"msg":"hello"}'
+  response:
+    code: 202
+`), 0644))
+
+	t.Run("vcr", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		task, err := taskFactory(map[string]string{"source": cassette, "url": ts.URL, "count": "2"}, "podinfo.default", logger)
+		require.NoError(t, err)
+
+		result := task.Run(context.TODO())
+		assert.True(t, result.ok, string(result.out))
+		assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		recording := filepath.Join(dir, "requests.jsonl")
+		require.NoError(t, os.WriteFile(recording, []byte(
+			`{"method":"GET","url":"/api/info?id=1","headers":{"Accept":["application/json"]}}
+{"method":"GET","url":"/api/missing"}
+`), 0644))
+
+		task, err := taskFactory(map[string]string{"source": recording, "url": ts.URL, "format": "jsonl"}, "podinfo.default", logger)
+		require.NoError(t, err)
+		result := task.Run(context.TODO())
+		assert.False(t, result.ok)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+		task, err = taskFactory(map[string]string{"source": recording, "url": ts.URL, "format": "jsonl", "maxErrorRate": "50"}, "podinfo.default", logger)
+		require.NoError(t, err)
+		result = task.Run(context.TODO())
+		assert.True(t, result.ok, string(result.out))
+	})
+
+	t.Run("http source", func(t *testing.T) {
+		src := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, cassette)
+		}))
+		defer src.Close()
+
+		task, err := taskFactory(map[string]string{"source": src.URL + "/cassette.yaml", "url": ts.URL}, "podinfo.default", logger)
+		require.NoError(t, err)
+		result := task.Run(context.TODO())
+		assert.True(t, result.ok, string(result.out))
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		_, err := taskFactory(map[string]string{"source": cassette}, "podinfo.default", logger)
+		require.Error(t, err)
+		_, err = taskFactory(map[string]string{"source": cassette, "url": ts.URL, "format": "har"}, "podinfo.default", logger)
+		require.Error(t, err)
+	})
+}
@@ -68,7 +68,8 @@ func (factory Factory) Observer(provider string) Interface {
 		return &NginxObserver{
 			client: factory.Client,
 		}
-	case provider == flaggerv1.KubernetesProvider:
+	case provider == flaggerv1.KubernetesProvider,
+		strings.HasPrefix(provider, flaggerv1.GatewayProvider):
 		return &HttpObserver{
 			client: factory.Client,
 		}
@@ -80,6 +81,18 @@ func (factory Factory) Observer(provider string) Interface {
 		return &TraefikObserver{
 			client: factory.Client,
 		}
+	case provider == flaggerv1.ApisixProvider:
+		return &ApisixObserver{
+			client: factory.Client,
+		}
+	case provider == flaggerv1.ConsulProvider:
+		return &ConsulObserver{
+			client: factory.Client,
+		}
+	case provider == flaggerv1.KumaProvider:
+		return &KumaObserver{
+			client: factory.Client,
+		}
 	case provider == flaggerv1.OsmProvider:
 		return &OsmObserver{
 			client: factory.Client,
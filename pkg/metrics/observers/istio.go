@@ -60,6 +60,44 @@ var istioQueries = map[string]string{
 			)
 		) by (le)
 	)`,
+	"source-request-success-rate": `
+	sum(
+		rate(
+			istio_requests_total{
+				reporter="destination",
+				source_workload="{{ source }}",
+				destination_workload_namespace="{{ namespace }}",
+				destination_workload=~"{{ target }}",
+				response_code!~"5.*"
+			}[{{ interval }}]
+		)
+	) 
+	/ 
+	sum(
+		rate(
+			istio_requests_total{
+				reporter="destination",
+				source_workload="{{ source }}",
+				destination_workload_namespace="{{ namespace }}",
+				destination_workload=~"{{ target }}"
+			}[{{ interval }}]
+		)
+	) 
+	* 100`,
+	"source-request-duration": `
+	histogram_quantile(
+		0.99,
+		sum(
+			rate(
+				istio_request_duration_milliseconds_bucket{
+					reporter="destination",
+					source_workload="{{ source }}",
+					destination_workload_namespace="{{ namespace }}",
+					destination_workload=~"{{ target }}"
+				}[{{ interval }}]
+			)
+		) by (le)
+	)`,
 }
 
 type IstioObserver struct {
@@ -94,3 +132,34 @@ func (ob *IstioObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel)
 	ms := time.Duration(int64(value)) * time.Millisecond
 	return ms, nil
 }
+
+// GetSourceRequestSuccessRate returns the success rate of the requests sent by the model source workload
+func (ob *IstioObserver) GetSourceRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(istioQueries["source-request-success-rate"], model)
+	if err != nil {
+		return 0, fmt.Errorf("rendering query failed: %w", err)
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, fmt.Errorf("running query failed: %w", err)
+	}
+
+	return value, nil
+}
+
+// GetSourceRequestDuration returns the P99 latency of the requests sent by the model source workload
+func (ob *IstioObserver) GetSourceRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(istioQueries["source-request-duration"], model)
+	if err != nil {
+		return 0, fmt.Errorf("rendering query failed: %w", err)
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, fmt.Errorf("running query failed: %w", err)
+	}
+
+	ms := time.Duration(int64(value)) * time.Millisecond
+	return ms, nil
+}
@@ -26,3 +26,10 @@ type Interface interface {
 	GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error)
 	GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error)
 }
+
+// SourceObserver is implemented by the observers that can break down
+// the builtin metrics per traffic source
+type SourceObserver interface {
+	GetSourceRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error)
+	GetSourceRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error)
+}
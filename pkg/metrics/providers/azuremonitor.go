@@ -0,0 +1,264 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// https://learn.microsoft.com/en-us/azure/azure-monitor/logs/api/overview
+const (
+	azureMonitorQueryPath = "/query"
+
+	azureMonitorTenantIDSecretKey      = "tenantId"
+	azureMonitorClientIDSecretKey      = "clientId"
+	azureMonitorClientSecretSecretKey  = "clientSecret"
+	azureMonitorAuthorityHostSecretKey = "authorityHost"
+
+	azureMonitorDefaultAuthorityHost = "https://login.microsoftonline.com"
+
+	// azureMonitorTokenExpiryDelta is subtracted from the token lifetime
+	// so that a token is never used right before it expires
+	azureMonitorTokenExpiryDelta = time.Minute
+)
+
+// AzureMonitorProvider executes Kusto queries against a Log Analytics workspace
+// or an Application Insights app using AAD client credentials
+type AzureMonitorProvider struct {
+	timeout       time.Duration
+	url           url.URL
+	timespan      string
+	tenantID      string
+	clientID      string
+	clientSecret  string
+	authorityHost string
+	client        *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+type azureMonitorQueryResponse struct {
+	Tables []struct {
+		Name    string `json:"name"`
+		Columns []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		} `json:"columns"`
+		Rows [][]interface{} `json:"rows"`
+	} `json:"tables"`
+}
+
+type azureMonitorTokenResponse struct {
+	AccessToken string      `json:"access_token"`
+	ExpiresIn   json.Number `json:"expires_in"`
+}
+
+// NewAzureMonitorProvider takes a metric interval, a provider spec and the credentials map,
+// validates the address, extracts the AAD service principal credentials and
+// returns an Azure Monitor client ready to execute queries against the workspace or app of the address
+func NewAzureMonitorProvider(metricInterval string,
+	provider flaggerv1.MetricTemplateProvider,
+	credentials map[string][]byte) (*AzureMonitorProvider, error) {
+
+	amURL, err := url.Parse(provider.Address)
+	if provider.Address == "" || err != nil || amURL.Host == "" {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	interval, err := time.ParseDuration(metricInterval)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metric interval: %w", err)
+	}
+
+	am := AzureMonitorProvider{
+		timeout:       5 * time.Second,
+		url:           *amURL,
+		timespan:      fmt.Sprintf("PT%dS", int64(interval.Seconds())),
+		authorityHost: azureMonitorDefaultAuthorityHost,
+		client:        http.DefaultClient,
+	}
+
+	for key, field := range map[string]*string{
+		azureMonitorTenantIDSecretKey:     &am.tenantID,
+		azureMonitorClientIDSecretKey:     &am.clientID,
+		azureMonitorClientSecretSecretKey: &am.clientSecret,
+	} {
+		b, ok := credentials[key]
+		if !ok || len(b) == 0 {
+			return nil, fmt.Errorf("%s credentials does not contain %s", provider.Type, key)
+		}
+		*field = string(b)
+	}
+
+	if b, ok := credentials[azureMonitorAuthorityHostSecretKey]; ok && len(b) > 0 {
+		am.authorityHost = strings.TrimSuffix(string(b), "/")
+	}
+
+	return &am, nil
+}
+
+// RunQuery executes the Kusto query over the metric interval and returns
+// the last column of the last row of the primary result table
+func (p *AzureMonitorProvider) RunQuery(query string) (float64, error) {
+	b, err := p.query(query)
+	if err != nil {
+		return 0, err
+	}
+
+	var result azureMonitorQueryResponse
+	if err := json.Unmarshal(b, &result); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	if len(result.Tables) < 1 || len(result.Tables[0].Rows) < 1 {
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	}
+
+	rows := result.Tables[0].Rows
+	row := rows[len(rows)-1]
+	if len(row) < 1 || row[len(row)-1] == nil {
+		return 0, fmt.Errorf("%w", ErrNoValuesFound)
+	}
+
+	switch v := row[len(row)-1].(type) {
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("the last column of the result is not a number: %v", v)
+	}
+}
+
+// IsOnline acquires an AAD token and runs a trivial query,
+// returns an error if the credentials are invalid or the API is unreachable
+func (p *AzureMonitorProvider) IsOnline() (bool, error) {
+	if _, err := p.query("print 1"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (p *AzureMonitorProvider) query(query string) ([]byte, error) {
+	token, err := p.getToken()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"query":    query,
+		"timespan": p.timespan,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling query: %w", err)
+	}
+
+	u := p.url
+	u.Path = path.Join(p.url.Path, azureMonitorQueryPath)
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return p.do(req)
+}
+
+// getToken returns the cached access token or requests a new one
+// with the client credentials grant, scoped to the API host of the address
+func (p *AzureMonitorProvider) getToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Now().Before(p.tokenExpiry) {
+		return p.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    []string{"client_credentials"},
+		"client_id":     []string{p.clientID},
+		"client_secret": []string{p.clientSecret},
+		"scope":         []string{fmt.Sprintf("%s://%s/.default", p.url.Scheme, p.url.Host)},
+	}
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", p.authorityHost, url.PathEscape(p.tenantID))
+
+	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	b, err := p.do(req)
+	if err != nil {
+		return "", fmt.Errorf("error acquiring token: %w", err)
+	}
+
+	var result azureMonitorTokenResponse
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("error unmarshaling token response: %w", err)
+	}
+	if result.AccessToken == "" {
+		return "", fmt.Errorf("token response does not contain an access token")
+	}
+
+	expiresIn, _ := result.ExpiresIn.Int64()
+	p.token = result.AccessToken
+	p.tokenExpiry = time.Now().Add(time.Duration(expiresIn)*time.Second - azureMonitorTokenExpiryDelta)
+
+	return p.token, nil
+}
+
+func (p *AzureMonitorProvider) do(req *http.Request) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if 400 <= r.StatusCode {
+		return nil, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return b, nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newAzureMonitorTestServer(t *testing.T, result string, tokenRequests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tenant/oauth2/v2.0/token":
+			*tokenRequests++
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "client", r.PostForm.Get("client_id"))
+			assert.Equal(t, "secret", r.PostForm.Get("client_secret"))
+			assert.Equal(t, "http://"+r.Host+"/.default", r.PostForm.Get("scope"))
+			w.Write([]byte(`{"token_type":"Bearer","expires_in":3599,"access_token":"token"}`))
+		case "/v1/workspaces/workspace/query":
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "PT60S", body["timespan"])
+			w.Write([]byte(result))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func azureMonitorTestCredentials(authorityHost string) map[string][]byte {
+	return map[string][]byte{
+		azureMonitorTenantIDSecretKey:      []byte("tenant"),
+		azureMonitorClientIDSecretKey:      []byte("client"),
+		azureMonitorClientSecretSecretKey:  []byte("secret"),
+		azureMonitorAuthorityHostSecretKey: []byte(authorityHost),
+	}
+}
+
+func TestNewAzureMonitorProvider(t *testing.T) {
+	address := "https://api.loganalytics.io/v1/workspaces/workspace"
+	cs := map[string][]byte{
+		azureMonitorTenantIDSecretKey:     []byte("tenant"),
+		azureMonitorClientIDSecretKey:     []byte("client"),
+		azureMonitorClientSecretSecretKey: []byte("secret"),
+	}
+
+	am, err := NewAzureMonitorProvider("1m", flaggerv1.MetricTemplateProvider{Address: address}, cs)
+	require.NoError(t, err)
+	assert.Equal(t, "api.loganalytics.io", am.url.Host)
+	assert.Equal(t, "PT60S", am.timespan)
+	assert.Equal(t, azureMonitorDefaultAuthorityHost, am.authorityHost)
+
+	_, err = NewAzureMonitorProvider("1m", flaggerv1.MetricTemplateProvider{}, cs)
+	require.Error(t, err)
+
+	delete(cs, azureMonitorClientSecretSecretKey)
+	_, err = NewAzureMonitorProvider("1m", flaggerv1.MetricTemplateProvider{Address: address}, cs)
+	require.Error(t, err)
+}
+
+func TestAzureMonitorProvider_RunQuery(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		tokenRequests := 0
+		ts := newAzureMonitorTestServer(t, `{"tables":[{"name":"PrimaryResult","columns":[{"name":"timestamp","type":"datetime"},{"name":"avg_duration","type":"real"}],"rows":[["2023-01-01T00:00:00Z",102.5],["2023-01-01T00:01:00Z",98.5]]}]}`, &tokenRequests)
+		defer ts.Close()
+
+		am, err := NewAzureMonitorProvider("1m",
+			flaggerv1.MetricTemplateProvider{Address: ts.URL + "/v1/workspaces/workspace"},
+			azureMonitorTestCredentials(ts.URL))
+		require.NoError(t, err)
+
+		f, err := am.RunQuery("requests | summarize avg(duration) by bin(timestamp, 1m)")
+		require.NoError(t, err)
+		assert.Equal(t, 98.5, f)
+
+		_, err = am.RunQuery("requests | summarize avg(duration)")
+		require.NoError(t, err)
+		assert.Equal(t, 1, tokenRequests)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		for _, result := range []string{
+			`{"tables":[{"name":"PrimaryResult","columns":[{"name":"avg_duration","type":"real"}],"rows":[]}]}`,
+			`{"tables":[{"name":"PrimaryResult","columns":[{"name":"avg_duration","type":"real"}],"rows":[[null]]}]}`,
+		} {
+			tokenRequests := 0
+			ts := newAzureMonitorTestServer(t, result, &tokenRequests)
+
+			am, err := NewAzureMonitorProvider("1m",
+				flaggerv1.MetricTemplateProvider{Address: ts.URL + "/v1/workspaces/workspace"},
+				azureMonitorTestCredentials(ts.URL))
+			require.NoError(t, err)
+
+			_, err = am.RunQuery("requests | summarize avg(duration)")
+			require.True(t, errors.Is(err, ErrNoValuesFound))
+			ts.Close()
+		}
+	})
+
+	t.Run("not a number", func(t *testing.T) {
+		tokenRequests := 0
+		ts := newAzureMonitorTestServer(t, `{"tables":[{"name":"PrimaryResult","columns":[{"name":"name","type":"string"}],"rows":[["podinfo"]]}]}`, &tokenRequests)
+		defer ts.Close()
+
+		am, err := NewAzureMonitorProvider("1m",
+			flaggerv1.MetricTemplateProvider{Address: ts.URL + "/v1/workspaces/workspace"},
+			azureMonitorTestCredentials(ts.URL))
+		require.NoError(t, err)
+
+		_, err = am.RunQuery("requests | project name")
+		require.Error(t, err)
+	})
+}
+
+func TestAzureMonitorProvider_IsOnline(t *testing.T) {
+	tokenRequests := 0
+	ts := newAzureMonitorTestServer(t, `{"tables":[{"name":"PrimaryResult","columns":[{"name":"print_0","type":"long"}],"rows":[[1]]}]}`, &tokenRequests)
+	defer ts.Close()
+
+	am, err := NewAzureMonitorProvider("1m",
+		flaggerv1.MetricTemplateProvider{Address: ts.URL + "/v1/workspaces/workspace"},
+		azureMonitorTestCredentials(ts.URL))
+	require.NoError(t, err)
+
+	ok, err := am.IsOnline()
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	am, err = NewAzureMonitorProvider("1m",
+		flaggerv1.MetricTemplateProvider{Address: ts.URL + "/v1/workspaces/workspace"},
+		azureMonitorTestCredentials(ts.URL+"/invalid"))
+	require.NoError(t, err)
+
+	ok, err = am.IsOnline()
+	require.Error(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// https://developers.checklyhq.com/reference
+const (
+	checklyDefaultHost = "https://api.checklyhq.com"
+
+	checklyCheckResultsPath = "/v1/check-results/"
+	checklyChecksPath       = "/v1/checks"
+
+	checklyAPIKeySecretKey    = "checkly_api_key"
+	checklyAccountIDSecretKey = "checkly_account_id"
+	checklyAccountIDHeaderKey = "X-Checkly-Account"
+)
+
+// ChecklyProvider computes the pass rate of Checkly synthetic checks
+type ChecklyProvider struct {
+	checkResultsEndpoint string
+	checksEndpoint       string
+
+	timeout   time.Duration
+	apiKey    string
+	accountID string
+	fromDelta int64
+}
+
+type checklyCheckResult struct {
+	HasFailures bool `json:"hasFailures"`
+	HasErrors   bool `json:"hasErrors"`
+}
+
+// NewChecklyProvider takes a metric interval, a provider spec and the credentials map, and
+// returns a Checkly client ready to execute queries against the API
+func NewChecklyProvider(metricInterval string,
+	provider flaggerv1.MetricTemplateProvider,
+	credentials map[string][]byte) (*ChecklyProvider, error) {
+
+	address := strings.TrimSuffix(provider.Address, "/")
+	if address == "" {
+		address = checklyDefaultHost
+	}
+
+	cp := ChecklyProvider{
+		timeout:              5 * time.Second,
+		checkResultsEndpoint: address + checklyCheckResultsPath,
+		checksEndpoint:       address + checklyChecksPath,
+	}
+
+	if b, ok := credentials[checklyAPIKeySecretKey]; ok {
+		cp.apiKey = string(b)
+	} else {
+		return nil, fmt.Errorf("checkly credentials does not contain checkly_api_key")
+	}
+
+	if b, ok := credentials[checklyAccountIDSecretKey]; ok {
+		cp.accountID = string(b)
+	} else {
+		return nil, fmt.Errorf("checkly credentials does not contain checkly_account_id")
+	}
+
+	md, err := time.ParseDuration(metricInterval)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metric interval: %w", err)
+	}
+
+	cp.fromDelta = int64(md.Seconds())
+	return &cp, nil
+}
+
+// RunQuery fetches the results of the check ID given as query for the last metric interval
+// and returns the percentage of runs that passed
+func (p *ChecklyProvider) RunQuery(query string) (float64, error) {
+	checkID := strings.TrimSpace(query)
+	if checkID == "" {
+		return 0, fmt.Errorf("query does not contain a check ID")
+	}
+
+	req, err := http.NewRequest("GET", p.checkResultsEndpoint+checkID, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+
+	now := time.Now().Unix()
+	q := req.URL.Query()
+	q.Add("from", strconv.FormatInt(now-p.fromDelta, 10))
+	q.Add("to", strconv.FormatInt(now, 10))
+	q.Add("limit", "100")
+	req.URL.RawQuery = q.Encode()
+
+	b, err := p.do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	var res []checklyCheckResult
+	if err := json.Unmarshal(b, &res); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	if len(res) < 1 {
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	}
+
+	passed := 0
+	for _, r := range res {
+		if !r.HasFailures && !r.HasErrors {
+			passed++
+		}
+	}
+
+	return float64(passed) / float64(len(res)) * 100, nil
+}
+
+// IsOnline lists the account checks and returns an error if the API is unreachable
+// or the credentials are invalid
+func (p *ChecklyProvider) IsOnline() (bool, error) {
+	req, err := http.NewRequest("GET", p.checksEndpoint, nil)
+	if err != nil {
+		return false, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("limit", "1")
+	req.URL.RawQuery = q.Encode()
+
+	if _, err := p.do(req); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (p *ChecklyProvider) do(req *http.Request) ([]byte, error) {
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set(checklyAccountIDHeaderKey, p.accountID)
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer r.Body.Close()
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return b, nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewChecklyProvider(t *testing.T) {
+	cs := map[string][]byte{
+		checklyAPIKeySecretKey:    []byte("api-key"),
+		checklyAccountIDSecretKey: []byte("account-id"),
+	}
+
+	cp, err := NewChecklyProvider("5m", flaggerv1.MetricTemplateProvider{}, cs)
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.checklyhq.com/v1/check-results/", cp.checkResultsEndpoint)
+	assert.Equal(t, "https://api.checklyhq.com/v1/checks", cp.checksEndpoint)
+	assert.Equal(t, int64(300), cp.fromDelta)
+	assert.Equal(t, "api-key", cp.apiKey)
+	assert.Equal(t, "account-id", cp.accountID)
+
+	_, err = NewChecklyProvider("5m", flaggerv1.MetricTemplateProvider{}, map[string][]byte{
+		checklyAPIKeySecretKey: []byte("api-key"),
+	})
+	require.Error(t, err)
+}
+
+func TestChecklyProvider_RunQuery(t *testing.T) {
+	cs := map[string][]byte{
+		checklyAPIKeySecretKey:    []byte("api-key"),
+		checklyAccountIDSecretKey: []byte("account-id"),
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		now := time.Now().Unix()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/check-results/check-1", r.URL.Path)
+			assert.Equal(t, "Bearer api-key", r.Header.Get("Authorization"))
+			assert.Equal(t, "account-id", r.Header.Get(checklyAccountIDHeaderKey))
+
+			from, err := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+			if assert.NoError(t, err) {
+				assert.Less(t, from, now)
+			}
+
+			w.Write([]byte(`[
+				{"hasFailures": false, "hasErrors": false},
+				{"hasFailures": true, "hasErrors": false},
+				{"hasFailures": false, "hasErrors": true},
+				{"hasFailures": false, "hasErrors": false}
+			]`))
+		}))
+		defer ts.Close()
+
+		cp, err := NewChecklyProvider("1m", flaggerv1.MetricTemplateProvider{Address: ts.URL}, cs)
+		require.NoError(t, err)
+
+		f, err := cp.RunQuery(" check-1\n")
+		require.NoError(t, err)
+		assert.Equal(t, float64(50), f)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[]`))
+		}))
+		defer ts.Close()
+
+		cp, err := NewChecklyProvider("1m", flaggerv1.MetricTemplateProvider{Address: ts.URL}, cs)
+		require.NoError(t, err)
+
+		_, err = cp.RunQuery("check-1")
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+}
+
+func TestChecklyProvider_IsOnline(t *testing.T) {
+	for _, c := range []struct {
+		code        int
+		errExpected bool
+	}{
+		{code: http.StatusOK, errExpected: false},
+		{code: http.StatusUnauthorized, errExpected: true},
+	} {
+		t.Run(fmt.Sprintf("%d", c.code), func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "Bearer api-key", r.Header.Get("Authorization"))
+				assert.Equal(t, "account-id", r.Header.Get(checklyAccountIDHeaderKey))
+				w.WriteHeader(c.code)
+			}))
+			defer ts.Close()
+
+			cp, err := NewChecklyProvider("1m",
+				flaggerv1.MetricTemplateProvider{Address: ts.URL},
+				map[string][]byte{
+					checklyAPIKeySecretKey:    []byte("api-key"),
+					checklyAccountIDSecretKey: []byte("account-id"),
+				},
+			)
+			require.NoError(t, err)
+
+			_, err = cp.IsOnline()
+			if c.errExpected {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
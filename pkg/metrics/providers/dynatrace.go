@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
@@ -53,8 +54,9 @@ type DynatraceProvider struct {
 type dynatraceResponse struct {
 	Result []struct {
 		Data []struct {
-			Timestamps []int64   `json:"timestamps"`
-			Values     []float64 `json:"values"`
+			Timestamps []int64 `json:"timestamps"`
+			// the values are null for the timeslots without data
+			Values []*float64 `json:"values"`
 		} `json:"data"`
 	} `json:"result"`
 }
@@ -94,59 +96,83 @@ func NewDynatraceProvider(metricInterval string,
 // RunQuery executes the dynatrace query against DynatraceProvider.metricsQueryEndpoint
 // and returns the the first result as float64
 func (p *DynatraceProvider) RunQuery(query string) (float64, error) {
+	now := time.Now().Unix() * 1000
+	values, err := p.query(query, "Inf", now-p.fromDelta, now)
+	if err != nil {
+		return 0, err
+	}
+	return values[0], nil
+}
+
+// RunRangeQuery executes the dynatrace query over the last interval
+// and returns the datapoints, the resolution is rounded to whole minutes
+func (p *DynatraceProvider) RunRangeQuery(query string, interval time.Duration, step time.Duration) ([]float64, error) {
+	minutes := int64(math.Ceil(step.Minutes()))
+	if minutes < 1 {
+		minutes = 1
+	}
+	now := time.Now().Unix() * 1000
+	return p.query(query, fmt.Sprintf("%dm", minutes), now-interval.Milliseconds(), now)
+}
 
+// query calls the metrics query endpoint and returns the values of the last series, without the null values
+func (p *DynatraceProvider) query(query string, resolution string, from int64, to int64) ([]float64, error) {
 	req, err := http.NewRequest("GET", p.metricsQueryEndpoint, nil)
 	if err != nil {
-		return 0, fmt.Errorf("error http.NewRequest: %w", err)
+		return nil, fmt.Errorf("error http.NewRequest: %w", err)
 	}
 
 	req.Header.Set(dynatraceAuthorizationHeaderKey, fmt.Sprintf("%s %s", dynatraceAuthorizationHeaderType, p.token))
 
-	now := time.Now().Unix() * 1000
 	q := req.URL.Query()
 	q.Add("metricSelector", query)
-	q.Add("resolution", "Inf")
-	q.Add("from", strconv.FormatInt(now-p.fromDelta, 10))
-	q.Add("to", strconv.FormatInt(now, 10))
+	q.Add("resolution", resolution)
+	q.Add("from", strconv.FormatInt(from, 10))
+	q.Add("to", strconv.FormatInt(to, 10))
 	req.URL.RawQuery = q.Encode()
 
 	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
 	defer cancel()
 	r, err := http.DefaultClient.Do(req.WithContext(ctx))
 	if err != nil {
-		return 0, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 
 	defer r.Body.Close()
 	b, err := io.ReadAll(r.Body)
 	if err != nil {
-		return 0, fmt.Errorf("error reading body: %w", err)
+		return nil, fmt.Errorf("error reading body: %w", err)
 	}
 
 	if r.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("error response: %s: %w", string(b), err)
+		return nil, fmt.Errorf("error response: %s: %w", string(b), err)
 	}
 
 	var res dynatraceResponse
 	if err := json.Unmarshal(b, &res); err != nil {
-		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+		return nil, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
 	}
 
 	if len(res.Result) < 1 {
-		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+		return nil, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
 	}
 
 	data := res.Result[0].Data
 	if len(data) < 1 {
-		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+		return nil, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
 	}
 
-	vs := data[len(data)-1]
-	if len(vs.Values) < 1 {
-		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	var values []float64
+	for _, v := range data[len(data)-1].Values {
+		if v != nil {
+			values = append(values, *v)
+		}
+	}
+	if len(values) < 1 {
+		return nil, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
 	}
 
-	return vs.Values[0], nil
+	return values, nil
 }
 
 // IsOnline calls the Dynatrace's metrics endpoint with token
@@ -133,6 +133,51 @@ func TestDynatraceProvider_RunQuery(t *testing.T) {
 		_, err = dp.RunQuery("")
 		require.True(t, errors.Is(err, ErrNoValuesFound))
 	})
+
+	t.Run("null values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json := `{"result": [{"metricId": "builtin:service.errors.total.rate", "data": [{"timestamps": [1589455320000], "values": [null]}]}]}`
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		dp, err := NewDynatraceProvider("1m",
+			flaggerv1.MetricTemplateProvider{Address: ts.URL},
+			map[string][]byte{
+				dynatraceAPITokenSecretKey: []byte(token),
+			},
+		)
+		require.NoError(t, err)
+		_, err = dp.RunQuery("builtin:service.errors.total.rate")
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+}
+
+func TestDynatraceProvider_RunRangeQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2m", r.URL.Query().Get("resolution"))
+		from, err := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+		require.NoError(t, err)
+		to, err := strconv.ParseInt(r.URL.Query().Get("to"), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10*60*1000), to-from)
+
+		json := `{"result": [{"metricId": "builtin:service.response.time", "data": [{"timestamps": [1, 2, 3, 4], "values": [210.5, null, 190, 200]}]}]}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	dp, err := NewDynatraceProvider("1m",
+		flaggerv1.MetricTemplateProvider{Address: ts.URL},
+		map[string][]byte{
+			dynatraceAPITokenSecretKey: []byte("token"),
+		},
+	)
+	require.NoError(t, err)
+
+	values, err := dp.RunRangeQuery("builtin:service.response.time", 10*time.Minute, 90*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{210.5, 190, 200}, values)
 }
 
 func TestDynatraceProvider_IsOnline(t *testing.T) {
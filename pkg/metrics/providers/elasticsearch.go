@@ -0,0 +1,197 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/spiffe"
+)
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/search-search.html
+const (
+	elasticsearchSearchPath = "/_search"
+	elasticsearchCountPath  = "/_count"
+
+	elasticsearchAPIKeySecretKey = "apiKey"
+
+	// elasticsearchResultAggregation is the aggregation read when the query defines several of them
+	elasticsearchResultAggregation = "result"
+)
+
+// ElasticsearchProvider executes search queries with aggregations
+type ElasticsearchProvider struct {
+	timeout  time.Duration
+	url      url.URL
+	username string
+	password string
+	apiKey   string
+	client   *http.Client
+}
+
+type elasticsearchResponse struct {
+	Hits struct {
+		Total struct {
+			Value float64 `json:"value"`
+		} `json:"total"`
+	} `json:"hits"`
+	Aggregations map[string]struct {
+		// single-value metric aggregations
+		Value *float64 `json:"value"`
+		// single bucket aggregations
+		DocCount *float64 `json:"doc_count"`
+	} `json:"aggregations"`
+}
+
+// NewElasticsearchProvider takes a provider spec and the credentials map,
+// validates the address, extracts the basic auth or API key if provided and
+// returns an Elasticsearch client ready to execute queries against the index of the address path
+func NewElasticsearchProvider(provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*ElasticsearchProvider, error) {
+	esURL, err := url.Parse(provider.Address)
+	if provider.Address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	es := ElasticsearchProvider{
+		timeout: 5 * time.Second,
+		url:     *esURL,
+		client:  http.DefaultClient,
+	}
+
+	if provider.InsecureSkipVerify {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		es.client = &http.Client{Transport: t}
+	}
+
+	if provider.SPIFFE {
+		client, err := spiffe.DefaultHTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		es.client = client
+	}
+
+	if provider.SecretRef != nil {
+		if username, ok := credentials["username"]; ok {
+			es.username = string(username)
+		}
+		if password, ok := credentials["password"]; ok {
+			es.password = string(password)
+		}
+		if (es.username == "") != (es.password == "") {
+			return nil, fmt.Errorf("%s credentials must contain both username and password", provider.Type)
+		}
+		if apiKey, ok := credentials[elasticsearchAPIKeySecretKey]; ok {
+			es.apiKey = string(apiKey)
+		}
+	}
+
+	return &es, nil
+}
+
+// RunQuery executes the search request body and returns the value of the result aggregation,
+// or of the single aggregation of the query, or the number of hits when the query has no aggregations
+func (p *ElasticsearchProvider) RunQuery(query string) (float64, error) {
+	b, err := p.do("POST", elasticsearchSearchPath, url.Values{"size": []string{"0"}}, []byte(query))
+	if err != nil {
+		return 0, err
+	}
+
+	var result elasticsearchResponse
+	if err := json.Unmarshal(b, &result); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	if len(result.Aggregations) == 0 {
+		return result.Hits.Total.Value, nil
+	}
+
+	agg, ok := result.Aggregations[elasticsearchResultAggregation]
+	if !ok {
+		if len(result.Aggregations) > 1 {
+			return 0, fmt.Errorf("the query has several aggregations and none is named %s", elasticsearchResultAggregation)
+		}
+		for _, a := range result.Aggregations {
+			agg = a
+		}
+	}
+	switch {
+	case agg.Value != nil:
+		return *agg.Value, nil
+	case agg.DocCount != nil:
+		return *agg.DocCount, nil
+	default:
+		return 0, fmt.Errorf("%w", ErrNoValuesFound)
+	}
+}
+
+// IsOnline calls the count endpoint of the index and returns an error if the API is unreachable
+func (p *ElasticsearchProvider) IsOnline() (bool, error) {
+	if _, err := p.do("GET", elasticsearchCountPath, nil, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (p *ElasticsearchProvider) do(method string, apiPath string, params url.Values, body []byte) ([]byte, error) {
+	u := p.url
+	u.Path = path.Join(p.url.Path, apiPath)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+p.apiKey)
+	} else if p.username != "" && p.password != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if 400 <= r.StatusCode {
+		return nil, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return b, nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewElasticsearchProvider(t *testing.T) {
+	ep, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+		Address:   "http://elasticsearch:9200/logs-*",
+		SecretRef: &corev1.LocalObjectReference{Name: "elasticsearch"},
+	}, map[string][]byte{
+		"username": []byte("user"),
+		"password": []byte("pass"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "user", ep.username)
+	assert.Equal(t, "pass", ep.password)
+
+	ep, err = NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+		Address:   "http://elasticsearch:9200/logs-*",
+		SecretRef: &corev1.LocalObjectReference{Name: "elasticsearch"},
+	}, map[string][]byte{elasticsearchAPIKeySecretKey: []byte("key")})
+	require.NoError(t, err)
+	assert.Equal(t, "key", ep.apiKey)
+
+	_, err = NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+		Address:   "http://elasticsearch:9200/logs-*",
+		SecretRef: &corev1.LocalObjectReference{Name: "elasticsearch"},
+	}, map[string][]byte{"username": []byte("user")})
+	require.Error(t, err)
+
+	_, err = NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{}, nil)
+	require.Error(t, err)
+}
+
+func TestElasticsearchProvider_RunQuery(t *testing.T) {
+	query := `{"query": {"term": {"app": "podinfo"}}, "aggs": {"result": {"avg": {"field": "error"}}}}`
+	for name, c := range map[string]struct {
+		response string
+		value    float64
+		err      error
+	}{
+		"result aggregation": {
+			response: `{"hits": {"total": {"value": 120}}, "aggregations": {"result": {"value": 0.25}, "total": {"value": 120}}}`,
+			value:    0.25,
+		},
+		"single aggregation": {
+			response: `{"hits": {"total": {"value": 120}}, "aggregations": {"errors": {"value": 3}}}`,
+			value:    3,
+		},
+		"bucket aggregation": {
+			response: `{"hits": {"total": {"value": 120}}, "aggregations": {"errors": {"doc_count": 7}}}`,
+			value:    7,
+		},
+		"hits": {
+			response: `{"hits": {"total": {"value": 120}}}`,
+			value:    120,
+		},
+		"no values": {
+			response: `{"hits": {"total": {"value": 0}}, "aggregations": {"result": {"value": null}}}`,
+			err:      ErrNoValuesFound,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "POST", r.Method)
+				assert.Equal(t, "/logs-podinfo/_search", r.URL.Path)
+				assert.Equal(t, "0", r.URL.Query().Get("size"))
+				assert.Equal(t, "ApiKey key", r.Header.Get("Authorization"))
+
+				var body map[string]interface{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Contains(t, body, "aggs")
+
+				w.Write([]byte(c.response))
+			}))
+			defer ts.Close()
+
+			ep, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+				Address:   ts.URL + "/logs-podinfo",
+				SecretRef: &corev1.LocalObjectReference{Name: "elasticsearch"},
+			}, map[string][]byte{elasticsearchAPIKeySecretKey: []byte("key")})
+			require.NoError(t, err)
+
+			f, err := ep.RunQuery(query)
+			if c.err != nil {
+				require.True(t, errors.Is(err, c.err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.value, f)
+		})
+	}
+
+	t.Run("several aggregations", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"aggregations": {"errors": {"value": 3}, "total": {"value": 120}}}`))
+		}))
+		defer ts.Close()
+
+		ep, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{Address: ts.URL}, nil)
+		require.NoError(t, err)
+		_, err = ep.RunQuery(query)
+		require.Error(t, err)
+	})
+}
+
+func TestElasticsearchProvider_IsOnline(t *testing.T) {
+	for _, c := range []struct {
+		code        int
+		errExpected bool
+	}{
+		{code: http.StatusOK, errExpected: false},
+		{code: http.StatusUnauthorized, errExpected: true},
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/logs-podinfo/_count", r.URL.Path)
+			user, pass, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "user", user)
+			assert.Equal(t, "pass", pass)
+			w.WriteHeader(c.code)
+		}))
+
+		ep, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+			Address:   ts.URL + "/logs-podinfo",
+			SecretRef: &corev1.LocalObjectReference{Name: "elasticsearch"},
+		}, map[string][]byte{"username": []byte("user"), "password": []byte("pass")})
+		require.NoError(t, err)
+
+		_, err = ep.IsOnline()
+		if c.errExpected {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+		ts.Close()
+	}
+}
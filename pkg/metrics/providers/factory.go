@@ -17,9 +17,22 @@ limitations under the License.
 package providers
 
 import (
+	"fmt"
+
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 )
 
+// spiffeProviders are the providers that can authenticate with the SPIFFE SVID of Flagger
+var spiffeProviders = map[string]bool{
+	"":              true,
+	"prometheus":    true,
+	"graphite":      true,
+	"loki":          true,
+	"elasticsearch": true,
+	"keptn":         true,
+	"webhook":       true,
+}
+
 type Factory struct{}
 
 func (factory Factory) Provider(
@@ -27,6 +40,10 @@ func (factory Factory) Provider(
 	provider flaggerv1.MetricTemplateProvider,
 	credentials map[string][]byte,
 ) (Interface, error) {
+	if provider.SPIFFE && !spiffeProviders[provider.Type] {
+		return nil, fmt.Errorf("provider %s doesn't support SPIFFE mTLS", provider.Type)
+	}
+
 	switch provider.Type {
 	case "prometheus":
 		return NewPrometheusProvider(provider, credentials)
@@ -44,6 +61,22 @@ func (factory Factory) Provider(
 		return NewInfluxdbProvider(provider, credentials)
 	case "dynatrace":
 		return NewDynatraceProvider(metricInterval, provider, credentials)
+	case "sentry":
+		return NewSentryProvider(metricInterval, provider, credentials)
+	case "loki":
+		return NewLokiProvider(provider, credentials)
+	case "elasticsearch":
+		return NewElasticsearchProvider(provider, credentials)
+	case "splunk":
+		return NewSplunkProvider(metricInterval, provider, credentials)
+	case "azuremonitor":
+		return NewAzureMonitorProvider(metricInterval, provider, credentials)
+	case "keptn":
+		return NewKeptnProvider(metricInterval, provider, credentials)
+	case "checkly":
+		return NewChecklyProvider(metricInterval, provider, credentials)
+	case "webhook":
+		return NewWebhookProvider(provider, credentials)
 	default:
 		return NewPrometheusProvider(provider, credentials)
 	}
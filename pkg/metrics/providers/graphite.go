@@ -31,6 +31,7 @@ import (
 	"time"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/spiffe"
 )
 
 type graphiteDataPoint struct {
@@ -130,6 +131,14 @@ func NewGraphiteProvider(provider flaggerv1.MetricTemplateProvider, credentials
 		graph.client = &http.Client{Transport: t}
 	}
 
+	if provider.SPIFFE {
+		client, err := spiffe.DefaultHTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		graph.client = client
+	}
+
 	if provider.SecretRef == nil {
 		return &graph, nil
 	}
@@ -23,20 +23,25 @@ import (
 	"time"
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	fluxquery "github.com/influxdata/influxdb-client-go/v2/api/query"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 )
 
+// influxdbDefaultBucket is queried by IsOnline when the secret doesn't set a bucket
+const influxdbDefaultBucket = "default"
+
 type InfluxdbProvider struct {
 	client influxdb2.Client
 	org    string
+	bucket string
 }
 
 func NewInfluxdbProvider(provider flaggerv1.MetricTemplateProvider,
 	credentials map[string][]byte) (*InfluxdbProvider, error) {
 	influxURL, err := url.Parse(provider.Address)
 	var token string
-	var influxProvider InfluxdbProvider
+	influxProvider := InfluxdbProvider{bucket: influxdbDefaultBucket}
 
 	if provider.Address == "" || err != nil {
 		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
@@ -54,6 +59,10 @@ func NewInfluxdbProvider(provider flaggerv1.MetricTemplateProvider,
 		} else {
 			return nil, fmt.Errorf("%s credentials does not contain an organisation", provider.Type)
 		}
+
+		if bucket, ok := credentials["bucket"]; ok {
+			influxProvider.bucket = string(bucket)
+		}
 	}
 
 	client := influxdb2.NewClient(influxURL.String(), token)
@@ -62,6 +71,7 @@ func NewInfluxdbProvider(provider flaggerv1.MetricTemplateProvider,
 	return &influxProvider, nil
 }
 
+// RunQuery executes the Flux query and returns the value of the last record
 func (i *InfluxdbProvider) RunQuery(query string) (float64, error) {
 	queryAPI := i.client.QueryAPI(i.org)
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
@@ -70,37 +80,50 @@ func (i *InfluxdbProvider) RunQuery(query string) (float64, error) {
 	if err != nil {
 		return 0, fmt.Errorf("error accessing influxdb query api: %s", err)
 	}
-	for result.Next() {
-		if result.Err() != nil {
-			return 0, fmt.Errorf("query error: %s", err)
-		}
-		if result.Record().Value() == nil {
-			return 0, fmt.Errorf("invalid response: %s: %w", result.Record().String(), ErrNoValuesFound)
-		}
-
-		if float, ok := result.Record().Value().(float64); ok {
-			return float, nil
-		}
+	defer result.Close()
 
-		return 0, fmt.Errorf("invalid response: %s", result.Record().String())
+	var last *fluxquery.FluxRecord
+	for result.Next() {
+		last = result.Record()
+	}
+	if result.Err() != nil {
+		return 0, fmt.Errorf("query error: %s", result.Err())
+	}
+	if last == nil || last.Value() == nil {
+		return 0, fmt.Errorf("invalid response: %w", ErrNoValuesFound)
 	}
 
-	return 0, nil
+	switch v := last.Value().(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	default:
+		return 0, fmt.Errorf("invalid response: %s", last.String())
+	}
 }
 
-// IsOnline runs a simple query against the default bucket.
+// IsOnline runs a simple query against the bucket set in the secret, or the default bucket.
 func (i *InfluxdbProvider) IsOnline() (bool, error) {
+	bucket := i.bucket
+	if bucket == "" {
+		bucket = influxdbDefaultBucket
+	}
 	queryAPI := i.client.QueryAPI(i.org)
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
-	result, err := queryAPI.Query(ctx, `from(bucket: "default") |> range(start: -2h)`)
+	result, err := queryAPI.Query(ctx, fmt.Sprintf(`from(bucket: %q) |> range(start: -2h)`, bucket))
 	if err != nil {
 		return false, fmt.Errorf("error accessing influxdb query api: %s", err)
 	}
+	defer result.Close()
 	for result.Next() {
-		if result.Err() != nil {
-			return false, fmt.Errorf("query error: %s", err)
-		}
+		// drain the records to surface the query errors
+	}
+	if result.Err() != nil {
+		return false, fmt.Errorf("query error: %s", result.Err())
 	}
 
 	return true, nil
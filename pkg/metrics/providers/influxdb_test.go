@@ -17,6 +17,7 @@ limitations under the License.
 package providers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -68,6 +69,23 @@ func TestNewInfluxdbProvider(t *testing.T) {
 
 		assert.NoError(t, err)
 	})
+
+	t.Run("bucket", func(t *testing.T) {
+		provider, err := NewInfluxdbProvider(flaggerv1.MetricTemplateProvider{
+			Type:    "influxdb",
+			Address: "http://localhost/",
+			SecretRef: &corev1.LocalObjectReference{
+				Name: "test-secret",
+			},
+		}, map[string][]byte{
+			"org":    []byte("test-org"),
+			"token":  []byte("x"),
+			"bucket": []byte("metrics"),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "metrics", provider.bucket)
+	})
 }
 
 func TestInfluxdbProvider_IsOnline(t *testing.T) {
@@ -127,5 +145,40 @@ func TestInfluxdbProvider_RunQuery(t *testing.T) {
 	float, err := provider.RunQuery(`from(bucket: "default")  |> range(start: -2h)`)
 
 	assert.NoError(t, err)
-	assert.Equal(t, float, 1.4)
+	assert.Equal(t, float, 6.6)
+}
+
+func TestInfluxdbProvider_RunQueryCount(t *testing.T) {
+	csvTable := `#datatype,string,long,long
+#group,false,false,false
+#default,_result,,
+,result,table,_value
+,,0,12
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(csvTable))
+	}))
+	defer ts.Close()
+
+	provider := InfluxdbProvider{
+		client: influxdb2.NewClient(ts.URL, "x"),
+		org:    "fake-org",
+	}
+	val, err := provider.RunQuery(`from(bucket: "default") |> range(start: -2h) |> count()`)
+	assert.NoError(t, err)
+	assert.Equal(t, 12.0, val)
+}
+
+func TestInfluxdbProvider_RunQueryNoValues(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	provider := InfluxdbProvider{
+		client: influxdb2.NewClient(ts.URL, "x"),
+		org:    "fake-org",
+	}
+	_, err := provider.RunQuery(`from(bucket: "default") |> range(start: -2h)`)
+	assert.True(t, errors.Is(err, ErrNoValuesFound))
 }
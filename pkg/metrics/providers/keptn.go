@@ -0,0 +1,239 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/spiffe"
+)
+
+// https://keptn.sh/docs/0.19.x/reference/api/
+const (
+	keptnEvaluationPath = "/v1/project/%s/stage/%s/service/%s/evaluation"
+	keptnEventPath      = "/mongodb-datastore/event"
+	keptnMetadataPath   = "/v1/metadata"
+
+	keptnEvaluationFinishedEvent = "sh.keptn.event.evaluation.finished"
+
+	keptnTokenSecretKey = "token"
+	keptnTokenHeaderKey = "x-token"
+)
+
+// KeptnProvider triggers Keptn quality gate evaluations and returns their score
+type KeptnProvider struct {
+	timeout      time.Duration
+	url          url.URL
+	token        string
+	timeframe    string
+	pollInterval time.Duration
+	pollTimeout  time.Duration
+	client       *http.Client
+}
+
+type keptnEvaluationResponse struct {
+	KeptnContext string `json:"keptnContext"`
+}
+
+type keptnEventsResponse struct {
+	Events []struct {
+		Data struct {
+			Result     string `json:"result"`
+			Evaluation struct {
+				Score  *float64 `json:"score"`
+				Result string   `json:"result"`
+			} `json:"evaluation"`
+		} `json:"data"`
+	} `json:"events"`
+}
+
+// NewKeptnProvider takes a metric interval, a provider spec and the credentials map,
+// validates the address, extracts the API token and
+// returns a Keptn client ready to trigger evaluations over the metric interval
+func NewKeptnProvider(metricInterval string,
+	provider flaggerv1.MetricTemplateProvider,
+	credentials map[string][]byte) (*KeptnProvider, error) {
+
+	keptnURL, err := url.Parse(provider.Address)
+	if provider.Address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	interval, err := time.ParseDuration(metricInterval)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metric interval: %w", err)
+	}
+
+	kp := KeptnProvider{
+		timeout:      5 * time.Second,
+		url:          *keptnURL,
+		timeframe:    fmt.Sprintf("%dm", int64(interval.Round(time.Minute).Minutes())),
+		pollInterval: 2 * time.Second,
+		pollTimeout:  time.Minute,
+		client:       http.DefaultClient,
+	}
+	if interval < time.Minute {
+		kp.timeframe = "1m"
+	}
+
+	if provider.InsecureSkipVerify {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		kp.client = &http.Client{Transport: t}
+	}
+
+	if provider.SPIFFE {
+		client, err := spiffe.DefaultHTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		kp.client = client
+	}
+
+	if b, ok := credentials[keptnTokenSecretKey]; ok {
+		kp.token = string(b)
+	} else {
+		return nil, fmt.Errorf("%s credentials does not contain %s", provider.Type, keptnTokenSecretKey)
+	}
+
+	return &kp, nil
+}
+
+// RunQuery triggers an evaluation of the service quality gate over the metric interval,
+// waits for the evaluation to finish and returns its score,
+// the query must be in the project/stage/service format
+func (p *KeptnProvider) RunQuery(query string) (float64, error) {
+	parts := strings.Split(strings.TrimSpace(query), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return 0, fmt.Errorf("query %s is not in the project/stage/service format", query)
+	}
+
+	body, err := json.Marshal(map[string]string{"timeframe": p.timeframe})
+	if err != nil {
+		return 0, fmt.Errorf("error marshaling evaluation: %w", err)
+	}
+
+	evaluationPath := fmt.Sprintf(keptnEvaluationPath,
+		url.PathEscape(parts[0]), url.PathEscape(parts[1]), url.PathEscape(parts[2]))
+	b, err := p.do("POST", evaluationPath, nil, body)
+	if err != nil {
+		return 0, err
+	}
+
+	var evaluation keptnEvaluationResponse
+	if err := json.Unmarshal(b, &evaluation); err != nil {
+		return 0, fmt.Errorf("error unmarshaling evaluation: %w, '%s'", err, string(b))
+	}
+	if evaluation.KeptnContext == "" {
+		return 0, fmt.Errorf("evaluation response does not contain a keptn context: '%s'", string(b))
+	}
+
+	deadline := time.Now().Add(p.pollTimeout)
+	for {
+		score, done, err := p.getScore(evaluation.KeptnContext)
+		if err != nil {
+			return 0, err
+		}
+		if done {
+			return score, nil
+		}
+		if time.Now().Add(p.pollInterval).After(deadline) {
+			return 0, fmt.Errorf("evaluation %s did not finish after %v", evaluation.KeptnContext, p.pollTimeout)
+		}
+		time.Sleep(p.pollInterval)
+	}
+}
+
+// IsOnline calls the Keptn metadata endpoint and returns an error if the API is unreachable
+func (p *KeptnProvider) IsOnline() (bool, error) {
+	if _, err := p.do("GET", keptnMetadataPath, nil, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// getScore looks up the evaluation finished event of the keptn context,
+// returns false if the evaluation is still in progress
+func (p *KeptnProvider) getScore(keptnContext string) (float64, bool, error) {
+	b, err := p.do("GET", keptnEventPath, url.Values{
+		"keptnContext": []string{keptnContext},
+		"type":         []string{keptnEvaluationFinishedEvent},
+	}, nil)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var result keptnEventsResponse
+	if err := json.Unmarshal(b, &result); err != nil {
+		return 0, false, fmt.Errorf("error unmarshaling events: %w, '%s'", err, string(b))
+	}
+	if len(result.Events) < 1 {
+		return 0, false, nil
+	}
+
+	data := result.Events[0].Data
+	if data.Evaluation.Score == nil {
+		return 0, false, fmt.Errorf("evaluation %s finished with result %s and no score: %w",
+			keptnContext, data.Result, ErrNoValuesFound)
+	}
+
+	return *data.Evaluation.Score, true, nil
+}
+
+func (p *KeptnProvider) do(method string, apiPath string, params url.Values, body []byte) ([]byte, error) {
+	u := p.url
+	u.Path = path.Join(p.url.Path, apiPath)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(keptnTokenHeaderKey, p.token)
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if 400 <= r.StatusCode {
+		return nil, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return b, nil
+}
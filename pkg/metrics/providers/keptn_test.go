@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewKeptnProvider(t *testing.T) {
+	cs := map[string][]byte{keptnTokenSecretKey: []byte("token")}
+
+	kp, err := NewKeptnProvider("5m", flaggerv1.MetricTemplateProvider{Address: "https://keptn.example.com/api"}, cs)
+	require.NoError(t, err)
+	assert.Equal(t, "5m", kp.timeframe)
+	assert.Equal(t, "token", kp.token)
+
+	kp, err = NewKeptnProvider("30s", flaggerv1.MetricTemplateProvider{Address: "https://keptn.example.com/api"}, cs)
+	require.NoError(t, err)
+	assert.Equal(t, "1m", kp.timeframe)
+
+	_, err = NewKeptnProvider("1m", flaggerv1.MetricTemplateProvider{}, cs)
+	require.Error(t, err)
+
+	_, err = NewKeptnProvider("1m", flaggerv1.MetricTemplateProvider{Address: "https://keptn.example.com/api"}, nil)
+	require.Error(t, err)
+}
+
+func TestKeptnProvider_RunQuery(t *testing.T) {
+	newServer := func(events ...string) *httptest.Server {
+		polls := 0
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "token", r.Header.Get(keptnTokenHeaderKey))
+			switch r.URL.Path {
+			case "/api/v1/project/podinfo/stage/qa/service/podinfo/evaluation":
+				assert.Equal(t, "POST", r.Method)
+				var body map[string]string
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, "1m", body["timeframe"])
+				w.Write([]byte(`{"keptnContext":"ctx"}`))
+			case "/api" + keptnEventPath:
+				assert.Equal(t, "ctx", r.URL.Query().Get("keptnContext"))
+				assert.Equal(t, keptnEvaluationFinishedEvent, r.URL.Query().Get("type"))
+				w.Write([]byte(events[polls]))
+				if polls < len(events)-1 {
+					polls++
+				}
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	}
+	newProvider := func(ts *httptest.Server) *KeptnProvider {
+		kp, err := NewKeptnProvider("1m", flaggerv1.MetricTemplateProvider{Address: ts.URL + "/api"},
+			map[string][]byte{keptnTokenSecretKey: []byte("token")})
+		require.NoError(t, err)
+		kp.pollInterval = time.Millisecond
+		kp.pollTimeout = 100 * time.Millisecond
+		return kp
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		ts := newServer(
+			`{"events":[]}`,
+			`{"events":[{"data":{"result":"pass","evaluation":{"score":95.5,"result":"pass"}}}]}`,
+		)
+		defer ts.Close()
+
+		f, err := newProvider(ts).RunQuery("podinfo/qa/podinfo")
+		require.NoError(t, err)
+		assert.Equal(t, 95.5, f)
+	})
+
+	t.Run("no score", func(t *testing.T) {
+		ts := newServer(`{"events":[{"data":{"result":"fail","evaluation":{"result":"fail"}}}]}`)
+		defer ts.Close()
+
+		_, err := newProvider(ts).RunQuery("podinfo/qa/podinfo")
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ts := newServer(`{"events":[]}`)
+		defer ts.Close()
+
+		_, err := newProvider(ts).RunQuery("podinfo/qa/podinfo")
+		require.Error(t, err)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		ts := newServer(`{"events":[]}`)
+		defer ts.Close()
+
+		_, err := newProvider(ts).RunQuery("podinfo/qa")
+		require.Error(t, err)
+	})
+}
+
+func TestKeptnProvider_IsOnline(t *testing.T) {
+	for _, c := range []struct {
+		code        int
+		errExpected bool
+	}{
+		{code: http.StatusOK, errExpected: false},
+		{code: http.StatusUnauthorized, errExpected: true},
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api"+keptnMetadataPath, r.URL.Path)
+			w.WriteHeader(c.code)
+		}))
+
+		kp, err := NewKeptnProvider("1m", flaggerv1.MetricTemplateProvider{Address: ts.URL + "/api"},
+			map[string][]byte{keptnTokenSecretKey: []byte("token")})
+		require.NoError(t, err)
+
+		_, err = kp.IsOnline()
+		if c.errExpected {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+		ts.Close()
+	}
+}
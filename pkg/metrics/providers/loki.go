@@ -0,0 +1,213 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/spiffe"
+)
+
+// https://grafana.com/docs/loki/latest/api/#query-loki
+const (
+	lokiQueryPath = "/loki/api/v1/query"
+	lokiReadyPath = "/ready"
+
+	lokiTenantSecretKey = "tenant"
+)
+
+// LokiProvider executes LogQL metric queries
+type LokiProvider struct {
+	timeout  time.Duration
+	url      url.URL
+	username string
+	password string
+	tenant   string
+	client   *http.Client
+}
+
+type lokiResponse struct {
+	Data struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// NewLokiProvider takes a provider spec and the credentials map,
+// validates the address, extracts the basic auth and tenant values if provided and
+// returns a Loki client ready to execute queries against the API
+func NewLokiProvider(provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*LokiProvider, error) {
+	lokiURL, err := url.Parse(provider.Address)
+	if provider.Address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	loki := LokiProvider{
+		timeout: 5 * time.Second,
+		url:     *lokiURL,
+		client:  http.DefaultClient,
+	}
+
+	if provider.InsecureSkipVerify {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		loki.client = &http.Client{Transport: t}
+	}
+
+	if provider.SPIFFE {
+		client, err := spiffe.DefaultHTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		loki.client = client
+	}
+
+	if provider.SecretRef != nil {
+		if username, ok := credentials["username"]; ok {
+			loki.username = string(username)
+		}
+		if password, ok := credentials["password"]; ok {
+			loki.password = string(password)
+		}
+		if (loki.username == "") != (loki.password == "") {
+			return nil, fmt.Errorf("%s credentials must contain both username and password", provider.Type)
+		}
+		if tenant, ok := credentials[lokiTenantSecretKey]; ok {
+			loki.tenant = string(tenant)
+		}
+	}
+
+	return &loki, nil
+}
+
+// RunQuery executes the LogQL metric query and returns the value of a scalar result
+// or of the last sample of a vector result as float64
+func (p *LokiProvider) RunQuery(query string) (float64, error) {
+	space := regexp.MustCompile(`\s+`)
+	params := url.Values{}
+	params.Set("query", space.ReplaceAllString(query, " "))
+
+	b, err := p.get(lokiQueryPath, params)
+	if err != nil {
+		return 0, err
+	}
+
+	var result lokiResponse
+	if err := json.Unmarshal(b, &result); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	var samples [][]interface{}
+	switch result.Data.ResultType {
+	case "scalar":
+		var sample []interface{}
+		if err := json.Unmarshal(result.Data.Result, &sample); err != nil {
+			return 0, fmt.Errorf("error unmarshaling scalar result: %w, '%s'", err, string(b))
+		}
+		samples = append(samples, sample)
+	case "vector":
+		var vector []struct {
+			Value []interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(result.Data.Result, &vector); err != nil {
+			return 0, fmt.Errorf("error unmarshaling vector result: %w, '%s'", err, string(b))
+		}
+		for _, v := range vector {
+			samples = append(samples, v.Value)
+		}
+	case "streams":
+		return 0, fmt.Errorf("the query returned log lines, use a LogQL metric query instead")
+	default:
+		return 0, fmt.Errorf("unsupported result type %s", result.Data.ResultType)
+	}
+
+	var value *float64
+	for _, v := range samples {
+		if len(v) < 2 {
+			continue
+		}
+		if s, ok := v[1].(string); ok {
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return 0, err
+			}
+			value = &f
+		}
+	}
+	if value == nil {
+		return 0, fmt.Errorf("%w", ErrNoValuesFound)
+	}
+
+	return *value, nil
+}
+
+// IsOnline calls the Loki ready endpoint and returns an error if the API is unreachable
+func (p *LokiProvider) IsOnline() (bool, error) {
+	if _, err := p.get(lokiReadyPath, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (p *LokiProvider) get(apiPath string, params url.Values) ([]byte, error) {
+	u := p.url
+	u.Path = path.Join(p.url.Path, apiPath)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+
+	if p.username != "" && p.password != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+	if p.tenant != "" {
+		req.Header.Set("X-Scope-OrgID", p.tenant)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if 400 <= r.StatusCode {
+		return nil, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return b, nil
+}
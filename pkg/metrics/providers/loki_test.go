@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewLokiProvider(t *testing.T) {
+	lp, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{
+		Address:   "http://loki:3100",
+		SecretRef: &corev1.LocalObjectReference{Name: "loki"},
+	}, map[string][]byte{
+		"username":          []byte("user"),
+		"password":          []byte("pass"),
+		lokiTenantSecretKey: []byte("faro"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "user", lp.username)
+	assert.Equal(t, "pass", lp.password)
+	assert.Equal(t, "faro", lp.tenant)
+
+	_, err = NewLokiProvider(flaggerv1.MetricTemplateProvider{
+		Address:   "http://loki:3100",
+		SecretRef: &corev1.LocalObjectReference{Name: "loki"},
+	}, map[string][]byte{"username": []byte("user")})
+	require.Error(t, err)
+}
+
+func TestLokiProvider_RunQuery(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		eq := `sum(rate({app="frontend", kind="exception"}[1m]))`
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, lokiQueryPath, r.URL.Path)
+			assert.Equal(t, eq, r.URL.Query().Get("query"))
+			assert.Equal(t, "faro", r.Header.Get("X-Scope-OrgID"))
+
+			json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545310341.123,"0.25"]}]}}`
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		lp, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{
+			Address:   ts.URL,
+			SecretRef: &corev1.LocalObjectReference{Name: "loki"},
+		}, map[string][]byte{lokiTenantSecretKey: []byte("faro")})
+		require.NoError(t, err)
+
+		f, err := lp.RunQuery(`sum(rate({app="frontend",   kind="exception"}[1m]))`)
+		require.NoError(t, err)
+		assert.Equal(t, 0.25, f)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+		}))
+		defer ts.Close()
+
+		lp, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		_, err = lp.RunQuery("vector(1)")
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("scalar", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1545310341.123,"2.5"]}}`))
+		}))
+		defer ts.Close()
+
+		lp, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		f, err := lp.RunQuery(`scalar(sum(rate({app="podinfo"} |= "error" [1m])))`)
+		require.NoError(t, err)
+		assert.Equal(t, 2.5, f)
+	})
+
+	t.Run("streams", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[{"stream":{"app":"podinfo"},"values":[["1545310341123000000","error"]]}]}}`))
+		}))
+		defer ts.Close()
+
+		lp, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		_, err = lp.RunQuery(`{app="podinfo"} |= "error"`)
+		require.Error(t, err)
+		require.False(t, errors.Is(err, ErrNoValuesFound))
+	})
+}
+
+func TestLokiProvider_IsOnline(t *testing.T) {
+	for _, c := range []struct {
+		code        int
+		errExpected bool
+	}{
+		{code: http.StatusOK, errExpected: false},
+		{code: http.StatusServiceUnavailable, errExpected: true},
+	} {
+		t.Run(fmt.Sprintf("%d", c.code), func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, lokiReadyPath, r.URL.Path)
+				w.WriteHeader(c.code)
+			}))
+			defer ts.Close()
+
+			lp, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{Address: ts.URL}, nil)
+			require.NoError(t, err)
+
+			_, err = lp.IsOnline()
+			if c.errExpected {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"time"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/spiffe"
 )
 
 const prometheusOnlineQuery = "vector(1)"
@@ -54,6 +55,14 @@ type prometheusResponse struct {
 	}
 }
 
+type prometheusRangeResponse struct {
+	Data struct {
+		Result []struct {
+			Values [][]interface{} `json:"values"`
+		}
+	}
+}
+
 // NewPrometheusProvider takes a provider spec and the credentials map,
 // validates the address, extracts the username and password values if provided and
 // returns a Prometheus client ready to execute queries against the API
@@ -75,6 +84,14 @@ func NewPrometheusProvider(provider flaggerv1.MetricTemplateProvider, credential
 		prom.client = &http.Client{Transport: t}
 	}
 
+	if provider.SPIFFE {
+		client, err := spiffe.DefaultHTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		prom.client = client
+	}
+
 	if provider.SecretRef != nil {
 		if username, ok := credentials["username"]; ok {
 			prom.username = string(username)
@@ -95,9 +112,80 @@ func NewPrometheusProvider(provider flaggerv1.MetricTemplateProvider, credential
 // RunQuery executes the promQL query and returns the the first result as float64
 func (p *PrometheusProvider) RunQuery(query string) (float64, error) {
 	query = url.QueryEscape(p.trimQuery(query))
-	u, err := url.Parse(fmt.Sprintf("./api/v1/query?query=%s", query))
+	b, err := p.get(fmt.Sprintf("./api/v1/query?query=%s", query))
+	if err != nil {
+		return 0, err
+	}
+
+	var result prometheusResponse
+	err = json.Unmarshal(b, &result)
+	if err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	var value *float64
+	for _, v := range result.Data.Result {
+		metricValue := v.Value[1]
+		switch metricValue.(type) {
+		case string:
+			f, err := strconv.ParseFloat(metricValue.(string), 64)
+			if err != nil {
+				return 0, err
+			}
+			value = &f
+		}
+	}
+	if value == nil {
+		return 0, fmt.Errorf("%w", ErrNoValuesFound)
+	}
+
+	return *value, nil
+}
+
+// RunRangeQuery executes the promQL query over the last interval
+// and returns the datapoints of the first series
+func (p *PrometheusProvider) RunRangeQuery(query string, interval time.Duration, step time.Duration) ([]float64, error) {
+	end := time.Now()
+	start := end.Add(-interval)
+	b, err := p.get(fmt.Sprintf("./api/v1/query_range?query=%s&start=%d&end=%d&step=%s",
+		url.QueryEscape(p.trimQuery(query)), start.Unix(), end.Unix(), step))
+	if err != nil {
+		return nil, err
+	}
+
+	var result prometheusRangeResponse
+	err = json.Unmarshal(b, &result)
 	if err != nil {
-		return 0, fmt.Errorf("url.Parase failed: %w", err)
+		return nil, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	var values []float64
+	if len(result.Data.Result) > 0 {
+		for _, v := range result.Data.Result[0].Values {
+			if len(v) < 2 {
+				continue
+			}
+			if s, ok := v[1].(string); ok {
+				f, err := strconv.ParseFloat(s, 64)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, f)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w", ErrNoValuesFound)
+	}
+
+	return values, nil
+}
+
+// get calls the Prometheus API and returns the response body
+func (p *PrometheusProvider) get(query string) ([]byte, error) {
+	u, err := url.Parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("url.Parase failed: %w", err)
 	}
 	u.Path = path.Join(p.url.Path, u.Path)
 
@@ -105,7 +193,7 @@ func (p *PrometheusProvider) RunQuery(query string) (float64, error) {
 
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
-		return 0, fmt.Errorf("http.NewRequest failed: %w", err)
+		return nil, fmt.Errorf("http.NewRequest failed: %w", err)
 	}
 
 	if p.username != "" && p.password != "" {
@@ -117,42 +205,19 @@ func (p *PrometheusProvider) RunQuery(query string) (float64, error) {
 
 	r, err := p.client.Do(req.WithContext(ctx))
 	if err != nil {
-		return 0, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer r.Body.Close()
 
 	b, err := io.ReadAll(r.Body)
 	if err != nil {
-		return 0, fmt.Errorf("error reading body: %w", err)
+		return nil, fmt.Errorf("error reading body: %w", err)
 	}
 
 	if 400 <= r.StatusCode {
-		return 0, fmt.Errorf("error response: %s", string(b))
-	}
-
-	var result prometheusResponse
-	err = json.Unmarshal(b, &result)
-	if err != nil {
-		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+		return nil, fmt.Errorf("error response: %s", string(b))
 	}
-
-	var value *float64
-	for _, v := range result.Data.Result {
-		metricValue := v.Value[1]
-		switch metricValue.(type) {
-		case string:
-			f, err := strconv.ParseFloat(metricValue.(string), 64)
-			if err != nil {
-				return 0, err
-			}
-			value = &f
-		}
-	}
-	if value == nil {
-		return 0, fmt.Errorf("%w", ErrNoValuesFound)
-	}
-
-	return *value, nil
+	return b, nil
 }
 
 // IsOnline run simple Prometheus query and returns an error if the API is unreachable
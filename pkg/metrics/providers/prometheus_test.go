@@ -23,6 +23,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -99,6 +100,24 @@ func TestNewPrometheusProvider(t *testing.T) {
 	assert.Equal(t, "password", prom.password)
 }
 
+func TestNewPrometheusProvider_SPIFFE(t *testing.T) {
+	// SPIFFE mTLS requires a Workload API
+	_, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Address: "https://prometheus:9090",
+		SPIFFE:  true,
+	}, nil)
+	require.Error(t, err)
+
+	// SaaS providers don't accept SPIFFE SVIDs
+	_, err = Factory{}.Provider("1m", flaggerv1.MetricTemplateProvider{
+		Type:    "datadog",
+		Address: "https://api.datadoghq.com",
+		SPIFFE:  true,
+	}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "doesn't support SPIFFE")
+}
+
 func TestPrometheusProvider_RunQueryWithBasicAuth(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
 		expected := `sum(envoy_cluster_upstream_rq)`
@@ -159,6 +178,25 @@ func TestPrometheusProvider_RunQueryWithBasicAuth(t *testing.T) {
 	})
 }
 
+func TestPrometheusProvider_RunRangeQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/query_range", r.URL.Path)
+		assert.Equal(t, "sum(envoy_cluster_upstream_rq)", r.URL.Query().Get("query"))
+		assert.Equal(t, "10s", r.URL.Query().Get("step"))
+
+		json := `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1545905245,"1"],[1545905255,"2"],[1545905265,"3"]]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}, nil)
+	require.NoError(t, err)
+
+	values, err := prom.RunRangeQuery("sum(envoy_cluster_upstream_rq)", time.Minute, 10*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 2, 3}, values)
+}
+
 func TestPrometheusProvider_IsOnline(t *testing.T) {
 	t.Run("fail", func(t *testing.T) {
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -16,6 +16,8 @@ limitations under the License.
 
 package providers
 
+import "time"
+
 type Interface interface {
 	// RunQuery executes the query and converts the first result to float64
 	RunQuery(query string) (float64, error)
@@ -23,3 +25,10 @@ type Interface interface {
 	// IsOnline calls the provider endpoint and returns an error if the API is unreachable
 	IsOnline() (bool, error)
 }
+
+// RangeInterface is implemented by the providers that can return
+// all the datapoints of a query over a time range
+type RangeInterface interface {
+	// RunRangeQuery executes the query over the last interval and returns the datapoints
+	RunRangeQuery(query string, interval time.Duration, step time.Duration) ([]float64, error)
+}
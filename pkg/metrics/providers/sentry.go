@@ -0,0 +1,177 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// https://docs.sentry.io/api/discover/query-discover-events-in-table-format/
+const (
+	sentryEventsPath = "/events/"
+
+	sentryTokenSecretKey = "sentry_token"
+)
+
+// SentryProvider executes Sentry Discover queries
+type SentryProvider struct {
+	timeout         time.Duration
+	eventsEndpoint  string
+	organizationURL string
+	token           string
+	statsPeriod     string
+	client          *http.Client
+}
+
+type sentryResponse struct {
+	Data []map[string]interface{} `json:"data"`
+}
+
+// NewSentryProvider takes a metric interval, a provider spec and the credentials map,
+// and returns a Sentry client ready to execute queries against the organization API
+func NewSentryProvider(metricInterval string,
+	provider flaggerv1.MetricTemplateProvider,
+	credentials map[string][]byte) (*SentryProvider, error) {
+
+	if _, err := url.Parse(provider.Address); provider.Address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	address := strings.TrimSuffix(provider.Address, "/")
+	sp := SentryProvider{
+		timeout:         5 * time.Second,
+		organizationURL: address + "/",
+		eventsEndpoint:  address + sentryEventsPath,
+		statsPeriod:     metricInterval,
+		client:          http.DefaultClient,
+	}
+
+	if b, ok := credentials[sentryTokenSecretKey]; ok {
+		sp.token = string(b)
+	} else {
+		return nil, fmt.Errorf("sentry credentials does not contain sentry_token")
+	}
+
+	return &sp, nil
+}
+
+// RunQuery executes the Discover query against SentryProvider.eventsEndpoint
+// and returns the first field of the first row as float64.
+// The query is a list of Discover parameters, one per line or separated by &, e.g.
+// field=failure_rate()&query=release:podinfo@6.0.1&project=1
+func (p *SentryProvider) RunQuery(query string) (float64, error) {
+	params := url.Values{}
+	for _, line := range strings.Split(query, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		values, err := url.ParseQuery(line)
+		if err != nil {
+			return 0, fmt.Errorf("error parsing query: %w", err)
+		}
+		for k, v := range values {
+			params[k] = append(params[k], v...)
+		}
+	}
+
+	field := params.Get("field")
+	if field == "" {
+		return 0, fmt.Errorf("query does not contain a field")
+	}
+	if params.Get("statsPeriod") == "" && params.Get("start") == "" {
+		params.Set("statsPeriod", p.statsPeriod)
+	}
+
+	req, err := http.NewRequest("GET", p.eventsEndpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	req.URL.RawQuery = params.Encode()
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer r.Body.Close()
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return 0, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("error response: %s", string(b))
+	}
+
+	var res sentryResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	if len(res.Data) < 1 {
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	}
+
+	value, ok := res.Data[0][field].(float64)
+	if !ok {
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	}
+
+	return value, nil
+}
+
+// IsOnline calls the Sentry organization endpoint with the auth token
+// and returns an error if the request fails
+func (p *SentryProvider) IsOnline() (bool, error) {
+	req, err := http.NewRequest("GET", p.organizationURL, nil)
+	if err != nil {
+		return false, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return false, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return true, nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewSentryProvider(t *testing.T) {
+	token := "token"
+	sp, err := NewSentryProvider("1m",
+		flaggerv1.MetricTemplateProvider{Address: "https://sentry.io/api/0/organizations/flagger/"},
+		map[string][]byte{sentryTokenSecretKey: []byte(token)},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "https://sentry.io/api/0/organizations/flagger/events/", sp.eventsEndpoint)
+	assert.Equal(t, "https://sentry.io/api/0/organizations/flagger/", sp.organizationURL)
+	assert.Equal(t, "1m", sp.statsPeriod)
+	assert.Equal(t, token, sp.token)
+
+	_, err = NewSentryProvider("1m",
+		flaggerv1.MetricTemplateProvider{Address: "https://sentry.io/api/0/organizations/flagger"},
+		map[string][]byte{},
+	)
+	require.Error(t, err)
+}
+
+func TestSentryProvider_RunQuery(t *testing.T) {
+	token := "token"
+	t.Run("ok", func(t *testing.T) {
+		expected := 0.0512
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/events/", r.URL.Path)
+			assert.Equal(t, "Bearer "+token, r.Header.Get("Authorization"))
+			assert.Equal(t, "failure_rate()", r.URL.Query().Get("field"))
+			assert.Equal(t, "release:podinfo@6.0.1 transaction.op:pageload", r.URL.Query().Get("query"))
+			assert.Equal(t, "1", r.URL.Query().Get("project"))
+			assert.Equal(t, "1m", r.URL.Query().Get("statsPeriod"))
+
+			json := fmt.Sprintf(`{"data": [{"failure_rate()": %f}]}`, expected)
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		sp, err := NewSentryProvider("1m",
+			flaggerv1.MetricTemplateProvider{Address: ts.URL},
+			map[string][]byte{sentryTokenSecretKey: []byte(token)},
+		)
+		require.NoError(t, err)
+
+		f, err := sp.RunQuery(`
+			field=failure_rate()
+			query=release:podinfo@6.0.1 transaction.op:pageload
+			project=1
+		`)
+		require.NoError(t, err)
+		assert.Equal(t, expected, f)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data": []}`))
+		}))
+		defer ts.Close()
+
+		sp, err := NewSentryProvider("1m",
+			flaggerv1.MetricTemplateProvider{Address: ts.URL},
+			map[string][]byte{sentryTokenSecretKey: []byte(token)},
+		)
+		require.NoError(t, err)
+
+		_, err = sp.RunQuery("field=p75(measurements.lcp)&project=1")
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		sp, err := NewSentryProvider("1m",
+			flaggerv1.MetricTemplateProvider{Address: "http://sentry"},
+			map[string][]byte{sentryTokenSecretKey: []byte(token)},
+		)
+		require.NoError(t, err)
+
+		_, err = sp.RunQuery("project=1")
+		require.Error(t, err)
+	})
+}
+
+func TestSentryProvider_IsOnline(t *testing.T) {
+	for _, c := range []struct {
+		code        int
+		errExpected bool
+	}{
+		{code: http.StatusOK, errExpected: false},
+		{code: http.StatusUnauthorized, errExpected: true},
+	} {
+		t.Run(fmt.Sprintf("%d", c.code), func(t *testing.T) {
+			token := "token"
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "Bearer "+token, r.Header.Get("Authorization"))
+				w.WriteHeader(c.code)
+			}))
+			defer ts.Close()
+
+			sp, err := NewSentryProvider("1m",
+				flaggerv1.MetricTemplateProvider{Address: ts.URL},
+				map[string][]byte{sentryTokenSecretKey: []byte(token)},
+			)
+			require.NoError(t, err)
+
+			_, err = sp.IsOnline()
+			if c.errExpected {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// https://dev.splunk.com/observability/reference/api/signalflow/latest
+const (
+	splunkSignalFlowPath = "/v2/signalflow/execute"
+	splunkValidationPath = "/v2/metric?limit=1"
+
+	splunkTokenSecretKey = "sf_token_key"
+	splunkTokenHeaderKey = "X-SF-Token"
+)
+
+// SplunkProvider executes SignalFlow programs against Splunk Observability Cloud
+type SplunkProvider struct {
+	timeout   time.Duration
+	streamURL url.URL
+	apiURL    url.URL
+	token     string
+	interval  time.Duration
+	client    *http.Client
+}
+
+type splunkDataMessage struct {
+	Data []struct {
+		TsID  string   `json:"tsId"`
+		Value *float64 `json:"value"`
+	} `json:"data"`
+	LogicalTimestampMs int64 `json:"logicalTimestampMs"`
+}
+
+// NewSplunkProvider takes a metric interval, a provider spec and the credentials map,
+// and returns a Splunk client ready to execute SignalFlow programs, the address is
+// the realm stream endpoint e.g. https://stream.us1.signalfx.com
+func NewSplunkProvider(metricInterval string,
+	provider flaggerv1.MetricTemplateProvider,
+	credentials map[string][]byte) (*SplunkProvider, error) {
+	streamURL, err := url.Parse(provider.Address)
+	if provider.Address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	// the REST API is served by the api host of the realm
+	apiURL := *streamURL
+	apiURL.Host = strings.Replace(streamURL.Host, "stream.", "api.", 1)
+
+	sp := SplunkProvider{
+		timeout:   10 * time.Second,
+		streamURL: *streamURL,
+		apiURL:    apiURL,
+		client:    http.DefaultClient,
+	}
+
+	if b, ok := credentials[splunkTokenSecretKey]; ok {
+		sp.token = string(b)
+	} else {
+		return nil, fmt.Errorf("splunk credentials does not contain %s", splunkTokenSecretKey)
+	}
+
+	sp.interval, err = time.ParseDuration(metricInterval)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metric interval: %w", err)
+	}
+
+	return &sp, nil
+}
+
+// RunQuery executes the SignalFlow program over the metric interval
+// and returns the last value published by the program
+func (p *SplunkProvider) RunQuery(query string) (float64, error) {
+	now := time.Now()
+	u := p.streamURL
+	u.Path = path.Join(p.streamURL.Path, splunkSignalFlowPath)
+	u.RawQuery = url.Values{
+		"start":     []string{strconv.FormatInt(now.Add(-p.interval).UnixMilli(), 10)},
+		"stop":      []string{strconv.FormatInt(now.UnixMilli(), 10)},
+		"immediate": []string{"true"},
+	}.Encode()
+
+	req, err := http.NewRequest("POST", u.String(), strings.NewReader(query))
+	if err != nil {
+		return 0, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set(splunkTokenHeaderKey, p.token)
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(r.Body)
+		return 0, fmt.Errorf("error response: %s", string(b))
+	}
+
+	value, err := lastSignalFlowValue(r.Body)
+	if err != nil {
+		return 0, err
+	}
+	return *value, nil
+}
+
+// lastSignalFlowValue reads the server-sent events stream of a SignalFlow
+// computation and returns the last non null value of the data messages
+func lastSignalFlowValue(stream io.Reader) (*float64, error) {
+	var value *float64
+	var event string
+	var data []string
+
+	dispatch := func() error {
+		if event == "data" && len(data) > 0 {
+			var msg splunkDataMessage
+			if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &msg); err != nil {
+				return fmt.Errorf("error unmarshaling data message: %w", err)
+			}
+			for _, d := range msg.Data {
+				if d.Value != nil {
+					value = d.Value
+				}
+			}
+		}
+		event, data = "", nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading stream: %w", err)
+	}
+	if err := dispatch(); err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		return nil, fmt.Errorf("%w", ErrNoValuesFound)
+	}
+	return value, nil
+}
+
+// IsOnline calls the metrics API of the realm with the token
+// and returns an error if the endpoint fails
+func (p *SplunkProvider) IsOnline() (bool, error) {
+	u := p.apiURL
+	req, err := http.NewRequest("GET", u.String()+splunkValidationPath, nil)
+	if err != nil {
+		return false, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+	req.Header.Set(splunkTokenHeaderKey, p.token)
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("request failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return false, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return true, nil
+}
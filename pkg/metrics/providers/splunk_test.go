@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewSplunkProvider(t *testing.T) {
+	cs := map[string][]byte{splunkTokenSecretKey: []byte("token")}
+
+	_, err := NewSplunkProvider("1m", flaggerv1.MetricTemplateProvider{}, cs)
+	require.Error(t, err)
+
+	_, err = NewSplunkProvider("1m", flaggerv1.MetricTemplateProvider{Address: "https://stream.us1.signalfx.com"}, nil)
+	require.Error(t, err)
+
+	sp, err := NewSplunkProvider("1m", flaggerv1.MetricTemplateProvider{Address: "https://stream.us1.signalfx.com"}, cs)
+	require.NoError(t, err)
+	assert.Equal(t, "stream.us1.signalfx.com", sp.streamURL.Host)
+	assert.Equal(t, "api.us1.signalfx.com", sp.apiURL.Host)
+	assert.Equal(t, time.Minute, sp.interval)
+	assert.Equal(t, "token", sp.token)
+}
+
+func TestSplunkProvider_RunQuery(t *testing.T) {
+	program := `data('demo.trans.latency', filter=filter('demo_host', 'server6')).mean().publish()`
+
+	t.Run("ok", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, splunkSignalFlowPath, r.URL.Path)
+			assert.Equal(t, "token", r.Header.Get(splunkTokenHeaderKey))
+			assert.Equal(t, "true", r.URL.Query().Get("immediate"))
+			start, err := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64)
+			require.NoError(t, err)
+			stop, err := strconv.ParseInt(r.URL.Query().Get("stop"), 10, 64)
+			require.NoError(t, err)
+			assert.Equal(t, int64(60000), stop-start)
+
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, program, string(b))
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte(`event: control-message
+data: {
+data:   "event" : "STREAM_START",
+data:   "timestampMs" : 1489513200000
+data: }
+
+event: data
+data: {
+data:   "data" : [ { "tsId" : "AAAAAKz8a8U", "value" : 205.5 } ],
+data:   "logicalTimestampMs" : 1489513200000
+data: }
+
+event: data
+data: {
+data:   "data" : [ { "tsId" : "AAAAAKz8a8U", "value" : 198.25 } ],
+data:   "logicalTimestampMs" : 1489513230000
+data: }
+
+event: data
+data: {
+data:   "data" : [ { "tsId" : "AAAAAKz8a8U", "value" : null } ],
+data:   "logicalTimestampMs" : 1489513260000
+data: }
+
+event: control-message
+data: {
+data:   "event" : "END_OF_CHANNEL",
+data:   "timestampMs" : 1489513260000
+data: }
+`))
+		}))
+		defer ts.Close()
+
+		sp, err := NewSplunkProvider("1m", flaggerv1.MetricTemplateProvider{Address: ts.URL},
+			map[string][]byte{splunkTokenSecretKey: []byte("token")})
+		require.NoError(t, err)
+
+		f, err := sp.RunQuery(program)
+		require.NoError(t, err)
+		assert.Equal(t, 198.25, f)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("event: control-message\ndata: {\"event\" : \"END_OF_CHANNEL\"}\n\n"))
+		}))
+		defer ts.Close()
+
+		sp, err := NewSplunkProvider("1m", flaggerv1.MetricTemplateProvider{Address: ts.URL},
+			map[string][]byte{splunkTokenSecretKey: []byte("token")})
+		require.NoError(t, err)
+
+		_, err = sp.RunQuery(program)
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer ts.Close()
+
+		sp, err := NewSplunkProvider("1m", flaggerv1.MetricTemplateProvider{Address: ts.URL},
+			map[string][]byte{splunkTokenSecretKey: []byte("token")})
+		require.NoError(t, err)
+
+		_, err = sp.RunQuery(program)
+		require.Error(t, err)
+	})
+}
+
+func TestSplunkProvider_IsOnline(t *testing.T) {
+	for _, c := range []struct {
+		code        int
+		errExpected bool
+	}{
+		{code: http.StatusOK, errExpected: false},
+		{code: http.StatusUnauthorized, errExpected: true},
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v2/metric", r.URL.Path)
+			assert.Equal(t, "token", r.Header.Get(splunkTokenHeaderKey))
+			w.WriteHeader(c.code)
+		}))
+
+		sp, err := NewSplunkProvider("1m", flaggerv1.MetricTemplateProvider{Address: ts.URL},
+			map[string][]byte{splunkTokenSecretKey: []byte("token")})
+		require.NoError(t, err)
+
+		_, err = sp.IsOnline()
+		if c.errExpected {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+		ts.Close()
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/spiffe"
+)
+
+const webhookTokenSecretKey = "token"
+
+// WebhookProvider posts the rendered query to an HTTP endpoint
+// and reads the metric value from the response body
+type WebhookProvider struct {
+	url     url.URL
+	token   string
+	timeout time.Duration
+	client  *http.Client
+}
+
+type webhookResponse struct {
+	Value *float64 `json:"value"`
+}
+
+// NewWebhookProvider takes a provider spec and the credentials map, validates the address
+// and returns a client ready to post queries to the webhook
+func NewWebhookProvider(provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*WebhookProvider, error) {
+	hookURL, err := url.Parse(provider.Address)
+	if provider.Address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	wp := WebhookProvider{
+		url:     *hookURL,
+		timeout: 30 * time.Second,
+		client:  http.DefaultClient,
+	}
+
+	if provider.InsecureSkipVerify {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		wp.client = &http.Client{Transport: t}
+	}
+
+	if provider.SPIFFE {
+		client, err := spiffe.DefaultHTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		wp.client = client
+	}
+
+	if b, ok := credentials[webhookTokenSecretKey]; ok {
+		wp.token = string(b)
+	}
+
+	return &wp, nil
+}
+
+// RunQuery posts the query to the webhook, the response body must contain
+// a number or a JSON object with a numeric value field e.g. {"value": 99.5}
+func (p *WebhookProvider) RunQuery(query string) (float64, error) {
+	body := strings.TrimSpace(query)
+	req, err := http.NewRequest("POST", p.url.String(), bytes.NewBufferString(body))
+	if err != nil {
+		return 0, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+	if json.Valid([]byte(body)) {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "text/plain")
+	}
+
+	b, err := p.do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	return parseWebhookValue(b)
+}
+
+// IsOnline calls the webhook address and returns an error if the endpoint is unreachable,
+// any HTTP response is considered online as the webhook may accept only POST requests
+func (p *WebhookProvider) IsOnline() (bool, error) {
+	req, err := http.NewRequest("GET", p.url.String(), nil)
+	if err != nil {
+		return false, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("request failed: %w", err)
+	}
+	r.Body.Close()
+
+	return true, nil
+}
+
+func (p *WebhookProvider) do(req *http.Request) ([]byte, error) {
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer r.Body.Close()
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return b, nil
+}
+
+func parseWebhookValue(b []byte) (float64, error) {
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		return 0, fmt.Errorf("invalid response: empty body: %w", ErrNoValuesFound)
+	}
+
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, nil
+	}
+
+	var res webhookResponse
+	if err := json.Unmarshal([]byte(s), &res); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, s)
+	}
+	if res.Value == nil {
+		return 0, fmt.Errorf("invalid response: %s: %w", s, ErrNoValuesFound)
+	}
+
+	return *res.Value, nil
+}
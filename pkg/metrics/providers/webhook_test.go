@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewWebhookProvider(t *testing.T) {
+	_, err := NewWebhookProvider(flaggerv1.MetricTemplateProvider{Type: "webhook"}, nil)
+	require.Error(t, err)
+
+	wp, err := NewWebhookProvider(flaggerv1.MetricTemplateProvider{
+		Type:    "webhook",
+		Address: "http://flagger-loadtester.test/",
+	}, map[string][]byte{"token": []byte("secret")})
+	require.NoError(t, err)
+	assert.Equal(t, "http://flagger-loadtester.test/", wp.url.String())
+	assert.Equal(t, "secret", wp.token)
+}
+
+func TestWebhookProvider_RunQuery(t *testing.T) {
+	query := `{"name":"podinfo","namespace":"test"}`
+	for _, tt := range []struct {
+		name     string
+		response string
+		expected float64
+		err      error
+	}{
+		{name: "number", response: "99.5\n", expected: 99.5},
+		{name: "json", response: `{"value": 42}`, expected: 42},
+		{name: "no value", response: `{"result": 42}`, err: ErrNoValuesFound},
+		{name: "empty", response: "", err: ErrNoValuesFound},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "POST", r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+				b, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, query, string(b))
+				w.Write([]byte(tt.response))
+			}))
+			defer ts.Close()
+
+			wp, err := NewWebhookProvider(flaggerv1.MetricTemplateProvider{
+				Type:    "webhook",
+				Address: ts.URL,
+			}, map[string][]byte{"token": []byte("secret")})
+			require.NoError(t, err)
+
+			val, err := wp.RunQuery(query)
+			if tt.err != nil {
+				require.True(t, errors.Is(err, tt.err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, val)
+		})
+	}
+}
+
+func TestWebhookProvider_RunQueryError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("command failed"))
+	}))
+	defer ts.Close()
+
+	wp, err := NewWebhookProvider(flaggerv1.MetricTemplateProvider{
+		Type:    "webhook",
+		Address: ts.URL,
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = wp.RunQuery("exit 1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command failed")
+}
+
+func TestWebhookProvider_IsOnline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+
+	wp, err := NewWebhookProvider(flaggerv1.MetricTemplateProvider{
+		Type:    "webhook",
+		Address: ts.URL,
+	}, nil)
+	require.NoError(t, err)
+
+	ok, err := wp.IsOnline()
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ts.Close()
+	ok, err = wp.IsOnline()
+	require.Error(t, err)
+	assert.False(t, ok)
+}
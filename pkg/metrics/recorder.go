@@ -18,6 +18,7 @@ package metrics
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -26,11 +27,17 @@ import (
 
 // Recorder records the canary analysis as Prometheus metrics
 type Recorder struct {
-	info     *prometheus.GaugeVec
-	duration *prometheus.HistogramVec
-	total    *prometheus.GaugeVec
-	status   *prometheus.GaugeVec
-	weight   *prometheus.GaugeVec
+	info      *prometheus.GaugeVec
+	duration  *prometheus.HistogramVec
+	total     *prometheus.GaugeVec
+	status    *prometheus.GaugeVec
+	weight    *prometheus.GaugeVec
+	run       *prometheus.GaugeVec
+	verify    *prometheus.GaugeVec
+	rollouts  *prometheus.CounterVec
+	promotion *prometheus.HistogramVec
+	rollbacks *prometheus.CounterVec
+	runIDs    *sync.Map
 }
 
 // NewRecorder creates a new recorder and registers the Prometheus metrics
@@ -67,20 +74,64 @@ func NewRecorder(controller string, register bool) Recorder {
 		Help:      "The virtual service destination weight current value",
 	}, []string{"workload", "namespace"})
 
+	// the run series is replaced when a new analysis starts
+	run := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: controller,
+		Name:      "canary_analysis_run",
+		Help:      "Unix timestamp of the last canary analysis start, labeled with the analysis run ID",
+	}, []string{"name", "namespace", "run_id"})
+
+	// 1 - successful, 2 - failed
+	verify := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: controller,
+		Name:      "canary_verification_status",
+		Help:      "Last scheduled verification result of the primary",
+	}, []string{"name", "namespace"})
+
+	rollouts := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: controller,
+		Name:      "canary_rollouts_total",
+		Help:      "Total number of finished canary rollouts per team and result",
+	}, []string{"team", "result"})
+
+	promotion := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: controller,
+		Name:      "canary_promotion_duration_seconds",
+		Help:      "Seconds from the start of the canary analysis to the promotion per team.",
+		Buckets:   prometheus.ExponentialBuckets(60, 2, 10),
+	}, []string{"team"})
+
+	rollbacks := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: controller,
+		Name:      "canary_rollbacks_total",
+		Help:      "Total number of canary rollbacks per team and cause",
+	}, []string{"team", "cause"})
+
 	if register {
 		prometheus.MustRegister(info)
 		prometheus.MustRegister(duration)
 		prometheus.MustRegister(total)
 		prometheus.MustRegister(status)
 		prometheus.MustRegister(weight)
+		prometheus.MustRegister(run)
+		prometheus.MustRegister(verify)
+		prometheus.MustRegister(rollouts)
+		prometheus.MustRegister(promotion)
+		prometheus.MustRegister(rollbacks)
 	}
 
 	return Recorder{
-		info:     info,
-		duration: duration,
-		total:    total,
-		status:   status,
-		weight:   weight,
+		info:      info,
+		duration:  duration,
+		total:     total,
+		status:    status,
+		weight:    weight,
+		run:       run,
+		verify:    verify,
+		rollouts:  rollouts,
+		promotion: promotion,
+		rollbacks: rollbacks,
+		runIDs:    new(sync.Map),
 	}
 }
 
@@ -89,9 +140,17 @@ func (cr *Recorder) SetInfo(version string, meshProvider string) {
 	cr.info.WithLabelValues(version, meshProvider).Set(1)
 }
 
-// SetDuration sets the time spent in seconds performing canary analysis
+// SetDuration sets the time spent in seconds performing canary analysis,
+// the observation has the analysis run ID as exemplar
 func (cr *Recorder) SetDuration(cd *flaggerv1.Canary, duration time.Duration) {
-	cr.duration.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace).Observe(duration.Seconds())
+	observer := cr.duration.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace)
+	if id := cd.Status.AnalysisRunID; id != "" {
+		if eo, ok := observer.(prometheus.ExemplarObserver); ok {
+			eo.ObserveWithExemplar(duration.Seconds(), prometheus.Labels{"run_id": id})
+			return
+		}
+	}
+	observer.Observe(duration.Seconds())
 }
 
 // SetTotal sets the total number of canaries per namespace
@@ -111,6 +170,40 @@ func (cr *Recorder) SetStatus(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase)
 		status = 1
 	}
 	cr.status.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace).Set(float64(status))
+	cr.setRun(cd)
+}
+
+// setRun exports the analysis run ID of the canary, replacing the previous run series
+func (cr *Recorder) setRun(cd *flaggerv1.Canary) {
+	id := cd.Status.AnalysisRunID
+	if id == "" {
+		return
+	}
+	key := fmt.Sprintf("%s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+	if prev, ok := cr.runIDs.Load(key); ok {
+		if prev.(string) == id {
+			return
+		}
+		cr.run.DeleteLabelValues(cd.Spec.TargetRef.Name, cd.Namespace, prev.(string))
+	}
+	cr.runIDs.Store(key, id)
+
+	start := cd.Status.LastTransitionTime.Time
+	for _, condition := range cd.Status.Conditions {
+		if condition.Type == flaggerv1.PromotedType && condition.Reason == string(flaggerv1.CanaryPhaseProgressing) {
+			start = condition.LastTransitionTime.Time
+		}
+	}
+	cr.run.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace, id).Set(float64(start.Unix()))
+}
+
+// SetVerification sets the last scheduled verification result of the primary
+func (cr *Recorder) SetVerification(cd *flaggerv1.Canary, succeeded bool) {
+	status := 2
+	if succeeded {
+		status = 1
+	}
+	cr.verify.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace).Set(float64(status))
 }
 
 // SetWeight sets the weight values for primary and canary destinations
@@ -118,3 +211,18 @@ func (cr *Recorder) SetWeight(cd *flaggerv1.Canary, primary int, canary int) {
 	cr.weight.WithLabelValues(fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name), cd.Namespace).Set(float64(primary))
 	cr.weight.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace).Set(float64(canary))
 }
+
+// SetPromotion counts a successful rollout of the team, the duration is
+// observed only when the start of the analysis is known
+func (cr *Recorder) SetPromotion(team string, duration time.Duration) {
+	cr.rollouts.WithLabelValues(team, "succeeded").Inc()
+	if duration > 0 {
+		cr.promotion.WithLabelValues(team).Observe(duration.Seconds())
+	}
+}
+
+// SetRollback counts a failed rollout of the team and its cause
+func (cr *Recorder) SetRollback(team string, cause string) {
+	cr.rollouts.WithLabelValues(team, "failed").Inc()
+	cr.rollbacks.WithLabelValues(team, cause).Inc()
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// AdmissionPath is the URL path of the canary validating webhook
+const AdmissionPath = "/validate-canary"
+
+// AdmissionHandler is a validating admission webhook that denies
+// the creation of canaries over the tenant quota
+func (c *Checker) AdmissionHandler(logger *zap.SugaredLogger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		review := &admissionv1.AdmissionReview{}
+		if err := json.NewDecoder(r.Body).Decode(review); err != nil || review.Request == nil {
+			http.Error(w, fmt.Sprintf("invalid admission review %v", err), http.StatusBadRequest)
+			return
+		}
+
+		response := &admissionv1.AdmissionResponse{
+			UID:     review.Request.UID,
+			Allowed: true,
+		}
+
+		if review.Request.Operation == admissionv1.Create {
+			cd := &flaggerv1.Canary{}
+			if err := json.Unmarshal(review.Request.Object.Raw, cd); err != nil {
+				http.Error(w, fmt.Sprintf("invalid canary %v", err), http.StatusBadRequest)
+				return
+			}
+			if cd.Namespace == "" {
+				cd.Namespace = review.Request.Namespace
+			}
+
+			if err := c.CheckCanaries(r.Context(), cd); err != nil {
+				logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof("Canary denied %v", err)
+				response.Allowed = false
+				response.Result = &metav1.Status{
+					Status:  metav1.StatusFailure,
+					Message: err.Error(),
+					Reason:  metav1.StatusReasonForbidden,
+					Code:    http.StatusForbidden,
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&admissionv1.AdmissionReview{
+			TypeMeta: review.TypeMeta,
+			Response: response,
+		})
+	})
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// Quota limits the number of canaries and concurrent analyses of a tenant,
+// a zero value means unlimited
+type Quota struct {
+	// MaxCanaries is the max number of canary objects
+	MaxCanaries int
+	// MaxAnalyses is the max number of canary analyses running at the same time
+	MaxAnalyses int
+	// TenantLabel is the namespace label that groups namespaces into tenants,
+	// when empty or when a namespace doesn't have the label, the namespace is the tenant
+	TenantLabel string
+}
+
+// Checker verifies the canary quotas of a tenant
+type Checker struct {
+	quota         Quota
+	kubeClient    kubernetes.Interface
+	flaggerClient clientset.Interface
+}
+
+// NewChecker returns a quota checker or nil if the quota is unlimited
+func NewChecker(quota Quota, kubeClient kubernetes.Interface, flaggerClient clientset.Interface) *Checker {
+	if quota.MaxCanaries < 1 && quota.MaxAnalyses < 1 {
+		return nil
+	}
+	return &Checker{
+		quota:         quota,
+		kubeClient:    kubeClient,
+		flaggerClient: flaggerClient,
+	}
+}
+
+// CheckCanaries returns an error if creating the canary exceeds the max canaries of its tenant
+func (c *Checker) CheckCanaries(ctx context.Context, cd *flaggerv1.Canary) error {
+	if c.quota.MaxCanaries < 1 {
+		return nil
+	}
+
+	tenant, canaries, err := c.tenantCanaries(ctx, cd)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, item := range canaries {
+		if !sameCanary(item, cd) {
+			count++
+		}
+	}
+	if count >= c.quota.MaxCanaries {
+		return fmt.Errorf("canary quota exceeded for %s: %d/%d canaries", tenant, count, c.quota.MaxCanaries)
+	}
+	return nil
+}
+
+// CheckAnalyses returns an error if starting the canary analysis exceeds the max analyses of its tenant
+func (c *Checker) CheckAnalyses(ctx context.Context, cd *flaggerv1.Canary) error {
+	if c.quota.MaxAnalyses < 1 {
+		return nil
+	}
+
+	tenant, canaries, err := c.tenantCanaries(ctx, cd)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, item := range canaries {
+		if !sameCanary(item, cd) && isAnalysing(item.Status.Phase) {
+			count++
+		}
+	}
+	if count >= c.quota.MaxAnalyses {
+		return fmt.Errorf("analysis quota exceeded for %s: %d/%d analyses running", tenant, count, c.quota.MaxAnalyses)
+	}
+	return nil
+}
+
+// tenantCanaries returns the tenant of the canary and the canaries in the tenant namespaces
+func (c *Checker) tenantCanaries(ctx context.Context, cd *flaggerv1.Canary) (string, []flaggerv1.Canary, error) {
+	tenant := fmt.Sprintf("namespace %s", cd.Namespace)
+	namespaces := []string{cd.Namespace}
+
+	if c.quota.TenantLabel != "" {
+		ns, err := c.kubeClient.CoreV1().Namespaces().Get(ctx, cd.Namespace, metav1.GetOptions{})
+		if err != nil {
+			return "", nil, fmt.Errorf("namespace %s get query error: %w", cd.Namespace, err)
+		}
+
+		if value, ok := ns.Labels[c.quota.TenantLabel]; ok && value != "" {
+			tenant = fmt.Sprintf("%s=%s", c.quota.TenantLabel, value)
+			list, err := c.kubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{
+				LabelSelector: labels.Set{c.quota.TenantLabel: value}.String(),
+			})
+			if err != nil {
+				return "", nil, fmt.Errorf("namespaces %s list query error: %w", tenant, err)
+			}
+			namespaces = namespaces[:0]
+			for _, item := range list.Items {
+				namespaces = append(namespaces, item.Name)
+			}
+		}
+	}
+
+	var canaries []flaggerv1.Canary
+	for _, namespace := range namespaces {
+		list, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return "", nil, fmt.Errorf("canaries %s list query error: %w", namespace, err)
+		}
+		canaries = append(canaries, list.Items...)
+	}
+	return tenant, canaries, nil
+}
+
+func sameCanary(a flaggerv1.Canary, b *flaggerv1.Canary) bool {
+	return a.Name == b.Name && a.Namespace == b.Namespace
+}
+
+func isAnalysing(phase flaggerv1.CanaryPhase) bool {
+	switch phase {
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaitingPromotion,
+		flaggerv1.CanaryPhasePromoting, flaggerv1.CanaryPhaseFinalising:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/fluxcd/flagger/pkg/client/clientset/versioned/fake"
+)
+
+func newTestNamespace(name, team string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"team": team}}}
+}
+
+func newTestCanary(name, namespace string, phase flaggerv1.CanaryPhase) *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Status:     flaggerv1.CanaryStatus{Phase: phase},
+	}
+}
+
+func TestChecker_Unlimited(t *testing.T) {
+	assert.Nil(t, NewChecker(Quota{}, fake.NewSimpleClientset(), fakeFlagger.NewSimpleClientset()))
+}
+
+func TestChecker_CheckCanaries(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		newTestNamespace("frontend", "web"),
+		newTestNamespace("backend", "web"),
+		newTestNamespace("billing", "payments"),
+	)
+	flaggerClient := fakeFlagger.NewSimpleClientset(
+		newTestCanary("podinfo", "frontend", flaggerv1.CanaryPhaseSucceeded),
+		newTestCanary("api", "backend", flaggerv1.CanaryPhaseSucceeded),
+	)
+
+	// one tenant per namespace
+	checker := NewChecker(Quota{MaxCanaries: 2}, kubeClient, flaggerClient)
+	assert.NoError(t, checker.CheckCanaries(context.TODO(), newTestCanary("new", "frontend", "")))
+
+	// namespaces grouped by the team label
+	checker = NewChecker(Quota{MaxCanaries: 2, TenantLabel: "team"}, kubeClient, flaggerClient)
+	err := checker.CheckCanaries(context.TODO(), newTestCanary("new", "frontend", ""))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "team=web")
+	assert.NoError(t, checker.CheckCanaries(context.TODO(), newTestCanary("podinfo", "frontend", "")))
+	assert.NoError(t, checker.CheckCanaries(context.TODO(), newTestCanary("new", "billing", "")))
+}
+
+func TestChecker_CheckAnalyses(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(newTestNamespace("frontend", "web"))
+	flaggerClient := fakeFlagger.NewSimpleClientset(
+		newTestCanary("podinfo", "frontend", flaggerv1.CanaryPhaseProgressing),
+		newTestCanary("api", "frontend", flaggerv1.CanaryPhaseSucceeded),
+	)
+	checker := NewChecker(Quota{MaxAnalyses: 1}, kubeClient, flaggerClient)
+
+	err := checker.CheckAnalyses(context.TODO(), newTestCanary("api", "frontend", flaggerv1.CanaryPhaseSucceeded))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1/1 analyses running")
+	assert.NoError(t, checker.CheckAnalyses(context.TODO(), newTestCanary("podinfo", "frontend", flaggerv1.CanaryPhaseProgressing)))
+}
+
+func TestChecker_AdmissionHandler(t *testing.T) {
+	flaggerClient := fakeFlagger.NewSimpleClientset(newTestCanary("podinfo", "test", flaggerv1.CanaryPhaseSucceeded))
+	checker := NewChecker(Quota{MaxCanaries: 1}, fake.NewSimpleClientset(), flaggerClient)
+	handler := checker.AdmissionHandler(zap.NewNop().Sugar())
+
+	review := func(operation admissionv1.Operation) *admissionv1.AdmissionResponse {
+		raw, err := json.Marshal(newTestCanary("api", "", ""))
+		require.NoError(t, err)
+		body, err := json.Marshal(&admissionv1.AdmissionReview{
+			Request: &admissionv1.AdmissionRequest{
+				UID:       "1",
+				Operation: operation,
+				Namespace: "test",
+				Object:    runtime.RawExtension{Raw: raw},
+			},
+		})
+		require.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, AdmissionPath, bytes.NewReader(body)))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		result := &admissionv1.AdmissionReview{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), result))
+		require.NotNil(t, result.Response)
+		assert.Equal(t, "1", string(result.Response.UID))
+		return result.Response
+	}
+
+	response := review(admissionv1.Create)
+	assert.False(t, response.Allowed)
+	assert.Contains(t, response.Result.Message, "namespace test")
+
+	assert.True(t, review(admissionv1.Update).Allowed)
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	apisixv2 "github.com/fluxcd/flagger/pkg/apis/apisix/v2"
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// ApisixRouter is managing APISIX routes
+type ApisixRouter struct {
+	apisixClient clientset.Interface
+	logger       *zap.SugaredLogger
+}
+
+// Reconcile creates or updates the canary APISIX route from the HTTP rules
+// of the referenced route that are routing to the apex service
+func (ar *ApisixRouter) Reconcile(canary *flaggerv1.Canary) error {
+	if canary.Spec.RouteRef == nil || canary.Spec.RouteRef.Name == "" {
+		return fmt.Errorf("ApisixRoute selector is empty")
+	}
+
+	apexName, _, _ := canary.GetServiceNames()
+	canaryRouteName := ar.getCanaryRouteName(canary)
+
+	route, err := ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Get(context.TODO(), canary.Spec.RouteRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ApisixRoute %s.%s get query error: %w", canary.Spec.RouteRef.Name, canary.Namespace, err)
+	}
+
+	var rules []apisixv2.ApisixRouteHTTP
+	for _, rule := range route.Spec.HTTP {
+		for _, backend := range rule.Backends {
+			if backend.ServiceName == apexName {
+				rules = append(rules, *rule.DeepCopy())
+				break
+			}
+		}
+	}
+	if len(rules) == 0 {
+		return fmt.Errorf("ApisixRoute %s.%s has no HTTP rule routing to the service %s",
+			canary.Spec.RouteRef.Name, canary.Namespace, apexName)
+	}
+
+	newSpec := ar.makeSpec(canary, rules, 100, 0)
+
+	canaryRoute, err := ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Get(context.TODO(), canaryRouteName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		metadata := canary.Spec.Service.Apex
+		if metadata == nil {
+			metadata = &flaggerv1.CustomMetadata{}
+		}
+		if metadata.Labels == nil {
+			metadata.Labels = make(map[string]string)
+		}
+		if metadata.Annotations == nil {
+			metadata.Annotations = make(map[string]string)
+		}
+
+		canaryRoute = &apisixv2.ApisixRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        canaryRouteName,
+				Namespace:   canary.Namespace,
+				Labels:      metadata.Labels,
+				Annotations: withOwnership(filterMetadata(metadata.Annotations), canary, newSpec),
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: newSpec,
+		}
+
+		_, err = ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Create(context.TODO(), canaryRoute, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("ApisixRoute %s.%s create error: %w", canaryRouteName, canary.Namespace, err)
+		}
+		ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ApisixRoute %s.%s created", canaryRoute.GetName(), canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("ApisixRoute %s.%s get query error: %w", canaryRouteName, canary.Namespace, err)
+	}
+
+	// update ApisixRoute but keep the original backend weights
+	if canaryRoute != nil {
+		if diff := cmp.Diff(
+			newSpec,
+			canaryRoute.Spec,
+			cmpopts.IgnoreFields(apisixv2.ApisixRouteHTTPBackend{}, "Weight"),
+		); diff != "" {
+			drifted, err := checkDrift(canary, "ApisixRoute", canaryRoute, newSpec)
+			if err != nil {
+				return err
+			}
+			clone := canaryRoute.DeepCopy()
+			clone.Spec = newSpec
+			clone.Annotations = withOwnership(clone.Annotations, canary, newSpec)
+
+			// the rules of the referenced route have changed, keep the current weights
+			if primaryWeight, canaryWeight, err := ar.getWeights(canary, canaryRoute); err == nil {
+				ar.setWeights(canary, clone, primaryWeight, canaryWeight)
+			}
+
+			_, err = ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+			if err != nil {
+				return fmt.Errorf("ApisixRoute %s.%s update error: %w", canaryRouteName, canary.Namespace, err)
+			}
+			ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+				Infof("ApisixRoute %s.%s updated", canaryRoute.GetName(), canary.Namespace)
+			if drifted {
+				return repairedDrift("ApisixRoute", canaryRoute)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetRoutes returns the backend weight for primary and canary
+func (ar *ApisixRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	canaryRouteName := ar.getCanaryRouteName(canary)
+	canaryRoute, err := ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Get(context.TODO(), canaryRouteName, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("ApisixRoute %s.%s get query error: %w", canaryRouteName, canary.Namespace, err)
+		return
+	}
+
+	primaryWeight, canaryWeight, err = ar.getWeights(canary, canaryRoute)
+	return
+}
+
+// SetRoutes updates the backend weight for primary and canary
+func (ar *ApisixRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	canaryRouteName := ar.getCanaryRouteName(canary)
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		return fmt.Errorf("ApisixRoute %s.%s update failed: no valid weights", canaryRouteName, canary.Namespace)
+	}
+
+	canaryRoute, err := ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Get(context.TODO(), canaryRouteName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ApisixRoute %s.%s query error: %w", canaryRouteName, canary.Namespace, err)
+	}
+
+	clone := canaryRoute.DeepCopy()
+	ar.setWeights(canary, clone, primaryWeight, canaryWeight)
+
+	_, err = ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("ApisixRoute %s.%s update error: %w", canaryRouteName, canary.Namespace, err)
+	}
+	return nil
+}
+
+func (ar *ApisixRouter) Finalize(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// getCanaryRouteName returns the name of the route generated from the referenced route
+func (ar *ApisixRouter) getCanaryRouteName(canary *flaggerv1.Canary) string {
+	apexName, _, _ := canary.GetServiceNames()
+	name := apexName
+	if canary.Spec.RouteRef != nil {
+		name = canary.Spec.RouteRef.Name
+	}
+	return fmt.Sprintf("%s-%s-canary", name, apexName)
+}
+
+// makeSpec replaces the apex backend of the rules with the weighted primary and canary backends,
+// the rules priority is raised to take precedence over the referenced route
+func (ar *ApisixRouter) makeSpec(canary *flaggerv1.Canary, rules []apisixv2.ApisixRouteHTTP, primaryWeight int, canaryWeight int) apisixv2.ApisixRouteSpec {
+	_, primaryName, canaryName := canary.GetServiceNames()
+	for i := range rules {
+		rules[i].Priority++
+		rules[i].Backends = []apisixv2.ApisixRouteHTTPBackend{
+			ar.makeBackend(canary, primaryName, primaryWeight),
+			ar.makeBackend(canary, canaryName, canaryWeight),
+		}
+	}
+	return apisixv2.ApisixRouteSpec{HTTP: rules}
+}
+
+func (ar *ApisixRouter) makeBackend(canary *flaggerv1.Canary, name string, weight int) apisixv2.ApisixRouteHTTPBackend {
+	w := weight
+	return apisixv2.ApisixRouteHTTPBackend{
+		ServiceName: name,
+		ServicePort: intstr.FromInt(int(canary.Spec.Service.Port)),
+		Weight:      &w,
+	}
+}
+
+func (ar *ApisixRouter) getWeights(canary *flaggerv1.Canary, route *apisixv2.ApisixRoute) (primaryWeight int, canaryWeight int, err error) {
+	_, primaryName, canaryName := canary.GetServiceNames()
+	if len(route.Spec.HTTP) < 1 || len(route.Spec.HTTP[0].Backends) < 2 {
+		err = fmt.Errorf("ApisixRoute %s.%s backends not found", route.Name, route.Namespace)
+		return
+	}
+	for _, backend := range route.Spec.HTTP[0].Backends {
+		if backend.Weight == nil {
+			continue
+		}
+		switch backend.ServiceName {
+		case primaryName:
+			primaryWeight = *backend.Weight
+		case canaryName:
+			canaryWeight = *backend.Weight
+		}
+	}
+	return
+}
+
+func (ar *ApisixRouter) setWeights(canary *flaggerv1.Canary, route *apisixv2.ApisixRoute, primaryWeight int, canaryWeight int) {
+	_, primaryName, canaryName := canary.GetServiceNames()
+	for i := range route.Spec.HTTP {
+		for j, backend := range route.Spec.HTTP[i].Backends {
+			switch backend.ServiceName {
+			case primaryName:
+				route.Spec.HTTP[i].Backends[j] = ar.makeBackend(canary, primaryName, primaryWeight)
+			case canaryName:
+				route.Spec.HTTP[i].Backends[j] = ar.makeBackend(canary, canaryName, canaryWeight)
+			}
+		}
+	}
+}
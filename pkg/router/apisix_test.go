@@ -0,0 +1,172 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	apisixv2 "github.com/fluxcd/flagger/pkg/apis/apisix/v2"
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newTestApisixRoute() *apisixv2.ApisixRoute {
+	return &apisixv2.ApisixRoute{
+		TypeMeta: metav1.TypeMeta{APIVersion: apisixv2.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+		},
+		Spec: apisixv2.ApisixRouteSpec{
+			HTTP: []apisixv2.ApisixRouteHTTP{
+				{
+					Name: "method",
+					Match: &apisixv2.ApisixRouteHTTPMatch{
+						Hosts:   []string{"app.example.com"},
+						Paths:   []string{"/*"},
+						Methods: []string{"GET"},
+					},
+					Backends: []apisixv2.ApisixRouteHTTPBackend{
+						{ServiceName: "podinfo", ServicePort: intstr.FromInt(80)},
+					},
+				},
+				{
+					Name: "other",
+					Match: &apisixv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/other"},
+					},
+					Backends: []apisixv2.ApisixRouteHTTPBackend{
+						{ServiceName: "other", ServicePort: intstr.FromInt(80)},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestApisixRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	mocks.canary.Spec.RouteRef = &flaggerv1.CrossNamespaceObjectReference{
+		APIVersion: apisixv2.SchemeGroupVersion.String(),
+		Kind:       "ApisixRoute",
+		Name:       "podinfo",
+	}
+	_, err := mocks.meshClient.ApisixV2().ApisixRoutes("default").Create(context.TODO(), newTestApisixRoute(), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	router := &ApisixRouter{
+		apisixClient: mocks.meshClient,
+		logger:       mocks.logger,
+	}
+
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	ar, err := router.apisixClient.ApisixV2().ApisixRoutes("default").Get(context.TODO(), "podinfo-podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo.default", ar.ObjectMeta.Annotations[ownerAnnotation])
+
+	require.Len(t, ar.Spec.HTTP, 1)
+	rule := ar.Spec.HTTP[0]
+	assert.Equal(t, "method", rule.Name)
+	assert.Equal(t, 1, rule.Priority)
+	assert.Equal(t, []string{"GET"}, rule.Match.Methods)
+	require.Len(t, rule.Backends, 2)
+	assert.Equal(t, "podinfo-primary", rule.Backends[0].ServiceName)
+	assert.Equal(t, 100, *rule.Backends[0].Weight)
+	assert.Equal(t, "podinfo-canary", rule.Backends[1].ServiceName)
+	assert.Equal(t, 0, *rule.Backends[1].Weight)
+
+	// update the referenced route and check the weights are preserved
+	require.NoError(t, router.SetRoutes(mocks.canary, 60, 40, false))
+
+	route, err := router.apisixClient.ApisixV2().ApisixRoutes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	route.Spec.HTTP[0].Match.Methods = []string{"GET", "POST"}
+	_, err = router.apisixClient.ApisixV2().ApisixRoutes("default").Update(context.TODO(), route, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	ar, err = router.apisixClient.ApisixV2().ApisixRoutes("default").Get(context.TODO(), "podinfo-podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GET", "POST"}, ar.Spec.HTTP[0].Match.Methods)
+
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+}
+
+func TestApisixRouter_Reconcile_NoMatchingRule(t *testing.T) {
+	mocks := newFixture(nil)
+	mocks.canary.Spec.RouteRef = &flaggerv1.CrossNamespaceObjectReference{
+		Name: "podinfo",
+	}
+	route := newTestApisixRoute()
+	route.Spec.HTTP = route.Spec.HTTP[1:]
+	_, err := mocks.meshClient.ApisixV2().ApisixRoutes("default").Create(context.TODO(), route, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	router := &ApisixRouter{
+		apisixClient: mocks.meshClient,
+		logger:       mocks.logger,
+	}
+
+	assert.Error(t, router.Reconcile(mocks.canary))
+}
+
+func TestApisixRouter_SetRoutes(t *testing.T) {
+	mocks := newFixture(nil)
+	mocks.canary.Spec.RouteRef = &flaggerv1.CrossNamespaceObjectReference{
+		Name: "podinfo",
+	}
+	_, err := mocks.meshClient.ApisixV2().ApisixRoutes("default").Create(context.TODO(), newTestApisixRoute(), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	router := &ApisixRouter{
+		apisixClient: mocks.meshClient,
+		logger:       mocks.logger,
+	}
+
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	for _, tt := range []struct {
+		name    string
+		primary int
+		canary  int
+	}{
+		{name: "0%", primary: 100, canary: 0},
+		{name: "20%", primary: 80, canary: 20},
+		{name: "60%", primary: 40, canary: 60},
+		{name: "100%", primary: 0, canary: 100},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, router.SetRoutes(mocks.canary, tt.primary, tt.canary, false))
+
+			p, c, m, err := router.GetRoutes(mocks.canary)
+			require.NoError(t, err)
+			assert.Equal(t, tt.primary, p)
+			assert.Equal(t, tt.canary, c)
+			assert.False(t, m)
+		})
+	}
+}
@@ -55,42 +55,44 @@ func (ar *AppMeshRouter) Reconcile(canary *flaggerv1.Canary) error {
 	primaryHost := fmt.Sprintf("%s.%s", primaryName, canary.Namespace)
 	canaryHost := fmt.Sprintf("%s.%s", canaryName, canary.Namespace)
 
+	var drift driftCollector
+
 	// sync virtual node e.g. app-namespace
 	// DNS app.namespace
-	err := ar.reconcileVirtualNode(canary, apexName, primaryHost)
+	err := drift.check(ar.reconcileVirtualNode(canary, apexName, primaryHost))
 	if err != nil {
 		return fmt.Errorf("reconcileVirtualNode failed: %w", err)
 	}
 
 	// sync virtual node e.g. app-primary-namespace
 	// DNS app-primary.namespace
-	err = ar.reconcileVirtualNode(canary, primaryName, primaryHost)
+	err = drift.check(ar.reconcileVirtualNode(canary, primaryName, primaryHost))
 	if err != nil {
 		return fmt.Errorf("reconcileVirtualNode failed: %w", err)
 	}
 
 	// sync virtual node e.g. app-canary-namespace
 	// DNS app-canary.namespace
-	err = ar.reconcileVirtualNode(canary, canaryName, canaryHost)
+	err = drift.check(ar.reconcileVirtualNode(canary, canaryName, canaryHost))
 	if err != nil {
 		return fmt.Errorf("reconcileVirtualNode failed: %w", err)
 	}
 
 	// sync main virtual service
 	// DNS app.namespace
-	err = ar.reconcileVirtualService(canary, targetHost, 0)
+	err = drift.check(ar.reconcileVirtualService(canary, targetHost, 0))
 	if err != nil {
 		return fmt.Errorf("reconcileVirtualService failed: %w", err)
 	}
 
 	// sync canary virtual service
 	// DNS app-canary.namespace
-	err = ar.reconcileVirtualService(canary, fmt.Sprintf("%s.%s", canaryName, canary.Namespace), 100)
+	err = drift.check(ar.reconcileVirtualService(canary, fmt.Sprintf("%s.%s", canaryName, canary.Namespace), 100))
 	if err != nil {
 		return fmt.Errorf("reconcileVirtualService failed: %w", err)
 	}
 
-	return nil
+	return drift.err()
 }
 
 // reconcileVirtualNode creates or updates a virtual node
@@ -158,6 +160,7 @@ func (ar *AppMeshRouter) reconcileVirtualNode(canary *flaggerv1.Canary, name str
 			},
 			Spec: vnSpec,
 		}
+		virtualnode.Annotations = withOwnership(virtualnode.Annotations, canary, vnSpec)
 		_, err = ar.appmeshClient.AppmeshV1beta1().VirtualNodes(canary.Namespace).Create(context.TODO(), virtualnode, metav1.CreateOptions{})
 		if err != nil {
 			return fmt.Errorf("VirtualNode %s.%s create error %w", name, canary.Namespace, err)
@@ -172,14 +175,22 @@ func (ar *AppMeshRouter) reconcileVirtualNode(canary *flaggerv1.Canary, name str
 	// update virtual node
 	if virtualnode != nil {
 		if diff := cmp.Diff(vnSpec, virtualnode.Spec); diff != "" {
+			drifted, err := checkDrift(canary, "VirtualNode", virtualnode, vnSpec)
+			if err != nil {
+				return err
+			}
 			vnClone := virtualnode.DeepCopy()
 			vnClone.Spec = vnSpec
+			vnClone.Annotations = withOwnership(vnClone.Annotations, canary, vnSpec)
 			_, err = ar.appmeshClient.AppmeshV1beta1().VirtualNodes(canary.Namespace).Update(context.TODO(), vnClone, metav1.UpdateOptions{})
 			if err != nil {
 				return fmt.Errorf("VirtualNode %s update error %w", name, err)
 			}
 			ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Infof("VirtualNode %s updated", virtualnode.GetName())
+			if drifted {
+				return repairedDrift("VirtualNode", virtualnode)
+			}
 		}
 	}
 
@@ -231,7 +242,7 @@ func (ar *AppMeshRouter) reconcileVirtualService(canary *flaggerv1.Canary, name
 	}
 
 	// A/B testing - header based routing
-	if len(canary.GetAnalysis().Match) > 0 && canaryWeight == 0 {
+	if len(canary.GetAnalysisMatch()) > 0 && canaryWeight == 0 {
 		routes = []appmeshv1.Route{
 			{
 				Name:     fmt.Sprintf("%s-a", apexName),
@@ -320,6 +331,7 @@ func (ar *AppMeshRouter) reconcileVirtualService(canary *flaggerv1.Canary, name
 			}
 		}
 
+		virtualService.Annotations = withOwnership(virtualService.Annotations, canary, vsSpec)
 		_, err = ar.appmeshClient.AppmeshV1beta1().VirtualServices(canary.Namespace).Create(context.TODO(), virtualService, metav1.CreateOptions{})
 		if err != nil {
 			return fmt.Errorf("VirtualService %s create error %w", name, err)
@@ -334,6 +346,10 @@ func (ar *AppMeshRouter) reconcileVirtualService(canary *flaggerv1.Canary, name
 	// update virtual service but keep the original target weights
 	if virtualService != nil {
 		if diff := cmp.Diff(vsSpec, virtualService.Spec, cmpopts.IgnoreTypes(appmeshv1.WeightedTarget{})); diff != "" {
+			drifted, err := checkDrift(canary, "VirtualService", virtualService, vsSpec)
+			if err != nil {
+				return err
+			}
 			vsClone := virtualService.DeepCopy()
 			vsClone.Spec = vsSpec
 			vsClone.Spec.Routes[0].Http.Action = virtualService.Spec.Routes[0].Http.Action
@@ -346,12 +362,17 @@ func (ar *AppMeshRouter) reconcileVirtualService(canary *flaggerv1.Canary, name
 				}
 			}
 
+			vsClone.Annotations = withOwnership(vsClone.Annotations, canary, vsSpec)
+
 			_, err = ar.appmeshClient.AppmeshV1beta1().VirtualServices(canary.Namespace).Update(context.TODO(), vsClone, metav1.UpdateOptions{})
 			if err != nil {
 				return fmt.Errorf("VirtualService %s update error: %w", name, err)
 			}
 			ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Infof("VirtualService %s updated", virtualService.GetName())
+			if drifted {
+				return repairedDrift("VirtualService", virtualService)
+			}
 		}
 	}
 
@@ -469,10 +490,10 @@ func makeRetryPolicy(canary *flaggerv1.Canary) *appmeshv1.HttpRetryPolicy {
 func (ar *AppMeshRouter) makeHeaders(canary *flaggerv1.Canary) []appmeshv1.HttpRouteHeader {
 
 	var headers []appmeshv1.HttpRouteHeader
-	for _, m := range canary.GetAnalysis().Match {
+	for _, m := range canary.GetAnalysisMatch() {
 		for key, value := range m.Headers {
 			header := appmeshv1.HttpRouteHeader{
-				Name: key,
+				Name: headerName(canary, key),
 				Match: &appmeshv1.HeaderMatchMethod{
 					Exact:  stringp(value.Exact),
 					Prefix: stringp(value.Prefix),
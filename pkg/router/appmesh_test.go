@@ -111,7 +111,9 @@ func TestAppmeshRouter_Reconcile(t *testing.T) {
 
 	// apply change
 	err = router.Reconcile(canary)
-	require.NoError(t, err)
+	var drift *DriftError
+	require.ErrorAs(t, err, &drift)
+	assert.True(t, drift.Repaired)
 	vs, err = router.appmeshClient.AppmeshV1beta1().VirtualServices("default").Get(context.TODO(), vsName, metav1.GetOptions{})
 	require.NoError(t, err)
 	assert.Equal(t, "/", vs.Spec.Routes[0].Http.Match.Prefix)
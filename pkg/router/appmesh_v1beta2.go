@@ -59,35 +59,45 @@ func (ar *AppMeshv1beta2Router) Reconcile(canary *flaggerv1.Canary) error {
 	//	return fmt.Errorf("reconcileVirtualNode failed: %w", err)
 	//}
 
+	var drift driftCollector
+
 	// sync virtual node e.g. app-primary-namespace
 	// DNS app-primary.namespace
-	err := ar.reconcileVirtualNode(canary, primaryName, fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name), primaryHost)
+	err := drift.check(ar.reconcileVirtualNode(canary, primaryName, fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name), primaryHost))
 	if err != nil {
 		return fmt.Errorf("reconcileVirtualNode failed: %w", err)
 	}
 
 	// sync virtual node e.g. app-canary-namespace
 	// DNS app-canary.namespace
-	err = ar.reconcileVirtualNode(canary, canaryName, canary.Spec.TargetRef.Name, canaryHost)
+	err = drift.check(ar.reconcileVirtualNode(canary, canaryName, canary.Spec.TargetRef.Name, canaryHost))
 	if err != nil {
 		return fmt.Errorf("reconcileVirtualNode failed: %w", err)
 	}
 
 	// sync main virtual router
 	// DNS app.namespace
-	err = ar.reconcileVirtualRouter(canary, apexName, 0)
+	err = drift.check(ar.reconcileVirtualRouter(canary, apexName, 0))
 	if err != nil {
 		return fmt.Errorf("reconcileVirtualService failed: %w", err)
 	}
 
 	// sync canary virtual router
 	// DNS app-canary.namespace
-	err = ar.reconcileVirtualRouter(canary, canaryName, 100)
+	err = drift.check(ar.reconcileVirtualRouter(canary, canaryName, 100))
 	if err != nil {
 		return fmt.Errorf("reconcileVirtualRouter failed: %w", err)
 	}
 
-	return nil
+	// sync the virtual gateway route of the main virtual service
+	if canary.Spec.Service.GatewayRoute != nil {
+		err = drift.check(ar.reconcileGatewayRoute(canary, apexName))
+		if err != nil {
+			return fmt.Errorf("reconcileGatewayRoute failed: %w", err)
+		}
+	}
+
+	return drift.err()
 }
 
 // reconcileVirtualNode creates or updates a virtual node
@@ -172,6 +182,7 @@ func (ar *AppMeshv1beta2Router) reconcileVirtualNode(canary *flaggerv1.Canary, n
 			},
 			Spec: vnSpec,
 		}
+		virtualnode.Annotations = withOwnership(virtualnode.Annotations, canary, vnSpec)
 		_, err = ar.appmeshClient.AppmeshV1beta2().VirtualNodes(canary.Namespace).Create(context.TODO(), virtualnode, metav1.CreateOptions{})
 		if err != nil {
 			return fmt.Errorf("VirtualNode %s.%s create error %w", name, canary.Namespace, err)
@@ -187,8 +198,13 @@ func (ar *AppMeshv1beta2Router) reconcileVirtualNode(canary *flaggerv1.Canary, n
 	if virtualnode != nil {
 		if diff := cmp.Diff(vnSpec, virtualnode.Spec,
 			cmpopts.IgnoreFields(appmeshv1.VirtualNodeSpec{}, "AWSName", "MeshRef")); diff != "" {
+			drifted, err := checkDrift(canary, "VirtualNode", virtualnode, vnSpec)
+			if err != nil {
+				return err
+			}
 			vnClone := virtualnode.DeepCopy()
 			vnClone.Spec = vnSpec
+			vnClone.Annotations = withOwnership(vnClone.Annotations, canary, vnSpec)
 			vnClone.Spec.AWSName = virtualnode.Spec.AWSName
 			vnClone.Spec.MeshRef = virtualnode.Spec.MeshRef
 			_, err = ar.appmeshClient.AppmeshV1beta2().VirtualNodes(canary.Namespace).Update(context.TODO(), vnClone, metav1.UpdateOptions{})
@@ -197,6 +213,9 @@ func (ar *AppMeshv1beta2Router) reconcileVirtualNode(canary *flaggerv1.Canary, n
 			}
 			ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Infof("VirtualNode %s updated", virtualnode.GetName())
+			if drifted {
+				return repairedDrift("VirtualNode", virtualnode)
+			}
 		}
 	}
 
@@ -254,7 +273,7 @@ func (ar *AppMeshv1beta2Router) reconcileVirtualRouter(canary *flaggerv1.Canary,
 	}
 
 	// A/B testing - header based routing
-	if len(canary.GetAnalysis().Match) > 0 && canaryWeight == 0 {
+	if len(canary.GetAnalysisMatch()) > 0 && canaryWeight == 0 {
 		routes = []appmeshv1.Route{
 			{
 				Name:     fmt.Sprintf("%s-a", apexName),
@@ -339,6 +358,7 @@ func (ar *AppMeshv1beta2Router) reconcileVirtualRouter(canary *flaggerv1.Canary,
 			Spec: vrSpec,
 		}
 
+		virtualRouter.Annotations = withOwnership(virtualRouter.Annotations, canary, vrSpec)
 		_, err = ar.appmeshClient.AppmeshV1beta2().VirtualRouters(canary.Namespace).Create(context.TODO(), virtualRouter, metav1.CreateOptions{})
 		if err != nil {
 			return fmt.Errorf("VirtualRouter %s create error %w", name, err)
@@ -394,8 +414,13 @@ func (ar *AppMeshv1beta2Router) reconcileVirtualRouter(canary *flaggerv1.Canary,
 		if diff := cmp.Diff(vrSpec, virtualRouter.Spec,
 			cmpopts.IgnoreFields(appmeshv1.VirtualRouterSpec{}, "AWSName", "MeshRef"),
 			cmpopts.IgnoreTypes(appmeshv1.WeightedTarget{}, appmeshv1.MeshReference{})); diff != "" {
+			drifted, err := checkDrift(canary, "VirtualRouter", virtualRouter, vrSpec)
+			if err != nil {
+				return err
+			}
 			vrClone := virtualRouter.DeepCopy()
 			vrClone.Spec = vrSpec
+			vrClone.Annotations = withOwnership(vrClone.Annotations, canary, vrSpec)
 			vrClone.Spec.Routes[0].HTTPRoute.Action = virtualRouter.Spec.Routes[0].HTTPRoute.Action
 			vrClone.Spec.AWSName = virtualRouter.Spec.AWSName
 			vrClone.Spec.MeshRef = virtualRouter.Spec.MeshRef
@@ -405,6 +430,104 @@ func (ar *AppMeshv1beta2Router) reconcileVirtualRouter(canary *flaggerv1.Canary,
 			}
 			ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Infof("VirtualRouter %s updated", virtualRouter.GetName())
+			if drifted {
+				return repairedDrift("VirtualRouter", virtualRouter)
+			}
+		}
+	}
+
+	return nil
+}
+
+// reconcileGatewayRoute creates or updates the gateway route that sends the virtual gateway
+// traffic to the main virtual service, the edge traffic is split by the virtual router weights
+func (ar *AppMeshv1beta2Router) reconcileGatewayRoute(canary *flaggerv1.Canary, name string) error {
+	gr := canary.Spec.Service.GatewayRoute
+	target := appmeshv1.GatewayRouteTarget{
+		VirtualService: appmeshv1.GatewayRouteVirtualService{
+			VirtualServiceRef: &appmeshv1.VirtualServiceReference{
+				Name: name,
+			},
+		},
+	}
+	var hostname *appmeshv1.GatewayRouteHostnameMatch
+	if gr.Hostname != "" {
+		hostname = &appmeshv1.GatewayRouteHostnameMatch{Exact: &gr.Hostname}
+	}
+
+	grSpec := appmeshv1.GatewayRouteSpec{
+		Priority: gr.Priority,
+	}
+	if ar.getProtocol(canary) == appmeshv1.PortProtocolGRPC {
+		if hostname == nil {
+			return fmt.Errorf("GatewayRoute %s.%s requires a hostname for gRPC services", name, canary.Namespace)
+		}
+		grSpec.GRPCRoute = &appmeshv1.GRPCGatewayRoute{
+			Match:  appmeshv1.GRPCGatewayRouteMatch{Hostname: hostname},
+			Action: appmeshv1.GRPCGatewayRouteAction{Target: target},
+		}
+	} else {
+		prefix := "/"
+		if gr.Prefix != "" {
+			prefix = gr.Prefix
+		}
+		grSpec.HTTPRoute = &appmeshv1.HTTPGatewayRoute{
+			Match:  appmeshv1.HTTPGatewayRouteMatch{Prefix: &prefix, Hostname: hostname},
+			Action: appmeshv1.HTTPGatewayRouteAction{Target: target},
+		}
+	}
+
+	gatewayRoute, err := ar.appmeshClient.AppmeshV1beta2().GatewayRoutes(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+
+	// create gateway route
+	if errors.IsNotFound(err) {
+		gatewayRoute = &appmeshv1.GatewayRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: grSpec,
+		}
+		gatewayRoute.Annotations = withOwnership(gatewayRoute.Annotations, canary, grSpec)
+		_, err = ar.appmeshClient.AppmeshV1beta2().GatewayRoutes(canary.Namespace).Create(context.TODO(), gatewayRoute, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("GatewayRoute %s.%s create error %w", name, canary.Namespace, err)
+		}
+		ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("GatewayRoute %s.%s created", gatewayRoute.GetName(), canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("GatewayRoute %s get query error %w", name, err)
+	}
+
+	// update gateway route
+	if diff := cmp.Diff(grSpec, gatewayRoute.Spec,
+		cmpopts.IgnoreFields(appmeshv1.GatewayRouteSpec{}, "AWSName", "VirtualGatewayRef", "MeshRef")); diff != "" {
+		drifted, err := checkDrift(canary, "GatewayRoute", gatewayRoute, grSpec)
+		if err != nil {
+			return err
+		}
+		grClone := gatewayRoute.DeepCopy()
+		grClone.Spec = grSpec
+		grClone.Annotations = withOwnership(grClone.Annotations, canary, grSpec)
+		grClone.Spec.AWSName = gatewayRoute.Spec.AWSName
+		grClone.Spec.VirtualGatewayRef = gatewayRoute.Spec.VirtualGatewayRef
+		grClone.Spec.MeshRef = gatewayRoute.Spec.MeshRef
+		_, err = ar.appmeshClient.AppmeshV1beta2().GatewayRoutes(canary.Namespace).Update(context.TODO(), grClone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("GatewayRoute %s update error %w", name, err)
+		}
+		ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("GatewayRoute %s updated", gatewayRoute.GetName())
+		if drifted {
+			return repairedDrift("GatewayRoute", gatewayRoute)
 		}
 	}
 
@@ -559,10 +682,10 @@ func (ar *AppMeshv1beta2Router) makeRetryPolicy(canary *flaggerv1.Canary) *appme
 func (ar *AppMeshv1beta2Router) makeHeaders(canary *flaggerv1.Canary) []appmeshv1.HTTPRouteHeader {
 
 	var headers []appmeshv1.HTTPRouteHeader
-	for _, m := range canary.GetAnalysis().Match {
+	for _, m := range canary.GetAnalysisMatch() {
 		for key, value := range m.Headers {
 			header := appmeshv1.HTTPRouteHeader{
-				Name: key,
+				Name: headerName(canary, key),
 				Match: &appmeshv1.HeaderMatchMethod{
 					Exact:  stringp(value.Exact),
 					Prefix: stringp(value.Prefix),
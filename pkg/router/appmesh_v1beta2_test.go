@@ -27,6 +27,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 )
 
 func TestAppmeshv1beta2Router_Reconcile(t *testing.T) {
@@ -104,7 +106,9 @@ func TestAppmeshv1beta2Router_Reconcile(t *testing.T) {
 
 	// undo URI change
 	err = router.Reconcile(canary)
-	require.NoError(t, err)
+	var drift *DriftError
+	require.ErrorAs(t, err, &drift)
+	assert.True(t, drift.Repaired)
 	vrApex, err = router.appmeshClient.AppmeshV1beta2().VirtualRouters("default").Get(context.TODO(), apexName, metav1.GetOptions{})
 	require.NoError(t, err)
 	assert.Equal(t, "/", vrApex.Spec.Routes[0].HTTPRoute.Match.Prefix)
@@ -181,3 +185,48 @@ func TestAppmeshv1beta2Router_Gateway(t *testing.T) {
 	retries := vs.Annotations["gateway.appmesh.k8s.aws/retries"]
 	assert.Equal(t, strconv.Itoa(mocks.appmeshCanary.Spec.Service.Retries.Attempts), retries)
 }
+
+func TestAppmeshv1beta2Router_GatewayRoute(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &AppMeshv1beta2Router{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		appmeshClient: mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	canary := mocks.appmeshCanary.DeepCopy()
+	canary.Spec.Service.GatewayRoute = &flaggerv1.CanaryGatewayRoute{
+		Prefix:   "/podinfo",
+		Hostname: "app.example.com",
+	}
+	apexName, _, _ := canary.GetServiceNames()
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	gr, err := router.appmeshClient.AppmeshV1beta2().GatewayRoutes("default").Get(context.TODO(), apexName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, gr.Spec.HTTPRoute)
+	assert.Equal(t, "/podinfo", *gr.Spec.HTTPRoute.Match.Prefix)
+	assert.Equal(t, "app.example.com", *gr.Spec.HTTPRoute.Match.Hostname.Exact)
+	assert.Equal(t, apexName, gr.Spec.HTTPRoute.Action.Target.VirtualService.VirtualServiceRef.Name)
+
+	// update the prefix
+	canary.Spec.Service.GatewayRoute.Prefix = "/"
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	gr, err = router.appmeshClient.AppmeshV1beta2().GatewayRoutes("default").Get(context.TODO(), apexName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "/", *gr.Spec.HTTPRoute.Match.Prefix)
+
+	// gRPC routes require a hostname
+	grpcCanary := mocks.appmeshCanary.DeepCopy()
+	grpcCanary.Name = "grpc"
+	grpcCanary.Spec.TargetRef.Name = "grpc"
+	grpcCanary.Spec.Service.PortName = "grpc"
+	grpcCanary.Spec.Service.GatewayRoute = &flaggerv1.CanaryGatewayRoute{}
+	err = router.Reconcile(grpcCanary)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires a hostname")
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"fmt"
+	"strings"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+)
+
+// Capabilities lists the routing features implemented by a provider router
+type Capabilities struct {
+	// Mirroring of the primary traffic to the canary
+	Mirroring bool
+	// HeaderMatch routes the A/B testing traffic based on HTTP headers and cookies
+	HeaderMatch bool
+	// RegexMatch routes the A/B testing traffic based on header regular expressions
+	RegexMatch bool
+	// MethodMatch routes the A/B testing traffic based on the HTTP method
+	MethodMatch bool
+	// RequestMatch routes the A/B testing traffic based on all the
+	// request attributes, e.g. URI, query parameters and source labels
+	RequestMatch bool
+	// CanaryHeaders sets headers on the requests and responses routed to the canary
+	CanaryHeaders bool
+	// HeaderAppend appends values to the request headers, e.g. to the W3C baggage
+	HeaderAppend bool
+	// MultiPort shifts the traffic of all the service ports, routers that
+	// route a single port of the service can't honor the declared ports
+	MultiPort bool
+	// SessionAffinity pins the clients routed to the canary with a cookie
+	SessionAffinity bool
+	// GeoRoutes routes each country of the geo match with an exact header match
+	// instead of a regular expression
+	GeoRoutes bool
+}
+
+// GetCapabilities returns the routing features implemented for the provider
+func GetCapabilities(provider string) Capabilities {
+	switch {
+	case provider == flaggerv1.IstioProvider || provider == "":
+		return Capabilities{Mirroring: true, HeaderMatch: true, RegexMatch: true, MethodMatch: true, RequestMatch: true,
+			CanaryHeaders: true, HeaderAppend: true, MultiPort: true, SessionAffinity: true}
+	case strings.HasPrefix(provider, flaggerv1.GatewayProvider):
+		return Capabilities{Mirroring: true, HeaderMatch: true, RegexMatch: true, MethodMatch: true}
+	case strings.HasPrefix(provider, flaggerv1.GlooProvider):
+		return Capabilities{HeaderMatch: true, RegexMatch: true, MethodMatch: true}
+	case strings.HasPrefix(provider, flaggerv1.AppMeshProvider),
+		provider == flaggerv1.NGINXProvider:
+		return Capabilities{HeaderMatch: true, RegexMatch: true}
+	case provider == flaggerv1.ContourProvider:
+		// HTTPProxy header conditions can't match regular expressions
+		return Capabilities{HeaderMatch: true, CanaryHeaders: true, GeoRoutes: true}
+	case provider == flaggerv1.KubernetesProvider,
+		provider == flaggerv1.LinkerdProvider,
+		provider == flaggerv1.OsmProvider,
+		provider == flaggerv1.KumaProvider,
+		provider == flaggerv1.ConsulProvider,
+		strings.HasPrefix(provider, flaggerv1.SMIProvider):
+		// the traffic is split per Kubernetes service, regardless of the port
+		return Capabilities{MultiPort: true}
+	default:
+		return Capabilities{}
+	}
+}
+
+// ValidateCapabilities returns an error if the canary analysis
+// uses a routing feature that is not implemented for the provider
+func ValidateCapabilities(provider string, canary *flaggerv1.Canary) error {
+	if canary.GetAnalysis() == nil {
+		return nil
+	}
+	capabilities := GetCapabilities(provider)
+	if provider == "" {
+		provider = flaggerv1.IstioProvider
+	}
+
+	// the builtin metrics are computed from the requests routed to the canary
+	if provider == flaggerv1.KedaProvider {
+		for _, metric := range canary.GetAnalysis().Metrics {
+			if metric.TemplateRef == nil && (metric.Name == "request-success-rate" || metric.Name == "request-duration") {
+				return fmt.Errorf("the builtin %s metric is not supported by the %s provider", metric.Name, provider)
+			}
+		}
+	}
+
+	if migration := canary.Spec.Service.GatewayAPIMigration; migration != nil {
+		if !IsSMIProvider(migration.SMIProvider) {
+			return fmt.Errorf("the Gateway API migration requires an SMI provider, got %s", migration.SMIProvider)
+		}
+		if provider != migration.SMIProvider && !strings.HasPrefix(provider, flaggerv1.GatewayProvider) {
+			return fmt.Errorf("the Gateway API migration from %s is not supported by the %s provider", migration.SMIProvider, provider)
+		}
+	}
+
+	if canary.IsTCP() {
+		if provider != flaggerv1.IstioProvider {
+			return fmt.Errorf("TCP routing is not supported by the %s provider", provider)
+		}
+		if canary.GetAnalysis().Mirror || len(canary.GetAnalysisMatch()) > 0 || len(canary.GetAnalysis().SourceWeights) > 0 {
+			return fmt.Errorf("traffic mirroring, A/B testing and source weights are not supported for TCP routes")
+		}
+		// the builtin metrics are computed from the HTTP requests
+		for _, metric := range canary.GetAnalysis().Metrics {
+			if metric.TemplateRef == nil && (metric.Name == "request-success-rate" || metric.Name == "request-duration") {
+				return fmt.Errorf("the builtin %s metric is not supported for TCP routes", metric.Name)
+			}
+		}
+	}
+
+	for _, g := range canary.GetAnalysis().GRPCMatch {
+		if g.Service == "" && len(g.Metadata) == 0 {
+			return fmt.Errorf("A/B testing gRPC match requires a service or metadata")
+		}
+		if g.Method != "" && g.Service == "" {
+			return fmt.Errorf("A/B testing gRPC match on method %s requires a service", g.Method)
+		}
+	}
+
+	if h := canary.Spec.Service.CanaryHeaders; h != nil {
+		if !capabilities.CanaryHeaders {
+			return fmt.Errorf("canary headers are not supported by the %s provider", provider)
+		}
+		if h.Baggage && !capabilities.HeaderAppend {
+			return fmt.Errorf("canary baggage is not supported by the %s provider", provider)
+		}
+	}
+
+	if len(canary.Spec.Service.Ports) > 0 {
+		if !capabilities.MultiPort {
+			return fmt.Errorf("service ports are not supported by the %s provider", provider)
+		}
+		names := map[string]bool{canary.Spec.Service.PortName: true}
+		numbers := map[int32]bool{canary.Spec.Service.Port: true}
+		for _, p := range canary.Spec.Service.Ports {
+			if p.Name == "" || names[p.Name] || numbers[p.Port] {
+				return fmt.Errorf("service port %s %v must have a unique name and number", p.Name, p.Port)
+			}
+			names[p.Name] = true
+			numbers[p.Port] = true
+		}
+	}
+
+	if canary.GetAnalysis().SessionAffinity != nil {
+		if !capabilities.SessionAffinity {
+			return fmt.Errorf("session affinity is not supported by the %s provider", provider)
+		}
+		if canary.IsTCP() {
+			return fmt.Errorf("session affinity is not supported for TCP routes")
+		}
+	}
+
+	if canary.GetAnalysis().Mirror && !capabilities.Mirroring {
+		return fmt.Errorf("traffic mirroring is not supported by the %s provider", provider)
+	}
+	if mw := canary.GetAnalysis().MirrorWeight; mw < 0 || mw > 100 {
+		return fmt.Errorf("mirror weight %v is not in the range [0, 100]", mw)
+	}
+
+	if len(canary.GetAnalysisMatch()) > 0 {
+		if !capabilities.HeaderMatch {
+			return fmt.Errorf("A/B testing is not supported by the %s provider", provider)
+		}
+		if !capabilities.RegexMatch {
+			for _, m := range canary.GetAnalysisMatch() {
+				for name, h := range m.Headers {
+					if h.Regex != "" && !(capabilities.GeoRoutes && name == canary.GetGeoHeader()) {
+						return fmt.Errorf("A/B testing match on %s header regex is not supported by the %s provider", name, provider)
+					}
+				}
+			}
+		}
+		// the NGINX canary-by-cookie annotation holds the name of the cookie set to always
+		if provider == flaggerv1.NGINXProvider {
+			for _, m := range canary.GetAnalysisMatch() {
+				if h, ok := m.Headers["cookie"]; ok && h.Exact == "" {
+					return fmt.Errorf("A/B testing match on cookie requires the exact cookie name for the %s provider", provider)
+				}
+			}
+		}
+		if !capabilities.RequestMatch {
+			for _, m := range canary.GetAnalysisMatch() {
+				if field := requestMatchField(m, capabilities); field != "" {
+					return fmt.Errorf("A/B testing match on %s is not supported by the %s provider", field, provider)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// requestMatchField returns the first match field that the provider can't route on
+func requestMatchField(m istiov1alpha3.HTTPMatchRequest, capabilities Capabilities) string {
+	switch {
+	case m.Uri != nil:
+		return "uri"
+	case m.Scheme != nil:
+		return "scheme"
+	case m.Method != nil && !capabilities.MethodMatch:
+		return "method"
+	case m.Authority != nil:
+		return "authority"
+	case len(m.QueryParams) > 0:
+		return "queryParams"
+	case len(m.SourceLabels) > 0:
+		return "sourceLabels"
+	case m.Port != 0:
+		return "port"
+	case len(m.Gateways) > 0:
+		return "gateways"
+	case len(m.WithoutHeaders) > 0:
+		return "withoutHeaders"
+	case m.SourceNamespace != "":
+		return "sourceNamespace"
+	}
+	return ""
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+)
+
+func TestValidateCapabilities(t *testing.T) {
+	headerMatch := []istiov1alpha3.HTTPMatchRequest{
+		{Headers: map[string]istiov1alpha1.StringMatch{"x-canary": {Exact: "insider"}}},
+	}
+	uriMatch := []istiov1alpha3.HTTPMatchRequest{
+		{Uri: &istiov1alpha1.StringMatch{Prefix: "/api"}},
+	}
+	regexMatch := []istiov1alpha3.HTTPMatchRequest{
+		{Headers: map[string]istiov1alpha1.StringMatch{"cookie": {Regex: "^(.*?;)?(canary=always)(;.*)?$"}}},
+	}
+	cookieMatch := []istiov1alpha3.HTTPMatchRequest{
+		{Headers: map[string]istiov1alpha1.StringMatch{"cookie": {Exact: "canary"}}},
+	}
+	methodMatch := []istiov1alpha3.HTTPMatchRequest{
+		{Method: &istiov1alpha1.StringMatch{Exact: "GET"}},
+	}
+
+	builtinMetrics := []flaggerv1.CanaryMetric{{Name: "request-success-rate"}}
+	templateMetrics := []flaggerv1.CanaryMetric{
+		{Name: "request-success-rate", TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: "lag"}},
+	}
+
+	tests := []struct {
+		provider string
+		mirror   bool
+		match    []istiov1alpha3.HTTPMatchRequest
+		metrics  []flaggerv1.CanaryMetric
+		err      string
+	}{
+		{provider: flaggerv1.IstioProvider, mirror: true, match: uriMatch},
+		{provider: flaggerv1.LinkerdProvider, mirror: true, err: "traffic mirroring is not supported by the linkerd provider"},
+		{provider: flaggerv1.KubernetesProvider, match: headerMatch, err: "A/B testing is not supported by the kubernetes provider"},
+		{provider: flaggerv1.NGINXProvider, match: headerMatch},
+		{provider: flaggerv1.NGINXProvider, match: uriMatch, err: "A/B testing match on uri is not supported by the nginx provider"},
+		{provider: flaggerv1.ContourProvider, match: methodMatch, err: "A/B testing match on method is not supported by the contour provider"},
+		{provider: flaggerv1.ContourProvider, match: headerMatch},
+		{provider: flaggerv1.ContourProvider, match: regexMatch, err: "A/B testing match on cookie header regex is not supported by the contour provider"},
+		{provider: flaggerv1.NGINXProvider, match: regexMatch, err: "A/B testing match on cookie requires the exact cookie name for the nginx provider"},
+		{provider: flaggerv1.NGINXProvider, match: cookieMatch},
+		{provider: flaggerv1.GlooProvider, match: methodMatch},
+		{provider: flaggerv1.GatewayProvider, match: methodMatch},
+		{provider: flaggerv1.GatewayProvider, mirror: true},
+		{provider: flaggerv1.GlooProvider, mirror: true, err: "traffic mirroring is not supported by the gloo provider"},
+		{provider: flaggerv1.AppMeshProvider + ":v1beta2", match: headerMatch},
+		{provider: flaggerv1.KedaProvider, metrics: templateMetrics},
+		{provider: flaggerv1.KedaProvider, metrics: builtinMetrics, err: "the builtin request-success-rate metric is not supported by the keda provider"},
+	}
+	tcp := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Service:  flaggerv1.CanaryService{Protocol: &flaggerv1.CanaryProtocol{Type: "tcp"}},
+			Analysis: &flaggerv1.CanaryAnalysis{Metrics: templateMetrics},
+		},
+	}
+	assert.NoError(t, ValidateCapabilities(flaggerv1.IstioProvider, tcp))
+	assert.EqualError(t, ValidateCapabilities(flaggerv1.LinkerdProvider, tcp), "TCP routing is not supported by the linkerd provider")
+	tcp.Spec.Analysis.Metrics = builtinMetrics
+	assert.EqualError(t, ValidateCapabilities(flaggerv1.IstioProvider, tcp), "the builtin request-success-rate metric is not supported for TCP routes")
+
+	mirror := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{Mirror: true, MirrorWeight: 10},
+		},
+	}
+	assert.NoError(t, ValidateCapabilities(flaggerv1.IstioProvider, mirror))
+	mirror.Spec.Analysis.MirrorWeight = 150
+	assert.EqualError(t, ValidateCapabilities(flaggerv1.IstioProvider, mirror), "mirror weight 150 is not in the range [0, 100]")
+
+	grpc := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{
+				GRPCMatch: []flaggerv1.CanaryGRPCMatch{{Service: "podinfo.v1.Echo"}},
+			},
+		},
+	}
+	assert.NoError(t, ValidateCapabilities(flaggerv1.IstioProvider, grpc))
+	assert.EqualError(t, ValidateCapabilities(flaggerv1.ContourProvider, grpc), "A/B testing match on uri is not supported by the contour provider")
+	grpc.Spec.Analysis.GRPCMatch = []flaggerv1.CanaryGRPCMatch{{Method: "Say"}}
+	assert.EqualError(t, ValidateCapabilities(flaggerv1.IstioProvider, grpc), "A/B testing gRPC match requires a service or metadata")
+
+	headers := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Service:  flaggerv1.CanaryService{CanaryHeaders: &flaggerv1.CanaryHeaders{Request: true, Baggage: true}},
+			Analysis: &flaggerv1.CanaryAnalysis{},
+		},
+	}
+	assert.NoError(t, ValidateCapabilities(flaggerv1.IstioProvider, headers))
+	assert.EqualError(t, ValidateCapabilities(flaggerv1.ContourProvider, headers), "canary baggage is not supported by the contour provider")
+	assert.EqualError(t, ValidateCapabilities(flaggerv1.NGINXProvider, headers), "canary headers are not supported by the nginx provider")
+	headers.Spec.Service.CanaryHeaders.Baggage = false
+	assert.NoError(t, ValidateCapabilities(flaggerv1.ContourProvider, headers))
+
+	ports := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Service: flaggerv1.CanaryService{
+				Port:     9898,
+				PortName: "http",
+				Ports:    []flaggerv1.CanaryServicePort{{Name: "grpc", Port: 9999}},
+			},
+			Analysis: &flaggerv1.CanaryAnalysis{},
+		},
+	}
+	assert.NoError(t, ValidateCapabilities(flaggerv1.IstioProvider, ports))
+	assert.NoError(t, ValidateCapabilities(flaggerv1.LinkerdProvider, ports))
+	assert.EqualError(t, ValidateCapabilities(flaggerv1.ContourProvider, ports), "service ports are not supported by the contour provider")
+	ports.Spec.Service.Ports = append(ports.Spec.Service.Ports, flaggerv1.CanaryServicePort{Name: "http", Port: 8080})
+	assert.EqualError(t, ValidateCapabilities(flaggerv1.IstioProvider, ports), "service port http 8080 must have a unique name and number")
+
+	affinity := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{SessionAffinity: &flaggerv1.CanarySessionAffinity{}},
+		},
+	}
+	assert.NoError(t, ValidateCapabilities(flaggerv1.IstioProvider, affinity))
+	assert.EqualError(t, ValidateCapabilities(flaggerv1.NGINXProvider, affinity), "session affinity is not supported by the nginx provider")
+
+	migration := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Service:  flaggerv1.CanaryService{GatewayAPIMigration: &flaggerv1.CanaryGatewayAPIMigration{SMIProvider: "smi:v1alpha2:linkerd"}},
+			Analysis: &flaggerv1.CanaryAnalysis{},
+		},
+	}
+	assert.NoError(t, ValidateCapabilities("smi:v1alpha2:linkerd", migration))
+	assert.NoError(t, ValidateCapabilities(flaggerv1.GatewayProvider, migration))
+	assert.EqualError(t, ValidateCapabilities(flaggerv1.LinkerdProvider, migration), "the Gateway API migration from smi:v1alpha2:linkerd is not supported by the linkerd provider")
+	migration.Spec.Service.GatewayAPIMigration.SMIProvider = flaggerv1.IstioProvider
+	assert.EqualError(t, ValidateCapabilities(flaggerv1.GatewayProvider, migration), "the Gateway API migration requires an SMI provider, got istio")
+
+	geo := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{Geo: &flaggerv1.CanaryGeoMatch{Countries: []string{"NZ", "IE"}}},
+		},
+	}
+	assert.NoError(t, ValidateCapabilities(flaggerv1.ContourProvider, geo))
+	geo.Spec.Analysis.Match = regexMatch
+	assert.EqualError(t, ValidateCapabilities(flaggerv1.ContourProvider, geo), "A/B testing match on cookie header regex is not supported by the contour provider")
+
+	for _, tt := range tests {
+		canary := &flaggerv1.Canary{
+			Spec: flaggerv1.CanarySpec{
+				Analysis: &flaggerv1.CanaryAnalysis{Mirror: tt.mirror, Match: tt.match, Metrics: tt.metrics},
+			},
+		}
+		err := ValidateCapabilities(tt.provider, canary)
+		if tt.err == "" {
+			assert.NoError(t, err, tt.provider)
+		} else {
+			assert.EqualError(t, err, tt.err, tt.provider)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+)
+
+// ConformanceResult holds the outcome of a routing feature check
+type ConformanceResult struct {
+	Feature   string
+	Supported bool
+	Message   string
+}
+
+// CheckConformance runs the routing features used during the canary analysis
+// against the mesh router and reports which of them are honoured by the provider.
+// The canary must exist in the cluster since the routing objects are owned by it.
+func CheckConformance(kubeRouter KubernetesRouter, meshRouter Interface, canary *flaggerv1.Canary) ([]ConformanceResult, error) {
+	if err := kubeRouter.Initialize(canary); err != nil {
+		return nil, fmt.Errorf("services init failed: %w", err)
+	}
+	if err := kubeRouter.Reconcile(canary); err != nil {
+		return nil, fmt.Errorf("services reconcile failed: %w", err)
+	}
+	if err := meshRouter.Reconcile(canary); err != nil {
+		return nil, fmt.Errorf("routes reconcile failed: %w", err)
+	}
+
+	return []ConformanceResult{
+		checkWeights(meshRouter, canary),
+		checkMirroring(meshRouter, canary),
+		checkMatch(meshRouter, canary),
+		{
+			Feature: "session affinity",
+			Message: "not implemented by Flagger routers",
+		},
+	}, nil
+}
+
+func checkWeights(meshRouter Interface, canary *flaggerv1.Canary) ConformanceResult {
+	result := ConformanceResult{Feature: "weights"}
+	if err := meshRouter.SetRoutes(canary, 70, 30, false); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	primaryWeight, canaryWeight, _, err := meshRouter.GetRoutes(canary)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	if primaryWeight != 70 || canaryWeight != 30 {
+		result.Message = fmt.Sprintf("routes set to 70/30 but read back as %d/%d", primaryWeight, canaryWeight)
+		return result
+	}
+	result.Supported = true
+	return result
+}
+
+func checkMirroring(meshRouter Interface, canary *flaggerv1.Canary) ConformanceResult {
+	result := ConformanceResult{Feature: "mirroring"}
+	err := meshRouter.SetRoutes(canary, 100, 0, true)
+	defer meshRouter.SetRoutes(canary, 100, 0, false)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	_, _, mirrored, err := meshRouter.GetRoutes(canary)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	if !mirrored {
+		result.Message = "mirror route was not applied"
+		return result
+	}
+	result.Supported = true
+	return result
+}
+
+func checkMatch(meshRouter Interface, canary *flaggerv1.Canary) ConformanceResult {
+	result := ConformanceResult{Feature: "A/B testing"}
+	abtest := canary.DeepCopy()
+	abtest.GetAnalysis().Iterations = 1
+	abtest.GetAnalysis().Match = []istiov1alpha3.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"x-flagger-verify": {Exact: "true"},
+			},
+		},
+	}
+	defer meshRouter.Reconcile(canary)
+
+	if err := meshRouter.Reconcile(abtest); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	if err := meshRouter.SetRoutes(abtest, 0, 100, false); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	_, canaryWeight, _, err := meshRouter.GetRoutes(abtest)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	if canaryWeight != 100 {
+		result.Message = fmt.Sprintf("header match route reads back canary weight %d", canaryWeight)
+		return result
+	}
+	result.Supported = true
+	return result
+}
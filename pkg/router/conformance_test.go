@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckConformance_Istio(t *testing.T) {
+	mocks := newFixture(nil)
+	kubeRouter := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+	}
+	meshRouter := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	results, err := CheckConformance(kubeRouter, meshRouter, mocks.canary)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	supported := make(map[string]bool)
+	for _, r := range results {
+		supported[r.Feature] = r.Supported
+	}
+	assert.True(t, supported["weights"])
+	assert.True(t, supported["mirroring"])
+	assert.True(t, supported["A/B testing"])
+	assert.False(t, supported["session affinity"])
+
+	// the routes are left without mirroring and header matching
+	_, _, mirrored, err := meshRouter.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.False(t, mirrored)
+}
+
+func TestCheckConformance_Kubernetes(t *testing.T) {
+	mocks := newFixture(nil)
+	kubeRouter := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+	}
+
+	results, err := CheckConformance(kubeRouter, &NopRouter{}, mocks.canary)
+	require.NoError(t, err)
+	for _, r := range results {
+		assert.False(t, r.Supported, r.Feature)
+	}
+}
@@ -0,0 +1,299 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	consulv1alpha1 "github.com/fluxcd/flagger/pkg/apis/consul/v1alpha1"
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// ConsulRouter is managing Consul service splitters and resolvers
+type ConsulRouter struct {
+	consulClient clientset.Interface
+	logger       *zap.SugaredLogger
+}
+
+// Reconcile creates or updates the Consul service splitter of the apex service
+// and the service resolver of the canary service
+func (cr *ConsulRouter) Reconcile(canary *flaggerv1.Canary) error {
+	drift := &driftCollector{}
+
+	err := drift.check(cr.reconcileServiceResolver(canary))
+	if err != nil {
+		return err
+	}
+
+	err = drift.check(cr.reconcileServiceSplitter(canary))
+	if err != nil {
+		return err
+	}
+
+	return drift.err()
+}
+
+// reconcileServiceResolver fails over the canary service to the primary
+// when there are no healthy canary instances
+func (cr *ConsulRouter) reconcileServiceResolver(canary *flaggerv1.Canary) error {
+	_, primaryName, canaryName := canary.GetServiceNames()
+
+	newSpec := consulv1alpha1.ServiceResolverSpec{
+		Failover: map[string]consulv1alpha1.ServiceResolverFailover{
+			"*": {
+				Service: primaryName,
+			},
+		},
+	}
+
+	resolver, err := cr.consulClient.ConsulV1alpha1().ServiceResolvers(canary.Namespace).Get(context.TODO(), canaryName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		resolver = &consulv1alpha1.ServiceResolver{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        canaryName,
+				Namespace:   canary.Namespace,
+				Annotations: withOwnership(nil, canary, newSpec),
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: newSpec,
+		}
+
+		_, err = cr.consulClient.ConsulV1alpha1().ServiceResolvers(canary.Namespace).Create(context.TODO(), resolver, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("ServiceResolver %s.%s create error: %w", canaryName, canary.Namespace, err)
+		}
+		cr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ServiceResolver %s.%s created", canaryName, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("ServiceResolver %s.%s get query error: %w", canaryName, canary.Namespace, err)
+	}
+
+	if diff := cmp.Diff(newSpec, resolver.Spec, cmpopts.EquateEmpty()); diff != "" {
+		drifted, err := checkDrift(canary, "ServiceResolver", resolver, newSpec)
+		if err != nil {
+			return err
+		}
+		clone := resolver.DeepCopy()
+		clone.Spec = newSpec
+		clone.Annotations = withOwnership(clone.Annotations, canary, newSpec)
+
+		_, err = cr.consulClient.ConsulV1alpha1().ServiceResolvers(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("ServiceResolver %s.%s update error: %w", canaryName, canary.Namespace, err)
+		}
+		cr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ServiceResolver %s.%s updated", canaryName, canary.Namespace)
+		if drifted {
+			return repairedDrift("ServiceResolver", resolver)
+		}
+	}
+
+	return nil
+}
+
+// reconcileServiceSplitter splits the apex service traffic between the primary and canary services
+func (cr *ConsulRouter) reconcileServiceSplitter(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+	newSpec := cr.makeSplitterSpec(canary, 100, 0)
+
+	splitter, err := cr.consulClient.ConsulV1alpha1().ServiceSplitters(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		metadata := canary.Spec.Service.Apex
+		if metadata == nil {
+			metadata = &flaggerv1.CustomMetadata{}
+		}
+		if metadata.Labels == nil {
+			metadata.Labels = make(map[string]string)
+		}
+		if metadata.Annotations == nil {
+			metadata.Annotations = make(map[string]string)
+		}
+
+		splitter = &consulv1alpha1.ServiceSplitter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        apexName,
+				Namespace:   canary.Namespace,
+				Labels:      metadata.Labels,
+				Annotations: withOwnership(filterMetadata(metadata.Annotations), canary, newSpec),
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: newSpec,
+		}
+
+		_, err = cr.consulClient.ConsulV1alpha1().ServiceSplitters(canary.Namespace).Create(context.TODO(), splitter, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("ServiceSplitter %s.%s create error: %w", apexName, canary.Namespace, err)
+		}
+		cr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ServiceSplitter %s.%s created", apexName, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("ServiceSplitter %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	// update the splitter but keep the original weights
+	if diff := cmp.Diff(newSpec, splitter.Spec, cmpopts.IgnoreFields(consulv1alpha1.ServiceSplit{}, "Weight")); diff != "" {
+		drifted, err := checkDrift(canary, "ServiceSplitter", splitter, newSpec)
+		if err != nil {
+			return err
+		}
+		clone := splitter.DeepCopy()
+		clone.Spec = newSpec
+		clone.Annotations = withOwnership(clone.Annotations, canary, newSpec)
+
+		_, err = cr.consulClient.ConsulV1alpha1().ServiceSplitters(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("ServiceSplitter %s.%s update error: %w", apexName, canary.Namespace, err)
+		}
+		cr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ServiceSplitter %s.%s updated", apexName, canary.Namespace)
+		if drifted {
+			return repairedDrift("ServiceSplitter", splitter)
+		}
+	}
+
+	return nil
+}
+
+// GetRoutes returns the service splitter weights of the primary and canary services
+func (cr *ConsulRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	splitter, err := cr.consulClient.ConsulV1alpha1().ServiceSplitters(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("ServiceSplitter %s.%s get query error: %w", apexName, canary.Namespace, err)
+		return
+	}
+
+	for _, split := range splitter.Spec.Splits {
+		switch split.Service {
+		case primaryName:
+			primaryWeight = int(split.Weight)
+		case canaryName:
+			canaryWeight = int(split.Weight)
+		}
+	}
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		err = fmt.Errorf("ServiceSplitter %s.%s does not contain splits for %s and %s",
+			apexName, canary.Namespace, primaryName, canaryName)
+	}
+	return
+}
+
+// SetRoutes updates the service splitter weights of the primary and canary services
+func (cr *ConsulRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	apexName, _, _ := canary.GetServiceNames()
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		return fmt.Errorf("ServiceSplitter %s.%s update failed: no valid weights", apexName, canary.Namespace)
+	}
+
+	splitter, err := cr.consulClient.ConsulV1alpha1().ServiceSplitters(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ServiceSplitter %s.%s query error: %w", apexName, canary.Namespace, err)
+	}
+
+	clone := splitter.DeepCopy()
+	clone.Spec = cr.makeSplitterSpec(canary, primaryWeight, canaryWeight)
+
+	_, err = cr.consulClient.ConsulV1alpha1().ServiceSplitters(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("ServiceSplitter %s.%s update error: %w", apexName, canary.Namespace, err)
+	}
+	return nil
+}
+
+func (cr *ConsulRouter) Finalize(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+func (cr *ConsulRouter) makeSplitterSpec(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int) consulv1alpha1.ServiceSplitterSpec {
+	_, primaryName, canaryName := canary.GetServiceNames()
+	return consulv1alpha1.ServiceSplitterSpec{
+		Splits: []consulv1alpha1.ServiceSplit{
+			{
+				Weight:  float32(primaryWeight),
+				Service: primaryName,
+			},
+			{
+				Weight:  float32(canaryWeight),
+				Service: canaryName,
+			},
+		},
+	}
+}
+
+// Snapshot returns the current state of the ServiceSplitter
+func (cr *ConsulRouter) Snapshot(canary *flaggerv1.Canary) (*RoutingSnapshot, error) {
+	apexName, _, _ := canary.GetServiceNames()
+	obj, err := cr.consulClient.ConsulV1alpha1().ServiceSplitters(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("ServiceSplitter %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+	return newRoutingSnapshot("ServiceSplitter", obj)
+}
+
+// Restore sets the spec, labels and annotations of the ServiceSplitter to the snapshot state
+func (cr *ConsulRouter) Restore(canary *flaggerv1.Canary, snapshot *RoutingSnapshot) error {
+	saved := &consulv1alpha1.ServiceSplitter{}
+	return snapshot.restore("ServiceSplitter", saved, func() error {
+		obj, err := cr.consulClient.ConsulV1alpha1().ServiceSplitters(canary.Namespace).Get(context.TODO(), saved.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("ServiceSplitter %s.%s get query error: %w", saved.Name, canary.Namespace, err)
+		}
+		clone := obj.DeepCopy()
+		clone.Spec = saved.Spec
+		clone.Labels = saved.Labels
+		clone.Annotations = saved.Annotations
+		if _, err := cr.consulClient.ConsulV1alpha1().ServiceSplitters(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("ServiceSplitter %s.%s update error: %w", saved.Name, canary.Namespace, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConsulRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ConsulRouter{
+		consulClient: mocks.meshClient,
+		logger:       mocks.logger,
+	}
+
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	splitter, err := router.consulClient.ConsulV1alpha1().ServiceSplitters("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, splitter.Spec.Splits, 2)
+	assert.Equal(t, "podinfo-primary", splitter.Spec.Splits[0].Service)
+	assert.Equal(t, float32(100), splitter.Spec.Splits[0].Weight)
+	assert.Equal(t, "podinfo-canary", splitter.Spec.Splits[1].Service)
+	assert.Equal(t, float32(0), splitter.Spec.Splits[1].Weight)
+	assert.Equal(t, "podinfo.default", splitter.Annotations[ownerAnnotation])
+
+	resolver, err := router.consulClient.ConsulV1alpha1().ServiceResolvers("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", resolver.Spec.Failover["*"].Service)
+
+	// reconcile keeps the weights
+	require.NoError(t, router.SetRoutes(mocks.canary, 60, 40, false))
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	p, c, m, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+	assert.False(t, m)
+
+	// undo external changes
+	resolver.Spec.Failover = nil
+	_, err = router.consulClient.ConsulV1alpha1().ServiceResolvers("default").Update(context.TODO(), resolver, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	var drift *DriftError
+	require.ErrorAs(t, router.Reconcile(mocks.canary), &drift)
+	assert.Equal(t, []string{"ServiceResolver/podinfo-canary.default"}, drift.Objects)
+
+	resolver, err = router.consulClient.ConsulV1alpha1().ServiceResolvers("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", resolver.Spec.Failover["*"].Service)
+}
+
+func TestConsulRouter_SetRoutes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ConsulRouter{
+		consulClient: mocks.meshClient,
+		logger:       mocks.logger,
+	}
+
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	for _, tt := range []struct {
+		name    string
+		primary int
+		canary  int
+	}{
+		{name: "0%", primary: 100, canary: 0},
+		{name: "20%", primary: 80, canary: 20},
+		{name: "50%", primary: 50, canary: 50},
+		{name: "100%", primary: 0, canary: 100},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, router.SetRoutes(mocks.canary, tt.primary, tt.canary, false))
+
+			p, c, _, err := router.GetRoutes(mocks.canary)
+			require.NoError(t, err)
+			assert.Equal(t, tt.primary, p)
+			assert.Equal(t, tt.canary, c)
+		})
+	}
+
+	assert.Error(t, router.SetRoutes(mocks.canary, 0, 0, false))
+}
@@ -19,6 +19,7 @@ package router
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -84,7 +85,7 @@ func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
 		},
 	}
 
-	if len(canary.GetAnalysis().Match) > 0 {
+	if len(canary.GetAnalysisMatch()) > 0 {
 		newSpec = contourv1.HTTPProxySpec{
 			Routes: []contourv1.Route{
 				{
@@ -149,6 +150,10 @@ func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
 		}
 	}
 
+	cr.setGeoRoutes(canary, &newSpec)
+	cr.setProtocol(canary, &newSpec)
+	cr.setCanaryHeaders(canary, &newSpec)
+
 	proxy, err := cr.contourClient.ProjectcontourV1().HTTPProxies(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if errors.IsNotFound(err) {
 		metadata := canary.Spec.Service.Apex
@@ -189,6 +194,7 @@ func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
 			}
 		}
 
+		proxy.Annotations = withOwnership(proxy.Annotations, canary, newSpec)
 		_, err = cr.contourClient.ProjectcontourV1().HTTPProxies(canary.Namespace).Create(context.TODO(), proxy, metav1.CreateOptions{})
 		if err != nil {
 			return fmt.Errorf("HTTPProxy %s.%s create error: %w", apexName, canary.Namespace, err)
@@ -207,8 +213,13 @@ func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
 			proxy.Spec,
 			cmpopts.IgnoreFields(contourv1.Service{}, "Weight"),
 		); diff != "" {
+			drifted, err := checkDrift(canary, "HTTPProxy", proxy, newSpec)
+			if err != nil {
+				return err
+			}
 			clone := proxy.DeepCopy()
 			clone.Spec = newSpec
+			clone.Annotations = withOwnership(clone.Annotations, canary, newSpec)
 
 			_, err = cr.contourClient.ProjectcontourV1().HTTPProxies(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
 			if err != nil {
@@ -216,6 +227,9 @@ func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
 			}
 			cr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Infof("HTTPProxy %s.%s updated", proxy.GetName(), canary.Namespace)
+			if drifted {
+				return repairedDrift("HTTPProxy", proxy)
+			}
 		}
 	}
 
@@ -306,7 +320,7 @@ func (cr *ContourRouter) SetRoutes(
 		},
 	}
 
-	if len(canary.GetAnalysis().Match) > 0 {
+	if len(canary.GetAnalysisMatch()) > 0 {
 		proxy.Spec = contourv1.HTTPProxySpec{
 			Routes: []contourv1.Route{
 				{
@@ -371,6 +385,10 @@ func (cr *ContourRouter) SetRoutes(
 		}
 	}
 
+	cr.setGeoRoutes(canary, &proxy.Spec)
+	cr.setProtocol(canary, &proxy.Spec)
+	cr.setCanaryHeaders(canary, &proxy.Spec)
+
 	_, err = cr.contourClient.ProjectcontourV1().HTTPProxies(canary.Namespace).Update(context.TODO(), proxy, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("HTTPProxy %s.%s update error: %w", apexName, canary.Namespace, err)
@@ -378,6 +396,89 @@ func (cr *ContourRouter) SetRoutes(
 	return nil
 }
 
+// setGeoRoutes expands the A/B testing route into one route per country, HTTPProxy
+// header conditions can't match regular expressions and the conditions of a route are ANDed
+func (cr *ContourRouter) setGeoRoutes(canary *flaggerv1.Canary, spec *contourv1.HTTPProxySpec) {
+	geo := canary.GetAnalysis().Geo
+	if geo == nil || len(geo.Countries) == 0 || len(spec.Routes) == 0 {
+		return
+	}
+	header := headerName(canary, canary.GetGeoHeader())
+	routes := make([]contourv1.Route, 0, len(geo.Countries)+len(spec.Routes)-1)
+	for _, country := range geo.Countries {
+		route := *spec.Routes[0].DeepCopy()
+		for i := range route.Conditions {
+			if h := route.Conditions[i].Header; h != nil && h.Name == header {
+				route.Conditions[i].Header = &contourv1.HeaderCondition{Name: header, Exact: country}
+			}
+		}
+		routes = append(routes, route)
+	}
+	spec.Routes = append(routes, spec.Routes[1:]...)
+}
+
+// setProtocol sets the upstream protocol and the insecure access on the generated routes
+func (cr *ContourRouter) setProtocol(canary *flaggerv1.Canary, spec *contourv1.HTTPProxySpec) {
+	protocol := canary.Spec.Service.Protocol
+	upstream := canary.GetUpstreamProtocol()
+	if protocol == nil && upstream == "" {
+		return
+	}
+	for i := range spec.Routes {
+		if protocol != nil {
+			spec.Routes[i].PermitInsecure = protocol.PermitInsecure
+		}
+		if upstream == "" {
+			continue
+		}
+		for j := range spec.Routes[i].Services {
+			upstream := upstream
+			spec.Routes[i].Services[j].Protocol = &upstream
+		}
+	}
+}
+
+// setCanaryHeaders marks the requests routed to the canary service
+func (cr *ContourRouter) setCanaryHeaders(canary *flaggerv1.Canary, spec *contourv1.HTTPProxySpec) {
+	request, response, _ := canary.GetCanaryHeaders()
+	if request == nil && response == nil {
+		return
+	}
+	_, _, canaryName := canary.GetServiceNames()
+	for i := range spec.Routes {
+		for j := range spec.Routes[i].Services {
+			svc := &spec.Routes[i].Services[j]
+			if svc.Name != canaryName {
+				continue
+			}
+			if request != nil {
+				if svc.RequestHeadersPolicy == nil {
+					svc.RequestHeadersPolicy = &contourv1.HeadersPolicy{}
+				}
+				svc.RequestHeadersPolicy.Set = append(svc.RequestHeadersPolicy.Set, makeHeaderValues(request)...)
+			}
+			if response != nil {
+				svc.ResponseHeadersPolicy = &contourv1.HeadersPolicy{Set: makeHeaderValues(response)}
+			}
+		}
+	}
+}
+
+// makeHeaderValues returns the headers sorted by name to generate the same spec on every reconciliation
+func makeHeaderValues(headers map[string]string) []contourv1.HeaderValue {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make([]contourv1.HeaderValue, 0, len(names))
+	for _, name := range names {
+		values = append(values, contourv1.HeaderValue{Name: name, Value: headers[name]})
+	}
+	return values
+}
+
 func (cr *ContourRouter) makePrefix(canary *flaggerv1.Canary) string {
 	prefix := "/"
 
@@ -393,9 +494,10 @@ func (cr *ContourRouter) makePrefix(canary *flaggerv1.Canary) string {
 func (cr *ContourRouter) makeConditions(canary *flaggerv1.Canary) []contourv1.Condition {
 	list := []contourv1.Condition{}
 
-	if len(canary.GetAnalysis().Match) > 0 {
-		for _, match := range canary.GetAnalysis().Match {
+	if len(canary.GetAnalysisMatch()) > 0 {
+		for _, match := range canary.GetAnalysisMatch() {
 			for s, stringMatch := range match.Headers {
+				s = headerName(canary, s)
 				h := &contourv1.HeaderCondition{
 					Name:  s,
 					Exact: stringMatch.Exact,
@@ -460,3 +562,32 @@ func (cr *ContourRouter) makeLinkerdHeaderValue(canary *flaggerv1.Canary, servic
 func (cr *ContourRouter) Finalize(_ *flaggerv1.Canary) error {
 	return nil
 }
+
+// Snapshot returns the current state of the HTTPProxy
+func (cr *ContourRouter) Snapshot(canary *flaggerv1.Canary) (*RoutingSnapshot, error) {
+	apexName, _, _ := canary.GetServiceNames()
+	obj, err := cr.contourClient.ProjectcontourV1().HTTPProxies(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("HTTPProxy %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+	return newRoutingSnapshot("HTTPProxy", obj)
+}
+
+// Restore sets the spec, labels and annotations of the HTTPProxy to the snapshot state
+func (cr *ContourRouter) Restore(canary *flaggerv1.Canary, snapshot *RoutingSnapshot) error {
+	saved := &contourv1.HTTPProxy{}
+	return snapshot.restore("HTTPProxy", saved, func() error {
+		obj, err := cr.contourClient.ProjectcontourV1().HTTPProxies(canary.Namespace).Get(context.TODO(), saved.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("HTTPProxy %s.%s get query error: %w", saved.Name, canary.Namespace, err)
+		}
+		clone := obj.DeepCopy()
+		clone.Spec = saved.Spec
+		clone.Labels = saved.Labels
+		clone.Annotations = saved.Annotations
+		if _, err := cr.contourClient.ProjectcontourV1().HTTPProxies(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("HTTPProxy %s.%s update error: %w", saved.Name, canary.Namespace, err)
+		}
+		return nil
+	})
+}
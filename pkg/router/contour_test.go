@@ -24,6 +24,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	contourv1 "github.com/fluxcd/flagger/pkg/apis/projectcontour/v1"
 )
 
 func TestContourRouter_Reconcile(t *testing.T) {
@@ -153,3 +156,127 @@ func TestContourRouter_Routes(t *testing.T) {
 	primary = proxy.Spec.Routes[1].Services[0]
 	assert.Equal(t, uint32(100), primary.Weight)
 }
+
+func TestContourRouter_Protocol(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ContourRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		contourClient: mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	// enable h2 upstreams on an existing proxy
+	mocks.canary.Spec.Service.Protocol = &flaggerv1.CanaryProtocol{
+		Upstream:       "h2",
+		PermitInsecure: true,
+	}
+	err = router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	require.NoError(t, err)
+
+	proxy, err := router.contourClient.ProjectcontourV1().HTTPProxies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	for _, route := range proxy.Spec.Routes {
+		assert.True(t, route.PermitInsecure)
+		for _, svc := range route.Services {
+			require.NotNil(t, svc.Protocol)
+			assert.Equal(t, "h2", *svc.Protocol)
+		}
+	}
+	assert.Equal(t, uint32(40), proxy.Spec.Routes[0].Services[1].Weight)
+}
+
+func TestContourRouter_ProtocolGRPC(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ContourRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		contourClient: mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	// gRPC services are detected from the port name
+	mocks.canary.Spec.Service.PortName = "grpc-api"
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	proxy, err := router.contourClient.ProjectcontourV1().HTTPProxies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	for _, route := range proxy.Spec.Routes {
+		for _, svc := range route.Services {
+			require.NotNil(t, svc.Protocol)
+			assert.Equal(t, "h2c", *svc.Protocol)
+		}
+	}
+}
+
+func TestContourRouter_CanaryHeaders(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ContourRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		contourClient: mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	mocks.canary.Spec.Service.CanaryHeaders = &flaggerv1.CanaryHeaders{Name: "X-Rollout", Request: true, Response: true}
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(mocks.canary, 50, 50, false)
+	require.NoError(t, err)
+
+	proxy, err := router.contourClient.ProjectcontourV1().HTTPProxies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	for _, svc := range proxy.Spec.Routes[0].Services {
+		if svc.Name == "podinfo-canary" {
+			assert.Contains(t, svc.RequestHeadersPolicy.Set, contourv1.HeaderValue{Name: "x-rollout", Value: "true"})
+			assert.Equal(t, []contourv1.HeaderValue{{Name: "x-rollout", Value: "true"}}, svc.ResponseHeadersPolicy.Set)
+		} else {
+			assert.Nil(t, svc.ResponseHeadersPolicy)
+		}
+	}
+}
+
+func TestContourRouter_ABTestGeo(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ContourRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		contourClient: mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	mocks.canary.Spec.Analysis.Geo = &flaggerv1.CanaryGeoMatch{
+		Header:    "CF-IPCountry",
+		Countries: []string{"NZ", "IE"},
+	}
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(mocks.canary, 0, 100, false)
+	require.NoError(t, err)
+
+	proxy, err := router.contourClient.ProjectcontourV1().HTTPProxies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// one route per country and the default route
+	require.Len(t, proxy.Spec.Routes, 3)
+	for i, country := range []string{"NZ", "IE"} {
+		route := proxy.Spec.Routes[i]
+		require.Len(t, route.Conditions, 1)
+		assert.Equal(t, &contourv1.HeaderCondition{Name: "cf-ipcountry", Exact: country}, route.Conditions[0].Header)
+		assert.Equal(t, uint32(100), route.Services[1].Weight)
+	}
+	assert.Nil(t, proxy.Spec.Routes[2].Conditions[0].Header)
+	assert.Equal(t, uint32(0), proxy.Spec.Routes[2].Services[1].Weight)
+}
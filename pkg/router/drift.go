@@ -0,0 +1,140 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// The ownership is tracked with annotations only, the routing objects have no finalizer:
+// an object deleted externally is recreated by the next reconciliation, and a finalizer
+// would block the namespace deletion while Flagger is not running.
+const (
+	// ownerAnnotation marks the routing objects generated by Flagger with the canary name
+	ownerAnnotation = "flagger.app/owned-by"
+	// specHashAnnotation holds the hash of the spec applied by Flagger,
+	// a live spec that differs while the desired spec is unchanged has been modified externally
+	specHashAnnotation = "flagger.app/spec-hash"
+)
+
+// DriftError reports routing objects modified outside of Flagger
+type DriftError struct {
+	// Objects lists the drifted objects as kind/name.namespace
+	Objects []string
+	// Repaired is true if the objects have been restored to the spec applied by Flagger
+	Repaired bool
+}
+
+func (e *DriftError) Error() string {
+	if e.Repaired {
+		return fmt.Sprintf("%s modified outside of Flagger, changes reverted", strings.Join(e.Objects, ", "))
+	}
+	return fmt.Sprintf("%s modified outside of Flagger, changes kept", strings.Join(e.Objects, ", "))
+}
+
+// withOwnership returns the annotations with the ownership markers of the canary and desired spec
+func withOwnership(annotations map[string]string, canary *flaggerv1.Canary, spec interface{}) map[string]string {
+	res := make(map[string]string, len(annotations)+2)
+	for k, v := range annotations {
+		res[k] = v
+	}
+	res[ownerAnnotation] = fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+	res[specHashAnnotation] = specHash(spec)
+	return res
+}
+
+// withoutOwnership removes the ownership markers from the annotations of a reverted object
+func withoutOwnership(annotations map[string]string) map[string]string {
+	res := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		if k != ownerAnnotation && k != specHashAnnotation {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// hasDrifted returns true if the object was last written by Flagger with the same
+// desired spec, so the difference to the live spec comes from an external change
+func hasDrifted(obj metav1.Object, spec interface{}) bool {
+	hash, ok := obj.GetAnnotations()[specHashAnnotation]
+	return ok && hash == specHash(spec)
+}
+
+// checkDrift returns a drift error if the object has drifted and
+// if the changes should be kept according to the canary drift policy
+func checkDrift(canary *flaggerv1.Canary, kind string, obj metav1.Object, spec interface{}) (drifted bool, err error) {
+	if !hasDrifted(obj, spec) {
+		return false, nil
+	}
+	if canary.Spec.Service.DriftPolicy == flaggerv1.DriftPolicyAlert {
+		return true, &DriftError{Objects: []string{driftObject(kind, obj)}}
+	}
+	return true, nil
+}
+
+// repairedDrift returns the drift error of an object restored to the desired spec
+func repairedDrift(kind string, obj metav1.Object) error {
+	return &DriftError{Objects: []string{driftObject(kind, obj)}, Repaired: true}
+}
+
+func driftObject(kind string, obj metav1.Object) string {
+	return fmt.Sprintf("%s/%s.%s", kind, obj.GetName(), obj.GetNamespace())
+}
+
+// driftCollector aggregates the drift errors of the objects reconciled by a router,
+// so that a drifted object doesn't prevent the reconciliation of the others
+type driftCollector struct {
+	drift *DriftError
+}
+
+// check records a drift error and returns any other error
+func (d *driftCollector) check(err error) error {
+	var drift *DriftError
+	if !errors.As(err, &drift) {
+		return err
+	}
+	if d.drift == nil {
+		d.drift = &DriftError{Repaired: drift.Repaired}
+	}
+	d.drift.Objects = append(d.drift.Objects, drift.Objects...)
+	d.drift.Repaired = d.drift.Repaired && drift.Repaired
+	return nil
+}
+
+func (d *driftCollector) err() error {
+	if d.drift == nil {
+		return nil
+	}
+	return d.drift
+}
+
+func specHash(spec interface{}) string {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b))[:16]
+}
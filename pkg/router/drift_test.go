@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestIstioRouter_Drift(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo.default", vs.Annotations[ownerAnnotation])
+	assert.NotEmpty(t, vs.Annotations[specHashAnnotation])
+
+	modify := func() {
+		vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		vs.Spec.Http[0].Timeout = "1s"
+		_, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Update(context.TODO(), vs, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+	timeout := func() string {
+		vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		return vs.Spec.Http[0].Timeout
+	}
+
+	// alert keeps the external changes
+	mocks.canary.Spec.Service.DriftPolicy = flaggerv1.DriftPolicyAlert
+	modify()
+	var drift *DriftError
+	require.ErrorAs(t, router.Reconcile(mocks.canary), &drift)
+	assert.False(t, drift.Repaired)
+	assert.Equal(t, []string{"VirtualService/podinfo.default"}, drift.Objects)
+	assert.Equal(t, "1s", timeout())
+
+	// repair reverts the external changes
+	mocks.canary.Spec.Service.DriftPolicy = flaggerv1.DriftPolicyRepair
+	require.ErrorAs(t, router.Reconcile(mocks.canary), &drift)
+	assert.True(t, drift.Repaired)
+	assert.Equal(t, mocks.canary.Spec.Service.Timeout, timeout())
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	// a canary spec change is not a drift
+	mocks.canary.Spec.Service.Timeout = "30s"
+	require.NoError(t, router.Reconcile(mocks.canary))
+	assert.Equal(t, "30s", timeout())
+}
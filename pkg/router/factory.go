@@ -59,7 +59,7 @@ func (factory *Factory) KubernetesRouter(kind string, labelSelector string, labe
 	switch kind {
 	case "Service":
 		return &KubernetesNoopRouter{}
-	default: // Daemonset or Deployment
+	default: // DaemonSet, Deployment or StatefulSet
 		return &KubernetesDefaultRouter{
 			logger:        factory.logger,
 			flaggerClient: factory.flaggerClient,
@@ -163,15 +163,51 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 			traefikClient: factory.meshClient,
 		}
 	case provider == flaggerv1.OsmProvider:
-		return &Smiv1alpha2Router{
-			logger:        factory.logger,
-			flaggerClient: factory.flaggerClient,
-			kubeClient:    factory.kubeClient,
-			smiClient:     factory.meshClient,
-			targetMesh:    flaggerv1.OsmProvider,
+		return &OsmRouter{
+			Smiv1alpha2Router: &Smiv1alpha2Router{
+				logger:        factory.logger,
+				flaggerClient: factory.flaggerClient,
+				kubeClient:    factory.kubeClient,
+				smiClient:     factory.meshClient,
+				targetMesh:    flaggerv1.OsmProvider,
+			},
 		}
 	case provider == flaggerv1.KubernetesProvider:
 		return &NopRouter{}
+	case provider == flaggerv1.NGINXGatewayProvider:
+		return &NGINXGatewayFabricRouter{
+			GatewayAPIRouter: &GatewayAPIRouter{
+				logger:           factory.logger,
+				gatewayAPIClient: factory.meshClient,
+			},
+			nginxGatewayClient: factory.meshClient,
+		}
+	case strings.HasPrefix(provider, flaggerv1.GatewayProvider):
+		return &GatewayAPIRouter{
+			logger:           factory.logger,
+			gatewayAPIClient: factory.meshClient,
+		}
+	case provider == flaggerv1.ApisixProvider:
+		return &ApisixRouter{
+			logger:       factory.logger,
+			apisixClient: factory.meshClient,
+		}
+	case provider == flaggerv1.ConsulProvider:
+		return &ConsulRouter{
+			logger:       factory.logger,
+			consulClient: factory.meshClient,
+		}
+	case provider == flaggerv1.KumaProvider:
+		return &KumaRouter{
+			logger:     factory.logger,
+			kubeClient: factory.kubeClient,
+			kumaClient: factory.meshClient,
+		}
+	case provider == flaggerv1.KedaProvider:
+		return &KedaRouter{
+			logger:        factory.logger,
+			flaggerClient: factory.flaggerClient,
+		}
 	default:
 		return &IstioRouter{
 			logger:        factory.logger,
@@ -181,3 +217,21 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 		}
 	}
 }
+
+// WithSnapshots wraps the mesh router to snapshot the routing objects before each traffic change
+// if the canary has routing snapshots enabled and the router supports them
+func (factory *Factory) WithSnapshots(canary *flaggerv1.Canary, router Interface) Interface {
+	if !canary.Spec.Service.RoutingSnapshot {
+		return router
+	}
+	snapshotter, ok := router.(Snapshotter)
+	if !ok {
+		return router
+	}
+	return &SnapshotRouter{
+		Interface:   router,
+		snapshotter: snapshotter,
+		kubeClient:  factory.kubeClient,
+		logger:      factory.logger,
+	}
+}
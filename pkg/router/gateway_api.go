@@ -0,0 +1,449 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	gatewayapi "github.com/fluxcd/flagger/pkg/apis/gatewayapi"
+	gatewayapiv1alpha2 "github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1alpha2"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// GatewayAPIRouter is managing Gateway API HTTPRoutes
+type GatewayAPIRouter struct {
+	gatewayAPIClient clientset.Interface
+	logger           *zap.SugaredLogger
+}
+
+// Reconcile creates or updates the HTTPRoute
+func (gwr *GatewayAPIRouter) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+
+	newSpec := gwr.makeSpec(canary, 100, 0, false)
+
+	route, err := gwr.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		metadata := canary.Spec.Service.Apex
+		if metadata == nil {
+			metadata = &flaggerv1.CustomMetadata{}
+		}
+		if metadata.Labels == nil {
+			metadata.Labels = make(map[string]string)
+		}
+		if metadata.Annotations == nil {
+			metadata.Annotations = make(map[string]string)
+		}
+
+		route = &gatewayapiv1alpha2.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        apexName,
+				Namespace:   canary.Namespace,
+				Labels:      metadata.Labels,
+				Annotations: withOwnership(filterMetadata(metadata.Annotations), canary, newSpec),
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: newSpec,
+		}
+
+		_, err = gwr.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes(canary.Namespace).Create(context.TODO(), route, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("HTTPRoute %s.%s create error: %w", apexName, canary.Namespace, err)
+		}
+		gwr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("HTTPRoute %s.%s created", route.GetName(), canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("HTTPRoute %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	// update HTTPRoute but keep the original backend weights and mirror
+	if route != nil {
+		// the mirror filter is set by SetRoutes during the analysis
+		desiredSpec := newSpec
+		if hasMirrorFilter(route.Spec) {
+			desiredSpec = gwr.makeSpec(canary, 100, 0, true)
+		}
+
+		if diff := cmp.Diff(
+			desiredSpec,
+			route.Spec,
+			cmpopts.IgnoreFields(gatewayapiv1alpha2.BackendRef{}, "Weight"),
+			cmpopts.EquateEmpty(),
+		); diff != "" {
+			drifted, err := checkDrift(canary, "HTTPRoute", route, newSpec)
+			if err != nil {
+				return err
+			}
+			clone := route.DeepCopy()
+			clone.Spec = newSpec
+			clone.Annotations = withOwnership(clone.Annotations, canary, newSpec)
+
+			_, err = gwr.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+			if err != nil {
+				return fmt.Errorf("HTTPRoute %s.%s update error: %w", apexName, canary.Namespace, err)
+			}
+			gwr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+				Infof("HTTPRoute %s.%s updated", route.GetName(), canary.Namespace)
+			if drifted {
+				return repairedDrift("HTTPRoute", route)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetRoutes returns the backend weight for primary and canary
+func (gwr *GatewayAPIRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	route, err := gwr.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("HTTPRoute %s.%s get query error: %w", apexName, canary.Namespace, err)
+		return
+	}
+
+	if len(route.Spec.Rules) < 1 || len(route.Spec.Rules[0].BackendRefs) < 2 {
+		err = fmt.Errorf("HTTPRoute %s.%s backends not found", apexName, canary.Namespace)
+		return
+	}
+
+	for _, ref := range route.Spec.Rules[0].BackendRefs {
+		if ref.Weight == nil {
+			continue
+		}
+		switch string(ref.Name) {
+		case primaryName:
+			primaryWeight = int(*ref.Weight)
+		case canaryName:
+			canaryWeight = int(*ref.Weight)
+		}
+	}
+	mirrored = hasMirrorFilter(route.Spec)
+
+	return
+}
+
+// SetRoutes updates the backend weight for primary and canary,
+// when mirrored is true the primary traffic is mirrored to the canary with a RequestMirror filter
+func (gwr *GatewayAPIRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+) error {
+	apexName, _, _ := canary.GetServiceNames()
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		return fmt.Errorf("HTTPRoute %s.%s update failed: no valid weights", apexName, canary.Namespace)
+	}
+
+	route, err := gwr.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("HTTPRoute %s.%s query error: %w", apexName, canary.Namespace, err)
+	}
+
+	route.Spec = gwr.makeSpec(canary, primaryWeight, canaryWeight, mirrored)
+
+	_, err = gwr.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes(canary.Namespace).Update(context.TODO(), route, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("HTTPRoute %s.%s update error: %w", apexName, canary.Namespace, err)
+	}
+	return nil
+}
+
+func (gwr *GatewayAPIRouter) Finalize(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// makeSpec generates the HTTPRoute spec, when A/B testing is enabled the first rule
+// routes the matching requests to the canary and the second rule routes everything else to the primary
+func (gwr *GatewayAPIRouter) makeSpec(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int, mirrored bool) gatewayapiv1alpha2.HTTPRouteSpec {
+	_, primaryName, canaryName := canary.GetServiceNames()
+
+	hostnames := make([]gatewayapiv1alpha2.Hostname, 0, len(canary.Spec.Service.Hosts))
+	for _, host := range canary.Spec.Service.Hosts {
+		hostnames = append(hostnames, gatewayapiv1alpha2.Hostname(host))
+	}
+
+	spec := gatewayapiv1alpha2.HTTPRouteSpec{
+		CommonRouteSpec: gatewayapiv1alpha2.CommonRouteSpec{
+			ParentRefs: gwr.makeParentRefs(canary),
+		},
+		Hostnames: hostnames,
+		Rules: []gatewayapiv1alpha2.HTTPRouteRule{
+			{
+				Matches: []gatewayapiv1alpha2.HTTPRouteMatch{
+					{Path: gwr.makePathMatch(canary)},
+				},
+				Filters: gwr.makeFilters(canary),
+				BackendRefs: []gatewayapiv1alpha2.HTTPBackendRef{
+					gwr.makeBackendRef(canary, primaryName, primaryWeight),
+					gwr.makeBackendRef(canary, canaryName, canaryWeight),
+				},
+			},
+		},
+	}
+
+	if len(canary.GetAnalysisMatch()) > 0 {
+		spec.Rules[0].Matches = gwr.makeMatches(canary)
+		spec.Rules = append(spec.Rules, gatewayapiv1alpha2.HTTPRouteRule{
+			Matches: []gatewayapiv1alpha2.HTTPRouteMatch{
+				{Path: gwr.makePathMatch(canary)},
+			},
+			Filters: gwr.makeFilters(canary),
+			BackendRefs: []gatewayapiv1alpha2.HTTPBackendRef{
+				gwr.makeBackendRef(canary, primaryName, 100),
+			},
+		})
+	}
+
+	if mirrored {
+		spec.Rules[0].Filters = append(spec.Rules[0].Filters, gwr.makeMirrorFilter(canary))
+	}
+
+	return spec
+}
+
+// makeMirrorFilter mirrors the requests to the canary service, if the mirror weight is set
+// only the given percentage of the requests is mirrored by the implementations that support it
+func (gwr *GatewayAPIRouter) makeMirrorFilter(canary *flaggerv1.Canary) gatewayapiv1alpha2.HTTPRouteFilter {
+	_, _, canaryName := canary.GetServiceNames()
+	group := gatewayapiv1alpha2.Group("")
+	kind := gatewayapiv1alpha2.Kind("Service")
+	port := gatewayapiv1alpha2.PortNumber(canary.Spec.Service.Port)
+	filter := &gatewayapiv1alpha2.HTTPRequestMirrorFilter{
+		BackendRef: gatewayapiv1alpha2.BackendObjectReference{
+			Group: &group,
+			Kind:  &kind,
+			Name:  gatewayapiv1alpha2.ObjectName(canaryName),
+			Port:  &port,
+		},
+	}
+	if mw := canary.GetAnalysis().MirrorWeight; mw > 0 {
+		percent := int32(mw)
+		filter.Percent = &percent
+	}
+	return gatewayapiv1alpha2.HTTPRouteFilter{
+		Type:          gatewayapiv1alpha2.HTTPRouteFilterRequestMirror,
+		RequestMirror: filter,
+	}
+}
+
+// hasMirrorFilter returns true if the first rule of the HTTPRoute mirrors the requests
+func hasMirrorFilter(spec gatewayapiv1alpha2.HTTPRouteSpec) bool {
+	if len(spec.Rules) < 1 {
+		return false
+	}
+	for _, filter := range spec.Rules[0].Filters {
+		if filter.Type == gatewayapiv1alpha2.HTTPRouteFilterRequestMirror {
+			return true
+		}
+	}
+	return false
+}
+
+// makeParentRefs sets the defaults of the Gateway API CRDs on the gateway references,
+// so that the defaulted fields don't show up as a diff of the generated spec
+func (gwr *GatewayAPIRouter) makeParentRefs(canary *flaggerv1.Canary) []gatewayapiv1alpha2.ParentReference {
+	var refs []gatewayapiv1alpha2.ParentReference
+	for _, ref := range canary.Spec.Service.GatewayRefs {
+		ref := *ref.DeepCopy()
+		if ref.Group == nil {
+			group := gatewayapiv1alpha2.Group(gatewayapi.GroupName)
+			ref.Group = &group
+		}
+		if ref.Kind == nil {
+			kind := gatewayapiv1alpha2.Kind("Gateway")
+			ref.Kind = &kind
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func (gwr *GatewayAPIRouter) makeBackendRef(canary *flaggerv1.Canary, name string, weight int) gatewayapiv1alpha2.HTTPBackendRef {
+	group := gatewayapiv1alpha2.Group("")
+	kind := gatewayapiv1alpha2.Kind("Service")
+	port := gatewayapiv1alpha2.PortNumber(canary.Spec.Service.Port)
+	w := int32(weight)
+	return gatewayapiv1alpha2.HTTPBackendRef{
+		BackendRef: gatewayapiv1alpha2.BackendRef{
+			BackendObjectReference: gatewayapiv1alpha2.BackendObjectReference{
+				Group: &group,
+				Kind:  &kind,
+				Name:  gatewayapiv1alpha2.ObjectName(name),
+				Port:  &port,
+			},
+			Weight: &w,
+		},
+	}
+}
+
+func (gwr *GatewayAPIRouter) makePathMatch(canary *flaggerv1.Canary) *gatewayapiv1alpha2.HTTPPathMatch {
+	pathType := gatewayapiv1alpha2.PathMatchPathPrefix
+	prefix := "/"
+
+	if len(canary.Spec.Service.Match) > 0 &&
+		canary.Spec.Service.Match[0].Uri != nil &&
+		canary.Spec.Service.Match[0].Uri.Prefix != "" {
+		prefix = canary.Spec.Service.Match[0].Uri.Prefix
+	}
+
+	return &gatewayapiv1alpha2.HTTPPathMatch{
+		Type:  &pathType,
+		Value: &prefix,
+	}
+}
+
+// makeMatches converts the canary analysis match conditions to HTTPRoute matches
+func (gwr *GatewayAPIRouter) makeMatches(canary *flaggerv1.Canary) []gatewayapiv1alpha2.HTTPRouteMatch {
+	var matches []gatewayapiv1alpha2.HTTPRouteMatch
+	for _, m := range canary.GetAnalysisMatch() {
+		match := gatewayapiv1alpha2.HTTPRouteMatch{
+			Path: gwr.makePathMatch(canary),
+		}
+
+		// sort the headers to generate the same spec on every reconciliation
+		names := make([]string, 0, len(m.Headers))
+		for name := range m.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			match.Headers = append(match.Headers, gwr.makeHeaderMatch(headerName(canary, name), m.Headers[name]))
+		}
+
+		if m.Method != nil && m.Method.Exact != "" {
+			method := gatewayapiv1alpha2.HTTPMethod(m.Method.Exact)
+			match.Method = &method
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// makeHeaderMatch converts the prefix and suffix string matches to regular expressions
+func (gwr *GatewayAPIRouter) makeHeaderMatch(name string, stringMatch istiov1alpha1.StringMatch) gatewayapiv1alpha2.HTTPHeaderMatch {
+	matchType := gatewayapiv1alpha2.HeaderMatchExact
+	value := stringMatch.Exact
+	switch {
+	case stringMatch.Regex != "":
+		matchType = gatewayapiv1alpha2.HeaderMatchRegularExpression
+		value = stringMatch.Regex
+	case stringMatch.Prefix != "":
+		matchType = gatewayapiv1alpha2.HeaderMatchRegularExpression
+		value = "^" + regexp.QuoteMeta(stringMatch.Prefix) + ".*"
+	case stringMatch.Suffix != "":
+		matchType = gatewayapiv1alpha2.HeaderMatchRegularExpression
+		value = ".*" + regexp.QuoteMeta(stringMatch.Suffix) + "$"
+	}
+	return gatewayapiv1alpha2.HTTPHeaderMatch{
+		Type:  &matchType,
+		Name:  gatewayapiv1alpha2.HTTPHeaderName(name),
+		Value: value,
+	}
+}
+
+// makeFilters converts the request headers operations of the canary service to a header modifier filter
+func (gwr *GatewayAPIRouter) makeFilters(canary *flaggerv1.Canary) []gatewayapiv1alpha2.HTTPRouteFilter {
+	if canary.Spec.Service.Headers == nil || canary.Spec.Service.Headers.Request == nil {
+		return nil
+	}
+	ops := canary.Spec.Service.Headers.Request
+	filter := &gatewayapiv1alpha2.HTTPRequestHeaderFilter{
+		Set:    makeHTTPHeaders(ops.Set),
+		Add:    makeHTTPHeaders(ops.Add),
+		Remove: ops.Remove,
+	}
+	return []gatewayapiv1alpha2.HTTPRouteFilter{
+		{
+			Type:                  gatewayapiv1alpha2.HTTPRouteFilterRequestHeaderModifier,
+			RequestHeaderModifier: filter,
+		},
+	}
+}
+
+func makeHTTPHeaders(headers map[string]string) []gatewayapiv1alpha2.HTTPHeader {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var res []gatewayapiv1alpha2.HTTPHeader
+	for _, name := range names {
+		res = append(res, gatewayapiv1alpha2.HTTPHeader{
+			Name:  gatewayapiv1alpha2.HTTPHeaderName(name),
+			Value: headers[name],
+		})
+	}
+	return res
+}
+
+// Snapshot returns the current state of the HTTPRoute
+func (gwr *GatewayAPIRouter) Snapshot(canary *flaggerv1.Canary) (*RoutingSnapshot, error) {
+	apexName, _, _ := canary.GetServiceNames()
+	obj, err := gwr.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("HTTPRoute %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+	return newRoutingSnapshot("HTTPRoute", obj)
+}
+
+// Restore sets the spec, labels and annotations of the HTTPRoute to the snapshot state
+func (gwr *GatewayAPIRouter) Restore(canary *flaggerv1.Canary, snapshot *RoutingSnapshot) error {
+	saved := &gatewayapiv1alpha2.HTTPRoute{}
+	return snapshot.restore("HTTPRoute", saved, func() error {
+		obj, err := gwr.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes(canary.Namespace).Get(context.TODO(), saved.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("HTTPRoute %s.%s get query error: %w", saved.Name, canary.Namespace, err)
+		}
+		clone := obj.DeepCopy()
+		clone.Spec = saved.Spec
+		clone.Labels = saved.Labels
+		clone.Annotations = saved.Annotations
+		if _, err := gwr.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("HTTPRoute %s.%s update error: %w", saved.Name, canary.Namespace, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gatewayapiv1alpha2 "github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1alpha2"
+)
+
+func TestGatewayAPIRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &GatewayAPIRouter{
+		logger:           mocks.logger,
+		gatewayAPIClient: mocks.meshClient,
+	}
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	cdClone := cd.DeepCopy()
+	cdClone.Spec.Service.Hosts = []string{"podinfo.example.com"}
+	cdClone.Spec.Service.GatewayRefs = []gatewayapiv1alpha2.ParentReference{{Name: "public"}}
+	canary, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cdClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// init
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	// test insert
+	route, err := router.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, route.Spec.ParentRefs, 1)
+	assert.Equal(t, gatewayapiv1alpha2.ObjectName("public"), route.Spec.ParentRefs[0].Name)
+	assert.Equal(t, gatewayapiv1alpha2.Kind("Gateway"), *route.Spec.ParentRefs[0].Kind)
+	assert.Equal(t, []gatewayapiv1alpha2.Hostname{"podinfo.example.com"}, route.Spec.Hostnames)
+
+	require.Len(t, route.Spec.Rules, 1)
+	rule := route.Spec.Rules[0]
+	assert.Equal(t, "/podinfo", *rule.Matches[0].Path.Value)
+	require.Len(t, rule.BackendRefs, 2)
+	assert.Equal(t, gatewayapiv1alpha2.ObjectName("podinfo-primary"), rule.BackendRefs[0].Name)
+	assert.Equal(t, int32(100), *rule.BackendRefs[0].Weight)
+	assert.Equal(t, gatewayapiv1alpha2.ObjectName("podinfo-canary"), rule.BackendRefs[1].Name)
+	assert.Equal(t, int32(0), *rule.BackendRefs[1].Weight)
+	require.Len(t, rule.Filters, 1)
+	assert.Equal(t, []string{"test"}, rule.Filters[0].RequestHeaderModifier.Remove)
+
+	// test update
+	cdClone = canary.DeepCopy()
+	cdClone.Spec.Service.Port = 8080
+	canary, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cdClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	route, err = router.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, gatewayapiv1alpha2.PortNumber(8080), *route.Spec.Rules[0].BackendRefs[0].Port)
+
+	// test A/B update
+	cdClone = canary.DeepCopy()
+	cdClone.Spec.Analysis.Iterations = 5
+	cdClone.Spec.Analysis.Match = newTestABTest().Spec.Analysis.Match
+	canary, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cdClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	route, err = router.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, route.Spec.Rules, 2)
+	headers := route.Spec.Rules[0].Matches[0].Headers
+	require.Len(t, headers, 1)
+	assert.Equal(t, gatewayapiv1alpha2.HTTPHeaderName("x-user-type"), headers[0].Name)
+	assert.Equal(t, "test", headers[0].Value)
+	assert.Equal(t, gatewayapiv1alpha2.HeaderMatchExact, *headers[0].Type)
+	require.Len(t, route.Spec.Rules[1].BackendRefs, 1)
+	assert.Equal(t, gatewayapiv1alpha2.ObjectName("podinfo-primary"), route.Spec.Rules[1].BackendRefs[0].Name)
+}
+
+func TestGatewayAPIRouter_Routes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &GatewayAPIRouter{
+		logger:           mocks.logger,
+		gatewayAPIClient: mocks.meshClient,
+	}
+
+	// init
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	// test set routes
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	require.NoError(t, err)
+
+	route, err := router.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(60), *route.Spec.Rules[0].BackendRefs[0].Weight)
+	assert.Equal(t, int32(40), *route.Spec.Rules[0].BackendRefs[1].Weight)
+
+	// test get routes
+	pw, cw, mirrored, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, pw)
+	assert.Equal(t, 40, cw)
+	assert.False(t, mirrored)
+
+	// test the weights are kept on reconcile
+	err = router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	pw, cw, _, err = router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, pw)
+	assert.Equal(t, 40, cw)
+}
+
+func TestGatewayAPIRouter_Mirror(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &GatewayAPIRouter{
+		logger:           mocks.logger,
+		gatewayAPIClient: mocks.meshClient,
+	}
+
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Analysis.Mirror = true
+	canary.Spec.Analysis.MirrorWeight = 50
+
+	// init
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	// test mirroring
+	err = router.SetRoutes(canary, 100, 0, true)
+	require.NoError(t, err)
+
+	route, err := router.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	filters := route.Spec.Rules[0].Filters
+	require.Len(t, filters, 2)
+	assert.Equal(t, gatewayapiv1alpha2.HTTPRouteFilterRequestMirror, filters[1].Type)
+	assert.Equal(t, gatewayapiv1alpha2.ObjectName("podinfo-canary"), filters[1].RequestMirror.BackendRef.Name)
+	assert.Equal(t, int32(50), *filters[1].RequestMirror.Percent)
+
+	pw, cw, mirrored, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, pw)
+	assert.Equal(t, 0, cw)
+	assert.True(t, mirrored)
+
+	// test the mirror filter is kept on reconcile
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	_, _, mirrored, err = router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.True(t, mirrored)
+
+	// test mirroring removal
+	err = router.SetRoutes(canary, 100, 0, false)
+	require.NoError(t, err)
+
+	route, err = router.gatewayAPIClient.GatewayapiV1alpha2().HTTPRoutes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, route.Spec.Rules[0].Filters, 1)
+	assert.Equal(t, gatewayapiv1alpha2.HTTPRouteFilterRequestHeaderModifier, route.Spec.Rules[0].Filters[0].Type)
+}
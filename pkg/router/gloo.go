@@ -115,7 +115,7 @@ func (gr *GlooRouter) Reconcile(canary *flaggerv1.Canary) error {
 				Name:        apexName,
 				Namespace:   canary.Namespace,
 				Labels:      metadata.Labels,
-				Annotations: filterMetadata(metadata.Annotations),
+				Annotations: withOwnership(filterMetadata(metadata.Annotations), canary, newSpec),
 				OwnerReferences: []metav1.OwnerReference{
 					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
 						Group:   flaggerv1.SchemeGroupVersion.Group,
@@ -145,8 +145,13 @@ func (gr *GlooRouter) Reconcile(canary *flaggerv1.Canary) error {
 			routeTable.Spec,
 			cmpopts.IgnoreFields(gatewayv1.WeightedDestination{}, "Weight"),
 		); diff != "" {
+			drifted, err := checkDrift(canary, "RouteTable", routeTable, newSpec)
+			if err != nil {
+				return err
+			}
 			clone := routeTable.DeepCopy()
 			clone.Spec = newSpec
+			clone.Annotations = withOwnership(clone.Annotations, canary, newSpec)
 
 			_, err = gr.glooClient.GatewayV1().RouteTables(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
 			if err != nil {
@@ -154,6 +159,9 @@ func (gr *GlooRouter) Reconcile(canary *flaggerv1.Canary) error {
 			}
 			gr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Infof("RouteTable %s.%s updated", routeTable.GetName(), canary.Namespace)
+			if drifted {
+				return repairedDrift("RouteTable", routeTable)
+			}
 		}
 	}
 
@@ -167,8 +175,8 @@ func (gr *GlooRouter) GetRoutes(canary *flaggerv1.Canary) (
 	mirrored bool,
 	err error,
 ) {
-	apexName := canary.Spec.TargetRef.Name
-	primaryName := fmt.Sprintf("%s-%s-primaryupstream-%v", canary.Namespace, canary.Spec.TargetRef.Name, canary.Spec.Service.Port)
+	apexName, _, _ := canary.GetServiceNames()
+	primaryName := fmt.Sprintf("%s-%s-primaryupstream-%v", canary.Namespace, apexName, canary.Spec.Service.Port)
 
 	routeTable, err := gr.glooClient.GatewayV1().RouteTables(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
@@ -358,15 +366,15 @@ func getMatchers(canary *flaggerv1.Canary) []gatewayv1.Matcher {
 
 func getHeaderMatchers(canary *flaggerv1.Canary) []gatewayv1.HeaderMatcher {
 	var headerMatchers []gatewayv1.HeaderMatcher
-	for _, match := range canary.GetAnalysis().Match {
+	for _, match := range canary.GetAnalysisMatch() {
 		for s, stringMatch := range match.Headers {
 			h := gatewayv1.HeaderMatcher{
-				Name:  s,
+				Name:  headerName(canary, s),
 				Value: stringMatch.Exact,
 			}
 			if stringMatch.Regex != "" {
 				h = gatewayv1.HeaderMatcher{
-					Name:  s,
+					Name:  headerName(canary, s),
 					Value: stringMatch.Regex,
 					Regex: true,
 				}
@@ -379,7 +387,7 @@ func getHeaderMatchers(canary *flaggerv1.Canary) []gatewayv1.HeaderMatcher {
 
 func getMethods(canary *flaggerv1.Canary) []string {
 	var methods []string
-	for _, match := range canary.GetAnalysis().Match {
+	for _, match := range canary.GetAnalysisMatch() {
 		if stringMatch := match.Method; stringMatch != nil {
 			methods = append(methods, stringMatch.Exact)
 		}
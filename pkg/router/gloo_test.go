@@ -176,3 +176,40 @@ func TestGlooRouter_GetRoutes(t *testing.T) {
 	assert.Equal(t, 0, c)
 	assert.False(t, m)
 }
+
+func TestGlooRouter_GetRoutesServiceName(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &GlooRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		glooClient:    mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	svcRouter := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+	}
+
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.Name = "podinfo-svc"
+	err := svcRouter.Initialize(canary)
+	require.NoError(t, err)
+	err = svcRouter.Reconcile(canary)
+	require.NoError(t, err)
+
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	_, err = router.glooClient.GatewayV1().RouteTables("default").Get(context.TODO(), "podinfo-svc", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	err = router.SetRoutes(canary, 60, 40, false)
+	require.NoError(t, err)
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+}
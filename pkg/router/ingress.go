@@ -84,7 +84,7 @@ func (i *IngressRouter) Reconcile(canary *flaggerv1.Canary) error {
 						Kind:    flaggerv1.CanaryKind,
 					}),
 				},
-				Annotations: i.makeAnnotations(ingressClone.Annotations),
+				Annotations: withOwnership(i.makeAnnotations(ingressClone.Annotations), canary, ingressClone.Spec),
 				Labels:      ingressClone.Labels,
 			},
 			Spec: ingressClone.Spec,
@@ -103,16 +103,24 @@ func (i *IngressRouter) Reconcile(canary *flaggerv1.Canary) error {
 	}
 
 	if diff := cmp.Diff(ingressClone.Spec, canaryIngress.Spec); diff != "" {
+		drifted, err := checkDrift(canary, "Ingress", canaryIngress, ingressClone.Spec)
+		if err != nil {
+			return err
+		}
 		iClone := canaryIngress.DeepCopy()
 		iClone.Spec = ingressClone.Spec
+		iClone.Annotations = withOwnership(iClone.Annotations, canary, ingressClone.Spec)
 
-		_, err := i.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Update(context.TODO(), iClone, metav1.UpdateOptions{})
+		_, err = i.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Update(context.TODO(), iClone, metav1.UpdateOptions{})
 		if err != nil {
 			return fmt.Errorf("ingress %s.%s update error: %w", canaryIngressName, iClone.Namespace, err)
 		}
 
 		i.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 			Infof("Ingress %s updated", canaryIngressName)
+		if drifted {
+			return repairedDrift("Ingress", canaryIngress)
+		}
 	}
 
 	return nil
@@ -132,7 +140,7 @@ func (i *IngressRouter) GetRoutes(canary *flaggerv1.Canary) (
 	}
 
 	// A/B testing
-	if len(canary.GetAnalysis().Match) > 0 {
+	if len(canary.GetAnalysisMatch()) > 0 {
 		for k := range canaryIngress.Annotations {
 			if k == i.GetAnnotationWithPrefix("canary-by-cookie") || k == i.GetAnnotationWithPrefix("canary-by-header") {
 				return 0, 100, false, nil
@@ -174,14 +182,14 @@ func (i *IngressRouter) SetRoutes(
 	iClone := canaryIngress.DeepCopy()
 
 	// A/B testing
-	if len(canary.GetAnalysis().Match) > 0 {
+	if len(canary.GetAnalysisMatch()) > 0 {
 		var cookie, header, headerValue, headerRegex string
-		for _, m := range canary.GetAnalysis().Match {
+		for _, m := range canary.GetAnalysisMatch() {
 			for k, v := range m.Headers {
 				if k == "cookie" {
 					cookie = v.Exact
 				} else {
-					header = k
+					header = headerName(canary, k)
 					headerRegex = v.Regex
 					headerValue = v.Exact
 				}
@@ -231,9 +239,10 @@ func (i *IngressRouter) makeAnnotations(annotations map[string]string) map[strin
 
 func (i *IngressRouter) makeHeaderAnnotations(annotations map[string]string,
 	header string, headerValue string, headerRegex string, cookie string) map[string]string {
+	// remove the previous match conditions, the A/B testing routes are set on every step
 	res := make(map[string]string)
 	for k, v := range filterMetadata(annotations) {
-		if !strings.Contains(v, i.GetAnnotationWithPrefix("canary")) {
+		if !strings.Contains(k, i.GetAnnotationWithPrefix("canary")) {
 			res[k] = v
 		}
 	}
@@ -114,6 +114,7 @@ func TestIngressRouter_ABTest(t *testing.T) {
 	tables := []struct {
 		makeCanary func() *flaggerv1.Canary
 		annotation string
+		removed    []string
 	}{
 		// Header exact match
 		{
@@ -148,6 +149,7 @@ func TestIngressRouter_ABTest(t *testing.T) {
 				return mocks.ingressCanary
 			},
 			annotation: router.GetAnnotationWithPrefix("canary-by-header-pattern"),
+			removed:    []string{"canary-by-header-value"},
 		},
 		// Cookie exact match
 		{
@@ -165,6 +167,7 @@ func TestIngressRouter_ABTest(t *testing.T) {
 				return mocks.ingressCanary
 			},
 			annotation: router.GetAnnotationWithPrefix("canary-by-cookie"),
+			removed:    []string{"canary-by-header", "canary-by-header-pattern"},
 		},
 	}
 
@@ -185,5 +188,10 @@ func TestIngressRouter_ABTest(t *testing.T) {
 		assert.Equal(t, "true", inCanary.Annotations[canaryAn])
 		assert.Equal(t, "test", inCanary.Annotations[table.annotation])
 		assert.Equal(t, "", inCanary.Annotations["kustomize.toolkit.fluxcd.io/checksum"])
+
+		// the match conditions of the previous cases are removed
+		for _, an := range table.removed {
+			assert.NotContains(t, inCanary.Annotations, router.GetAnnotationWithPrefix(an))
+		}
 	}
 }
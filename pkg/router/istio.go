@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -30,6 +31,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
 	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 )
@@ -44,26 +46,35 @@ type IstioRouter struct {
 
 // Reconcile creates or updates the Istio virtual service and destination rules
 func (ir *IstioRouter) Reconcile(canary *flaggerv1.Canary) error {
+	canary, err := ir.renderServiceTemplates(canary)
+	if err != nil {
+		return err
+	}
 	_, primaryName, canaryName := canary.GetServiceNames()
 
-	if err := ir.reconcileDestinationRule(canary, canaryName); err != nil {
+	canaryTrafficPolicy := mergeTrafficPolicy(canary.Spec.Service.TrafficPolicy, canary.Spec.Service.CanaryTrafficPolicy)
+	if isAnalysisRunning(canary) {
+		canaryTrafficPolicy = mergeTrafficPolicy(canaryTrafficPolicy, canary.Spec.Service.AnalysisTrafficPolicy)
+	}
+	var drift driftCollector
+	if err := drift.check(ir.reconcileDestinationRule(canary, canaryName, canaryTrafficPolicy)); err != nil {
 		return fmt.Errorf("reconcileDestinationRule failed: %w", err)
 	}
 
-	if err := ir.reconcileDestinationRule(canary, primaryName); err != nil {
+	if err := drift.check(ir.reconcileDestinationRule(canary, primaryName, canary.Spec.Service.TrafficPolicy)); err != nil {
 		return fmt.Errorf("reconcileDestinationRule failed: %w", err)
 	}
 
-	if err := ir.reconcileVirtualService(canary); err != nil {
+	if err := drift.check(ir.reconcileVirtualService(canary)); err != nil {
 		return fmt.Errorf("reconcileVirtualService failed: %w", err)
 	}
-	return nil
+	return drift.err()
 }
 
-func (ir *IstioRouter) reconcileDestinationRule(canary *flaggerv1.Canary, name string) error {
+func (ir *IstioRouter) reconcileDestinationRule(canary *flaggerv1.Canary, name string, trafficPolicy *istiov1alpha3.TrafficPolicy) error {
 	newSpec := istiov1alpha3.DestinationRuleSpec{
 		Host:          name,
-		TrafficPolicy: canary.Spec.Service.TrafficPolicy,
+		TrafficPolicy: trafficPolicy,
 	}
 
 	destinationRule, err := ir.istioClient.NetworkingV1alpha3().DestinationRules(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
@@ -71,8 +82,9 @@ func (ir *IstioRouter) reconcileDestinationRule(canary *flaggerv1.Canary, name s
 	if errors.IsNotFound(err) {
 		destinationRule = &istiov1alpha3.DestinationRule{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      name,
-				Namespace: canary.Namespace,
+				Name:        name,
+				Namespace:   canary.Namespace,
+				Annotations: withOwnership(nil, canary, newSpec),
 				OwnerReferences: []metav1.OwnerReference{
 					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
 						Group:   flaggerv1.SchemeGroupVersion.Group,
@@ -97,14 +109,22 @@ func (ir *IstioRouter) reconcileDestinationRule(canary *flaggerv1.Canary, name s
 	// update
 	if destinationRule != nil {
 		if diff := cmp.Diff(newSpec, destinationRule.Spec); diff != "" {
+			drifted, err := checkDrift(canary, "DestinationRule", destinationRule, newSpec)
+			if err != nil {
+				return err
+			}
 			clone := destinationRule.DeepCopy()
 			clone.Spec = newSpec
+			clone.Annotations = withOwnership(clone.Annotations, canary, newSpec)
 			_, err = ir.istioClient.NetworkingV1alpha3().DestinationRules(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
 			if err != nil {
 				return fmt.Errorf("DestinationRule %s.%s update error: %w", name, canary.Namespace, err)
 			}
 			ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Infof("DestinationRule %s.%s updated", destinationRule.GetName(), canary.Namespace)
+			if drifted {
+				return repairedDrift("DestinationRule", destinationRule)
+			}
 		}
 	}
 
@@ -119,6 +139,10 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 			// delegate VirtualService cannot have hosts and gateways.
 			return fmt.Errorf("VirtualService %s.%s cannot have hosts and gateways when delegation enabled", apexName, canary.Namespace)
 		}
+		if canary.IsTCP() {
+			// Istio merges only the HTTP routes of a delegate into its parent
+			return fmt.Errorf("VirtualService %s.%s cannot have TCP routes when delegation enabled", apexName, canary.Namespace)
+		}
 	}
 
 	// set hosts and add the ClusterIP service host if it doesn't exists
@@ -177,8 +201,13 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 		},
 	}
 
-	if len(canary.GetAnalysis().Match) > 0 {
-		canaryMatch := mergeMatchConditions(canary.GetAnalysis().Match, canary.Spec.Service.Match)
+	// route each traffic source separately (progressive canary)
+	if len(canary.GetAnalysis().SourceWeights) > 0 {
+		newSpec.Http = append(makeSourceRoutes(canary, primaryName, canaryName, 100, 0, false), newSpec.Http...)
+	}
+
+	if len(canary.GetAnalysisMatch()) > 0 {
+		canaryMatch := mergeMatchConditions(canary.GetAnalysisMatch(), canary.Spec.Service.Match)
 		newSpec.Http = []istiov1alpha3.HTTPRoute{
 			{
 				Match:      canaryMatch,
@@ -203,6 +232,15 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 		}
 	}
 
+	// route raw TCP connections
+	if canary.IsTCP() {
+		newSpec = istiov1alpha3.VirtualServiceSpec{
+			Hosts:    hosts,
+			Gateways: gateways,
+			Tcp:      makeTCPRoutes(canary, primaryName, canaryName, 100, 0),
+		}
+	}
+
 	virtualService, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	// insert
 	if errors.IsNotFound(err) {
@@ -222,7 +260,7 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 				Name:        apexName,
 				Namespace:   canary.Namespace,
 				Labels:      metadata.Labels,
-				Annotations: filterMetadata(metadata.Annotations),
+				Annotations: withOwnership(filterMetadata(metadata.Annotations), canary, newSpec),
 				OwnerReferences: []metav1.OwnerReference{
 					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
 						Group:   flaggerv1.SchemeGroupVersion.Group,
@@ -252,12 +290,22 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 
 	// update service but keep the original destination weights and mirror
 	if virtualService != nil {
+		// the session affinity route is set by SetRoutes during the analysis
+		desiredSpec := newSpec
+		if !canary.IsTCP() && len(canary.GetAnalysisMatch()) == 0 && hasSessionAffinity(canary, virtualService.Spec.Http) {
+			desiredSpec.Http = withSessionAffinity(canary, canaryName, newSpec.Http)
+		}
+
 		if diff := cmp.Diff(
-			newSpec,
+			desiredSpec,
 			virtualService.Spec,
 			cmpopts.IgnoreFields(istiov1alpha3.DestinationWeight{}, "Weight"),
 			cmpopts.IgnoreFields(istiov1alpha3.HTTPRoute{}, "Mirror", "MirrorPercentage"),
 		); diff != "" {
+			drifted, err := checkDrift(canary, "VirtualService", virtualService, newSpec)
+			if err != nil {
+				return err
+			}
 			vtClone := virtualService.DeepCopy()
 			vtClone.Spec = newSpec
 
@@ -278,6 +326,7 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 
 				vtClone.ObjectMeta.Annotations[configAnnotation] = string(b)
 			}
+			vtClone.ObjectMeta.Annotations = withOwnership(vtClone.ObjectMeta.Annotations, canary, newSpec)
 
 			_, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), vtClone, metav1.UpdateOptions{})
 			if err != nil {
@@ -285,6 +334,9 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 			}
 			ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Infof("VirtualService %s.%s updated", virtualService.GetName(), canary.Namespace)
+			if drifted {
+				return repairedDrift("VirtualService", virtualService)
+			}
 		}
 	}
 
@@ -306,6 +358,24 @@ func (ir *IstioRouter) GetRoutes(canary *flaggerv1.Canary) (
 		return
 	}
 
+	if canary.IsTCP() {
+		for _, tcp := range vs.Spec.Tcp {
+			for _, route := range tcp.Route {
+				if route.Destination.Host == primaryName {
+					primaryWeight = route.Weight
+				}
+				if route.Destination.Host == canaryName {
+					canaryWeight = route.Weight
+				}
+			}
+		}
+		if primaryWeight == 0 && canaryWeight == 0 {
+			err = fmt.Errorf("VirtualService %s.%s does not contain TCP routes for %s-primary and %s-canary",
+				apexName, canary.Namespace, apexName, apexName)
+		}
+		return
+	}
+
 	var httpRoute istiov1alpha3.HTTPRoute
 	for _, http := range vs.Spec.Http {
 		for _, r := range http.Route {
@@ -343,6 +413,10 @@ func (ir *IstioRouter) SetRoutes(
 	canaryWeight int,
 	mirrored bool,
 ) error {
+	canary, err := ir.renderServiceTemplates(canary)
+	if err != nil {
+		return err
+	}
 	apexName, primaryName, canaryName := canary.GetServiceNames()
 
 	vs, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
@@ -352,6 +426,17 @@ func (ir *IstioRouter) SetRoutes(
 
 	vsCopy := vs.DeepCopy()
 
+	// weighted TCP routing, mirroring and A/B testing are not available for TCP routes
+	if canary.IsTCP() {
+		vsCopy.Spec.Http = nil
+		vsCopy.Spec.Tcp = makeTCPRoutes(canary, primaryName, canaryName, primaryWeight, canaryWeight)
+		_, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), vsCopy, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("VirtualService %s.%s update failed: %w", apexName, canary.Namespace, err)
+		}
+		return nil
+	}
+
 	// weighted routing (progressive canary)
 	vsCopy.Spec.Http = []istiov1alpha3.HTTPRoute{
 		{
@@ -369,19 +454,23 @@ func (ir *IstioRouter) SetRoutes(
 	}
 
 	if mirrored {
-		vsCopy.Spec.Http[0].Mirror = &istiov1alpha3.Destination{
-			Host: canaryName,
-		}
+		setMirror(canary, canaryName, &vsCopy.Spec.Http[0])
+	}
 
-		if mw := canary.GetAnalysis().MirrorWeight; mw > 0 {
-			vsCopy.Spec.Http[0].MirrorPercentage = &istiov1alpha3.Percent{Value: float64(mw)}
-		}
+	// cap the canary weight per traffic source
+	if len(canary.GetAnalysis().SourceWeights) > 0 {
+		vsCopy.Spec.Http = append(makeSourceRoutes(canary, primaryName, canaryName, primaryWeight, canaryWeight, mirrored), vsCopy.Spec.Http...)
+	}
+
+	// keep the clients routed to the canary on the canary (session affinity)
+	if cookie, _ := canary.GetSessionAffinityCookie(); cookie != "" && canaryWeight > 0 && canaryWeight < 100 {
+		vsCopy.Spec.Http = withSessionAffinity(canary, canaryName, vsCopy.Spec.Http)
 	}
 
 	// fix routing (A/B testing)
-	if len(canary.GetAnalysis().Match) > 0 {
+	if len(canary.GetAnalysisMatch()) > 0 {
 		// merge the common routes with the canary ones
-		canaryMatch := mergeMatchConditions(canary.GetAnalysis().Match, canary.Spec.Service.Match)
+		canaryMatch := mergeMatchConditions(canary.GetAnalysisMatch(), canary.Spec.Service.Match)
 		vsCopy.Spec.Http = []istiov1alpha3.HTTPRoute{
 			{
 				Match:      canaryMatch,
@@ -445,6 +534,7 @@ func (ir *IstioRouter) Finalize(canary *flaggerv1.Canary) error {
 
 	clone := vs.DeepCopy()
 	clone.Spec = storedSpec
+	clone.Annotations = withoutOwnership(clone.Annotations)
 
 	_, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
 	if err != nil {
@@ -453,6 +543,59 @@ func (ir *IstioRouter) Finalize(canary *flaggerv1.Canary) error {
 	return nil
 }
 
+// setMirror mirrors the requests of the route to the canary
+func setMirror(canary *flaggerv1.Canary, canaryName string, route *istiov1alpha3.HTTPRoute) {
+	route.Mirror = &istiov1alpha3.Destination{
+		Host: canaryName,
+	}
+
+	if mw := canary.GetAnalysis().MirrorWeight; mw > 0 {
+		route.MirrorPercentage = &istiov1alpha3.Percent{Value: float64(mw)}
+	}
+}
+
+// makeSourceRoutes returns a route for each gateway listed in the analysis source weights,
+// with the canary weight capped to the gateway max weight, the source traffic is mirrored
+// to the canary like the traffic of the default route
+func makeSourceRoutes(canary *flaggerv1.Canary, primaryName string, canaryName string, primaryWeight int, canaryWeight int, mirrored bool) []istiov1alpha3.HTTPRoute {
+	var routes []istiov1alpha3.HTTPRoute
+	for _, source := range canary.GetAnalysis().SourceWeights {
+		sourceCanaryWeight := canaryWeight
+		if sourceCanaryWeight > source.MaxWeight {
+			sourceCanaryWeight = source.MaxWeight
+		}
+		sourcePrimaryWeight := primaryWeight + canaryWeight - sourceCanaryWeight
+
+		match := []istiov1alpha3.HTTPMatchRequest{{Gateways: []string{source.Gateway}}}
+		if len(canary.Spec.Service.Match) > 0 {
+			match = make([]istiov1alpha3.HTTPMatchRequest, 0, len(canary.Spec.Service.Match))
+			for _, m := range canary.Spec.Service.Match {
+				m = *m.DeepCopy()
+				m.Gateways = []string{source.Gateway}
+				match = append(match, m)
+			}
+		}
+
+		route := istiov1alpha3.HTTPRoute{
+			Match:      match,
+			Rewrite:    canary.Spec.Service.Rewrite,
+			Timeout:    canary.Spec.Service.Timeout,
+			Retries:    canary.Spec.Service.Retries,
+			CorsPolicy: canary.Spec.Service.CorsPolicy,
+			Headers:    canary.Spec.Service.Headers,
+			Route: []istiov1alpha3.DestinationWeight{
+				makeDestination(canary, primaryName, sourcePrimaryWeight),
+				makeDestination(canary, canaryName, sourceCanaryWeight),
+			},
+		}
+		if mirrored {
+			setMirror(canary, canaryName, &route)
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
 // mergeMatchConditions appends the URI match rules to canary conditions
 func mergeMatchConditions(canary, defaults []istiov1alpha3.HTTPMatchRequest) []istiov1alpha3.HTTPMatchRequest {
 	if len(defaults) == 0 {
@@ -477,6 +620,18 @@ func mergeMatchConditions(canary, defaults []istiov1alpha3.HTTPMatchRequest) []i
 	return merged
 }
 
+// makeTCPRoutes returns the weighted TCP route of the primary and canary services
+func makeTCPRoutes(canary *flaggerv1.Canary, primaryName string, canaryName string, primaryWeight int, canaryWeight int) []istiov1alpha3.TCPRoute {
+	return []istiov1alpha3.TCPRoute{
+		{
+			Route: []istiov1alpha3.DestinationWeight{
+				makeDestination(canary, primaryName, primaryWeight),
+				makeDestination(canary, canaryName, canaryWeight),
+			},
+		},
+	}
+}
+
 // makeDestination returns a an destination weight for the specified host
 func makeDestination(canary *flaggerv1.Canary, host string, weight int) istiov1alpha3.DestinationWeight {
 	dest := istiov1alpha3.DestinationWeight{
@@ -487,7 +642,8 @@ func makeDestination(canary *flaggerv1.Canary, host string, weight int) istiov1a
 	}
 
 	// set destination port when an ingress gateway is specified
-	if canary.Spec.Service.PortDiscovery &&
+	// and the service has multiple ports
+	if (canary.Spec.Service.PortDiscovery || len(canary.Spec.Service.Ports) > 0) &&
 		len(canary.Spec.Service.Gateways) > 0 &&
 		canary.Spec.Service.Gateways[0] != "mesh" {
 		dest = istiov1alpha3.DestinationWeight{
@@ -501,5 +657,162 @@ func makeDestination(canary *flaggerv1.Canary, host string, weight int) istiov1a
 		}
 	}
 
+	// mark the requests routed to the canary
+	if _, _, canaryName := canary.GetServiceNames(); host == canaryName {
+		dest.Headers = makeCanaryHeaders(canary)
+	}
+
 	return dest
 }
+
+// makeCanaryHeaders returns the header operations of the canary destination
+func makeCanaryHeaders(canary *flaggerv1.Canary) *istiov1alpha3.Headers {
+	request, response, baggage := canary.GetCanaryHeaders()
+	if request == nil && response == nil && baggage == "" {
+		return nil
+	}
+
+	headers := &istiov1alpha3.Headers{}
+	if request != nil || baggage != "" {
+		headers.Request = &istiov1alpha3.HeaderOperations{Set: request}
+		if baggage != "" {
+			headers.Request.Add = map[string]string{"baggage": baggage}
+		}
+	}
+	if response != nil {
+		headers.Response = &istiov1alpha3.HeaderOperations{Set: response}
+	}
+	return headers
+}
+
+// withSessionAffinity returns a copy of the weighted routes with the canary responses
+// setting the session affinity cookie and a route pinning the cookie holders to the canary
+func withSessionAffinity(canary *flaggerv1.Canary, canaryName string, routes []istiov1alpha3.HTTPRoute) []istiov1alpha3.HTTPRoute {
+	cookie, maxAge := canary.GetSessionAffinityCookie()
+
+	res := make([]istiov1alpha3.HTTPRoute, 0, len(routes)+1)
+	res = append(res, istiov1alpha3.HTTPRoute{
+		Match:      mergeMatchConditions(sessionAffinityMatch(cookie), canary.Spec.Service.Match),
+		Rewrite:    canary.Spec.Service.Rewrite,
+		Timeout:    canary.Spec.Service.Timeout,
+		Retries:    canary.Spec.Service.Retries,
+		CorsPolicy: canary.Spec.Service.CorsPolicy,
+		Headers:    canary.Spec.Service.Headers,
+		Route: []istiov1alpha3.DestinationWeight{
+			makeDestination(canary, canaryName, 100),
+		},
+	})
+	res = append(res, routes...)
+
+	weighted := &res[len(res)-1]
+	weighted.Route = append([]istiov1alpha3.DestinationWeight{}, weighted.Route...)
+	weighted.Route[1].Headers = setCookieHeader(weighted.Route[1].Headers, cookie, maxAge)
+
+	return res
+}
+
+// hasSessionAffinity returns true if the routes pin the holders of the canary cookie
+func hasSessionAffinity(canary *flaggerv1.Canary, routes []istiov1alpha3.HTTPRoute) bool {
+	cookie, _ := canary.GetSessionAffinityCookie()
+	if cookie == "" || len(routes) < 2 || len(routes[0].Match) == 0 {
+		return false
+	}
+	return routes[0].Match[0].Headers["cookie"] == sessionAffinityMatch(cookie)[0].Headers["cookie"]
+}
+
+func sessionAffinityMatch(cookie string) []istiov1alpha3.HTTPMatchRequest {
+	return []istiov1alpha3.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"cookie": {Regex: fmt.Sprintf("^(.*?;)?(%s)(;.*)?$", regexp.QuoteMeta(cookie))},
+			},
+		},
+	}
+}
+
+// setCookieHeader returns a copy of the header operations that
+// appends the session affinity cookie to the canary responses
+func setCookieHeader(headers *istiov1alpha3.Headers, cookie string, maxAge int) *istiov1alpha3.Headers {
+	if headers == nil {
+		headers = &istiov1alpha3.Headers{}
+	}
+	headers = headers.DeepCopy()
+	if headers.Response == nil {
+		headers.Response = &istiov1alpha3.HeaderOperations{}
+	}
+	if headers.Response.Add == nil {
+		headers.Response.Add = map[string]string{}
+	}
+	headers.Response.Add["Set-Cookie"] = fmt.Sprintf("%s; Max-Age=%d", cookie, maxAge)
+	return headers
+}
+
+// mergeTrafficPolicy returns the base traffic policy with the fields set in overrides replaced
+func mergeTrafficPolicy(base, overrides *istiov1alpha3.TrafficPolicy) *istiov1alpha3.TrafficPolicy {
+	if overrides == nil {
+		return base
+	}
+	if base == nil {
+		return overrides.DeepCopy()
+	}
+
+	res := base.DeepCopy()
+	if overrides.LoadBalancer != nil {
+		res.LoadBalancer = overrides.LoadBalancer.DeepCopy()
+	}
+	if overrides.ConnectionPool != nil {
+		res.ConnectionPool = overrides.ConnectionPool.DeepCopy()
+	}
+	if overrides.OutlierDetection != nil {
+		res.OutlierDetection = overrides.OutlierDetection.DeepCopy()
+	}
+	if overrides.TLS != nil {
+		res.TLS = overrides.TLS.DeepCopy()
+	}
+	if len(overrides.PortLevelSettings) > 0 {
+		res.PortLevelSettings = make([]istiov1alpha3.PortTrafficPolicy, len(overrides.PortLevelSettings))
+		for i := range overrides.PortLevelSettings {
+			overrides.PortLevelSettings[i].DeepCopyInto(&res.PortLevelSettings[i])
+		}
+	}
+	return res
+}
+
+// isAnalysisRunning returns true while the canary receives traffic as part of the analysis,
+// the analysis traffic policy is removed once the promotion starts
+func isAnalysisRunning(canary *flaggerv1.Canary) bool {
+	switch canary.Status.Phase {
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaitingPromotion:
+		return true
+	}
+	return false
+}
+
+// Snapshot returns the current state of the VirtualService
+func (ir *IstioRouter) Snapshot(canary *flaggerv1.Canary) (*RoutingSnapshot, error) {
+	apexName, _, _ := canary.GetServiceNames()
+	obj, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("VirtualService %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+	return newRoutingSnapshot("VirtualService", obj)
+}
+
+// Restore sets the spec, labels and annotations of the VirtualService to the snapshot state
+func (ir *IstioRouter) Restore(canary *flaggerv1.Canary, snapshot *RoutingSnapshot) error {
+	saved := &istiov1alpha3.VirtualService{}
+	return snapshot.restore("VirtualService", saved, func() error {
+		obj, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), saved.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("VirtualService %s.%s get query error: %w", saved.Name, canary.Namespace, err)
+		}
+		clone := obj.DeepCopy()
+		clone.Spec = saved.Spec
+		clone.Labels = saved.Labels
+		clone.Annotations = saved.Annotations
+		if _, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("VirtualService %s.%s update error: %w", saved.Name, canary.Namespace, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// renderServiceTemplates returns a copy of the canary with the service hosts and gateways
+// rendered from the canary and namespace labels, e.g. `{{ namespaceLabel "team" }}-gateway`
+func (ir *IstioRouter) renderServiceTemplates(canary *flaggerv1.Canary) (*flaggerv1.Canary, error) {
+	if !hasTemplate(canary.Spec.Service.Hosts) && !hasTemplate(canary.Spec.Service.Gateways) {
+		return canary, nil
+	}
+
+	ns, err := ir.kubeClient.CoreV1().Namespaces().Get(context.TODO(), canary.Namespace, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("namespace %s get query error: %w", canary.Namespace, err)
+	}
+
+	funcs := template.FuncMap{
+		"name":      func() string { return canary.Name },
+		"namespace": func() string { return canary.Namespace },
+		"label": func(key string) (string, error) {
+			if v, ok := canary.Labels[key]; ok {
+				return v, nil
+			}
+			return "", fmt.Errorf("canary label %s not found", key)
+		},
+		"namespaceLabel": func(key string) (string, error) {
+			if v, ok := ns.Labels[key]; ok {
+				return v, nil
+			}
+			return "", fmt.Errorf("namespace label %s not found", key)
+		},
+	}
+
+	clone := canary.DeepCopy()
+	if clone.Spec.Service.Hosts, err = renderTemplates(clone.Spec.Service.Hosts, funcs); err != nil {
+		return nil, fmt.Errorf("hosts template error: %w", err)
+	}
+	if clone.Spec.Service.Gateways, err = renderTemplates(clone.Spec.Service.Gateways, funcs); err != nil {
+		return nil, fmt.Errorf("gateways template error: %w", err)
+	}
+	return clone, nil
+}
+
+func hasTemplate(values []string) bool {
+	for _, v := range values {
+		if strings.Contains(v, "{{") {
+			return true
+		}
+	}
+	return false
+}
+
+func renderTemplates(values []string, funcs template.FuncMap) ([]string, error) {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		t, err := template.New("tmpl").Funcs(funcs).Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		var b bytes.Buffer
+		if err := t.Execute(&b, nil); err != nil {
+			return nil, err
+		}
+		result = append(result, b.String())
+	}
+	return result, nil
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -185,6 +186,100 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 	})
 }
 
+func TestIstioRouter_SourceWeights(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	mocks.canary.Spec.Analysis.SourceWeights = []v1beta1.CanarySourceWeight{
+		{Gateway: "public-gateway.istio-system.svc.cluster.local", MaxWeight: 5},
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+	for _, match := range vs.Spec.Http[0].Match {
+		assert.Equal(t, []string{"public-gateway.istio-system.svc.cluster.local"}, match.Gateways)
+	}
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	require.NoError(t, err)
+
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+
+	// gateway traffic is capped
+	assert.Equal(t, 95, vs.Spec.Http[0].Route[0].Weight)
+	assert.Equal(t, 5, vs.Spec.Http[0].Route[1].Weight)
+
+	// mesh traffic is not
+	assert.Equal(t, 60, vs.Spec.Http[1].Route[0].Weight)
+	assert.Equal(t, 40, vs.Spec.Http[1].Route[1].Weight)
+
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+
+	// gateway traffic is mirrored like the mesh traffic
+	mocks.canary.Spec.Analysis.MirrorWeight = 50
+	err = router.SetRoutes(mocks.canary, 100, 0, true)
+	require.NoError(t, err)
+
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+	for _, route := range vs.Spec.Http {
+		require.NotNil(t, route.Mirror)
+		assert.Equal(t, "podinfo-canary", route.Mirror.Host)
+		assert.Equal(t, float64(50), route.MirrorPercentage.Value)
+	}
+}
+
+func TestIstioRouter_TCP(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+	mocks.canary.Spec.Service.Protocol = &v1beta1.CanaryProtocol{Type: "tcp"}
+
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, vs.Spec.Http)
+	require.Len(t, vs.Spec.Tcp, 1)
+	require.Len(t, vs.Spec.Tcp[0].Route, 2)
+	assert.Equal(t, "podinfo-primary", vs.Spec.Tcp[0].Route[0].Destination.Host)
+	assert.Equal(t, 100, vs.Spec.Tcp[0].Route[0].Weight)
+
+	require.NoError(t, router.SetRoutes(mocks.canary, 60, 40, false))
+
+	p, c, m, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+	assert.False(t, m)
+
+	// the weights are kept on reconciliation
+	require.NoError(t, router.Reconcile(mocks.canary))
+	p, c, _, err = router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+}
+
 func TestIstioRouter_GetRoutes(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &IstioRouter{
@@ -261,6 +356,196 @@ func TestIstioRouter_HTTPRequestHeaders(t *testing.T) {
 	assert.Equal(t, "token", vs.Spec.Http[0].Headers.Response.Remove[0])
 }
 
+func TestIstioRouter_CanaryHeaders(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.CanaryHeaders = &v1beta1.CanaryHeaders{Request: true, Response: true, Baggage: true}
+	canary.Status.AnalysisRunID = "run-1"
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(canary, 90, 10, false)
+	require.NoError(t, err)
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	route := vs.Spec.Http[0].Route
+	require.Len(t, route, 2)
+	assert.Nil(t, route[0].Headers)
+	require.NotNil(t, route[1].Headers)
+	assert.Equal(t, "true", route[1].Headers.Request.Set["x-canary"])
+	assert.Equal(t, "run-1", route[1].Headers.Request.Set["x-canary-run"])
+	assert.Equal(t, "canary=true,canary.run=run-1", route[1].Headers.Request.Add["baggage"])
+	assert.Equal(t, "true", route[1].Headers.Response.Set["x-canary"])
+}
+
+func TestIstioRouter_SessionAffinity(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Analysis.SessionAffinity = &v1beta1.CanarySessionAffinity{CookieName: "podinfo", MaxAge: 600}
+	canary.Status.AnalysisRunID = "run-1"
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(canary, 90, 10, false)
+	require.NoError(t, err)
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+
+	// clients holding the cookie are pinned to the canary
+	require.Len(t, vs.Spec.Http[0].Route, 1)
+	assert.Equal(t, "podinfo-canary", vs.Spec.Http[0].Route[0].Destination.Host)
+	assert.Equal(t, 100, vs.Spec.Http[0].Route[0].Weight)
+	assert.Equal(t, "^(.*?;)?(podinfo=run-1)(;.*)?$", vs.Spec.Http[0].Match[0].Headers["cookie"].Regex)
+
+	// the canary responses of the weighted route set the cookie
+	route := vs.Spec.Http[1].Route
+	assert.Nil(t, route[0].Headers)
+	require.NotNil(t, route[1].Headers)
+	assert.Equal(t, "podinfo=run-1; Max-Age=600", route[1].Headers.Response.Add["Set-Cookie"])
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 90, p)
+	assert.Equal(t, 10, c)
+
+	// the reconciliation during the analysis keeps the affinity route and the weights
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+
+	p, c, _, err = router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 90, p)
+	assert.Equal(t, 10, c)
+
+	// the cookie is ignored once the traffic is routed back to the primary
+	err = router.SetRoutes(canary, 100, 0, false)
+	require.NoError(t, err)
+
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 1)
+	assert.Nil(t, vs.Spec.Http[0].Route[1].Headers)
+}
+
+func TestIstioRouter_CanaryTrafficPolicy(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	mocks.canary.Spec.Service.TrafficPolicy = &istiov1alpha3.TrafficPolicy{
+		TLS: &istiov1alpha3.TLSSettings{Mode: istiov1alpha3.TLSmodeMutual},
+		OutlierDetection: &istiov1alpha3.OutlierDetection{
+			ConsecutiveErrors: 10,
+		},
+	}
+	mocks.canary.Spec.Service.CanaryTrafficPolicy = &istiov1alpha3.TrafficPolicy{
+		OutlierDetection: &istiov1alpha3.OutlierDetection{
+			ConsecutiveErrors: 2,
+		},
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	primaryDR, err := mocks.meshClient.NetworkingV1alpha3().DestinationRules("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), primaryDR.Spec.TrafficPolicy.OutlierDetection.ConsecutiveErrors)
+	assert.Equal(t, istiov1alpha3.TLSmodeMutual, primaryDR.Spec.TrafficPolicy.TLS.Mode)
+
+	canaryDR, err := mocks.meshClient.NetworkingV1alpha3().DestinationRules("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), canaryDR.Spec.TrafficPolicy.OutlierDetection.ConsecutiveErrors)
+	assert.Equal(t, istiov1alpha3.TLSmodeMutual, canaryDR.Spec.TrafficPolicy.TLS.Mode)
+
+	// remove the overrides
+	mocks.canary.Spec.Service.CanaryTrafficPolicy = nil
+	err = router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	canaryDR, err = mocks.meshClient.NetworkingV1alpha3().DestinationRules("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), canaryDR.Spec.TrafficPolicy.OutlierDetection.ConsecutiveErrors)
+}
+
+func TestIstioRouter_AnalysisTrafficPolicy(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	mocks.canary.Spec.Service.TrafficPolicy = &istiov1alpha3.TrafficPolicy{
+		OutlierDetection: &istiov1alpha3.OutlierDetection{
+			ConsecutiveErrors: 10,
+		},
+	}
+	mocks.canary.Spec.Service.AnalysisTrafficPolicy = &istiov1alpha3.TrafficPolicy{
+		OutlierDetection: &istiov1alpha3.OutlierDetection{
+			ConsecutiveErrors:  1,
+			MaxEjectionPercent: 100,
+		},
+	}
+
+	// analysis not started
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	canaryDR, err := mocks.meshClient.NetworkingV1alpha3().DestinationRules("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), canaryDR.Spec.TrafficPolicy.OutlierDetection.ConsecutiveErrors)
+
+	// analysis running
+	mocks.canary.Status.Phase = v1beta1.CanaryPhaseProgressing
+	err = router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	canaryDR, err = mocks.meshClient.NetworkingV1alpha3().DestinationRules("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), canaryDR.Spec.TrafficPolicy.OutlierDetection.ConsecutiveErrors)
+	assert.Equal(t, int32(100), canaryDR.Spec.TrafficPolicy.OutlierDetection.MaxEjectionPercent)
+
+	primaryDR, err := mocks.meshClient.NetworkingV1alpha3().DestinationRules("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), primaryDR.Spec.TrafficPolicy.OutlierDetection.ConsecutiveErrors)
+
+	// promotion started
+	mocks.canary.Status.Phase = v1beta1.CanaryPhasePromoting
+	err = router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	canaryDR, err = mocks.meshClient.NetworkingV1alpha3().DestinationRules("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), canaryDR.Spec.TrafficPolicy.OutlierDetection.ConsecutiveErrors)
+}
+
 func TestIstioRouter_CORS(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &IstioRouter{
@@ -331,6 +616,63 @@ func TestIstioRouter_ABTest(t *testing.T) {
 	assert.Nil(t, mirror)
 }
 
+func TestIstioRouter_ABTestGeo(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	mocks.abtest.Spec.Analysis.Geo = &v1beta1.CanaryGeoMatch{
+		Header:    "CF-IPCountry",
+		Countries: []string{"NZ", "IE"},
+	}
+
+	err := router.Reconcile(mocks.abtest)
+	require.NoError(t, err)
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+
+	for _, match := range vs.Spec.Http[0].Match {
+		assert.Equal(t, "^(NZ|IE)$", match.Headers["cf-ipcountry"].Regex)
+	}
+	assert.Len(t, mocks.abtest.Spec.Analysis.Match[0].Headers, 1)
+}
+
+func TestIstioRouter_ABTestGRPC(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	mocks.abtest.Spec.Analysis.GRPCMatch = []v1beta1.CanaryGRPCMatch{{
+		Service:  "podinfo.v1.Echo",
+		Method:   "Say",
+		Metadata: map[string]istiov1alpha1.StringMatch{"X-Tenant": {Exact: "insider"}},
+	}}
+
+	err := router.Reconcile(mocks.abtest)
+	require.NoError(t, err)
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+
+	// the gRPC condition is added to the HTTP header match
+	require.Len(t, vs.Spec.Http[0].Match, 2)
+	grpcMatch := vs.Spec.Http[0].Match[1]
+	assert.Equal(t, "insider", grpcMatch.Headers["x-tenant"].Exact)
+	assert.Equal(t, "/podinfo.v1.Echo/Say", grpcMatch.Uri.Exact)
+	assert.Len(t, mocks.abtest.Spec.Analysis.Match, 1)
+}
+
 func TestIstioRouter_GatewayPort(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &IstioRouter{
@@ -395,6 +737,24 @@ func TestIstioRouter_Delegate(t *testing.T) {
 		err := router.Reconcile(mocks.canary)
 		require.Error(t, err)
 	})
+
+	t.Run("tcp", func(t *testing.T) {
+		mocks := newFixture(nil)
+		mocks.canary.Spec.Service.Hosts = []string{}
+		mocks.canary.Spec.Service.Gateways = []string{}
+		mocks.canary.Spec.Service.Delegation = true
+		mocks.canary.Spec.Service.Protocol = &v1beta1.CanaryProtocol{Type: "tcp"}
+
+		router := &IstioRouter{
+			logger:        mocks.logger,
+			flaggerClient: mocks.flaggerClient,
+			istioClient:   mocks.meshClient,
+			kubeClient:    mocks.kubeClient,
+		}
+
+		err := router.Reconcile(mocks.canary)
+		require.EqualError(t, err, "reconcileVirtualService failed: VirtualService podinfo.default cannot have TCP routes when delegation enabled")
+	})
 }
 
 func TestIstioRouter_Finalize(t *testing.T) {
@@ -564,3 +924,39 @@ func TestIstioRouter_Match(t *testing.T) {
 	assert.Len(t, vs.Spec.Http[1].Match, 1) // check for abtest-primary
 	require.Equal(t, vs.Spec.Http[1].Match[0].Uri.Prefix, "/podinfo")
 }
+
+func TestIstioRouter_ServiceTemplates(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	_, err := mocks.kubeClient.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "default",
+			Labels: map[string]string{"team": "payments", "env": "staging"},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	canary := mocks.canary.DeepCopy()
+	canary.Labels = map[string]string{"tier": "public"}
+	canary.Spec.Service.Gateways = []string{`istio-system/{{ namespaceLabel "team" }}-{{ label "tier" }}`}
+	canary.Spec.Service.Hosts = []string{`{{ name }}.{{ namespaceLabel "env" }}.example.com`}
+
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"istio-system/payments-public"}, vs.Spec.Gateways)
+	assert.Equal(t, []string{"podinfo.staging.example.com", "podinfo"}, vs.Spec.Hosts)
+
+	// a missing label fails the reconciliation
+	canary.Spec.Service.Gateways = []string{`{{ namespaceLabel "region" }}-gateway`}
+	err = router.Reconcile(canary)
+	require.Error(t, err)
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	kedav1alpha1 "github.com/fluxcd/flagger/pkg/apis/keda/v1alpha1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+const (
+	primaryWeightAnnotation = "flagger.app/primary-weight"
+	canaryWeightAnnotation  = "flagger.app/canary-weight"
+
+	// KEDA default max replica count
+	defaultMaxReplicaCount = 100
+)
+
+// KedaRouter splits the consumers of a queue worker between primary and canary
+// by scaling the workloads with KEDA, the canary weight is the ratio of canary consumers
+type KedaRouter struct {
+	flaggerClient clientset.Interface
+	logger        *zap.SugaredLogger
+}
+
+// Reconcile validates that the workload is scaled by a KEDA ScaledObject,
+// the primary ScaledObject is generated by the canary controller
+func (kr *KedaRouter) Reconcile(canary *flaggerv1.Canary) error {
+	if canary.Spec.TargetRef.Kind != "Deployment" {
+		return fmt.Errorf("the %s provider doesn't support %s targets", flaggerv1.KedaProvider, canary.Spec.TargetRef.Kind)
+	}
+	if canary.Spec.AutoscalerRef == nil || canary.Spec.AutoscalerRef.Kind != "ScaledObject" {
+		return fmt.Errorf("the %s provider requires an autoscalerRef of kind ScaledObject", flaggerv1.KedaProvider)
+	}
+	_, _, err := kr.getScaledObjects(canary)
+	return err
+}
+
+// GetRoutes returns the weights recorded on the primary ScaledObject
+func (kr *KedaRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	_, primary, err := kr.getScaledObjects(canary)
+	if err != nil {
+		return
+	}
+
+	primaryWeight = 100
+	if v, ok := primary.Annotations[primaryWeightAnnotation]; ok {
+		if primaryWeight, err = strconv.Atoi(v); err != nil {
+			err = fmt.Errorf("ScaledObject %s.%s invalid %s annotation: %w", primary.Name, primary.Namespace, primaryWeightAnnotation, err)
+			return
+		}
+	}
+	if v, ok := primary.Annotations[canaryWeightAnnotation]; ok {
+		if canaryWeight, err = strconv.Atoi(v); err != nil {
+			err = fmt.Errorf("ScaledObject %s.%s invalid %s annotation: %w", primary.Name, primary.Namespace, canaryWeightAnnotation, err)
+			return
+		}
+	}
+	return
+}
+
+// SetRoutes pins the canary consumers to its share of the total consumers
+// and caps the primary autoscaling to the remaining consumers
+func (kr *KedaRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	so, primary, err := kr.getScaledObjects(canary)
+	if err != nil {
+		return err
+	}
+
+	total := consumersTotal(canary, so)
+	canaryReplicas := consumersShare(total, primaryWeight, canaryWeight)
+
+	primaryClone := primary.DeepCopy()
+	if primaryClone.Annotations == nil {
+		primaryClone.Annotations = make(map[string]string)
+	}
+	primaryClone.Annotations[primaryWeightAnnotation] = strconv.Itoa(primaryWeight)
+	primaryClone.Annotations[canaryWeightAnnotation] = strconv.Itoa(canaryWeight)
+	if primaryWeight == 0 {
+		primaryClone.Annotations[kedav1alpha1.PausedReplicasAnnotation] = "0"
+	} else {
+		delete(primaryClone.Annotations, kedav1alpha1.PausedReplicasAnnotation)
+		maxReplicas := total - canaryReplicas
+		primaryClone.Spec.MaxReplicaCount = &maxReplicas
+		if primaryClone.Spec.MinReplicaCount != nil && *primaryClone.Spec.MinReplicaCount > maxReplicas {
+			primaryClone.Spec.MinReplicaCount = &maxReplicas
+		}
+	}
+
+	_, err = kr.flaggerClient.KedaV1alpha1().ScaledObjects(canary.Namespace).Update(context.TODO(), primaryClone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s update error: %w", primaryClone.Name, primaryClone.Namespace, err)
+	}
+
+	// the canary ScaledObject is owned by the user, only its annotations are patched
+	if err := kr.pause(so, &canaryReplicas); err != nil {
+		return err
+	}
+
+	kr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Debugf("Consumers primary: %d canary: %d", total-canaryReplicas, canaryReplicas)
+	return nil
+}
+
+// Finalize resumes the autoscaling of the canary ScaledObject
+func (kr *KedaRouter) Finalize(canary *flaggerv1.Canary) error {
+	if canary.Spec.AutoscalerRef == nil {
+		return nil
+	}
+	so, err := kr.flaggerClient.KedaV1alpha1().ScaledObjects(canary.Namespace).Get(context.TODO(), canary.Spec.AutoscalerRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s get query error: %w", canary.Spec.AutoscalerRef.Name, canary.Namespace, err)
+	}
+	return kr.pause(so, nil)
+}
+
+func (kr *KedaRouter) getScaledObjects(canary *flaggerv1.Canary) (*kedav1alpha1.ScaledObject, *kedav1alpha1.ScaledObject, error) {
+	if canary.Spec.AutoscalerRef == nil {
+		return nil, nil, fmt.Errorf("autoscalerRef is empty")
+	}
+	name := canary.Spec.AutoscalerRef.Name
+	so, err := kr.flaggerClient.KedaV1alpha1().ScaledObjects(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("ScaledObject %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+	primaryName := fmt.Sprintf("%s-primary", name)
+	primary, err := kr.flaggerClient.KedaV1alpha1().ScaledObjects(canary.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("ScaledObject %s.%s get query error: %w", primaryName, canary.Namespace, err)
+	}
+	return so, primary, nil
+}
+
+// pause sets the paused replicas of the ScaledObject, a nil value resumes the autoscaling
+func (kr *KedaRouter) pause(so *kedav1alpha1.ScaledObject, replicas *int32) error {
+	current, paused := so.Annotations[kedav1alpha1.PausedReplicasAnnotation]
+	var value interface{}
+	if replicas != nil {
+		if paused && current == strconv.Itoa(int(*replicas)) {
+			return nil
+		}
+		value = strconv.Itoa(int(*replicas))
+	} else if !paused {
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				kedav1alpha1.PausedReplicasAnnotation: value,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s patch marshal error: %w", so.Name, so.Namespace, err)
+	}
+	_, err = kr.flaggerClient.KedaV1alpha1().ScaledObjects(so.Namespace).Patch(context.TODO(), so.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s patch error: %w", so.Name, so.Namespace, err)
+	}
+	return nil
+}
+
+// consumersTotal returns the max number of consumers of primary and canary,
+// with partitions set a consumer past the partitions count would sit idle
+func consumersTotal(canary *flaggerv1.Canary, so *kedav1alpha1.ScaledObject) int32 {
+	if canary.Spec.Worker != nil && canary.Spec.Worker.Partitions > 0 {
+		return canary.Spec.Worker.Partitions
+	}
+	if so.Spec.MaxReplicaCount != nil && *so.Spec.MaxReplicaCount > 0 {
+		return *so.Spec.MaxReplicaCount
+	}
+	return defaultMaxReplicaCount
+}
+
+// consumersShare returns the canary consumers for the given weights,
+// keeping at least one consumer on each side that has a weight
+func consumersShare(total int32, primaryWeight int, canaryWeight int) int32 {
+	if canaryWeight <= 0 {
+		return 0
+	}
+	if primaryWeight <= 0 {
+		return total
+	}
+	share := int32(math.Round(float64(total) * float64(canaryWeight) / float64(primaryWeight+canaryWeight)))
+	if share < 1 {
+		share = 1
+	}
+	if share > total-1 {
+		share = total - 1
+	}
+	return share
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	kedav1alpha1 "github.com/fluxcd/flagger/pkg/apis/keda/v1alpha1"
+)
+
+func newKedaTestScaledObjects(t *testing.T, mocks fixture) {
+	minReplicas, maxReplicas := int32(1), int32(10)
+	for _, name := range []string{"podinfo", "podinfo-primary"} {
+		so := &kedav1alpha1.ScaledObject{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: kedav1alpha1.ScaledObjectSpec{
+				ScaleTargetRef:  &kedav1alpha1.ScaleTarget{Name: name},
+				MinReplicaCount: &minReplicas,
+				MaxReplicaCount: &maxReplicas,
+				Triggers: []kedav1alpha1.ScaleTriggers{
+					{Type: "kafka", Metadata: map[string]string{"topic": "orders"}},
+				},
+			},
+		}
+		_, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Create(context.TODO(), so, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	mocks.canary.Spec.Provider = flaggerv1.KedaProvider
+	mocks.canary.Spec.AutoscalerRef = &flaggerv1.CrossNamespaceObjectReference{
+		APIVersion: "keda.sh/v1alpha1",
+		Kind:       "ScaledObject",
+		Name:       "podinfo",
+	}
+}
+
+func TestKedaRouter_Routes(t *testing.T) {
+	mocks := newFixture(nil)
+	newKedaTestScaledObjects(t, mocks)
+	router := &KedaRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+	}
+
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+
+	require.NoError(t, router.SetRoutes(mocks.canary, 70, 30, false))
+
+	p, c, _, err = router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 70, p)
+	assert.Equal(t, 30, c)
+
+	so, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "3", so.Annotations[kedav1alpha1.PausedReplicasAnnotation])
+	assert.Equal(t, int32(10), *so.Spec.MaxReplicaCount)
+
+	primary, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), *primary.Spec.MaxReplicaCount)
+
+	// with partitions the consumers are capped to one per partition
+	mocks.canary.Spec.Worker = &flaggerv1.CanaryWorker{Partitions: 4}
+	require.NoError(t, router.SetRoutes(mocks.canary, 90, 10, false))
+
+	so, err = mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "1", so.Annotations[kedav1alpha1.PausedReplicasAnnotation])
+
+	primary, err = mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), *primary.Spec.MaxReplicaCount)
+
+	require.NoError(t, router.Finalize(mocks.canary))
+
+	so, err = mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, so.Annotations, kedav1alpha1.PausedReplicasAnnotation)
+}
+
+func TestKedaRouter_ConsumersShare(t *testing.T) {
+	tests := []struct {
+		total         int32
+		primaryWeight int
+		canaryWeight  int
+		share         int32
+	}{
+		{total: 10, primaryWeight: 100, canaryWeight: 0, share: 0},
+		{total: 10, primaryWeight: 95, canaryWeight: 5, share: 1},
+		{total: 10, primaryWeight: 50, canaryWeight: 50, share: 5},
+		{total: 10, primaryWeight: 1, canaryWeight: 99, share: 9},
+		{total: 10, primaryWeight: 0, canaryWeight: 100, share: 10},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.share, consumersShare(tt.total, tt.primaryWeight, tt.canaryWeight))
+	}
+}
@@ -45,23 +45,30 @@ type KubernetesDefaultRouter struct {
 	ports         map[string]int32
 }
 
-// Initialize creates the primary and canary services
+// Initialize creates the primary and canary services and monitors
 func (c *KubernetesDefaultRouter) Initialize(canary *flaggerv1.Canary) error {
 	_, primaryName, canaryName := canary.GetServiceNames()
 
+	var drift driftCollector
+
 	// canary svc
-	err := c.reconcileService(canary, canaryName, c.labelValue, canary.Spec.Service.Canary)
+	err := drift.check(c.reconcileService(canary, canaryName, c.labelValue, canary.Spec.Service.Canary))
 	if err != nil {
 		return fmt.Errorf("reconcileService failed: %w", err)
 	}
 
 	// primary svc
-	err = c.reconcileService(canary, primaryName, fmt.Sprintf("%s-primary", c.labelValue), canary.Spec.Service.Primary)
+	err = drift.check(c.reconcileService(canary, primaryName, fmt.Sprintf("%s-primary", c.labelValue), canary.Spec.Service.Primary))
 	if err != nil {
 		return fmt.Errorf("reconcileService failed: %w", err)
 	}
 
-	return nil
+	// primary and canary monitors
+	if err := c.reconcileMonitors(canary); err != nil {
+		return fmt.Errorf("reconcileMonitors failed: %w", err)
+	}
+
+	return drift.err()
 }
 
 // Reconcile creates or updates the main service
@@ -89,6 +96,13 @@ func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, nam
 	portName := canary.Spec.Service.PortName
 	if portName == "" {
 		portName = "http"
+		// Istio selects the protocol based on the port name
+		if canary.IsTCP() {
+			portName = "tcp"
+		}
+		if canary.IsGRPC() {
+			portName = "grpc"
+		}
 	}
 
 	targetPort := intstr.IntOrString{
@@ -114,8 +128,29 @@ func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, nam
 		},
 	}
 
+	// set the declared ports
+	declared := make(map[string]bool, len(canary.Spec.Service.Ports))
+	for _, p := range canary.Spec.Service.Ports {
+		tp := p.TargetPort
+		if tp.String() == "0" {
+			tp = intstr.FromInt(int(p.Port))
+		}
+		svcSpec.Ports = append(svcSpec.Ports, corev1.ServicePort{
+			Name:       p.Name,
+			Protocol:   corev1.ProtocolTCP,
+			Port:       p.Port,
+			TargetPort: tp,
+		})
+		declared[p.Name] = true
+		declared[fmt.Sprint(p.Port)] = true
+	}
+
 	// set additional ports
 	for n, p := range c.ports {
+		// the declared ports take precedence over the discovered ones
+		if declared[n] || declared[fmt.Sprint(p)] {
+			continue
+		}
 		cp := corev1.ServicePort{
 			Name:     n,
 			Protocol: corev1.ProtocolTCP,
@@ -144,13 +179,29 @@ func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, nam
 
 	// create service if it doesn't exists
 	svc, err := c.kubeClient.CoreV1().Services(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("service %s get query error: %w", name, err)
+	}
+
+	// the apex service keeps the type of the service it takes over unless one is specified
+	if apexName, _, _ := canary.GetServiceNames(); name == apexName {
+		serviceType := canary.Spec.Service.Type
+		if serviceType == "" && svc != nil && svc.Spec.Type != "" {
+			serviceType = svc.Spec.Type
+		}
+		c.setServiceType(canary, &svcSpec, serviceType)
+	}
+
+	// the node ports copied from the existing service are not part of the desired spec
+	desiredSpec := svcSpec.DeepCopy()
+
 	if errors.IsNotFound(err) {
 		svc = &corev1.Service{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:        name,
 				Namespace:   canary.Namespace,
 				Labels:      metadata.Labels,
-				Annotations: filterMetadata(metadata.Annotations),
+				Annotations: withOwnership(filterMetadata(metadata.Annotations), canary, desiredSpec),
 				OwnerReferences: []metav1.OwnerReference{
 					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
 						Group:   flaggerv1.SchemeGroupVersion.Group,
@@ -170,8 +221,6 @@ func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, nam
 		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 			Infof("Service %s.%s created", svc.GetName(), canary.Namespace)
 		return nil
-	} else if err != nil {
-		return fmt.Errorf("service %s get query error: %w", name, err)
 	}
 
 	// update existing service pod selector and ports
@@ -181,33 +230,49 @@ func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, nam
 		}
 
 		// copy node ports from existing service
-		for _, port := range svc.Spec.Ports {
-			for i, servicePort := range svcSpec.Ports {
-				if port.Name == servicePort.Name && port.NodePort > 0 {
-					svcSpec.Ports[i].NodePort = port.NodePort
-					break
+		if svcSpec.Type == corev1.ServiceTypeNodePort || svcSpec.Type == corev1.ServiceTypeLoadBalancer {
+			for _, port := range svc.Spec.Ports {
+				for i, servicePort := range svcSpec.Ports {
+					if port.Name == servicePort.Name && port.NodePort > 0 {
+						svcSpec.Ports[i].NodePort = port.NodePort
+						break
+					}
 				}
 			}
 		}
 
 		updateService := false
+		drifted := false
 		svcClone := svc.DeepCopy()
+		_, owned := c.isOwnedByCanary(svc, canary.Name)
 
 		portsDiff := cmp.Diff(svcSpec.Ports, svc.Spec.Ports, cmpopts.SortSlices(sortPorts))
-		selectorsDiff := cmp.Diff(svcSpec.Selector, svc.Spec.Selector)
-		if portsDiff != "" || selectorsDiff != "" {
+		selectorsDiff := cmp.Diff(svcSpec.Selector, svc.Spec.Selector, cmpopts.EquateEmpty())
+		typeDiff := svcSpec.Type != svc.Spec.Type || svcSpec.ExternalName != svc.Spec.ExternalName
+		if portsDiff != "" || selectorsDiff != "" || typeDiff {
+			// detect drift only on the services created by Flagger
+			if owned {
+				drifted, err = checkDrift(canary, "Service", svc, desiredSpec)
+				if err != nil {
+					return err
+				}
+			}
 			svcClone.Spec.Ports = svcSpec.Ports
 			svcClone.Spec.Selector = svcSpec.Selector
+			if typeDiff {
+				setServiceTypeFields(&svcClone.Spec, svcSpec)
+			}
 			updateService = true
 		}
 
 		// update annotations and labels only if the service has been created by Flagger
-		if _, owned := c.isOwnedByCanary(svc, canary.Name); owned {
+		if owned {
 			if svc.ObjectMeta.Annotations == nil {
 				svc.ObjectMeta.Annotations = make(map[string]string)
 			}
-			if diff := cmp.Diff(filterMetadata(metadata.Annotations), svc.ObjectMeta.Annotations); diff != "" {
-				svcClone.ObjectMeta.Annotations = filterMetadata(metadata.Annotations)
+			annotations := withOwnership(filterMetadata(metadata.Annotations), canary, desiredSpec)
+			if diff := cmp.Diff(annotations, svc.ObjectMeta.Annotations); diff != "" {
+				svcClone.ObjectMeta.Annotations = annotations
 				updateService = true
 			}
 			if diff := cmp.Diff(metadata.Labels, svc.ObjectMeta.Labels); diff != "" {
@@ -227,12 +292,61 @@ func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, nam
 			}
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Infof("Service %s updated", svc.GetName())
+			if drifted {
+				return repairedDrift("Service", svc)
+			}
 		}
 	}
 
 	return nil
 }
 
+// setServiceType sets the type of the apex service, an ExternalName apex service
+// resolves to the primary service instead of selecting the primary pods
+func (c *KubernetesDefaultRouter) setServiceType(canary *flaggerv1.Canary, spec *corev1.ServiceSpec, serviceType corev1.ServiceType) {
+	switch serviceType {
+	case corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+		spec.Type = serviceType
+	case corev1.ServiceTypeExternalName:
+		_, primaryName, _ := canary.GetServiceNames()
+		spec.Type = serviceType
+		spec.ExternalName = fmt.Sprintf("%s.%s.svc.cluster.local", primaryName, canary.Namespace)
+		spec.Selector = nil
+	default:
+		spec.Type = corev1.ServiceTypeClusterIP
+	}
+}
+
+// setServiceTypeFields switches the live service to the desired type, the fields of the
+// previous type that the API server rejects for the new one are cleared, while the load balancer
+// and health check settings of the live service are kept
+func setServiceTypeFields(live *corev1.ServiceSpec, desired corev1.ServiceSpec) {
+	live.Type = desired.Type
+	live.ExternalName = desired.ExternalName
+
+	if desired.Type != corev1.ServiceTypeLoadBalancer {
+		live.LoadBalancerIP = ""
+		live.LoadBalancerSourceRanges = nil
+		live.LoadBalancerClass = nil
+		live.AllocateLoadBalancerNodePorts = nil
+		live.HealthCheckNodePort = 0
+	}
+	if desired.Type != corev1.ServiceTypeLoadBalancer && desired.Type != corev1.ServiceTypeNodePort {
+		live.ExternalTrafficPolicy = ""
+	}
+	if desired.Type == corev1.ServiceTypeExternalName {
+		live.ClusterIP = ""
+		live.ClusterIPs = nil
+		live.IPFamilies = nil
+		live.IPFamilyPolicy = nil
+		live.InternalTrafficPolicy = nil
+		live.SessionAffinity = ""
+	} else if live.ClusterIP == "" {
+		// let the API server allocate the cluster IP of a service converted from ExternalName
+		live.ClusterIPs = nil
+	}
+}
+
 // Finalize reverts the apex router if not owned by the Flagger controller.
 func (c *KubernetesDefaultRouter) Finalize(canary *flaggerv1.Canary) error {
 	apexName, _, _ := canary.GetServiceNames()
@@ -23,6 +23,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/stretchr/testify/assert"
@@ -58,6 +59,41 @@ func TestServiceRouter_Create(t *testing.T) {
 	assert.Equal(t, int32(9898), primarySvc.Spec.Ports[0].Port)
 }
 
+func TestServiceRouter_Ports(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		ports:         map[string]int32{"grpc": 9999, "metrics": 9797},
+	}
+
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.Ports = []flaggerv1.CanaryServicePort{
+		{Name: "grpc", Port: 9999, TargetPort: intstr.FromString("grpc-api")},
+		{Name: "http-admin", Port: 8080},
+	}
+
+	err := router.Initialize(canary)
+	require.NoError(t, err)
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	for _, name := range []string{"podinfo", "podinfo-canary", "podinfo-primary"} {
+		svc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+
+		ports := make(map[string]corev1.ServicePort)
+		for _, p := range svc.Spec.Ports {
+			ports[p.Name] = p
+		}
+		require.Len(t, ports, 4, name)
+		assert.Equal(t, intstr.FromString("grpc-api"), ports["grpc"].TargetPort)
+		assert.Equal(t, intstr.FromInt(8080), ports["http-admin"].TargetPort)
+		assert.Equal(t, int32(9797), ports["metrics"].Port)
+	}
+}
+
 func TestServiceRouter_Update(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &KubernetesDefaultRouter{
@@ -92,6 +128,89 @@ func TestServiceRouter_Update(t *testing.T) {
 	assert.Equal(t, "grpc", canarySvc.Spec.Ports[0].Name)
 }
 
+func TestServiceRouter_LoadBalancerTakeover(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+	}
+
+	_, err := mocks.kubeClient.CoreV1().Services("default").Create(context.TODO(), &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "podinfo",
+			Namespace:   "default",
+			Annotations: map[string]string{"metallb.universe.tf/loadBalancerIPs": "192.168.1.100"},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:                  corev1.ServiceTypeLoadBalancer,
+			Selector:              map[string]string{"app": "podinfo"},
+			LoadBalancerIP:        "192.168.1.100",
+			ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+			HealthCheckNodePort:   32000,
+			Ports: []corev1.ServicePort{
+				{Name: "http", Protocol: corev1.ProtocolTCP, Port: 9898, NodePort: 31000},
+			},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, router.Initialize(mocks.canary))
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	apexSvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, apexSvc.Spec.Type)
+	assert.Equal(t, "podinfo-primary", apexSvc.Spec.Selector["app"])
+	assert.Equal(t, "192.168.1.100", apexSvc.Spec.LoadBalancerIP)
+	assert.Equal(t, corev1.ServiceExternalTrafficPolicyTypeLocal, apexSvc.Spec.ExternalTrafficPolicy)
+	assert.Equal(t, int32(32000), apexSvc.Spec.HealthCheckNodePort)
+	assert.Equal(t, int32(31000), apexSvc.Spec.Ports[0].NodePort)
+	assert.Equal(t, "192.168.1.100", apexSvc.Annotations["metallb.universe.tf/loadBalancerIPs"])
+
+	primarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ServiceTypeClusterIP, primarySvc.Spec.Type)
+
+	// switch to ClusterIP
+	mocks.canary.Spec.Service.Type = corev1.ServiceTypeClusterIP
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	apexSvc, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ServiceTypeClusterIP, apexSvc.Spec.Type)
+	assert.Empty(t, apexSvc.Spec.LoadBalancerIP)
+	assert.Empty(t, apexSvc.Spec.ExternalTrafficPolicy)
+	assert.Equal(t, int32(0), apexSvc.Spec.HealthCheckNodePort)
+	assert.Equal(t, int32(0), apexSvc.Spec.Ports[0].NodePort)
+}
+
+func TestServiceRouter_ExternalName(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+	}
+	mocks.canary.Spec.Service.Type = corev1.ServiceTypeExternalName
+
+	require.NoError(t, router.Initialize(mocks.canary))
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	apexSvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ServiceTypeExternalName, apexSvc.Spec.Type)
+	assert.Equal(t, "podinfo-primary.default.svc.cluster.local", apexSvc.Spec.ExternalName)
+	assert.Empty(t, apexSvc.Spec.Selector)
+
+	// no changes on subsequent reconciliations
+	require.NoError(t, router.Reconcile(mocks.canary))
+}
+
 func TestServiceRouter_Undo(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &KubernetesDefaultRouter{
@@ -118,7 +237,10 @@ func TestServiceRouter_Undo(t *testing.T) {
 
 	// undo changes
 	err = router.Initialize(mocks.canary)
-	require.NoError(t, err)
+	var drift *DriftError
+	require.ErrorAs(t, err, &drift)
+	assert.True(t, drift.Repaired)
+	assert.Equal(t, []string{"Service/podinfo-canary.default"}, drift.Objects)
 	err = router.Reconcile(mocks.canary)
 	require.NoError(t, err)
 
@@ -391,7 +513,8 @@ func TestServiceRouter_InitializeMetadata(t *testing.T) {
 
 	primarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
 	require.NoError(t, err)
-	assert.Equal(t, 1, len(primarySvc.Annotations))
+	assert.Equal(t, 3, len(primarySvc.Annotations))
+	assert.Equal(t, "podinfo.default", primarySvc.Annotations[ownerAnnotation])
 	assert.Equal(t, "podinfo-primary", primarySvc.Labels["app"])
 }
 
@@ -423,12 +546,13 @@ func TestServiceRouter_ReconcileMetadata(t *testing.T) {
 
 	canarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
 	require.NoError(t, err)
-	assert.Equal(t, 1, len(canarySvc.Annotations))
+	assert.Equal(t, 3, len(canarySvc.Annotations))
 	assert.Equal(t, "podinfo-canary", canarySvc.Labels["app"])
 
 	primarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
 	require.NoError(t, err)
-	assert.Equal(t, 1, len(primarySvc.Annotations))
+	assert.Equal(t, 3, len(primarySvc.Annotations))
+	assert.Equal(t, "podinfo.default", primarySvc.Annotations[ownerAnnotation])
 	assert.Equal(t, "podinfo-primary", primarySvc.Labels["app"])
 
 	mocks.canary.Spec.Service.Apex = &flaggerv1.CustomMetadata{
@@ -0,0 +1,193 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	monitoringv1 "github.com/fluxcd/flagger/pkg/apis/monitoring/v1"
+)
+
+const (
+	serviceMonitorKind = "ServiceMonitor"
+	podMonitorKind     = "PodMonitor"
+
+	// monitorVersionLabel is set to primary or canary on all the scraped series
+	monitorVersionLabel = "version"
+)
+
+// reconcileMonitors creates or updates the Prometheus Operator monitors
+// of the primary and canary workloads
+func (c *KubernetesDefaultRouter) reconcileMonitors(canary *flaggerv1.Canary) error {
+	if canary.Spec.Monitor == nil {
+		return nil
+	}
+
+	_, primaryName, canaryName := canary.GetServiceNames()
+	primaryLabelValue := fmt.Sprintf("%s-primary", c.labelValue)
+
+	switch kind := canary.Spec.Monitor.Kind; kind {
+	case "", serviceMonitorKind:
+		if err := c.reconcileServiceMonitor(canary, canaryName, canaryName, "canary"); err != nil {
+			return err
+		}
+		return c.reconcileServiceMonitor(canary, primaryName, primaryName, "primary")
+	case podMonitorKind:
+		if err := c.reconcilePodMonitor(canary, canaryName, c.labelValue, "canary"); err != nil {
+			return err
+		}
+		return c.reconcilePodMonitor(canary, primaryName, primaryLabelValue, "primary")
+	default:
+		return fmt.Errorf("monitor kind %s not supported", kind)
+	}
+}
+
+func (c *KubernetesDefaultRouter) reconcileServiceMonitor(canary *flaggerv1.Canary, name string, svcLabelValue string, version string) error {
+	spec := monitoringv1.ServiceMonitorSpec{
+		Selector: metav1.LabelSelector{
+			MatchLabels: map[string]string{c.labelSelector: svcLabelValue},
+		},
+		Endpoints: []monitoringv1.Endpoint{
+			{
+				Port:           monitorPort(canary),
+				Path:           canary.Spec.Monitor.Path,
+				Interval:       canary.Spec.Monitor.Interval,
+				RelabelConfigs: monitorRelabelConfigs(version),
+			},
+		},
+	}
+
+	monitor, err := c.flaggerClient.MonitoringV1().ServiceMonitors(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		monitor = &monitoringv1.ServiceMonitor{
+			ObjectMeta: monitorObjectMeta(canary, name),
+			Spec:       spec,
+		}
+		_, err = c.flaggerClient.MonitoringV1().ServiceMonitors(canary.Namespace).Create(context.TODO(), monitor, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("ServiceMonitor %s.%s create error: %w", name, canary.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ServiceMonitor %s.%s created", name, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("ServiceMonitor %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+
+	if cmp.Diff(spec, monitor.Spec) != "" || cmp.Diff(canary.Spec.Monitor.Labels, monitor.Labels) != "" {
+		clone := monitor.DeepCopy()
+		clone.Spec = spec
+		clone.Labels = canary.Spec.Monitor.Labels
+		_, err = c.flaggerClient.MonitoringV1().ServiceMonitors(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("ServiceMonitor %s.%s update error: %w", name, canary.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ServiceMonitor %s.%s updated", name, canary.Namespace)
+	}
+	return nil
+}
+
+func (c *KubernetesDefaultRouter) reconcilePodMonitor(canary *flaggerv1.Canary, name string, podLabelValue string, version string) error {
+	spec := monitoringv1.PodMonitorSpec{
+		Selector: metav1.LabelSelector{
+			MatchLabels: map[string]string{c.labelSelector: podLabelValue},
+		},
+		PodMetricsEndpoints: []monitoringv1.PodMetricsEndpoint{
+			{
+				Port:           monitorPort(canary),
+				Path:           canary.Spec.Monitor.Path,
+				Interval:       canary.Spec.Monitor.Interval,
+				RelabelConfigs: monitorRelabelConfigs(version),
+			},
+		},
+	}
+
+	monitor, err := c.flaggerClient.MonitoringV1().PodMonitors(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		monitor = &monitoringv1.PodMonitor{
+			ObjectMeta: monitorObjectMeta(canary, name),
+			Spec:       spec,
+		}
+		_, err = c.flaggerClient.MonitoringV1().PodMonitors(canary.Namespace).Create(context.TODO(), monitor, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("PodMonitor %s.%s create error: %w", name, canary.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("PodMonitor %s.%s created", name, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("PodMonitor %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+
+	if cmp.Diff(spec, monitor.Spec) != "" || cmp.Diff(canary.Spec.Monitor.Labels, monitor.Labels) != "" {
+		clone := monitor.DeepCopy()
+		clone.Spec = spec
+		clone.Labels = canary.Spec.Monitor.Labels
+		_, err = c.flaggerClient.MonitoringV1().PodMonitors(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("PodMonitor %s.%s update error: %w", name, canary.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("PodMonitor %s.%s updated", name, canary.Namespace)
+	}
+	return nil
+}
+
+func monitorObjectMeta(canary *flaggerv1.Canary, name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: canary.Namespace,
+		Labels:    canary.Spec.Monitor.Labels,
+		OwnerReferences: []metav1.OwnerReference{
+			*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+				Group:   flaggerv1.SchemeGroupVersion.Group,
+				Version: flaggerv1.SchemeGroupVersion.Version,
+				Kind:    flaggerv1.CanaryKind,
+			}),
+		},
+	}
+}
+
+func monitorPort(canary *flaggerv1.Canary) string {
+	if canary.Spec.Monitor.Port != "" {
+		return canary.Spec.Monitor.Port
+	}
+	if canary.Spec.Service.PortName != "" {
+		return canary.Spec.Service.PortName
+	}
+	return "http"
+}
+
+// monitorRelabelConfigs sets the version label on the scraped series
+// so that the primary and canary metrics can be told apart in queries
+func monitorRelabelConfigs(version string) []*monitoringv1.RelabelConfig {
+	return []*monitoringv1.RelabelConfig{
+		{
+			TargetLabel: monitorVersionLabel,
+			Replacement: version,
+			Action:      "replace",
+		},
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestServiceRouter_ServiceMonitors(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+	}
+
+	mocks.canary.Spec.Monitor = &flaggerv1.CanaryMonitor{
+		Interval: "15s",
+		Labels:   map[string]string{"release": "prometheus"},
+	}
+	err := router.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	sm, err := mocks.flaggerClient.MonitoringV1().ServiceMonitors("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", sm.Spec.Selector.MatchLabels["app"])
+	assert.Equal(t, "prometheus", sm.Labels["release"])
+	require.Len(t, sm.Spec.Endpoints, 1)
+	assert.Equal(t, "http", sm.Spec.Endpoints[0].Port)
+	assert.Equal(t, "15s", sm.Spec.Endpoints[0].Interval)
+	assert.Equal(t, "primary", sm.Spec.Endpoints[0].RelabelConfigs[0].Replacement)
+
+	sm, err = mocks.flaggerClient.MonitoringV1().ServiceMonitors("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-canary", sm.Spec.Selector.MatchLabels["app"])
+	assert.Equal(t, "canary", sm.Spec.Endpoints[0].RelabelConfigs[0].Replacement)
+
+	// update
+	mocks.canary.Spec.Monitor.Path = "/stats"
+	err = router.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	sm, err = mocks.flaggerClient.MonitoringV1().ServiceMonitors("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "/stats", sm.Spec.Endpoints[0].Path)
+}
+
+func TestServiceRouter_PodMonitors(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+	}
+
+	mocks.canary.Spec.Monitor = &flaggerv1.CanaryMonitor{
+		Kind: "PodMonitor",
+		Port: "metrics",
+	}
+	err := router.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	pm, err := mocks.flaggerClient.MonitoringV1().PodMonitors("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", pm.Spec.Selector.MatchLabels["app"])
+	assert.Equal(t, "metrics", pm.Spec.PodMetricsEndpoints[0].Port)
+
+	pm, err = mocks.flaggerClient.MonitoringV1().PodMonitors("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", pm.Spec.Selector.MatchLabels["app"])
+	assert.Equal(t, "canary", pm.Spec.PodMetricsEndpoints[0].RelabelConfigs[0].Replacement)
+
+	mocks.canary.Spec.Monitor.Kind = "Probe"
+	err = router.Initialize(mocks.canary)
+	require.Error(t, err)
+}
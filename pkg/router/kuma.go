@@ -0,0 +1,311 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	kumav1alpha1 "github.com/fluxcd/flagger/pkg/apis/kuma/v1alpha1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+const (
+	kumaServiceTag     = "kuma.io/service"
+	kumaMeshAnnotation = "kuma.io/mesh"
+	kumaDefaultMesh    = "default"
+)
+
+// KumaRouter is managing Kuma traffic routes
+type KumaRouter struct {
+	kubeClient kubernetes.Interface
+	kumaClient clientset.Interface
+	logger     *zap.SugaredLogger
+}
+
+// Reconcile tags the generated services with their Kuma service name
+// and creates or updates the Kuma traffic route of the apex service
+func (kr *KumaRouter) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	for _, name := range []string{apexName, primaryName, canaryName} {
+		if err := kr.reconcileServiceTag(canary, name); err != nil {
+			return err
+		}
+	}
+
+	drift := &driftCollector{}
+	if err := drift.check(kr.reconcileTrafficRoute(canary)); err != nil {
+		return err
+	}
+	return drift.err()
+}
+
+// reconcileServiceTag labels the service with the kuma.io/service tag and sets the
+// port protocol to HTTP unless specified, Kuma splits the traffic only for HTTP services
+func (kr *KumaRouter) reconcileServiceTag(canary *flaggerv1.Canary, name string) error {
+	svc, err := kr.kubeClient.CoreV1().Services(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("service %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+
+	tag := kumaServiceName(name, canary.Namespace, canary.Spec.Service.Port)
+	protocolAnnotation := fmt.Sprintf("%d.service.kuma.io/protocol", canary.Spec.Service.Port)
+	_, hasProtocol := svc.Annotations[protocolAnnotation]
+	if svc.Labels[kumaServiceTag] == tag && hasProtocol {
+		return nil
+	}
+
+	clone := svc.DeepCopy()
+	if clone.Labels == nil {
+		clone.Labels = make(map[string]string)
+	}
+	clone.Labels[kumaServiceTag] = tag
+	if !hasProtocol {
+		if clone.Annotations == nil {
+			clone.Annotations = make(map[string]string)
+		}
+		clone.Annotations[protocolAnnotation] = "http"
+	}
+
+	if _, err := kr.kubeClient.CoreV1().Services(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("service %s.%s update error: %w", name, canary.Namespace, err)
+	}
+	return nil
+}
+
+// reconcileTrafficRoute routes the apex service traffic from all the mesh services to the primary and canary
+func (kr *KumaRouter) reconcileTrafficRoute(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+	newSpec := kr.makeTrafficRouteSpec(canary, 100, 0)
+
+	route, err := kr.kumaClient.KumaV1alpha1().TrafficRoutes().Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		metadata := canary.Spec.Service.Apex
+		if metadata == nil {
+			metadata = &flaggerv1.CustomMetadata{}
+		}
+		if metadata.Labels == nil {
+			metadata.Labels = make(map[string]string)
+		}
+		if metadata.Annotations == nil {
+			metadata.Annotations = make(map[string]string)
+		}
+
+		mesh, err := kr.getMesh(canary)
+		if err != nil {
+			return err
+		}
+
+		// traffic routes are cluster scoped and can't be owned by the canary
+		route = &kumav1alpha1.TrafficRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        apexName,
+				Labels:      metadata.Labels,
+				Annotations: withOwnership(filterMetadata(metadata.Annotations), canary, newSpec),
+			},
+			Mesh: mesh,
+			Spec: newSpec,
+		}
+
+		_, err = kr.kumaClient.KumaV1alpha1().TrafficRoutes().Create(context.TODO(), route, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("TrafficRoute %s create error: %w", apexName, err)
+		}
+		kr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("TrafficRoute %s created", apexName)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("TrafficRoute %s get query error: %w", apexName, err)
+	}
+
+	// update the traffic route but keep the original weights
+	if diff := cmp.Diff(newSpec, route.Spec, cmpopts.IgnoreFields(kumav1alpha1.TrafficRouteSplit{}, "Weight")); diff != "" {
+		drifted, err := checkDrift(canary, "TrafficRoute", route, newSpec)
+		if err != nil {
+			return err
+		}
+		clone := route.DeepCopy()
+		clone.Spec = newSpec
+		clone.Annotations = withOwnership(clone.Annotations, canary, newSpec)
+
+		_, err = kr.kumaClient.KumaV1alpha1().TrafficRoutes().Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("TrafficRoute %s update error: %w", apexName, err)
+		}
+		kr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("TrafficRoute %s updated", apexName)
+		if drifted {
+			return repairedDrift("TrafficRoute", route)
+		}
+	}
+
+	return nil
+}
+
+// GetRoutes returns the traffic route weights of the primary and canary services
+func (kr *KumaRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	route, err := kr.kumaClient.KumaV1alpha1().TrafficRoutes().Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("TrafficRoute %s get query error: %w", apexName, err)
+		return
+	}
+
+	primaryTag := kumaServiceName(primaryName, canary.Namespace, canary.Spec.Service.Port)
+	canaryTag := kumaServiceName(canaryName, canary.Namespace, canary.Spec.Service.Port)
+	for _, split := range route.Spec.Conf.Split {
+		switch split.Destination[kumaServiceTag] {
+		case primaryTag:
+			primaryWeight = int(split.Weight)
+		case canaryTag:
+			canaryWeight = int(split.Weight)
+		}
+	}
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		err = fmt.Errorf("TrafficRoute %s does not contain splits for %s and %s", apexName, primaryTag, canaryTag)
+	}
+	return
+}
+
+// SetRoutes updates the traffic route weights of the primary and canary services
+func (kr *KumaRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	apexName, _, _ := canary.GetServiceNames()
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		return fmt.Errorf("TrafficRoute %s update failed: no valid weights", apexName)
+	}
+
+	route, err := kr.kumaClient.KumaV1alpha1().TrafficRoutes().Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("TrafficRoute %s query error: %w", apexName, err)
+	}
+
+	clone := route.DeepCopy()
+	clone.Spec = kr.makeTrafficRouteSpec(canary, primaryWeight, canaryWeight)
+
+	_, err = kr.kumaClient.KumaV1alpha1().TrafficRoutes().Update(context.TODO(), clone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("TrafficRoute %s update error: %w", apexName, err)
+	}
+	return nil
+}
+
+// Finalize deletes the traffic route, being cluster scoped it is not garbage collected with the canary
+func (kr *KumaRouter) Finalize(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+	err := kr.kumaClient.KumaV1alpha1().TrafficRoutes().Delete(context.TODO(), apexName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("TrafficRoute %s delete error: %w", apexName, err)
+	}
+	return nil
+}
+
+func (kr *KumaRouter) makeTrafficRouteSpec(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int) kumav1alpha1.TrafficRouteSpec {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	port := canary.Spec.Service.Port
+	return kumav1alpha1.TrafficRouteSpec{
+		Sources: []kumav1alpha1.Selector{
+			{
+				Match: map[string]string{kumaServiceTag: "*"},
+			},
+		},
+		Destinations: []kumav1alpha1.Selector{
+			{
+				Match: map[string]string{kumaServiceTag: kumaServiceName(apexName, canary.Namespace, port)},
+			},
+		},
+		Conf: kumav1alpha1.TrafficRouteConf{
+			Split: []kumav1alpha1.TrafficRouteSplit{
+				{
+					Weight:      uint32(primaryWeight),
+					Destination: map[string]string{kumaServiceTag: kumaServiceName(primaryName, canary.Namespace, port)},
+				},
+				{
+					Weight:      uint32(canaryWeight),
+					Destination: map[string]string{kumaServiceTag: kumaServiceName(canaryName, canary.Namespace, port)},
+				},
+			},
+		},
+	}
+}
+
+// getMesh returns the Kuma mesh of the canary namespace
+func (kr *KumaRouter) getMesh(canary *flaggerv1.Canary) (string, error) {
+	ns, err := kr.kubeClient.CoreV1().Namespaces().Get(context.TODO(), canary.Namespace, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return kumaDefaultMesh, nil
+	} else if err != nil {
+		return "", fmt.Errorf("namespace %s get query error: %w", canary.Namespace, err)
+	}
+	if mesh := ns.Annotations[kumaMeshAnnotation]; mesh != "" {
+		return mesh, nil
+	}
+	return kumaDefaultMesh, nil
+}
+
+// kumaServiceName returns the name Kuma gives to a Kubernetes service
+func kumaServiceName(name string, namespace string, port int32) string {
+	return fmt.Sprintf("%s_%s_svc_%d", name, namespace, port)
+}
+
+// Snapshot returns the current state of the TrafficRoute
+func (kr *KumaRouter) Snapshot(canary *flaggerv1.Canary) (*RoutingSnapshot, error) {
+	apexName, _, _ := canary.GetServiceNames()
+	obj, err := kr.kumaClient.KumaV1alpha1().TrafficRoutes().Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("TrafficRoute %s get query error: %w", apexName, err)
+	}
+	return newRoutingSnapshot("TrafficRoute", obj)
+}
+
+// Restore sets the spec, labels and annotations of the TrafficRoute to the snapshot state
+func (kr *KumaRouter) Restore(_ *flaggerv1.Canary, snapshot *RoutingSnapshot) error {
+	saved := &kumav1alpha1.TrafficRoute{}
+	return snapshot.restore("TrafficRoute", saved, func() error {
+		obj, err := kr.kumaClient.KumaV1alpha1().TrafficRoutes().Get(context.TODO(), saved.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("TrafficRoute %s get query error: %w", saved.Name, err)
+		}
+		clone := obj.DeepCopy()
+		clone.Spec = saved.Spec
+		clone.Labels = saved.Labels
+		clone.Annotations = saved.Annotations
+		if _, err := kr.kumaClient.KumaV1alpha1().TrafficRoutes().Update(context.TODO(), clone, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("TrafficRoute %s update error: %w", saved.Name, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newKumaTestRouter(t *testing.T, mocks fixture) *KumaRouter {
+	svcRouter := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+	}
+	require.NoError(t, svcRouter.Initialize(mocks.canary))
+	require.NoError(t, svcRouter.Reconcile(mocks.canary))
+
+	return &KumaRouter{
+		kubeClient: mocks.kubeClient,
+		kumaClient: mocks.meshClient,
+		logger:     mocks.logger,
+	}
+}
+
+func TestKumaRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := newKumaTestRouter(t, mocks)
+
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	route, err := router.kumaClient.KumaV1alpha1().TrafficRoutes().Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "default", route.Mesh)
+	assert.Equal(t, "*", route.Spec.Sources[0].Match[kumaServiceTag])
+	assert.Equal(t, "podinfo_default_svc_9898", route.Spec.Destinations[0].Match[kumaServiceTag])
+	require.Len(t, route.Spec.Conf.Split, 2)
+	assert.Equal(t, "podinfo-primary_default_svc_9898", route.Spec.Conf.Split[0].Destination[kumaServiceTag])
+	assert.Equal(t, uint32(100), route.Spec.Conf.Split[0].Weight)
+	assert.Equal(t, "podinfo-canary_default_svc_9898", route.Spec.Conf.Split[1].Destination[kumaServiceTag])
+	assert.Equal(t, uint32(0), route.Spec.Conf.Split[1].Weight)
+	assert.Equal(t, "podinfo.default", route.Annotations[ownerAnnotation])
+
+	for _, name := range []string{"podinfo", "podinfo-primary", "podinfo-canary"} {
+		svc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, name+"_default_svc_9898", svc.Labels[kumaServiceTag])
+		assert.Equal(t, "http", svc.Annotations["9898.service.kuma.io/protocol"])
+	}
+
+	// reconcile keeps the weights
+	require.NoError(t, router.SetRoutes(mocks.canary, 60, 40, false))
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	p, c, m, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+	assert.False(t, m)
+
+	// undo external changes
+	route, err = router.kumaClient.KumaV1alpha1().TrafficRoutes().Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	route.Spec.Sources[0].Match[kumaServiceTag] = "frontend_default_svc_80"
+	_, err = router.kumaClient.KumaV1alpha1().TrafficRoutes().Update(context.TODO(), route, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	var drift *DriftError
+	require.ErrorAs(t, router.Reconcile(mocks.canary), &drift)
+
+	route, err = router.kumaClient.KumaV1alpha1().TrafficRoutes().Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "*", route.Spec.Sources[0].Match[kumaServiceTag])
+
+	// finalize removes the cluster scoped route
+	require.NoError(t, router.Finalize(mocks.canary))
+	_, err = router.kumaClient.KumaV1alpha1().TrafficRoutes().Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestKumaRouter_SetRoutes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := newKumaTestRouter(t, mocks)
+
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	for _, tt := range []struct {
+		name    string
+		primary int
+		canary  int
+	}{
+		{name: "0%", primary: 100, canary: 0},
+		{name: "20%", primary: 80, canary: 20},
+		{name: "50%", primary: 50, canary: 50},
+		{name: "100%", primary: 0, canary: 100},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, router.SetRoutes(mocks.canary, tt.primary, tt.canary, false))
+
+			p, c, _, err := router.GetRoutes(mocks.canary)
+			require.NoError(t, err)
+			assert.Equal(t, tt.primary, p)
+			assert.Equal(t, tt.canary, c)
+		})
+	}
+
+	assert.Error(t, router.SetRoutes(mocks.canary, 0, 0, false))
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	// MigrationNone is the migration status of the canaries routed by SMI only
+	MigrationNone = "none"
+	// MigrationDualWrite is the migration status of the canaries routed by SMI
+	// while the weights are also written to the Gateway API HTTPRoute
+	MigrationDualWrite = "dual-write"
+	// MigrationFlipped is the migration status of the canaries routed by the Gateway API
+	// while the weights are still written to the SMI TrafficSplit
+	MigrationFlipped = "flipped"
+)
+
+// IsSMIProvider returns true if the provider routes the traffic with SMI TrafficSplits
+func IsSMIProvider(provider string) bool {
+	return provider == flaggerv1.LinkerdProvider ||
+		provider == flaggerv1.OsmProvider ||
+		strings.HasPrefix(provider, flaggerv1.SMIProvider+":")
+}
+
+// MigrationStatus returns the SMI to Gateway API migration status of a canary,
+// or an empty string if the canary doesn't use SMI
+func MigrationStatus(canary *flaggerv1.Canary, provider string) string {
+	migrating := canary.Spec.Service.GatewayAPIMigration != nil
+	switch {
+	case IsSMIProvider(provider) && migrating:
+		return MigrationDualWrite
+	case IsSMIProvider(provider):
+		return MigrationNone
+	case strings.HasPrefix(provider, flaggerv1.GatewayProvider) && migrating:
+		return MigrationFlipped
+	default:
+		return ""
+	}
+}
+
+// MigrationRouter writes the traffic weights to the routing objects of both the SMI and the
+// Gateway API providers, the weights are read from the router of the canary provider,
+// so the provider can be switched during the analysis without resetting the traffic
+type MigrationRouter struct {
+	Interface
+	secondary Interface
+	logger    *zap.SugaredLogger
+}
+
+// WithGatewayAPIMigration wraps the mesh router to dual-write the SMI TrafficSplit and the
+// Gateway API HTTPRoute if the canary has the migration enabled
+func (factory *Factory) WithGatewayAPIMigration(canary *flaggerv1.Canary, provider string, labelSelector string, router Interface) Interface {
+	migration := canary.Spec.Service.GatewayAPIMigration
+	if migration == nil {
+		return router
+	}
+	var secondary Interface
+	switch {
+	case IsSMIProvider(provider):
+		secondary = factory.MeshRouter(flaggerv1.GatewayProvider, labelSelector)
+	case strings.HasPrefix(provider, flaggerv1.GatewayProvider):
+		secondary = factory.MeshRouter(migration.SMIProvider, labelSelector)
+	default:
+		return router
+	}
+	return &MigrationRouter{
+		Interface: router,
+		secondary: secondary,
+		logger:    factory.logger,
+	}
+}
+
+// Reconcile reconciles the routing objects of both providers, then copies the weights
+// of the canary provider to the other one, e.g. when the HTTPRoute has just been created
+func (mr *MigrationRouter) Reconcile(canary *flaggerv1.Canary) error {
+	drift := &driftCollector{}
+	if err := drift.check(mr.Interface.Reconcile(canary)); err != nil {
+		return err
+	}
+	if err := drift.check(mr.secondary.Reconcile(canary)); err != nil {
+		return err
+	}
+
+	primaryWeight, canaryWeight, mirrored, err := mr.Interface.GetRoutes(canary)
+	if err != nil {
+		return err
+	}
+	// SMI doesn't support mirroring, only the weights are compared
+	pw, cw, _, err := mr.secondary.GetRoutes(canary)
+	if err != nil {
+		return err
+	}
+	if pw != primaryWeight || cw != canaryWeight {
+		if err := mr.secondary.SetRoutes(canary, primaryWeight, canaryWeight, mirrored); err != nil {
+			return fmt.Errorf("migration routes sync failed: %w", err)
+		}
+		mr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Migration routes synced to primary %v%% canary %v%%", primaryWeight, canaryWeight)
+	}
+
+	return drift.err()
+}
+
+// SetRoutes updates the weights of both providers
+func (mr *MigrationRouter) SetRoutes(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int, mirrored bool) error {
+	if err := mr.Interface.SetRoutes(canary, primaryWeight, canaryWeight, mirrored); err != nil {
+		return err
+	}
+	if err := mr.secondary.SetRoutes(canary, primaryWeight, canaryWeight, mirrored); err != nil {
+		return fmt.Errorf("migration routes update failed: %w", err)
+	}
+	return nil
+}
+
+// Finalize reverts the routing objects of both providers
+func (mr *MigrationRouter) Finalize(canary *flaggerv1.Canary) error {
+	if err := mr.Interface.Finalize(canary); err != nil {
+		return err
+	}
+	return mr.secondary.Finalize(canary)
+}
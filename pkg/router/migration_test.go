@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestMigrationRouter_DualWrite(t *testing.T) {
+	canary := newTestSMICanary()
+	canary.Spec.Service.GatewayAPIMigration = &flaggerv1.CanaryGatewayAPIMigration{SMIProvider: "smi:v1alpha2:linkerd"}
+	mocks := newFixture(canary)
+	factory := NewFactory(nil, mocks.kubeClient, mocks.flaggerClient, "", "", mocks.logger, mocks.meshClient)
+	gatewayAPI := factory.MeshRouter(flaggerv1.GatewayProvider, "app")
+	smi := factory.MeshRouter("smi:v1alpha2:linkerd", "app")
+
+	router := factory.WithGatewayAPIMigration(canary, "smi:v1alpha2:linkerd", "app", smi)
+	require.IsType(t, &MigrationRouter{}, router)
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	// test dual-write
+	err = router.SetRoutes(canary, 60, 40, false)
+	require.NoError(t, err)
+
+	pw, cw, _, err := smi.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, pw)
+	assert.Equal(t, 40, cw)
+
+	pw, cw, _, err = gatewayAPI.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, pw)
+	assert.Equal(t, 40, cw)
+
+	// test the weights are kept when flipping the provider
+	router = factory.WithGatewayAPIMigration(canary, flaggerv1.GatewayProvider, "app", gatewayAPI)
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	pw, cw, _, err = router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, pw)
+	assert.Equal(t, 40, cw)
+
+	// test the TrafficSplit is still written after the flip
+	err = router.SetRoutes(canary, 50, 50, false)
+	require.NoError(t, err)
+
+	pw, cw, _, err = smi.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 50, pw)
+	assert.Equal(t, 50, cw)
+}
+
+func TestMigrationRouter_Sync(t *testing.T) {
+	canary := newTestSMICanary()
+	mocks := newFixture(canary)
+	factory := NewFactory(nil, mocks.kubeClient, mocks.flaggerClient, "", "", mocks.logger, mocks.meshClient)
+	smi := factory.MeshRouter("smi:v1alpha2:linkerd", "app")
+
+	// analysis running before the migration is enabled
+	err := smi.Reconcile(canary)
+	require.NoError(t, err)
+	err = smi.SetRoutes(canary, 70, 30, false)
+	require.NoError(t, err)
+
+	canary.Spec.Service.GatewayAPIMigration = &flaggerv1.CanaryGatewayAPIMigration{SMIProvider: "smi:v1alpha2:linkerd"}
+	router := factory.WithGatewayAPIMigration(canary, "smi:v1alpha2:linkerd", "app", smi)
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	pw, cw, _, err := factory.MeshRouter(flaggerv1.GatewayProvider, "app").GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 70, pw)
+	assert.Equal(t, 30, cw)
+}
+
+func TestMigrationStatus(t *testing.T) {
+	migration := &flaggerv1.CanaryGatewayAPIMigration{SMIProvider: flaggerv1.LinkerdProvider}
+	tests := []struct {
+		provider  string
+		migration *flaggerv1.CanaryGatewayAPIMigration
+		status    string
+	}{
+		{provider: flaggerv1.LinkerdProvider, status: MigrationNone},
+		{provider: "smi:v1alpha3:linkerd", status: MigrationNone},
+		{provider: flaggerv1.OsmProvider, migration: migration, status: MigrationDualWrite},
+		{provider: flaggerv1.GatewayProvider, migration: migration, status: MigrationFlipped},
+		{provider: flaggerv1.GatewayProvider, status: ""},
+		{provider: flaggerv1.IstioProvider, status: ""},
+	}
+
+	for _, tt := range tests {
+		canary := &flaggerv1.Canary{}
+		canary.Spec.Service.GatewayAPIMigration = tt.migration
+		assert.Equal(t, tt.status, MigrationStatus(canary, tt.provider), tt.provider)
+	}
+}
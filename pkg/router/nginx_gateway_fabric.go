@@ -0,0 +1,217 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	gatewayapi "github.com/fluxcd/flagger/pkg/apis/gatewayapi"
+	nginxgatewayv1alpha1 "github.com/fluxcd/flagger/pkg/apis/nginxgateway/v1alpha1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// NGINXGatewayFabricRouter is managing the Gateway API HTTPRoutes and
+// the NGINX Gateway Fabric policies attached to them
+type NGINXGatewayFabricRouter struct {
+	*GatewayAPIRouter
+	nginxGatewayClient clientset.Interface
+}
+
+// Reconcile creates or updates the HTTPRoute and the ObservabilityPolicy and ClientSettingsPolicy targeting it
+func (nr *NGINXGatewayFabricRouter) Reconcile(canary *flaggerv1.Canary) error {
+	drift := &driftCollector{}
+	if err := drift.check(nr.GatewayAPIRouter.Reconcile(canary)); err != nil {
+		return err
+	}
+	if err := drift.check(nr.reconcileObservabilityPolicy(canary)); err != nil {
+		return err
+	}
+	if err := drift.check(nr.reconcileClientSettingsPolicy(canary)); err != nil {
+		return err
+	}
+	return drift.err()
+}
+
+func (nr *NGINXGatewayFabricRouter) reconcileObservabilityPolicy(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+	policies := nr.nginxGatewayClient.NginxgatewayV1alpha1().ObservabilityPolicies(canary.Namespace)
+
+	policy, err := policies.Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("ObservabilityPolicy %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+	exists := err == nil
+
+	// remove the policy generated by Flagger when tracing is no longer enabled
+	if canary.Spec.Service.NGINXGateway == nil || canary.Spec.Service.NGINXGateway.Tracing == nil {
+		if !exists || !isOwnedBy(policy, canary) {
+			return nil
+		}
+		if err := policies.Delete(context.TODO(), apexName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("ObservabilityPolicy %s.%s delete error: %w", apexName, canary.Namespace, err)
+		}
+		nr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ObservabilityPolicy %s.%s deleted", apexName, canary.Namespace)
+		return nil
+	}
+
+	newSpec := nginxgatewayv1alpha1.ObservabilityPolicySpec{
+		Tracing:    canary.Spec.Service.NGINXGateway.Tracing.DeepCopy(),
+		TargetRefs: []nginxgatewayv1alpha1.LocalPolicyTargetReference{nr.makePolicyTargetRef(canary)},
+	}
+
+	if !exists {
+		policy = &nginxgatewayv1alpha1.ObservabilityPolicy{
+			ObjectMeta: nr.makePolicyMeta(canary, newSpec),
+			Spec:       newSpec,
+		}
+		_, err = policies.Create(context.TODO(), policy, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("ObservabilityPolicy %s.%s create error: %w", apexName, canary.Namespace, err)
+		}
+		nr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ObservabilityPolicy %s.%s created", apexName, canary.Namespace)
+		return nil
+	}
+
+	if diff := cmp.Diff(newSpec, policy.Spec, cmpopts.EquateEmpty()); diff != "" {
+		drifted, err := checkDrift(canary, "ObservabilityPolicy", policy, newSpec)
+		if err != nil {
+			return err
+		}
+		clone := policy.DeepCopy()
+		clone.Spec = newSpec
+		clone.Annotations = withOwnership(clone.Annotations, canary, newSpec)
+
+		_, err = policies.Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("ObservabilityPolicy %s.%s update error: %w", apexName, canary.Namespace, err)
+		}
+		nr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ObservabilityPolicy %s.%s updated", apexName, canary.Namespace)
+		if drifted {
+			return repairedDrift("ObservabilityPolicy", policy)
+		}
+	}
+	return nil
+}
+
+func (nr *NGINXGatewayFabricRouter) reconcileClientSettingsPolicy(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+	policies := nr.nginxGatewayClient.NginxgatewayV1alpha1().ClientSettingsPolicies(canary.Namespace)
+
+	policy, err := policies.Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("ClientSettingsPolicy %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+	exists := err == nil
+
+	// remove the policy generated by Flagger when the client settings are no longer defined
+	settings := canary.Spec.Service.NGINXGateway
+	if settings == nil || (settings.ClientBody == nil && settings.ClientKeepAlive == nil) {
+		if !exists || !isOwnedBy(policy, canary) {
+			return nil
+		}
+		if err := policies.Delete(context.TODO(), apexName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("ClientSettingsPolicy %s.%s delete error: %w", apexName, canary.Namespace, err)
+		}
+		nr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ClientSettingsPolicy %s.%s deleted", apexName, canary.Namespace)
+		return nil
+	}
+
+	newSpec := nginxgatewayv1alpha1.ClientSettingsPolicySpec{
+		Body:      settings.ClientBody.DeepCopy(),
+		KeepAlive: settings.ClientKeepAlive.DeepCopy(),
+		TargetRef: nr.makePolicyTargetRef(canary),
+	}
+
+	if !exists {
+		policy = &nginxgatewayv1alpha1.ClientSettingsPolicy{
+			ObjectMeta: nr.makePolicyMeta(canary, newSpec),
+			Spec:       newSpec,
+		}
+		_, err = policies.Create(context.TODO(), policy, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("ClientSettingsPolicy %s.%s create error: %w", apexName, canary.Namespace, err)
+		}
+		nr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ClientSettingsPolicy %s.%s created", apexName, canary.Namespace)
+		return nil
+	}
+
+	if diff := cmp.Diff(newSpec, policy.Spec, cmpopts.EquateEmpty()); diff != "" {
+		drifted, err := checkDrift(canary, "ClientSettingsPolicy", policy, newSpec)
+		if err != nil {
+			return err
+		}
+		clone := policy.DeepCopy()
+		clone.Spec = newSpec
+		clone.Annotations = withOwnership(clone.Annotations, canary, newSpec)
+
+		_, err = policies.Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("ClientSettingsPolicy %s.%s update error: %w", apexName, canary.Namespace, err)
+		}
+		nr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ClientSettingsPolicy %s.%s updated", apexName, canary.Namespace)
+		if drifted {
+			return repairedDrift("ClientSettingsPolicy", policy)
+		}
+	}
+	return nil
+}
+
+// isOwnedBy returns true if the object was generated by Flagger for the canary
+func isOwnedBy(obj metav1.Object, canary *flaggerv1.Canary) bool {
+	return obj.GetAnnotations()[ownerAnnotation] == fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+}
+
+// makePolicyTargetRef targets the HTTPRoute generated for the canary
+func (nr *NGINXGatewayFabricRouter) makePolicyTargetRef(canary *flaggerv1.Canary) nginxgatewayv1alpha1.LocalPolicyTargetReference {
+	apexName, _, _ := canary.GetServiceNames()
+	return nginxgatewayv1alpha1.LocalPolicyTargetReference{
+		Group: gatewayapi.GroupName,
+		Kind:  "HTTPRoute",
+		Name:  apexName,
+	}
+}
+
+// makePolicyMeta returns the metadata of a policy owned by the canary, so that it is garbage collected with it
+func (nr *NGINXGatewayFabricRouter) makePolicyMeta(canary *flaggerv1.Canary, spec interface{}) metav1.ObjectMeta {
+	apexName, _, _ := canary.GetServiceNames()
+	return metav1.ObjectMeta{
+		Name:        apexName,
+		Namespace:   canary.Namespace,
+		Annotations: withOwnership(nil, canary, spec),
+		OwnerReferences: []metav1.OwnerReference{
+			*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+				Group:   flaggerv1.SchemeGroupVersion.Group,
+				Version: flaggerv1.SchemeGroupVersion.Version,
+				Kind:    flaggerv1.CanaryKind,
+			}),
+		},
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	nginxgatewayv1alpha1 "github.com/fluxcd/flagger/pkg/apis/nginxgateway/v1alpha1"
+)
+
+func TestNGINXGatewayFabricRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &NGINXGatewayFabricRouter{
+		GatewayAPIRouter: &GatewayAPIRouter{
+			logger:           mocks.logger,
+			gatewayAPIClient: mocks.meshClient,
+		},
+		nginxGatewayClient: mocks.meshClient,
+	}
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	ratio := int32(10)
+	timeout := nginxgatewayv1alpha1.Duration("30s")
+	cdClone := cd.DeepCopy()
+	cdClone.Spec.Service.NGINXGateway = &flaggerv1.NGINXGatewayPolicies{
+		Tracing: &nginxgatewayv1alpha1.Tracing{
+			Strategy: nginxgatewayv1alpha1.TraceStrategyRatio,
+			Ratio:    &ratio,
+		},
+		ClientBody: &nginxgatewayv1alpha1.ClientBody{
+			Timeout: &timeout,
+		},
+	}
+	canary, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cdClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	// test insert
+	_, err = mocks.meshClient.GatewayapiV1alpha2().HTTPRoutes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	observability, err := mocks.meshClient.NginxgatewayV1alpha1().ObservabilityPolicies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, observability.Spec.TargetRefs, 1)
+	assert.Equal(t, "HTTPRoute", observability.Spec.TargetRefs[0].Kind)
+	assert.Equal(t, "podinfo", observability.Spec.TargetRefs[0].Name)
+	assert.Equal(t, int32(10), *observability.Spec.Tracing.Ratio)
+	require.Len(t, observability.OwnerReferences, 1)
+	assert.Equal(t, "podinfo", observability.OwnerReferences[0].Name)
+
+	clientSettings, err := mocks.meshClient.NginxgatewayV1alpha1().ClientSettingsPolicies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", clientSettings.Spec.TargetRef.Name)
+	assert.Equal(t, timeout, *clientSettings.Spec.Body.Timeout)
+	assert.Nil(t, clientSettings.Spec.KeepAlive)
+
+	// test update
+	ratio = 50
+	cdClone = canary.DeepCopy()
+	cdClone.Spec.Service.NGINXGateway.Tracing.Ratio = &ratio
+	canary, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cdClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	observability, err = mocks.meshClient.NginxgatewayV1alpha1().ObservabilityPolicies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(50), *observability.Spec.Tracing.Ratio)
+
+	// test removal
+	cdClone = canary.DeepCopy()
+	cdClone.Spec.Service.NGINXGateway.ClientBody = nil
+	canary, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cdClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	_, err = mocks.meshClient.NginxgatewayV1alpha1().ClientSettingsPolicies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = mocks.meshClient.NginxgatewayV1alpha1().ObservabilityPolicies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
+func TestNGINXGatewayFabricRouter_KeepUnownedPolicies(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &NGINXGatewayFabricRouter{
+		GatewayAPIRouter: &GatewayAPIRouter{
+			logger:           mocks.logger,
+			gatewayAPIClient: mocks.meshClient,
+		},
+		nginxGatewayClient: mocks.meshClient,
+	}
+
+	policy := &nginxgatewayv1alpha1.ObservabilityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+	}
+	_, err := mocks.meshClient.NginxgatewayV1alpha1().ObservabilityPolicies("default").Create(context.TODO(), policy, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	err = router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	_, err = mocks.meshClient.NginxgatewayV1alpha1().ObservabilityPolicies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+}
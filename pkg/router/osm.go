@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	// osmMonitoredByLabel marks the namespaces monitored by an OSM control plane
+	osmMonitoredByLabel = "openservicemesh.io/monitored-by"
+	// osmInjectionAnnotation enables or disables the sidecar injection on a namespace or pod
+	osmInjectionAnnotation = "openservicemesh.io/sidecar-injection"
+)
+
+// OsmRouter is managing the SMI traffic splits of Open Service Mesh
+type OsmRouter struct {
+	*Smiv1alpha2Router
+}
+
+// Reconcile validates that the primary pods are injected with the OSM sidecar,
+// then creates or updates the SMI traffic split
+func (or *OsmRouter) Reconcile(canary *flaggerv1.Canary) error {
+	if err := or.checkSidecarInjection(canary); err != nil {
+		return err
+	}
+	return or.Smiv1alpha2Router.Reconcile(canary)
+}
+
+// checkSidecarInjection returns an error if the namespace is not monitored by OSM
+// or if the sidecar injection is disabled for the primary pods
+func (or *OsmRouter) checkSidecarInjection(canary *flaggerv1.Canary) error {
+	ns, err := or.kubeClient.CoreV1().Namespaces().Get(context.TODO(), canary.Namespace, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("namespace %s get query error: %w", canary.Namespace, err)
+	}
+	if _, ok := ns.Labels[osmMonitoredByLabel]; !ok {
+		return fmt.Errorf("namespace %s is not monitored by OSM, label %s not found", canary.Namespace, osmMonitoredByLabel)
+	}
+
+	template, err := or.getPrimaryPodTemplate(canary)
+	if err != nil {
+		return err
+	}
+	if template == nil {
+		return nil
+	}
+
+	primaryName := fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
+	enabled, ok := parseOsmInjection(template.Annotations[osmInjectionAnnotation])
+	if !ok {
+		enabled, ok = parseOsmInjection(ns.Annotations[osmInjectionAnnotation])
+	}
+	if !ok || !enabled {
+		return fmt.Errorf("OSM sidecar injection is not enabled for %s %s.%s, annotate the namespace or the pod template with %s: enabled",
+			canary.Spec.TargetRef.Kind, primaryName, canary.Namespace, osmInjectionAnnotation)
+	}
+	return nil
+}
+
+// getPrimaryPodTemplate returns the pod template of the primary workload,
+// a Service target has no pod template
+func (or *OsmRouter) getPrimaryPodTemplate(canary *flaggerv1.Canary) (*corev1.PodTemplateSpec, error) {
+	primaryName := fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
+	switch canary.Spec.TargetRef.Kind {
+	case "Deployment":
+		dep, err := or.kubeClient.AppsV1().Deployments(canary.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("deployment %s.%s get query error: %w", primaryName, canary.Namespace, err)
+		}
+		return &dep.Spec.Template, nil
+	case "DaemonSet":
+		ds, err := or.kubeClient.AppsV1().DaemonSets(canary.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("daemonset %s.%s get query error: %w", primaryName, canary.Namespace, err)
+		}
+		return &ds.Spec.Template, nil
+	case "StatefulSet":
+		sts, err := or.kubeClient.AppsV1().StatefulSets(canary.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("statefulset %s.%s get query error: %w", primaryName, canary.Namespace, err)
+		}
+		return &sts.Spec.Template, nil
+	default:
+		return nil, nil
+	}
+}
+
+// parseOsmInjection returns the value of the sidecar injection annotation
+// and false if the annotation is not set
+func parseOsmInjection(value string) (enabled bool, ok bool) {
+	switch strings.ToLower(value) {
+	case "enabled", "yes", "true":
+		return true, true
+	case "disabled", "no", "false":
+		return false, true
+	default:
+		return false, false
+	}
+}
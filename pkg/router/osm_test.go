@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestOsmRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	mocks.canary.Spec.Service.Apex = &flaggerv1.CustomMetadata{
+		Annotations: map[string]string{"test": "annotation"},
+	}
+	router := &OsmRouter{
+		Smiv1alpha2Router: &Smiv1alpha2Router{
+			logger:        mocks.logger,
+			flaggerClient: mocks.flaggerClient,
+			kubeClient:    mocks.kubeClient,
+			smiClient:     mocks.meshClient,
+			targetMesh:    flaggerv1.OsmProvider,
+		},
+	}
+
+	// namespace not monitored
+	_, err := mocks.kubeClient.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	assert.Error(t, router.Reconcile(mocks.canary))
+
+	// sidecar injection not enabled
+	ns, err := mocks.kubeClient.CoreV1().Namespaces().Get(context.TODO(), "default", metav1.GetOptions{})
+	require.NoError(t, err)
+	ns.Labels = map[string]string{osmMonitoredByLabel: "osm"}
+	ns, err = mocks.kubeClient.CoreV1().Namespaces().Update(context.TODO(), ns, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	primary := newTestDeployment()
+	primary.Name = "podinfo-primary"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Create(context.TODO(), primary, metav1.CreateOptions{})
+	require.NoError(t, err)
+	assert.Error(t, router.Reconcile(mocks.canary))
+
+	// sidecar injection enabled for the namespace
+	ns.Annotations = map[string]string{osmInjectionAnnotation: "enabled"}
+	_, err = mocks.kubeClient.CoreV1().Namespaces().Update(context.TODO(), ns, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	ts, err := mocks.meshClient.SplitV1alpha2().TrafficSplits("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "annotation", ts.Annotations["test"])
+
+	// sidecar injection disabled for the primary pods
+	primary.Spec.Template.Annotations = map[string]string{osmInjectionAnnotation: "disabled"}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), primary, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.Error(t, router.Reconcile(mocks.canary))
+}
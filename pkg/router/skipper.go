@@ -77,11 +77,13 @@ func (skp *SkipperRouter) Reconcile(canary *flaggerv1.Canary) error {
 
 	// building the canary ingress from apex
 	iClone := apexIngress.DeepCopy()
+	found := false
 	for x := range iClone.Spec.Rules {
 		rule := &iClone.Spec.Rules[x] // ref not value
 		for y := range rule.HTTP.Paths {
 			path := &rule.HTTP.Paths[y] // ref not value
 			if path.Backend.Service != nil && path.Backend.Service.Name == apexSvcName {
+				found = true
 				// flipping to primary service
 				path.Backend.Service.Name = primarySvcName
 				// adding second canary service
@@ -91,11 +93,12 @@ func (skp *SkipperRouter) Reconcile(canary *flaggerv1.Canary) error {
 			}
 		}
 	}
-	if apexIngress.DeepCopy() == iClone {
+	if !found {
 		return fmt.Errorf("backend %s not found in ingress %s", apexSvcName, apexIngressName)
 	}
 
 	iClone.Annotations = skp.makeAnnotations(iClone.Annotations, map[string]int{primarySvcName: 100, canarySvcName: 0})
+	iClone.Annotations = withOwnership(iClone.Annotations, canary, iClone.Spec)
 	iClone.Name = canaryIngressName
 	iClone.Namespace = canary.Namespace
 	iClone.OwnerReferences = []metav1.OwnerReference{
@@ -127,16 +130,23 @@ func (skp *SkipperRouter) Reconcile(canary *flaggerv1.Canary) error {
 
 	// existant, updating
 	if cmp.Diff(iClone.Spec, canaryIngress.Spec) != "" {
+		drifted, err := checkDrift(canary, "Ingress", canaryIngress, iClone.Spec)
+		if err != nil {
+			return err
+		}
 		ingressClone := canaryIngress.DeepCopy()
 		ingressClone.Spec = iClone.Spec
 		ingressClone.Annotations = filterMetadata(iClone.Annotations)
 
-		_, err := skp.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Update(context.TODO(), ingressClone, metav1.UpdateOptions{})
+		_, err = skp.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Update(context.TODO(), ingressClone, metav1.UpdateOptions{})
 		if err != nil {
 			return fmt.Errorf("ingress %s.%s update error: %w", canaryIngressName, ingressClone.Namespace, err)
 		}
 		skp.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 			Infof("Ingress %s updated", canaryIngressName)
+		if drifted {
+			return repairedDrift("Ingress", canaryIngress)
+		}
 	}
 	return nil
 }
@@ -225,7 +235,7 @@ func (skp *SkipperRouter) Finalize(canary *flaggerv1.Canary) error {
 func (skp *SkipperRouter) makeAnnotations(annotations map[string]string, backendWeights map[string]int) map[string]string {
 	b, err := json.Marshal(backendWeights)
 	if err != nil {
-		skp.logger.Errorf("Skipper:makeAnnotations: unable to marshal backendWeights %w", err)
+		skp.logger.Errorf("Skipper:makeAnnotations: unable to marshal backendWeights %v", err)
 		return annotations
 	}
 	annotations[skipperBackendWeightsAnnotationKey] = string(b)
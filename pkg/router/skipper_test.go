@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -79,6 +80,19 @@ func TestSkipperRouter_Reconcile(t *testing.T) {
 	}
 }
 
+func TestSkipperRouter_ReconcileBackendNotFound(t *testing.T) {
+	mocks := newFixture(nil)
+	ti := newTestIngress()
+	ti.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name = "frontend"
+	_, err := mocks.kubeClient.NetworkingV1().Ingresses("default").Update(context.TODO(), ti, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	router := &SkipperRouter{logger: mocks.logger, kubeClient: mocks.kubeClient}
+	err = router.Reconcile(mocks.ingressCanary)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backend podinfo not found")
+}
+
 func TestSkipperRouter_GetSetRoutes(t *testing.T) {
 	assert := assert.New(t)
 	mocks := newFixture(nil)
@@ -83,7 +83,7 @@ func (sr *SmiRouter) Reconcile(canary *flaggerv1.Canary) error {
 						Kind:    flaggerv1.CanaryKind,
 					}),
 				},
-				Annotations: sr.makeAnnotations(canary.Spec.Service.Gateways),
+				Annotations: withOwnership(sr.makeAnnotations(canary.Spec.Service.Gateways), canary, tsSpec),
 			},
 			Spec: tsSpec,
 		}
@@ -102,16 +102,24 @@ func (sr *SmiRouter) Reconcile(canary *flaggerv1.Canary) error {
 
 	// update traffic split
 	if diff := cmp.Diff(tsSpec, ts.Spec, cmpopts.IgnoreTypes(resource.Quantity{})); diff != "" {
+		drifted, err := checkDrift(canary, "TrafficSplit", ts, tsSpec)
+		if err != nil {
+			return err
+		}
 		tsClone := ts.DeepCopy()
 		tsClone.Spec = tsSpec
+		tsClone.Annotations = withOwnership(tsClone.Annotations, canary, tsSpec)
 
-		_, err := sr.smiClient.SplitV1alpha1().TrafficSplits(canary.Namespace).Update(context.TODO(), tsClone, metav1.UpdateOptions{})
+		_, err = sr.smiClient.SplitV1alpha1().TrafficSplits(canary.Namespace).Update(context.TODO(), tsClone, metav1.UpdateOptions{})
 		if err != nil {
 			return fmt.Errorf("TrafficSplit %s.%s update error: %w", apexName, canary.Namespace, err)
 		}
 
 		sr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 			Infof("TrafficSplit %s.%s updated", apexName, canary.Namespace)
+		if drifted {
+			return repairedDrift("TrafficSplit", ts)
+		}
 		return nil
 	}
 
@@ -245,3 +253,32 @@ func (sr *SmiRouter) getWithConvert(canary *flaggerv1.Canary, host string) (*smi
 func (sr *SmiRouter) Finalize(_ *flaggerv1.Canary) error {
 	return nil
 }
+
+// Snapshot returns the current state of the TrafficSplit
+func (sr *SmiRouter) Snapshot(canary *flaggerv1.Canary) (*RoutingSnapshot, error) {
+	apexName, _, _ := canary.GetServiceNames()
+	obj, err := sr.smiClient.SplitV1alpha1().TrafficSplits(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("TrafficSplit %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+	return newRoutingSnapshot("TrafficSplit", obj)
+}
+
+// Restore sets the spec, labels and annotations of the TrafficSplit to the snapshot state
+func (sr *SmiRouter) Restore(canary *flaggerv1.Canary, snapshot *RoutingSnapshot) error {
+	saved := &smiv1alpha1.TrafficSplit{}
+	return snapshot.restore("TrafficSplit", saved, func() error {
+		obj, err := sr.smiClient.SplitV1alpha1().TrafficSplits(canary.Namespace).Get(context.TODO(), saved.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("TrafficSplit %s.%s get query error: %w", saved.Name, canary.Namespace, err)
+		}
+		clone := obj.DeepCopy()
+		clone.Spec = saved.Spec
+		clone.Labels = saved.Labels
+		clone.Annotations = saved.Annotations
+		if _, err := sr.smiClient.SplitV1alpha1().TrafficSplits(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("TrafficSplit %s.%s update error: %w", saved.Name, canary.Namespace, err)
+		}
+		return nil
+	})
+}
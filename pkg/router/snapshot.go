@@ -0,0 +1,227 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	snapshotStateAnnotation = "flagger.app/routing-snapshot-state"
+	snapshotDataKey         = "snapshot"
+	snapshotPending         = "pending"
+	snapshotApplied         = "applied"
+	snapshotRestored        = "restored"
+)
+
+// Snapshotter is implemented by the routers that can save the full state of their
+// routing objects and restore it, including the fields that Flagger doesn't manage
+type Snapshotter interface {
+	Snapshot(canary *flaggerv1.Canary) (*RoutingSnapshot, error)
+	Restore(canary *flaggerv1.Canary, snapshot *RoutingSnapshot) error
+}
+
+// RoutingSnapshot holds the routing objects as they were before a traffic change
+type RoutingSnapshot struct {
+	Objects []SnapshotObject `json:"objects"`
+}
+
+// SnapshotObject is the serialized state of a routing object
+type SnapshotObject struct {
+	Kind   string          `json:"kind"`
+	Name   string          `json:"name"`
+	Object json.RawMessage `json:"object"`
+}
+
+func newRoutingSnapshot(kind string, objects ...metav1.Object) (*RoutingSnapshot, error) {
+	snapshot := &RoutingSnapshot{}
+	for _, obj := range objects {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s.%s marshal error: %w", kind, obj.GetName(), obj.GetNamespace(), err)
+		}
+		snapshot.Objects = append(snapshot.Objects, SnapshotObject{
+			Kind:   kind,
+			Name:   obj.GetName(),
+			Object: data,
+		})
+	}
+	return snapshot, nil
+}
+
+// restore decodes the objects of the given kind into obj and calls fn for each of them
+func (s *RoutingSnapshot) restore(kind string, obj interface{}, fn func() error) error {
+	for _, so := range s.Objects {
+		if so.Kind != kind {
+			continue
+		}
+		if err := json.Unmarshal(so.Object, obj); err != nil {
+			return fmt.Errorf("%s %s unmarshal error: %w", kind, so.Name, err)
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SnapshotRouter wraps a router to save its routing objects in a config map before
+// each traffic change, if the change fails the routing objects are restored from the snapshot
+type SnapshotRouter struct {
+	Interface
+	snapshotter Snapshotter
+	kubeClient  kubernetes.Interface
+	logger      *zap.SugaredLogger
+}
+
+// Reconcile restores the routing objects if the previous traffic change was interrupted
+// before completing, then reconciles the wrapped router
+func (sr *SnapshotRouter) Reconcile(canary *flaggerv1.Canary) error {
+	cm, err := sr.getSnapshot(canary)
+	if err != nil {
+		return err
+	}
+	if cm != nil && cm.Annotations[snapshotStateAnnotation] == snapshotPending {
+		if err := sr.restore(canary, cm); err != nil {
+			return err
+		}
+		sr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Routing restored from snapshot %s after an interrupted traffic change", cm.Name)
+	}
+	return sr.Interface.Reconcile(canary)
+}
+
+// SetRoutes snapshots the routing objects, then updates the destinations weight
+func (sr *SnapshotRouter) SetRoutes(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int, mirrored bool) error {
+	snapshot, err := sr.snapshotter.Snapshot(canary)
+	if err != nil {
+		return fmt.Errorf("routing snapshot failed: %w", err)
+	}
+	cm, err := sr.saveSnapshot(canary, snapshot)
+	if err != nil {
+		return err
+	}
+
+	if err := sr.Interface.SetRoutes(canary, primaryWeight, canaryWeight, mirrored); err != nil {
+		if restoreErr := sr.restore(canary, cm); restoreErr != nil {
+			return fmt.Errorf("%v, restoring routing snapshot failed: %w", err, restoreErr)
+		}
+		return err
+	}
+
+	return sr.setSnapshotState(cm, snapshotApplied)
+}
+
+func snapshotName(canary *flaggerv1.Canary) string {
+	return fmt.Sprintf("%s-routing-snapshot", canary.Name)
+}
+
+func (sr *SnapshotRouter) getSnapshot(canary *flaggerv1.Canary) (*corev1.ConfigMap, error) {
+	name := snapshotName(canary)
+	cm, err := sr.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("configmap %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+	return cm, nil
+}
+
+// saveSnapshot stores the snapshot in a config map owned by the canary and marks it as pending
+func (sr *SnapshotRouter) saveSnapshot(canary *flaggerv1.Canary, snapshot *RoutingSnapshot) (*corev1.ConfigMap, error) {
+	name := snapshotName(canary)
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("routing snapshot marshal error: %w", err)
+	}
+
+	existing, err := sr.getSnapshot(canary)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: canary.Namespace,
+				Annotations: map[string]string{
+					snapshotStateAnnotation: snapshotPending,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Data: map[string]string{snapshotDataKey: string(data)},
+		}
+		cm, err = sr.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("configmap %s.%s create error: %w", name, canary.Namespace, err)
+		}
+		return cm, nil
+	}
+
+	clone := existing.DeepCopy()
+	if clone.Annotations == nil {
+		clone.Annotations = make(map[string]string)
+	}
+	clone.Annotations[snapshotStateAnnotation] = snapshotPending
+	clone.Data = map[string]string{snapshotDataKey: string(data)}
+	cm, err := sr.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("configmap %s.%s update error: %w", name, canary.Namespace, err)
+	}
+	return cm, nil
+}
+
+func (sr *SnapshotRouter) setSnapshotState(cm *corev1.ConfigMap, state string) error {
+	clone := cm.DeepCopy()
+	if clone.Annotations == nil {
+		clone.Annotations = make(map[string]string)
+	}
+	clone.Annotations[snapshotStateAnnotation] = state
+	if _, err := sr.kubeClient.CoreV1().ConfigMaps(cm.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("configmap %s.%s update error: %w", cm.Name, cm.Namespace, err)
+	}
+	return nil
+}
+
+// restore applies the routing objects saved in the config map and marks the snapshot as restored
+func (sr *SnapshotRouter) restore(canary *flaggerv1.Canary, cm *corev1.ConfigMap) error {
+	snapshot := &RoutingSnapshot{}
+	if err := json.Unmarshal([]byte(cm.Data[snapshotDataKey]), snapshot); err != nil {
+		return fmt.Errorf("configmap %s.%s snapshot unmarshal error: %w", cm.Name, cm.Namespace, err)
+	}
+	if err := sr.snapshotter.Restore(canary, snapshot); err != nil {
+		return fmt.Errorf("routing restore failed: %w", err)
+	}
+	return sr.setSnapshotState(cm, snapshotRestored)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// failingIstioRouter changes the virtual service then fails like an interrupted update would
+type failingIstioRouter struct {
+	*IstioRouter
+}
+
+func (fr *failingIstioRouter) SetRoutes(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int, mirrored bool) error {
+	if err := fr.IstioRouter.SetRoutes(canary, primaryWeight, canaryWeight, mirrored); err != nil {
+		return err
+	}
+	return errors.New("connection reset")
+}
+
+func newSnapshotFixture(t *testing.T) (fixture, *IstioRouter, *Factory) {
+	mocks := newFixture(nil)
+	mocks.canary.Spec.Service.RoutingSnapshot = true
+	ir := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+	require.NoError(t, ir.Reconcile(mocks.canary))
+
+	// add a field that Flagger doesn't manage
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	vs.Annotations["team"] = "podinfo"
+	_, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Update(context.TODO(), vs, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	factory := NewFactory(nil, mocks.kubeClient, mocks.flaggerClient, "", "", mocks.logger, mocks.meshClient)
+	return mocks, ir, factory
+}
+
+func TestSnapshotRouter_SetRoutes(t *testing.T) {
+	mocks, ir, factory := newSnapshotFixture(t)
+	router := factory.WithSnapshots(mocks.canary, ir)
+	require.IsType(t, &SnapshotRouter{}, router)
+
+	require.NoError(t, router.SetRoutes(mocks.canary, 80, 20, false))
+
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 80, p)
+	assert.Equal(t, 20, c)
+
+	cm, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-routing-snapshot", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, snapshotApplied, cm.Annotations[snapshotStateAnnotation])
+	assert.Contains(t, cm.Data[snapshotDataKey], "VirtualService")
+
+	// snapshots are disabled by default
+	mocks.canary.Spec.Service.RoutingSnapshot = false
+	assert.IsType(t, &IstioRouter{}, factory.WithSnapshots(mocks.canary, ir))
+}
+
+func TestSnapshotRouter_RestoreOnFailure(t *testing.T) {
+	mocks, ir, factory := newSnapshotFixture(t)
+	require.NoError(t, factory.WithSnapshots(mocks.canary, ir).SetRoutes(mocks.canary, 90, 10, false))
+
+	router := factory.WithSnapshots(mocks.canary, &failingIstioRouter{ir})
+	require.Error(t, router.SetRoutes(mocks.canary, 50, 50, false))
+
+	p, c, _, err := ir.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 90, p)
+	assert.Equal(t, 10, c)
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", vs.Annotations["team"])
+
+	cm, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-routing-snapshot", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, snapshotRestored, cm.Annotations[snapshotStateAnnotation])
+}
+
+func TestSnapshotRouter_RestoreInterrupted(t *testing.T) {
+	mocks, ir, factory := newSnapshotFixture(t)
+	router := factory.WithSnapshots(mocks.canary, ir).(*SnapshotRouter)
+
+	// save a snapshot and change the routes without marking the snapshot as applied
+	snapshot, err := ir.Snapshot(mocks.canary)
+	require.NoError(t, err)
+	_, err = router.saveSnapshot(mocks.canary, snapshot)
+	require.NoError(t, err)
+	require.NoError(t, ir.SetRoutes(mocks.canary, 40, 60, false))
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	delete(vs.Annotations, "team")
+	_, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Update(context.TODO(), vs, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	p, c, _, err := ir.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", vs.Annotations["team"])
+}
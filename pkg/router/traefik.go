@@ -48,6 +48,7 @@ func (tr *TraefikRouter) Reconcile(canary *flaggerv1.Canary) error {
 					Name:      primaryName,
 					Namespace: canary.Namespace,
 					Port:      canary.Spec.Service.Port,
+					Scheme:    tr.makeScheme(canary),
 					Weight:    100,
 				},
 			},
@@ -72,7 +73,7 @@ func (tr *TraefikRouter) Reconcile(canary *flaggerv1.Canary) error {
 				Name:        apexName,
 				Namespace:   canary.Namespace,
 				Labels:      tsMetadata.Labels,
-				Annotations: filterMetadata(tsMetadata.Annotations),
+				Annotations: withOwnership(filterMetadata(tsMetadata.Annotations), canary, newSpec),
 				OwnerReferences: []metav1.OwnerReference{
 					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
 						Group:   flaggerv1.SchemeGroupVersion.Group,
@@ -104,6 +105,7 @@ func (tr *TraefikRouter) Reconcile(canary *flaggerv1.Canary) error {
 					Name:      canaryName,
 					Namespace: canary.Namespace,
 					Port:      canary.Spec.Service.Port,
+					Scheme:    tr.makeScheme(canary),
 					Weight:    100,
 				},
 			)
@@ -115,8 +117,13 @@ func (tr *TraefikRouter) Reconcile(canary *flaggerv1.Canary) error {
 			cmpopts.IgnoreFields(traefikv1alpha1.Service{}, "Weight"),
 		); diff != "" {
 
+			drifted, err := checkDrift(canary, "TraefikService", traefikService, newSpec)
+			if err != nil {
+				return err
+			}
 			clone := traefikService.DeepCopy()
 			clone.Spec = newSpec
+			clone.Annotations = withOwnership(clone.Annotations, canary, newSpec)
 
 			_, err = tr.traefikClient.TraefikV1alpha1().TraefikServices(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
 			if err != nil {
@@ -124,6 +131,9 @@ func (tr *TraefikRouter) Reconcile(canary *flaggerv1.Canary) error {
 			}
 			tr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Infof("TraefikService %s.%s updated", traefikService.GetName(), canary.Namespace)
+			if drifted {
+				return repairedDrift("TraefikService", traefikService)
+			}
 		}
 	}
 
@@ -172,7 +182,7 @@ func (tr *TraefikRouter) SetRoutes(
 	apexName, primaryName, canaryName := canary.GetServiceNames()
 
 	if primaryWeight == 0 && canaryWeight == 0 {
-		return fmt.Errorf("RoutingRule %s.%s update failed: no valid weights", apexName, canary.Namespace)
+		return fmt.Errorf("TraefikService %s.%s update failed: no valid weights", apexName, canary.Namespace)
 	}
 	traefikService, err := tr.traefikClient.TraefikV1alpha1().TraefikServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
@@ -184,6 +194,7 @@ func (tr *TraefikRouter) SetRoutes(
 			Name:      primaryName,
 			Namespace: canary.Namespace,
 			Port:      canary.Spec.Service.Port,
+			Scheme:    tr.makeScheme(canary),
 			Weight:    uint(primaryWeight),
 		},
 	}
@@ -192,6 +203,7 @@ func (tr *TraefikRouter) SetRoutes(
 			Name:      canaryName,
 			Namespace: canary.Namespace,
 			Port:      canary.Spec.Service.Port,
+			Scheme:    tr.makeScheme(canary),
 			Weight:    uint(canaryWeight),
 		})
 	}
@@ -208,3 +220,43 @@ func (tr *TraefikRouter) SetRoutes(
 func (tr *TraefikRouter) Finalize(_ *flaggerv1.Canary) error {
 	return nil
 }
+
+// makeScheme returns the scheme Traefik uses to reach the primary and canary services
+func (tr *TraefikRouter) makeScheme(canary *flaggerv1.Canary) string {
+	switch canary.GetUpstreamProtocol() {
+	case "h2c":
+		return "h2c"
+	case "h2", "tls":
+		return "https"
+	}
+	return ""
+}
+
+// Snapshot returns the current state of the TraefikService
+func (tr *TraefikRouter) Snapshot(canary *flaggerv1.Canary) (*RoutingSnapshot, error) {
+	apexName, _, _ := canary.GetServiceNames()
+	obj, err := tr.traefikClient.TraefikV1alpha1().TraefikServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("TraefikService %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+	return newRoutingSnapshot("TraefikService", obj)
+}
+
+// Restore sets the spec, labels and annotations of the TraefikService to the snapshot state
+func (tr *TraefikRouter) Restore(canary *flaggerv1.Canary, snapshot *RoutingSnapshot) error {
+	saved := &traefikv1alpha1.TraefikService{}
+	return snapshot.restore("TraefikService", saved, func() error {
+		obj, err := tr.traefikClient.TraefikV1alpha1().TraefikServices(canary.Namespace).Get(context.TODO(), saved.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("TraefikService %s.%s get query error: %w", saved.Name, canary.Namespace, err)
+		}
+		clone := obj.DeepCopy()
+		clone.Spec = saved.Spec
+		clone.Labels = saved.Labels
+		clone.Annotations = saved.Annotations
+		if _, err := tr.traefikClient.TraefikV1alpha1().TraefikServices(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("TraefikService %s.%s update error: %w", saved.Name, canary.Namespace, err)
+		}
+		return nil
+	})
+}
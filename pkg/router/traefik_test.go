@@ -52,7 +52,8 @@ func TestTraefikRouter_Reconcile(t *testing.T) {
 	assert.Equal(t, uint(100), services[0].Weight)
 
 	assert.Equal(t, ts.ObjectMeta.Labels, mocks.canary.Spec.Service.Apex.Labels)
-	assert.Equal(t, ts.ObjectMeta.Annotations, filterMetadata(mocks.canary.Spec.Service.Apex.Annotations))
+	assert.Equal(t, withoutOwnership(ts.ObjectMeta.Annotations), filterMetadata(mocks.canary.Spec.Service.Apex.Annotations))
+	assert.Equal(t, "podinfo.default", ts.ObjectMeta.Annotations[ownerAnnotation])
 
 	for _, tt := range []struct {
 		name        string
@@ -156,3 +157,27 @@ func TestTraefikRouter_GetRoutes(t *testing.T) {
 	assert.Equal(t, 0, c)
 	assert.False(t, m)
 }
+
+func TestTraefikRouter_Scheme(t *testing.T) {
+	mocks := newFixture(nil)
+	mocks.canary.Spec.Service.Protocol = &flaggerv1.CanaryProtocol{
+		Upstream: "h2c",
+	}
+
+	router := &TraefikRouter{
+		traefikClient: mocks.meshClient,
+		logger:        mocks.logger,
+	}
+
+	require.NoError(t, router.Reconcile(mocks.canary))
+	require.NoError(t, router.SetRoutes(mocks.canary, 80, 20, false))
+
+	ts, err := router.traefikClient.TraefikV1alpha1().TraefikServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	services := ts.Spec.Weighted.Services
+	require.Len(t, services, 2)
+	for _, s := range services {
+		assert.Equal(t, "h2c", s.Scheme)
+	}
+}
@@ -2,6 +2,8 @@ package router
 
 import (
 	"strings"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 )
 
 const (
@@ -41,3 +43,13 @@ func filterMetadata(meta map[string]string) map[string]string {
 	res[toolkitReconcileKey] = toolkitReconcileValue
 	return res
 }
+
+// headerName translates a JWT claim match key (@request.auth.claims.<claim>)
+// to the header populated by the gateway with the claim value,
+// for providers that can't match on JWT claims natively
+func headerName(canary *flaggerv1.Canary, key string) string {
+	if claim := strings.TrimPrefix(key, flaggerv1.JWTClaimMatchPrefix); claim != key {
+		return canary.GetJWTClaimHeaderPrefix() + strings.ReplaceAll(claim, ".", "-")
+	}
+	return key
+}
@@ -20,6 +20,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 )
 
 func TestIncludeLabelsByPrefix(t *testing.T) {
@@ -56,3 +58,14 @@ func TestIncludeLabelsByPrefixWithWildcard(t *testing.T) {
 		"lorem": "ipsum",
 	})
 }
+
+func TestHeaderName(t *testing.T) {
+	canary := &flaggerv1.Canary{Spec: flaggerv1.CanarySpec{Analysis: &flaggerv1.CanaryAnalysis{}}}
+
+	assert.Equal(t, "x-canary", headerName(canary, "x-canary"))
+	assert.Equal(t, "x-jwt-claim-groups", headerName(canary, "@request.auth.claims.groups"))
+	assert.Equal(t, "x-jwt-claim-org-tier", headerName(canary, "@request.auth.claims.org.tier"))
+
+	canary.Spec.Analysis.JWTClaimHeaderPrefix = "x-auth-"
+	assert.Equal(t, "x-auth-groups", headerName(canary, "@request.auth.claims.groups"))
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// DecisionsPath is the URL prefix of the promotion decisions API
+const DecisionsPath = "/api/v1/decisions/"
+
+// Decision is the promotion decision context of a canary, it is meant to be
+// queried by admission policies before allowing direct edits of the target workload
+type Decision struct {
+	Name      string                                  `json:"name"`
+	Namespace string                                  `json:"namespace"`
+	TargetRef flaggerv1.CrossNamespaceObjectReference `json:"targetRef"`
+	Phase     flaggerv1.CanaryPhase                   `json:"phase"`
+	// LastSucceeded is true if the last canary run ended with a promotion,
+	// after a successful analysis or with the analysis skipped
+	LastSucceeded bool `json:"lastSucceeded"`
+	// InProgress is true while a canary analysis is underway
+	InProgress bool `json:"inProgress"`
+	// SpecPromoted is true if the last applied spec of the target has been promoted
+	SpecPromoted       bool                                `json:"specPromoted"`
+	LastAppliedSpec    string                              `json:"lastAppliedSpec,omitempty"`
+	LastPromotedSpec   string                              `json:"lastPromotedSpec,omitempty"`
+	LastTransitionTime metav1.Time                         `json:"lastTransitionTime,omitempty"`
+	Conditions         []flaggerv1.CanaryCondition         `json:"conditions,omitempty"`
+	Verification       *flaggerv1.CanaryVerificationStatus `json:"verification,omitempty"`
+	TimeSlice          *flaggerv1.CanaryTimeSliceStatus    `json:"timeSlice,omitempty"`
+}
+
+// DecisionsHandler serves the promotion decision of a canary at
+// /api/v1/decisions/<namespace>/canaries/<name> or of the canary that targets a workload
+// at /api/v1/decisions/<namespace>/<deployments|daemonsets>/<name>,
+// the requests must carry the token as a bearer authorization header
+func DecisionsHandler(flaggerClient clientset.Interface, token string, logger *zap.SugaredLogger) http.Handler {
+	return canaryHandler(DecisionsPath, flaggerClient, token, logger, func(cd *flaggerv1.Canary) interface{} {
+		return newDecision(cd)
+	})
+}
+
+// canaryHandler serves a view of the canary found at <prefix><namespace>/<kind>/<name>
+func canaryHandler(prefix string, flaggerClient clientset.Interface, token string, logger *zap.SugaredLogger,
+	view func(cd *flaggerv1.Canary) interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/"), "/")
+		if len(parts) != 3 {
+			http.Error(w, fmt.Sprintf("path must be %s<namespace>/<kind>/<name>", prefix), http.StatusBadRequest)
+			return
+		}
+
+		cd, err := findCanary(r.Context(), flaggerClient, parts[0], parts[1], parts[2])
+		if err != nil {
+			logger.Debugf("Canary query %s failed %v", r.URL.Path, err)
+			if errors.IsNotFound(err) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(view(cd))
+	})
+}
+
+func findCanary(ctx context.Context, flaggerClient clientset.Interface, namespace, kind, name string) (*flaggerv1.Canary, error) {
+	var targetKind string
+	switch kind {
+	case "canaries":
+		return flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "deployments":
+		targetKind = "Deployment"
+	case "daemonsets":
+		targetKind = "DaemonSet"
+	default:
+		return nil, fmt.Errorf("kind %s not supported, can be canaries, deployments or daemonsets", kind)
+	}
+
+	list, err := flaggerClient.FlaggerV1beta1().Canaries(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		ref := list.Items[i].Spec.TargetRef
+		if ref.Kind == targetKind && ref.Name == name {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, errors.NewNotFound(flaggerv1.Resource("canary"), fmt.Sprintf("%s/%s target", kind, name))
+}
+
+func newDecision(cd *flaggerv1.Canary) Decision {
+	d := Decision{
+		Name:               cd.Name,
+		Namespace:          cd.Namespace,
+		TargetRef:          cd.Spec.TargetRef,
+		Phase:              cd.Status.Phase,
+		LastSucceeded:      cd.Status.Phase.IsPromoted(),
+		SpecPromoted:       cd.Status.LastAppliedSpec != "" && cd.Status.LastAppliedSpec == cd.Status.LastPromotedSpec,
+		LastAppliedSpec:    cd.Status.LastAppliedSpec,
+		LastPromotedSpec:   cd.Status.LastPromotedSpec,
+		LastTransitionTime: cd.Status.LastTransitionTime,
+		Conditions:         cd.Status.Conditions,
+		Verification:       cd.Status.LastVerification,
+		TimeSlice:          cd.Status.TimeSlice,
+	}
+
+	switch cd.Status.Phase {
+	case flaggerv1.CanaryPhaseWaiting, flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaitingPromotion,
+		flaggerv1.CanaryPhasePromoting, flaggerv1.CanaryPhaseFinalising:
+		d.InProgress = true
+	}
+
+	return d
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/fluxcd/flagger/pkg/client/clientset/versioned/fake"
+)
+
+func TestDecisionsHandler(t *testing.T) {
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo-canary", Namespace: "test"},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{Kind: "Deployment", Name: "podinfo"},
+		},
+		Status: flaggerv1.CanaryStatus{
+			Phase:            flaggerv1.CanaryPhaseSucceeded,
+			LastAppliedSpec:  "123",
+			LastPromotedSpec: "123",
+		},
+	}
+	handler := DecisionsHandler(fakeFlagger.NewSimpleClientset(cd), "secret", zap.NewNop().Sugar())
+
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get(DecisionsPath+"test/canaries/podinfo-canary", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, get(DecisionsPath+"test/canaries/podinfo-canary", "wrong").Code)
+	assert.Equal(t, http.StatusBadRequest, get(DecisionsPath+"test/podinfo", "secret").Code)
+	assert.Equal(t, http.StatusNotFound, get(DecisionsPath+"test/deployments/frontend", "secret").Code)
+
+	for _, path := range []string{"test/canaries/podinfo-canary", "test/deployments/podinfo"} {
+		rec := get(DecisionsPath+path, "secret")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var d Decision
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &d))
+		assert.Equal(t, "podinfo-canary", d.Name)
+		assert.True(t, d.LastSucceeded)
+		assert.True(t, d.SpecPromoted)
+		assert.False(t, d.InProgress)
+	}
+}
+
+func TestNewDecision_PromotedWithoutAnalysis(t *testing.T) {
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "test"},
+		Status: flaggerv1.CanaryStatus{
+			Phase:            flaggerv1.CanaryPhasePromotedWithoutAnalysis,
+			LastAppliedSpec:  "123",
+			LastPromotedSpec: "123",
+		},
+	}
+
+	d := newDecision(cd)
+	assert.True(t, d.LastSucceeded)
+	assert.True(t, d.SpecPromoted)
+	assert.False(t, d.InProgress)
+}
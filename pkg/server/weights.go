@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// WeightsPath is the URL prefix of the traffic weights API
+const WeightsPath = "/api/v1/weights/"
+
+// Weights is the traffic split of a canary as read back from the provider routing objects
+type Weights struct {
+	Name      string                `json:"name"`
+	Namespace string                `json:"namespace"`
+	Phase     flaggerv1.CanaryPhase `json:"phase"`
+	// Provider is empty until Flagger reads the routes for the first time
+	Provider      string `json:"provider,omitempty"`
+	PrimaryWeight int    `json:"primaryWeight"`
+	CanaryWeight  int    `json:"canaryWeight"`
+	Mirrored      bool   `json:"mirrored"`
+	// LastTransitionTime is the time the traffic split changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// WeightsHandler serves the traffic split of a canary at
+// /api/v1/weights/<namespace>/canaries/<name> or of the canary that targets a workload
+// at /api/v1/weights/<namespace>/<deployments|daemonsets>/<name>,
+// the requests must carry the token as a bearer authorization header
+func WeightsHandler(flaggerClient clientset.Interface, token string, logger *zap.SugaredLogger) http.Handler {
+	return canaryHandler(WeightsPath, flaggerClient, token, logger, func(cd *flaggerv1.Canary) interface{} {
+		return newWeights(cd)
+	})
+}
+
+func newWeights(cd *flaggerv1.Canary) Weights {
+	w := Weights{
+		Name:          cd.Name,
+		Namespace:     cd.Namespace,
+		Phase:         cd.Status.Phase,
+		PrimaryWeight: 100,
+	}
+	if r := cd.Status.Routes; r != nil {
+		w.Provider = r.Provider
+		w.PrimaryWeight = r.PrimaryWeight
+		w.CanaryWeight = r.CanaryWeight
+		w.Mirrored = r.Mirrored
+		w.LastTransitionTime = r.LastTransitionTime
+	}
+	return w
+}
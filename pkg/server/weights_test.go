@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/fluxcd/flagger/pkg/client/clientset/versioned/fake"
+)
+
+func TestWeightsHandler(t *testing.T) {
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "test"},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{Kind: "Deployment", Name: "podinfo"},
+		},
+		Status: flaggerv1.CanaryStatus{
+			Phase:        flaggerv1.CanaryPhaseProgressing,
+			CanaryWeight: 20,
+			Routes: &flaggerv1.CanaryRoutesStatus{
+				Provider:      "istio",
+				PrimaryWeight: 90,
+				CanaryWeight:  10,
+			},
+		},
+	}
+	initializing := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "test"},
+	}
+	handler := WeightsHandler(fakeFlagger.NewSimpleClientset(cd, initializing), "secret", zap.NewNop().Sugar())
+
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get(WeightsPath+"test/canaries/podinfo", "").Code)
+	assert.Equal(t, http.StatusNotFound, get(WeightsPath+"test/canaries/backend", "secret").Code)
+
+	// the weights read back from the provider are served instead of the desired weight
+	rec := get(WeightsPath+"test/deployments/podinfo", "secret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var w Weights
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &w))
+	assert.Equal(t, "istio", w.Provider)
+	assert.Equal(t, 90, w.PrimaryWeight)
+	assert.Equal(t, 10, w.CanaryWeight)
+
+	// the primary receives all the traffic until the routes are read
+	rec = get(WeightsPath+"test/canaries/frontend", "secret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	w = Weights{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &w))
+	assert.Equal(t, 100, w.PrimaryWeight)
+	assert.Equal(t, 0, w.CanaryWeight)
+	assert.Empty(t, w.Provider)
+}
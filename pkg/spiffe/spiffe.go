@@ -0,0 +1,278 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spiffe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// EndpointSocketEnv is the environment variable holding the address of the SPIFFE Workload API
+	EndpointSocketEnv = "SPIFFE_ENDPOINT_SOCKET"
+
+	// https://github.com/spiffe/spiffe/blob/main/standards/SPIFFE_Workload_Endpoint.md
+	fetchX509SVIDMethod = "/SpiffeWorkloadAPI/FetchX509SVID"
+	workloadAPIHeader   = "workload.spiffe.io"
+
+	// streamRetryInterval is the delay before reopening a closed Workload API stream
+	streamRetryInterval = 5 * time.Second
+)
+
+var (
+	defaultMu     sync.RWMutex
+	defaultClient *http.Client
+)
+
+// SetDefault sets the source of the SVID presented by the
+// webhooks and metric providers that opt in for SPIFFE mTLS
+func SetDefault(s *Source) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultClient = s.HTTPClient()
+}
+
+// DefaultHTTPClient returns the client presenting the SVID of the default source,
+// it returns an error if Flagger isn't connected to a Workload API
+func DefaultHTTPClient() (*http.Client, error) {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	if defaultClient == nil {
+		return nil, fmt.Errorf("SPIFFE mTLS requires Flagger to run with -spiffe-endpoint-socket")
+	}
+	return defaultClient, nil
+}
+
+// Source serves the X.509 SVID of Flagger fetched from the SPIFFE Workload API,
+// the SVID and the trust bundles are updated when the SPIRE agent rotates them
+type Source struct {
+	trustDomain string
+	conn        *grpc.ClientConn
+	logger      *zap.SugaredLogger
+	cancel      context.CancelFunc
+
+	mu     sync.RWMutex
+	cert   *tls.Certificate
+	bundle *x509.CertPool
+	ready  chan struct{}
+	once   sync.Once
+}
+
+// NewSource connects to the Workload API at the address, e.g. unix:///run/spire/sockets/agent.sock,
+// and waits for the first SVID until the context is done. The peers must present an SVID
+// of the trust domain, or of any trust domain in the bundles if empty.
+func NewSource(ctx context.Context, address string, trustDomain string, logger *zap.SugaredLogger) (*Source, error) {
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("Workload API %s dial error: %w", address, err)
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	s := &Source{
+		trustDomain: trustDomain,
+		conn:        conn,
+		logger:      logger,
+		cancel:      cancel,
+		ready:       make(chan struct{}),
+	}
+	go s.watch(watchCtx)
+
+	select {
+	case <-s.ready:
+		return s, nil
+	case <-ctx.Done():
+		s.Close()
+		return nil, fmt.Errorf("Workload API %s didn't return an SVID: %w", address, ctx.Err())
+	}
+}
+
+// Close stops watching the SVID updates and closes the Workload API connection
+func (s *Source) Close() error {
+	s.cancel()
+	return s.conn.Close()
+}
+
+// TLSConfig returns a client TLS config that presents the SVID and verifies
+// the server SVID against the trust bundles instead of the server DNS name
+func (s *Source) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+			return s.cert, nil
+		},
+		// the SVIDs have no DNS names, the chain and the SPIFFE ID are verified below
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: s.verifyPeer,
+	}
+}
+
+// HTTPClient returns a client with its own transport that presents the SVID,
+// the default transport used by the other outbound calls is left unchanged
+func (s *Source) HTTPClient() *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = s.TLSConfig()
+	return &http.Client{Transport: t}
+}
+
+func (s *Source) verifyPeer(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return fmt.Errorf("peer didn't present a certificate")
+	}
+	s.mu.RLock()
+	bundle := s.bundle
+	s.mu.RUnlock()
+
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("parsing peer certificate failed: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         bundle,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return fmt.Errorf("peer SVID verification failed: %w", err)
+	}
+
+	id, err := ID(certs[0])
+	if err != nil {
+		return err
+	}
+	if s.trustDomain != "" && id.Host != s.trustDomain {
+		return fmt.Errorf("peer SPIFFE ID %s is not in the trust domain %s", id, s.trustDomain)
+	}
+	return nil
+}
+
+// watch reopens the Workload API stream until the source is closed
+func (s *Source) watch(ctx context.Context) {
+	for {
+		err := s.stream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Warnf("Workload API stream error: %v", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(streamRetryInterval):
+		}
+	}
+}
+
+// stream receives the SVID updates pushed by the Workload API
+func (s *Source) stream(ctx context.Context) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, workloadAPIHeader, "true")
+	st, err := s.conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, fetchX509SVIDMethod, grpc.ForceCodec(rawCodec{}))
+	if err != nil {
+		return err
+	}
+	if err := st.SendMsg([]byte{}); err != nil {
+		return err
+	}
+	if err := st.CloseSend(); err != nil {
+		return err
+	}
+
+	for {
+		var msg []byte
+		if err := st.RecvMsg(&msg); err != nil {
+			return err
+		}
+		if err := s.update(msg); err != nil {
+			s.logger.Warnf("Workload API returned an invalid SVID: %v", err)
+			continue
+		}
+		s.once.Do(func() { close(s.ready) })
+	}
+}
+
+// update replaces the SVID and the trust bundles with the ones of the response,
+// the first SVID of the response is the default identity of the workload
+func (s *Source) update(msg []byte) error {
+	resp, err := parseX509SVIDResponse(msg)
+	if err != nil {
+		return err
+	}
+	if len(resp.svids) == 0 {
+		return fmt.Errorf("the response contains no SVID")
+	}
+	svid := resp.svids[0]
+
+	chain, err := x509.ParseCertificates(svid.certs)
+	if err != nil || len(chain) == 0 {
+		return fmt.Errorf("parsing SVID %s failed: %v", svid.id, err)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(svid.key)
+	if err != nil {
+		return fmt.Errorf("parsing SVID %s key failed: %w", svid.id, err)
+	}
+
+	bundle := x509.NewCertPool()
+	for _, b := range append([][]byte{svid.bundle}, resp.federatedBundles...) {
+		roots, err := x509.ParseCertificates(b)
+		if err != nil {
+			return fmt.Errorf("parsing trust bundle failed: %w", err)
+		}
+		for _, root := range roots {
+			bundle.AddCert(root)
+		}
+	}
+
+	cert := &tls.Certificate{PrivateKey: key, Leaf: chain[0]}
+	for _, c := range chain {
+		cert.Certificate = append(cert.Certificate, c.Raw)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cert = cert
+	s.bundle = bundle
+	return nil
+}
+
+// ID returns the SPIFFE ID of an X.509 SVID
+func ID(cert *x509.Certificate) (*url.URL, error) {
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" {
+			return uri, nil
+		}
+	}
+	return nil, fmt.Errorf("certificate %s has no SPIFFE ID", cert.Subject)
+}
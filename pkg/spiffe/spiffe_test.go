@@ -0,0 +1,252 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spiffe
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "spire"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+// svid returns the DER encoded certificate and PKCS#8 key of an SVID
+func (ca *testCA) svid(t *testing.T, id string, serial int64) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	uri, err := url.Parse(id)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         []*url.URL{uri},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return der, keyDER
+}
+
+// response encodes an X509SVIDResponse with a single SVID
+func (ca *testCA) response(t *testing.T, id string, serial int64) []byte {
+	cert, key := ca.svid(t, id, serial)
+
+	var svid []byte
+	svid = protowire.AppendTag(svid, 1, protowire.BytesType)
+	svid = protowire.AppendString(svid, id)
+	svid = protowire.AppendTag(svid, 2, protowire.BytesType)
+	svid = protowire.AppendBytes(svid, cert)
+	svid = protowire.AppendTag(svid, 3, protowire.BytesType)
+	svid = protowire.AppendBytes(svid, key)
+	svid = protowire.AppendTag(svid, 4, protowire.BytesType)
+	svid = protowire.AppendBytes(svid, ca.cert.Raw)
+
+	var resp []byte
+	resp = protowire.AppendTag(resp, 1, protowire.BytesType)
+	resp = protowire.AppendBytes(resp, svid)
+	return resp
+}
+
+// newWorkloadAPI starts a Workload API on a unix socket that sends the initial response,
+// if any, on each stream followed by the updates sent to the channel
+func newWorkloadAPI(t *testing.T, initial []byte, updates chan []byte) string {
+	dir, err := os.MkdirTemp("", "spiffe")
+	require.NoError(t, err)
+	socket := filepath.Join(dir, "agent.sock")
+	l, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := grpc.NewServer(
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+			method, _ := grpc.MethodFromServerStream(stream)
+			if method != fetchX509SVIDMethod {
+				return fmt.Errorf("unknown method %s", method)
+			}
+			md, _ := metadata.FromIncomingContext(stream.Context())
+			if v := md.Get(workloadAPIHeader); len(v) != 1 || v[0] != "true" {
+				return fmt.Errorf("missing %s header", workloadAPIHeader)
+			}
+			var req []byte
+			if err := stream.RecvMsg(&req); err != nil {
+				return err
+			}
+			if initial != nil {
+				if err := stream.SendMsg(initial); err != nil {
+					return err
+				}
+			}
+			for {
+				select {
+				case <-stream.Context().Done():
+					return nil
+				case resp := <-updates:
+					if err := stream.SendMsg(resp); err != nil {
+						return err
+					}
+				}
+			}
+		}),
+	)
+	go server.Serve(l)
+	t.Cleanup(func() {
+		server.Stop()
+		os.RemoveAll(dir)
+	})
+
+	return "unix://" + socket
+}
+
+func newTestSource(t *testing.T, address string, trustDomain string) *Source {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	source, err := NewSource(ctx, address, trustDomain, zap.NewNop().Sugar())
+	require.NoError(t, err)
+	t.Cleanup(func() { source.Close() })
+	return source
+}
+
+func TestSource_MutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	address := newWorkloadAPI(t, ca.response(t, "spiffe://example.org/flagger", 2), nil)
+
+	serverCert, serverKey := ca.svid(t, "spiffe://example.org/prometheus", 4)
+	key, err := x509.ParsePKCS8PrivateKey(serverKey)
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	var clientID string
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := ID(r.TLS.PeerCertificates[0])
+		require.NoError(t, err)
+		clientID = id.String()
+	}))
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert}, PrivateKey: key}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	source := newTestSource(t, address, "example.org")
+	resp, err := source.HTTPClient().Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "spiffe://example.org/flagger", clientID)
+
+	// the default transport used by the other outbound calls doesn't present the SVID
+	if cfg := http.DefaultTransport.(*http.Transport).TLSClientConfig; cfg != nil {
+		assert.Nil(t, cfg.GetClientCertificate)
+		assert.False(t, cfg.InsecureSkipVerify)
+	}
+
+	// reject the servers of other trust domains
+	other := newTestSource(t, address, "other.org")
+	_, err = other.HTTPClient().Get(ts.URL)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not in the trust domain other.org")
+}
+
+func TestSource_Rotation(t *testing.T) {
+	ca := newTestCA(t)
+	updates := make(chan []byte)
+	address := newWorkloadAPI(t, ca.response(t, "spiffe://example.org/flagger", 2), updates)
+
+	source := newTestSource(t, address, "")
+	first, err := source.TLSConfig().GetClientCertificate(nil)
+	require.NoError(t, err)
+
+	// the agent pushes the rotated SVID
+	updates <- ca.response(t, "spiffe://example.org/flagger", 3)
+	require.Eventually(t, func() bool {
+		second, err := source.TLSConfig().GetClientCertificate(nil)
+		require.NoError(t, err)
+		return second.Leaf.SerialNumber.Cmp(first.Leaf.SerialNumber) != 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNewSource_Timeout(t *testing.T) {
+	address := newWorkloadAPI(t, nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := NewSource(ctx, address, "", zap.NewNop().Sugar())
+	require.Error(t, err)
+}
+
+func TestDefaultHTTPClient(t *testing.T) {
+	defer func() { defaultClient = nil }()
+
+	_, err := DefaultHTTPClient()
+	require.Error(t, err)
+
+	ca := newTestCA(t)
+	SetDefault(newTestSource(t, newWorkloadAPI(t, ca.response(t, "spiffe://example.org/flagger", 2), nil), ""))
+
+	client, err := DefaultHTTPClient()
+	require.NoError(t, err)
+	assert.NotEqual(t, http.DefaultClient, client)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spiffe
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The Workload API messages are decoded with protowire to avoid depending on the generated
+// SPIFFE protobuf package, only the X.509 SVID fields used by Flagger are read.
+// https://github.com/spiffe/go-spiffe/blob/main/proto/spiffe/workload/workload.proto
+
+type x509SVID struct {
+	id     string
+	certs  []byte
+	key    []byte
+	bundle []byte
+}
+
+type x509SVIDResponse struct {
+	svids            []x509SVID
+	federatedBundles [][]byte
+}
+
+func parseX509SVIDResponse(b []byte) (*x509SVIDResponse, error) {
+	resp := &x509SVIDResponse{}
+	err := parseFields(b, func(num protowire.Number, v []byte) error {
+		switch num {
+		case 1: // repeated X509SVID svids
+			svid, err := parseX509SVID(v)
+			if err != nil {
+				return err
+			}
+			resp.svids = append(resp.svids, svid)
+		case 3: // map<string, bytes> federated_bundles
+			return parseFields(v, func(num protowire.Number, v []byte) error {
+				if num == 2 {
+					resp.federatedBundles = append(resp.federatedBundles, v)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return resp, err
+}
+
+func parseX509SVID(b []byte) (x509SVID, error) {
+	var svid x509SVID
+	err := parseFields(b, func(num protowire.Number, v []byte) error {
+		switch num {
+		case 1:
+			svid.id = string(v)
+		case 2:
+			svid.certs = v
+		case 3:
+			svid.key = v
+		case 4:
+			svid.bundle = v
+		}
+		return nil
+	})
+	return svid, err
+}
+
+// parseFields calls fn with the number and value of the length-delimited fields of a message
+func parseFields(b []byte, fn func(num protowire.Number, v []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("invalid message: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return fmt.Errorf("invalid field %d: %w", num, protowire.ParseError(n))
+			}
+			b = b[n:]
+			continue
+		}
+
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return fmt.Errorf("invalid field %d: %w", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+		if err := fn(num, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rawCodec passes the encoded messages through as byte slices
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	*b = append([]byte(nil), data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
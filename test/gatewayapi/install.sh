@@ -0,0 +1,56 @@
+#!/usr/bin/env bash
+
+set -o errexit
+
+GATEWAY_API_VER="v1.1.0"
+ISTIO_VER="1.22.0"
+REPO_ROOT=$(git rev-parse --show-toplevel)
+
+mkdir -p ${REPO_ROOT}/bin
+
+echo '>>> Installing Gateway API CRDs'
+# the experimental channel serves the v1alpha2 HTTPRoutes generated by Flagger
+kubectl apply -f https://github.com/kubernetes-sigs/gateway-api/releases/download/${GATEWAY_API_VER}/experimental-install.yaml
+
+echo ">>> Downloading Istio ${ISTIO_VER}"
+cd ${REPO_ROOT}/bin && \
+curl -L https://istio.io/downloadIstio | ISTIO_VERSION=${ISTIO_VER} sh -
+
+echo ">>> Installing Istio ${ISTIO_VER} as a Gateway API implementation"
+${REPO_ROOT}/bin/istio-${ISTIO_VER}/bin/istioctl install --set profile=minimal --skip-confirmation \
+  --set values.pilot.resources.requests.cpu=100m \
+  --set values.pilot.resources.requests.memory=100Mi
+
+kubectl -n istio-system rollout status deployment/istiod
+
+kubectl create ns istio-ingress --dry-run=client -oyaml | kubectl apply -f -
+cat <<EOT | kubectl apply -f -
+apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: gateway
+  namespace: istio-ingress
+spec:
+  gatewayClassName: istio
+  listeners:
+    - name: http
+      port: 80
+      protocol: HTTP
+      allowedRoutes:
+        namespaces:
+          from: All
+EOT
+
+kubectl -n istio-ingress wait --for=condition=programmed --timeout=2m gateways.gateway.networking.k8s.io/gateway
+kubectl -n istio-ingress get all
+
+echo '>>> Installing Flagger'
+helm upgrade -i flagger ${REPO_ROOT}/charts/flagger \
+--namespace istio-ingress \
+--set prometheus.install=true \
+--set meshProvider=gatewayapi
+
+kubectl -n istio-ingress set image deployment/flagger flagger=test/flagger:latest
+
+kubectl -n istio-ingress rollout status deployment/flagger
+kubectl -n istio-ingress rollout status deployment/flagger-prometheus
@@ -80,7 +80,7 @@ retries=50
 count=0
 ok=false
 until ${ok}; do
-    kubectl -n test get canary/podinfo | grep 'Succeeded' && ok=true || ok=false
+    kubectl -n test get canary/podinfo | grep 'PromotedWithoutAnalysis' && ok=true || ok=false
     sleep 5
     count=$(($count + 1))
     if [[ ${count} -eq ${retries} ]]; then
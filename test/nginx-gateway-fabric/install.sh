@@ -0,0 +1,48 @@
+#!/usr/bin/env bash
+
+set -o errexit
+
+GATEWAY_API_VER="v1.1.0"
+NGF_VER="v1.4.0"
+REPO_ROOT=$(git rev-parse --show-toplevel)
+
+mkdir -p ${REPO_ROOT}/bin
+
+echo '>>> Installing Gateway API CRDs'
+# the experimental channel serves the v1alpha2 HTTPRoutes generated by Flagger
+kubectl apply -f https://github.com/kubernetes-sigs/gateway-api/releases/download/${GATEWAY_API_VER}/experimental-install.yaml
+
+echo '>>> Installing NGINX Gateway Fabric'
+kubectl apply -f https://raw.githubusercontent.com/nginxinc/nginx-gateway-fabric/${NGF_VER}/deploy/crds.yaml
+kubectl apply -f https://raw.githubusercontent.com/nginxinc/nginx-gateway-fabric/${NGF_VER}/deploy/experimental/deploy.yaml
+
+kubectl -n nginx-gateway rollout status deployment/nginx-gateway
+kubectl -n nginx-gateway get all
+
+cat <<EOF | kubectl apply -f -
+apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: nginx
+  namespace: nginx-gateway
+spec:
+  gatewayClassName: nginx
+  listeners:
+    - name: http
+      port: 80
+      protocol: HTTP
+      allowedRoutes:
+        namespaces:
+          from: All
+EOF
+
+echo '>>> Installing Flagger'
+helm upgrade -i flagger ${REPO_ROOT}/charts/flagger \
+--namespace nginx-gateway \
+--set prometheus.install=true \
+--set meshProvider=gatewayapi:nginx
+
+kubectl -n nginx-gateway set image deployment/flagger flagger=test/flagger:latest
+
+kubectl -n nginx-gateway rollout status deployment/flagger
+kubectl -n nginx-gateway rollout status deployment/flagger-prometheus